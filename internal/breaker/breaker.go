@@ -0,0 +1,176 @@
+package breaker
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultThreshold is how many consecutive failures open the circuit
+	DefaultThreshold = 5
+	// DefaultCooldown is how long an open circuit fast-fails before probing the upstream again
+	DefaultCooldown = 30 * time.Second
+)
+
+// Circuit states
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half_open"
+)
+
+// OpenError is returned instead of calling the upstream while the circuit is open.
+// It is retryable: the upstream is probed again once RetryAfter has elapsed.
+type OpenError struct {
+	Upstream   string
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("UPSTREAM_UNAVAILABLE: %s circuit is open after repeated failures; retry after %s",
+		e.Upstream, e.RetryAfter.Round(time.Second))
+}
+
+// Status is a snapshot of a breaker for health reporting
+type Status struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+}
+
+// Breaker tracks consecutive failures of one upstream. After Threshold failures in a row it
+// opens and rejects calls for Cooldown; the first call after that is let through as a probe
+// (half-open), closing the circuit on success and reopening it on failure.
+type Breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// New creates a closed breaker. Non-positive threshold or cooldown fall back to the defaults.
+func New(name string, threshold int, cooldown time.Duration) *Breaker {
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultCooldown
+	}
+	return &Breaker{
+		name:      name,
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     StateClosed,
+	}
+}
+
+// Name returns the upstream the breaker guards
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// Allow reports whether a call may proceed, returning an *OpenError when it may not.
+// In the half-open state only one probe is allowed at a time.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		remaining := b.cooldown - b.now().Sub(b.openedAt)
+		if remaining > 0 {
+			return &OpenError{Upstream: b.name, RetryAfter: remaining}
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			return &OpenError{Upstream: b.name, RetryAfter: b.cooldown}
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Record reports the outcome of an allowed call
+func (b *Breaker) Record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if success {
+		b.state = StateClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.state = StateOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Status returns the breaker's current state
+func (b *Breaker) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := Status{State: b.state, ConsecutiveFailures: b.failures}
+	if b.state != StateClosed {
+		openedAt := b.openedAt.UTC()
+		status.OpenedAt = &openedAt
+	}
+	// An open circuit whose cooldown has passed lets the next call through
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		status.State = StateHalfOpen
+	}
+	return status
+}
+
+// Transport wraps next (http.DefaultTransport when nil) so every request passes through the
+// breaker. Transport errors and 5xx responses count as failures; other responses, including
+// 429, count as successes since the upstream answered. Requests abandoned by their caller
+// are not counted either way.
+func (b *Breaker) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{breaker: b, next: next}
+}
+
+type transport struct {
+	breaker *Breaker
+	next    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil && req.Context().Err() != nil {
+		t.breaker.release()
+		return nil, err
+	}
+	t.breaker.Record(err == nil && resp.StatusCode < http.StatusInternalServerError)
+	return resp, err
+}
+
+// release ends a call without recording an outcome, freeing the half-open probe slot
+func (b *Breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
@@ -0,0 +1,139 @@
+package breaker
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock lets tests move time past the cooldown
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func newTestBreaker(threshold int, cooldown time.Duration) (*Breaker, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b := New("osv", threshold, cooldown)
+	b.now = clock.Now
+	return b, clock
+}
+
+func TestBreaker_OpensFastFailsAndRecovers(t *testing.T) {
+	b, clock := newTestBreaker(3, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Allow() before threshold = %v", err)
+		}
+		b.Record(false)
+	}
+	if got := b.Status().State; got != StateOpen {
+		t.Fatalf("state after 3 failures = %s, want open", got)
+	}
+
+	// Open: calls fail fast with a retryable error
+	clock.now = clock.now.Add(20 * time.Second)
+	var open *OpenError
+	if err := b.Allow(); !errors.As(err, &open) {
+		t.Fatalf("Allow() while open = %v, want *OpenError", err)
+	}
+	if open.Upstream != "osv" || open.RetryAfter != 40*time.Second {
+		t.Errorf("OpenError = %+v, want osv retrying after 40s", open)
+	}
+
+	// Cooldown over: one probe is let through, others still fail fast
+	clock.now = clock.now.Add(time.Minute)
+	if got := b.Status().State; got != StateHalfOpen {
+		t.Errorf("state after cooldown = %s, want half_open", got)
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() probe = %v", err)
+	}
+	if err := b.Allow(); !errors.As(err, &open) {
+		t.Errorf("Allow() during probe = %v, want *OpenError", err)
+	}
+
+	// A successful probe closes the circuit
+	b.Record(true)
+	if status := b.Status(); status.State != StateClosed || status.ConsecutiveFailures != 0 {
+		t.Errorf("status after recovery = %+v, want closed with no failures", status)
+	}
+	if err := b.Allow(); err != nil {
+		t.Errorf("Allow() after recovery = %v", err)
+	}
+}
+
+func TestBreaker_FailedProbeReopens(t *testing.T) {
+	b, clock := newTestBreaker(1, time.Minute)
+	_ = b.Allow()
+	b.Record(false)
+
+	clock.now = clock.now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() probe = %v", err)
+	}
+	b.Record(false)
+
+	var open *OpenError
+	if err := b.Allow(); !errors.As(err, &open) || open.RetryAfter != time.Minute {
+		t.Errorf("Allow() after failed probe = %v, want a fresh cooldown", err)
+	}
+}
+
+func TestBreaker_SuccessResetsFailureCount(t *testing.T) {
+	b, _ := newTestBreaker(2, time.Minute)
+	b.Record(false)
+	b.Record(true)
+	b.Record(false)
+	if got := b.Status().State; got != StateClosed {
+		t.Errorf("state = %s, want closed since failures were not consecutive", got)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var status atomic.Int64
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	b, _ := newTestBreaker(2, time.Minute)
+	client := &http.Client{Transport: b.Transport(nil)}
+	get := func() error {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		return nil
+	}
+
+	// Rate limiting means the upstream is answering, so it is not a failure
+	status.Store(http.StatusTooManyRequests)
+	for i := 0; i < 3; i++ {
+		if err := get(); err != nil {
+			t.Fatalf("GET = %v", err)
+		}
+	}
+	if got := b.Status().State; got != StateClosed {
+		t.Errorf("state after 429s = %s, want closed", got)
+	}
+
+	status.Store(http.StatusBadGateway)
+	_ = get()
+	_ = get()
+	before := requests.Load()
+
+	var open *OpenError
+	if err := get(); !errors.As(err, &open) {
+		t.Fatalf("GET with open circuit = %v, want *OpenError", err)
+	}
+	if requests.Load() != before {
+		t.Error("open circuit should not reach the upstream")
+	}
+}
@@ -0,0 +1,159 @@
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Modes a Cassette runs in
+const (
+	// ModeRecord sends requests upstream and saves every response
+	ModeRecord = "record"
+	// ModeReplay answers requests from saved responses and never touches the network
+	ModeReplay = "replay"
+)
+
+// ErrNotRecorded is returned in replay mode for a request the cassette holds no response for
+var ErrNotRecorded = errors.New("no recorded interaction")
+
+// Cassette records upstream HTTP interactions to a directory, one JSON file per request, and
+// replays them. Requests are keyed by method, URL, and body; headers are not part of the key
+// and request headers are never saved, so tokens stay out of the fixtures.
+type Cassette struct {
+	dir  string
+	mode string
+}
+
+// interaction is the file format of one recorded request and its response
+type interaction struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestBody string      `json:"request_body,omitempty"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        string      `json:"body"`
+}
+
+// New opens a cassette in dir, creating the directory when recording
+func New(dir, mode string) (*Cassette, error) {
+	switch mode {
+	case ModeRecord:
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create cassette dir: %w", err)
+		}
+	case ModeReplay:
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("cassette dir %s is not a readable directory", dir)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported cassette mode %q (valid: %s, %s)", mode, ModeRecord, ModeReplay)
+	}
+	return &Cassette{dir: dir, mode: mode}, nil
+}
+
+// Mode returns ModeRecord or ModeReplay
+func (c *Cassette) Mode() string {
+	return c.mode
+}
+
+// Transport wraps next (http.DefaultTransport when nil) to record or replay every request.
+// In replay mode next is never called.
+func (c *Cassette) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{cassette: c, next: next}
+}
+
+type transport struct {
+	cassette *Cassette
+	next     http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	path := t.cassette.path(req, body)
+
+	if t.cassette.mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w for %s %s", ErrNotRecorded, req.Method, req.URL)
+		}
+		if err != nil {
+			return nil, err
+		}
+		var saved interaction
+		if err := json.Unmarshal(data, &saved); err != nil {
+			return nil, fmt.Errorf("cassette %s: %w", path, err)
+		}
+		return saved.response(req), nil
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	data, _ := json.MarshalIndent(interaction{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		RequestBody: string(body),
+		Status:      resp.StatusCode,
+		Header:      resp.Header,
+		Body:        string(respBody),
+	}, "", "  ")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return nil, fmt.Errorf("record interaction: %w", err)
+	}
+	return resp, nil
+}
+
+// path names the file holding the interaction for a request
+func (c *Cassette) path(req *http.Request, body []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", req.Method, req.URL)
+	h.Write(body)
+	return filepath.Join(c.dir, hex.EncodeToString(h.Sum(nil)[:16])+".json")
+}
+
+// response rebuilds the recorded response for req
+func (i interaction) response(req *http.Request) *http.Response {
+	header := i.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", i.Status, http.StatusText(i.Status)),
+		StatusCode:    i.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(i.Body))),
+		ContentLength: int64(len(i.Body)),
+		Request:       req,
+	}
+}
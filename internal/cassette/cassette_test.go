@@ -0,0 +1,77 @@
+package cassette
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// offline fails every request, standing in for a disabled network
+type offline struct{}
+
+func (offline) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("network disabled")
+}
+
+func TestCassette_RecordThenReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"echo":` + string(body) + `}`))
+	}))
+	dir := t.TempDir()
+
+	recorder, err := New(dir, ModeRecord)
+	if err != nil {
+		t.Fatalf("New(record) error = %v", err)
+	}
+	post := func(client *http.Client, body string) (*http.Response, string, error) {
+		resp, err := client.Post(server.URL+"/v1/query", "application/json", strings.NewReader(body))
+		if err != nil {
+			return nil, "", err
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		return resp, string(data), err
+	}
+	_, recorded, err := post(&http.Client{Transport: recorder.Transport(nil)}, `{"package":"lodash"}`)
+	if err != nil {
+		t.Fatalf("recording request error = %v", err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Fatalf("cassette holds %d files, want 1", len(files))
+	}
+	server.Close()
+
+	player, err := New(dir, ModeReplay)
+	if err != nil {
+		t.Fatalf("New(replay) error = %v", err)
+	}
+	client := &http.Client{Transport: player.Transport(offline{})}
+	resp, replayed, err := post(client, `{"package":"lodash"}`)
+	if err != nil {
+		t.Fatalf("replayed request error = %v", err)
+	}
+	if replayed != recorded || resp.StatusCode != http.StatusCreated || resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("replayed %d %q (%s), want 201 %q (application/json)", resp.StatusCode, replayed, resp.Header.Get("Content-Type"), recorded)
+	}
+
+	// A request that was never recorded fails rather than reaching the network
+	if _, _, err := post(client, `{"package":"express"}`); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("unrecorded request error = %v, want ErrNotRecorded", err)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	if _, err := New(t.TempDir()+"/missing", ModeReplay); err == nil {
+		t.Error("expected an error replaying from a missing directory")
+	}
+	if _, err := New(t.TempDir(), "rewind"); err == nil {
+		t.Error("expected an error for an unsupported mode")
+	}
+}
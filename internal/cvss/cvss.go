@@ -0,0 +1,170 @@
+package cvss
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Qualitative severity ratings from the CVSS v3.1 specification
+const (
+	RatingNone     = "none"
+	RatingLow      = "low"
+	RatingMedium   = "medium"
+	RatingHigh     = "high"
+	RatingCritical = "critical"
+)
+
+var v3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// BaseScoreV3 computes the CVSS v3.0/v3.1 base score for a vector such as
+// "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H".
+func BaseScoreV3(vector string) (float64, error) {
+	parts := strings.Split(strings.TrimSpace(vector), "/")
+	if len(parts) == 0 || !strings.HasPrefix(parts[0], "CVSS:3.") {
+		return 0, fmt.Errorf("not a CVSS v3 vector: %q", vector)
+	}
+
+	metrics := make(map[string]string, len(parts)-1)
+	for _, part := range parts[1:] {
+		key, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return 0, fmt.Errorf("malformed metric %q in %q", part, vector)
+		}
+		metrics[key] = value
+	}
+
+	scope := metrics["S"]
+	if scope != "U" && scope != "C" {
+		return 0, fmt.Errorf("missing or invalid scope in %q", vector)
+	}
+	changed := scope == "C"
+
+	weight := func(metric string) (float64, error) {
+		w, ok := v3Weights[metric][metrics[metric]]
+		if !ok {
+			return 0, fmt.Errorf("missing or invalid %s in %q", metric, vector)
+		}
+		return w, nil
+	}
+
+	var values [6]float64
+	for i, metric := range []string{"AV", "AC", "UI", "C", "I", "A"} {
+		w, err := weight(metric)
+		if err != nil {
+			return 0, err
+		}
+		values[i] = w
+	}
+	av, ac, ui, c, i, a := values[0], values[1], values[2], values[3], values[4], values[5]
+
+	var pr float64
+	switch metrics["PR"] {
+	case "N":
+		pr = 0.85
+	case "L":
+		pr = 0.62
+		if changed {
+			pr = 0.68
+		}
+	case "H":
+		pr = 0.27
+		if changed {
+			pr = 0.5
+		}
+	default:
+		return 0, fmt.Errorf("missing or invalid PR in %q", vector)
+	}
+
+	iss := 1 - (1-c)*(1-i)*(1-a)
+	var impact float64
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	} else {
+		impact = 6.42 * iss
+	}
+	exploitability := 8.22 * av * ac * pr * ui
+
+	if impact <= 0 {
+		return 0, nil
+	}
+	if changed {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), nil
+}
+
+var v2Weights = map[string]map[string]float64{
+	"AV": {"L": 0.395, "A": 0.646, "N": 1.0},
+	"AC": {"H": 0.35, "M": 0.61, "L": 0.71},
+	"Au": {"M": 0.45, "S": 0.56, "N": 0.704},
+	"C":  {"N": 0, "P": 0.275, "C": 0.660},
+	"I":  {"N": 0, "P": 0.275, "C": 0.660},
+	"A":  {"N": 0, "P": 0.275, "C": 0.660},
+}
+
+// BaseScoreV2 computes the CVSS v2 base score for a vector such as "AV:N/AC:L/Au:N/C:P/I:P/A:P".
+// Surrounding parentheses and a "CVSS:2.0/" prefix, as some databases write them, are accepted.
+func BaseScoreV2(vector string) (float64, error) {
+	v := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(vector), "("), ")")
+	v = strings.TrimPrefix(v, "CVSS:2.0/")
+
+	metrics := make(map[string]string)
+	for _, part := range strings.Split(v, "/") {
+		key, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return 0, fmt.Errorf("malformed metric %q in %q", part, vector)
+		}
+		metrics[key] = value
+	}
+
+	var values [6]float64
+	for i, metric := range []string{"AV", "AC", "Au", "C", "I", "A"} {
+		w, ok := v2Weights[metric][metrics[metric]]
+		if !ok {
+			return 0, fmt.Errorf("missing or invalid %s in %q", metric, vector)
+		}
+		values[i] = w
+	}
+	av, ac, au, c, i, a := values[0], values[1], values[2], values[3], values[4], values[5]
+
+	impact := 10.41 * (1 - (1-c)*(1-i)*(1-a))
+	exploitability := 20 * av * ac * au
+	if impact == 0 {
+		return 0, nil
+	}
+	score := (0.6*impact + 0.4*exploitability - 1.5) * 1.176
+	return math.Round(score*10) / 10, nil
+}
+
+// Rating maps a numeric base score to its qualitative severity
+func Rating(score float64) string {
+	switch {
+	case score >= 9.0:
+		return RatingCritical
+	case score >= 7.0:
+		return RatingHigh
+	case score >= 4.0:
+		return RatingMedium
+	case score > 0:
+		return RatingLow
+	default:
+		return RatingNone
+	}
+}
+
+// roundUp implements the CVSS v3.1 Roundup function (smallest one-decimal value >= x)
+func roundUp(x float64) float64 {
+	scaled := int64(math.Round(x * 100000))
+	if scaled%10000 == 0 {
+		return float64(scaled) / 100000
+	}
+	return float64(scaled/10000+1) / 10
+}
@@ -0,0 +1,93 @@
+package cvss
+
+import "testing"
+
+func TestBaseScoreV3(t *testing.T) {
+	tests := []struct {
+		vector string
+		want   float64
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", 10.0},
+		{"CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H", 7.2},
+		{"CVSS:3.0/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N", 6.1},
+		{"CVSS:3.1/AV:L/AC:H/PR:L/UI:N/S:U/C:L/I:N/A:N", 2.5},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", 0.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.vector, func(t *testing.T) {
+			got, err := BaseScoreV3(tt.vector)
+			if err != nil {
+				t.Fatalf("BaseScoreV3() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("BaseScoreV3() = %.1f, want %.1f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaseScoreV3_Invalid(t *testing.T) {
+	for _, vector := range []string{
+		"",
+		"HIGH",
+		"AV:N/AC:L/Au:N/C:P/I:P/A:P",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/C:H/I:H/A:H",
+		"CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+	} {
+		if _, err := BaseScoreV3(vector); err == nil {
+			t.Errorf("BaseScoreV3(%q) expected error", vector)
+		}
+	}
+}
+
+func TestBaseScoreV2(t *testing.T) {
+	tests := []struct {
+		vector string
+		want   float64
+	}{
+		{"AV:N/AC:L/Au:N/C:P/I:P/A:P", 7.5},
+		{"AV:N/AC:L/Au:N/C:C/I:C/A:C", 10.0},
+		{"(AV:N/AC:M/Au:N/C:N/I:P/A:N)", 4.3},
+		{"CVSS:2.0/AV:L/AC:L/Au:N/C:P/I:N/A:N", 2.1},
+		{"AV:N/AC:L/Au:N/C:N/I:N/A:N", 0.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.vector, func(t *testing.T) {
+			got, err := BaseScoreV2(tt.vector)
+			if err != nil {
+				t.Fatalf("BaseScoreV2() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("BaseScoreV2() = %.1f, want %.1f", got, tt.want)
+			}
+		})
+	}
+
+	for _, vector := range []string{"", "HIGH", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "AV:N/AC:L/C:P/I:P/A:P"} {
+		if _, err := BaseScoreV2(vector); err == nil {
+			t.Errorf("BaseScoreV2(%q) expected error", vector)
+		}
+	}
+}
+
+func TestRating(t *testing.T) {
+	tests := map[float64]string{
+		0:    RatingNone,
+		0.1:  RatingLow,
+		3.9:  RatingLow,
+		4.0:  RatingMedium,
+		6.9:  RatingMedium,
+		7.0:  RatingHigh,
+		8.9:  RatingHigh,
+		9.0:  RatingCritical,
+		10.0: RatingCritical,
+	}
+	for score, want := range tests {
+		if got := Rating(score); got != want {
+			t.Errorf("Rating(%.1f) = %s, want %s", score, got, want)
+		}
+	}
+}
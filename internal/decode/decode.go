@@ -0,0 +1,42 @@
+package decode
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// SnippetBytes is how much of a malformed body a MalformedError keeps
+const SnippetBytes = 256
+
+// MalformedError is returned when an upstream answers 200 with a body that is not the JSON
+// expected, as happens when a response is cut off during an incident. It carries the start of
+// the body for debugging and is retryable: a later request usually gets a whole response.
+type MalformedError struct {
+	Upstream string
+	// Snippet is the first SnippetBytes of the body
+	Snippet string
+	Err     error
+}
+
+func (e *MalformedError) Error() string {
+	return fmt.Sprintf("UPSTREAM_UNAVAILABLE: %s returned a malformed response (%v); body starts %q",
+		e.Upstream, e.Err, e.Snippet)
+}
+
+func (e *MalformedError) Unwrap() error {
+	return e.Err
+}
+
+// JSON reads all of r and decodes it into v. A body that cannot be read to the end or does not
+// decode yields a *MalformedError naming upstream.
+func JSON(upstream string, r io.Reader, v any) error {
+	body, err := io.ReadAll(r)
+	if err == nil {
+		err = json.Unmarshal(body, v)
+	}
+	if err != nil {
+		return &MalformedError{Upstream: upstream, Snippet: string(body[:min(len(body), SnippetBytes)]), Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package decode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		Vulns []string `json:"vulns"`
+	}
+	if err := JSON("osv", strings.NewReader(`{"vulns": ["GHSA-1"]}`), &v); err != nil || len(v.Vulns) != 1 {
+		t.Fatalf("JSON() = %v, %v; want one vuln", v, err)
+	}
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"truncated", `{"vulns": ["GHSA-1", "GHS`},
+		{"html error page", `<html><body>502 Bad Gateway</body></html>`},
+		{"empty", ``},
+		{"oversized", `{"vulns": ["` + strings.Repeat("x", 2*SnippetBytes)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := JSON("osv", strings.NewReader(tt.body), &v)
+			var malformed *MalformedError
+			if !errors.As(err, &malformed) {
+				t.Fatalf("JSON() error = %v, want a *MalformedError", err)
+			}
+			if malformed.Upstream != "osv" || !strings.HasPrefix(tt.body, malformed.Snippet) || len(malformed.Snippet) > SnippetBytes {
+				t.Errorf("MalformedError = %+v, want osv and the first %d bytes of the body", malformed, SnippetBytes)
+			}
+			if !strings.HasPrefix(err.Error(), "UPSTREAM_UNAVAILABLE: osv") {
+				t.Errorf("Error() = %q, want it to start UPSTREAM_UNAVAILABLE: osv", err)
+			}
+		})
+	}
+}
+
+func TestJSON_ReadFailure(t *testing.T) {
+	// A connection dropped mid-body keeps what arrived before it
+	r := iotest.TimeoutReader(iotest.OneByteReader(strings.NewReader(`{"vulns"`)))
+	var v map[string]any
+	err := JSON("deps.dev", r, &v)
+	var malformed *MalformedError
+	if !errors.As(err, &malformed) || malformed.Snippet != "{" || !errors.Is(err, iotest.ErrTimeout) {
+		t.Errorf("JSON() error = %v, want a *MalformedError wrapping the read error after {", err)
+	}
+}
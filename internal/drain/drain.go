@@ -0,0 +1,76 @@
+package drain
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultGrace is how long shutdown waits for in-flight tool calls
+const DefaultGrace = 10 * time.Second
+
+// Tracker counts in-flight tool calls so shutdown can wait for them. Once draining starts,
+// new calls are rejected.
+type Tracker struct {
+	mu       sync.Mutex
+	draining bool
+	inflight sync.WaitGroup
+}
+
+// New creates a tracker that accepts calls until Drain is called
+func New() *Tracker {
+	return &Tracker{}
+}
+
+// begin registers a call, or reports false once draining has started
+func (t *Tracker) begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return false
+	}
+	t.inflight.Add(1)
+	return true
+}
+
+// Drain stops accepting calls and waits for in-flight ones to finish. It returns ctx's error
+// if ctx is done first, leaving the remaining calls to be cut off by the transport closing.
+func (t *Tracker) Drain(ctx context.Context) error {
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Middleware tracks every tool call in t and rejects calls that arrive while t drains
+func Middleware(t *Tracker) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+			if !t.begin() {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{Text: "Server is shutting down; retry against another instance"}},
+					IsError: true,
+					Meta:    mcp.Meta{"retryable": true},
+				}, nil
+			}
+			defer t.inflight.Done()
+			return next(ctx, method, req)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package drain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// blockingHandler answers tool calls once release is closed, signalling started first
+func blockingHandler(started chan<- struct{}, release <-chan struct{}) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		started <- struct{}{}
+		<-release
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil
+	}
+}
+
+func TestDrain_WaitsForInflightCalls(t *testing.T) {
+	tracker := New()
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	handler := Middleware(tracker)(blockingHandler(started, release))
+
+	results := make(chan mcp.Result, 1)
+	go func() {
+		result, _ := handler(context.Background(), "tools/call", &mcp.CallToolRequest{})
+		results <- result
+	}()
+	<-started
+
+	drained := make(chan error, 1)
+	go func() { drained <- tracker.Drain(context.Background()) }()
+
+	// Wait for draining to begin, then check that new calls are turned away
+	deadline := time.Now().Add(time.Second)
+	for {
+		tracker.mu.Lock()
+		draining := tracker.draining
+		tracker.mu.Unlock()
+		if draining {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Drain() never started draining")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	rejected, err := handler(context.Background(), "tools/call", &mcp.CallToolRequest{})
+	if result, ok := rejected.(*mcp.CallToolResult); err != nil || !ok || !result.IsError || result.Meta["retryable"] != true {
+		t.Errorf("call while draining = %+v, %v; want a retryable error result", rejected, err)
+	}
+
+	select {
+	case err := <-drained:
+		t.Fatalf("Drain() = %v before the in-flight call finished", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-drained; err != nil {
+		t.Errorf("Drain() error = %v", err)
+	}
+	if result := (<-results).(*mcp.CallToolResult); result.IsError {
+		t.Errorf("in-flight call result = %+v, want success", result)
+	}
+}
+
+func TestDrain_GraceExpires(t *testing.T) {
+	tracker := New()
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	defer close(release)
+	handler := Middleware(tracker)(blockingHandler(started, release))
+	go func() { _, _ = handler(context.Background(), "tools/call", &mcp.CallToolRequest{}) }()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := tracker.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Drain() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// Other methods are never tracked or rejected
+	passthrough := Middleware(tracker)(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		return &mcp.ListToolsResult{}, nil
+	})
+	if result, err := passthrough(context.Background(), "tools/list", &mcp.ListToolsRequest{}); err != nil || result == nil {
+		t.Errorf("tools/list while draining = %v, %v; want it passed through", result, err)
+	}
+}
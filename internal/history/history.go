@@ -0,0 +1,257 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultCapacity is how many tool calls a Recorder keeps when none is configured
+const DefaultCapacity = 100
+
+// Cache outcomes reported in Entry.Cache
+const (
+	CacheHit     = "hit"
+	CacheMiss    = "miss"
+	CachePartial = "partial"
+)
+
+// maxInputLength caps recorded string inputs; longer values such as lockfile contents are
+// replaced by their size
+const maxInputLength = 200
+
+// maxSummaryLength caps the recorded error message of a failed call
+const maxSummaryLength = 200
+
+// Entry is one recorded tool call
+type Entry struct {
+	Tool      string         `json:"tool"`
+	Inputs    map[string]any `json:"inputs,omitempty"`
+	Summary   string         `json:"summary"`
+	IsError   bool           `json:"is_error,omitempty"`
+	Cache     string         `json:"cache,omitempty"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+// Recorder keeps the most recent tool calls in a fixed-size ring buffer. It is safe for
+// concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+}
+
+// New creates a Recorder holding at most capacity entries; a non-positive capacity uses
+// DefaultCapacity
+func New(capacity int) *Recorder {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Recorder{entries: make([]Entry, capacity)}
+}
+
+// Record adds an entry, evicting the oldest one once the buffer is full
+func (r *Recorder) Record(e Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Entries returns the recorded calls, newest first
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := r.next
+	if r.full {
+		n = len(r.entries)
+	}
+	out := make([]Entry, 0, n)
+	for i := 1; i <= n; i++ {
+		out = append(out, r.entries[(r.next-i+len(r.entries))%len(r.entries)])
+	}
+	return out
+}
+
+// Capacity returns the maximum number of entries kept
+func (r *Recorder) Capacity() int {
+	return len(r.entries)
+}
+
+type cacheKey struct{}
+
+// cacheStats counts the response-cache lookups made while serving one tool call
+type cacheStats struct {
+	mu     sync.Mutex
+	hits   int
+	misses int
+}
+
+// NoteCache records a response-cache lookup for the tool call in ctx. Outside a recorded
+// call it does nothing.
+func NoteCache(ctx context.Context, hit bool) {
+	stats, ok := ctx.Value(cacheKey{}).(*cacheStats)
+	if !ok {
+		return
+	}
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	if hit {
+		stats.hits++
+	} else {
+		stats.misses++
+	}
+}
+
+// CacheOutcome reports the response-cache outcome so far of the recorded tool call in ctx, as
+// Entry.Cache does; "" outside a recorded call or before any lookup
+func CacheOutcome(ctx context.Context) string {
+	stats, ok := ctx.Value(cacheKey{}).(*cacheStats)
+	if !ok {
+		return ""
+	}
+	return stats.outcome()
+}
+
+// outcome reports hit when every lookup hit, miss when none did, and partial otherwise
+func (s *cacheStats) outcome() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case s.hits == 0 && s.misses == 0:
+		return ""
+	case s.misses == 0:
+		return CacheHit
+	case s.hits == 0:
+		return CacheMiss
+	default:
+		return CachePartial
+	}
+}
+
+// Middleware records every tool call in r once its handler returns
+func Middleware(r *Recorder) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if method != "tools/call" || !ok || call.Params == nil {
+				return next(ctx, method, req)
+			}
+
+			stats := &cacheStats{}
+			result, err := next(context.WithValue(ctx, cacheKey{}, stats), method, req)
+
+			entry := Entry{
+				Tool:      call.Params.Name,
+				Inputs:    inputs(call.Params.Arguments),
+				Cache:     stats.outcome(),
+				Timestamp: time.Now().UTC(),
+			}
+			entry.Summary, entry.IsError = summarize(result, err)
+			r.Record(entry)
+			return result, err
+		}
+	}
+}
+
+// inputs keeps the scalar arguments of a call. Long strings and lists are reduced to their size.
+func inputs(raw json.RawMessage) map[string]any {
+	var args map[string]any
+	if err := json.Unmarshal(raw, &args); err != nil || len(args) == 0 {
+		return nil
+	}
+	for key, value := range args {
+		switch v := value.(type) {
+		case string:
+			if len(v) > maxInputLength {
+				args[key] = fmt.Sprintf("<%d bytes>", len(v))
+			}
+		case []any:
+			args[key] = fmt.Sprintf("<%d items>", len(v))
+		case map[string]any:
+			args[key] = fmt.Sprintf("<%d fields>", len(v))
+		}
+	}
+	return args
+}
+
+// summarize describes a call's outcome: the error message of a failed call, otherwise the
+// top-level counts of the JSON result (e.g. "vulnerability_count=3")
+func summarize(result mcp.Result, err error) (string, bool) {
+	if err != nil {
+		return truncate(err.Error()), true
+	}
+	res, ok := result.(*mcp.CallToolResult)
+	if !ok || res == nil || len(res.Content) == 0 {
+		return "ok", false
+	}
+	text, ok := res.Content[0].(*mcp.TextContent)
+	if !ok {
+		return "ok", false
+	}
+	if res.IsError {
+		return truncate(text.Text), true
+	}
+
+	var counts []string
+	for key, n := range ResultCounts(result) {
+		counts = append(counts, fmt.Sprintf("%s=%d", key, n))
+	}
+	if len(counts) == 0 {
+		return "ok", false
+	}
+	sort.Strings(counts)
+	return strings.Join(counts, ", "), false
+}
+
+// ResultCounts returns the top-level counts of a successful tool call's JSON result, the fields
+// named *_count (e.g. vulnerability_count), or nil when it has none
+func ResultCounts(result mcp.Result) map[string]int64 {
+	res, ok := result.(*mcp.CallToolResult)
+	if !ok || res == nil || res.IsError || len(res.Content) == 0 {
+		return nil
+	}
+	text, ok := res.Content[0].(*mcp.TextContent)
+	if !ok {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal([]byte(text.Text), &fields) != nil {
+		return nil
+	}
+	var counts map[string]int64
+	for key, value := range fields {
+		if !strings.HasSuffix(key, "_count") {
+			continue
+		}
+		var n json.Number
+		if json.Unmarshal(value, &n) != nil {
+			continue
+		}
+		if v, err := n.Int64(); err == nil {
+			if counts == nil {
+				counts = make(map[string]int64)
+			}
+			counts[key] = v
+		}
+	}
+	return counts
+}
+
+func truncate(s string) string {
+	if len(s) > maxSummaryLength {
+		return s[:maxSummaryLength] + "..."
+	}
+	return s
+}
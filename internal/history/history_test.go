@@ -0,0 +1,88 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestRecorder_NewestFirstAndBounded(t *testing.T) {
+	r := New(3)
+	for _, tool := range []string{"a", "b", "c", "d"} {
+		r.Record(Entry{Tool: tool})
+	}
+
+	entries := r.Entries()
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Tool)
+	}
+	want := []string{"d", "c", "b"}
+	if len(got) != len(want) {
+		t.Fatalf("Entries() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Entries() = %v, want %v", got, want)
+		}
+	}
+
+	if New(0).Capacity() != DefaultCapacity {
+		t.Errorf("New(0).Capacity() = %d, want %d", New(0).Capacity(), DefaultCapacity)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	r := New(10)
+	handler := Middleware(r)(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		NoteCache(ctx, true)
+		NoteCache(ctx, false)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: `{"package":"lodash","vulnerability_count":3}`}},
+		}, nil
+	})
+
+	args, _ := json.Marshal(map[string]any{
+		"ecosystem": "npm",
+		"package":   "lodash",
+		"versions":  []string{"1.0.0", "2.0.0"},
+	})
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "deps.vulns", Arguments: args}}
+	if _, err := handler(context.Background(), "tools/call", req); err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+
+	entries := r.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("recorded %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Tool != "deps.vulns" || e.Inputs["package"] != "lodash" || e.Inputs["versions"] != "<2 items>" {
+		t.Errorf("entry = %+v", e)
+	}
+	if e.Summary != "vulnerability_count=3" || e.IsError {
+		t.Errorf("summary = %q (error %v), want vulnerability_count=3", e.Summary, e.IsError)
+	}
+	if e.Cache != CachePartial {
+		t.Errorf("cache = %q, want %q", e.Cache, CachePartial)
+	}
+	if e.Timestamp.IsZero() {
+		t.Error("timestamp not set")
+	}
+}
+
+func TestSummarize_Errors(t *testing.T) {
+	if s, isErr := summarize(nil, errors.New("boom")); s != "boom" || !isErr {
+		t.Errorf("summarize(err) = %q, %v", s, isErr)
+	}
+	res := &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: "Invalid input: bad"}}}
+	if s, isErr := summarize(res, nil); s != "Invalid input: bad" || !isErr {
+		t.Errorf("summarize(error result) = %q, %v", s, isErr)
+	}
+
+	// Outside a recorded call NoteCache is a no-op
+	NoteCache(context.Background(), true)
+}
@@ -0,0 +1,90 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// EcosystemCratesIO is the OSV ecosystem name for Rust crates
+const EcosystemCratesIO = "crates.io"
+
+// ParseCargoLock extracts the registry [[package]] entries from a Cargo.lock file.
+// Packages without a source are workspace members (including the root crate), and
+// git or path sources are not published crates; both are skipped since registry
+// advisories do not apply to them.
+func ParseCargoLock(content []byte) ([]Dependency, error) {
+	var (
+		deps    []Dependency
+		current map[string]string
+		inPkg   bool
+	)
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		if current["name"] != "" && current["version"] != "" && strings.HasPrefix(current["source"], "registry+") {
+			deps = append(deps, Dependency{
+				Name:      current["name"],
+				Version:   current["version"],
+				Ecosystem: EcosystemCratesIO,
+			})
+		}
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			flush()
+			inPkg = line == "[[package]]"
+			if inPkg {
+				current = make(map[string]string)
+			}
+			continue
+		}
+
+		if !inPkg {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			// Continuation lines of multi-line arrays (e.g. dependencies)
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, "\"") {
+			unquoted, err := unquoteTOML(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			current[key] = unquoted
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	return deps, nil
+}
+
+// unquoteTOML returns the contents of a basic TOML string
+func unquoteTOML(value string) (string, error) {
+	end := strings.Index(value[1:], "\"")
+	if end < 0 {
+		return "", fmt.Errorf("unterminated string %s", value)
+	}
+	return value[1 : end+1], nil
+}
@@ -0,0 +1,61 @@
+package manifest
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseCargoLock(t *testing.T) {
+	content, err := os.ReadFile("testdata/Cargo.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	deps, err := ParseCargoLock(content)
+	if err != nil {
+		t.Fatalf("ParseCargoLock() error = %v", err)
+	}
+
+	want := map[string]string{
+		"serde":    "1.0.188",
+		"smallvec": "1.6.0",
+	}
+	if len(deps) != len(want) {
+		t.Fatalf("got %d dependencies, want %d: %+v", len(deps), len(want), deps)
+	}
+
+	for _, dep := range deps {
+		version, ok := want[dep.Name]
+		if !ok {
+			t.Errorf("unexpected dependency %s (workspace members and git sources should be skipped)", dep.Name)
+			continue
+		}
+		if dep.Version != version {
+			t.Errorf("%s version = %s, want %s", dep.Name, dep.Version, version)
+		}
+		if dep.Ecosystem != EcosystemCratesIO {
+			t.Errorf("%s ecosystem = %s, want %s", dep.Name, dep.Ecosystem, EcosystemCratesIO)
+		}
+	}
+}
+
+func TestParse_UnsupportedFormat(t *testing.T) {
+	if _, err := Parse("unknown.txt", []byte(""), Options{}); err == nil {
+		t.Error("expected error for unsupported manifest")
+	}
+}
+
+func TestParse_DetectsCargoLock(t *testing.T) {
+	content, err := os.ReadFile("testdata/Cargo.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	m, err := Parse("path/to/Cargo.lock", content, Options{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if m.Format != FormatCargoLock {
+		t.Errorf("Format = %s, want %s", m.Format, FormatCargoLock)
+	}
+}
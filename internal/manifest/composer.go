@@ -0,0 +1,65 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/packagist"
+)
+
+// EcosystemPackagist is the OSV ecosystem name for Composer packages
+const EcosystemPackagist = "Packagist"
+
+// ScopeDev marks dependencies only installed for development
+const ScopeDev = "dev"
+
+type composerLock struct {
+	Packages    []composerPackage `json:"packages"`
+	PackagesDev []composerPackage `json:"packages-dev"`
+}
+
+type composerPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ParseComposerLock extracts the locked packages of a composer.lock file.
+// Entries from packages-dev are scoped "dev" and skipped when RuntimeOnly is set.
+// Packages locked to a branch (e.g. "dev-main") have no release version and are
+// returned as unresolved.
+func ParseComposerLock(content []byte, opts Options) (resolved, unresolved []Dependency, err error) {
+	var lock composerLock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, nil, fmt.Errorf("decode json: %w", err)
+	}
+
+	add := func(pkgs []composerPackage, scope string) {
+		for _, p := range pkgs {
+			name := strings.ToLower(strings.TrimSpace(p.Name))
+			if name == "" {
+				continue
+			}
+			dep := Dependency{
+				Name:      name,
+				Version:   packagist.TrimVersionPrefix(strings.TrimSpace(p.Version)),
+				Ecosystem: EcosystemPackagist,
+				Scope:     scope,
+			}
+			if strings.HasPrefix(dep.Version, "dev-") || strings.HasSuffix(dep.Version, "-dev") {
+				dep.Reason = fmt.Sprintf("locked to branch %s", dep.Version)
+				dep.Version = ""
+				unresolved = append(unresolved, dep)
+				continue
+			}
+			resolved = append(resolved, dep)
+		}
+	}
+
+	add(lock.Packages, "")
+	if !opts.RuntimeOnly {
+		add(lock.PackagesDev, ScopeDev)
+	}
+
+	return resolved, unresolved, nil
+}
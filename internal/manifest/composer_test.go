@@ -0,0 +1,62 @@
+package manifest
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseComposerLock(t *testing.T) {
+	content, err := os.ReadFile("testdata/composer.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	deps, unresolved, err := ParseComposerLock(content, Options{})
+	if err != nil {
+		t.Fatalf("ParseComposerLock() error = %v", err)
+	}
+
+	want := map[string]string{
+		"guzzlehttp/psr7": "2.4.1",
+		"symfony/console": "6.3.0",
+		"phpunit/phpunit": "10.2.2",
+	}
+	if len(deps) != len(want) {
+		t.Fatalf("got %d dependencies, want %d: %+v", len(deps), len(want), deps)
+	}
+	for _, dep := range deps {
+		if dep.Version != want[dep.Name] {
+			t.Errorf("%s version = %s, want %s", dep.Name, dep.Version, want[dep.Name])
+		}
+		if dep.Ecosystem != EcosystemPackagist {
+			t.Errorf("%s ecosystem = %s, want %s", dep.Name, dep.Ecosystem, EcosystemPackagist)
+		}
+		if dep.Name == "phpunit/phpunit" && dep.Scope != ScopeDev {
+			t.Errorf("phpunit scope = %q, want %q", dep.Scope, ScopeDev)
+		}
+	}
+
+	if len(unresolved) != 1 || unresolved[0].Name != "acme/internal-tools" {
+		t.Errorf("unresolved = %+v, want acme/internal-tools", unresolved)
+	}
+}
+
+func TestParseComposerLock_RuntimeOnly(t *testing.T) {
+	content, err := os.ReadFile("testdata/composer.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	deps, _, err := ParseComposerLock(content, Options{RuntimeOnly: true})
+	if err != nil {
+		t.Fatalf("ParseComposerLock() error = %v", err)
+	}
+	for _, dep := range deps {
+		if dep.Scope == ScopeDev {
+			t.Errorf("runtime_only should skip dev dependency %s", dep.Name)
+		}
+	}
+	if len(deps) != 2 {
+		t.Errorf("got %d dependencies, want 2", len(deps))
+	}
+}
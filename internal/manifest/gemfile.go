@@ -0,0 +1,80 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// EcosystemRubyGems is the OSV ecosystem name for Ruby gems
+const EcosystemRubyGems = "RubyGems"
+
+// ParseGemfileLock extracts the resolved gems listed under GEM/specs in a Gemfile.lock.
+// Only the four-space-indented spec lines are dependencies; the deeper-indented
+// lines beneath them are version constraints and are ignored. Gems sourced from
+// GIT or PATH sections are skipped since they are not fetched from a registry.
+func ParseGemfileLock(content []byte) ([]Dependency, error) {
+	var (
+		deps    []Dependency
+		section string
+		inSpecs bool
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" {
+			continue
+		}
+
+		// Unindented lines start a new section (GEM, GIT, PATH, PLATFORMS, ...)
+		if !strings.HasPrefix(line, " ") {
+			section = line
+			inSpecs = false
+			continue
+		}
+		if section != "GEM" {
+			continue
+		}
+
+		if line == "  specs:" {
+			inSpecs = true
+			continue
+		}
+		if !inSpecs || !strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "     ") {
+			continue
+		}
+
+		name, version, ok := parseGemSpec(strings.TrimSpace(line))
+		if !ok {
+			continue
+		}
+		deps = append(deps, Dependency{
+			Name:      name,
+			Version:   version,
+			Ecosystem: EcosystemRubyGems,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return deps, nil
+}
+
+// parseGemSpec splits "nokogiri (1.13.3-x86_64-linux)" into name and version,
+// dropping any platform suffix since advisories are published per version
+func parseGemSpec(spec string) (string, string, bool) {
+	name, rest, ok := strings.Cut(spec, " (")
+	if !ok || !strings.HasSuffix(rest, ")") {
+		return "", "", false
+	}
+	version := strings.TrimSuffix(rest, ")")
+	if i := strings.Index(version, "-"); i > 0 {
+		version = version[:i]
+	}
+	if name == "" || version == "" {
+		return "", "", false
+	}
+	return name, version, true
+}
@@ -0,0 +1,60 @@
+package manifest
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseGemfileLock(t *testing.T) {
+	content, err := os.ReadFile("testdata/Gemfile.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	deps, err := ParseGemfileLock(content)
+	if err != nil {
+		t.Fatalf("ParseGemfileLock() error = %v", err)
+	}
+
+	want := map[string]string{
+		"mini_portile2": "2.8.0",
+		"nokogiri":      "1.13.3",
+		"racc":          "1.6.0",
+		"rack":          "2.2.3",
+	}
+	if len(deps) != len(want) {
+		t.Fatalf("got %d dependencies, want %d: %+v", len(deps), len(want), deps)
+	}
+
+	for _, dep := range deps {
+		version, ok := want[dep.Name]
+		if !ok {
+			t.Errorf("unexpected dependency %s (GIT gems and constraints should be skipped)", dep.Name)
+			continue
+		}
+		if dep.Version != version {
+			t.Errorf("%s version = %s, want %s", dep.Name, dep.Version, version)
+		}
+		if dep.Ecosystem != EcosystemRubyGems {
+			t.Errorf("%s ecosystem = %s, want %s", dep.Name, dep.Ecosystem, EcosystemRubyGems)
+		}
+	}
+}
+
+func TestParse_DetectsGemfileLock(t *testing.T) {
+	content, err := os.ReadFile("testdata/Gemfile.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	m, err := Parse("app/Gemfile.lock", content, Options{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if m.Format != FormatGemfileLock {
+		t.Errorf("Format = %s, want %s", m.Format, FormatGemfileLock)
+	}
+	if len(m.Dependencies) != 4 {
+		t.Errorf("got %d dependencies, want 4", len(m.Dependencies))
+	}
+}
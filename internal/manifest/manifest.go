@@ -0,0 +1,79 @@
+package manifest
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Manifest formats recognized by Parse
+const (
+	FormatCargoLock   = "Cargo.lock"
+	FormatPOM         = "pom.xml"
+	FormatGemfileLock = "Gemfile.lock"
+	FormatComposer    = "composer.lock"
+	FormatYarnLock    = "yarn.lock"
+)
+
+// Dependency is a single package pinned by a manifest or lockfile
+type Dependency struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Ecosystem string `json:"ecosystem"`
+	Scope     string `json:"scope,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// Options controls which dependencies Parse returns
+type Options struct {
+	// RuntimeOnly skips test, provided, and dev scoped dependencies where the format records scope
+	RuntimeOnly bool `json:"runtime_only,omitempty"`
+}
+
+// Manifest is the parsed result of a dependency file
+type Manifest struct {
+	Format       string       `json:"format"`
+	Dependencies []Dependency `json:"dependencies"`
+	// Unresolved holds dependencies that could not be pinned to a concrete version
+	Unresolved []Dependency `json:"unresolved,omitempty"`
+}
+
+// Parse detects the manifest format from its filename and extracts dependencies
+// Example: manifest.Parse("Cargo.lock", content, manifest.Options{})
+func Parse(filename string, content []byte, opts Options) (*Manifest, error) {
+	base := filepath.Base(strings.TrimSpace(filename))
+
+	var (
+		deps       []Dependency
+		unresolved []Dependency
+		err        error
+	)
+	switch base {
+	case FormatCargoLock:
+		deps, err = ParseCargoLock(content)
+	case FormatPOM:
+		deps, unresolved, err = ParsePOM(content, opts)
+	case FormatGemfileLock:
+		deps, err = ParseGemfileLock(content)
+	case FormatComposer:
+		deps, unresolved, err = ParseComposerLock(content, opts)
+	case FormatYarnLock:
+		deps, err = ParseYarnLock(content)
+	default:
+		return nil, fmt.Errorf("unsupported manifest %q (supported: %s)", base, strings.Join(SupportedFormats(), ", "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", base, err)
+	}
+
+	return &Manifest{
+		Format:       base,
+		Dependencies: deps,
+		Unresolved:   unresolved,
+	}, nil
+}
+
+// SupportedFormats lists the manifest filenames Parse understands
+func SupportedFormats() []string {
+	return []string{FormatCargoLock, FormatPOM, FormatGemfileLock, FormatComposer, FormatYarnLock}
+}
@@ -0,0 +1,130 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// EcosystemMaven is the OSV ecosystem name for Maven artifacts
+const EcosystemMaven = "Maven"
+
+// maxPropertyDepth bounds nested ${property} substitution to guard against cycles
+const maxPropertyDepth = 10
+
+var propertyRef = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+type pomProject struct {
+	GroupID      string          `xml:"groupId"`
+	ArtifactID   string          `xml:"artifactId"`
+	Version      string          `xml:"version"`
+	Parent       pomCoordinates  `xml:"parent"`
+	Properties   pomProperties   `xml:"properties"`
+	Dependencies []pomDependency `xml:"dependencies>dependency"`
+}
+
+type pomCoordinates struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+type pomProperties struct {
+	Entries []pomProperty `xml:",any"`
+}
+
+type pomProperty struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+}
+
+// ParsePOM extracts the direct <dependency> entries of a pom.xml.
+// ${property} references are resolved from <properties> and the project's own
+// coordinates. Dependencies whose version is missing (inherited from a parent
+// or BOM) or references an undefined property are returned as unresolved.
+func ParsePOM(content []byte, opts Options) (resolved, unresolved []Dependency, err error) {
+	var project pomProject
+	if err := xml.NewDecoder(bytes.NewReader(content)).Decode(&project); err != nil {
+		return nil, nil, fmt.Errorf("decode xml: %w", err)
+	}
+
+	props := map[string]string{
+		"project.groupId":        firstNonEmpty(project.GroupID, project.Parent.GroupID),
+		"project.artifactId":     project.ArtifactID,
+		"project.version":        firstNonEmpty(project.Version, project.Parent.Version),
+		"project.parent.version": project.Parent.Version,
+		"project.parent.groupId": project.Parent.GroupID,
+	}
+	props["pom.version"] = props["project.version"]
+	props["version"] = props["project.version"]
+	for _, p := range project.Properties.Entries {
+		props[p.XMLName.Local] = strings.TrimSpace(p.Value)
+	}
+
+	for _, d := range project.Dependencies {
+		scope := strings.TrimSpace(d.Scope)
+		if opts.RuntimeOnly && (scope == "test" || scope == "provided") {
+			continue
+		}
+
+		groupID := resolveProperties(strings.TrimSpace(d.GroupID), props)
+		artifactID := resolveProperties(strings.TrimSpace(d.ArtifactID), props)
+		version := resolveProperties(strings.TrimSpace(d.Version), props)
+
+		dep := Dependency{
+			Name:      groupID + ":" + artifactID,
+			Version:   version,
+			Ecosystem: EcosystemMaven,
+			Scope:     scope,
+		}
+
+		switch {
+		case version == "":
+			dep.Reason = "version inherited from parent or BOM"
+			unresolved = append(unresolved, dep)
+		case strings.Contains(dep.Name+version, "${"):
+			dep.Reason = "undefined property reference"
+			unresolved = append(unresolved, dep)
+		default:
+			resolved = append(resolved, dep)
+		}
+	}
+
+	return resolved, unresolved, nil
+}
+
+// resolveProperties substitutes ${name} references, leaving unknown ones intact
+func resolveProperties(value string, props map[string]string) string {
+	for i := 0; i < maxPropertyDepth && strings.Contains(value, "${"); i++ {
+		next := propertyRef.ReplaceAllStringFunc(value, func(ref string) string {
+			name := ref[2 : len(ref)-1]
+			if v, ok := props[name]; ok && v != "" {
+				return v
+			}
+			return ref
+		})
+		if next == value {
+			break
+		}
+		value = next
+	}
+	return value
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
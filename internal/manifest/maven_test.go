@@ -0,0 +1,69 @@
+package manifest
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParsePOM(t *testing.T) {
+	content, err := os.ReadFile("testdata/pom.xml")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	deps, unresolved, err := ParsePOM(content, Options{})
+	if err != nil {
+		t.Fatalf("ParsePOM() error = %v", err)
+	}
+
+	want := map[string]string{
+		"org.apache.logging.log4j:log4j-core":         "2.14.1",
+		"com.fasterxml.jackson.core:jackson-databind": "2.12.1",
+		"com.example:sibling-module":                  "1.0.0",
+		"junit:junit":                                 "4.12",
+	}
+	if len(deps) != len(want) {
+		t.Fatalf("got %d resolved dependencies, want %d: %+v", len(deps), len(want), deps)
+	}
+	for _, dep := range deps {
+		if want[dep.Name] != dep.Version {
+			t.Errorf("%s version = %q, want %q", dep.Name, dep.Version, want[dep.Name])
+		}
+		if dep.Ecosystem != EcosystemMaven {
+			t.Errorf("%s ecosystem = %s, want %s", dep.Name, dep.Ecosystem, EcosystemMaven)
+		}
+	}
+
+	if len(unresolved) != 2 {
+		t.Fatalf("got %d unresolved dependencies, want 2: %+v", len(unresolved), unresolved)
+	}
+	for _, dep := range unresolved {
+		if dep.Reason == "" {
+			t.Errorf("unresolved dependency %s has no reason", dep.Name)
+		}
+	}
+}
+
+func TestParsePOM_RuntimeOnly(t *testing.T) {
+	content, err := os.ReadFile("testdata/pom.xml")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	deps, unresolved, err := ParsePOM(content, Options{RuntimeOnly: true})
+	if err != nil {
+		t.Fatalf("ParsePOM() error = %v", err)
+	}
+
+	for _, dep := range append(deps, unresolved...) {
+		if dep.Scope == "test" || dep.Scope == "provided" {
+			t.Errorf("runtime_only should skip %s (scope %s)", dep.Name, dep.Scope)
+		}
+	}
+	if len(deps) != 3 {
+		t.Errorf("got %d resolved runtime dependencies, want 3", len(deps))
+	}
+	if len(unresolved) != 1 {
+		t.Errorf("got %d unresolved runtime dependencies, want 1", len(unresolved))
+	}
+}
@@ -0,0 +1,189 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EcosystemNPM is the OSV ecosystem name for npm packages
+const EcosystemNPM = "npm"
+
+// ParseYarnLock extracts the resolved packages of a yarn.lock file, in either the classic v1
+// text format or the YAML format of Yarn 2 and later (recognized by its __metadata entry).
+// Entries resolved from git, a local path, or a workspace are skipped since registry
+// advisories do not apply to them. yarn.lock records no dev/runtime distinction.
+func ParseYarnLock(content []byte) ([]Dependency, error) {
+	var (
+		deps []Dependency
+		err  error
+	)
+	if bytes.Contains(content, []byte("\n__metadata:")) || bytes.HasPrefix(content, []byte("__metadata:")) {
+		deps, err = parseYarnBerryLock(content)
+	} else {
+		deps, err = parseYarnClassicLock(content)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Aliases can resolve two entries to the same package version
+	seen := make(map[string]bool, len(deps))
+	unique := deps[:0]
+	for _, dep := range deps {
+		key := dep.Name + "@" + dep.Version
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, dep)
+		}
+	}
+	return unique, nil
+}
+
+// parseYarnClassicLock reads the v1 format, where each entry starts with an unindented line
+// listing every requested range that resolved to it ("a@^1.0.0, a@^1.1.0:") and carries the
+// resolved version on an indented `version "1.1.2"` line
+func parseYarnClassicLock(content []byte) ([]Dependency, error) {
+	var (
+		deps    []Dependency
+		name    string
+		inEntry bool
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Unindented lines start a new entry
+		if !strings.HasPrefix(line, " ") {
+			if !strings.HasSuffix(line, ":") {
+				return nil, fmt.Errorf("line %d: malformed entry %q", lineNo, line)
+			}
+			name, inEntry = yarnEntryName(strings.Split(strings.TrimSuffix(line, ":"), ","))
+			continue
+		}
+		if !inEntry || strings.HasPrefix(line, "   ") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok || key != "version" {
+			continue
+		}
+		version := unquoteYarn(value)
+		if version == "" {
+			return nil, fmt.Errorf("line %d: empty version for %s", lineNo, name)
+		}
+		deps = append(deps, Dependency{
+			Name:      name,
+			Version:   version,
+			Ecosystem: EcosystemNPM,
+		})
+		inEntry = false
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return deps, nil
+}
+
+// yarnBerryEntry is one package entry of a Yarn 2+ lockfile
+type yarnBerryEntry struct {
+	Version    string `yaml:"version"`
+	Resolution string `yaml:"resolution"`
+}
+
+// parseYarnBerryLock reads the YAML format, where each entry's resolution names the protocol it
+// was fetched with ("lodash@npm:4.17.20"); only npm resolutions are registry packages
+func parseYarnBerryLock(content []byte) ([]Dependency, error) {
+	var entries map[string]yaml.Node
+	if err := yaml.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("decode yaml: %w", err)
+	}
+
+	var deps []Dependency
+	for key, node := range entries {
+		if key == "__metadata" {
+			continue
+		}
+		var entry yarnBerryEntry
+		if err := node.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("entry %q: %w", key, err)
+		}
+		name, ref, ok := splitYarnSpec(entry.Resolution)
+		if !ok || !strings.HasPrefix(ref, "npm:") || entry.Version == "" {
+			continue
+		}
+		deps = append(deps, Dependency{
+			Name:      name,
+			Version:   entry.Version,
+			Ecosystem: EcosystemNPM,
+		})
+	}
+
+	// Map iteration is random; the lockfile orders entries by name
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].Name != deps[j].Name {
+			return deps[i].Name < deps[j].Name
+		}
+		return deps[i].Version < deps[j].Version
+	})
+	return deps, nil
+}
+
+// yarnEntryName returns the package an entry's requested ranges resolve to, following npm:
+// aliases to the real package. It reports false when a range is not a registry range.
+func yarnEntryName(specs []string) (string, bool) {
+	name := ""
+	for _, spec := range specs {
+		specName, rng, ok := splitYarnSpec(unquoteYarn(strings.TrimSpace(spec)))
+		if !ok {
+			return "", false
+		}
+		// "alias@npm:real@^1.0.0" installs real under another name
+		if target, isAlias := strings.CutPrefix(rng, "npm:"); isAlias {
+			if realName, _, ok := splitYarnSpec(target); ok {
+				specName = realName
+			}
+		} else if strings.Contains(rng, ":") || strings.Contains(rng, "/") {
+			// git, github, file, link, and tarball URL ranges are not registry versions
+			return "", false
+		}
+		name = specName
+	}
+	return name, name != ""
+}
+
+// splitYarnSpec splits "name@range" at the "@" after the name, so a scoped "@babel/core@^7"
+// keeps its leading "@"
+func splitYarnSpec(spec string) (string, string, bool) {
+	start := 0
+	if strings.HasPrefix(spec, "@") {
+		start = 1
+	}
+	i := strings.Index(spec[start:], "@")
+	if i < 0 {
+		return "", "", false
+	}
+	i += start
+	return spec[:i], spec[i+1:], i > start
+}
+
+// unquoteYarn strips the double quotes yarn puts around values and keys with special characters
+func unquoteYarn(s string) string {
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s
+}
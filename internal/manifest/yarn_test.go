@@ -0,0 +1,101 @@
+package manifest
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseYarnLock_Classic(t *testing.T) {
+	content, err := os.ReadFile("testdata/yarn.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	deps, err := ParseYarnLock(content)
+	if err != nil {
+		t.Fatalf("ParseYarnLock() error = %v", err)
+	}
+
+	want := map[string]string{
+		"@babel/code-frame": "7.12.13",
+		"lodash":            "4.17.15",
+		"string-width":      "4.2.3",
+	}
+	if len(deps) != len(want) {
+		t.Fatalf("got %d dependencies, want %d: %+v", len(deps), len(want), deps)
+	}
+	for _, dep := range deps {
+		version, ok := want[dep.Name]
+		if !ok {
+			t.Errorf("unexpected dependency %s (git and file sources should be skipped)", dep.Name)
+			continue
+		}
+		if dep.Version != version || dep.Ecosystem != EcosystemNPM {
+			t.Errorf("%s = %s/%s, want %s/%s", dep.Name, dep.Ecosystem, dep.Version, EcosystemNPM, version)
+		}
+	}
+
+	if _, err := ParseYarnLock([]byte("lodash@^4.17.15\n  version \"4.17.15\"\n")); err == nil {
+		t.Error("ParseYarnLock() accepted an entry line without a trailing colon")
+	}
+}
+
+func TestParseYarnLock_Berry(t *testing.T) {
+	content := []byte(`# This file is generated by running "yarn install" inside your project.
+
+__metadata:
+  version: 6
+  cacheKey: 8
+
+"@babel/core@npm:^7.12.3":
+  version: 7.21.0
+  resolution: "@babel/core@npm:7.21.0"
+  dependencies:
+    "@babel/code-frame": ^7.18.6
+  checksum: 357f4dd3638861ceebf6d95ff49ad8b902065ee8b7b352621deed5666c2a6d702a48ca7254dec2cd3ee2d7b1c0fc5d8d19ad5a1de8b3c17e8e9d0af8e1c1f1b8
+  languageName: node
+  linkType: hard
+
+"lodash@npm:^4.17.15, lodash@npm:^4.17.20":
+  version: 4.17.20
+  resolution: "lodash@npm:4.17.20"
+  languageName: node
+  linkType: hard
+
+"my-app@workspace:.":
+  version: 0.0.0-use.local
+  resolution: "my-app@workspace:."
+  languageName: unknown
+  linkType: soft
+
+"resolve@patch:resolve@^1.20.0#~builtin<compat/resolve>":
+  version: 1.22.1
+  resolution: "resolve@patch:resolve@npm%3A1.22.1#~builtin<compat/resolve>::version=1.22.1&hash=c3c19d"
+  languageName: node
+  linkType: hard
+`)
+
+	deps, err := ParseYarnLock(content)
+	if err != nil {
+		t.Fatalf("ParseYarnLock() error = %v", err)
+	}
+	if len(deps) != 2 || deps[0].Name != "@babel/core" || deps[0].Version != "7.21.0" ||
+		deps[1].Name != "lodash" || deps[1].Version != "4.17.20" {
+		t.Errorf("ParseYarnLock() = %+v, want @babel/core 7.21.0 and lodash 4.17.20", deps)
+	}
+}
+
+func TestParse_DetectsYarnLock(t *testing.T) {
+	content, err := os.ReadFile("testdata/yarn.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	m, err := Parse("web/yarn.lock", content, Options{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if m.Format != FormatYarnLock || len(m.Dependencies) != 3 {
+		t.Errorf("Parse() = %s with %d dependencies, want %s with 3", m.Format, len(m.Dependencies), FormatYarnLock)
+	}
+}
@@ -0,0 +1,49 @@
+package pool
+
+import (
+	"context"
+	"sync"
+)
+
+// Result is the outcome of applying a function to one item
+type Result[R any] struct {
+	Value R
+	Err   error
+}
+
+// Map applies fn to every item with at most concurrency calls in flight (at least one).
+// Results are returned in input order, each carrying its own error, so one failing item does
+// not abort the rest. Once ctx is done no further items are started: they are recorded with
+// ctx's error and Map returns that error after the in-flight calls finish.
+func Map[T, R any](ctx context.Context, items []T, concurrency int, fn func(context.Context, T) (R, error)) ([]Result[R], error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	results := make([]Result[R], len(items))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, item := range items {
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+		}
+		// A select with both cases ready picks at random, so check again before starting
+		if err := ctx.Err(); err != nil {
+			for j := i; j < len(items); j++ {
+				results[j].Err = err
+			}
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, item T) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i].Value, results[i].Err = fn(ctx, item)
+		}(i, item)
+	}
+	wg.Wait()
+
+	return results, ctx.Err()
+}
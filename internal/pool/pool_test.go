@@ -0,0 +1,108 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMap_PreservesOrder(t *testing.T) {
+	items := []int{5, 1, 4, 2, 3}
+	results, err := Map(context.Background(), items, 3, func(_ context.Context, n int) (int, error) {
+		// Finish out of order: larger items sleep longer
+		time.Sleep(time.Duration(n) * time.Millisecond)
+		return n * 10, nil
+	})
+	if err != nil {
+		t.Fatalf("Map() error = %v", err)
+	}
+	for i, r := range results {
+		if r.Err != nil || r.Value != items[i]*10 {
+			t.Errorf("results[%d] = %+v, want %d", i, r, items[i]*10)
+		}
+	}
+}
+
+func TestMap_BoundsConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int64
+	_, err := Map(context.Background(), make([]int, 20), 4, func(context.Context, int) (struct{}, error) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		inFlight.Add(-1)
+		return struct{}{}, nil
+	})
+	if err != nil {
+		t.Fatalf("Map() error = %v", err)
+	}
+	if got := peak.Load(); got > 4 {
+		t.Errorf("peak concurrency = %d, want at most 4", got)
+	}
+}
+
+func TestMap_CollectsErrors(t *testing.T) {
+	errOdd := errors.New("odd")
+	results, err := Map(context.Background(), []int{1, 2, 3, 4}, 2, func(_ context.Context, n int) (int, error) {
+		if n%2 == 1 {
+			return 0, errOdd
+		}
+		return n, nil
+	})
+	if err != nil {
+		t.Fatalf("Map() error = %v, want per-item errors only", err)
+	}
+	for i, r := range results {
+		n := i + 1
+		if n%2 == 1 && !errors.Is(r.Err, errOdd) {
+			t.Errorf("results[%d].Err = %v, want errOdd", i, r.Err)
+		}
+		if n%2 == 0 && (r.Err != nil || r.Value != n) {
+			t.Errorf("results[%d] = %+v, want %d", i, r, n)
+		}
+	}
+}
+
+func TestMap_CancelStopsDispatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var started atomic.Int64
+	results, err := Map(ctx, make([]int, 10), 1, func(ctx context.Context, _ int) (int, error) {
+		if started.Add(1) == 2 {
+			cancel()
+		}
+		return 0, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Map() error = %v, want context.Canceled", err)
+	}
+	if got := started.Load(); got != 2 {
+		t.Errorf("started %d items, want 2 before cancellation", got)
+	}
+	for i, r := range results[2:] {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("results[%d].Err = %v, want context.Canceled", i+2, r.Err)
+		}
+	}
+}
+
+func BenchmarkMap(b *testing.B) {
+	items := make([]int, 1000)
+	for i := range items {
+		items[i] = i
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Map(context.Background(), items, 8, func(_ context.Context, n int) (int, error) {
+			return n * 2, nil
+		}); err != nil {
+			b.Fatalf("Map() error = %v", err)
+		}
+	}
+}
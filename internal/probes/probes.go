@@ -0,0 +1,240 @@
+package probes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/breaker"
+)
+
+const (
+	// DefaultCacheTTL is how long a readiness result is reused before upstreams are checked again
+	DefaultCacheTTL = 5 * time.Second
+	// DefaultCheckTimeout bounds each upstream reachability check
+	DefaultCheckTimeout = 2 * time.Second
+
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+)
+
+// UpstreamStatus is the reachability of one upstream API
+type UpstreamStatus struct {
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	Reachable bool   `json:"reachable"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+	// Circuit is the state of the upstream's circuit breaker, when one guards it
+	Circuit *breaker.Status `json:"circuit,omitempty"`
+}
+
+// WatchlistItem is the background refresh state of one watchlisted package
+type WatchlistItem struct {
+	Ecosystem   string     `json:"ecosystem"`
+	Package     string     `json:"package"`
+	Version     string     `json:"version,omitempty"`
+	LastRefresh *time.Time `json:"last_refresh,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// Health is the /healthz response body
+type Health struct {
+	Status    string          `json:"status"`
+	Watchlist []WatchlistItem `json:"watchlist,omitempty"`
+}
+
+// Readiness is the /readyz response body
+type Readiness struct {
+	Status    string           `json:"status"`
+	CheckedAt time.Time        `json:"checked_at"`
+	Upstreams []UpstreamStatus `json:"upstreams"`
+}
+
+// Checker serves liveness and readiness probes for HTTP mode.
+// Readiness results are cached so frequent probes do not hammer the upstream APIs.
+type Checker struct {
+	httpClient   *http.Client
+	upstreams    map[string]string
+	breakers     map[string]*breaker.Breaker
+	watchlist    func() []WatchlistItem
+	cacheTTL     time.Duration
+	checkTimeout time.Duration
+
+	mu        sync.Mutex
+	last      *Readiness
+	lastCheck time.Time
+}
+
+// Option configures optional Checker behavior
+type Option func(*Checker)
+
+// WithCacheTTL overrides how long a readiness result is reused
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(c *Checker) {
+		c.cacheTTL = ttl
+	}
+}
+
+// WithCheckTimeout overrides the per-upstream reachability timeout
+func WithCheckTimeout(timeout time.Duration) Option {
+	return func(c *Checker) {
+		c.checkTimeout = timeout
+	}
+}
+
+// WithBreakers reports the state of the circuit breakers guarding upstreams, keyed by the
+// same names as the upstream URLs. An open circuit marks readiness degraded.
+func WithBreakers(breakers map[string]*breaker.Breaker) Option {
+	return func(c *Checker) {
+		c.breakers = breakers
+	}
+}
+
+// WithWatchlist reports the last refresh of each watchlisted package on /healthz
+func WithWatchlist(status func() []WatchlistItem) Option {
+	return func(c *Checker) {
+		c.watchlist = status
+	}
+}
+
+// WithTransport sends the reachability checks through rt, such as the transport carrying OSV
+// and deps.dev requests through a TLS-inspecting proxy. A nil rt leaves the default in place.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Checker) {
+		if rt != nil {
+			c.httpClient.Transport = rt
+		}
+	}
+}
+
+// NewChecker creates a probe checker for the given upstream base URLs, keyed by name
+func NewChecker(upstreams map[string]string, opts ...Option) *Checker {
+	c := &Checker{
+		httpClient:   &http.Client{},
+		upstreams:    upstreams,
+		cacheTTL:     DefaultCacheTTL,
+		checkTimeout: DefaultCheckTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Liveness reports that the process is up, with the watchlist refresh state when one is
+// configured. It never touches upstreams.
+func (c *Checker) Liveness(w http.ResponseWriter, r *http.Request) {
+	health := Health{Status: StatusOK}
+	if c.watchlist != nil {
+		health.Watchlist = c.watchlist()
+	}
+	writeJSON(w, http.StatusOK, health)
+}
+
+// Readiness reports upstream reachability: 200 when every upstream answers, 503 otherwise
+func (c *Checker) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := c.Check(r.Context())
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+// Check returns the cached readiness result, refreshing it once it is older than the cache TTL.
+// Circuit breaker states are read fresh on every call.
+func (c *Checker) Check(ctx context.Context) *Readiness {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.last == nil || time.Since(c.lastCheck) >= c.cacheTTL {
+		c.last = c.checkUpstreams(ctx)
+		c.lastCheck = time.Now()
+	}
+	if len(c.breakers) == 0 {
+		return c.last
+	}
+
+	report := *c.last
+	report.Upstreams = make([]UpstreamStatus, len(c.last.Upstreams))
+	for i, u := range c.last.Upstreams {
+		if b, ok := c.breakers[u.Name]; ok {
+			status := b.Status()
+			u.Circuit = &status
+			if status.State == breaker.StateOpen {
+				report.Status = StatusDegraded
+			}
+		}
+		report.Upstreams[i] = u
+	}
+	return &report
+}
+
+// checkUpstreams probes every upstream concurrently
+func (c *Checker) checkUpstreams(ctx context.Context) *Readiness {
+
+	names := make([]string, 0, len(c.upstreams))
+	for name := range c.upstreams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	report := &Readiness{
+		Status:    StatusOK,
+		CheckedAt: time.Now().UTC(),
+		Upstreams: make([]UpstreamStatus, len(names)),
+	}
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			report.Upstreams[i] = c.checkUpstream(ctx, name, c.upstreams[name])
+		}(i, name)
+	}
+	wg.Wait()
+
+	for _, u := range report.Upstreams {
+		if !u.Reachable {
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
+
+// checkUpstream treats any HTTP response as reachable; only transport failures count as down
+func (c *Checker) checkUpstream(ctx context.Context, name, url string) UpstreamStatus {
+	status := UpstreamStatus{Name: name, URL: url}
+
+	ctx, cancel := context.WithTimeout(ctx, c.checkTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	status.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	_ = resp.Body.Close()
+
+	status.Reachable = true
+	return status
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
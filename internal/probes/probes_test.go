@@ -0,0 +1,153 @@
+package probes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/breaker"
+)
+
+func TestLiveness(t *testing.T) {
+	checker := NewChecker(nil)
+
+	rec := httptest.NewRecorder()
+	checker.Liveness(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body["status"] != StatusOK {
+		t.Errorf("body = %v (%v), want status ok", body, err)
+	}
+
+	refreshed := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	checker = NewChecker(nil, WithWatchlist(func() []WatchlistItem {
+		return []WatchlistItem{{Ecosystem: "npm", Package: "lodash", LastRefresh: &refreshed}}
+	}))
+	rec = httptest.NewRecorder()
+	checker.Liveness(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	var health Health
+	if err := json.NewDecoder(rec.Body).Decode(&health); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if len(health.Watchlist) != 1 || !health.Watchlist[0].LastRefresh.Equal(refreshed) {
+		t.Errorf("watchlist = %+v, want lodash refreshed at %v", health.Watchlist, refreshed)
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	var hits atomic.Int32
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		// Base URLs often 404; any answer proves the upstream is reachable
+		http.NotFound(w, r)
+	}))
+	defer up.Close()
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	downURL := down.URL
+	down.Close()
+
+	t.Run("all reachable", func(t *testing.T) {
+		checker := NewChecker(map[string]string{"osv": up.URL, "deps.dev": up.URL})
+
+		rec := httptest.NewRecorder()
+		checker.Readiness(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want 200", rec.Code)
+		}
+
+		var report Readiness
+		if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+			t.Fatalf("invalid readiness JSON: %v", err)
+		}
+		if report.Status != StatusOK || report.CheckedAt.IsZero() || len(report.Upstreams) != 2 {
+			t.Errorf("report = %+v, want ok with two upstreams", report)
+		}
+		if report.Upstreams[0].Name != "deps.dev" || report.Upstreams[1].Name != "osv" {
+			t.Errorf("upstreams = %+v, want sorted by name", report.Upstreams)
+		}
+	})
+
+	t.Run("upstream down", func(t *testing.T) {
+		checker := NewChecker(map[string]string{"osv": up.URL, "deps.dev": downURL}, WithCheckTimeout(time.Second))
+
+		rec := httptest.NewRecorder()
+		checker.Readiness(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("status = %d, want 503", rec.Code)
+		}
+
+		var report Readiness
+		if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+			t.Fatalf("invalid readiness JSON: %v", err)
+		}
+		if report.Status != StatusDegraded {
+			t.Errorf("Status = %s, want degraded", report.Status)
+		}
+		depsdev := report.Upstreams[0]
+		if depsdev.Reachable || depsdev.Error == "" {
+			t.Errorf("deps.dev = %+v, want unreachable with an error", depsdev)
+		}
+	})
+
+	t.Run("custom transport", func(t *testing.T) {
+		// An upstream behind a private CA, such as a TLS-inspecting proxy
+		private := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer private.Close()
+
+		if report := NewChecker(map[string]string{"osv": private.URL}).Check(context.Background()); report.Status == StatusOK {
+			t.Errorf("report = %+v, want the untrusted certificate to fail the default client", report)
+		}
+		checker := NewChecker(map[string]string{"osv": private.URL}, WithTransport(private.Client().Transport))
+		if report := checker.Check(context.Background()); report.Status != StatusOK {
+			t.Errorf("report = %+v, want ok through the transport trusting the CA", report)
+		}
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		checker := NewChecker(map[string]string{"osv": up.URL}, WithCacheTTL(time.Hour))
+		before := hits.Load()
+		for i := 0; i < 3; i++ {
+			checker.Readiness(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		}
+		if got := hits.Load() - before; got != 1 {
+			t.Errorf("upstream hits = %d, want 1 while the result is cached", got)
+		}
+	})
+
+	t.Run("open circuit", func(t *testing.T) {
+		osvBreaker := breaker.New("osv", 1, time.Hour)
+		checker := NewChecker(map[string]string{"osv": up.URL, "deps.dev": up.URL},
+			WithCacheTTL(time.Hour),
+			WithBreakers(map[string]*breaker.Breaker{"osv": osvBreaker}))
+
+		// Breaker state is not cached with the reachability result
+		if report := checker.Check(context.Background()); report.Status != StatusOK || report.Upstreams[1].Circuit.State != breaker.StateClosed {
+			t.Fatalf("report = %+v, want ok with a closed osv circuit", report)
+		}
+		osvBreaker.Record(false)
+
+		rec := httptest.NewRecorder()
+		checker.Readiness(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("status = %d, want 503 while a circuit is open", rec.Code)
+		}
+		var report Readiness
+		if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+			t.Fatalf("invalid readiness JSON: %v", err)
+		}
+		if c := report.Upstreams[1].Circuit; c == nil || c.State != breaker.StateOpen || c.ConsecutiveFailures != 1 {
+			t.Errorf("osv circuit = %+v, want open after one failure", c)
+		}
+		if report.Upstreams[0].Circuit != nil {
+			t.Errorf("deps.dev circuit = %+v, want none without a breaker", report.Upstreams[0].Circuit)
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package depsdev
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/rayprogramming/PackagePulse/internal/decode"
+	"github.com/rayprogramming/PackagePulse/internal/reqlog"
+	"go.uber.org/zap"
+)
+
+// ErrAdvisoryNotFound is returned when deps.dev has no advisory with the given ID
+var ErrAdvisoryNotFound = errors.New("advisory not found")
+
+// AdvisoryKey identifies a deps.dev advisory by its OSV ID
+type AdvisoryKey struct {
+	ID string `json:"id"`
+}
+
+// Advisory is deps.dev's view of a security advisory
+type Advisory struct {
+	AdvisoryKey AdvisoryKey `json:"advisoryKey"`
+	URL         string      `json:"url,omitempty"`
+	Title       string      `json:"title,omitempty"`
+	Aliases     []string    `json:"aliases,omitempty"`
+	CVSS3Score  float64     `json:"cvss3Score,omitempty"`
+	CVSS3Vector string      `json:"cvss3Vector,omitempty"`
+}
+
+// GetAdvisory retrieves an advisory by its OSV ID, returning ErrAdvisoryNotFound when
+// deps.dev does not carry it
+// Example: client.GetAdvisory(ctx, "GHSA-35jh-r3h4-6jhm")
+func (c *Client) GetAdvisory(ctx context.Context, id string) (*Advisory, error) {
+	endpoint := fmt.Sprintf("%s/advisories/%s", c.baseURL, escapePathSegment(id))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	reqlog.Logger(ctx, c.logger).Debug("querying deps.dev advisory", zap.String("id", id))
+
+	resp, err := c.doWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("execute request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrAdvisoryNotFound, id)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("deps.dev API error: status=%d body=%s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var advisory Advisory
+	if err := decode.JSON("deps.dev", resp.Body, &advisory); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return &advisory, nil
+}
@@ -0,0 +1,43 @@
+package depsdev
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestGetAdvisory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/advisories/GHSA-35jh-r3h4-6jhm" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{
+			"advisoryKey": {"id": "GHSA-35jh-r3h4-6jhm"},
+			"url": "https://osv.dev/vulnerability/GHSA-35jh-r3h4-6jhm",
+			"title": "Command Injection in lodash",
+			"aliases": ["CVE-2021-23337"],
+			"cvss3Score": 7.2,
+			"cvss3Vector": "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H"
+		}`))
+	}))
+	t.Cleanup(server.Close)
+	client := NewClient(zap.NewNop(), WithBaseURL(server.URL))
+
+	advisory, err := client.GetAdvisory(context.Background(), "GHSA-35jh-r3h4-6jhm")
+	if err != nil {
+		t.Fatalf("GetAdvisory() error = %v", err)
+	}
+	if advisory.AdvisoryKey.ID != "GHSA-35jh-r3h4-6jhm" || advisory.Title != "Command Injection in lodash" ||
+		advisory.CVSS3Score != 7.2 || len(advisory.Aliases) != 1 {
+		t.Errorf("GetAdvisory() = %+v", advisory)
+	}
+
+	if _, err := client.GetAdvisory(context.Background(), "GHSA-none"); !errors.Is(err, ErrAdvisoryNotFound) {
+		t.Errorf("GetAdvisory(unknown) error = %v, want ErrAdvisoryNotFound", err)
+	}
+}
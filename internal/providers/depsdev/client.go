@@ -2,35 +2,132 @@ package depsdev
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
+	"github.com/rayprogramming/PackagePulse/internal/breaker"
+	"github.com/rayprogramming/PackagePulse/internal/cassette"
+	"github.com/rayprogramming/PackagePulse/internal/decode"
+	"github.com/rayprogramming/PackagePulse/internal/reqlog"
+	"github.com/rayprogramming/PackagePulse/internal/retrybudget"
+	"github.com/rayprogramming/PackagePulse/internal/stats"
+	"github.com/rayprogramming/PackagePulse/internal/tracing"
+	"github.com/rayprogramming/PackagePulse/internal/useragent"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
 const (
-	depsDevBaseURL = "https://api.deps.dev/v3alpha"
-	apiTimeout     = 30 * time.Second
+	APIBaseURL = "https://api.deps.dev/v3alpha"
 )
 
 // Client handles deps.dev API interactions
 type Client struct {
-	httpClient *http.Client
-	logger     *zap.Logger
+	httpClient     *http.Client
+	logger         *zap.Logger
+	baseURL        string
+	maxRetries     int
+	retryBaseDelay time.Duration
+	// retryBudget, when set, caps retries across every client sharing it
+	retryBudget *retrybudget.Budget
+}
+
+// Option configures optional Client behavior
+type Option func(*Client)
+
+// WithBaseURL points the client at an alternate deps.dev API (e.g. a test server)
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithTransport sends every request through rt in place of http.DefaultTransport, for example
+// to present a client certificate to a TLS-inspecting proxy. It replaces the transport built up
+// so far, so pass it before the options that wrap it. A nil rt leaves the default in place.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		if rt != nil {
+			c.httpClient.Transport = rt
+		}
+	}
+}
+
+// WithHTTPClient sends requests with a copy of hc, keeping its timeout, redirect policy, and
+// cookie jar. Like WithTransport it replaces what earlier options set up, so pass it first.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc != nil {
+			clone := *hc
+			c.httpClient = &clone
+		}
+	}
+}
+
+// WithUserAgent sets the User-Agent header on every request the client sends
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = useragent.Transport(ua, c.httpClient.Transport)
+	}
+}
+
+// WithStats records the outcome and latency of every request the client sends
+func WithStats(u *stats.Upstream) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = u.Transport(c.httpClient.Transport)
+	}
+}
+
+// WithCassette records every response to, or replays it from, a cassette; see cassette.New.
+// A nil cassette leaves the client talking to the network as usual.
+func WithCassette(cs *cassette.Cassette) Option {
+	return func(c *Client) {
+		if cs != nil {
+			c.httpClient.Transport = cs.Transport(c.httpClient.Transport)
+		}
+	}
+}
+
+// WithBreaker routes every request through a circuit breaker so calls fail fast with a
+// *breaker.OpenError while deps.dev is down
+func WithBreaker(b *breaker.Breaker) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = b.Transport(c.httpClient.Transport)
+	}
+}
+
+// WithTracerProvider gives every request a client span under the span in its context. Pass it
+// after WithBreaker so requests the breaker turns away are traced too. A nil tp adds nothing.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = tracing.Transport(tp, "deps.dev", c.httpClient.Transport)
+	}
 }
 
 // NewClient creates a new deps.dev API client
-func NewClient(logger *zap.Logger) *Client {
-	return &Client{
-		httpClient: &http.Client{
-			Timeout: apiTimeout,
-		},
-		logger: logger,
+func NewClient(logger *zap.Logger, opts ...Option) *Client {
+	c := &Client{
+		httpClient:     &http.Client{},
+		logger:         logger,
+		baseURL:        APIBaseURL,
+		maxRetries:     DefaultMaxRetries,
+		retryBaseDelay: DefaultRetryBaseDelay,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// BaseURL returns the deps.dev API base URL the client queries
+func (c *Client) BaseURL() string {
+	return c.baseURL
 }
 
 // PackageInfo contains metadata about a package
@@ -48,9 +145,12 @@ type PackageKey struct {
 
 // VersionInfo contains metadata about a specific version
 type VersionInfo struct {
-	VersionKey      VersionKey    `json:"versionKey"`
-	PublishedAt     time.Time     `json:"publishedAt"`
-	IsDefault       bool          `json:"isDefault"`
+	VersionKey  VersionKey `json:"versionKey"`
+	PublishedAt time.Time  `json:"publishedAt"`
+	IsDefault   bool       `json:"isDefault"`
+	// IsDeprecated marks a release its registry has withdrawn: yanked from crates.io or PyPI,
+	// or deprecated on npm
+	IsDeprecated    bool          `json:"isDeprecated,omitempty"`
 	Licenses        []string      `json:"licenses,omitempty"`
 	Links           []Link        `json:"links,omitempty"`
 	SlsaProvenances []interface{} `json:"slsaProvenances,omitempty"`
@@ -81,29 +181,67 @@ type HealthMetrics struct {
 	HasRepository    bool      `json:"has_repository"`
 	HasDocumentation bool      `json:"has_documentation"`
 	LicenseCount     int       `json:"license_count"`
+	Licenses         []string  `json:"licenses,omitempty"`
 	MaintenanceScore float64   `json:"maintenance_score"`
 	MaintenanceLevel string    `json:"maintenance_level"`
 	Recommendation   string    `json:"recommendation"`
+
+	// Set when deps.dev's default version is a prerelease; LatestStableVersion is then the
+	// highest stable release, or empty when the package has none
+	LatestIsPrerelease  bool   `json:"latest_is_prerelease,omitempty"`
+	LatestStableVersion string `json:"latest_stable_version,omitempty"`
+
+	// Populated only when metrics are computed for a specific version
+	Version          string     `json:"version,omitempty"`
+	VersionPublished *time.Time `json:"version_published,omitempty"`
+	VersionAgeDays   int        `json:"version_age_days,omitempty"`
+	VersionLicenses  []string   `json:"version_licenses,omitempty"`
+	HasProvenance    bool       `json:"has_provenance,omitempty"`
+	ReleasesBehind   *int       `json:"releases_behind,omitempty"`
 }
 
+// systemNames maps OSV ecosystem names to deps.dev system names where they differ
+var systemNames = map[string]string{
+	"crates.io": "cargo",
+}
+
+// System returns the deps.dev system name for an ecosystem.
+// deps.dev systems are lowercase, and Rust crates are "cargo" rather than OSV's "crates.io".
+func System(ecosystem string) string {
+	system := strings.ToLower(ecosystem)
+	if mapped, ok := systemNames[system]; ok {
+		return mapped
+	}
+	return system
+}
+
+// escapePathSegment percent-encodes a package name or version as one deps.dev URL path
+// segment. Scoped npm names carry a "/" that must not split the path, and deps.dev documents
+// their "@" encoded too: @babel/core is requested as %40babel%2Fcore.
+func escapePathSegment(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "@", "%40")
+}
+
+// ErrPackageNotFound is returned when deps.dev has no package with the given name
+var ErrPackageNotFound = errors.New("package not found")
+
 // GetPackage retrieves package information from deps.dev
 // Example: client.GetPackage(ctx, "npm", "express")
 func (c *Client) GetPackage(ctx context.Context, ecosystem, name string) (*PackageInfo, error) {
-	c.logger.Debug("querying deps.dev", zap.String("ecosystem", ecosystem), zap.String("package", name))
+	reqlog.Logger(ctx, c.logger).Debug("querying deps.dev", zap.String("ecosystem", ecosystem), zap.String("package", name))
 
-	escapedName := url.PathEscape(name)
-	endpoint := fmt.Sprintf("%s/systems/%s/packages/%s", depsDevBaseURL, ecosystem, escapedName)
+	endpoint := fmt.Sprintf("%s/systems/%s/packages/%s", c.baseURL, System(ecosystem), escapePathSegment(name))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
-	c.logger.Debug("querying deps.dev",
+	reqlog.Logger(ctx, c.logger).Debug("querying deps.dev",
 		zap.String("ecosystem", ecosystem),
 		zap.String("package", name))
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.doWithRetry(req)
 	if err != nil {
 		return nil, fmt.Errorf("execute request: %w", err)
 	}
@@ -112,7 +250,7 @@ func (c *Client) GetPackage(ctx context.Context, ecosystem, name string) (*Packa
 	}()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("package not found: %s/%s", ecosystem, name)
+		return nil, fmt.Errorf("%w: %s/%s", ErrPackageNotFound, ecosystem, name)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -121,36 +259,109 @@ func (c *Client) GetPackage(ctx context.Context, ecosystem, name string) (*Packa
 	}
 
 	var result PackageInfo
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decode.JSON("deps.dev", resp.Body, &result); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
-	c.logger.Debug("deps.dev query complete",
+	reqlog.Logger(ctx, c.logger).Debug("deps.dev query complete",
 		zap.Int("versions", len(result.Versions)))
 
 	return &result, nil
 }
 
-// ComputeHealthMetrics calculates health metrics from package info
+// CanonicalName returns the registry's canonical spelling of a package name.
+// deps.dev resolves case-insensitive ecosystems such as NuGet regardless of the
+// casing requested and reports the canonical ID in the package key.
+func (c *Client) CanonicalName(ctx context.Context, ecosystem, name string) (string, error) {
+	pkg, err := c.GetPackage(ctx, ecosystem, name)
+	if err != nil {
+		return "", err
+	}
+	if pkg.PackageKey.Name == "" {
+		return name, nil
+	}
+	return pkg.PackageKey.Name, nil
+}
+
+// MaintenanceLevelUnknown is reported when a package has no versions to assess
+const MaintenanceLevelUnknown = "unknown"
+
+// HealthScoringConfig sets how many points each maintenance signal is worth. The maintenance
+// score is normalized to 0-100 whatever the weights add up to, so only their proportions
+// matter. Recency and version count earn their full weight at the top tier (updated within
+// 30 days, 50+ versions) and three quarters, half, or a quarter of it at the lower tiers.
+type HealthScoringConfig struct {
+	Recency       float64 `json:"recency"`
+	Versions      float64 `json:"versions"`
+	Repository    float64 `json:"repository"`
+	Documentation float64 `json:"documentation"`
+	License       float64 `json:"license"`
+}
+
+// DefaultHealthScoringConfig weighs recent releases most: 40 points for recency, 20 each for
+// version count and a source repository, and 10 each for documentation and a license
+func DefaultHealthScoringConfig() HealthScoringConfig {
+	return HealthScoringConfig{Recency: 40, Versions: 20, Repository: 20, Documentation: 10, License: 10}
+}
+
+// Validate checks that weights are non-negative and not all zero
+func (c HealthScoringConfig) Validate() error {
+	if c.Recency < 0 || c.Versions < 0 || c.Repository < 0 || c.Documentation < 0 || c.License < 0 {
+		return fmt.Errorf("health scoring weights must be non-negative: %+v", c)
+	}
+	if c.total() == 0 {
+		return fmt.Errorf("at least one health scoring weight must be positive")
+	}
+	return nil
+}
+
+func (c HealthScoringConfig) total() float64 {
+	return c.Recency + c.Versions + c.Repository + c.Documentation + c.License
+}
+
+// ComputeHealthMetrics calculates health metrics from package info with the default scoring
 func ComputeHealthMetrics(pkg *PackageInfo) *HealthMetrics {
+	return ComputeHealthMetricsWithScoring(pkg, DefaultHealthScoringConfig())
+}
+
+// ComputeHealthMetricsWithScoring calculates health metrics from package info, weighting the
+// maintenance score by scoring
+func ComputeHealthMetricsWithScoring(pkg *PackageInfo, scoring HealthScoringConfig) *HealthMetrics {
 	metrics := &HealthMetrics{
 		PackageName:  pkg.PackageKey.Name,
 		Ecosystem:    pkg.PackageKey.System,
 		VersionCount: len(pkg.Versions),
 	}
 
+	// Without any releases there is nothing to score; say so rather than report a low score
+	if len(pkg.Versions) == 0 {
+		metrics.MaintenanceLevel = MaintenanceLevelUnknown
+		metrics.Recommendation = "Insufficient data: deps.dev lists no versions for this package, so its maintenance cannot be assessed."
+		return metrics
+	}
+
 	// Find latest version and publication date
 	var latestPub time.Time
 	for _, v := range pkg.Versions {
 		if v.IsDefault {
 			metrics.LatestVersion = v.VersionKey.Version
 			metrics.LicenseCount = len(v.Licenses)
+			metrics.Licenses = v.Licenses
 		}
 		if v.PublishedAt.After(latestPub) {
 			latestPub = v.PublishedAt
 		}
 	}
 	metrics.LastPublished = latestPub
+	// Without a default version, fall back to the highest stable release in the package's scheme
+	if metrics.LatestVersion == "" {
+		metrics.LatestVersion = LatestStableVersion(pkg)
+	}
+	// Some packages default to a release candidate; report the stable release alongside it
+	if metrics.LatestVersion != "" && SchemeFor(pkg.PackageKey.System).IsPrerelease(metrics.LatestVersion) {
+		metrics.LatestIsPrerelease = true
+		metrics.LatestStableVersion = LatestStableVersion(pkg)
+	}
 
 	if !latestPub.IsZero() {
 		metrics.DaysSinceUpdate = int(time.Since(latestPub).Hours() / 24)
@@ -168,44 +379,14 @@ func ComputeHealthMetrics(pkg *PackageInfo) *HealthMetrics {
 
 	// Compute maintenance score (0-100)
 	score := 0.0
-
-	// Recent updates (40 points max)
-	if metrics.DaysSinceUpdate <= 30 {
-		score += 40.0
-	} else if metrics.DaysSinceUpdate <= 90 {
-		score += 30.0
-	} else if metrics.DaysSinceUpdate <= 180 {
-		score += 20.0
-	} else if metrics.DaysSinceUpdate <= 365 {
-		score += 10.0
-	}
-
-	// Version count (20 points max)
-	if metrics.VersionCount >= 50 {
-		score += 20.0
-	} else if metrics.VersionCount >= 20 {
-		score += 15.0
-	} else if metrics.VersionCount >= 10 {
-		score += 10.0
-	} else if metrics.VersionCount >= 5 {
-		score += 5.0
-	}
-
-	// Repository (20 points)
-	if metrics.HasRepository {
-		score += 20.0
-	}
-
-	// Documentation (10 points)
-	if metrics.HasDocumentation {
-		score += 10.0
+	for _, c := range scoreComponents(metrics, scoring) {
+		score += c.Points
 	}
 
-	// License (10 points)
-	if metrics.LicenseCount > 0 {
-		score += 10.0
+	// Rounded so proportional weights land exactly on the level thresholds
+	if total := scoring.total(); total > 0 {
+		score = math.Round(score*10000/total) / 100
 	}
-
 	metrics.MaintenanceScore = score
 
 	// Assign maintenance level and recommendation
@@ -228,3 +409,205 @@ func ComputeHealthMetrics(pkg *PackageInfo) *HealthMetrics {
 
 	return metrics
 }
+
+// Maintenance score components
+const (
+	ScoreRecency       = "recency"
+	ScoreVersions      = "versions"
+	ScoreRepository    = "repository"
+	ScoreDocumentation = "documentation"
+	ScoreLicense       = "license"
+)
+
+// ScoreComponent is one signal's contribution to the maintenance score, out of Max points
+type ScoreComponent struct {
+	Component string  `json:"component"`
+	Points    float64 `json:"points"`
+	Max       float64 `json:"max"`
+	Detail    string  `json:"detail"`
+}
+
+// ScoreBreakdown explains metrics' maintenance score as the points each signal contributed,
+// normalized like the score so they add up to it (to within rounding for weights that do not
+// divide 100). It returns nil for packages without versions, which are not scored.
+func ScoreBreakdown(metrics *HealthMetrics, scoring HealthScoringConfig) []ScoreComponent {
+	total := scoring.total()
+	if metrics.MaintenanceLevel == MaintenanceLevelUnknown || total <= 0 {
+		return nil
+	}
+	components := scoreComponents(metrics, scoring)
+	for i := range components {
+		components[i].Points = math.Round(components[i].Points*10000/total) / 100
+		components[i].Max = math.Round(components[i].Max*10000/total) / 100
+	}
+	return components
+}
+
+// scoreComponents awards each signal its points in scoring's unnormalized weights
+func scoreComponents(metrics *HealthMetrics, scoring HealthScoringConfig) []ScoreComponent {
+	recency := ScoreComponent{
+		Component: ScoreRecency,
+		Max:       scoring.Recency,
+		Detail:    fmt.Sprintf("last release %d days ago", metrics.DaysSinceUpdate),
+	}
+	switch {
+	case metrics.LastPublished.IsZero():
+		// Without release dates there is no recency to credit
+		recency.Detail = "release dates unknown"
+	case metrics.DaysSinceUpdate <= 30:
+		recency.Points = scoring.Recency
+	case metrics.DaysSinceUpdate <= 90:
+		recency.Points = scoring.Recency * 0.75
+	case metrics.DaysSinceUpdate <= 180:
+		recency.Points = scoring.Recency * 0.5
+	case metrics.DaysSinceUpdate <= 365:
+		recency.Points = scoring.Recency * 0.25
+	}
+
+	versions := ScoreComponent{
+		Component: ScoreVersions,
+		Max:       scoring.Versions,
+		Detail:    fmt.Sprintf("%d versions published", metrics.VersionCount),
+	}
+	switch {
+	case metrics.VersionCount >= 50:
+		versions.Points = scoring.Versions
+	case metrics.VersionCount >= 20:
+		versions.Points = scoring.Versions * 0.75
+	case metrics.VersionCount >= 10:
+		versions.Points = scoring.Versions * 0.5
+	case metrics.VersionCount >= 5:
+		versions.Points = scoring.Versions * 0.25
+	}
+
+	present := func(component string, weight float64, ok bool, yes, no string) ScoreComponent {
+		c := ScoreComponent{Component: component, Max: weight, Detail: no}
+		if ok {
+			c.Points, c.Detail = weight, yes
+		}
+		return c
+	}
+	return []ScoreComponent{
+		recency,
+		versions,
+		present(ScoreRepository, scoring.Repository, metrics.HasRepository, "source repository linked", "no source repository linked"),
+		present(ScoreDocumentation, scoring.Documentation, metrics.HasDocumentation, "documentation linked", "no documentation linked"),
+		present(ScoreLicense, scoring.License, metrics.LicenseCount > 0, "license declared", "no license declared"),
+	}
+}
+
+// ComputeVersionHealthMetrics calculates package health metrics and adds details for one version.
+// ReleasesBehind counts the stable releases ordered after the requested version up to and
+// including the latest (default) version, so prereleases and backports to older lines are ignored.
+func ComputeVersionHealthMetrics(pkg *PackageInfo, version string) (*HealthMetrics, error) {
+	return ComputeVersionHealthMetricsWithScoring(pkg, version, DefaultHealthScoringConfig())
+}
+
+// ComputeVersionHealthMetricsWithScoring is ComputeVersionHealthMetrics with the maintenance
+// score weighted by scoring
+func ComputeVersionHealthMetricsWithScoring(pkg *PackageInfo, version string, scoring HealthScoringConfig) (*HealthMetrics, error) {
+	target, err := findVersion(pkg, version)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := ComputeHealthMetricsWithScoring(pkg, scoring)
+	metrics.Version = version
+	metrics.VersionLicenses = target.Licenses
+	metrics.HasProvenance = len(target.SlsaProvenances) > 0
+
+	if !target.PublishedAt.IsZero() {
+		published := target.PublishedAt
+		metrics.VersionPublished = &published
+		metrics.VersionAgeDays = int(time.Since(published).Hours() / 24)
+	}
+
+	behind := releasesBehind(pkg, version, metrics.LatestVersion)
+	metrics.ReleasesBehind = &behind
+
+	return metrics, nil
+}
+
+// Freshness describes how far a version trails the latest release
+type Freshness struct {
+	PackageName      string     `json:"package_name"`
+	Ecosystem        string     `json:"ecosystem"`
+	CurrentVersion   string     `json:"current_version"`
+	LatestVersion    string     `json:"latest_version"`
+	CurrentPublished *time.Time `json:"current_published,omitempty"`
+	LatestPublished  *time.Time `json:"latest_published,omitempty"`
+	IsLatest         bool       `json:"is_latest"`
+	ReleasesBehind   int        `json:"releases_behind"`
+	// DaysBehind is the time between the two publish dates; 0 when either date is unknown
+	DaysBehind int        `json:"days_behind"`
+	VersionGap VersionGap `json:"version_gap"`
+}
+
+// ComputeFreshness compares a version with the latest (default) release. ReleasesBehind
+// counts releases the same way as ComputeVersionHealthMetrics.
+func ComputeFreshness(pkg *PackageInfo, version string) (*Freshness, error) {
+	target, err := findVersion(pkg, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *VersionInfo
+	for i := range pkg.Versions {
+		if pkg.Versions[i].IsDefault {
+			latest = &pkg.Versions[i]
+			break
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("latest version unknown: %s/%s", pkg.PackageKey.System, pkg.PackageKey.Name)
+	}
+
+	freshness := &Freshness{
+		PackageName:    pkg.PackageKey.Name,
+		Ecosystem:      pkg.PackageKey.System,
+		CurrentVersion: version,
+		LatestVersion:  latest.VersionKey.Version,
+		IsLatest:       SchemeFor(pkg.PackageKey.System).Compare(version, latest.VersionKey.Version) >= 0,
+		ReleasesBehind: releasesBehind(pkg, version, latest.VersionKey.Version),
+		VersionGap:     ComputeVersionGap(version, latest.VersionKey.Version),
+	}
+	if !target.PublishedAt.IsZero() {
+		published := target.PublishedAt
+		freshness.CurrentPublished = &published
+	}
+	if !latest.PublishedAt.IsZero() {
+		published := latest.PublishedAt
+		freshness.LatestPublished = &published
+	}
+	if freshness.CurrentPublished != nil && freshness.LatestPublished != nil && !freshness.IsLatest {
+		freshness.DaysBehind = max(0, int(latest.PublishedAt.Sub(target.PublishedAt).Hours()/24))
+	}
+
+	return freshness, nil
+}
+
+func findVersion(pkg *PackageInfo, version string) (*VersionInfo, error) {
+	for i := range pkg.Versions {
+		if pkg.Versions[i].VersionKey.Version == version {
+			return &pkg.Versions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("version not found: %s/%s@%s", pkg.PackageKey.System, pkg.PackageKey.Name, version)
+}
+
+// releasesBehind counts the stable releases ordered after version up to and including latest
+func releasesBehind(pkg *PackageInfo, version, latest string) int {
+	scheme := SchemeFor(pkg.PackageKey.System)
+	behind := 0
+	for _, v := range pkg.Versions {
+		candidate := v.VersionKey.Version
+		if scheme.IsPrerelease(candidate) || scheme.Compare(candidate, version) <= 0 {
+			continue
+		}
+		if latest != "" && scheme.Compare(candidate, latest) > 0 {
+			continue
+		}
+		behind++
+	}
+	return behind
+}
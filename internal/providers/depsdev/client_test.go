@@ -2,10 +2,16 @@ package depsdev
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/rayprogramming/PackagePulse/internal/decode"
 	"go.uber.org/zap"
 )
 
@@ -83,6 +89,167 @@ func TestDepsDevClientGetPackage(t *testing.T) {
 	}
 }
 
+func TestGetPackage_EscapesName(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		_ = json.NewEncoder(w).Encode(PackageInfo{})
+	}))
+	t.Cleanup(server.Close)
+	client := NewClient(zap.NewNop(), WithBaseURL(server.URL))
+
+	tests := []struct {
+		ecosystem, name string
+		want            string
+	}{
+		{"npm", "@babel/core", "/systems/npm/packages/%40babel%2Fcore"},
+		{"npm", "@types/node", "/systems/npm/packages/%40types%2Fnode"},
+		{"npm", "express", "/systems/npm/packages/express"},
+		{"Go", "github.com/gin-gonic/gin", "/systems/go/packages/github.com%2Fgin-gonic%2Fgin"},
+		{"Maven", "org.apache.commons:commons-lang3", "/systems/maven/packages/org.apache.commons:commons-lang3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := client.GetPackage(context.Background(), tt.ecosystem, tt.name); err != nil {
+				t.Fatalf("GetPackage() error = %v", err)
+			}
+			if gotPath != tt.want {
+				t.Errorf("request path = %s, want %s", gotPath, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPackage_MalformedResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"packageKey": {"system": "NPM", "name": "express"}, "versions": [{"versionKey"`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(zap.NewNop(), WithBaseURL(server.URL))
+	_, err := client.GetPackage(context.Background(), "npm", "express")
+	var malformed *decode.MalformedError
+	if !errors.As(err, &malformed) {
+		t.Fatalf("GetPackage() error = %v, want a *decode.MalformedError", err)
+	}
+	if malformed.Upstream != "deps.dev" || !strings.HasPrefix(malformed.Snippet, `{"packageKey"`) {
+		t.Errorf("MalformedError = %+v, want deps.dev and the body's start", malformed)
+	}
+}
+
+func TestClient_UserAgent(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.UserAgent()
+		_ = json.NewEncoder(w).Encode(PackageInfo{})
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(zap.NewNop(), WithBaseURL(server.URL), WithUserAgent("PackagePulse/1.2.3 (+ops@example.com)"))
+	if _, err := client.GetPackage(context.Background(), "npm", "express"); err != nil {
+		t.Fatalf("GetPackage() error = %v", err)
+	}
+	if got != "PackagePulse/1.2.3 (+ops@example.com)" {
+		t.Errorf("User-Agent = %q, want PackagePulse/1.2.3 (+ops@example.com)", got)
+	}
+}
+
+// recordingTransport counts the requests it carries and the User-Agent they were sent with
+type recordingTransport struct {
+	requests   int
+	userAgents []string
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.requests++
+	rt.userAgents = append(rt.userAgents, req.UserAgent())
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClient_CustomTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(PackageInfo{})
+	}))
+	t.Cleanup(server.Close)
+
+	for name, opt := range map[string]func(http.RoundTripper) Option{
+		"transport": WithTransport,
+		"http client": func(rt http.RoundTripper) Option {
+			return WithHTTPClient(&http.Client{Transport: rt, Timeout: time.Minute})
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rt := &recordingTransport{}
+			// Wrapping options applied afterwards still send through the custom transport
+			client := NewClient(zap.NewNop(), opt(rt), WithBaseURL(server.URL), WithUserAgent("PackagePulse/1.2.3"))
+			if _, err := client.GetPackage(context.Background(), "npm", "express"); err != nil {
+				t.Fatalf("GetPackage() error = %v", err)
+			}
+			if rt.requests != 1 || rt.userAgents[0] != "PackagePulse/1.2.3" {
+				t.Errorf("custom transport saw %d requests with User-Agent %q, want 1 with PackagePulse/1.2.3", rt.requests, rt.userAgents)
+			}
+		})
+	}
+}
+
+func TestClient_CancelledMidFlight(t *testing.T) {
+	calls := map[string]func(*Client, context.Context) error{
+		"GetPackage": func(c *Client, ctx context.Context) error {
+			_, err := c.GetPackage(ctx, "npm", "@babel/core")
+			return err
+		},
+		"GetDependencies": func(c *Client, ctx context.Context) error {
+			_, err := c.GetDependencies(ctx, "npm", "express", "4.18.2")
+			return err
+		},
+		"GetAdvisory": func(c *Client, ctx context.Context) error {
+			_, err := c.GetAdvisory(ctx, "GHSA-35jh-r3h4-6jhm")
+			return err
+		},
+	}
+	for name, call := range calls {
+		for _, midBody := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/mid_body=%v", name, midBody), func(t *testing.T) {
+				// The server stalls until the client goes away, optionally after starting the body
+				arrived := make(chan struct{}, 1)
+				release := make(chan struct{})
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if midBody {
+						_, _ = w.Write([]byte(`{"packageKey": {`))
+						w.(http.Flusher).Flush()
+					}
+					arrived <- struct{}{}
+					select {
+					case <-r.Context().Done():
+					case <-release:
+					}
+				}))
+				t.Cleanup(server.Close)
+				t.Cleanup(func() { close(release) })
+				client := NewClient(zap.NewNop(), WithBaseURL(server.URL))
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				go func() {
+					<-arrived
+					cancel()
+				}()
+
+				errc := make(chan error, 1)
+				go func() { errc <- call(client, ctx) }()
+				select {
+				case err := <-errc:
+					if !errors.Is(err, context.Canceled) {
+						t.Errorf("%s() error = %v, want context.Canceled", name, err)
+					}
+				case <-time.After(2 * time.Second):
+					t.Fatalf("%s() did not return after its context was cancelled", name)
+				}
+			})
+		}
+	}
+}
+
 func TestComputeHealthMetrics(t *testing.T) {
 	now := time.Now()
 
@@ -234,3 +401,315 @@ func TestHealthMetricsScoring(t *testing.T) {
 		}
 	})
 }
+
+func TestComputeVersionHealthMetrics(t *testing.T) {
+	now := time.Now()
+	pkg := &PackageInfo{
+		PackageKey: PackageKey{Name: "left-pad", System: "npm"},
+		Versions: []VersionInfo{
+			{VersionKey: VersionKey{Version: "1.0.0"}, PublishedAt: now.Add(-900 * 24 * time.Hour), Licenses: []string{"WTFPL"}},
+			{VersionKey: VersionKey{Version: "1.1.0"}, PublishedAt: now.Add(-700 * 24 * time.Hour), Licenses: []string{"MIT"}},
+			{VersionKey: VersionKey{Version: "1.2.0"}, PublishedAt: now.Add(-400 * 24 * time.Hour), Licenses: []string{"MIT"}},
+			// Neither a prerelease nor a backport to an older line counts as a missed release
+			{VersionKey: VersionKey{Version: "2.0.0-beta.1"}, PublishedAt: now.Add(-3 * 24 * time.Hour)},
+			{VersionKey: VersionKey{Version: "0.9.9"}, PublishedAt: now.Add(-2 * 24 * time.Hour)},
+			{
+				VersionKey:      VersionKey{Version: "1.3.0"},
+				PublishedAt:     now.Add(-5 * 24 * time.Hour),
+				IsDefault:       true,
+				Licenses:        []string{"MIT"},
+				SlsaProvenances: []interface{}{map[string]interface{}{"verified": true}},
+			},
+		},
+	}
+
+	metrics, err := ComputeVersionHealthMetrics(pkg, "1.0.0")
+	if err != nil {
+		t.Fatalf("ComputeVersionHealthMetrics() error = %v", err)
+	}
+
+	if metrics.ReleasesBehind == nil || *metrics.ReleasesBehind != 3 {
+		t.Errorf("ReleasesBehind = %v, want 3", metrics.ReleasesBehind)
+	}
+	if metrics.VersionAgeDays < 899 || metrics.VersionAgeDays > 900 {
+		t.Errorf("VersionAgeDays = %d, want ~900", metrics.VersionAgeDays)
+	}
+	if len(metrics.VersionLicenses) != 1 || metrics.VersionLicenses[0] != "WTFPL" {
+		t.Errorf("VersionLicenses = %v, want [WTFPL]", metrics.VersionLicenses)
+	}
+	if metrics.HasProvenance {
+		t.Error("HasProvenance should be false for 1.0.0")
+	}
+	if metrics.LatestVersion != "1.3.0" {
+		t.Errorf("LatestVersion = %s, want 1.3.0", metrics.LatestVersion)
+	}
+
+	latest, err := ComputeVersionHealthMetrics(pkg, "1.3.0")
+	if err != nil {
+		t.Fatalf("ComputeVersionHealthMetrics() error = %v", err)
+	}
+	if latest.ReleasesBehind == nil || *latest.ReleasesBehind != 0 || !latest.HasProvenance {
+		t.Errorf("latest: ReleasesBehind = %v, HasProvenance = %v", latest.ReleasesBehind, latest.HasProvenance)
+	}
+
+	if _, err := ComputeVersionHealthMetrics(pkg, "9.9.9"); err == nil {
+		t.Error("expected error for unknown version")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.10", "1.2.9", 1},
+		{"1.2", "1.2.0", 0},
+		{"v2.0.0", "1.9.9", 1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.0.0rc1", "1.0.0", -1},
+		{"1.0.0+build.5", "1.0.0", 0},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	for version, want := range map[string]bool{"1.0.0": false, "1.0.0-rc.1": true, "2.0b3": true, "v3.1": false} {
+		if got := IsPrerelease(version); got != want {
+			t.Errorf("IsPrerelease(%q) = %v, want %v", version, got, want)
+		}
+	}
+}
+
+func TestGoVersions(t *testing.T) {
+	ordered := []string{
+		"v0.0.0-20220101000000-aaaaaaaaaaaa",
+		"v0.0.0-20230101000000-bbbbbbbbbbbb",
+		"v1.0.0",
+		"v1.2.3",
+		"v1.2.4-0.20230101000000-cccccccccccc",
+		"v1.2.4-0.20240101000000-dddddddddddd",
+		"v1.2.4-rc.1",
+		"v1.2.4-rc.1.0.20240201000000-eeeeeeeeeeee",
+		"v1.2.4",
+		"v2.0.0+incompatible",
+		"v2.0.1-0.20240301000000-ffffffffffff+incompatible",
+		"v2.1.0+incompatible",
+	}
+	for i := 0; i < len(ordered)-1; i++ {
+		a, b := ordered[i], ordered[i+1]
+		if got := CompareVersions(a, b); got != -1 {
+			t.Errorf("CompareVersions(%q, %q) = %d, want -1", a, b, got)
+		}
+		if got := CompareVersions(b, a); got != 1 {
+			t.Errorf("CompareVersions(%q, %q) = %d, want 1", b, a, got)
+		}
+	}
+	if got := CompareVersions("v2.0.0+incompatible", "2.0.0"); got != 0 {
+		t.Errorf("CompareVersions(+incompatible) = %d, want 0", got)
+	}
+
+	tests := []struct {
+		version string
+		base    string
+		commit  string
+		date    string
+	}{
+		{"v0.0.0-20230101120000-abcdef123456", "", "abcdef123456", "2023-01-01"},
+		{"v1.2.4-0.20230615000000-abcdef123456", "v1.2.3", "abcdef123456", "2023-06-15"},
+		{"v1.2.4-rc.1.0.20230615000000-abcdef123456", "v1.2.4-rc.1", "abcdef123456", "2023-06-15"},
+		{"v2.0.1-0.20240301000000-ffffffffffff+incompatible", "v2.0.0", "ffffffffffff", "2024-03-01"},
+	}
+	for _, tt := range tests {
+		pv, ok := ParsePseudoVersion(tt.version)
+		if !ok {
+			t.Errorf("ParsePseudoVersion(%q) failed", tt.version)
+			continue
+		}
+		if pv.Base != tt.base || pv.Commit != tt.commit || pv.Time.Format("2006-01-02") != tt.date {
+			t.Errorf("ParsePseudoVersion(%q) = %+v, want base %q commit %s at %s", tt.version, pv, tt.base, tt.commit, tt.date)
+		}
+	}
+	for _, version := range []string{"v1.2.3", "v2.0.0+incompatible", "1.0.0-rc.1", "v1.0.0-20230101-abc"} {
+		if IsPseudoVersion(version) {
+			t.Errorf("IsPseudoVersion(%q) = true, want false", version)
+		}
+	}
+
+	for in, want := range map[string]string{
+		"1.2.3":                                "v1.2.3",
+		"v2.0.0+incompatible":                  "v2.0.0",
+		" v0.0.0-20230101000000-abcdef123456 ": "v0.0.0-20230101000000-abcdef123456",
+	} {
+		if got := CanonicalGoVersion(in); got != want {
+			t.Errorf("CanonicalGoVersion(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestComputeHealthMetrics_PrereleaseLatest(t *testing.T) {
+	now := time.Now()
+	pkg := &PackageInfo{
+		PackageKey: PackageKey{Name: "widget", System: "NPM"},
+		Versions: []VersionInfo{
+			{VersionKey: VersionKey{Version: "1.9.0"}, PublishedAt: now.Add(-60 * 24 * time.Hour)},
+			{VersionKey: VersionKey{Version: "2.0.0-rc.1"}, PublishedAt: now.Add(-5 * 24 * time.Hour), IsDefault: true},
+		},
+	}
+	metrics := ComputeHealthMetrics(pkg)
+	if metrics.LatestVersion != "2.0.0-rc.1" || !metrics.LatestIsPrerelease || metrics.LatestStableVersion != "1.9.0" {
+		t.Errorf("metrics = latest %s (prerelease %v), stable %s; want 2.0.0-rc.1 flagged with stable 1.9.0",
+			metrics.LatestVersion, metrics.LatestIsPrerelease, metrics.LatestStableVersion)
+	}
+
+	// A stable default leaves both fields unset
+	pkg.Versions[0].IsDefault, pkg.Versions[1].IsDefault = true, false
+	if metrics := ComputeHealthMetrics(pkg); metrics.LatestIsPrerelease || metrics.LatestStableVersion != "" {
+		t.Errorf("stable default: prerelease %v, stable %q; want neither", metrics.LatestIsPrerelease, metrics.LatestStableVersion)
+	}
+}
+
+func TestComputeHealthMetrics_NoVersions(t *testing.T) {
+	metrics := ComputeHealthMetrics(&PackageInfo{
+		PackageKey: PackageKey{Name: "ghost", System: "NPM"},
+		Versions:   []VersionInfo{},
+		Links:      []Link{{Label: "SOURCE_REPO", URL: "https://github.com/example/ghost"}},
+	})
+
+	if metrics.MaintenanceLevel != MaintenanceLevelUnknown {
+		t.Errorf("MaintenanceLevel = %s, want %s", metrics.MaintenanceLevel, MaintenanceLevelUnknown)
+	}
+	if metrics.MaintenanceScore != 0 || metrics.LatestVersion != "" || metrics.VersionCount != 0 {
+		t.Errorf("metrics = %+v, want no score and no latest version", metrics)
+	}
+	if !strings.Contains(metrics.Recommendation, "Insufficient data") {
+		t.Errorf("Recommendation = %q, want an insufficient-data explanation", metrics.Recommendation)
+	}
+}
+
+func TestComputeVersionGap(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		want            VersionGap
+	}{
+		{"1.2.3", "3.0.1", VersionGap{Level: GapMajor, Major: 2}},
+		{"4.15.0", "4.17.21", VersionGap{Level: GapMinor, Minor: 2}},
+		{"4.17.19", "4.17.21", VersionGap{Level: GapPatch, Patch: 2}},
+		{"v1.0.0", "1.0.0", VersionGap{Level: GapNone}},
+		{"2.0.0-rc.1", "2.0.0", VersionGap{Level: GapPatch}},
+		{"5.0.0", "4.17.21", VersionGap{Level: GapNone}},
+	}
+	for _, tt := range tests {
+		if got := ComputeVersionGap(tt.current, tt.latest); got != tt.want {
+			t.Errorf("ComputeVersionGap(%q, %q) = %+v, want %+v", tt.current, tt.latest, got, tt.want)
+		}
+	}
+}
+
+func TestComputeHealthMetricsWithScoring(t *testing.T) {
+	// Stale but otherwise healthy: 60 versions, a repository, docs, and a license
+	versions := make([]VersionInfo, 60)
+	for i := range versions {
+		versions[i] = VersionInfo{PublishedAt: time.Now().Add(-400 * 24 * time.Hour)}
+	}
+	versions[59].IsDefault = true
+	versions[59].Licenses = []string{"MIT"}
+	pkg := &PackageInfo{
+		PackageKey: PackageKey{Name: "stale-lib", System: "npm"},
+		Versions:   versions,
+		Links:      []Link{{Label: "SOURCE_REPO"}, {Label: "DOCUMENTATION"}},
+	}
+
+	tests := []struct {
+		name      string
+		scoring   HealthScoringConfig
+		wantScore float64
+		wantLevel string
+	}{
+		{"defaults", DefaultHealthScoringConfig(), 60, "good"},
+		{"recency ignored", HealthScoringConfig{Versions: 20, Repository: 20, Documentation: 10, License: 10}, 100, "excellent"},
+		{"recency dominant", HealthScoringConfig{Recency: 80, Repository: 10, Documentation: 5, License: 5}, 20, "poor"},
+		{"normalized to 100", HealthScoringConfig{Recency: 4, Versions: 2, Repository: 2, Documentation: 1, License: 1}, 60, "good"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := ComputeHealthMetricsWithScoring(pkg, tt.scoring)
+			if metrics.MaintenanceScore != tt.wantScore || metrics.MaintenanceLevel != tt.wantLevel {
+				t.Errorf("score/level = %.2f/%s, want %.2f/%s",
+					metrics.MaintenanceScore, metrics.MaintenanceLevel, tt.wantScore, tt.wantLevel)
+			}
+		})
+	}
+
+	if err := (HealthScoringConfig{}).Validate(); err == nil {
+		t.Error("Validate() accepted all-zero weights")
+	}
+	if err := (HealthScoringConfig{Recency: -1, License: 5}).Validate(); err == nil {
+		t.Error("Validate() accepted a negative weight")
+	}
+}
+
+func TestScoreBreakdown(t *testing.T) {
+	// Released two months ago with 25 versions, a repository and a license, but no docs
+	versions := make([]VersionInfo, 25)
+	for i := range versions {
+		versions[i] = VersionInfo{PublishedAt: time.Now().Add(-60 * 24 * time.Hour)}
+	}
+	versions[24].IsDefault = true
+	versions[24].Licenses = []string{"MIT"}
+	pkg := &PackageInfo{
+		PackageKey: PackageKey{Name: "mid-lib", System: "npm"},
+		Versions:   versions,
+		Links:      []Link{{Label: "SOURCE_REPO"}},
+	}
+
+	want := map[string][2]float64{
+		ScoreRecency:       {30, 40},
+		ScoreVersions:      {15, 20},
+		ScoreRepository:    {20, 20},
+		ScoreDocumentation: {0, 10},
+		ScoreLicense:       {10, 10},
+	}
+	metrics := ComputeHealthMetrics(pkg)
+	breakdown := ScoreBreakdown(metrics, DefaultHealthScoringConfig())
+	if len(breakdown) != len(want) {
+		t.Fatalf("ScoreBreakdown() = %+v, want %d components", breakdown, len(want))
+	}
+	sum := 0.0
+	for _, c := range breakdown {
+		if w := want[c.Component]; c.Points != w[0] || c.Max != w[1] {
+			t.Errorf("%s = %.2f/%.2f, want %.0f/%.0f", c.Component, c.Points, c.Max, w[0], w[1])
+		}
+		if c.Detail == "" {
+			t.Errorf("%s has no detail", c.Component)
+		}
+		sum += c.Points
+	}
+	if sum != metrics.MaintenanceScore || sum != 75 {
+		t.Errorf("components sum to %.2f, maintenance score = %.2f, want 75", sum, metrics.MaintenanceScore)
+	}
+
+	// Custom weights are normalized the same way as the score
+	scoring := HealthScoringConfig{Recency: 4, Versions: 2, Repository: 2, Documentation: 1, License: 1}
+	metrics = ComputeHealthMetricsWithScoring(pkg, scoring)
+	sum = 0
+	for _, c := range ScoreBreakdown(metrics, scoring) {
+		sum += c.Points
+	}
+	if sum != metrics.MaintenanceScore {
+		t.Errorf("custom weights: components sum to %.2f, maintenance score = %.2f", sum, metrics.MaintenanceScore)
+	}
+
+	// Versions without release dates earn no recency points
+	undated := ComputeHealthMetrics(&PackageInfo{Versions: []VersionInfo{{IsDefault: true}}})
+	if recency := ScoreBreakdown(undated, DefaultHealthScoringConfig())[0]; recency.Points != 0 || recency.Detail != "release dates unknown" {
+		t.Errorf("undated recency = %+v, want 0 points with release dates unknown", recency)
+	}
+
+	if got := ScoreBreakdown(ComputeHealthMetrics(&PackageInfo{}), DefaultHealthScoringConfig()); got != nil {
+		t.Errorf("ScoreBreakdown() without versions = %+v, want nil", got)
+	}
+}
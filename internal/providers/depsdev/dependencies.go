@@ -0,0 +1,118 @@
+package depsdev
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/rayprogramming/PackagePulse/internal/decode"
+	"github.com/rayprogramming/PackagePulse/internal/reqlog"
+	"go.uber.org/zap"
+)
+
+// Dependency relations reported for graph nodes
+const (
+	RelationSelf     = "SELF"
+	RelationDirect   = "DIRECT"
+	RelationIndirect = "INDIRECT"
+)
+
+// DependencyGraph is the resolved transitive dependency graph of one package version.
+// Node 0 is the package itself.
+type DependencyGraph struct {
+	Nodes []DependencyNode `json:"nodes"`
+	Edges []DependencyEdge `json:"edges"`
+	Error string           `json:"error,omitempty"`
+}
+
+// DependencyNode is one resolved package version in a graph
+type DependencyNode struct {
+	VersionKey VersionKey `json:"versionKey"`
+	Relation   string     `json:"relation"`
+	Errors     []string   `json:"errors,omitempty"`
+}
+
+// DependencyEdge links a node to a dependency it requires, by node index
+type DependencyEdge struct {
+	FromNode    int    `json:"fromNode"`
+	ToNode      int    `json:"toNode"`
+	Requirement string `json:"requirement"`
+}
+
+// GetDependencies retrieves the resolved dependency graph of a package version
+// Example: client.GetDependencies(ctx, "npm", "express", "4.18.2")
+func (c *Client) GetDependencies(ctx context.Context, ecosystem, name, version string) (*DependencyGraph, error) {
+	endpoint := fmt.Sprintf("%s/systems/%s/packages/%s/versions/%s:dependencies",
+		c.baseURL, System(ecosystem), escapePathSegment(name), escapePathSegment(version))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	reqlog.Logger(ctx, c.logger).Debug("querying deps.dev dependencies",
+		zap.String("ecosystem", ecosystem),
+		zap.String("package", name),
+		zap.String("version", version))
+
+	resp, err := c.doWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("execute request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("package version not found: %s/%s@%s", ecosystem, name, version)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("deps.dev API error: status=%d body=%s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var graph DependencyGraph
+	if err := decode.JSON("deps.dev", resp.Body, &graph); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if len(graph.Nodes) == 0 {
+		return nil, fmt.Errorf("empty dependency graph for %s/%s@%s", ecosystem, name, version)
+	}
+
+	reqlog.Logger(ctx, c.logger).Debug("deps.dev dependencies query complete",
+		zap.Int("nodes", len(graph.Nodes)))
+
+	return &graph, nil
+}
+
+// Paths returns, for every node, the shortest chain of node indexes from the root to it.
+// Unreachable nodes get a nil path.
+func (g *DependencyGraph) Paths() [][]int {
+	paths := make([][]int, len(g.Nodes))
+	if len(g.Nodes) == 0 {
+		return paths
+	}
+	children := make(map[int][]int)
+	for _, e := range g.Edges {
+		children[e.FromNode] = append(children[e.FromNode], e.ToNode)
+	}
+
+	paths[0] = []int{0}
+	queue := []int{0}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, child := range children[node] {
+			if child < 0 || child >= len(g.Nodes) || paths[child] != nil {
+				continue
+			}
+			path := make([]int, len(paths[node]), len(paths[node])+1)
+			copy(path, paths[node])
+			paths[child] = append(path, child)
+			queue = append(queue, child)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,52 @@
+package depsdev
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestGetDependencies(t *testing.T) {
+	graph := DependencyGraph{
+		Nodes: []DependencyNode{
+			{VersionKey: VersionKey{System: "NPM", Name: "@scope/app", Version: "1.0.0"}, Relation: RelationSelf},
+			{VersionKey: VersionKey{System: "NPM", Name: "a", Version: "1.0.0"}, Relation: RelationDirect},
+			{VersionKey: VersionKey{System: "NPM", Name: "b", Version: "2.0.0"}, Relation: RelationDirect},
+			{VersionKey: VersionKey{System: "NPM", Name: "c", Version: "3.0.0"}, Relation: RelationIndirect},
+			{VersionKey: VersionKey{System: "NPM", Name: "orphan", Version: "1.0.0"}, Relation: RelationIndirect},
+		},
+		Edges: []DependencyEdge{
+			{FromNode: 0, ToNode: 1}, {FromNode: 0, ToNode: 2}, {FromNode: 1, ToNode: 3}, {FromNode: 2, ToNode: 3},
+		},
+	}
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		_ = json.NewEncoder(w).Encode(graph)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(zap.NewNop(), WithBaseURL(server.URL))
+	result, err := client.GetDependencies(context.Background(), "npm", "@scope/app", "1.0.0")
+	if err != nil {
+		t.Fatalf("GetDependencies() error = %v", err)
+	}
+	if want := "/systems/npm/packages/%40scope%2Fapp/versions/1.0.0:dependencies"; gotPath != want {
+		t.Errorf("request path = %s, want %s", gotPath, want)
+	}
+	if len(result.Nodes) != 5 || len(result.Edges) != 4 {
+		t.Fatalf("graph = %+v, want 5 nodes and 4 edges", result)
+	}
+
+	paths := result.Paths()
+	if len(paths[3]) != 3 || paths[3][0] != 0 || paths[3][1] != 1 || paths[3][2] != 3 {
+		t.Errorf("path to c = %v, want shortest [0 1 3]", paths[3])
+	}
+	if paths[4] != nil {
+		t.Errorf("path to unreachable node = %v, want nil", paths[4])
+	}
+}
@@ -0,0 +1,106 @@
+package depsdev
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/reqlog"
+	"github.com/rayprogramming/PackagePulse/internal/retrybudget"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultMaxRetries is how many times a 429 or 5xx response is retried
+	DefaultMaxRetries = 2
+	// DefaultRetryBaseDelay is the first backoff delay; each retry doubles it
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	// maxRetryDelay caps both exponential backoff and server-provided Retry-After values
+	maxRetryDelay = 30 * time.Second
+)
+
+// WithRetries sets how many times 429 and 5xx responses are retried and the
+// initial backoff delay. A maxRetries of zero disables retries.
+func WithRetries(maxRetries int, baseDelay time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryBaseDelay = baseDelay
+	}
+}
+
+// WithRetryBudget draws every retry from a budget shared with other clients, so when it is
+// exhausted a retryable response is returned to the caller without retrying
+func WithRetryBudget(b *retrybudget.Budget) Option {
+	return func(c *Client) {
+		c.retryBudget = b
+	}
+}
+
+// doWithRetry executes a bodiless request, retrying 429 and 5xx responses with
+// exponential backoff. A Retry-After header overrides the computed delay.
+// Other statuses, including 404, are returned to the caller immediately, as is a
+// retryable response once the retry budget is exhausted.
+func (c *Client) doWithRetry(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if !isRetryable(resp.StatusCode) || attempt >= c.maxRetries {
+			return resp, nil
+		}
+		if c.retryBudget != nil && !c.retryBudget.Allow() {
+			reqlog.Logger(req.Context(), c.logger).Warn("retry budget exhausted, not retrying deps.dev request",
+				zap.String("url", req.URL.String()),
+				zap.Int("status", resp.StatusCode))
+			return resp, nil
+		}
+
+		delay := retryDelay(c.retryBaseDelay, attempt, resp.Header.Get("Retry-After"))
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		reqlog.Logger(req.Context(), c.logger).Warn("retrying deps.dev request",
+			zap.String("url", req.URL.String()),
+			zap.Int("status", resp.StatusCode),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("delay", delay))
+
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isRetryable reports whether a status indicates a transient failure
+func isRetryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// retryDelay returns the wait before the next attempt, preferring the
+// server's Retry-After (seconds or HTTP date) over exponential backoff
+func retryDelay(base time.Duration, attempt int, retryAfter string) time.Duration {
+	delay := base << attempt
+	if retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			delay = time.Duration(seconds) * time.Second
+		} else if at, err := http.ParseTime(retryAfter); err == nil {
+			delay = max(time.Until(at), 0)
+		}
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,152 @@
+package depsdev
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/retrybudget"
+	"go.uber.org/zap"
+)
+
+// flakyServer fails the first `failures` requests with status, then serves express
+func flakyServer(t *testing.T, failures int64, status int, retryAfter string) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			http.Error(w, "unavailable", status)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(PackageInfo{PackageKey: PackageKey{System: "NPM", Name: "express"}})
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestGetPackage_RetriesTransientErrors(t *testing.T) {
+	server, requests := flakyServer(t, 1, http.StatusServiceUnavailable, "")
+	client := NewClient(zap.NewNop(), WithBaseURL(server.URL), WithRetries(2, time.Millisecond))
+
+	pkg, err := client.GetPackage(context.Background(), "npm", "express")
+	if err != nil {
+		t.Fatalf("GetPackage() error = %v", err)
+	}
+	if pkg.PackageKey.Name != "express" {
+		t.Errorf("PackageKey.Name = %s, want express", pkg.PackageKey.Name)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("server received %d requests, want 2", n)
+	}
+}
+
+func TestGetPackage_RetriesExhausted(t *testing.T) {
+	server, requests := flakyServer(t, 10, http.StatusTooManyRequests, "0")
+	client := NewClient(zap.NewNop(), WithBaseURL(server.URL), WithRetries(2, time.Millisecond))
+
+	_, err := client.GetPackage(context.Background(), "npm", "express")
+	if err == nil || !strings.Contains(err.Error(), "status=429") {
+		t.Fatalf("GetPackage() error = %v, want status=429", err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("server received %d requests, want 3 (1 + 2 retries)", n)
+	}
+}
+
+func TestGetPackage_NotFoundIsNotRetried(t *testing.T) {
+	server, requests := flakyServer(t, 10, http.StatusNotFound, "")
+	client := NewClient(zap.NewNop(), WithBaseURL(server.URL), WithRetries(2, time.Millisecond))
+
+	_, err := client.GetPackage(context.Background(), "npm", "missing")
+	if err == nil || !strings.Contains(err.Error(), "package not found") {
+		t.Fatalf("GetPackage() error = %v, want package not found", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("server received %d requests, want 1", n)
+	}
+}
+
+func TestGetPackage_CancelledDuringBackoff(t *testing.T) {
+	server, requests := flakyServer(t, 10, http.StatusServiceUnavailable, "")
+	client := NewClient(zap.NewNop(), WithBaseURL(server.URL), WithRetries(5, time.Minute))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetPackage(ctx, "npm", "express")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("GetPackage() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("GetPackage() took %v, should stop backing off when the context ends", elapsed)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("server received %d requests, want 1", n)
+	}
+}
+
+func TestGetPackage_RetryBudgetCapsStorm(t *testing.T) {
+	server, requests := flakyServer(t, 1<<30, http.StatusServiceUnavailable, "")
+	// The budget never refills during the test, so only its capacity can be spent
+	const capacity = 5
+	budget := retrybudget.New(capacity, time.Hour)
+	clients := []*Client{
+		NewClient(zap.NewNop(), WithBaseURL(server.URL), WithRetries(3, time.Millisecond), WithRetryBudget(budget)),
+		NewClient(zap.NewNop(), WithBaseURL(server.URL), WithRetries(3, time.Millisecond), WithRetryBudget(budget)),
+	}
+
+	const calls = 50
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := clients[i%len(clients)].GetPackage(context.Background(), "npm", "express"); err == nil {
+				t.Error("GetPackage() against a failing upstream succeeded")
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Without the budget the calls would make 200 requests: each first attempt and 3 retries
+	if retries := requests.Load() - calls; retries > capacity {
+		t.Errorf("%d retries reached the upstream, want at most the budget of %d", retries, capacity)
+	}
+	status := budget.Status()
+	if status.Granted != capacity || !status.Exhausted || status.Denied == 0 {
+		t.Errorf("budget = %+v, want all %d retries spent and later ones denied", status, capacity)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	base := 100 * time.Millisecond
+
+	if got := retryDelay(base, 0, ""); got != base {
+		t.Errorf("attempt 0 delay = %v, want %v", got, base)
+	}
+	if got := retryDelay(base, 2, ""); got != 4*base {
+		t.Errorf("attempt 2 delay = %v, want %v", got, 4*base)
+	}
+	if got := retryDelay(base, 0, "3"); got != 3*time.Second {
+		t.Errorf("Retry-After seconds delay = %v, want 3s", got)
+	}
+	if got := retryDelay(base, 0, "3600"); got != maxRetryDelay {
+		t.Errorf("Retry-After delay = %v, want cap %v", got, maxRetryDelay)
+	}
+	date := time.Now().Add(5 * time.Second).UTC().Format(http.TimeFormat)
+	if got := retryDelay(base, 0, date); got <= 3*time.Second || got > 5*time.Second {
+		t.Errorf("Retry-After date delay = %v, want about 5s", got)
+	}
+}
@@ -0,0 +1,244 @@
+package depsdev
+
+import (
+	"strings"
+)
+
+// VersionScheme orders and classifies the versions of one ecosystem
+type VersionScheme interface {
+	// Compare returns -1, 0, or 1 as a sorts before, with, or after b
+	Compare(a, b string) int
+	// IsPrerelease reports whether a version is a prerelease rather than a stable release
+	IsPrerelease(version string) bool
+}
+
+// Version schemes. SemverScheme is the default; Maven and NuGet versions do not follow semver.
+var (
+	SemverScheme VersionScheme = semverScheme{}
+	MavenScheme  VersionScheme = mavenScheme{}
+	NuGetScheme  VersionScheme = nugetScheme{}
+)
+
+// SchemeFor returns the version scheme of an ecosystem, accepting OSV ("Maven") and
+// deps.dev ("MAVEN") spellings. Ecosystems without a scheme of their own use SemverScheme.
+func SchemeFor(ecosystem string) VersionScheme {
+	switch strings.ToLower(strings.TrimSpace(ecosystem)) {
+	case "maven":
+		return MavenScheme
+	case "nuget":
+		return NuGetScheme
+	}
+	return SemverScheme
+}
+
+// LatestStableVersion returns the highest version of a package that is neither a prerelease
+// nor yanked, ordered by the package's ecosystem scheme, or "" when there is none
+func LatestStableVersion(pkg *PackageInfo) string {
+	return LatestMatching(pkg, false, false)
+}
+
+// LatestVersion returns the highest version of a package that is not yanked, prereleases
+// included, ordered by the package's ecosystem scheme
+func LatestVersion(pkg *PackageInfo) string {
+	return LatestMatching(pkg, true, false)
+}
+
+// LatestMatching returns the highest version of a package ordered by its ecosystem scheme,
+// counting prereleases and yanked (IsDeprecated) releases only when asked to, or "" when no
+// version qualifies
+func LatestMatching(pkg *PackageInfo, includePrerelease, includeYanked bool) string {
+	scheme := SchemeFor(pkg.PackageKey.System)
+	latest := ""
+	for _, v := range pkg.Versions {
+		version := v.VersionKey.Version
+		if !includePrerelease && scheme.IsPrerelease(version) {
+			continue
+		}
+		if !includeYanked && v.IsDeprecated {
+			continue
+		}
+		if latest == "" || scheme.Compare(version, latest) > 0 {
+			latest = version
+		}
+	}
+	return latest
+}
+
+// semverScheme orders versions with CompareVersions
+type semverScheme struct{}
+
+func (semverScheme) Compare(a, b string) int          { return CompareVersions(a, b) }
+func (semverScheme) IsPrerelease(version string) bool { return IsPrerelease(version) }
+
+// nugetScheme follows NuGet's SemVer 2.0 superset: up to four numeric parts, where missing
+// parts count as zero ("1.2.3" == "1.2.3.0"), and case-insensitive prerelease labels
+type nugetScheme struct{}
+
+func (nugetScheme) Compare(a, b string) int {
+	return CompareVersions(strings.ToLower(a), strings.ToLower(b))
+}
+
+func (nugetScheme) IsPrerelease(version string) bool {
+	_, pre := splitVersion(version)
+	return pre != ""
+}
+
+// mavenQualifierRanks orders Maven's well-known qualifiers. A release ("", "ga", "final",
+// "release") sorts after every prerelease and before service packs; other qualifiers sort
+// after all of them, alphabetically.
+var mavenQualifierRanks = map[string]int{
+	"alpha":     1,
+	"beta":      2,
+	"milestone": 3,
+	"rc":        4,
+	"snapshot":  5,
+	"":          6,
+	"sp":        7,
+}
+
+// mavenReleaseRank is the rank of a plain release
+const mavenReleaseRank = 6
+
+// mavenQualifierAliases maps Maven's qualifier shorthands and synonyms to their canonical form
+var mavenQualifierAliases = map[string]string{
+	"a":       "alpha",
+	"b":       "beta",
+	"m":       "milestone",
+	"cr":      "rc",
+	"ga":      "",
+	"final":   "",
+	"release": "",
+}
+
+// mavenScheme follows Maven's ComparableVersion rules closely enough for upgrade decisions:
+// "1.0-RELEASE" == "1.0", "1.0-alpha-1" < "1.0-rc1" < "1.0" < "1.0-sp1", and any number of
+// numeric parts ("2020.1", "1.2.3.4")
+type mavenScheme struct{}
+
+// mavenItem is one token of a Maven version: a number or a qualifier
+type mavenItem struct {
+	numeric   bool
+	number    int
+	qualifier string
+}
+
+func (mavenScheme) Compare(a, b string) int {
+	aItems, bItems := mavenItems(a), mavenItems(b)
+	for i := 0; i < max(len(aItems), len(bItems)); i++ {
+		var c int
+		switch {
+		case i >= len(aItems):
+			c = -bItems[i].compareToNull()
+		case i >= len(bItems):
+			c = aItems[i].compareToNull()
+		default:
+			c = aItems[i].compare(bItems[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func (mavenScheme) IsPrerelease(version string) bool {
+	for _, item := range mavenItems(version) {
+		if !item.numeric && item.rank() < mavenReleaseRank {
+			return true
+		}
+	}
+	return false
+}
+
+// mavenItems tokenizes a Maven version at '.', '-', and digit/letter boundaries. Zero and
+// release items are trimmed before a qualifier and at the end, as Maven does, so
+// "1.0.0-RELEASE" and "1" yield the same items.
+func mavenItems(version string) []mavenItem {
+	version = strings.ToLower(strings.TrimSpace(version))
+
+	var tokens []string
+	start := 0
+	for i := 0; i <= len(version); i++ {
+		if i < len(version) {
+			c := version[i]
+			if c != '.' && c != '-' && (i == start || isDigit(c) == isDigit(version[i-1])) {
+				continue
+			}
+		}
+		if i > start {
+			tokens = append(tokens, version[start:i])
+		}
+		start = i
+		if i < len(version) && (version[i] == '.' || version[i] == '-') {
+			start = i + 1
+		}
+	}
+
+	var items []mavenItem
+	for _, token := range tokens {
+		if isDigit(token[0]) {
+			n, _ := splitComponent(token)
+			items = append(items, mavenItem{numeric: true, number: n})
+			continue
+		}
+		if alias, ok := mavenQualifierAliases[token]; ok {
+			token = alias
+		}
+		items = trimMavenNulls(items)
+		items = append(items, mavenItem{qualifier: token})
+	}
+	return trimMavenNulls(items)
+}
+
+// trimMavenNulls drops trailing items equal to an absent one: zeros and plain releases
+func trimMavenNulls(items []mavenItem) []mavenItem {
+	for len(items) > 0 && items[len(items)-1].compareToNull() == 0 {
+		items = items[:len(items)-1]
+	}
+	return items
+}
+
+func (m mavenItem) rank() int {
+	if rank, ok := mavenQualifierRanks[m.qualifier]; ok {
+		return rank
+	}
+	return len(mavenQualifierRanks) + 1
+}
+
+// compare orders two items; a number sorts after any qualifier
+func (m mavenItem) compare(o mavenItem) int {
+	switch {
+	case m.numeric && o.numeric:
+		return compareInts(m.number, o.number)
+	case m.numeric:
+		return 1
+	case o.numeric:
+		return -1
+	}
+	if c := compareInts(m.rank(), o.rank()); c != 0 {
+		return c
+	}
+	return strings.Compare(m.qualifier, o.qualifier)
+}
+
+// compareToNull orders an item against a missing one, which counts as 0 or a plain release
+func (m mavenItem) compareToNull() int {
+	if m.numeric {
+		return compareInts(m.number, 0)
+	}
+	return compareInts(m.rank(), mavenReleaseRank)
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
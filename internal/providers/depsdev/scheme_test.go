@@ -0,0 +1,82 @@
+package depsdev
+
+import "testing"
+
+func TestVersionSchemes(t *testing.T) {
+	tests := []struct {
+		ecosystem string
+		a, b      string
+		want      int
+	}{
+		// Maven
+		{"Maven", "1.0-RELEASE", "1.1", -1},
+		{"Maven", "1.0-RELEASE", "1.0", 0},
+		{"Maven", "1.0.Final", "1.0.0", 0},
+		{"Maven", "1.0-alpha-1", "1.0-beta-1", -1},
+		{"Maven", "1.0-rc1", "1.0", -1},
+		{"Maven", "1.0-CR1", "1.0-rc2", -1},
+		{"Maven", "1.0-SNAPSHOT", "1.0", -1},
+		{"Maven", "1.0-M2", "1.0-RC1", -1},
+		{"Maven", "1.0-sp1", "1.0", 1},
+		{"Maven", "1.2.3.4", "1.2.3.10", -1},
+		{"Maven", "2020.1", "2019.12", 1},
+		{"Maven", "31.1-jre", "31.1", 1},
+		{"MAVEN", "1.0.1", "1.0-RELEASE", 1},
+		// NuGet
+		{"NuGet", "1.2.3.4", "1.2.3.10", -1},
+		{"NuGet", "1.2.3.4", "1.2.4", -1},
+		{"NuGet", "1.2.3", "1.2.3.0", 0},
+		{"NuGet", "1.2.3.4", "1.2.3", 1},
+		{"NuGet", "1.0.0-Beta", "1.0.0-beta", 0},
+		{"NUGET", "1.0.0-beta.2", "1.0.0", -1},
+		// Others fall back to semver
+		{"npm", "1.0.0-rc.1", "1.0.0", -1},
+	}
+	for _, tt := range tests {
+		if got := SchemeFor(tt.ecosystem).Compare(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: Compare(%q, %q) = %d, want %d", tt.ecosystem, tt.a, tt.b, got, tt.want)
+		}
+		if got := SchemeFor(tt.ecosystem).Compare(tt.b, tt.a); got != -tt.want {
+			t.Errorf("%s: Compare(%q, %q) = %d, want %d", tt.ecosystem, tt.b, tt.a, got, -tt.want)
+		}
+	}
+
+	prereleases := []struct {
+		ecosystem, version string
+		want               bool
+	}{
+		{"Maven", "1.0-RELEASE", false},
+		{"Maven", "31.1-jre", false},
+		{"Maven", "1.0-SNAPSHOT", true},
+		{"Maven", "2.0.0-M1", true},
+		{"Maven", "5.3.0.Final", false},
+		{"NuGet", "1.2.3.4", false},
+		{"NuGet", "1.2.3-preview.1", true},
+	}
+	for _, tt := range prereleases {
+		if got := SchemeFor(tt.ecosystem).IsPrerelease(tt.version); got != tt.want {
+			t.Errorf("%s: IsPrerelease(%q) = %v, want %v", tt.ecosystem, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestLatestStableVersion(t *testing.T) {
+	tests := []struct {
+		system   string
+		versions []string
+		want     string
+	}{
+		{"MAVEN", []string{"1.0-RELEASE", "1.1-RC1", "1.0.1", "0.9"}, "1.0.1"},
+		{"NUGET", []string{"1.2.3.4", "1.2.3.10", "1.2.4-beta"}, "1.2.3.10"},
+		{"NPM", []string{"2.0.0-rc.1", "1.9.0"}, "1.9.0"},
+	}
+	for _, tt := range tests {
+		pkg := &PackageInfo{PackageKey: PackageKey{System: tt.system, Name: "pkg"}}
+		for _, v := range tt.versions {
+			pkg.Versions = append(pkg.Versions, VersionInfo{VersionKey: VersionKey{Version: v}})
+		}
+		if got := LatestStableVersion(pkg); got != tt.want {
+			t.Errorf("LatestStableVersion(%s %v) = %q, want %q", tt.system, tt.versions, got, tt.want)
+		}
+	}
+}
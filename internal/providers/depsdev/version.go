@@ -0,0 +1,277 @@
+package depsdev
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Go pseudo-versions name an untagged commit: vX.0.0-yyyymmddhhmmss-abcdefabcdef with no
+// tagged ancestor, vX.Y.Z-pre.0.yyyymmddhhmmss-abcdefabcdef after a prerelease, and
+// vX.Y.(Z+1)-0.yyyymmddhhmmss-abcdefabcdef after a release. Any of them may end in +incompatible.
+var pseudoVersionPattern = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)-(?:(?:(.+)\.)?(0)\.)?(\d{14})-([0-9a-f]{12})(?:\+incompatible)?$`)
+
+// pseudoTimestampLayout is the UTC commit time embedded in a pseudo-version
+const pseudoTimestampLayout = "20060102150405"
+
+// PseudoVersion is the commit a Go pseudo-version names
+type PseudoVersion struct {
+	// Base is the tagged version the commit follows, or "" when it has no tagged ancestor
+	Base   string    `json:"base,omitempty"`
+	Time   time.Time `json:"time"`
+	Commit string    `json:"commit"`
+}
+
+// ParsePseudoVersion decodes a Go pseudo-version, reporting false for any other version
+func ParsePseudoVersion(version string) (*PseudoVersion, bool) {
+	m := pseudoVersionPattern.FindStringSubmatch(strings.TrimSpace(version))
+	if m == nil {
+		return nil, false
+	}
+	committed, err := time.Parse(pseudoTimestampLayout, m[6])
+	if err != nil {
+		return nil, false
+	}
+	pv := &PseudoVersion{Time: committed, Commit: m[7]}
+
+	major, minor, patch, pre, hasBase := m[1], m[2], m[3], m[4], m[5] != ""
+	switch {
+	case !hasBase:
+		// vX.0.0-timestamp-commit: no tagged ancestor
+	case pre != "":
+		pv.Base = fmt.Sprintf("v%s.%s.%s-%s", major, minor, patch, pre)
+	default:
+		// The patch was incremented past the tagged release
+		if p, err := strconv.Atoi(patch); err == nil && p > 0 {
+			pv.Base = fmt.Sprintf("v%s.%s.%d", major, minor, p-1)
+		}
+	}
+	return pv, true
+}
+
+// IsPseudoVersion reports whether a version is a Go pseudo-version
+func IsPseudoVersion(version string) bool {
+	_, ok := ParsePseudoVersion(version)
+	return ok
+}
+
+// CanonicalGoVersion normalizes a Go module version for comparison: a "v" prefix is added
+// and the +incompatible suffix, which marks a v2+ module without a /vN path, is dropped
+func CanonicalGoVersion(version string) string {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return ""
+	}
+	version = strings.TrimSuffix(version, "+incompatible")
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return version
+}
+
+// MajorVersion returns the numeric major component of a version
+func MajorVersion(version string) int {
+	return releaseNumbers(version)[0]
+}
+
+// IsPrerelease reports whether a version carries a prerelease marker, either a
+// semver "-suffix" ("2.0.0-rc.1") or letters in a release component ("2.0.0rc1").
+func IsPrerelease(version string) bool {
+	main, pre := splitVersion(version)
+	if pre != "" {
+		return true
+	}
+	for _, part := range strings.Split(main, ".") {
+		if _, suffix := splitComponent(part); suffix != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareVersions orders dotted version strings component by component,
+// returning -1, 0, or 1. Numeric components compare numerically, missing
+// components count as zero, and a release sorts after its prereleases
+// ("1.0.0-rc.1" < "1.0.0", "1.0.0rc1" < "1.0.0"). Build metadata, including Go's
+// +incompatible, is ignored. Prereleases follow semver precedence, so a Go pseudo-version sorts
+// after the release it was derived from and before the next one, and pseudo-versions of the
+// same base order by their commit timestamp.
+func CompareVersions(a, b string) int {
+	aMain, aPre := splitVersion(a)
+	bMain, bPre := splitVersion(b)
+
+	if c := compareDotted(aMain, bMain); c != 0 {
+		return c
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return comparePrerelease(aPre, bPre)
+}
+
+// comparePrerelease applies semver prerelease precedence: identifiers compare left to right,
+// numeric ones numerically and below alphanumeric ones, and a longer list of otherwise equal
+// identifiers sorts later ("rc.1" < "rc.1.0"). Pseudo-version "timestamp-commit" identifiers
+// compare by timestamp.
+func comparePrerelease(a, b string) int {
+	aIDs := strings.Split(a, ".")
+	bIDs := strings.Split(b, ".")
+	for i := 0; i < min(len(aIDs), len(bIDs)); i++ {
+		if c := compareIdentifier(aIDs[i], bIDs[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(aIDs) < len(bIDs):
+		return -1
+	case len(aIDs) > len(bIDs):
+		return 1
+	}
+	return 0
+}
+
+func compareIdentifier(a, b string) int {
+	aNum, aErr := strconv.Atoi(a)
+	bNum, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		if aNum != bNum {
+			if aNum < bNum {
+				return -1
+			}
+			return 1
+		}
+		return 0
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	// A pseudo-version's "yyyymmddhhmmss-commit" orders by its fixed-width timestamp
+	if aStamp, _, ok := strings.Cut(a, "-"); ok && len(aStamp) == len(pseudoTimestampLayout) {
+		if bStamp, _, ok := strings.Cut(b, "-"); ok && len(bStamp) == len(pseudoTimestampLayout) && aStamp != bStamp {
+			return strings.Compare(aStamp, bStamp)
+		}
+	}
+	return compareComponent(a, b)
+}
+
+// Version gap levels, named for the most significant component that differs
+const (
+	GapNone  = "none"
+	GapMajor = "major"
+	GapMinor = "minor"
+	GapPatch = "patch"
+)
+
+// VersionGap is the distance from one version to a newer one. Only the most significant
+// differing component is counted: 1.2.3 to 3.0.1 is two majors, 4.17.19 to 4.17.21 two patches.
+type VersionGap struct {
+	Level string `json:"level"`
+	Major int    `json:"major"`
+	Minor int    `json:"minor"`
+	Patch int    `json:"patch"`
+}
+
+// ComputeVersionGap measures how far current trails latest. A current version at or
+// beyond latest has no gap.
+func ComputeVersionGap(current, latest string) VersionGap {
+	gap := VersionGap{Level: GapNone}
+	if CompareVersions(current, latest) >= 0 {
+		return gap
+	}
+
+	c := releaseNumbers(current)
+	l := releaseNumbers(latest)
+	switch {
+	case l[0] != c[0]:
+		gap.Level, gap.Major = GapMajor, l[0]-c[0]
+	case l[1] != c[1]:
+		gap.Level, gap.Minor = GapMinor, l[1]-c[1]
+	case l[2] != c[2]:
+		gap.Level, gap.Patch = GapPatch, l[2]-c[2]
+	default:
+		// Same release numbers: current is a prerelease of latest or differs past the patch
+		gap.Level = GapPatch
+	}
+	return gap
+}
+
+// releaseNumbers returns the numeric major, minor, and patch components of a version
+func releaseNumbers(version string) [3]int {
+	var nums [3]int
+	main, _ := splitVersion(version)
+	for i, part := range strings.SplitN(main, ".", 4) {
+		if i == len(nums) {
+			break
+		}
+		nums[i], _ = splitComponent(part)
+	}
+	return nums
+}
+
+// splitVersion separates the release part from a semver prerelease, dropping
+// any "v" prefix and "+build" metadata
+func splitVersion(version string) (main, pre string) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, _, _ = strings.Cut(version, "+")
+	main, pre, _ = strings.Cut(version, "-")
+	return main, pre
+}
+
+func compareDotted(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < max(len(aParts), len(bParts)); i++ {
+		var ap, bp string
+		if i < len(aParts) {
+			ap = aParts[i]
+		}
+		if i < len(bParts) {
+			bp = bParts[i]
+		}
+		if c := compareComponent(ap, bp); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareComponent compares "12" < "13", "3rc1" < "3", and falls back to
+// lexical order for wholly non-numeric identifiers
+func compareComponent(a, b string) int {
+	an, aSuffix := splitComponent(a)
+	bn, bSuffix := splitComponent(b)
+	if an != bn {
+		if an < bn {
+			return -1
+		}
+		return 1
+	}
+	switch {
+	case aSuffix == bSuffix:
+		return 0
+	case aSuffix == "":
+		return 1
+	case bSuffix == "":
+		return -1
+	}
+	return strings.Compare(aSuffix, bSuffix)
+}
+
+// splitComponent splits a version component into its leading number and the remainder
+func splitComponent(part string) (int, string) {
+	i := 0
+	for i < len(part) && part[i] >= '0' && part[i] <= '9' {
+		i++
+	}
+	n, _ := strconv.Atoi(part[:i])
+	return n, part[i:]
+}
@@ -0,0 +1,172 @@
+package epss
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/rayprogramming/PackagePulse/internal/cassette"
+	"github.com/rayprogramming/PackagePulse/internal/reqlog"
+	"github.com/rayprogramming/PackagePulse/internal/stats"
+	"github.com/rayprogramming/PackagePulse/internal/useragent"
+	"go.uber.org/zap"
+)
+
+const (
+	APIBaseURL = "https://api.first.org/data/v1"
+	ScorePath  = "/epss"
+
+	// maxCVEsPerRequest keeps the query string well under common URL length limits
+	maxCVEsPerRequest = 100
+)
+
+// ErrNotFound is returned when EPSS has no score for a CVE
+var ErrNotFound = errors.New("no EPSS score")
+
+// Client handles FIRST EPSS API interactions
+type Client struct {
+	httpClient *http.Client
+	logger     *zap.Logger
+	baseURL    string
+}
+
+// Option configures optional Client behavior
+type Option func(*Client)
+
+// WithBaseURL points the client at an alternate EPSS API (e.g. a test server)
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithUserAgent sets the User-Agent header on every request the client sends
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = useragent.Transport(ua, c.httpClient.Transport)
+	}
+}
+
+// WithStats records the outcome and latency of every request the client sends
+func WithStats(u *stats.Upstream) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = u.Transport(c.httpClient.Transport)
+	}
+}
+
+// WithCassette records every response to, or replays it from, a cassette; see cassette.New.
+// A nil cassette leaves the client talking to the network as usual.
+func WithCassette(cs *cassette.Cassette) Option {
+	return func(c *Client) {
+		if cs != nil {
+			c.httpClient.Transport = cs.Transport(c.httpClient.Transport)
+		}
+	}
+}
+
+// NewClient creates a new EPSS API client
+func NewClient(logger *zap.Logger, opts ...Option) *Client {
+	c := &Client{
+		httpClient: &http.Client{},
+		logger:     logger,
+		baseURL:    APIBaseURL,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Score is the exploit prediction for a single CVE
+type Score struct {
+	CVE         string  `json:"cve"`
+	Probability float64 `json:"probability"`
+	Percentile  float64 `json:"percentile"`
+}
+
+type scoreResponse struct {
+	Status string `json:"status"`
+	Data   []struct {
+		CVE        string `json:"cve"`
+		EPSS       string `json:"epss"`
+		Percentile string `json:"percentile"`
+	} `json:"data"`
+}
+
+// GetScore returns the EPSS probability and percentile for a CVE
+// Example: client.GetScore(ctx, "CVE-2021-44228")
+func (c *Client) GetScore(ctx context.Context, cveID string) (probability, percentile float64, err error) {
+	scores, err := c.GetScores(ctx, []string{cveID})
+	if err != nil {
+		return 0, 0, err
+	}
+	score, ok := scores[strings.ToUpper(cveID)]
+	if !ok {
+		return 0, 0, fmt.Errorf("%w: %s", ErrNotFound, cveID)
+	}
+	return score.Probability, score.Percentile, nil
+}
+
+// GetScores returns EPSS scores keyed by upper-case CVE ID. CVEs without a score are omitted.
+func (c *Client) GetScores(ctx context.Context, cveIDs []string) (map[string]Score, error) {
+	scores := make(map[string]Score, len(cveIDs))
+	for start := 0; start < len(cveIDs); start += maxCVEsPerRequest {
+		end := min(start+maxCVEsPerRequest, len(cveIDs))
+		if err := c.fetch(ctx, cveIDs[start:end], scores); err != nil {
+			return nil, err
+		}
+	}
+	return scores, nil
+}
+
+func (c *Client) fetch(ctx context.Context, cveIDs []string, scores map[string]Score) error {
+	endpoint := fmt.Sprintf("%s%s?cve=%s", c.baseURL, ScorePath, url.QueryEscape(strings.Join(cveIDs, ",")))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	reqlog.Logger(ctx, c.logger).Debug("querying EPSS", zap.Int("cve_count", len(cveIDs)))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("execute request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("EPSS API error: status=%d body=%s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var result scoreResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	for _, d := range result.Data {
+		probability, err := strconv.ParseFloat(d.EPSS, 64)
+		if err != nil {
+			return fmt.Errorf("parse epss for %s: %w", d.CVE, err)
+		}
+		percentile, err := strconv.ParseFloat(d.Percentile, 64)
+		if err != nil {
+			return fmt.Errorf("parse percentile for %s: %w", d.CVE, err)
+		}
+		id := strings.ToUpper(d.CVE)
+		scores[id] = Score{CVE: id, Probability: probability, Percentile: percentile}
+	}
+
+	reqlog.Logger(ctx, c.logger).Debug("EPSS query complete", zap.Int("scores_found", len(result.Data)))
+
+	return nil
+}
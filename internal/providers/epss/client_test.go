@@ -0,0 +1,47 @@
+package epss
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestEPSSClientGetScore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != ScorePath {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.URL.Query().Get("cve"); got != "CVE-2021-44228" && got != "CVE-0000-0000" {
+			t.Errorf("unexpected cve query %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("cve") == "CVE-0000-0000" {
+			_, _ = w.Write([]byte(`{"status":"OK","data":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"OK","data":[{"cve":"CVE-2021-44228","epss":"0.975660000","percentile":"0.999990000","date":"2024-01-01"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(zap.NewNop(), WithBaseURL(server.URL))
+
+	probability, percentile, err := client.GetScore(context.Background(), "CVE-2021-44228")
+	if err != nil {
+		t.Fatalf("GetScore() error = %v", err)
+	}
+	if probability != 0.97566 {
+		t.Errorf("probability = %v, want 0.97566", probability)
+	}
+	if percentile != 0.99999 {
+		t.Errorf("percentile = %v, want 0.99999", percentile)
+	}
+
+	if _, _, err := client.GetScore(context.Background(), "CVE-0000-0000"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetScore() for unscored CVE error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,316 @@
+package ghsa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/cassette"
+	"github.com/rayprogramming/PackagePulse/internal/reqlog"
+	"github.com/rayprogramming/PackagePulse/internal/stats"
+	"github.com/rayprogramming/PackagePulse/internal/useragent"
+	"go.uber.org/zap"
+)
+
+const (
+	APIBaseURL = "https://api.github.com/graphql"
+
+	// pageSize is the largest page the GraphQL API allows for securityVulnerabilities
+	pageSize = 100
+)
+
+// ecosystems maps OSV ecosystem names to GitHub's SecurityAdvisoryEcosystem enum
+var ecosystems = map[string]string{
+	"npm":       "NPM",
+	"pypi":      "PIP",
+	"go":        "GO",
+	"maven":     "MAVEN",
+	"crates.io": "RUST",
+	"nuget":     "NUGET",
+	"rubygems":  "RUBYGEMS",
+	"packagist": "COMPOSER",
+	"pub":       "PUB",
+	"hex":       "ERLANG",
+	"swifturl":  "SWIFT",
+}
+
+// Ecosystem returns GitHub's ecosystem name for an OSV ecosystem, or "" if GitHub does not track it
+func Ecosystem(osvEcosystem string) string {
+	return ecosystems[strings.ToLower(osvEcosystem)]
+}
+
+// Client handles GitHub Security Advisory GraphQL API interactions
+type Client struct {
+	httpClient *http.Client
+	logger     *zap.Logger
+	baseURL    string
+	token      string
+}
+
+// Option configures optional Client behavior
+type Option func(*Client)
+
+// WithBaseURL points the client at an alternate GraphQL endpoint (e.g. GitHub Enterprise or a test server)
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithUserAgent sets the User-Agent header on every request the client sends
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = useragent.Transport(ua, c.httpClient.Transport)
+	}
+}
+
+// WithStats records the outcome and latency of every request the client sends
+func WithStats(u *stats.Upstream) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = u.Transport(c.httpClient.Transport)
+	}
+}
+
+// WithCassette records every response to, or replays it from, a cassette; see cassette.New.
+// A nil cassette leaves the client talking to the network as usual.
+func WithCassette(cs *cassette.Cassette) Option {
+	return func(c *Client) {
+		if cs != nil {
+			c.httpClient.Transport = cs.Transport(c.httpClient.Transport)
+		}
+	}
+}
+
+// WithToken sets the GitHub token used to authenticate. The GraphQL API rejects anonymous requests.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// NewClient creates a new GitHub Security Advisory client
+func NewClient(logger *zap.Logger, opts ...Option) *Client {
+	c := &Client{
+		httpClient: &http.Client{},
+		logger:     logger,
+		baseURL:    APIBaseURL,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Enabled reports whether a token is configured; without one the source is skipped
+func (c *Client) Enabled() bool {
+	return c.token != ""
+}
+
+// Identifier is an advisory identifier such as a GHSA or CVE ID
+type Identifier struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Advisory is a GitHub Security Advisory
+type Advisory struct {
+	GHSAID      string       `json:"ghsaId"`
+	Summary     string       `json:"summary"`
+	Description string       `json:"description"`
+	Severity    string       `json:"severity"`
+	PublishedAt time.Time    `json:"publishedAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+	WithdrawnAt *time.Time   `json:"withdrawnAt"`
+	Permalink   string       `json:"permalink"`
+	Identifiers []Identifier `json:"identifiers"`
+	References  []struct {
+		URL string `json:"url"`
+	} `json:"references"`
+	CVSS struct {
+		Score        float64 `json:"score"`
+		VectorString string  `json:"vectorString"`
+	} `json:"cvss"`
+}
+
+// Vulnerability is an advisory's effect on one package
+type Vulnerability struct {
+	Advisory               Advisory `json:"advisory"`
+	VulnerableVersionRange string   `json:"vulnerableVersionRange"`
+	FirstPatchedVersion    *struct {
+		Identifier string `json:"identifier"`
+	} `json:"firstPatchedVersion"`
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+}
+
+// PatchedVersion returns the first patched version, or "" if there is none
+func (v Vulnerability) PatchedVersion() string {
+	if v.FirstPatchedVersion == nil {
+		return ""
+	}
+	return v.FirstPatchedVersion.Identifier
+}
+
+// Affects reports whether version falls inside the vulnerable range, e.g. ">= 1.0.0, < 1.2.3".
+// compare orders two versions of the package's ecosystem. An empty version matches every range.
+func (v Vulnerability) Affects(version string, compare func(a, b string) int) (bool, error) {
+	if version == "" {
+		return true, nil
+	}
+	for _, clause := range strings.Split(v.VulnerableVersionRange, ",") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+
+		op, bound := "=", clause
+		for _, candidate := range []string{">=", "<=", ">", "<", "="} {
+			if rest, ok := strings.CutPrefix(clause, candidate); ok {
+				op, bound = candidate, strings.TrimSpace(rest)
+				break
+			}
+		}
+		if bound == "" {
+			return false, fmt.Errorf("invalid version range %q", v.VulnerableVersionRange)
+		}
+
+		c := compare(version, bound)
+		var ok bool
+		switch op {
+		case ">=":
+			ok = c >= 0
+		case "<=":
+			ok = c <= 0
+		case ">":
+			ok = c > 0
+		case "<":
+			ok = c < 0
+		default:
+			ok = c == 0
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+const vulnerabilitiesQuery = `query($ecosystem: SecurityAdvisoryEcosystem!, $package: String!, $first: Int!, $after: String) {
+  securityVulnerabilities(ecosystem: $ecosystem, package: $package, first: $first, after: $after) {
+    nodes {
+      vulnerableVersionRange
+      firstPatchedVersion { identifier }
+      package { name ecosystem }
+      advisory {
+        ghsaId summary description severity publishedAt updatedAt withdrawnAt permalink
+        identifiers { type value }
+        references { url }
+        cvss { score vectorString }
+      }
+    }
+    pageInfo { hasNextPage endCursor }
+  }
+}`
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// vulnerabilityPage is one page of the securityVulnerabilities connection
+type vulnerabilityPage struct {
+	Nodes    []Vulnerability `json:"nodes"`
+	PageInfo struct {
+		HasNextPage bool   `json:"hasNextPage"`
+		EndCursor   string `json:"endCursor"`
+	} `json:"pageInfo"`
+}
+
+type graphQLResponse struct {
+	Data struct {
+		SecurityVulnerabilities vulnerabilityPage `json:"securityVulnerabilities"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// Query returns every non-withdrawn advisory affecting a package, across all versions.
+// ecosystem is an OSV ecosystem name; use Affects to narrow the result to one version.
+// Example: client.Query(ctx, "npm", "lodash")
+func (c *Client) Query(ctx context.Context, ecosystem, name string) ([]Vulnerability, error) {
+	ghEcosystem := Ecosystem(ecosystem)
+	if ghEcosystem == "" {
+		return nil, fmt.Errorf("ecosystem %q is not tracked by GitHub Security Advisories", ecosystem)
+	}
+
+	reqlog.Logger(ctx, c.logger).Debug("querying GitHub advisories",
+		zap.String("ecosystem", ghEcosystem),
+		zap.String("package", name))
+
+	var vulns []Vulnerability
+	variables := map[string]interface{}{"ecosystem": ghEcosystem, "package": name, "first": pageSize}
+	for {
+		page, err := c.fetch(ctx, variables)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range page.Nodes {
+			if v.Advisory.WithdrawnAt == nil {
+				vulns = append(vulns, v)
+			}
+		}
+		if !page.PageInfo.HasNextPage || page.PageInfo.EndCursor == "" {
+			break
+		}
+		variables["after"] = page.PageInfo.EndCursor
+	}
+
+	reqlog.Logger(ctx, c.logger).Debug("GitHub advisory query complete", zap.Int("vulns_found", len(vulns)))
+
+	return vulns, nil
+}
+
+func (c *Client) fetch(ctx context.Context, variables map[string]interface{}) (*vulnerabilityPage, error) {
+	body, err := json.Marshal(graphQLRequest{Query: vulnerabilitiesQuery, Variables: variables})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("execute request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("GitHub API error: status=%d body=%s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var result graphQLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return nil, fmt.Errorf("GitHub API error: %s", result.Errors[0].Message)
+	}
+
+	return &result.Data.SecurityVulnerabilities, nil
+}
@@ -0,0 +1,109 @@
+package ghsa
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestClientQuery_Paginates(t *testing.T) {
+	pages := []string{
+		`{"data":{"securityVulnerabilities":{"nodes":[
+			{"vulnerableVersionRange":"< 4.17.21","firstPatchedVersion":{"identifier":"4.17.21"},"package":{"name":"lodash","ecosystem":"NPM"},
+			 "advisory":{"ghsaId":"GHSA-35jh-r3h4-6jhm","severity":"HIGH","identifiers":[{"type":"GHSA","value":"GHSA-35jh-r3h4-6jhm"},{"type":"CVE","value":"CVE-2021-23337"}]}}],
+			"pageInfo":{"hasNextPage":true,"endCursor":"c1"}}}}`,
+		`{"data":{"securityVulnerabilities":{"nodes":[
+			{"vulnerableVersionRange":"< 1.0.0","package":{"name":"lodash","ecosystem":"NPM"},
+			 "advisory":{"ghsaId":"GHSA-xxxx-xxxx-xxxx","withdrawnAt":"2022-01-01T00:00:00Z"}}],
+			"pageInfo":{"hasNextPage":false}}}}`,
+	}
+
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("Authorization = %q, want bearer token", got)
+		}
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if req.Variables["ecosystem"] != "NPM" || req.Variables["package"] != "lodash" {
+			t.Errorf("variables = %v, want NPM/lodash", req.Variables)
+		}
+		if calls == 1 && req.Variables["after"] != "c1" {
+			t.Errorf("second page after = %v, want c1", req.Variables["after"])
+		}
+		_, _ = w.Write([]byte(pages[calls]))
+		calls++
+	}))
+	defer server.Close()
+
+	client := NewClient(zap.NewNop(), WithBaseURL(server.URL), WithToken("test-token"))
+	vulns, err := client.Query(context.Background(), "npm", "lodash")
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("made %d requests, want 2", calls)
+	}
+	// The withdrawn advisory on page two is dropped
+	if len(vulns) != 1 || vulns[0].Advisory.GHSAID != "GHSA-35jh-r3h4-6jhm" || vulns[0].PatchedVersion() != "4.17.21" {
+		t.Fatalf("vulns = %+v, want the one active advisory", vulns)
+	}
+}
+
+func TestClientQuery_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errors":[{"message":"Bad credentials"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(zap.NewNop(), WithBaseURL(server.URL), WithToken("bad"))
+	if _, err := client.Query(context.Background(), "npm", "lodash"); err == nil || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("Query() error = %v, want the GraphQL error", err)
+	}
+	if _, err := client.Query(context.Background(), "Debian", "openssl"); err == nil {
+		t.Error("expected an error for an ecosystem GitHub does not track")
+	}
+	if NewClient(zap.NewNop()).Enabled() {
+		t.Error("expected a client without a token to be disabled")
+	}
+}
+
+func TestVulnerabilityAffects(t *testing.T) {
+	compare := func(a, b string) int { return strings.Compare(a, b) }
+
+	tests := []struct {
+		rng     string
+		version string
+		want    bool
+	}{
+		{"< 1.5", "1.4", true},
+		{"< 1.5", "1.5", false},
+		{">= 1.0, < 1.5", "1.2", true},
+		{">= 1.0, < 1.5", "0.9", false},
+		{"<= 1.5", "1.5", true},
+		{"= 1.3", "1.3", true},
+		{"= 1.3", "1.4", false},
+		{"< 1.5", "", true},
+	}
+	for _, tt := range tests {
+		v := Vulnerability{VulnerableVersionRange: tt.rng}
+		got, err := v.Affects(tt.version, compare)
+		if err != nil {
+			t.Fatalf("Affects(%q, %q) error = %v", tt.rng, tt.version, err)
+		}
+		if got != tt.want {
+			t.Errorf("Affects(%q, %q) = %v, want %v", tt.rng, tt.version, got, tt.want)
+		}
+	}
+
+	if _, err := (Vulnerability{VulnerableVersionRange: "<"}).Affects("1.0", compare); err == nil {
+		t.Error("expected an error for a range without a bound")
+	}
+}
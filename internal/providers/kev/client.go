@@ -0,0 +1,186 @@
+package kev
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/cassette"
+	"github.com/rayprogramming/PackagePulse/internal/reqlog"
+	"github.com/rayprogramming/PackagePulse/internal/stats"
+	"github.com/rayprogramming/PackagePulse/internal/useragent"
+	"go.uber.org/zap"
+)
+
+const (
+	CatalogURL      = "https://www.cisa.gov/sites/default/files/feeds/known_exploited_vulnerabilities.json"
+	RefreshInterval = 24 * time.Hour
+	// RetryInterval is how long EnsureFresh waits after a failed download before trying again
+	RetryInterval = 15 * time.Minute
+)
+
+// Client holds an in-memory copy of the CISA Known Exploited Vulnerabilities catalog
+type Client struct {
+	httpClient *http.Client
+	logger     *zap.Logger
+	catalogURL string
+
+	mu          sync.RWMutex
+	cves        map[string]bool
+	loadedAt    time.Time
+	attemptedAt time.Time
+	refreshMu   sync.Mutex
+}
+
+// Option configures optional Client behavior
+type Option func(*Client)
+
+// WithCatalogURL loads the catalog from an alternate location (e.g. a mirror or test server)
+func WithCatalogURL(catalogURL string) Option {
+	return func(c *Client) {
+		c.catalogURL = catalogURL
+	}
+}
+
+// WithUserAgent sets the User-Agent header on every request the client sends
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = useragent.Transport(ua, c.httpClient.Transport)
+	}
+}
+
+// WithStats records the outcome and latency of every request the client sends
+func WithStats(u *stats.Upstream) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = u.Transport(c.httpClient.Transport)
+	}
+}
+
+// WithCassette records every response to, or replays it from, a cassette; see cassette.New.
+// A nil cassette leaves the client talking to the network as usual.
+func WithCassette(cs *cassette.Cassette) Option {
+	return func(c *Client) {
+		if cs != nil {
+			c.httpClient.Transport = cs.Transport(c.httpClient.Transport)
+		}
+	}
+}
+
+// NewClient creates a new KEV catalog client. The catalog is loaded lazily by EnsureFresh.
+func NewClient(logger *zap.Logger, opts ...Option) *Client {
+	c := &Client{
+		httpClient: &http.Client{},
+		logger:     logger,
+		catalogURL: CatalogURL,
+		cves:       make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+type catalog struct {
+	CatalogVersion  string `json:"catalogVersion"`
+	Vulnerabilities []struct {
+		CveID string `json:"cveID"`
+	} `json:"vulnerabilities"`
+}
+
+// IsKnownExploited reports whether the CVE is in the most recently loaded catalog
+func (c *Client) IsKnownExploited(cveID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cves[strings.ToUpper(strings.TrimSpace(cveID))]
+}
+
+// LoadedAt returns when the catalog was last refreshed (zero if never)
+func (c *Client) LoadedAt() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.loadedAt
+}
+
+// EnsureFresh refreshes the catalog if it has never been loaded or is older than RefreshInterval.
+// On failure the previous catalog stays in place and no new download is attempted for
+// RetryInterval. While a catalog is loaded, callers that arrive during a refresh use it
+// instead of waiting for the download.
+func (c *Client) EnsureFresh(ctx context.Context) error {
+	if !c.needsRefresh() {
+		return nil
+	}
+
+	if !c.refreshMu.TryLock() {
+		if !c.LoadedAt().IsZero() {
+			return nil
+		}
+		c.refreshMu.Lock()
+	}
+	defer c.refreshMu.Unlock()
+
+	// Another caller may have refreshed (or failed to) while we waited
+	if !c.needsRefresh() {
+		return nil
+	}
+	return c.Refresh(ctx)
+}
+
+// needsRefresh reports whether the catalog is stale and the last attempt is outside the retry window
+func (c *Client) needsRefresh() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return time.Since(c.loadedAt) >= RefreshInterval && time.Since(c.attemptedAt) >= RetryInterval
+}
+
+// Refresh downloads the catalog and atomically replaces the in-memory copy
+func (c *Client) Refresh(ctx context.Context) error {
+	c.mu.Lock()
+	c.attemptedAt = time.Now()
+	c.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.catalogURL, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	reqlog.Logger(ctx, c.logger).Debug("downloading KEV catalog", zap.String("url", c.catalogURL))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("execute request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("KEV catalog error: status=%d body=%s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var result catalog
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decode catalog: %w", err)
+	}
+
+	cves := make(map[string]bool, len(result.Vulnerabilities))
+	for _, v := range result.Vulnerabilities {
+		cves[strings.ToUpper(strings.TrimSpace(v.CveID))] = true
+	}
+
+	c.mu.Lock()
+	c.cves = cves
+	c.loadedAt = time.Now()
+	c.mu.Unlock()
+
+	reqlog.Logger(ctx, c.logger).Info("Loaded KEV catalog",
+		zap.String("version", result.CatalogVersion),
+		zap.Int("count", len(cves)))
+
+	return nil
+}
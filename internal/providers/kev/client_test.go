@@ -0,0 +1,100 @@
+package kev
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestKEVClient(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`{
+			"catalogVersion": "2024.01.01",
+			"vulnerabilities": [
+				{"cveID": "CVE-2021-44228", "vendorProject": "Apache", "product": "Log4j2"},
+				{"cveID": "CVE-2017-5638", "vendorProject": "Apache", "product": "Struts"}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(zap.NewNop(), WithCatalogURL(server.URL))
+
+	if client.IsKnownExploited("CVE-2021-44228") {
+		t.Error("catalog should be empty before the first refresh")
+	}
+
+	ctx := context.Background()
+	if err := client.EnsureFresh(ctx); err != nil {
+		t.Fatalf("EnsureFresh() error = %v", err)
+	}
+
+	if !client.IsKnownExploited("CVE-2021-44228") {
+		t.Error("expected CVE-2021-44228 to be known exploited")
+	}
+	if !client.IsKnownExploited("cve-2017-5638") {
+		t.Error("lookup should be case-insensitive")
+	}
+	if client.IsKnownExploited("CVE-2020-0001") {
+		t.Error("CVE-2020-0001 should not be known exploited")
+	}
+
+	// A fresh catalog is not downloaded again
+	if err := client.EnsureFresh(ctx); err != nil {
+		t.Fatalf("EnsureFresh() error = %v", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("catalog downloads = %d, want 1", got)
+	}
+}
+
+func TestKEVClient_RefreshFailureKeepsCatalog(t *testing.T) {
+	fail := atomic.Bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"vulnerabilities": [{"cveID": "CVE-2021-44228"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(zap.NewNop(), WithCatalogURL(server.URL))
+	if err := client.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	fail.Store(true)
+	if err := client.Refresh(context.Background()); err == nil {
+		t.Error("expected error from failing refresh")
+	}
+	if !client.IsKnownExploited("CVE-2021-44228") {
+		t.Error("previous catalog should survive a failed refresh")
+	}
+}
+
+func TestKEVClient_EnsureFreshBacksOffAfterFailure(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(zap.NewNop(), WithCatalogURL(server.URL))
+	if err := client.EnsureFresh(context.Background()); err == nil {
+		t.Error("expected error from failing catalog download")
+	}
+	if err := client.EnsureFresh(context.Background()); err != nil {
+		t.Errorf("EnsureFresh() within retry window error = %v, want nil", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("catalog requests = %d, want 1 (no retry within RetryInterval)", got)
+	}
+}
@@ -0,0 +1,291 @@
+package osv
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// OSV range types
+const (
+	RangeSemver    = "SEMVER"
+	RangeEcosystem = "ECOSYSTEM"
+	RangeGit       = "GIT"
+)
+
+// Results of matching a version against a vulnerability's affected data
+const (
+	StatusAffected         = "affected"
+	StatusNotAffected      = "not_affected"
+	StatusPossiblyAffected = "possibly_affected"
+)
+
+var (
+	commitPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+	// Go pseudo-versions end in a UTC timestamp and a 12-character commit prefix,
+	// e.g. v0.0.0-20230101120000-abcdef123456 or v1.2.4-0.20230101120000-abcdef123456
+	pseudoVersionPattern = regexp.MustCompile(`[-.]\d{14}-([0-9a-f]{12})(\+incompatible)?$`)
+)
+
+// IsCommitHash reports whether a version is a bare (possibly abbreviated) git commit hash.
+// All-digit strings are treated as versions, since date-based versions look like hex.
+func IsCommitHash(version string) bool {
+	v := strings.ToLower(strings.TrimSpace(version))
+	return commitPattern.MatchString(v) && strings.ContainsAny(v, "abcdef")
+}
+
+// CommitOf returns the commit a version names: the version itself when it is a commit
+// hash, or the commit prefix embedded in a Go pseudo-version. It returns "" otherwise.
+func CommitOf(version string) string {
+	v := strings.ToLower(strings.TrimSpace(version))
+	if IsCommitHash(v) {
+		return v
+	}
+	if m := pseudoVersionPattern.FindStringSubmatch(v); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// GitRanges returns the GIT ranges recorded for the named package
+func GitRanges(vuln Vulnerability, pkg string) []VersionRange {
+	var ranges []VersionRange
+	for _, affected := range vuln.Affected {
+		if !affected.matches(pkg) {
+			continue
+		}
+		for _, r := range affected.Ranges {
+			if r.Type == RangeGit {
+				ranges = append(ranges, r)
+			}
+		}
+	}
+	return ranges
+}
+
+// IsVersionAffected matches a version of pkg against a vulnerability's affected data.
+// SEMVER and ECOSYSTEM ranges are evaluated with compare, and the explicit versions list
+// is checked as-is. GIT ranges record commits, so they are consulted only for versions that
+// name a commit (see CommitOf) and decide the result only when that commit is a range event;
+// ancestry cannot be resolved without the repository. A version that cannot be placed is
+// reported as possibly affected rather than dropped.
+func IsVersionAffected(vuln Vulnerability, pkg, version string, compare func(a, b string) int) string {
+	commit := CommitOf(version)
+	// A bare hash has no version ordering; a pseudo-version has both
+	ordered := !IsCommitHash(version)
+
+	matched, decided := false, false
+	for _, affected := range vuln.Affected {
+		if !affected.matches(pkg) {
+			continue
+		}
+		matched = true
+
+		for _, v := range affected.Versions {
+			if v == version {
+				return StatusAffected
+			}
+		}
+		if len(affected.Versions) > 0 && ordered {
+			decided = true
+		}
+
+		for _, r := range affected.Ranges {
+			switch r.Type {
+			case RangeGit:
+				if commit == "" {
+					continue
+				}
+				switch gitRangeStatus(r, commit) {
+				case StatusAffected:
+					return StatusAffected
+				case StatusNotAffected:
+					decided = true
+				}
+			default:
+				if !ordered {
+					continue
+				}
+				decided = true
+				if r.contains(version, compare) {
+					return StatusAffected
+				}
+			}
+		}
+	}
+
+	if !matched || decided {
+		return StatusNotAffected
+	}
+	// Either a GIT range could not place the commit or nothing evaluable was recorded
+	return StatusPossiblyAffected
+}
+
+func (a Affected) matches(pkg string) bool {
+	return pkg == "" || a.Package.Name == "" || a.Package.Name == pkg
+}
+
+// gitRangeStatus places a commit in a GIT range when it is one of the range's events
+func gitRangeStatus(r VersionRange, commit string) string {
+	for _, e := range r.Events {
+		switch {
+		case sameCommit(e.Fixed, commit):
+			return StatusNotAffected
+		case sameCommit(e.Introduced, commit), sameCommit(e.LastAffected, commit):
+			return StatusAffected
+		}
+	}
+	return StatusPossiblyAffected
+}
+
+// sameCommit compares a full event hash with a possibly abbreviated commit
+func sameCommit(event, commit string) bool {
+	if event == "" || event == "0" {
+		return false
+	}
+	event = strings.ToLower(event)
+	return strings.HasPrefix(event, commit) || strings.HasPrefix(commit, event)
+}
+
+// contains evaluates a SEMVER or ECOSYSTEM range the way the OSV schema describes: events
+// are applied in version order, each introduced opening and each fixed or last_affected
+// closing an affected span
+func (r VersionRange) contains(version string, compare func(a, b string) int) bool {
+	affected := false
+	for _, e := range r.sortedEvents(compare) {
+		switch {
+		case e.Introduced == "0":
+			affected = true
+		case e.Introduced != "":
+			if compare(version, e.Introduced) >= 0 {
+				affected = true
+			}
+		case e.Fixed != "":
+			if compare(version, e.Fixed) >= 0 {
+				affected = false
+			}
+		case e.LastAffected != "":
+			if compare(version, e.LastAffected) > 0 {
+				affected = false
+			}
+		}
+	}
+	return affected
+}
+
+// AffectedVersions summarizes the SEMVER and ECOSYSTEM ranges recorded for pkg as version
+// constraints, e.g. ">=4.0.0, <4.17.21". Disjoint spans are ordered by their lower bound and
+// joined with " || ", a span affecting every release is "*", and "" means no range was
+// recorded. GIT ranges name commits, not versions, and are left out.
+func AffectedVersions(vuln Vulnerability, pkg string, compare func(a, b string) int) string {
+	type span struct{ lower, text string }
+	var spans []span
+	seen := make(map[string]bool)
+	add := func(lower, upper string) {
+		var parts []string
+		if lower != "" {
+			parts = append(parts, ">="+lower)
+		}
+		if upper != "" {
+			parts = append(parts, upper)
+		}
+		text := strings.Join(parts, ", ")
+		if text == "" {
+			text = "*"
+		}
+		if !seen[text] {
+			seen[text] = true
+			spans = append(spans, span{lower, text})
+		}
+	}
+
+	for _, affected := range vuln.Affected {
+		if !affected.matches(pkg) {
+			continue
+		}
+		for _, r := range affected.Ranges {
+			if r.Type == RangeGit {
+				continue
+			}
+			open, lower := false, ""
+			for _, e := range r.sortedEvents(compare) {
+				switch {
+				case e.Introduced != "":
+					// A repeated introduced inside an open span leaves its earlier start in place
+					if !open && e.Introduced != "0" {
+						lower = e.Introduced
+					}
+					open = true
+				case open && e.Fixed != "":
+					add(lower, "<"+e.Fixed)
+					open, lower = false, ""
+				case open && e.LastAffected != "":
+					add(lower, "<="+e.LastAffected)
+					open, lower = false, ""
+				}
+			}
+			if open {
+				add(lower, "")
+			}
+		}
+	}
+
+	// "" is a span open since the first release and sorts first
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].lower == "" || spans[j].lower == "" {
+			return spans[i].lower == "" && spans[j].lower != ""
+		}
+		return compare(spans[i].lower, spans[j].lower) < 0
+	})
+	texts := make([]string, len(spans))
+	for i, s := range spans {
+		texts[i] = s.text
+	}
+	return strings.Join(texts, " || ")
+}
+
+// FixedVersions lists the releases recorded as fixing vuln in pkg, lowest first
+func FixedVersions(vuln Vulnerability, pkg string, compare func(a, b string) int) []string {
+	var fixed []string
+	for _, affected := range vuln.Affected {
+		if !affected.matches(pkg) {
+			continue
+		}
+		for _, r := range affected.Ranges {
+			if r.Type == RangeGit {
+				continue
+			}
+			for _, e := range r.Events {
+				if e.Fixed != "" && !slices.Contains(fixed, e.Fixed) {
+					fixed = append(fixed, e.Fixed)
+				}
+			}
+		}
+	}
+	slices.SortStableFunc(fixed, compare)
+	return fixed
+}
+
+// sortedEvents returns a range's events in version order
+func (r VersionRange) sortedEvents(compare func(a, b string) int) []Event {
+	events := append([]Event(nil), r.Events...)
+	// "0" means "since the first release" and sorts below every version
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Introduced == "0" || events[j].Introduced == "0" {
+			return events[i].Introduced == "0" && events[j].Introduced != "0"
+		}
+		return compare(events[i].version(), events[j].version()) < 0
+	})
+	return events
+}
+
+func (e Event) version() string {
+	switch {
+	case e.Introduced != "":
+		return e.Introduced
+	case e.Fixed != "":
+		return e.Fixed
+	}
+	return e.LastAffected
+}
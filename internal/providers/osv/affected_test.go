@@ -0,0 +1,222 @@
+package osv
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// compareSemver orders plain dotted versions, which is all the fixtures need
+func compareSemver(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(strings.SplitN(as[i], "-", 2)[0])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(strings.SplitN(bs[i], "-", 2)[0])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func loadGitFixture(t *testing.T) Vulnerability {
+	t.Helper()
+	data, err := os.ReadFile("testdata/GO-2099-0001.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var vuln Vulnerability
+	if err := json.Unmarshal(data, &vuln); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+	return vuln
+}
+
+func TestCommitOf(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"3f1c2b4a5d6e7f8091a2b3c4d5e6f708192a3b4c", "3f1c2b4a5d6e7f8091a2b3c4d5e6f708192a3b4c"},
+		{"3F1C2B4", "3f1c2b4"},
+		{"v0.0.0-20240101120000-3f1c2b4a5d6e", "3f1c2b4a5d6e"},
+		{"v1.2.4-0.20240101120000-3f1c2b4a5d6e", "3f1c2b4a5d6e"},
+		{"v2.0.1-0.20240101120000-3f1c2b4a5d6e+incompatible", "3f1c2b4a5d6e"},
+		{"1.2.3", ""},
+		// Date-based versions are all digits and are not commits
+		{"20240101", ""},
+	}
+	for _, tt := range tests {
+		if got := CommitOf(tt.version); got != tt.want {
+			t.Errorf("CommitOf(%q) = %q, want %q", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestGitRanges(t *testing.T) {
+	vuln := loadGitFixture(t)
+
+	ranges := GitRanges(vuln, "example.com/widget")
+	if len(ranges) != 1 {
+		t.Fatalf("GitRanges() returned %d ranges, want 1", len(ranges))
+	}
+	if ranges[0].Repo != "https://github.com/example/widget" {
+		t.Errorf("Repo = %q, want https://github.com/example/widget", ranges[0].Repo)
+	}
+	if got := GitRanges(vuln, "example.com/other"); len(got) != 0 {
+		t.Errorf("GitRanges() for another package = %v, want none", got)
+	}
+}
+
+func TestIsVersionAffected(t *testing.T) {
+	vuln := loadGitFixture(t)
+
+	tests := []struct {
+		name    string
+		pkg     string
+		version string
+		want    string
+	}{
+		{"semver inside range", "example.com/widget", "v1.3.0", StatusAffected},
+		{"semver at fix", "example.com/widget", "v1.4.1", StatusNotAffected},
+		{"semver below range", "example.com/widget", "v1.1.0", StatusNotAffected},
+		{"introducing commit", "example.com/widget", "3f1c2b4a5d6e7f8091a2b3c4d5e6f708192a3b4c", StatusAffected},
+		{"abbreviated fixing commit", "example.com/widget", "9e8d7c6", StatusNotAffected},
+		{"unplaceable commit", "example.com/widget", "abcdef0123456789", StatusPossiblyAffected},
+		// The pseudo-version's base orders it inside the SEMVER range
+		{"pseudo-version", "example.com/widget", "v1.3.1-0.20240101120000-abcdef012345", StatusAffected},
+		{"other package", "example.com/other", "v1.3.0", StatusNotAffected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsVersionAffected(vuln, tt.pkg, tt.version, compareSemver); got != tt.want {
+				t.Errorf("IsVersionAffected(%q) = %q, want %q", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAffectedPackages(t *testing.T) {
+	data, err := os.ReadFile("testdata/GHSA-9999-mlti-eco1.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var vuln Vulnerability
+	if err := json.Unmarshal(data, &vuln); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+
+	got := vuln.AffectedPackages()
+	want := []Package{
+		{Ecosystem: "npm", Name: "widget-codec"},
+		{Ecosystem: "PyPI", Name: "widget-codec"},
+		{Ecosystem: "Maven", Name: "com.example:widget-codec-java"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("AffectedPackages() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("AffectedPackages()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if pkgs := (Vulnerability{}).AffectedPackages(); pkgs != nil {
+		t.Errorf("AffectedPackages() without affected entries = %+v, want nil", pkgs)
+	}
+
+	// One package listed once with a purl and once without is still one package
+	withPURL := Vulnerability{Affected: []Affected{
+		{Package: Package{Ecosystem: "npm", Name: "lodash", PURL: "pkg:npm/lodash"}},
+		{Package: Package{Ecosystem: "npm", Name: "lodash"}},
+	}}
+	if pkgs := withPURL.AffectedPackages(); len(pkgs) != 1 || pkgs[0].PURL != "pkg:npm/lodash" {
+		t.Errorf("AffectedPackages() = %+v, want lodash once, as first listed", pkgs)
+	}
+}
+
+func TestAffectedVersions(t *testing.T) {
+	vuln := func(ranges ...[]Event) Vulnerability {
+		affected := Affected{Package: Package{Ecosystem: "npm", Name: "lodash"}}
+		for _, events := range ranges {
+			affected.Ranges = append(affected.Ranges, VersionRange{Type: RangeSemver, Events: events})
+		}
+		return Vulnerability{ID: "GHSA-test", Affected: []Affected{affected}}
+	}
+
+	tests := []struct {
+		name      string
+		vuln      Vulnerability
+		want      string
+		wantFixed []string
+	}{
+		{
+			name:      "introduced and fixed",
+			vuln:      vuln([]Event{{Introduced: "4.0.0"}, {Fixed: "4.17.21"}}),
+			want:      ">=4.0.0, <4.17.21",
+			wantFixed: []string{"4.17.21"},
+		},
+		{
+			name:      "since the first release",
+			vuln:      vuln([]Event{{Introduced: "0"}, {Fixed: "1.2.3"}}),
+			want:      "<1.2.3",
+			wantFixed: []string{"1.2.3"},
+		},
+		{
+			name: "last affected",
+			vuln: vuln([]Event{{Introduced: "2.0.0"}, {LastAffected: "2.4.1"}}),
+			want: ">=2.0.0, <=2.4.1",
+		},
+		{
+			name:      "introduced twice before a fix",
+			vuln:      vuln([]Event{{Introduced: "1.0.0"}, {Introduced: "1.5.0"}, {Fixed: "2.0.0"}}),
+			want:      ">=1.0.0, <2.0.0",
+			wantFixed: []string{"2.0.0"},
+		},
+		{
+			name: "never fixed",
+			vuln: vuln([]Event{{Introduced: "0"}}),
+			want: "*",
+		},
+		{
+			// Disjoint spans in one range and across ranges, listed out of order
+			name: "disjoint ranges",
+			vuln: vuln(
+				[]Event{{Introduced: "4.0.0"}, {Fixed: "4.17.21"}, {Introduced: "3.0.0"}, {Fixed: "3.10.2"}},
+				[]Event{{Introduced: "0"}, {Fixed: "2.4.3"}},
+			),
+			want:      "<2.4.3 || >=3.0.0, <3.10.2 || >=4.0.0, <4.17.21",
+			wantFixed: []string{"2.4.3", "3.10.2", "4.17.21"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AffectedVersions(tt.vuln, "lodash", compareSemver); got != tt.want {
+				t.Errorf("AffectedVersions() = %q, want %q", got, tt.want)
+			}
+			if got := FixedVersions(tt.vuln, "lodash", compareSemver); !slices.Equal(got, tt.wantFixed) {
+				t.Errorf("FixedVersions() = %v, want %v", got, tt.wantFixed)
+			}
+			if got := AffectedVersions(tt.vuln, "underscore", compareSemver); got != "" {
+				t.Errorf("AffectedVersions() for another package = %q, want none", got)
+			}
+		})
+	}
+
+	// GIT ranges name commits and are left out of the summary
+	if got := AffectedVersions(loadGitFixture(t), "example.com/widget", compareSemver); strings.Contains(got, "3f1c2b4") {
+		t.Errorf("AffectedVersions() = %q, want no commits", got)
+	}
+}
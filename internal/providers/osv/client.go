@@ -7,8 +7,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/rayprogramming/PackagePulse/internal/breaker"
+	"github.com/rayprogramming/PackagePulse/internal/cassette"
+	"github.com/rayprogramming/PackagePulse/internal/decode"
+	"github.com/rayprogramming/PackagePulse/internal/pool"
+	"github.com/rayprogramming/PackagePulse/internal/reqlog"
+	"github.com/rayprogramming/PackagePulse/internal/stats"
+	"github.com/rayprogramming/PackagePulse/internal/tracing"
+	"github.com/rayprogramming/PackagePulse/internal/useragent"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -16,23 +27,111 @@ const (
 	APIBaseURL = "https://api.osv.dev/v1"
 	QueryPath  = "/query"
 	BatchPath  = "/querybatch"
-	Timeout    = 30 * time.Second
+	VulnsPath  = "/vulns/"
 )
 
+// hydrateConcurrency bounds how many advisory detail requests BatchQueryDetailed has in flight
+const hydrateConcurrency = 8
+
+// EcosystemNuGet is OSV's name for the NuGet ecosystem. OSV matches NuGet
+// package IDs case-sensitively, so callers should pass the registry's casing.
+const EcosystemNuGet = "NuGet"
+
 // Client handles OSV API interactions
 type Client struct {
 	httpClient *http.Client
 	logger     *zap.Logger
+	baseURL    string
+}
+
+// Option configures optional Client behavior
+type Option func(*Client)
+
+// WithBaseURL points the client at an alternate OSV API (e.g. a mirror or test server)
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithTransport sends every request through rt in place of http.DefaultTransport, for example
+// to present a client certificate to a TLS-inspecting proxy. It replaces the transport built up
+// so far, so pass it before the options that wrap it. A nil rt leaves the default in place.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		if rt != nil {
+			c.httpClient.Transport = rt
+		}
+	}
+}
+
+// WithHTTPClient sends requests with a copy of hc, keeping its timeout, redirect policy, and
+// cookie jar. Like WithTransport it replaces what earlier options set up, so pass it first.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc != nil {
+			clone := *hc
+			c.httpClient = &clone
+		}
+	}
+}
+
+// WithUserAgent sets the User-Agent header on every request the client sends
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = useragent.Transport(ua, c.httpClient.Transport)
+	}
+}
+
+// WithStats records the outcome and latency of every request the client sends
+func WithStats(u *stats.Upstream) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = u.Transport(c.httpClient.Transport)
+	}
+}
+
+// WithCassette records every response to, or replays it from, a cassette; see cassette.New.
+// A nil cassette leaves the client talking to the network as usual.
+func WithCassette(cs *cassette.Cassette) Option {
+	return func(c *Client) {
+		if cs != nil {
+			c.httpClient.Transport = cs.Transport(c.httpClient.Transport)
+		}
+	}
+}
+
+// WithBreaker routes every request through a circuit breaker so calls fail fast with a
+// *breaker.OpenError while OSV is down
+func WithBreaker(b *breaker.Breaker) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = b.Transport(c.httpClient.Transport)
+	}
+}
+
+// WithTracerProvider gives every request a client span under the span in its context. Pass it
+// after WithBreaker so requests the breaker turns away are traced too. A nil tp adds nothing.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = tracing.Transport(tp, "osv", c.httpClient.Transport)
+	}
 }
 
 // NewClient creates a new OSV API client
-func NewClient(logger *zap.Logger) *Client {
-	return &Client{
-		httpClient: &http.Client{
-			Timeout: Timeout,
-		},
-		logger: logger,
+func NewClient(logger *zap.Logger, opts ...Option) *Client {
+	c := &Client{
+		httpClient: &http.Client{},
+		logger:     logger,
+		baseURL:    APIBaseURL,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
+}
+
+// BaseURL returns the OSV API base URL the client queries
+func (c *Client) BaseURL() string {
+	return c.baseURL
 }
 
 // QueryRequest represents an OSV vulnerability query
@@ -41,10 +140,11 @@ type QueryRequest struct {
 	Version string  `json:"version,omitempty"`
 }
 
-// Package identifies the package ecosystem and name
+// Package identifies the package ecosystem and name, or a package URL standing in for both
 type Package struct {
-	Name      string `json:"name"`
-	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name,omitempty"`
+	Ecosystem string `json:"ecosystem,omitempty"`
+	PURL      string `json:"purl,omitempty"`
 }
 
 // QueryResponse contains vulnerability results
@@ -54,15 +154,116 @@ type QueryResponse struct {
 
 // Vulnerability represents a single vulnerability entry
 type Vulnerability struct {
-	ID         string      `json:"id"`
-	Summary    string      `json:"summary"`
-	Details    string      `json:"details"`
-	Published  time.Time   `json:"published"`
-	Modified   time.Time   `json:"modified"`
-	Severity   []Severity  `json:"severity,omitempty"`
-	Affected   []Affected  `json:"affected,omitempty"`
-	References []Reference `json:"references,omitempty"`
-	Aliases    []string    `json:"aliases,omitempty"`
+	SchemaVersion string      `json:"schema_version,omitempty"`
+	ID            string      `json:"id"`
+	Summary       string      `json:"summary"`
+	Details       string      `json:"details"`
+	Published     time.Time   `json:"published"`
+	Modified      time.Time   `json:"modified"`
+	Severity      []Severity  `json:"severity,omitempty"`
+	Affected      []Affected  `json:"affected,omitempty"`
+	References    []Reference `json:"references,omitempty"`
+	Aliases       []string    `json:"aliases,omitempty"`
+	// Related names entries about a similar but distinct issue; Upstream names the entries this
+	// one was derived from, e.g. the CVE behind a distribution advisory
+	Related          []string               `json:"related,omitempty"`
+	Upstream         []string               `json:"upstream,omitempty"`
+	DatabaseSpecific map[string]interface{} `json:"database_specific,omitempty"`
+}
+
+// databases maps advisory ID prefixes to the database that publishes them
+var databases = []struct {
+	prefix string
+	name   string
+}{
+	{"GHSA-", "GitHub Advisory Database"},
+	{"GO-", "Go Vulnerability Database"},
+	{"PYSEC-", "PyPI Advisory Database"},
+	{"RUSTSEC-", "RustSec Advisory Database"},
+	{"MAL-", "OpenSSF Malicious Packages"},
+	{"OSV-", "OSS-Fuzz"},
+	{"CVE-", "NVD"},
+}
+
+// Database names the advisory database an entry originates from, based on its ID prefix.
+// It returns "" for prefixes it does not know.
+func (v Vulnerability) Database() string {
+	id := strings.ToUpper(v.ID)
+	for _, db := range databases {
+		if strings.HasPrefix(id, db.prefix) {
+			return db.name
+		}
+	}
+	return ""
+}
+
+// AffectedPackages returns the distinct packages an entry affects, by ecosystem and name, in
+// the order listed. Advisories for a project published to several registries name one package
+// per ecosystem. A package listed both with and without a purl appears once, as first listed.
+func (v Vulnerability) AffectedPackages() []Package {
+	var packages []Package
+	seen := make(map[Package]bool)
+	for _, a := range v.Affected {
+		key := Package{Ecosystem: a.Package.Ecosystem, Name: a.Package.Name}
+		if a.Package.Name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		packages = append(packages, a.Package)
+	}
+	return packages
+}
+
+// CWEIDs returns the weakness identifiers (e.g. "CWE-79") an entry records in its
+// database_specific "cwe_ids", upper-cased and deduplicated. Entries without CWE data return nil.
+func (v Vulnerability) CWEIDs() []string {
+	raw, ok := v.DatabaseSpecific["cwe_ids"].([]interface{})
+	if !ok {
+		return nil
+	}
+	var ids []string
+	seen := make(map[string]bool)
+	for _, r := range raw {
+		id, ok := r.(string)
+		if !ok {
+			continue
+		}
+		id = strings.ToUpper(strings.TrimSpace(id))
+		if !strings.HasPrefix(id, "CWE-") || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// DatabaseSeverity returns the qualitative rating an entry records in its database_specific
+// "severity", as GitHub advisories do: critical, high, medium (GitHub's "moderate"), or low.
+// Entries without a recognized rating return "".
+func (v Vulnerability) DatabaseSeverity() string {
+	raw, _ := v.DatabaseSpecific["severity"].(string)
+	switch rating := strings.ToLower(strings.TrimSpace(raw)); rating {
+	case "critical", "high", "medium", "low":
+		return rating
+	case "moderate":
+		return "medium"
+	}
+	return ""
+}
+
+// Source returns the database_specific "source" of an entry, the URL of the record in its
+// originating database. Entries that only record it per affected package use the first one.
+func (v Vulnerability) Source() string {
+	if source, ok := v.DatabaseSpecific["source"].(string); ok && source != "" {
+		return source
+	}
+	for _, a := range v.Affected {
+		if source, ok := a.DatabaseSpecific["source"].(string); ok && source != "" {
+			return source
+		}
+	}
+	return ""
 }
 
 // Severity contains severity scoring information
@@ -75,6 +276,7 @@ type Severity struct {
 type Affected struct {
 	Package           Package                `json:"package"`
 	Ranges            []VersionRange         `json:"ranges,omitempty"`
+	Versions          []string               `json:"versions,omitempty"`
 	DatabaseSpecific  map[string]interface{} `json:"database_specific,omitempty"`
 	EcosystemSpecific map[string]interface{} `json:"ecosystem_specific,omitempty"`
 }
@@ -82,13 +284,15 @@ type Affected struct {
 // VersionRange specifies the range of affected versions
 type VersionRange struct {
 	Type   string  `json:"type"`
+	Repo   string  `json:"repo,omitempty"`
 	Events []Event `json:"events"`
 }
 
 // Event represents a version event (introduced/fixed)
 type Event struct {
-	Introduced string `json:"introduced,omitempty"`
-	Fixed      string `json:"fixed,omitempty"`
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
 }
 
 // Reference contains external reference links
@@ -97,6 +301,33 @@ type Reference struct {
 	URL  string `json:"url"`
 }
 
+// Reference types defined by the OSV schema
+const (
+	ReferenceAdvisory   = "ADVISORY"
+	ReferenceArticle    = "ARTICLE"
+	ReferenceDetection  = "DETECTION"
+	ReferenceDiscussion = "DISCUSSION"
+	ReferenceReport     = "REPORT"
+	ReferenceFix        = "FIX"
+	ReferenceIntroduced = "INTRODUCED"
+	ReferencePackage    = "PACKAGE"
+	ReferenceEvidence   = "EVIDENCE"
+	ReferenceWeb        = "WEB"
+)
+
+// GroupReferences groups reference URLs by upper-cased type, keeping their original order
+func GroupReferences(refs []Reference) map[string][]string {
+	groups := make(map[string][]string)
+	for _, ref := range refs {
+		if ref.URL == "" {
+			continue
+		}
+		t := strings.ToUpper(strings.TrimSpace(ref.Type))
+		groups[t] = append(groups[t], ref.URL)
+	}
+	return groups
+}
+
 // Query queries OSV for vulnerabilities in a specific package version
 // Example: client.Query(ctx, "npm", "lodash", "4.17.19")
 func (c *Client) Query(ctx context.Context, ecosystem, name, version string) (*QueryResponse, error) {
@@ -108,22 +339,36 @@ func (c *Client) Query(ctx context.Context, ecosystem, name, version string) (*Q
 		Version: version,
 	}
 
+	reqlog.Logger(ctx, c.logger).Debug("querying OSV",
+		zap.String("ecosystem", ecosystem),
+		zap.String("package", name),
+		zap.String("version", version))
+
+	return c.query(ctx, req)
+}
+
+// QueryByPURL queries OSV for vulnerabilities in the package a package URL names, scoped to
+// the URL's version when it has one
+// Example: client.QueryByPURL(ctx, "pkg:npm/lodash@4.17.19")
+func (c *Client) QueryByPURL(ctx context.Context, purl string) (*QueryResponse, error) {
+	reqlog.Logger(ctx, c.logger).Debug("querying OSV", zap.String("purl", purl))
+
+	return c.query(ctx, QueryRequest{Package: Package{PURL: purl}})
+}
+
+// query sends one request to OSV's query endpoint
+func (c *Client) query(ctx context.Context, req QueryRequest) (*QueryResponse, error) {
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, APIBaseURL+QueryPath, bytes.NewReader(body))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+QueryPath, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	c.logger.Debug("querying OSV",
-		zap.String("ecosystem", ecosystem),
-		zap.String("package", name),
-		zap.String("version", version))
-
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("execute request: %w", err)
@@ -138,17 +383,19 @@ func (c *Client) Query(ctx context.Context, ecosystem, name, version string) (*Q
 	}
 
 	var result QueryResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decode.JSON("osv", resp.Body, &result); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
-	c.logger.Debug("OSV query complete",
+	reqlog.Logger(ctx, c.logger).Debug("OSV query complete",
 		zap.Int("vulns_found", len(result.Vulns)))
 
 	return &result, nil
 }
 
-// BatchQuery queries multiple packages in a single request
+// BatchQuery queries multiple packages in a single request. OSV's querybatch endpoint
+// returns only each vulnerability's ID and modified time; use BatchQueryDetailed for full
+// advisories.
 func (c *Client) BatchQuery(ctx context.Context, queries []QueryRequest) ([]QueryResponse, error) {
 	if len(queries) == 0 {
 		return nil, nil
@@ -161,13 +408,13 @@ func (c *Client) BatchQuery(ctx context.Context, queries []QueryRequest) ([]Quer
 		return nil, fmt.Errorf("marshal batch request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, APIBaseURL+BatchPath, bytes.NewReader(body))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+BatchPath, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("create batch request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	c.logger.Debug("batch querying OSV", zap.Int("query_count", len(queries)))
+	reqlog.Logger(ctx, c.logger).Debug("batch querying OSV", zap.Int("query_count", len(queries)))
 
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
@@ -185,11 +432,89 @@ func (c *Client) BatchQuery(ctx context.Context, queries []QueryRequest) ([]Quer
 	var result struct {
 		Results []QueryResponse `json:"results"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decode.JSON("osv", resp.Body, &result); err != nil {
 		return nil, fmt.Errorf("decode batch response: %w", err)
 	}
 
-	c.logger.Debug("OSV batch query complete", zap.Int("results", len(result.Results)))
+	reqlog.Logger(ctx, c.logger).Debug("OSV batch query complete", zap.Int("results", len(result.Results)))
 
 	return result.Results, nil
 }
+
+// BatchQueryDetailed runs BatchQuery, then fetches the full advisory for every distinct ID in
+// the results, concurrently, so summaries, severities, and affected ranges are populated.
+// An advisory that cannot be fetched fails the whole call.
+func (c *Client) BatchQueryDetailed(ctx context.Context, queries []QueryRequest) ([]QueryResponse, error) {
+	results, err := c.BatchQuery(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var ids []string
+	for _, result := range results {
+		for _, v := range result.Vulns {
+			if !seen[v.ID] {
+				seen[v.ID] = true
+				ids = append(ids, v.ID)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return results, nil
+	}
+
+	reqlog.Logger(ctx, c.logger).Debug("hydrating OSV batch results", zap.Int("vulns", len(ids)))
+
+	fetched, err := pool.Map(ctx, ids, hydrateConcurrency, c.GetVulnerability)
+	if err != nil {
+		return nil, err
+	}
+	details := make(map[string]*Vulnerability, len(ids))
+	for i, r := range fetched {
+		if r.Err != nil {
+			return nil, fmt.Errorf("hydrate %s: %w", ids[i], r.Err)
+		}
+		details[ids[i]] = r.Value
+	}
+
+	for i := range results {
+		for j, v := range results[i].Vulns {
+			results[i].Vulns[j] = *details[v.ID]
+		}
+	}
+	return results, nil
+}
+
+// GetVulnerability fetches one advisory by ID
+// Example: client.GetVulnerability(ctx, "GHSA-jf85-cpcp-j695")
+func (c *Client) GetVulnerability(ctx context.Context, id string) (*Vulnerability, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+VulnsPath+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	reqlog.Logger(ctx, c.logger).Debug("fetching OSV vulnerability", zap.String("id", id))
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("execute request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("vulnerability not found: %s", id)
+	}
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("OSV API error: status=%d body=%s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var result Vulnerability
+	if err := decode.JSON("osv", resp.Body, &result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return &result, nil
+}
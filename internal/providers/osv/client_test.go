@@ -2,9 +2,18 @@ package osv
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/rayprogramming/PackagePulse/internal/decode"
 	"go.uber.org/zap"
 )
 
@@ -88,3 +97,350 @@ func TestOSVClientBatchQuery(t *testing.T) {
 		t.Logf("Query %d: Found %d vulnerabilities", i, len(result.Vulns))
 	}
 }
+
+func TestOSVClientQuery_ContextDeadline(t *testing.T) {
+	// Hang until the client gives up or the test ends
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	client := NewClient(zap.NewNop(), WithBaseURL(server.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.Query(ctx, "npm", "lodash", "4.17.19")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Query() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Query() took %v, the context deadline should cut off the request", elapsed)
+	}
+}
+
+// stallingServer accepts each request and then stalls until the client goes away: before
+// sending headers, or with midBody after sending the start of a JSON body. arrived receives
+// once per request as it starts stalling.
+func stallingServer(t *testing.T, midBody bool) (*httptest.Server, <-chan struct{}) {
+	t.Helper()
+
+	arrived := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if midBody {
+			_, _ = w.Write([]byte(`{"vulns": [`))
+			w.(http.Flusher).Flush()
+		}
+		arrived <- struct{}{}
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+	return server, arrived
+}
+
+func TestQueryByPURL(t *testing.T) {
+	var body map[string]map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(QueryResponse{Vulns: []Vulnerability{{ID: "GHSA-35jh-r3h4-6jhm"}}})
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(zap.NewNop(), WithBaseURL(server.URL))
+	resp, err := client.QueryByPURL(context.Background(), "pkg:npm/lodash@4.17.19")
+	if err != nil {
+		t.Fatalf("QueryByPURL() error = %v", err)
+	}
+	if len(resp.Vulns) != 1 {
+		t.Errorf("QueryByPURL() vulns = %d, want 1", len(resp.Vulns))
+	}
+	// OSV rejects a purl query that also names the package or version
+	want := map[string]map[string]string{"package": {"purl": "pkg:npm/lodash@4.17.19"}}
+	if fmt.Sprint(body) != fmt.Sprint(want) {
+		t.Errorf("request body = %v, want %v", body, want)
+	}
+}
+
+func TestOSVClientQuery_TruncatedResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"vulns": [{"id": "GHSA-35jh-r3h4-6jhm", "summ`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(zap.NewNop(), WithBaseURL(server.URL))
+	_, err := client.Query(context.Background(), "npm", "lodash", "4.17.19")
+	var malformed *decode.MalformedError
+	if !errors.As(err, &malformed) {
+		t.Fatalf("Query() error = %v, want a *decode.MalformedError", err)
+	}
+	if malformed.Upstream != "osv" || !strings.Contains(err.Error(), `GHSA-35jh-r3h4-6jhm`) {
+		t.Errorf("Query() error = %v, want osv named and the body's start quoted", err)
+	}
+}
+
+func TestClient_UserAgent(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.UserAgent())
+		switch r.URL.Path {
+		case BatchPath:
+			_, _ = w.Write([]byte(`{"results": [{}]}`))
+		default:
+			_ = json.NewEncoder(w).Encode(QueryResponse{})
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(zap.NewNop(), WithBaseURL(server.URL), WithUserAgent("PackagePulse/1.2.3"))
+	if _, err := client.Query(context.Background(), "npm", "lodash", "4.17.20"); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if _, err := client.BatchQuery(context.Background(), []QueryRequest{{Package: Package{Name: "lodash", Ecosystem: "npm"}}}); err != nil {
+		t.Fatalf("BatchQuery() error = %v", err)
+	}
+	if len(got) != 2 || got[0] != "PackagePulse/1.2.3" || got[1] != "PackagePulse/1.2.3" {
+		t.Errorf("User-Agent headers = %q, want PackagePulse/1.2.3 on every request", got)
+	}
+}
+
+// recordingTransport counts the requests it carries and the User-Agent they were sent with
+type recordingTransport struct {
+	requests   int
+	userAgents []string
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.requests++
+	rt.userAgents = append(rt.userAgents, req.UserAgent())
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClient_CustomTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(QueryResponse{})
+	}))
+	t.Cleanup(server.Close)
+
+	for name, opt := range map[string]func(http.RoundTripper) Option{
+		"transport": WithTransport,
+		"http client": func(rt http.RoundTripper) Option {
+			return WithHTTPClient(&http.Client{Transport: rt, Timeout: time.Minute})
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rt := &recordingTransport{}
+			// Wrapping options applied afterwards still send through the custom transport
+			client := NewClient(zap.NewNop(), opt(rt), WithBaseURL(server.URL), WithUserAgent("PackagePulse/1.2.3"))
+			if _, err := client.Query(context.Background(), "npm", "lodash", "4.17.20"); err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			if rt.requests != 1 || rt.userAgents[0] != "PackagePulse/1.2.3" {
+				t.Errorf("custom transport saw %d requests with User-Agent %q, want 1 with PackagePulse/1.2.3", rt.requests, rt.userAgents)
+			}
+		})
+	}
+}
+
+func TestClient_CancelledMidFlight(t *testing.T) {
+	calls := map[string]func(*Client, context.Context) error{
+		"Query": func(c *Client, ctx context.Context) error {
+			_, err := c.Query(ctx, "npm", "lodash", "4.17.19")
+			return err
+		},
+		"BatchQuery": func(c *Client, ctx context.Context) error {
+			_, err := c.BatchQuery(ctx, []QueryRequest{{Package: Package{Name: "lodash", Ecosystem: "npm"}, Version: "4.17.19"}})
+			return err
+		},
+		"GetVulnerability": func(c *Client, ctx context.Context) error {
+			_, err := c.GetVulnerability(ctx, "GHSA-35jh-r3h4-6jhm")
+			return err
+		},
+	}
+	for name, call := range calls {
+		for _, midBody := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/mid_body=%v", name, midBody), func(t *testing.T) {
+				server, arrived := stallingServer(t, midBody)
+				client := NewClient(zap.NewNop(), WithBaseURL(server.URL))
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				go func() {
+					<-arrived
+					cancel()
+				}()
+
+				errc := make(chan error, 1)
+				go func() { errc <- call(client, ctx) }()
+				select {
+				case err := <-errc:
+					if !errors.Is(err, context.Canceled) {
+						t.Errorf("%s() error = %v, want context.Canceled", name, err)
+					}
+				case <-time.After(2 * time.Second):
+					t.Fatalf("%s() did not return after its context was cancelled", name)
+				}
+			})
+		}
+	}
+}
+
+func TestGroupReferences(t *testing.T) {
+	groups := GroupReferences([]Reference{
+		{Type: "ADVISORY", URL: "https://a.example/1"},
+		{Type: "fix", URL: "https://f.example/1"},
+		{Type: "ADVISORY", URL: "https://a.example/2"},
+		{Type: "WEB", URL: ""},
+	})
+
+	if got := groups[ReferenceAdvisory]; len(got) != 2 || got[0] != "https://a.example/1" {
+		t.Errorf("ADVISORY = %v, want both advisories in order", got)
+	}
+	if got := groups[ReferenceFix]; len(got) != 1 {
+		t.Errorf("FIX = %v, want the lower-case fix reference", got)
+	}
+	if _, ok := groups[ReferenceWeb]; ok {
+		t.Error("references without a URL should be skipped")
+	}
+}
+
+func TestVulnerability_Provenance(t *testing.T) {
+	data, err := os.ReadFile("testdata/GHSA-35jh-r3h4-6jhm.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	var vuln Vulnerability
+	if err := json.Unmarshal(data, &vuln); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+
+	if vuln.SchemaVersion != "1.6.0" {
+		t.Errorf("SchemaVersion = %q, want 1.6.0", vuln.SchemaVersion)
+	}
+	if got := vuln.Database(); got != "GitHub Advisory Database" {
+		t.Errorf("Database() = %q, want GitHub Advisory Database", got)
+	}
+	// The top-level database_specific has no source, so the affected entry's is used
+	if got := vuln.Source(); !strings.HasPrefix(got, "https://github.com/github/advisory-database/") {
+		t.Errorf("Source() = %q, want the advisory-database record", got)
+	}
+	if vuln.DatabaseSpecific["severity"] != "HIGH" {
+		t.Errorf("DatabaseSpecific = %v, want the raw database_specific fields", vuln.DatabaseSpecific)
+	}
+
+	vuln.DatabaseSpecific = map[string]interface{}{"source": "https://mirror.example/GHSA-35jh-r3h4-6jhm"}
+	if got := vuln.Source(); got != "https://mirror.example/GHSA-35jh-r3h4-6jhm" {
+		t.Errorf("Source() = %q, want the top-level source to win", got)
+	}
+	if got := (Vulnerability{ID: "EXAMPLE-1"}).Database(); got != "" {
+		t.Errorf("Database() = %q, want empty for an unknown prefix", got)
+	}
+}
+
+func TestVulnerability_CWEIDs(t *testing.T) {
+	data, err := os.ReadFile("testdata/GHSA-35jh-r3h4-6jhm.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var vuln Vulnerability
+	if err := json.Unmarshal(data, &vuln); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+
+	if got := vuln.CWEIDs(); strings.Join(got, ",") != "CWE-77,CWE-94" {
+		t.Errorf("CWEIDs() = %v, want [CWE-77 CWE-94]", got)
+	}
+
+	vuln.DatabaseSpecific = map[string]interface{}{"cwe_ids": []interface{}{" cwe-79", "CWE-79", "NVD-CWE-noinfo", 79}}
+	if got := vuln.CWEIDs(); strings.Join(got, ",") != "CWE-79" {
+		t.Errorf("CWEIDs() = %v, want [CWE-79] after normalizing and skipping non-CWE entries", got)
+	}
+
+	for _, specific := range []map[string]interface{}{nil, {"cwe_ids": "CWE-79"}, {"cwe_ids": []interface{}{}}} {
+		if got := (Vulnerability{DatabaseSpecific: specific}).CWEIDs(); got != nil {
+			t.Errorf("CWEIDs() with database_specific %v = %v, want nil", specific, got)
+		}
+	}
+}
+
+func TestBatchQueryDetailed(t *testing.T) {
+	full := map[string]Vulnerability{
+		"GHSA-aaaa-bbbb-cccc": {ID: "GHSA-aaaa-bbbb-cccc", Summary: "Prototype pollution"},
+		"GHSA-dddd-eeee-ffff": {ID: "GHSA-dddd-eeee-ffff", Summary: "ReDoS", Aliases: []string{"CVE-2021-0001"}},
+	}
+	var (
+		mu      sync.Mutex
+		fetches []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == BatchPath:
+			// querybatch answers with IDs only
+			_ = json.NewEncoder(w).Encode(map[string]any{"results": []map[string]any{
+				{"vulns": []map[string]string{{"id": "GHSA-aaaa-bbbb-cccc"}, {"id": "GHSA-dddd-eeee-ffff"}}},
+				{"vulns": []map[string]string{{"id": "GHSA-aaaa-bbbb-cccc"}}},
+				{},
+			}})
+		case strings.HasPrefix(r.URL.Path, VulnsPath):
+			id := strings.TrimPrefix(r.URL.Path, VulnsPath)
+			mu.Lock()
+			fetches = append(fetches, id)
+			mu.Unlock()
+			v, ok := full[id]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(v)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(zap.NewNop(), WithBaseURL(server.URL))
+	queries := []QueryRequest{
+		{Package: Package{Ecosystem: "npm", Name: "a"}},
+		{Package: Package{Ecosystem: "npm", Name: "b"}},
+		{Package: Package{Ecosystem: "npm", Name: "c"}},
+	}
+
+	ids, err := client.BatchQuery(context.Background(), queries)
+	if err != nil {
+		t.Fatalf("BatchQuery() error = %v", err)
+	}
+	if ids[0].Vulns[0].Summary != "" || len(fetches) != 0 {
+		t.Errorf("BatchQuery() should return IDs only without fetching details")
+	}
+
+	results, err := client.BatchQueryDetailed(context.Background(), queries)
+	if err != nil {
+		t.Fatalf("BatchQueryDetailed() error = %v", err)
+	}
+	if len(results) != 3 || len(results[0].Vulns) != 2 || len(results[1].Vulns) != 1 || len(results[2].Vulns) != 0 {
+		t.Fatalf("results = %+v, want 2, 1, and 0 vulns", results)
+	}
+	if results[0].Vulns[0].Summary != "Prototype pollution" || results[0].Vulns[1].Aliases[0] != "CVE-2021-0001" {
+		t.Errorf("results[0] = %+v, want hydrated advisories", results[0].Vulns)
+	}
+	if results[1].Vulns[0].Summary != "Prototype pollution" {
+		t.Errorf("results[1] = %+v, want the shared advisory hydrated", results[1].Vulns)
+	}
+	if len(fetches) != 2 {
+		t.Errorf("advisory fetches = %v, want each distinct ID once", fetches)
+	}
+}
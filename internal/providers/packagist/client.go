@@ -0,0 +1,215 @@
+package packagist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/cassette"
+	"github.com/rayprogramming/PackagePulse/internal/reqlog"
+	"github.com/rayprogramming/PackagePulse/internal/stats"
+	"github.com/rayprogramming/PackagePulse/internal/useragent"
+	"go.uber.org/zap"
+)
+
+const (
+	APIBaseURL  = "https://repo.packagist.org"
+	PackagePath = "/p2/"
+)
+
+// ErrNotFound is returned when Packagist has no package with the given name
+var ErrNotFound = errors.New("package not found")
+
+// Client handles Packagist metadata API interactions
+type Client struct {
+	httpClient *http.Client
+	logger     *zap.Logger
+	baseURL    string
+}
+
+// Option configures optional Client behavior
+type Option func(*Client)
+
+// WithBaseURL points the client at an alternate Composer repository (e.g. a mirror or test server)
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithUserAgent sets the User-Agent header on every request the client sends
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = useragent.Transport(ua, c.httpClient.Transport)
+	}
+}
+
+// WithStats records the outcome and latency of every request the client sends
+func WithStats(u *stats.Upstream) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = u.Transport(c.httpClient.Transport)
+	}
+}
+
+// WithCassette records every response to, or replays it from, a cassette; see cassette.New.
+// A nil cassette leaves the client talking to the network as usual.
+func WithCassette(cs *cassette.Cassette) Option {
+	return func(c *Client) {
+		if cs != nil {
+			c.httpClient.Transport = cs.Transport(c.httpClient.Transport)
+		}
+	}
+}
+
+// NewClient creates a new Packagist API client
+func NewClient(logger *zap.Logger, opts ...Option) *Client {
+	c := &Client{
+		httpClient: &http.Client{},
+		logger:     logger,
+		baseURL:    APIBaseURL,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// BaseURL returns the Packagist API base URL the client queries
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// Source is the VCS location a release was tagged from
+type Source struct {
+	Type      string `json:"type"`
+	URL       string `json:"url"`
+	Reference string `json:"reference"`
+}
+
+// Version is a single tagged release of a Composer package
+type Version struct {
+	Name              string    `json:"name"`
+	Version           string    `json:"version"`
+	VersionNormalized string    `json:"version_normalized"`
+	Time              time.Time `json:"time"`
+	License           []string  `json:"license"`
+	Homepage          string    `json:"homepage"`
+	Source            *Source   `json:"source"`
+}
+
+type packageResponse struct {
+	Packages map[string][]map[string]json.RawMessage `json:"packages"`
+	Minified string                                  `json:"minified"`
+}
+
+// GetVersions returns the tagged releases of a vendor-prefixed package, newest first
+// Example: client.GetVersions(ctx, "symfony/console")
+func (c *Client) GetVersions(ctx context.Context, name string) ([]Version, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if vendor, pkg, ok := strings.Cut(name, "/"); !ok || vendor == "" || pkg == "" {
+		return nil, fmt.Errorf("invalid package name %q: expected vendor/package", name)
+	}
+
+	endpoint := c.baseURL + PackagePath + name + ".json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	reqlog.Logger(ctx, c.logger).Debug("querying Packagist", zap.String("package", name))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("execute request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Packagist API error: status=%d body=%s", resp.StatusCode, string(body))
+	}
+
+	var result packageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	entries, ok := result.Packages[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if result.Minified != "" {
+		entries = expandMinified(entries)
+	}
+
+	versions := make([]Version, 0, len(entries))
+	for _, entry := range entries {
+		raw, err := json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("re-encode version: %w", err)
+		}
+		var v Version
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, fmt.Errorf("decode version: %w", err)
+		}
+		versions = append(versions, v)
+	}
+
+	reqlog.Logger(ctx, c.logger).Debug("Packagist query complete", zap.Int("versions", len(versions)))
+
+	return versions, nil
+}
+
+// expandMinified undoes Composer 2 metadata minification, where each version
+// lists only the fields that changed from the previous one and "__unset"
+// marks a field that no longer applies.
+func expandMinified(entries []map[string]json.RawMessage) []map[string]json.RawMessage {
+	expanded := make([]map[string]json.RawMessage, len(entries))
+	prev := map[string]json.RawMessage{}
+	for i, entry := range entries {
+		current := make(map[string]json.RawMessage, len(prev)+len(entry))
+		for k, v := range prev {
+			current[k] = v
+		}
+		for k, v := range entry {
+			if string(v) == `"__unset"` {
+				delete(current, k)
+				continue
+			}
+			current[k] = v
+		}
+		expanded[i] = current
+		prev = current
+	}
+	return expanded
+}
+
+// IsStable reports whether a Composer version string is a stable release
+// (no dev, alpha, beta, or RC stability suffix)
+func IsStable(version string) bool {
+	v := strings.ToLower(version)
+	for _, marker := range []string{"dev", "alpha", "beta", "rc"} {
+		if strings.Contains(v, marker) {
+			return false
+		}
+	}
+	return true
+}
+
+// TrimVersionPrefix drops the "v" that Composer keeps from git tags ("v6.3.0" -> "6.3.0")
+func TrimVersionPrefix(version string) string {
+	if len(version) > 1 && (version[0] == 'v' || version[0] == 'V') && version[1] >= '0' && version[1] <= '9' {
+		return version[1:]
+	}
+	return version
+}
@@ -0,0 +1,96 @@
+package packagist
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// minifiedConsole is a trimmed Composer 2 metadata response in minified form
+const minifiedConsole = `{
+  "packages": {
+    "symfony/console": [
+      {"name": "symfony/console", "version": "v6.3.1", "time": "2023-06-20T08:00:00+00:00", "license": ["MIT"], "homepage": "https://symfony.com", "source": {"type": "git", "url": "https://github.com/symfony/console.git", "reference": "abc"}},
+      {"version": "v6.3.0", "time": "2023-05-29T12:49:39+00:00"},
+      {"version": "v6.3.0-RC1", "time": "2023-05-10T08:00:00+00:00", "homepage": "__unset"}
+    ]
+  },
+  "minified": "composer/2.0"
+}`
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != PackagePath+"symfony/console.json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(minifiedConsole))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetVersions(t *testing.T) {
+	client := NewClient(zap.NewNop(), WithBaseURL(newTestServer(t).URL))
+
+	versions, err := client.GetVersions(context.Background(), "Symfony/Console")
+	if err != nil {
+		t.Fatalf("GetVersions() error = %v", err)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("got %d versions, want 3", len(versions))
+	}
+
+	// Fields omitted from minified entries are inherited from the previous version
+	second := versions[1]
+	if second.Version != "v6.3.0" || second.Name != "symfony/console" {
+		t.Errorf("versions[1] = %s@%s, want symfony/console@v6.3.0", second.Name, second.Version)
+	}
+	if len(second.License) != 1 || second.License[0] != "MIT" {
+		t.Errorf("versions[1] license = %v, want [MIT]", second.License)
+	}
+	if second.Source == nil || second.Source.URL != "https://github.com/symfony/console.git" {
+		t.Errorf("versions[1] source = %+v, want inherited source", second.Source)
+	}
+	if second.Time.IsZero() {
+		t.Error("versions[1] time should be parsed")
+	}
+
+	// "__unset" removes an inherited field
+	if versions[2].Homepage != "" {
+		t.Errorf("versions[2] homepage = %q, want unset", versions[2].Homepage)
+	}
+}
+
+func TestGetVersions_Errors(t *testing.T) {
+	client := NewClient(zap.NewNop(), WithBaseURL(newTestServer(t).URL))
+
+	if _, err := client.GetVersions(context.Background(), "console"); err == nil {
+		t.Error("expected error for name without vendor prefix")
+	}
+	if _, err := client.GetVersions(context.Background(), "acme/missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetVersions(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestIsStable(t *testing.T) {
+	tests := map[string]bool{
+		"v6.3.0":     true,
+		"2.4.1":      true,
+		"v6.3.0-RC1": false,
+		"1.0.0-beta": false,
+		"dev-main":   false,
+		"2.x-dev":    false,
+	}
+	for version, want := range tests {
+		if got := IsStable(version); got != want {
+			t.Errorf("IsStable(%q) = %v, want %v", version, got, want)
+		}
+	}
+}
@@ -2,18 +2,70 @@ package spdx
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/rayprogramming/PackagePulse/internal/cassette"
+	"github.com/rayprogramming/PackagePulse/internal/reqlog"
+	"github.com/rayprogramming/PackagePulse/internal/stats"
+	"github.com/rayprogramming/PackagePulse/internal/useragent"
 	"go.uber.org/zap"
 )
 
-// Client provides access to SPDX license information
+// LicenseListURL is the machine-readable SPDX license list fetched by Reload
+const LicenseListURL = "https://spdx.org/licenses/licenses.json"
+
+// Client provides access to SPDX license information. The license map is replaced
+// wholesale by Reload, so lookups only need a read lock.
 type Client struct {
-	logger   *zap.Logger
+	httpClient *http.Client
+	logger     *zap.Logger
+	listURL    string
+
+	mu       sync.RWMutex
 	licenses map[string]*LicenseInfo
 }
 
+// Option configures optional Client behavior
+type Option func(*Client)
+
+// WithLicenseListURL points Reload at an alternate SPDX license list (e.g. a mirror or test server)
+func WithLicenseListURL(url string) Option {
+	return func(c *Client) {
+		c.listURL = url
+	}
+}
+
+// WithUserAgent sets the User-Agent header on every request the client sends
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = useragent.Transport(ua, c.httpClient.Transport)
+	}
+}
+
+// WithStats records the outcome and latency of every request the client sends
+func WithStats(u *stats.Upstream) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = u.Transport(c.httpClient.Transport)
+	}
+}
+
+// WithCassette records every response to, or replays it from, a cassette; see cassette.New.
+// A nil cassette leaves the client talking to the network as usual.
+func WithCassette(cs *cassette.Cassette) Option {
+	return func(c *Client) {
+		if cs != nil {
+			c.httpClient.Transport = cs.Transport(c.httpClient.Transport)
+		}
+	}
+}
+
 // LicenseInfo represents structured license data
 type LicenseInfo struct {
 	ID            string   `json:"id"`
@@ -26,24 +78,37 @@ type LicenseInfo struct {
 	Comments      string   `json:"comments,omitempty"`
 	Category      string   `json:"category"`
 	Compatibility string   `json:"compatibility"`
+
+	// Obligations of curated licenses, tagged in the style of choosealicense.com: what the
+	// license permits (commercial-use), requires (include-copyright), and disclaims (no-warranty)
+	Permissions []string `json:"permissions,omitempty"`
+	Conditions  []string `json:"conditions,omitempty"`
+	Limitations []string `json:"limitations,omitempty"`
 }
 
-// NewClient creates a new SPDX license client
-func NewClient(logger *zap.Logger) *Client {
+// NewClient creates a new SPDX license client seeded with the built-in license data
+func NewClient(logger *zap.Logger, opts ...Option) *Client {
 	client := &Client{
-		logger:   logger,
-		licenses: make(map[string]*LicenseInfo),
+		httpClient: &http.Client{},
+		logger:     logger,
+		listURL:    LicenseListURL,
+		licenses:   builtinLicenses(),
+	}
+	for _, opt := range opts {
+		opt(client)
 	}
 
-	// Initialize with common license data
-	client.initializeLicenses()
+	logger.Info("Initialized license database", zap.Int("count", len(client.licenses)))
 
 	return client
 }
 
 // GetLicense retrieves information about a specific license by SPDX ID
 func (c *Client) GetLicense(ctx context.Context, licenseID string) (*LicenseInfo, error) {
-	c.logger.Debug("Looking up license", zap.String("id", licenseID))
+	reqlog.Logger(ctx, c.logger).Debug("Looking up license", zap.String("id", licenseID))
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 
 	// Normalize the license ID (case-insensitive lookup)
 	normalizedID := strings.ToUpper(strings.TrimSpace(licenseID))
@@ -65,11 +130,14 @@ func (c *Client) GetLicense(ctx context.Context, licenseID string) (*LicenseInfo
 
 // SearchLicenses searches for licenses matching the query
 func (c *Client) SearchLicenses(ctx context.Context, query string) ([]*LicenseInfo, error) {
-	c.logger.Debug("Searching licenses", zap.String("query", query))
+	reqlog.Logger(ctx, c.logger).Debug("Searching licenses", zap.String("query", query))
 
 	query = strings.ToLower(strings.TrimSpace(query))
 	var results []*LicenseInfo
 
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	for _, license := range c.licenses {
 		// Search in ID, name, and comments
 		if strings.Contains(strings.ToLower(license.ID), query) ||
@@ -79,12 +147,15 @@ func (c *Client) SearchLicenses(ctx context.Context, query string) ([]*LicenseIn
 		}
 	}
 
-	c.logger.Debug("Search complete", zap.Int("results", len(results)))
+	reqlog.Logger(ctx, c.logger).Debug("Search complete", zap.Int("results", len(results)))
 	return results, nil
 }
 
-// ListCategories returns all available license categories
+// ListCategories returns all available license categories in alphabetical order
 func (c *Client) ListCategories() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	categories := make(map[string]bool)
 	for _, license := range c.licenses {
 		if license.Category != "" {
@@ -96,11 +167,15 @@ func (c *Client) ListCategories() []string {
 	for cat := range categories {
 		result = append(result, cat)
 	}
+	sort.Strings(result)
 	return result
 }
 
 // GetLicensesByCategory returns all licenses in a specific category
 func (c *Client) GetLicensesByCategory(category string) []*LicenseInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	var results []*LicenseInfo
 	for _, license := range c.licenses {
 		if license.Category == category {
@@ -110,10 +185,100 @@ func (c *Client) GetLicensesByCategory(category string) []*LicenseInfo {
 	return results
 }
 
-// initializeLicenses populates the license database with common SPDX licenses
-func (c *Client) initializeLicenses() {
+// ReloadResult summarizes a completed Reload
+type ReloadResult struct {
+	LicenseListVersion string    `json:"license_list_version"`
+	LicenseCount       int       `json:"license_count"`
+	LoadedAt           time.Time `json:"loaded_at"`
+}
+
+// licenseList is the subset of the SPDX licenses.json document used by Reload
+type licenseList struct {
+	LicenseListVersion string `json:"licenseListVersion"`
+	Licenses           []struct {
+		LicenseID     string   `json:"licenseId"`
+		Name          string   `json:"name"`
+		IsOSIApproved bool     `json:"isOsiApproved"`
+		IsFSFLibre    bool     `json:"isFsfLibre"`
+		IsDeprecated  bool     `json:"isDeprecatedLicenseId"`
+		SeeAlso       []string `json:"seeAlso"`
+	} `json:"licenses"`
+}
+
+// Reload fetches the current SPDX license list and atomically swaps it in. Built-in
+// entries keep their category, compatibility, comments, and obligations; licenses only
+// known to the list have no category. On error the existing data is left untouched.
+func (c *Client) Reload(ctx context.Context) (*ReloadResult, error) {
+	reqlog.Logger(ctx, c.logger).Debug("fetching SPDX license list", zap.String("url", c.listURL))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.listURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("execute request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("SPDX license list error: status=%d body=%s", resp.StatusCode, string(body))
+	}
+
+	var list licenseList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if len(list.Licenses) == 0 {
+		return nil, fmt.Errorf("SPDX license list is empty")
+	}
+
+	licenses := builtinLicenses()
+	for _, l := range list.Licenses {
+		info := &LicenseInfo{ID: l.LicenseID}
+		if builtin, ok := licenses[l.LicenseID]; ok {
+			copied := *builtin
+			info = &copied
+		}
+		info.Name = l.Name
+		info.IsOSIApproved = l.IsOSIApproved
+		info.IsFSFLibre = l.IsFSFLibre
+		info.IsDeprecated = l.IsDeprecated
+		if len(l.SeeAlso) > 0 {
+			info.SeeAlso = l.SeeAlso
+		}
+		licenses[l.LicenseID] = info
+	}
+
+	c.mu.Lock()
+	c.licenses = licenses
+	c.mu.Unlock()
+
+	reqlog.Logger(ctx, c.logger).Info("Reloaded license database",
+		zap.String("license_list_version", list.LicenseListVersion),
+		zap.Int("count", len(licenses)))
+
+	return &ReloadResult{
+		LicenseListVersion: list.LicenseListVersion,
+		LicenseCount:       len(licenses),
+		LoadedAt:           time.Now(),
+	}, nil
+}
+
+// builtinLicenses returns the curated license data, including the category and
+// compatibility annotations the SPDX list does not carry
+func builtinLicenses() map[string]*LicenseInfo {
+	licenses := make(map[string]*LicenseInfo)
+	add := func(license *LicenseInfo) {
+		licenses[license.ID] = license
+	}
+
 	// Popular permissive licenses
-	c.addLicense(&LicenseInfo{
+	add(&LicenseInfo{
 		ID:            "MIT",
 		Name:          "MIT License",
 		IsOSIApproved: true,
@@ -122,9 +287,12 @@ func (c *Client) initializeLicenses() {
 		Compatibility: "Very High",
 		Comments:      "Simple and permissive license allowing almost unrestricted freedom",
 		SeeAlso:       []string{"https://opensource.org/licenses/MIT"},
+		Permissions:   []string{"commercial-use", "modification", "distribution", "private-use"},
+		Conditions:    []string{"include-copyright"},
+		Limitations:   []string{"no-liability", "no-warranty"},
 	})
 
-	c.addLicense(&LicenseInfo{
+	add(&LicenseInfo{
 		ID:            "Apache-2.0",
 		Name:          "Apache License 2.0",
 		IsOSIApproved: true,
@@ -133,9 +301,12 @@ func (c *Client) initializeLicenses() {
 		Compatibility: "High",
 		Comments:      "Permissive license with patent grant and trademark protection",
 		SeeAlso:       []string{"https://www.apache.org/licenses/LICENSE-2.0"},
+		Permissions:   []string{"commercial-use", "modification", "distribution", "patent-use", "private-use"},
+		Conditions:    []string{"include-copyright", "document-changes"},
+		Limitations:   []string{"no-liability", "no-trademark-use", "no-warranty"},
 	})
 
-	c.addLicense(&LicenseInfo{
+	add(&LicenseInfo{
 		ID:            "BSD-3-Clause",
 		Name:          "BSD 3-Clause \"New\" or \"Revised\" License",
 		IsOSIApproved: true,
@@ -144,9 +315,12 @@ func (c *Client) initializeLicenses() {
 		Compatibility: "Very High",
 		Comments:      "Permissive license similar to MIT but with explicit non-endorsement clause",
 		SeeAlso:       []string{"https://opensource.org/licenses/BSD-3-Clause"},
+		Permissions:   []string{"commercial-use", "modification", "distribution", "private-use"},
+		Conditions:    []string{"include-copyright"},
+		Limitations:   []string{"no-liability", "no-warranty"},
 	})
 
-	c.addLicense(&LicenseInfo{
+	add(&LicenseInfo{
 		ID:            "BSD-2-Clause",
 		Name:          "BSD 2-Clause \"Simplified\" License",
 		IsOSIApproved: true,
@@ -155,9 +329,12 @@ func (c *Client) initializeLicenses() {
 		Compatibility: "Very High",
 		Comments:      "Simplified version of BSD license with fewer restrictions",
 		SeeAlso:       []string{"https://opensource.org/licenses/BSD-2-Clause"},
+		Permissions:   []string{"commercial-use", "modification", "distribution", "private-use"},
+		Conditions:    []string{"include-copyright"},
+		Limitations:   []string{"no-liability", "no-warranty"},
 	})
 
-	c.addLicense(&LicenseInfo{
+	add(&LicenseInfo{
 		ID:            "ISC",
 		Name:          "ISC License",
 		IsOSIApproved: true,
@@ -166,10 +343,13 @@ func (c *Client) initializeLicenses() {
 		Compatibility: "Very High",
 		Comments:      "Functionally equivalent to MIT and BSD 2-Clause",
 		SeeAlso:       []string{"https://opensource.org/licenses/ISC"},
+		Permissions:   []string{"commercial-use", "modification", "distribution", "private-use"},
+		Conditions:    []string{"include-copyright"},
+		Limitations:   []string{"no-liability", "no-warranty"},
 	})
 
 	// Copyleft licenses
-	c.addLicense(&LicenseInfo{
+	add(&LicenseInfo{
 		ID:            "GPL-3.0",
 		Name:          "GNU General Public License v3.0",
 		IsOSIApproved: true,
@@ -178,9 +358,12 @@ func (c *Client) initializeLicenses() {
 		Compatibility: "Low",
 		Comments:      "Strong copyleft license requiring source code disclosure",
 		SeeAlso:       []string{"https://www.gnu.org/licenses/gpl-3.0.html"},
+		Permissions:   []string{"commercial-use", "modification", "distribution", "patent-use", "private-use"},
+		Conditions:    []string{"include-copyright", "document-changes", "disclose-source", "same-license"},
+		Limitations:   []string{"no-liability", "no-warranty"},
 	})
 
-	c.addLicense(&LicenseInfo{
+	add(&LicenseInfo{
 		ID:            "GPL-2.0",
 		Name:          "GNU General Public License v2.0",
 		IsOSIApproved: true,
@@ -189,9 +372,12 @@ func (c *Client) initializeLicenses() {
 		Compatibility: "Low",
 		Comments:      "Earlier version of GPL with strong copyleft requirements",
 		SeeAlso:       []string{"https://www.gnu.org/licenses/old-licenses/gpl-2.0.html"},
+		Permissions:   []string{"commercial-use", "modification", "distribution", "private-use"},
+		Conditions:    []string{"include-copyright", "document-changes", "disclose-source", "same-license"},
+		Limitations:   []string{"no-liability", "no-warranty"},
 	})
 
-	c.addLicense(&LicenseInfo{
+	add(&LicenseInfo{
 		ID:            "LGPL-3.0",
 		Name:          "GNU Lesser General Public License v3.0",
 		IsOSIApproved: true,
@@ -200,9 +386,12 @@ func (c *Client) initializeLicenses() {
 		Compatibility: "Medium",
 		Comments:      "Weaker copyleft allowing dynamic linking without license propagation",
 		SeeAlso:       []string{"https://www.gnu.org/licenses/lgpl-3.0.html"},
+		Permissions:   []string{"commercial-use", "modification", "distribution", "patent-use", "private-use"},
+		Conditions:    []string{"include-copyright", "document-changes", "disclose-source", "same-license--library"},
+		Limitations:   []string{"no-liability", "no-warranty"},
 	})
 
-	c.addLicense(&LicenseInfo{
+	add(&LicenseInfo{
 		ID:            "AGPL-3.0",
 		Name:          "GNU Affero General Public License v3.0",
 		IsOSIApproved: true,
@@ -211,9 +400,12 @@ func (c *Client) initializeLicenses() {
 		Compatibility: "Very Low",
 		Comments:      "Strongest copyleft license including network use trigger",
 		SeeAlso:       []string{"https://www.gnu.org/licenses/agpl-3.0.html"},
+		Permissions:   []string{"commercial-use", "modification", "distribution", "patent-use", "private-use"},
+		Conditions:    []string{"include-copyright", "document-changes", "disclose-source", "network-use-disclose", "same-license"},
+		Limitations:   []string{"no-liability", "no-warranty"},
 	})
 
-	c.addLicense(&LicenseInfo{
+	add(&LicenseInfo{
 		ID:            "MPL-2.0",
 		Name:          "Mozilla Public License 2.0",
 		IsOSIApproved: true,
@@ -222,10 +414,13 @@ func (c *Client) initializeLicenses() {
 		Compatibility: "Medium",
 		Comments:      "File-level copyleft license balancing openness and commercial use",
 		SeeAlso:       []string{"https://www.mozilla.org/MPL/2.0/"},
+		Permissions:   []string{"commercial-use", "modification", "distribution", "patent-use", "private-use"},
+		Conditions:    []string{"include-copyright", "disclose-source", "same-license--file"},
+		Limitations:   []string{"no-liability", "no-trademark-use", "no-warranty"},
 	})
 
 	// Creative Commons
-	c.addLicense(&LicenseInfo{
+	add(&LicenseInfo{
 		ID:            "CC0-1.0",
 		Name:          "Creative Commons Zero v1.0 Universal",
 		IsOSIApproved: false,
@@ -234,9 +429,11 @@ func (c *Client) initializeLicenses() {
 		Compatibility: "Very High",
 		Comments:      "Public domain dedication for maximum freedom",
 		SeeAlso:       []string{"https://creativecommons.org/publicdomain/zero/1.0/"},
+		Permissions:   []string{"commercial-use", "modification", "distribution", "private-use"},
+		Limitations:   []string{"no-liability", "no-patent-grant", "no-trademark-use", "no-warranty"},
 	})
 
-	c.addLicense(&LicenseInfo{
+	add(&LicenseInfo{
 		ID:            "CC-BY-4.0",
 		Name:          "Creative Commons Attribution 4.0 International",
 		IsOSIApproved: false,
@@ -245,10 +442,13 @@ func (c *Client) initializeLicenses() {
 		Compatibility: "High",
 		Comments:      "Requires attribution but allows commercial use and derivatives",
 		SeeAlso:       []string{"https://creativecommons.org/licenses/by/4.0/"},
+		Permissions:   []string{"commercial-use", "modification", "distribution", "private-use"},
+		Conditions:    []string{"include-copyright", "document-changes"},
+		Limitations:   []string{"no-liability", "no-patent-grant", "no-trademark-use", "no-warranty"},
 	})
 
 	// Proprietary/Restrictive
-	c.addLicense(&LicenseInfo{
+	add(&LicenseInfo{
 		ID:            "Unlicense",
 		Name:          "The Unlicense",
 		IsOSIApproved: false,
@@ -257,9 +457,11 @@ func (c *Client) initializeLicenses() {
 		Compatibility: "Very High",
 		Comments:      "Release software into public domain",
 		SeeAlso:       []string{"http://unlicense.org/"},
+		Permissions:   []string{"commercial-use", "modification", "distribution", "private-use"},
+		Limitations:   []string{"no-liability", "no-warranty"},
 	})
 
-	c.addLicense(&LicenseInfo{
+	add(&LicenseInfo{
 		ID:            "WTFPL",
 		Name:          "Do What The F*ck You Want To Public License",
 		IsOSIApproved: false,
@@ -268,12 +470,8 @@ func (c *Client) initializeLicenses() {
 		Compatibility: "Very High",
 		Comments:      "Extremely permissive public domain-like license",
 		SeeAlso:       []string{"http://www.wtfpl.net/"},
+		Permissions:   []string{"commercial-use", "modification", "distribution", "private-use"},
 	})
 
-	c.logger.Info("Initialized license database", zap.Int("count", len(c.licenses)))
-}
-
-// addLicense adds a license to the internal database
-func (c *Client) addLicense(license *LicenseInfo) {
-	c.licenses[license.ID] = license
+	return licenses
 }
@@ -2,6 +2,10 @@ package spdx
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
 	"testing"
 
 	"go.uber.org/zap"
@@ -301,3 +305,108 @@ func TestSPDXClient_LicenseCompatibility(t *testing.T) {
 		})
 	}
 }
+
+func TestSPDXClient_Obligations(t *testing.T) {
+	client := NewClient(zap.NewNop())
+
+	tests := []struct {
+		licenseID string
+		condition string
+	}{
+		{"MIT", "include-copyright"},
+		{"GPL-3.0", "disclose-source"},
+	}
+	for _, tt := range tests {
+		license, err := client.GetLicense(context.Background(), tt.licenseID)
+		if err != nil {
+			t.Fatalf("GetLicense(%s) error = %v", tt.licenseID, err)
+		}
+		if !slices.Contains(license.Conditions, tt.condition) {
+			t.Errorf("%s conditions = %v, want %s", tt.licenseID, license.Conditions, tt.condition)
+		}
+		if !slices.Contains(license.Permissions, "commercial-use") || !slices.Contains(license.Limitations, "no-warranty") {
+			t.Errorf("%s permissions = %v, limitations = %v; want commercial-use and no-warranty",
+				tt.licenseID, license.Permissions, license.Limitations)
+		}
+	}
+
+	// MIT does not require disclosing source
+	mit, _ := client.GetLicense(context.Background(), "MIT")
+	if slices.Contains(mit.Conditions, "disclose-source") {
+		t.Errorf("MIT conditions = %v, want no disclose-source", mit.Conditions)
+	}
+}
+
+func TestSPDXClient_ReloadDuringLookups(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"licenseListVersion":"3.24","licenses":[
+			{"licenseId":"MIT","name":"MIT License","isOsiApproved":true,"isFsfLibre":true},
+			{"licenseId":"0BSD","name":"BSD Zero Clause License","isOsiApproved":true,"seeAlso":["https://opensource.org/licenses/0BSD"]}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(zap.NewNop(), WithLicenseListURL(server.URL))
+	ctx := context.Background()
+
+	if _, err := client.GetLicense(ctx, "0BSD"); err == nil {
+		t.Fatal("expected 0BSD to be unknown before a reload")
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if _, err := client.GetLicense(ctx, "mit"); err != nil {
+					t.Errorf("GetLicense() during reload error = %v", err)
+					return
+				}
+				_ = client.ListCategories()
+			}
+		}()
+	}
+
+	for i := 0; i < 5; i++ {
+		result, err := client.Reload(ctx)
+		if err != nil {
+			t.Fatalf("Reload() error = %v", err)
+		}
+		if result.LicenseListVersion != "3.24" {
+			t.Errorf("LicenseListVersion = %q, want 3.24", result.LicenseListVersion)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	added, err := client.GetLicense(ctx, "0BSD")
+	if err != nil || !added.IsOSIApproved || added.Category != "" {
+		t.Errorf("0BSD = %+v, %v; want an uncategorized OSI-approved entry", added, err)
+	}
+	mit, err := client.GetLicense(ctx, "MIT")
+	if err != nil || mit.Category != "Permissive" || mit.Compatibility != "Very High" {
+		t.Errorf("MIT = %+v, %v; want curated annotations kept", mit, err)
+	}
+}
+
+func TestSPDXClient_ReloadFailureKeepsData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(zap.NewNop(), WithLicenseListURL(server.URL))
+	if _, err := client.Reload(context.Background()); err == nil {
+		t.Fatal("expected Reload() to fail")
+	}
+	if _, err := client.GetLicense(context.Background(), "MIT"); err != nil {
+		t.Errorf("GetLicense() after failed reload error = %v", err)
+	}
+}
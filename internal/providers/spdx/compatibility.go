@@ -0,0 +1,218 @@
+package spdx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// License categories used by the built-in dataset
+const (
+	CategoryPublicDomain   = "Public Domain"
+	CategoryPermissive     = "Permissive"
+	CategoryWeakCopyleft   = "Weak Copyleft"
+	CategoryCopyleft       = "Copyleft"
+	CategoryStrongCopyleft = "Strong Copyleft"
+)
+
+// permissiveness ranks categories from most to least permissive; a higher rank can absorb
+// every category at or below it
+var permissiveness = map[string]int{
+	CategoryPublicDomain:   0,
+	CategoryPermissive:     0,
+	CategoryWeakCopyleft:   1,
+	CategoryCopyleft:       2,
+	CategoryStrongCopyleft: 3,
+}
+
+// ComparePermissiveness orders two categories by how much they restrict the licensee: negative
+// when a is more permissive than b, positive when it is less, and 0 when they rank the same.
+// ok is false when either category is not one of the dataset's.
+func ComparePermissiveness(a, b string) (cmp int, ok bool) {
+	rankA, okA := permissiveness[a]
+	rankB, okB := permissiveness[b]
+	if !okA || !okB {
+		return 0, false
+	}
+	return rankA - rankB, true
+}
+
+// incompatiblePairs lists license combinations the category matrix would allow but the
+// licenses' own terms forbid, keyed by project license then dependency license. Entries apply
+// only to the -only forms; an -or-later license can move to a version that resolves the clash.
+var incompatiblePairs = map[string]map[string]string{
+	"GPL-2.0": {
+		"Apache-2.0": "Apache-2.0's patent terms are incompatible with GPL-2.0",
+		"GPL-3.0":    "GPL-3.0 code cannot be distributed under GPL-2.0",
+		"LGPL-3.0":   "LGPL-3.0 code cannot be distributed under GPL-2.0",
+	},
+	"GPL-3.0": {
+		"GPL-2.0": "GPL-2.0-only code cannot be distributed under GPL-3.0",
+	},
+}
+
+// Compatibility is the verdict for distributing a dependency's license as part of a project
+type Compatibility struct {
+	Compatible bool `json:"compatible"`
+	// License is the dependency license option the verdict is based on; for a dual-licensed
+	// dependency it is the most permissive compatible choice
+	License  string `json:"license,omitempty"`
+	Category string `json:"category,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	// Unknown is set when a license has no category, so compatibility could not be judged
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// CheckCompatibility decides whether a dependency licensed under dependency may be distributed
+// in a project licensed under project. Both sides may be expressions: an AND requires every
+// operand to be compatible, and an OR needs one compatible option. For a dual-licensed
+// dependency the most permissive compatible option is chosen. Licenses without a category are
+// reported as unknown and treated as compatible.
+func (c *Client) CheckCompatibility(project, dependency *Expression) Compatibility {
+	if !project.IsLeaf() {
+		left := c.CheckCompatibility(project.Left, dependency)
+		right := c.CheckCompatibility(project.Right, dependency)
+		if project.Op == OpOr {
+			return morePermissive(left, right)
+		}
+		return stricter(left, right)
+	}
+
+	if !dependency.IsLeaf() {
+		left := c.CheckCompatibility(project, dependency.Left)
+		right := c.CheckCompatibility(project, dependency.Right)
+		if dependency.Op == OpOr {
+			return morePermissive(left, right)
+		}
+		// Every license of a conjunction applies, so the strictest one decides
+		result := stricter(left, right)
+		result.License = strings.TrimSuffix(strings.TrimPrefix(dependency.String(), "("), ")")
+		return result
+	}
+
+	return c.compatibleLicenses(project.License, dependency.License)
+}
+
+// PermissiveChoice returns the licenses a licensee is bound by when taking the most permissive
+// option an expression offers: the more permissive operand of an OR and, since every operand of
+// an AND applies, the conjunction of each operand's choice rated by its strictest license.
+// Licenses without a category are reported as unknown and lose to categorized options.
+func (c *Client) PermissiveChoice(expr *Expression) Compatibility {
+	if expr.IsLeaf() {
+		category := c.Category(expr.License)
+		return Compatibility{Compatible: true, License: expr.String(), Category: category, Unknown: category == ""}
+	}
+
+	left := c.PermissiveChoice(expr.Left)
+	right := c.PermissiveChoice(expr.Right)
+	if expr.Op == OpOr {
+		return morePermissive(left, right)
+	}
+	result := stricter(left, right)
+	result.License = left.License + " AND " + right.License
+	return result
+}
+
+// compatibleLicenses applies the category matrix and license-specific exceptions to two
+// single licenses
+func (c *Client) compatibleLicenses(project, dependency string) Compatibility {
+	result := Compatibility{Compatible: true, License: dependency, Category: c.Category(dependency)}
+
+	projectRank, projectKnown := permissiveness[c.Category(project)]
+	dependencyRank, dependencyKnown := permissiveness[result.Category]
+	if !projectKnown || !dependencyKnown {
+		result.Unknown = true
+		unknown := dependency
+		if !projectKnown {
+			unknown = project
+		}
+		result.Reason = fmt.Sprintf("%s has no known category", unknown)
+		return result
+	}
+
+	if reason, ok := incompatiblePairs[onlyBase(project)][onlyBase(dependency)]; ok {
+		result.Compatible = false
+		result.Reason = reason
+		return result
+	}
+	if dependencyRank > projectRank && dependencyRank > permissiveness[CategoryWeakCopyleft] {
+		result.Compatible = false
+		result.Reason = fmt.Sprintf("%s (%s) requires derivative works to use a compatible copyleft license, but the project is %s",
+			dependency, result.Category, project)
+	}
+	return result
+}
+
+// Category returns a license's category, trying the base identifier of an -only or -or-later
+// form since the built-in dataset keys GPL-family licenses without the suffix. It returns ""
+// for licenses without a category.
+func (c *Client) Category(id string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	candidates := []string{id}
+	for _, suffix := range []string{"-only", "-or-later"} {
+		if base, ok := cutSuffixFold(id, suffix); ok {
+			candidates = append(candidates, base)
+		}
+	}
+	for _, candidate := range candidates {
+		for known, info := range c.licenses {
+			if strings.EqualFold(known, candidate) && info.Category != "" {
+				return info.Category
+			}
+		}
+	}
+	return ""
+}
+
+// onlyBase strips -only from an identifier and returns "" for -or-later forms, so
+// incompatiblePairs matches only licenses pinned to one version
+func onlyBase(id string) string {
+	if _, orLater := cutSuffixFold(id, "-or-later"); orLater || strings.HasSuffix(id, "+") {
+		return ""
+	}
+	base, _ := cutSuffixFold(id, "-only")
+	return base
+}
+
+// morePermissive picks between two options of a dual license: a compatible option over an
+// incompatible one, a judged option over an unknown one, then the more permissive category
+func morePermissive(a, b Compatibility) Compatibility {
+	if a.Compatible != b.Compatible {
+		if a.Compatible {
+			return a
+		}
+		return b
+	}
+	if a.Unknown != b.Unknown {
+		if b.Unknown {
+			return a
+		}
+		return b
+	}
+	if permissiveness[b.Category] < permissiveness[a.Category] {
+		return b
+	}
+	return a
+}
+
+// stricter is the counterpart of morePermissive for licenses that all apply: an incompatible
+// verdict over a compatible one, an unknown one over a judged one, then the less permissive category
+func stricter(a, b Compatibility) Compatibility {
+	if a.Compatible != b.Compatible {
+		if a.Compatible {
+			return b
+		}
+		return a
+	}
+	if a.Unknown != b.Unknown {
+		if a.Unknown {
+			return a
+		}
+		return b
+	}
+	if permissiveness[b.Category] > permissiveness[a.Category] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,118 @@
+package spdx
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	client := NewClient(zap.NewNop())
+
+	tests := []struct {
+		project    string
+		dependency string
+		compatible bool
+		license    string
+		unknown    bool
+	}{
+		{"Apache-2.0", "MIT", true, "MIT", false},
+		{"Apache-2.0", "GPL-3.0-only", false, "GPL-3.0-only", false},
+		{"MIT", "AGPL-3.0", false, "AGPL-3.0", false},
+		{"MIT", "LGPL-3.0", true, "LGPL-3.0", false},
+		{"GPL-3.0", "GPL-3.0-only", true, "GPL-3.0-only", false},
+		{"GPL-3.0", "AGPL-3.0", false, "AGPL-3.0", false},
+		{"AGPL-3.0", "GPL-3.0", true, "GPL-3.0", false},
+		// Dual licenses pick the most permissive compatible option
+		{"Apache-2.0", "GPL-3.0-or-later OR MIT", true, "MIT", false},
+		{"GPL-3.0", "MPL-2.0 OR GPL-3.0", true, "MPL-2.0", false},
+		{"MIT", "MIT AND GPL-2.0", false, "MIT AND GPL-2.0", false},
+		// License-specific exceptions to the category matrix
+		{"GPL-2.0-only", "Apache-2.0", false, "Apache-2.0", false},
+		{"GPL-2.0-or-later", "Apache-2.0", true, "Apache-2.0", false},
+		{"GPL-3.0-only", "GPL-2.0-only", false, "GPL-2.0-only", false},
+		{"GPL-3.0-only", "GPL-2.0-or-later", true, "GPL-2.0-or-later", false},
+		// A dual-licensed project may pick the option that admits the dependency
+		{"MIT OR GPL-3.0", "GPL-3.0", true, "GPL-3.0", false},
+		{"LicenseRef-Internal", "MIT", true, "MIT", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.project+" <- "+tt.dependency, func(t *testing.T) {
+			project, err := ParseExpression(tt.project)
+			if err != nil {
+				t.Fatalf("ParseExpression(%s) error = %v", tt.project, err)
+			}
+			dependency, err := ParseExpression(tt.dependency)
+			if err != nil {
+				t.Fatalf("ParseExpression(%s) error = %v", tt.dependency, err)
+			}
+			got := client.CheckCompatibility(project, dependency)
+			if got.Compatible != tt.compatible || got.License != tt.license || got.Unknown != tt.unknown {
+				t.Errorf("CheckCompatibility() = %+v, want compatible=%v license=%s unknown=%v",
+					got, tt.compatible, tt.license, tt.unknown)
+			}
+			if !got.Compatible && got.Reason == "" {
+				t.Error("expected a reason for an incompatible license")
+			}
+		})
+	}
+}
+
+func TestCategory(t *testing.T) {
+	client := NewClient(zap.NewNop())
+	for id, want := range map[string]string{
+		"mit":              CategoryPermissive,
+		"GPL-3.0-or-later": CategoryCopyleft,
+		"LGPL-3.0-only":    CategoryWeakCopyleft,
+		"Nonexistent-1.0":  "",
+	} {
+		if got := client.Category(id); got != want {
+			t.Errorf("Category(%s) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestPermissiveChoice(t *testing.T) {
+	client := NewClient(zap.NewNop())
+
+	tests := []struct {
+		expr     string
+		license  string
+		category string
+	}{
+		{"MIT", "MIT", CategoryPermissive},
+		{"GPL-3.0-or-later OR MIT", "MIT", CategoryPermissive},
+		{"MIT AND Apache-2.0", "MIT AND Apache-2.0", CategoryPermissive},
+		{"MIT AND GPL-2.0", "MIT AND GPL-2.0", CategoryCopyleft},
+		{"(GPL-3.0 OR MPL-2.0) AND MIT", "MPL-2.0 AND MIT", CategoryWeakCopyleft},
+		{"LicenseRef-Internal OR GPL-3.0", "GPL-3.0", CategoryCopyleft},
+	}
+	for _, tt := range tests {
+		expr, err := ParseExpression(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseExpression(%s) error = %v", tt.expr, err)
+		}
+		if got := client.PermissiveChoice(expr); got.License != tt.license || got.Category != tt.category {
+			t.Errorf("PermissiveChoice(%s) = %s (%s), want %s (%s)", tt.expr, got.License, got.Category, tt.license, tt.category)
+		}
+	}
+}
+
+func TestComparePermissiveness(t *testing.T) {
+	tests := []struct {
+		a, b string
+		sign int
+		ok   bool
+	}{
+		{CategoryPermissive, CategoryCopyleft, -1, true},
+		{CategoryStrongCopyleft, CategoryWeakCopyleft, 1, true},
+		{CategoryPublicDomain, CategoryPermissive, 0, true},
+		{CategoryPermissive, "Unknown", 0, false},
+	}
+	for _, tt := range tests {
+		c, ok := ComparePermissiveness(tt.a, tt.b)
+		if ok != tt.ok || (c > 0) != (tt.sign > 0) || (c < 0) != (tt.sign < 0) {
+			t.Errorf("ComparePermissiveness(%s, %s) = %d, %v; want sign %d, %v", tt.a, tt.b, c, ok, tt.sign, tt.ok)
+		}
+	}
+}
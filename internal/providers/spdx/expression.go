@@ -0,0 +1,265 @@
+package spdx
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Expression operators
+const (
+	OpAnd = "AND"
+	OpOr  = "OR"
+)
+
+// Expression is a parsed SPDX license expression.
+// Leaf nodes carry a License (and optional Exception); compound nodes carry
+// an Op with Left and Right operands.
+type Expression struct {
+	Op        string      `json:"op,omitempty"`
+	License   string      `json:"license,omitempty"`
+	Exception string      `json:"exception,omitempty"`
+	OrLater   bool        `json:"or_later,omitempty"`
+	Left      *Expression `json:"left,omitempty"`
+	Right     *Expression `json:"right,omitempty"`
+	Pos       int         `json:"-"`
+	// ExceptionPos is the index of the exception identifier, when there is one
+	ExceptionPos int `json:"-"`
+}
+
+// ExpressionError describes where and why an expression failed to parse
+type ExpressionError struct {
+	Pos    int
+	Reason string
+}
+
+func (e *ExpressionError) Error() string {
+	return fmt.Sprintf("%s at index %d", e.Reason, e.Pos)
+}
+
+// IsLeaf reports whether the expression is a single license reference
+func (e *Expression) IsLeaf() bool {
+	return e.Op == ""
+}
+
+// Licenses returns the license identifiers referenced by the expression in order of appearance
+func (e *Expression) Licenses() []string {
+	if e == nil {
+		return nil
+	}
+	if e.IsLeaf() {
+		return []string{e.License}
+	}
+	return append(e.Left.Licenses(), e.Right.Licenses()...)
+}
+
+// Leaves returns the license references in the expression in order of appearance
+func (e *Expression) Leaves() []*Expression {
+	if e == nil {
+		return nil
+	}
+	if e.IsLeaf() {
+		return []*Expression{e}
+	}
+	return append(e.Left.Leaves(), e.Right.Leaves()...)
+}
+
+// String renders the expression in canonical SPDX form
+func (e *Expression) String() string {
+	if e == nil {
+		return ""
+	}
+	if e.IsLeaf() {
+		s := e.License
+		if e.OrLater {
+			s += "+"
+		}
+		if e.Exception != "" {
+			s += " WITH " + e.Exception
+		}
+		return s
+	}
+	return fmt.Sprintf("(%s %s %s)", e.Left.String(), e.Op, e.Right.String())
+}
+
+type token struct {
+	value string
+	pos   int
+}
+
+// ParseExpression parses an SPDX license expression such as
+// "MIT OR (Apache-2.0 AND BSD-3-Clause)" or "GPL-2.0+ WITH Classpath-exception-2.0".
+// Operator precedence follows the SPDX specification: WITH binds tightest, then AND, then OR.
+func ParseExpression(expr string) (*Expression, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, &ExpressionError{Pos: 0, Reason: "empty expression"}
+	}
+
+	p := &parser{tokens: tokens, end: len(expr)}
+	result, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok, ok := p.peek(); ok {
+		return nil, &ExpressionError{Pos: tok.pos, Reason: fmt.Sprintf("unexpected %q", tok.value)}
+	}
+	return result, nil
+}
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	start := -1
+	flush := func(i int) {
+		if start >= 0 {
+			tokens = append(tokens, token{value: expr[start:i], pos: start})
+			start = -1
+		}
+	}
+
+	for i, r := range expr {
+		switch {
+		case r == '(' || r == ')':
+			flush(i)
+			tokens = append(tokens, token{value: string(r), pos: i})
+		case unicode.IsSpace(r):
+			flush(i)
+		case isIDChar(r):
+			if start < 0 {
+				start = i
+			}
+		default:
+			return nil, &ExpressionError{Pos: i, Reason: fmt.Sprintf("invalid character %q", r)}
+		}
+	}
+	flush(len(expr))
+
+	return tokens, nil
+}
+
+func isIDChar(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '.' || r == '+' || r == ':')
+}
+
+type parser struct {
+	tokens []token
+	idx    int
+	end    int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.idx >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.idx], true
+}
+
+func (p *parser) next() (token, bool) {
+	tok, ok := p.peek()
+	if ok {
+		p.idx++
+	}
+	return tok, ok
+}
+
+func (p *parser) acceptKeyword(keyword string) (token, bool) {
+	tok, ok := p.peek()
+	if ok && strings.EqualFold(tok.value, keyword) {
+		p.idx++
+		return tok, true
+	}
+	return token{}, false
+}
+
+func (p *parser) parseOr() (*Expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.acceptKeyword(OpOr)
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Expression{Op: OpOr, Left: left, Right: right, Pos: tok.pos}
+	}
+}
+
+func (p *parser) parseAnd() (*Expression, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.acceptKeyword(OpAnd)
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &Expression{Op: OpAnd, Left: left, Right: right, Pos: tok.pos}
+	}
+}
+
+func (p *parser) parseTerm() (*Expression, error) {
+	tok, ok := p.next()
+	if !ok {
+		return nil, &ExpressionError{Pos: p.end, Reason: "unexpected end of expression"}
+	}
+
+	switch {
+	case tok.value == "(":
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		closing, ok := p.next()
+		if !ok {
+			return nil, &ExpressionError{Pos: tok.pos, Reason: "unclosed parenthesis"}
+		}
+		if closing.value != ")" {
+			return nil, &ExpressionError{Pos: closing.pos, Reason: fmt.Sprintf("expected ')' but found %q", closing.value)}
+		}
+		return inner, nil
+	case tok.value == ")":
+		return nil, &ExpressionError{Pos: tok.pos, Reason: "unexpected ')'"}
+	case isKeyword(tok.value):
+		return nil, &ExpressionError{Pos: tok.pos, Reason: fmt.Sprintf("expected license identifier but found operator %q", tok.value)}
+	}
+
+	leaf := &Expression{License: tok.value, Pos: tok.pos}
+	if strings.HasSuffix(leaf.License, "+") {
+		leaf.License = strings.TrimSuffix(leaf.License, "+")
+		leaf.OrLater = true
+	}
+	if leaf.License == "" {
+		return nil, &ExpressionError{Pos: tok.pos, Reason: "empty license identifier"}
+	}
+
+	if _, ok := p.acceptKeyword("WITH"); ok {
+		exception, ok := p.next()
+		if !ok {
+			return nil, &ExpressionError{Pos: p.end, Reason: "expected exception identifier after WITH"}
+		}
+		if exception.value == "(" || exception.value == ")" || isKeyword(exception.value) {
+			return nil, &ExpressionError{Pos: exception.pos, Reason: fmt.Sprintf("expected exception identifier but found %q", exception.value)}
+		}
+		leaf.Exception = exception.value
+		leaf.ExceptionPos = exception.pos
+	}
+
+	return leaf, nil
+}
+
+func isKeyword(s string) bool {
+	return strings.EqualFold(s, OpAnd) || strings.EqualFold(s, OpOr) || strings.EqualFold(s, "WITH")
+}
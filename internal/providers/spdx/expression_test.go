@@ -0,0 +1,99 @@
+package spdx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		name         string
+		expr         string
+		wantLicenses []string
+		wantString   string
+	}{
+		{
+			name:         "single license",
+			expr:         "MIT",
+			wantLicenses: []string{"MIT"},
+			wantString:   "MIT",
+		},
+		{
+			name:         "dual license",
+			expr:         "MIT OR Apache-2.0",
+			wantLicenses: []string{"MIT", "Apache-2.0"},
+			wantString:   "(MIT OR Apache-2.0)",
+		},
+		{
+			name:         "AND binds tighter than OR",
+			expr:         "MIT OR Apache-2.0 AND BSD-3-Clause",
+			wantLicenses: []string{"MIT", "Apache-2.0", "BSD-3-Clause"},
+			wantString:   "(MIT OR (Apache-2.0 AND BSD-3-Clause))",
+		},
+		{
+			name:         "parentheses override precedence",
+			expr:         "(MIT OR Apache-2.0) AND BSD-3-Clause",
+			wantLicenses: []string{"MIT", "Apache-2.0", "BSD-3-Clause"},
+			wantString:   "((MIT OR Apache-2.0) AND BSD-3-Clause)",
+		},
+		{
+			name:         "or-later and exception",
+			expr:         "GPL-2.0+ WITH Classpath-exception-2.0",
+			wantLicenses: []string{"GPL-2.0"},
+			wantString:   "GPL-2.0+ WITH Classpath-exception-2.0",
+		},
+		{
+			name:         "lowercase operators",
+			expr:         "mit or isc",
+			wantLicenses: []string{"mit", "isc"},
+			wantString:   "(mit OR isc)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseExpression(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseExpression(%q) error = %v", tt.expr, err)
+			}
+			if got := strings.Join(expr.Licenses(), ","); got != strings.Join(tt.wantLicenses, ",") {
+				t.Errorf("Licenses() = %s, want %s", got, strings.Join(tt.wantLicenses, ","))
+			}
+			if got := expr.String(); got != tt.wantString {
+				t.Errorf("String() = %s, want %s", got, tt.wantString)
+			}
+		})
+	}
+}
+
+func TestParseExpression_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantPos int
+	}{
+		{name: "empty", expr: "   ", wantPos: 0},
+		{name: "unclosed parenthesis", expr: "(MIT OR Apache-2.0", wantPos: 0},
+		{name: "stray closing parenthesis", expr: "MIT)", wantPos: 3},
+		{name: "dangling operator", expr: "MIT AND", wantPos: 7},
+		{name: "leading operator", expr: "OR MIT", wantPos: 0},
+		{name: "invalid character", expr: "MIT / ISC", wantPos: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseExpression(tt.expr)
+			if err == nil {
+				t.Fatalf("ParseExpression(%q) expected error", tt.expr)
+			}
+			var exprErr *ExpressionError
+			if !errors.As(err, &exprErr) {
+				t.Fatalf("expected *ExpressionError, got %T", err)
+			}
+			if exprErr.Pos != tt.wantPos {
+				t.Errorf("error position = %d, want %d (%v)", exprErr.Pos, tt.wantPos, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package spdx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// exceptions lists the SPDX license exception identifiers recognized after WITH
+var exceptions = []string{
+	"389-exception",
+	"Autoconf-exception-2.0",
+	"Autoconf-exception-3.0",
+	"Bison-exception-2.2",
+	"Bootloader-exception",
+	"Classpath-exception-2.0",
+	"CLISP-exception-2.0",
+	"eCos-exception-2.0",
+	"Fawkes-Runtime-exception",
+	"FLTK-exception",
+	"Font-exception-2.0",
+	"freertos-exception-2.0",
+	"GCC-exception-2.0",
+	"GCC-exception-3.1",
+	"gnu-javamail-exception",
+	"GPL-3.0-linking-exception",
+	"GPL-3.0-linking-source-exception",
+	"GPL-CC-1.0",
+	"i2p-gpl-java-exception",
+	"Libtool-exception",
+	"Linux-syscall-note",
+	"LLVM-exception",
+	"LZMA-exception",
+	"mif-exception",
+	"OCaml-LGPL-linking-exception",
+	"OpenJDK-assembly-exception-1.0",
+	"openvpn-openssl-exception",
+	"Qt-GPL-exception-1.0",
+	"Qt-LGPL-exception-1.1",
+	"Swift-exception",
+	"u-boot-exception-2.0",
+	"Universal-FOSS-exception-1.0",
+	"WxWindows-exception-3.1",
+}
+
+// ExpressionIssue is one problem found while validating an expression
+type ExpressionIssue struct {
+	Position int    `json:"position"`
+	Reason   string `json:"reason"`
+	Message  string `json:"message"`
+}
+
+// ExpressionValidation reports whether an SPDX expression is well formed and whether every
+// identifier it references is a known license or exception
+type ExpressionValidation struct {
+	Expression       string            `json:"expression"`
+	Valid            bool              `json:"valid"`
+	SyntaxValid      bool              `json:"syntax_valid"`
+	IdentifiersKnown bool              `json:"identifiers_known"`
+	Canonical        string            `json:"canonical,omitempty"`
+	Issues           []ExpressionIssue `json:"issues"`
+}
+
+// ValidateExpression parses an SPDX expression and checks its identifiers against the license
+// database. Identifiers match case-insensitively, as the SPDX specification allows; the -only
+// and -or-later forms of a known license and LicenseRef-/DocumentRef- references are accepted.
+// A syntax error stops validation at its position.
+func (c *Client) ValidateExpression(expr string) *ExpressionValidation {
+	result := &ExpressionValidation{Expression: expr, Issues: []ExpressionIssue{}}
+
+	parsed, err := ParseExpression(expr)
+	if err != nil {
+		var exprErr *ExpressionError
+		if !errors.As(err, &exprErr) {
+			exprErr = &ExpressionError{Reason: err.Error()}
+		}
+		result.Issues = append(result.Issues, ExpressionIssue{Position: exprErr.Pos, Reason: exprErr.Reason, Message: exprErr.Error()})
+		return result
+	}
+	result.SyntaxValid = true
+	result.Canonical = parsed.String()
+
+	for _, leaf := range parsed.Leaves() {
+		if !c.knownLicense(leaf.License) {
+			reason := fmt.Sprintf("unknown license '%s'", leaf.License)
+			result.Issues = append(result.Issues, ExpressionIssue{
+				Position: leaf.Pos,
+				Reason:   reason,
+				Message:  fmt.Sprintf("%s at index %d", reason, leaf.Pos),
+			})
+		}
+		if leaf.Exception != "" && !knownException(leaf.Exception) {
+			reason := fmt.Sprintf("unknown exception '%s'", leaf.Exception)
+			result.Issues = append(result.Issues, ExpressionIssue{
+				Position: leaf.ExceptionPos,
+				Reason:   reason,
+				Message:  fmt.Sprintf("%s at index %d", reason, leaf.ExceptionPos),
+			})
+		}
+	}
+
+	result.IdentifiersKnown = len(result.Issues) == 0
+	result.Valid = result.IdentifiersKnown
+	return result
+}
+
+// knownLicense reports whether id names a license in the database or a user-defined reference
+func (c *Client) knownLicense(id string) bool {
+	if isLicenseRef(id) {
+		return true
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	candidates := []string{id}
+	for _, suffix := range []string{"-only", "-or-later"} {
+		if base, ok := cutSuffixFold(id, suffix); ok {
+			candidates = append(candidates, base)
+		}
+	}
+	for known := range c.licenses {
+		for _, candidate := range candidates {
+			if strings.EqualFold(known, candidate) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func knownException(id string) bool {
+	if isLicenseRef(id) {
+		return true
+	}
+	for _, known := range exceptions {
+		if strings.EqualFold(known, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLicenseRef reports whether id is a LicenseRef- or DocumentRef- reference, which SPDX
+// documents define themselves
+func isLicenseRef(id string) bool {
+	upper := strings.ToUpper(id)
+	return strings.HasPrefix(upper, "LICENSEREF-") ||
+		(strings.HasPrefix(upper, "DOCUMENTREF-") && strings.Contains(upper, ":LICENSEREF-"))
+}
+
+func cutSuffixFold(s, suffix string) (string, bool) {
+	if len(s) > len(suffix) && strings.EqualFold(s[len(s)-len(suffix):], suffix) {
+		return s[:len(s)-len(suffix)], true
+	}
+	return s, false
+}
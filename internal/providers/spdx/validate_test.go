@@ -0,0 +1,72 @@
+package spdx
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestValidateExpression(t *testing.T) {
+	client := NewClient(zap.NewNop())
+
+	tests := []struct {
+		name       string
+		expr       string
+		wantSyntax bool
+		wantValid  bool
+		wantIssues []string
+	}{
+		{
+			name:       "valid compound expression",
+			expr:       "MIT OR (Apache-2.0 AND bsd-3-clause)",
+			wantSyntax: true,
+			wantValid:  true,
+		},
+		{
+			name:       "only and or-later forms with an exception",
+			expr:       "GPL-2.0-or-later WITH Classpath-exception-2.0 OR LGPL-3.0-only",
+			wantSyntax: true,
+			wantValid:  true,
+		},
+		{
+			name:       "license reference",
+			expr:       "LicenseRef-Proprietary AND MIT",
+			wantSyntax: true,
+			wantValid:  true,
+		},
+		{
+			name:       "unknown license",
+			expr:       "MIT OR Apache2",
+			wantSyntax: true,
+			wantIssues: []string{"unknown license 'Apache2' at index 7"},
+		},
+		{
+			name:       "unknown exception",
+			expr:       "GPL-2.0 WITH Made-Up-exception",
+			wantSyntax: true,
+			wantIssues: []string{"unknown exception 'Made-Up-exception' at index 13"},
+		},
+		{
+			name:       "malformed parenthesis",
+			expr:       "(MIT OR Apache-2.0",
+			wantIssues: []string{"unclosed parenthesis at index 0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := client.ValidateExpression(tt.expr)
+			if result.SyntaxValid != tt.wantSyntax || result.Valid != tt.wantValid {
+				t.Errorf("syntax_valid/valid = %v/%v, want %v/%v", result.SyntaxValid, result.Valid, tt.wantSyntax, tt.wantValid)
+			}
+			if len(result.Issues) != len(tt.wantIssues) {
+				t.Fatalf("issues = %+v, want %v", result.Issues, tt.wantIssues)
+			}
+			for i, want := range tt.wantIssues {
+				if result.Issues[i].Message != want {
+					t.Errorf("issue[%d] = %q, want %q", i, result.Issues[i].Message, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package reqlog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// MetaKey is the _meta field a client can set on a tool call to supply its own request ID
+const MetaKey = "request_id"
+
+// Field is the log field carrying the request ID
+const Field = "request_id"
+
+type loggerKey struct{}
+
+// NewID returns a random 16-character hex request ID
+func NewID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithLogger returns a context carrying a request-scoped logger
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logger returns the request-scoped logger carried by ctx, or fallback outside a request
+func Logger(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+		return logger
+	}
+	return fallback
+}
+
+// Middleware scopes every tool call to a request ID, taken from the call's _meta when the
+// client supplies one and generated otherwise. The handler's context carries a logger with
+// the ID attached; read it with Logger.
+func Middleware(logger *zap.Logger) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+			id := requestID(req)
+			return next(WithLogger(ctx, logger.With(zap.String(Field, id))), method, req)
+		}
+	}
+}
+
+func requestID(req mcp.Request) string {
+	if call, ok := req.(*mcp.CallToolRequest); ok && call.Params != nil {
+		if id, ok := call.Params.Meta[MetaKey]; ok && id != nil {
+			if s := fmt.Sprint(id); s != "" {
+				return s
+			}
+		}
+	}
+	return NewID()
+}
@@ -0,0 +1,70 @@
+package reqlog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogger_FallsBackOutsideRequest(t *testing.T) {
+	fallback := zap.NewNop()
+	if got := Logger(context.Background(), fallback); got != fallback {
+		t.Error("Logger() without a scoped logger should return the fallback")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	handler := Middleware(zap.New(core))(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		Logger(ctx, zap.NewNop()).Info("handled")
+		return nil, nil
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		req    mcp.Request
+		want   string
+	}{
+		{
+			name:   "id from _meta",
+			method: "tools/call",
+			req:    &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Meta: mcp.Meta{MetaKey: "abc"}}},
+			want:   "abc",
+		},
+		{
+			name:   "generated id",
+			method: "tools/call",
+			req:    &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := handler(context.Background(), tt.method, tt.req); err != nil {
+				t.Fatalf("handler error = %v", err)
+			}
+			entries := logs.TakeAll()
+			if len(entries) != 1 {
+				t.Fatalf("got %d log entries, want 1", len(entries))
+			}
+			id, _ := entries[0].ContextMap()[Field].(string)
+			if tt.want != "" && id != tt.want {
+				t.Errorf("request_id = %q, want %q", id, tt.want)
+			}
+			if tt.want == "" && len(id) != 16 {
+				t.Errorf("request_id = %q, want a generated 16-character ID", id)
+			}
+		})
+	}
+
+	// Other methods pass through unscoped
+	if _, err := handler(context.Background(), "tools/list", &mcp.ListToolsRequest{}); err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+	if entries := logs.TakeAll(); len(entries) != 0 {
+		t.Errorf("tools/list logged through the scoped logger: %v", entries)
+	}
+}
@@ -1,24 +1,185 @@
 package resources
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/PackagePulse/internal/history"
+	"github.com/rayprogramming/PackagePulse/internal/tools"
 	"github.com/rayprogramming/hypermcp"
 	"go.uber.org/zap"
 )
 
+// PackageURIPrefix is the scheme and path shared by the package report resource templates
+const PackageURIPrefix = "packagepulse://package/"
+
+// HistoryURI is the resource listing recent tool calls
+const HistoryURI = "packagepulse://history"
+
+// EcosystemsURI is the resource listing the supported ecosystems
+const EcosystemsURI = "packagepulse://ecosystems"
+
+// PackageReporter builds the consolidated report behind the package resource templates
+type PackageReporter interface {
+	HandlePackageReport(ctx context.Context, ecosystem, name, version string) (*tools.PackageReport, error)
+}
+
+// HistorySource lists recent tool calls, newest first
+type HistorySource interface {
+	Entries() []history.Entry
+}
+
 // ResourceRegistry manages all MCP resources
 type ResourceRegistry struct {
-	logger *zap.Logger
+	logger   *zap.Logger
+	reporter PackageReporter
+	history  HistorySource
 }
 
-// NewResourceRegistry creates a new resource registry
-func NewResourceRegistry(logger *zap.Logger) (*ResourceRegistry, error) {
+// NewResourceRegistry creates a new resource registry backed by the tool logic in reporter
+// and the tool call record in hist
+func NewResourceRegistry(logger *zap.Logger, reporter PackageReporter, hist HistorySource) (*ResourceRegistry, error) {
+	if reporter == nil {
+		return nil, fmt.Errorf("package reporter is required")
+	}
+	if hist == nil {
+		return nil, fmt.Errorf("history source is required")
+	}
 	return &ResourceRegistry{
-		logger: logger,
+		logger:   logger,
+		reporter: reporter,
+		history:  hist,
 	}, nil
 }
 
 // Register registers all resources with the server
 func (rr *ResourceRegistry) Register(srv *hypermcp.Server) error {
-	// Resources will be added in future iterations
+	srv.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "package-report",
+		Title:       "Package report",
+		URITemplate: PackageURIPrefix + "{ecosystem}/{name}",
+		Description: "Vulnerabilities and health for the latest view of a package. URL-encode names containing '/' (Go import paths, scoped npm packages) or ':' (Maven coordinates).",
+		MIMEType:    "application/json",
+	}, rr.handlePackageReport)
+
+	srv.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "package-version-report",
+		Title:       "Package version report",
+		URITemplate: PackageURIPrefix + "{ecosystem}/{name}/{version}",
+		Description: "Vulnerabilities and health for one version of a package. URL-encode names containing '/' or ':'.",
+		MIMEType:    "application/json",
+	}, rr.handlePackageReport)
+
+	srv.AddResource(&mcp.Resource{
+		Name:        "history",
+		Title:       "Recent tool calls",
+		URI:         HistoryURI,
+		Description: "The most recent tool calls in this server, newest first: tool name, inputs, result summary, cache hit or miss, and timestamp.",
+		MIMEType:    "application/json",
+	}, rr.handleHistory)
+
+	srv.AddResource(&mcp.Resource{
+		Name:        "ecosystems",
+		Title:       "Supported ecosystems",
+		URI:         EcosystemsURI,
+		Description: "The package ecosystems the tools accept, in OSV's spelling: language ecosystems, and the OS distribution ecosystems (e.g. Debian:11, Alpine:v3.18) that deps.vulns and deps.batch_vulns pass to OSV with their release suffix.",
+		MIMEType:    "application/json",
+	}, rr.handleEcosystems)
+
 	return nil
 }
+
+func (rr *ResourceRegistry) handleEcosystems(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	data, err := json.MarshalIndent(tools.SupportedEcosystems(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("format ecosystems: %w", err)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{
+			URI:      req.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		}},
+	}, nil
+}
+
+// HistoryOutput is the body of the history resource
+type HistoryOutput struct {
+	Count   int             `json:"count"`
+	Entries []history.Entry `json:"entries"`
+}
+
+func (rr *ResourceRegistry) handleHistory(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	entries := rr.history.Entries()
+	data, err := json.MarshalIndent(HistoryOutput{Count: len(entries), Entries: entries}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("format history: %w", err)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{
+			URI:      req.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		}},
+	}, nil
+}
+
+func (rr *ResourceRegistry) handlePackageReport(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	ecosystem, name, version, err := parsePackageURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := rr.reporter.HandlePackageReport(ctx, ecosystem, name, version)
+	if err != nil {
+		rr.logger.Warn("package report failed", zap.String("uri", uri), zap.Error(err))
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("format report: %w", err)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{
+			URI:      uri,
+			MIMEType: "application/json",
+			Text:     string(data),
+		}},
+	}, nil
+}
+
+// parsePackageURI splits packagepulse://package/{ecosystem}/{name}[/{version}],
+// decoding each segment so "github.com%2Fgin-gonic%2Fgin" becomes an import path
+func parsePackageURI(uri string) (ecosystem, name, version string, err error) {
+	rest, ok := strings.CutPrefix(uri, PackageURIPrefix)
+	if !ok {
+		return "", "", "", mcp.ResourceNotFoundError(uri)
+	}
+
+	segments := strings.Split(rest, "/")
+	if len(segments) < 2 || len(segments) > 3 {
+		return "", "", "", mcp.ResourceNotFoundError(uri)
+	}
+	for i, segment := range segments {
+		decoded, err := url.PathUnescape(segment)
+		if err != nil {
+			return "", "", "", fmt.Errorf("invalid package URI %s: %w", uri, err)
+		}
+		if decoded == "" {
+			return "", "", "", mcp.ResourceNotFoundError(uri)
+		}
+		segments[i] = decoded
+	}
+
+	ecosystem, name = segments[0], segments[1]
+	if len(segments) == 3 {
+		version = segments[2]
+	}
+	return ecosystem, name, version, nil
+}
@@ -0,0 +1,190 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/PackagePulse/internal/history"
+	"github.com/rayprogramming/PackagePulse/internal/tools"
+	"github.com/rayprogramming/hypermcp"
+	"github.com/rayprogramming/hypermcp/cache"
+	"go.uber.org/zap"
+)
+
+// fakeReporter records the coordinates it is asked about
+type fakeReporter struct {
+	calls [][3]string
+}
+
+func (f *fakeReporter) HandlePackageReport(_ context.Context, ecosystem, name, version string) (*tools.PackageReport, error) {
+	f.calls = append(f.calls, [3]string{ecosystem, name, version})
+	return &tools.PackageReport{Ecosystem: ecosystem, Package: name, Version: version}, nil
+}
+
+// newServer creates a bare server for connect
+func newServer(t *testing.T) *hypermcp.Server {
+	t.Helper()
+	srv, err := hypermcp.New(hypermcp.Config{
+		Name:         "test",
+		Version:      "1.0.0",
+		CacheEnabled: true,
+		CacheConfig:  cache.Config{MaxCost: 1 << 20, NumCounters: 1000, BufferItems: 64},
+	}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	return srv
+}
+
+// connect registers the resources on srv and returns a connected client session
+func connect(t *testing.T, srv *hypermcp.Server, reporter PackageReporter, hist HistorySource) *mcp.ClientSession {
+	t.Helper()
+
+	registry, err := NewResourceRegistry(zap.NewNop(), reporter, hist)
+	if err != nil {
+		t.Fatalf("NewResourceRegistry() error = %v", err)
+	}
+	if err := registry.Register(srv); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.MCP().Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+func TestPackageResourceTemplate(t *testing.T) {
+	reporter := &fakeReporter{}
+	session := connect(t, newServer(t), reporter, history.New(10))
+	ctx := context.Background()
+
+	tests := []struct {
+		uri  string
+		want [3]string
+	}{
+		{"packagepulse://package/npm/lodash/4.17.19", [3]string{"npm", "lodash", "4.17.19"}},
+		{"packagepulse://package/npm/express", [3]string{"npm", "express", ""}},
+		{"packagepulse://package/Go/github.com%2Fgin-gonic%2Fgin/v1.7.0", [3]string{"Go", "github.com/gin-gonic/gin", "v1.7.0"}},
+		{"packagepulse://package/Maven/org.apache.logging.log4j%3Alog4j-core/2.14.1", [3]string{"Maven", "org.apache.logging.log4j:log4j-core", "2.14.1"}},
+	}
+	for _, tt := range tests {
+		result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: tt.uri})
+		if err != nil {
+			t.Errorf("ReadResource(%s) error = %v", tt.uri, err)
+			continue
+		}
+		got := reporter.calls[len(reporter.calls)-1]
+		if got != tt.want {
+			t.Errorf("ReadResource(%s) reported %v, want %v", tt.uri, got, tt.want)
+		}
+
+		if len(result.Contents) != 1 || result.Contents[0].MIMEType != "application/json" {
+			t.Fatalf("ReadResource(%s) contents = %+v, want one JSON document", tt.uri, result.Contents)
+		}
+		var report tools.PackageReport
+		if err := json.Unmarshal([]byte(result.Contents[0].Text), &report); err != nil {
+			t.Errorf("ReadResource(%s) returned invalid JSON: %v", tt.uri, err)
+		}
+	}
+
+	templates, err := session.ListResourceTemplates(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListResourceTemplates() error = %v", err)
+	}
+	if len(templates.ResourceTemplates) != 2 {
+		t.Errorf("ResourceTemplates = %d, want 2", len(templates.ResourceTemplates))
+	}
+}
+
+func TestParsePackageURI_Invalid(t *testing.T) {
+	for _, uri := range []string{
+		"packagepulse://package/npm",
+		"packagepulse://package/npm//1.0.0",
+		"packagepulse://package/npm/a/b/c",
+		"packagepulse://other/npm/lodash",
+	} {
+		if _, _, _, err := parsePackageURI(uri); err == nil {
+			t.Errorf("parsePackageURI(%s) succeeded, want error", uri)
+		}
+	}
+}
+
+func TestHistoryResource(t *testing.T) {
+	srv := newServer(t)
+	toolRegistry, err := tools.NewToolRegistry(zap.NewNop(), srv.Cache())
+	if err != nil {
+		t.Fatalf("NewToolRegistry() error = %v", err)
+	}
+	if err := toolRegistry.Register(srv); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	session := connect(t, srv, toolRegistry, toolRegistry.History())
+	ctx := context.Background()
+
+	calls := []*mcp.CallToolParams{
+		{Name: "license.info", Arguments: map[string]any{"license_id": "MIT"}},
+		{Name: "license.validate_expression", Arguments: map[string]any{"expression": "MIT OR Apache2"}},
+	}
+	for _, call := range calls {
+		if _, err := session.CallTool(ctx, call); err != nil {
+			t.Fatalf("CallTool(%s) error = %v", call.Name, err)
+		}
+	}
+
+	result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: HistoryURI})
+	if err != nil {
+		t.Fatalf("ReadResource(%s) error = %v", HistoryURI, err)
+	}
+	var out HistoryOutput
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &out); err != nil {
+		t.Fatalf("history is not valid JSON: %v", err)
+	}
+	if out.Count != 2 || len(out.Entries) != 2 {
+		t.Fatalf("history has %d entries, want 2: %+v", len(out.Entries), out.Entries)
+	}
+
+	newest, oldest := out.Entries[0], out.Entries[1]
+	if newest.Tool != "license.validate_expression" || oldest.Tool != "license.info" {
+		t.Errorf("history order = [%s, %s], want newest first", newest.Tool, oldest.Tool)
+	}
+	if oldest.Inputs["license_id"] != "MIT" {
+		t.Errorf("license.info inputs = %v, want license_id MIT", oldest.Inputs)
+	}
+	if oldest.Cache != history.CacheMiss {
+		t.Errorf("license.info cache = %q, want %q", oldest.Cache, history.CacheMiss)
+	}
+	if newest.Timestamp.Before(oldest.Timestamp) {
+		t.Errorf("newest entry %v is older than %v", newest.Timestamp, oldest.Timestamp)
+	}
+}
+
+func TestEcosystemsResource(t *testing.T) {
+	session := connect(t, newServer(t), &fakeReporter{}, history.New(10))
+
+	result, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: EcosystemsURI})
+	if err != nil {
+		t.Fatalf("ReadResource(%s) error = %v", EcosystemsURI, err)
+	}
+	var out tools.EcosystemList
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &out); err != nil {
+		t.Fatalf("ecosystems is not valid JSON: %v", err)
+	}
+	if !slices.Contains(out.Language, "npm") || !slices.Contains(out.OS, "Debian") || !slices.Contains(out.OSToolNames, "deps.vulns") {
+		t.Errorf("ecosystems = %+v, want npm, Debian, and the OS-capable tools", out)
+	}
+}
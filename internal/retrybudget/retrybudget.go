@@ -0,0 +1,108 @@
+package retrybudget
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCapacity is how many retries may be spent in a burst
+	DefaultCapacity = 10
+	// DefaultRefill is how long the budget takes to earn back one retry
+	DefaultRefill = 2 * time.Second
+)
+
+// Status is a snapshot of a budget for metrics
+type Status struct {
+	// Available is how many retries may be spent right now
+	Available        int   `json:"available"`
+	Capacity         int   `json:"capacity"`
+	RefillIntervalMS int64 `json:"refill_interval_ms"`
+	Exhausted        bool  `json:"exhausted"`
+	// Granted and Denied count retries allowed and refused since start or the last ResetCounts
+	Granted int64 `json:"granted"`
+	Denied  int64 `json:"denied"`
+}
+
+// Budget is a token bucket shared by every retrying call to the upstreams, capping aggregate
+// retries so a flood of failures cannot multiply into a retry storm. Each retry spends a token;
+// tokens are earned back one per refill interval up to capacity, and once the bucket is empty
+// callers give up instead of retrying. First attempts never spend tokens. It is safe for
+// concurrent use.
+type Budget struct {
+	capacity float64
+	refill   time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	tokens  float64
+	updated time.Time
+	granted int64
+	denied  int64
+}
+
+// New creates a full budget. Non-positive capacity or refill fall back to the defaults.
+func New(capacity int, refill time.Duration) *Budget {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	if refill <= 0 {
+		refill = DefaultRefill
+	}
+	b := &Budget{
+		capacity: float64(capacity),
+		refill:   refill,
+		now:      time.Now,
+		tokens:   float64(capacity),
+	}
+	b.updated = b.now()
+	return b
+}
+
+// Allow spends one retry, reporting false without spending when the budget is exhausted
+func (b *Budget) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.replenish()
+	if b.tokens < 1 {
+		b.denied++
+		return false
+	}
+	b.tokens--
+	b.granted++
+	return true
+}
+
+// Status returns the budget's current state
+func (b *Budget) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.replenish()
+	return Status{
+		Available:        int(b.tokens),
+		Capacity:         int(b.capacity),
+		RefillIntervalMS: b.refill.Milliseconds(),
+		Exhausted:        b.tokens < 1,
+		Granted:          b.granted,
+		Denied:           b.denied,
+	}
+}
+
+// ResetCounts clears the granted and denied counts, leaving the available retries as they are
+func (b *Budget) ResetCounts() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.granted = 0
+	b.denied = 0
+}
+
+// replenish credits the tokens earned since the last update; callers hold mu
+func (b *Budget) replenish() {
+	now := b.now()
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = min(b.capacity, b.tokens+float64(elapsed)/float64(b.refill))
+	}
+	b.updated = now
+}
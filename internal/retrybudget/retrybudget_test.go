@@ -0,0 +1,66 @@
+package retrybudget
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock lets tests move time to replenish the budget
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func newTestBudget(capacity int, refill time.Duration) (*Budget, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b := New(capacity, refill)
+	b.now = clock.Now
+	b.updated = clock.now
+	return b, clock
+}
+
+func TestBudget_ExhaustsAndReplenishes(t *testing.T) {
+	b, clock := newTestBudget(3, 10*time.Second)
+
+	for i := 0; i < 3; i++ {
+		if !b.Allow() {
+			t.Fatalf("Allow() #%d within capacity = false", i+1)
+		}
+	}
+	if b.Allow() {
+		t.Fatal("Allow() past capacity = true, want false")
+	}
+	status := b.Status()
+	if !status.Exhausted || status.Available != 0 || status.Granted != 3 || status.Denied != 1 {
+		t.Errorf("Status() = %+v, want exhausted with 3 granted and 1 denied", status)
+	}
+
+	// Part of a refill interval earns nothing spendable
+	clock.now = clock.now.Add(5 * time.Second)
+	if b.Allow() {
+		t.Error("Allow() half a refill later = true, want false")
+	}
+
+	// Each full interval earns one retry back; the refused call spent nothing
+	clock.now = clock.now.Add(15 * time.Second)
+	if got := b.Status().Available; got != 2 {
+		t.Errorf("Available two intervals after exhaustion = %d, want 2", got)
+	}
+
+	// A long idle period refills only to capacity
+	clock.now = clock.now.Add(time.Hour)
+	if status := b.Status(); status.Available != 3 || status.Exhausted {
+		t.Errorf("Status() after idling = %+v, want 3 available", status)
+	}
+
+	b.ResetCounts()
+	if status := b.Status(); status.Granted != 0 || status.Denied != 0 || status.Available != 3 {
+		t.Errorf("Status() after ResetCounts = %+v, want zero counts and tokens kept", status)
+	}
+}
+
+func TestNew_Defaults(t *testing.T) {
+	status := New(0, 0).Status()
+	if status.Capacity != DefaultCapacity || status.RefillIntervalMS != DefaultRefill.Milliseconds() || status.Available != DefaultCapacity {
+		t.Errorf("Status() = %+v, want a full budget with the defaults", status)
+	}
+}
@@ -0,0 +1,232 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/PackagePulse/internal/retrybudget"
+)
+
+// DefaultSamples is how many recent request latencies each upstream keeps for percentiles
+const DefaultSamples = 1000
+
+// Counts tallies the outcomes of tool calls or upstream requests
+type Counts struct {
+	Successes int64 `json:"successes"`
+	Errors    int64 `json:"errors"`
+	// ErrorRate is Errors over all outcomes, 0 before any are recorded
+	ErrorRate float64 `json:"error_rate"`
+}
+
+// UpstreamStats is the request outcomes and latency of one upstream API
+type UpstreamStats struct {
+	Counts
+	// P50MS and P95MS are latency percentiles, in milliseconds, over the most recent requests
+	P50MS float64 `json:"p50_ms"`
+	P95MS float64 `json:"p95_ms"`
+}
+
+// Snapshot is the state of a Recorder at one point in time
+type Snapshot struct {
+	Since      time.Time                `json:"since"`
+	Ecosystems map[string]Counts        `json:"ecosystems"`
+	Upstreams  map[string]UpstreamStats `json:"upstreams"`
+	// RetryBudget is the state of the shared upstream retry budget, when one is tracked
+	RetryBudget *retrybudget.Status `json:"retry_budget,omitempty"`
+}
+
+// Recorder counts tool-call outcomes per ecosystem and request outcomes and latencies per
+// upstream, from process start or the last Reset. It is safe for concurrent use.
+type Recorder struct {
+	mu         sync.Mutex
+	since      time.Time
+	ecosystems map[string]*Counts
+	upstreams  map[string]*upstream
+	// retryBudget is reported alongside the counts, and its counts are reset with them
+	retryBudget *retrybudget.Budget
+}
+
+// upstream holds one upstream's counts and a ring buffer of its recent latencies
+type upstream struct {
+	counts  Counts
+	samples []time.Duration
+	next    int
+}
+
+// New creates an empty Recorder
+func New() *Recorder {
+	r := &Recorder{}
+	r.Reset()
+	return r
+}
+
+// Reset clears every count and latency sample
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.since = time.Now().UTC()
+	r.ecosystems = make(map[string]*Counts)
+	r.upstreams = make(map[string]*upstream)
+	if r.retryBudget != nil {
+		r.retryBudget.ResetCounts()
+	}
+}
+
+// TrackRetryBudget reports b's state in every Snapshot
+func (r *Recorder) TrackRetryBudget(b *retrybudget.Budget) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.retryBudget = b
+}
+
+// RecordEcosystem notes the outcome of a tool call for one ecosystem
+func (r *Recorder) RecordEcosystem(ecosystem string, success bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts, ok := r.ecosystems[ecosystem]
+	if !ok {
+		counts = &Counts{}
+		r.ecosystems[ecosystem] = counts
+	}
+	counts.add(success)
+}
+
+// RecordUpstream notes the outcome and latency of one request to an upstream
+func (r *Recorder) RecordUpstream(name string, success bool, latency time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.upstreams[name]
+	if !ok {
+		u = &upstream{}
+		r.upstreams[name] = u
+	}
+	u.counts.add(success)
+	if len(u.samples) < DefaultSamples {
+		u.samples = append(u.samples, latency)
+		return
+	}
+	u.samples[u.next] = latency
+	u.next = (u.next + 1) % DefaultSamples
+}
+
+func (c *Counts) add(success bool) {
+	if success {
+		c.Successes++
+	} else {
+		c.Errors++
+	}
+	c.ErrorRate = float64(c.Errors) / float64(c.Successes+c.Errors)
+}
+
+// Snapshot returns a copy of the current counts with latency percentiles computed
+func (r *Recorder) Snapshot() Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	snapshot := Snapshot{
+		Since:      r.since,
+		Ecosystems: make(map[string]Counts, len(r.ecosystems)),
+		Upstreams:  make(map[string]UpstreamStats, len(r.upstreams)),
+	}
+	for name, counts := range r.ecosystems {
+		snapshot.Ecosystems[name] = *counts
+	}
+	for name, u := range r.upstreams {
+		sorted := slices.Clone(u.samples)
+		slices.Sort(sorted)
+		snapshot.Upstreams[name] = UpstreamStats{
+			Counts: u.counts,
+			P50MS:  percentile(sorted, 50),
+			P95MS:  percentile(sorted, 95),
+		}
+	}
+	if r.retryBudget != nil {
+		status := r.retryBudget.Status()
+		snapshot.RetryBudget = &status
+	}
+	return snapshot
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies in milliseconds
+func percentile(sorted []time.Duration, p int) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	return float64(sorted[max(rank, 1)-1].Microseconds()) / 1000
+}
+
+// ServeHTTP writes the current Snapshot as JSON, for the /metrics endpoint
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(r.Snapshot())
+}
+
+// Upstream returns a handle that records requests under name
+func (r *Recorder) Upstream(name string) *Upstream {
+	return &Upstream{recorder: r, name: name}
+}
+
+// Upstream records the requests of one upstream API
+type Upstream struct {
+	recorder *Recorder
+	name     string
+}
+
+// Transport wraps next (http.DefaultTransport when nil) so every request's outcome and latency
+// is recorded. Like the circuit breaker, transport errors and 5xx responses count as errors,
+// and requests abandoned by their caller are not counted.
+func (u *Upstream) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{upstream: u, next: next}
+}
+
+type transport struct {
+	upstream *Upstream
+	next     http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil && req.Context().Err() != nil {
+		return nil, err
+	}
+	success := err == nil && resp.StatusCode < http.StatusInternalServerError
+	t.upstream.recorder.RecordUpstream(t.upstream.name, success, time.Since(start))
+	return resp, err
+}
+
+// Middleware records the outcome of every tool call whose arguments name an ecosystem.
+// ecosystem maps the argument to the name it is counted under, reporting false for values
+// that are not a supported ecosystem so typos do not each get an entry.
+func Middleware(r *Recorder, ecosystem func(string) (string, bool)) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if method != "tools/call" || !ok || call.Params == nil {
+				return next(ctx, method, req)
+			}
+
+			result, err := next(ctx, method, req)
+
+			var args struct {
+				Ecosystem string `json:"ecosystem"`
+			}
+			if json.Unmarshal(call.Params.Arguments, &args) != nil || args.Ecosystem == "" {
+				return result, err
+			}
+			if name, ok := ecosystem(args.Ecosystem); ok {
+				res, _ := result.(*mcp.CallToolResult)
+				r.RecordEcosystem(name, err == nil && (res == nil || !res.IsError))
+			}
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestRecorder_Percentiles(t *testing.T) {
+	r := New()
+	for ms := 100; ms >= 1; ms-- {
+		r.RecordUpstream("osv", ms != 100, time.Duration(ms)*time.Millisecond)
+	}
+
+	got := r.Snapshot().Upstreams["osv"]
+	if got.Successes != 99 || got.Errors != 1 || got.ErrorRate != 0.01 {
+		t.Errorf("counts = %+v, want 99 successes and 1 error", got.Counts)
+	}
+	if got.P50MS != 50 || got.P95MS != 95 {
+		t.Errorf("p50 = %v, p95 = %v; want 50 and 95", got.P50MS, got.P95MS)
+	}
+
+	// Only the most recent DefaultSamples latencies are kept
+	for i := 0; i < DefaultSamples; i++ {
+		r.RecordUpstream("osv", true, time.Second)
+	}
+	if got := r.Snapshot().Upstreams["osv"]; got.P50MS != 1000 || got.P95MS != 1000 {
+		t.Errorf("after %d slow requests p50 = %v, p95 = %v; want 1000", DefaultSamples, got.P50MS, got.P95MS)
+	}
+
+	r.Reset()
+	if snapshot := r.Snapshot(); len(snapshot.Upstreams) != 0 || len(snapshot.Ecosystems) != 0 {
+		t.Errorf("snapshot after Reset = %+v, want empty", snapshot)
+	}
+}
+
+func TestUpstream_Transport(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	r := New()
+	client := &http.Client{Transport: r.Upstream("deps.dev").Transport(nil)}
+	for _, code := range []int{http.StatusOK, http.StatusNotFound, http.StatusBadGateway} {
+		status = code
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	// A 404 is an answer; only the 502 is an upstream error
+	got := r.Snapshot().Upstreams["deps.dev"]
+	if got.Successes != 2 || got.Errors != 1 {
+		t.Errorf("counts = %+v, want 2 successes and 1 error", got.Counts)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	r := New()
+	fail := false
+	handler := Middleware(r, func(ecosystem string) (string, bool) {
+		return ecosystem, ecosystem != "bogus"
+	})(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if fail {
+			return nil, errors.New("boom")
+		}
+		return &mcp.CallToolResult{}, nil
+	})
+
+	call := func(args map[string]any) {
+		raw, _ := json.Marshal(args)
+		_, _ = handler(context.Background(), "tools/call", &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Name: "deps.vulns", Arguments: raw},
+		})
+	}
+	call(map[string]any{"ecosystem": "npm"})
+	fail = true
+	call(map[string]any{"ecosystem": "npm"})
+	call(map[string]any{"ecosystem": "bogus"})
+	call(map[string]any{"filename": "go.sum"})
+
+	snapshot := r.Snapshot()
+	want := Counts{Successes: 1, Errors: 1, ErrorRate: 0.5}
+	if got := snapshot.Ecosystems["npm"]; got != want || len(snapshot.Ecosystems) != 1 {
+		t.Errorf("ecosystems = %+v, want only npm %+v", snapshot.Ecosystems, want)
+	}
+}
@@ -0,0 +1,126 @@
+package stdioguard
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// maxLoggedBytes caps how much of a stray line is copied into the warning
+const maxLoggedBytes = 256
+
+// Guard reserves stdout for the MCP protocol. While installed, os.Stdout points at a pipe;
+// anything written there is logged as a warning and discarded instead of corrupting the stream.
+// Writes made directly to file descriptor 1 bypass os.Stdout and are not caught.
+type Guard struct {
+	logger   *zap.Logger
+	original *os.File
+	reader   *os.File
+	writer   *os.File
+	done     chan struct{}
+	once     sync.Once
+}
+
+// Install swaps os.Stdout for a guarded pipe. Call Restore to put the original back.
+func Install(logger *zap.Logger) (*Guard, error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Guard{
+		logger:   logger,
+		original: os.Stdout,
+		reader:   reader,
+		writer:   writer,
+		done:     make(chan struct{}),
+	}
+	os.Stdout = writer
+	go g.watch()
+	return g, nil
+}
+
+// Restore reinstates the original stdout and waits for pending warnings to be logged.
+// It is safe to call more than once.
+func (g *Guard) Restore() {
+	g.once.Do(func() {
+		os.Stdout = g.original
+		_ = g.writer.Close()
+		<-g.done
+		_ = g.reader.Close()
+	})
+}
+
+// watch logs each stray line until the pipe closes. Lines of any length are drained, with only
+// their first maxLoggedBytes kept, so a long write cannot stall the pipe and block its writer.
+func (g *Guard) watch() {
+	defer close(g.done)
+
+	reader := bufio.NewReader(g.reader)
+	var line []byte
+	truncated := false
+	for {
+		chunk, isPrefix, err := reader.ReadLine()
+		if err != nil {
+			if len(line) > 0 || truncated {
+				g.warn(line, truncated)
+			}
+			return
+		}
+		if room := maxLoggedBytes - len(line); len(chunk) > room {
+			chunk, truncated = chunk[:room], true
+		}
+		line = append(line, chunk...)
+		if !isPrefix {
+			g.warn(line, truncated)
+			line, truncated = line[:0], false
+		}
+	}
+}
+
+func (g *Guard) warn(line []byte, truncated bool) {
+	output := string(line)
+	if truncated {
+		output += "..."
+	}
+	g.logger.Warn("stray write to stdout discarded: stdout carries the MCP protocol and must not be written to directly",
+		zap.String("output", output))
+}
+
+// Transport serves MCP over stdio and installs a Guard once the protocol connection holds
+// the real stdout. The guard is removed when the connection closes.
+type Transport struct {
+	Logger *zap.Logger
+}
+
+// Connect opens the stdio connection, then guards os.Stdout against other writers
+func (t *Transport) Connect(ctx context.Context) (mcp.Connection, error) {
+	// StdioTransport binds whatever os.Stdout is at connect time, so connect before swapping it
+	conn, err := (&mcp.StdioTransport{}).Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	guard, err := Install(t.Logger)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return &guardedConn{Connection: conn, guard: guard}, nil
+}
+
+// guardedConn restores stdout when the protocol connection closes
+type guardedConn struct {
+	mcp.Connection
+	guard *Guard
+}
+
+func (c *guardedConn) Close() error {
+	err := c.Connection.Close()
+	c.guard.Restore()
+	return err
+}
@@ -0,0 +1,82 @@
+package stdioguard
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestGuard_WarnsOnStrayWrite(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	original := os.Stdout
+
+	guard, err := Install(zap.New(core))
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	t.Cleanup(guard.Restore)
+
+	if os.Stdout == original {
+		t.Fatal("expected os.Stdout to be replaced while the guard is installed")
+	}
+	fmt.Println("debug: oops")
+
+	guard.Restore()
+	if os.Stdout != original {
+		t.Error("expected Restore to reinstate the original stdout")
+	}
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("got %d warnings, want 1", len(entries))
+	}
+	if got := entries[0].ContextMap()["output"]; got != "debug: oops" {
+		t.Errorf("output = %v, want the stray line", got)
+	}
+
+	// Restore is idempotent
+	guard.Restore()
+}
+
+func TestGuard_DrainsLongLines(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	guard, err := Install(zap.New(core))
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	t.Cleanup(guard.Restore)
+
+	// A line past bufio.Scanner's 64 KB limit, then more than a pipe buffer of further output,
+	// which blocks the writer unless the guard keeps draining
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		fmt.Println(strings.Repeat("x", 100*1024))
+		for i := 0; i < 1000; i++ {
+			fmt.Printf("after %d %s\n", i, strings.Repeat("y", 100))
+		}
+	}()
+	select {
+	case <-written:
+	case <-time.After(5 * time.Second):
+		t.Fatal("writes to stdout blocked after a long line")
+	}
+	guard.Restore()
+
+	entries := logs.All()
+	if len(entries) != 1001 {
+		t.Fatalf("got %d warnings, want 1001", len(entries))
+	}
+	if got := entries[0].ContextMap()["output"]; got != strings.Repeat("x", maxLoggedBytes)+"..." {
+		t.Errorf("long line output = %.20v..., want its first %d bytes", got, maxLoggedBytes)
+	}
+	if got, _ := entries[1000].ContextMap()["output"].(string); !strings.HasPrefix(got, "after 999 ") {
+		t.Errorf("last output = %.20q, want the final line", got)
+	}
+}
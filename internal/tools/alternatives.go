@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+)
+
+// defaultAlternatives seeds the curated replacements for well-known abandoned or deprecated
+// packages, keyed by "ecosystem/name" in lower case
+var defaultAlternatives = map[string][]string{
+	"npm/request":                    {"axios", "got", "node-fetch"},
+	"npm/moment":                     {"date-fns", "luxon", "dayjs"},
+	"npm/node-sass":                  {"sass"},
+	"npm/tslint":                     {"eslint", "typescript-eslint"},
+	"npm/bower":                      {"npm", "yarn"},
+	"npm/istanbul":                   {"nyc", "c8"},
+	"npm/uuid-js":                    {"uuid"},
+	"pypi/nose":                      {"pytest"},
+	"pypi/pycrypto":                  {"pycryptodome", "cryptography"},
+	"pypi/mock":                      {"unittest.mock (standard library)"},
+	"go/github.com/dgrijalva/jwt-go": {"github.com/golang-jwt/jwt/v5"},
+	"go/github.com/golang/protobuf":  {"google.golang.org/protobuf"},
+	"go/github.com/pkg/errors":       {"errors (standard library)"},
+	"go/github.com/satori/go.uuid":   {"github.com/google/uuid", "github.com/gofrs/uuid"},
+	"maven/commons-httpclient:commons-httpclient": {"org.apache.httpcomponents.client5:httpclient5"},
+	"rubygems/therubyracer":                       {"mini_racer"},
+}
+
+// alternativesKey is the lookup key for a package in the alternatives map
+func alternativesKey(ecosystem, name string) string {
+	return strings.ToLower(ecosystem) + "/" + strings.ToLower(name)
+}
+
+// mergeAlternatives overlays configured alternatives on the seeded ones; a configured entry
+// replaces the seeded list for that package
+func mergeAlternatives(configured map[string][]string) map[string][]string {
+	merged := make(map[string][]string, len(defaultAlternatives)+len(configured))
+	for key, alts := range defaultAlternatives {
+		merged[key] = alts
+	}
+	for key, alts := range configured {
+		ecosystem, name, _ := strings.Cut(key, "/")
+		merged[alternativesKey(ecosystem, name)] = alts
+	}
+	return merged
+}
+
+// validateAlternatives checks that every configured key names an ecosystem and a package
+func validateAlternatives(configured map[string][]string) error {
+	for key := range configured {
+		ecosystem, name, ok := strings.Cut(key, "/")
+		if !ok || ecosystem == "" || name == "" {
+			return fmt.Errorf("alternatives: key %q must be ecosystem/name", key)
+		}
+	}
+	return nil
+}
+
+// suggestAlternatives returns curated replacements for a package whose maintenance level is
+// poor or critical. It returns nil for healthier packages and for packages without a
+// curated entry rather than guessing.
+func (tr *ToolRegistry) suggestAlternatives(ecosystem, name, maintenanceLevel string) []string {
+	if maintenanceLevel != "poor" && maintenanceLevel != "critical" {
+		return nil
+	}
+	return slices.Clone(tr.alternatives[alternativesKey(ecosystem, name)])
+}
+
+// HealthOutput is the deps.health response: the package's health metrics plus curated
+// alternatives when maintenance is poor
+type HealthOutput struct {
+	*depsdev.HealthMetrics
+	// ScoreBreakdown is each signal's share of the maintenance score, when explain is set
+	ScoreBreakdown        []depsdev.ScoreComponent `json:"score_breakdown,omitempty"`
+	SuggestedAlternatives []string                 `json:"suggested_alternatives,omitempty"`
+	ResolvedQuery         *ResolvedQuery           `json:"resolved_query,omitempty"`
+	CacheStatus
+}
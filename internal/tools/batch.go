@@ -0,0 +1,312 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/rayprogramming/PackagePulse/internal/cvss"
+	"github.com/rayprogramming/PackagePulse/internal/pool"
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"go.uber.org/zap"
+)
+
+// batchChunkSize is how many packages go into each OSV batch request
+const batchChunkSize = 100
+
+// batchChunkConcurrency bounds how many OSV batch requests a scan has in flight
+const batchChunkConcurrency = 4
+
+// BatchVulnsInput defines input for deps.batch_vulns tool
+type BatchVulnsInput struct {
+	Packages     []VulnsInput `json:"packages"`
+	OutputFormat string       `json:"output_format,omitempty"`
+	// DryRun reports the planned upstream calls instead of making them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// BatchVulnsOutput contains per-package vulnerability results
+type BatchVulnsOutput struct {
+	PackageCount       int                 `json:"package_count"`
+	VulnerabilityCount int                 `json:"vulnerability_count"`
+	ErrorCount         int                 `json:"error_count"`
+	Results            []*BatchVulnsResult `json:"results"`
+	Summary            VulnSummary         `json:"summary"`
+	OSVAPI             string              `json:"osv_api,omitempty"`
+	DataSources
+	// Plan is set instead of results on a dry run
+	Plan *DryRunPlan `json:"plan,omitempty"`
+	// BySeverity is a flat triage view of every finding in Results, critical first
+	BySeverity []SeverityGroup `json:"by_severity,omitempty"`
+}
+
+// severityOrder lists the ratings of SeverityGroup, most severe first
+var severityOrder = []string{cvss.RatingCritical, cvss.RatingHigh, cvss.RatingMedium, cvss.RatingLow, "unknown"}
+
+// SeverityGroup lists every finding of one severity rating across a batch scan
+type SeverityGroup struct {
+	Severity string          `json:"severity"`
+	Count    int             `json:"count"`
+	Findings []TriageFinding `json:"findings"`
+}
+
+// TriageFinding is one finding of a SeverityGroup with the package it was found in. The full
+// finding stays under its package in Results.
+type TriageFinding struct {
+	ID             string   `json:"id"`
+	Summary        string   `json:"summary,omitempty"`
+	Ecosystem      string   `json:"ecosystem"`
+	Package        string   `json:"package"`
+	Version        string   `json:"version,omitempty"`
+	SeverityScore  *float64 `json:"severity_score,omitempty"`
+	RiskScore      float64  `json:"risk_score"`
+	KnownExploited bool     `json:"known_exploited,omitempty"`
+	FixURL         string   `json:"fix_url,omitempty"`
+}
+
+// BatchVulnsResult is one entry of a batch scan, aligned to the input. An entry that could
+// not be scanned carries Error and echoes its Request instead of a result.
+type BatchVulnsResult struct {
+	*VulnsOutput
+	Request *VulnsInput `json:"request,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// scanned returns the successful results, in input order
+func (o *BatchVulnsOutput) scanned() []*VulnsOutput {
+	var results []*VulnsOutput
+	for _, r := range o.Results {
+		if r.VulnsOutput != nil {
+			results = append(results, r.VulnsOutput)
+		}
+	}
+	return results
+}
+
+// HandleBatchVulns implements deps.batch_vulns tool using OSV batch queries of up to
+// batchChunkSize packages, up to batchChunkConcurrency at a time, reporting progress as each completes. Findings get the same EPSS, KEV, and risk enrichment as deps.vulns.
+// An invalid entry or a failed OSV request only fails the entries it affects; everything else is still returned.
+// With dry_run the entries are validated and the planned upstream calls returned without making any.
+// Example: {"packages": [{"ecosystem": "npm", "package": "lodash", "version": "4.17.19"}]}
+func (tr *ToolRegistry) HandleBatchVulns(ctx context.Context, input BatchVulnsInput) (*BatchVulnsOutput, error) {
+	if len(input.Packages) == 0 {
+		return nil, fmt.Errorf("packages is required")
+	}
+
+	output := &BatchVulnsOutput{
+		PackageCount: len(input.Packages),
+		Results:      make([]*BatchVulnsResult, len(input.Packages)),
+		OSVAPI:       tr.osvClient.BaseURL() + osv.BatchPath,
+	}
+	fail := func(i int, err error) {
+		request := input.Packages[i]
+		output.Results[i] = &BatchVulnsResult{Request: &request, Error: err.Error()}
+		output.ErrorCount++
+	}
+
+	// Validate each entry on its own; indexes maps each query back to its input position
+	var (
+		queries []osv.QueryRequest
+		indexes []int
+	)
+	for i, pkg := range input.Packages {
+		if pkg.Ecosystem == "" || pkg.Package == "" {
+			fail(i, fmt.Errorf("ecosystem and package are required"))
+			continue
+		}
+		if err := validateSortBy(pkg.SortBy); err != nil {
+			fail(i, err)
+			continue
+		}
+		ecosystem, err := validateScanEcosystem(pkg.Ecosystem)
+		if err != nil {
+			fail(i, err)
+			continue
+		}
+		if pkg.Package, pkg.Version, err = routeGoVersion(ecosystem, pkg.Package, pkg.Version); err != nil {
+			fail(i, err)
+			continue
+		}
+		if input.DryRun {
+			// Canonical names need a lookup, so a dry run plans it instead
+			pkg.Ecosystem = normalizeEcosystem(ecosystem)
+		} else {
+			pkg.Ecosystem, pkg.Package = tr.normalizePackage(ctx, ecosystem, pkg.Package)
+		}
+		input.Packages[i] = pkg
+		queries = append(queries, osv.QueryRequest{
+			Package: osv.Package{
+				Name:      pkg.Package,
+				Ecosystem: pkg.Ecosystem,
+			},
+			Version: pkg.Version,
+		})
+		indexes = append(indexes, i)
+	}
+
+	tr.log(ctx).Info("Handling batch vulnerability query",
+		zap.Int("packages", len(input.Packages)),
+		zap.Int("invalid", output.ErrorCount),
+		zap.Bool("dry_run", input.DryRun))
+
+	// A dry run stops here, keeping only the invalid entries as results
+	if input.DryRun {
+		output.Plan = tr.planBatchVulns(queries, output.ErrorCount)
+		output.Results = slices.DeleteFunc(output.Results, func(r *BatchVulnsResult) bool { return r == nil })
+		return output, nil
+	}
+
+	// Query in chunks so long scans can report progress between requests. A failed chunk
+	// fails only its own entries.
+	var starts []int
+	for start := 0; start < len(queries); start += batchChunkSize {
+		starts = append(starts, start)
+	}
+	var scanned atomic.Int64
+	chunks, err := pool.Map(ctx, starts, batchChunkConcurrency, func(ctx context.Context, start int) ([]osv.QueryResponse, error) {
+		end := min(start+batchChunkSize, len(queries))
+		chunk, err := tr.osvClient.BatchQueryDetailed(ctx, queries[start:end])
+		if err == nil && len(chunk) != end-start {
+			err = fmt.Errorf("expected %d results, got %d", end-start, len(chunk))
+		}
+		reportProgress(ctx, int(scanned.Add(int64(end-start))), len(queries))
+		return chunk, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("batch query OSV: %w", err)
+	}
+
+	responses := make([]*osv.QueryResponse, len(queries))
+	var osvErr error
+	for c, result := range chunks {
+		start := starts[c]
+		end := min(start+batchChunkSize, len(queries))
+		if result.Err != nil {
+			tr.log(ctx).Warn("OSV batch chunk failed",
+				zap.Int("start", start),
+				zap.Int("size", end-start),
+				zap.Error(result.Err))
+			osvErr = result.Err
+			for j := start; j < end; j++ {
+				fail(indexes[j], fmt.Errorf("batch query OSV: %w", result.Err))
+			}
+			continue
+		}
+		for j := range result.Value {
+			responses[start+j] = &result.Value[j]
+		}
+	}
+
+	var all []osv.Vulnerability
+	for _, resp := range responses {
+		if resp != nil {
+			all = append(all, resp.Vulns...)
+		}
+	}
+
+	// Enrich every finding in one pass so EPSS is fetched with a single request
+	findings := newFindings(all)
+	epssErr := tr.enrichEPSS(ctx, findings)
+	_, kevErr := tr.markKnownExploited(ctx, findings)
+	scoreFindings(findings, tr.config.RiskWeights)
+
+	// The envelope reports every source across the batch. Each scanned entry's OSV chunk
+	// succeeded, so an entry shares only the batch-wide EPSS and KEV outcomes.
+	output.record(SourceOSV, osvErr)
+	output.record(SourceEPSS, epssErr)
+	output.record(SourceKEV, kevErr)
+	var entrySources DataSources
+	entrySources.record(SourceOSV, nil)
+	entrySources.record(SourceEPSS, epssErr)
+	entrySources.record(SourceKEV, kevErr)
+
+	offset := 0
+	for j, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		pkg := input.Packages[indexes[j]]
+		vulns := resp.Vulns
+		pkgFindings := findings[offset : offset+len(vulns) : offset+len(vulns)]
+		offset += len(vulns)
+		setAffectedVersions(pkgFindings, pkg.Package, depsdev.SchemeFor(pkg.Ecosystem))
+		sortFindings(pkgFindings, pkg.SortBy)
+
+		output.Results[indexes[j]] = &BatchVulnsResult{VulnsOutput: &VulnsOutput{
+			Package:            pkg.Package,
+			Ecosystem:          pkg.Ecosystem,
+			Version:            pkg.Version,
+			VulnerabilityCount: len(vulns),
+			Vulnerabilities:    pkgFindings,
+			Summary:            computeVulnSummary(vulns),
+			OSVAPI:             output.OSVAPI,
+			DataSources:        entrySources,
+		}}
+	}
+
+	output.VulnerabilityCount = len(all)
+	output.Summary = computeVulnSummary(all)
+	output.BySeverity = groupBySeverity(output.scanned())
+
+	return output, nil
+}
+
+// groupBySeverity flattens the findings of every scanned package into severity groups, most
+// severe first and empty groups left out. Within a group findings are ordered by CVSS base
+// score, then risk score, descending, then by package and ID.
+func groupBySeverity(results []*VulnsOutput) []SeverityGroup {
+	byRating := make(map[string][]TriageFinding)
+	for _, result := range results {
+		for _, f := range result.Vulnerabilities {
+			rating := severityRating(f.Vulnerability)
+			if !slices.Contains(severityOrder, rating) {
+				rating = "unknown"
+			}
+			byRating[rating] = append(byRating[rating], TriageFinding{
+				ID:             f.ID,
+				Summary:        f.Summary,
+				Ecosystem:      result.Ecosystem,
+				Package:        result.Package,
+				Version:        result.Version,
+				SeverityScore:  f.SeverityScore,
+				RiskScore:      f.RiskScore,
+				KnownExploited: f.KnownExploited,
+				FixURL:         f.FixURL,
+			})
+		}
+	}
+
+	var groups []SeverityGroup
+	for _, rating := range severityOrder {
+		findings := byRating[rating]
+		if len(findings) == 0 {
+			continue
+		}
+		slices.SortStableFunc(findings, func(a, b TriageFinding) int {
+			if c := cmp.Compare(scoreOf(b.SeverityScore), scoreOf(a.SeverityScore)); c != 0 {
+				return c
+			}
+			if c := cmp.Compare(b.RiskScore, a.RiskScore); c != 0 {
+				return c
+			}
+			if c := strings.Compare(a.Package, b.Package); c != 0 {
+				return c
+			}
+			return strings.Compare(a.ID, b.ID)
+		})
+		groups = append(groups, SeverityGroup{Severity: rating, Count: len(findings), Findings: findings})
+	}
+	return groups
+}
+
+// scoreOf returns a finding's base score, or -1 when it has none so unscored findings sort last
+func scoreOf(score *float64) float64 {
+	if score == nil {
+		return -1
+	}
+	return *score
+}
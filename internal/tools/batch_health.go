@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rayprogramming/PackagePulse/internal/pool"
+	"go.uber.org/zap"
+)
+
+// batchHealthConcurrency bounds how many packages deps.batch_health looks up at once
+const batchHealthConcurrency = 8
+
+// BatchHealthInput defines input for deps.batch_health tool
+type BatchHealthInput struct {
+	Packages []VulnsInput `json:"packages"`
+}
+
+// HealthCounts aggregates health results by maintenance level.
+// Failed counts entries whose health could not be looked up.
+type HealthCounts struct {
+	Excellent int `json:"excellent"`
+	Good      int `json:"good"`
+	Fair      int `json:"fair"`
+	Poor      int `json:"poor"`
+	Critical  int `json:"critical"`
+	Unknown   int `json:"unknown"`
+	Failed    int `json:"failed"`
+}
+
+// add counts one result at the given maintenance level; packages too sparse to score count
+// as unknown
+func (c *HealthCounts) add(level string) {
+	switch level {
+	case "excellent":
+		c.Excellent++
+	case "good":
+		c.Good++
+	case "fair":
+		c.Fair++
+	case "poor":
+		c.Poor++
+	case "critical":
+		c.Critical++
+	default:
+		c.Unknown++
+	}
+}
+
+// BatchHealthOutput contains per-package health results and their aggregate
+type BatchHealthOutput struct {
+	PackageCount int                  `json:"package_count"`
+	ErrorCount   int                  `json:"error_count"`
+	Summary      HealthCounts         `json:"summary"`
+	Results      []*BatchHealthResult `json:"results"`
+}
+
+// BatchHealthResult is one entry of a batch health check, aligned to the input. An entry that
+// could not be looked up carries Error and echoes its Request instead of a result.
+type BatchHealthResult struct {
+	*HealthOutput
+	Request *VulnsInput `json:"request,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// HandleBatchHealth implements deps.batch_health tool: health metrics for each package, looked
+// up batchHealthConcurrency at a time through the same cache as deps.health. A package that
+// cannot be looked up only fails its own entry.
+// Example: {"packages": [{"ecosystem": "npm", "package": "express"}, {"ecosystem": "pypi", "package": "nose"}]}
+func (tr *ToolRegistry) HandleBatchHealth(ctx context.Context, input BatchHealthInput) (*BatchHealthOutput, error) {
+	if len(input.Packages) == 0 {
+		return nil, fmt.Errorf("%w: packages is required", errInvalidInput)
+	}
+
+	tr.log(ctx).Info("Handling batch health query", zap.Int("packages", len(input.Packages)))
+
+	healths, err := pool.Map(ctx, input.Packages, batchHealthConcurrency, func(ctx context.Context, pkg VulnsInput) (*HealthOutput, error) {
+		query, err := tr.resolveQuery(ctx, pkg.Ecosystem, pkg.Package, pkg.Version)
+		if err != nil {
+			return nil, err
+		}
+		metrics, err := tr.packageHealth(ctx, query.Ecosystem, query.Package, query.Version)
+		if err != nil {
+			return nil, err
+		}
+		return &HealthOutput{
+			HealthMetrics:         metrics,
+			SuggestedAlternatives: tr.suggestAlternatives(query.Ecosystem, query.Package, metrics.MaintenanceLevel),
+			ResolvedQuery:         query,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	output := &BatchHealthOutput{
+		PackageCount: len(input.Packages),
+		Results:      make([]*BatchHealthResult, len(input.Packages)),
+	}
+	for i, result := range healths {
+		if result.Err != nil {
+			tr.log(ctx).Warn("health lookup failed",
+				zap.String("package", input.Packages[i].Package),
+				zap.Error(result.Err))
+			request := input.Packages[i]
+			output.Results[i] = &BatchHealthResult{Request: &request, Error: result.Err.Error()}
+			output.ErrorCount++
+			output.Summary.Failed++
+			continue
+		}
+		output.Results[i] = &BatchHealthResult{HealthOutput: result.Value}
+		output.Summary.add(result.Value.MaintenanceLevel)
+	}
+	return output, nil
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+)
+
+func TestHandleBatchHealth(t *testing.T) {
+	now := time.Now()
+	mock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"npm/express": {
+			PackageKey: depsdev.PackageKey{System: "NPM", Name: "express"},
+			Versions: []depsdev.VersionInfo{
+				{VersionKey: depsdev.VersionKey{Version: "5.0.0"}, PublishedAt: now.Add(-10 * 24 * time.Hour), Licenses: []string{"MIT"}, IsDefault: true},
+			},
+			Links: []depsdev.Link{
+				{Label: "SOURCE_REPO", URL: "https://github.com/expressjs/express"},
+				{Label: "DOCUMENTATION", URL: "https://expressjs.com"},
+			},
+		},
+		// One unlicensed release from years ago
+		"npm/request": {
+			PackageKey: depsdev.PackageKey{System: "NPM", Name: "request"},
+			Versions: []depsdev.VersionInfo{
+				{VersionKey: depsdev.VersionKey{Version: "2.88.2"}, PublishedAt: now.Add(-2000 * 24 * time.Hour), IsDefault: true},
+			},
+		},
+	})
+	registry := newTestRegistry(t)
+	registry.depsDevClient = mock.client()
+
+	output, err := registry.HandleBatchHealth(context.Background(), BatchHealthInput{Packages: []VulnsInput{
+		{Ecosystem: "NPM", Package: "express"},
+		{Ecosystem: "npm", Package: "request"},
+		{Ecosystem: "npm", Package: "does-not-exist"},
+		{Ecosystem: "cobol", Package: "anything"},
+	}})
+	if err != nil {
+		t.Fatalf("HandleBatchHealth() error = %v", err)
+	}
+
+	if output.PackageCount != 4 || output.ErrorCount != 2 || len(output.Results) != 4 {
+		t.Fatalf("HandleBatchHealth() = %d packages, %d errors, %d results; want 4, 2, 4",
+			output.PackageCount, output.ErrorCount, len(output.Results))
+	}
+	want := HealthCounts{Excellent: 1, Critical: 1, Failed: 2}
+	if output.Summary != want {
+		t.Errorf("Summary = %+v, want %+v", output.Summary, want)
+	}
+
+	healthy, abandoned := output.Results[0], output.Results[1]
+	if healthy.HealthOutput == nil || healthy.MaintenanceLevel != "excellent" || healthy.ResolvedQuery.Ecosystem != "npm" {
+		t.Errorf("express result = %+v, want excellent health for npm", healthy)
+	}
+	if abandoned.HealthOutput == nil || abandoned.MaintenanceLevel != "critical" || len(abandoned.SuggestedAlternatives) == 0 {
+		t.Errorf("request result = %+v, want critical health with alternatives", abandoned)
+	}
+	for _, failed := range output.Results[2:] {
+		if failed.HealthOutput != nil || failed.Error == "" || failed.Request == nil {
+			t.Errorf("failed result = %+v, want an error echoing the request", failed)
+		}
+	}
+
+	// A second check of the same packages is served from the cache; writes land asynchronously
+	deadline := time.Now().Add(2 * time.Second)
+	for _, name := range []string{"express", "request"} {
+		for {
+			if _, ok := registry.cache.Get(cacheKey("health", "npm", name, "")); ok {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("health for %s was never cached", name)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	before := mock.requests.Load()
+	if _, err := registry.HandleBatchHealth(context.Background(), BatchHealthInput{Packages: []VulnsInput{
+		{Ecosystem: "npm", Package: "express"},
+		{Ecosystem: "npm", Package: "request"},
+	}}); err != nil {
+		t.Fatalf("HandleBatchHealth() error = %v", err)
+	}
+	if n := mock.requests.Load() - before; n != 0 {
+		t.Errorf("deps.dev requests = %d, want 0 (cache hits)", n)
+	}
+
+	if _, err := registry.HandleBatchHealth(context.Background(), BatchHealthInput{}); err == nil {
+		t.Error("HandleBatchHealth() without packages succeeded, want an error")
+	}
+}
@@ -0,0 +1,211 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"go.uber.org/zap"
+)
+
+func TestBatchVulns_PartialResults(t *testing.T) {
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/lodash@4.17.19": {{ID: "GHSA-35jh-r3h4-6jhm"}},
+		"PyPI/requests":      {},
+	})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	result, err := registry.HandleBatchVulns(context.Background(), BatchVulnsInput{Packages: []VulnsInput{
+		{Ecosystem: "npm", Package: "lodash", Version: "4.17.19"},
+		{Ecosystem: "cobol", Package: "payroll"},
+		{Ecosystem: "PyPI", Package: "requests"},
+	}})
+	if err != nil {
+		t.Fatalf("HandleBatchVulns() error = %v", err)
+	}
+
+	if len(result.Results) != 3 || result.ErrorCount != 1 {
+		t.Fatalf("got %d results with %d errors, want 3 aligned results and 1 error", len(result.Results), result.ErrorCount)
+	}
+	if r := result.Results[0]; r.VulnsOutput == nil || r.Package != "lodash" || r.VulnerabilityCount != 1 {
+		t.Errorf("results[0] = %+v, want lodash with one finding", r)
+	}
+	failed := result.Results[1]
+	if failed.VulnsOutput != nil || failed.Request == nil || failed.Request.Package != "payroll" ||
+		!strings.Contains(failed.Error, "unsupported ecosystem") {
+		t.Errorf("results[1] = %+v, want an error echoing the payroll request", failed)
+	}
+	if r := result.Results[2]; r.VulnsOutput == nil || r.Package != "requests" || r.VulnerabilityCount != 0 {
+		t.Errorf("results[2] = %+v, want a clean requests result", r)
+	}
+	if result.VulnerabilityCount != 1 || !result.DataComplete {
+		t.Errorf("output = %+v, want one finding from complete data", result)
+	}
+
+	// A failed entry serializes as its request and error only
+	data, err := json.Marshal(failed)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if strings.Contains(string(data), "vulnerability_count") {
+		t.Errorf("failed entry JSON = %s, want no result fields", data)
+	}
+}
+
+func TestBatchVulns_OSVFailureIsPerEntry(t *testing.T) {
+	mock := newMockOSV(t, nil)
+	mock.Close()
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	result, err := registry.HandleBatchVulns(context.Background(), BatchVulnsInput{Packages: []VulnsInput{
+		{Ecosystem: "npm", Package: "lodash"},
+		{Ecosystem: "npm", Package: "express"},
+	}})
+	if err != nil {
+		t.Fatalf("HandleBatchVulns() error = %v", err)
+	}
+	if result.ErrorCount != 2 || result.DataComplete {
+		t.Errorf("output = %+v, want both entries failed and data incomplete", result)
+	}
+	for i, r := range result.Results {
+		if !strings.Contains(r.Error, "batch query OSV") {
+			t.Errorf("results[%d].Error = %q, want the OSV failure", i, r.Error)
+		}
+	}
+}
+
+func TestBatchVulns_HydratesDetails(t *testing.T) {
+	advisory := osv.Vulnerability{
+		ID:       "GHSA-35jh-r3h4-6jhm",
+		Summary:  "Command injection in lodash",
+		Severity: []osv.Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H"}},
+	}
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/lodash@4.17.19": {advisory},
+		"npm/lodash@4.17.20": {advisory},
+	})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	result, err := registry.HandleBatchVulns(context.Background(), BatchVulnsInput{Packages: []VulnsInput{
+		{Ecosystem: "npm", Package: "lodash", Version: "4.17.19"},
+		{Ecosystem: "npm", Package: "lodash", Version: "4.17.20"},
+	}})
+	if err != nil {
+		t.Fatalf("HandleBatchVulns() error = %v", err)
+	}
+
+	for i, r := range result.Results {
+		if r.VulnsOutput == nil || len(r.Vulnerabilities) != 1 {
+			t.Fatalf("results[%d] = %+v, want one finding", i, r)
+		}
+		if f := r.Vulnerabilities[0]; f.Summary != advisory.Summary || len(f.Severity) != 1 {
+			t.Errorf("results[%d] finding = %+v, want the hydrated summary and severity", i, f)
+		}
+	}
+	if got := mock.details.Load(); got != 1 {
+		t.Errorf("advisory fetches = %d, want 1 for a shared ID", got)
+	}
+}
+
+func TestBatchVulns_GroupsBySeverity(t *testing.T) {
+	critical := []osv.Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}
+	high := []osv.Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H"}}
+	medium := []osv.Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:U/C:L/I:L/A:N"}}
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/lodash@4.17.19": {
+			{ID: "GHSA-lodash-medium", Severity: medium},
+			{ID: "GHSA-lodash-high", Severity: high},
+		},
+		"PyPI/requests@2.19.0": {
+			{ID: "GHSA-requests-none"},
+			{ID: "GHSA-requests-critical", Severity: critical},
+		},
+	})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	result, err := registry.HandleBatchVulns(context.Background(), BatchVulnsInput{Packages: []VulnsInput{
+		{Ecosystem: "npm", Package: "lodash", Version: "4.17.19"},
+		{Ecosystem: "PyPI", Package: "requests", Version: "2.19.0"},
+	}})
+	if err != nil {
+		t.Fatalf("HandleBatchVulns() error = %v", err)
+	}
+
+	type entry struct{ severity, id, pkg string }
+	want := []entry{
+		{"critical", "GHSA-requests-critical", "requests"},
+		{"high", "GHSA-lodash-high", "lodash"},
+		{"medium", "GHSA-lodash-medium", "lodash"},
+		{"unknown", "GHSA-requests-none", "requests"},
+	}
+	var got []entry
+	for _, group := range result.BySeverity {
+		if group.Count != len(group.Findings) {
+			t.Errorf("group %s count = %d, want %d", group.Severity, group.Count, len(group.Findings))
+		}
+		for _, f := range group.Findings {
+			got = append(got, entry{group.Severity, f.ID, f.Package})
+		}
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("by_severity = %v, want %v", got, want)
+	}
+
+	// The per-package results are unchanged
+	if len(result.Results) != 2 || len(result.Results[0].Vulnerabilities) != 2 || len(result.Results[1].Vulnerabilities) != 2 {
+		t.Errorf("results = %+v, want two findings under each package", result.Results)
+	}
+}
+
+// failingChunk fails any OSV batch request that mentions the broken package
+type failingChunk struct{}
+
+func (failingChunk) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.Body != nil {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Contains(body, []byte(`"broken"`)) {
+			return nil, errors.New("connection reset")
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestBatchVulns_EntrySourcesExcludeOtherChunks(t *testing.T) {
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{"npm/pkg-0": {{ID: "GHSA-35jh-r3h4-6jhm"}}})
+	registry := newTestRegistry(t)
+	registry.osvClient = osv.NewClient(zap.NewNop(), osv.WithBaseURL(mock.URL), osv.WithTransport(failingChunk{}))
+
+	// The first chunk is full, so broken lands alone in a second chunk
+	var packages []VulnsInput
+	for i := range batchChunkSize {
+		packages = append(packages, VulnsInput{Ecosystem: "npm", Package: fmt.Sprintf("pkg-%d", i)})
+	}
+	packages = append(packages, VulnsInput{Ecosystem: "npm", Package: "broken"})
+
+	result, err := registry.HandleBatchVulns(context.Background(), BatchVulnsInput{Packages: packages})
+	if err != nil {
+		t.Fatalf("HandleBatchVulns() error = %v", err)
+	}
+	if result.ErrorCount != 1 || result.DataComplete || !slices.Contains(result.SourcesFailed, SourceOSV) {
+		t.Errorf("output = %+v, want one failed entry and osv failed on the envelope", result.DataSources)
+	}
+	if r := result.Results[0]; r.VulnsOutput == nil || !r.VulnsOutput.DataComplete || len(r.VulnsOutput.SourcesFailed) != 0 {
+		t.Errorf("results[0] = %+v, want complete data from its own chunk", r)
+	}
+}
@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"math"
+	"time"
+)
+
+// CacheStatus reports whether a response was served from the response cache and how long ago
+// it was computed, so a client can decide whether to force a refresh. Both fields are omitted
+// for a freshly computed response.
+type CacheStatus struct {
+	Cached          bool    `json:"cached,omitempty"`
+	CacheAgeSeconds float64 `json:"cache_age_seconds,omitempty"`
+}
+
+// cachedResponse is what the response cache stores: a tool result and when it was computed.
+// Lookup caches such as EPSS scores and canonical names store bare values.
+type cachedResponse struct {
+	value    any
+	storedAt time.Time
+}
+
+// getCachedResponse returns the response cached under key and its CacheStatus
+func (tr *ToolRegistry) getCachedResponse(key string) (any, CacheStatus, bool) {
+	if tr.cache == nil {
+		return nil, CacheStatus{}, false
+	}
+	cached, found := tr.cache.Get(key)
+	if !found {
+		return nil, CacheStatus{}, false
+	}
+	entry, ok := cached.(cachedResponse)
+	if !ok {
+		return nil, CacheStatus{}, false
+	}
+	age := time.Since(entry.storedAt).Seconds()
+	return entry.value, CacheStatus{Cached: true, CacheAgeSeconds: math.Round(age*1000) / 1000}, true
+}
+
+// setCachedResponse caches a tool response under key, stamped with the current time
+func (tr *ToolRegistry) setCachedResponse(key string, value any, ttl time.Duration) {
+	if tr.cache == nil {
+		return
+	}
+	tr.cache.Set(key, cachedResponse{value: value, storedAt: time.Now()}, ttl)
+}
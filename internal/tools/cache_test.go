@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+)
+
+func TestHandleVulns_ReportsCacheAge(t *testing.T) {
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/lodash@4.17.19": {{ID: "GHSA-35jh-r3h4-6jhm"}},
+	})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+	input := VulnsInput{Ecosystem: "npm", Package: "lodash", Version: "4.17.19"}
+
+	first, err := registry.HandleVulns(context.Background(), input)
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+	if first.Cached || first.CacheAgeSeconds != 0 {
+		t.Errorf("first call cache status = %+v, want a fresh result", first.CacheStatus)
+	}
+	data, _ := json.Marshal(first)
+	if strings.Contains(string(data), `"cached"`) || strings.Contains(string(data), "cache_age_seconds") {
+		t.Errorf("fresh result JSON = %s, want no cache fields", data)
+	}
+
+	// Cache writes land asynchronously
+	var second *VulnsOutput
+	for deadline := time.Now().Add(time.Second); ; {
+		time.Sleep(10 * time.Millisecond)
+		if second, err = registry.HandleVulns(context.Background(), input); err != nil {
+			t.Fatalf("HandleVulns() error = %v", err)
+		}
+		if second.Cached || time.Now().After(deadline) {
+			break
+		}
+	}
+	if !second.Cached || second.CacheAgeSeconds <= 0 || second.CacheAgeSeconds > 5 {
+		t.Errorf("second call cache status = %+v, want cached with a small positive age", second.CacheStatus)
+	}
+	// The cached result still echoes the query it answers
+	if want := (ResolvedQuery{Ecosystem: "npm", Package: "lodash", Version: "4.17.19"}); second.ResolvedQuery == nil || *second.ResolvedQuery != want {
+		t.Errorf("second call resolved_query = %+v, want %+v", second.ResolvedQuery, want)
+	}
+}
@@ -0,0 +1,119 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"github.com/rayprogramming/hypermcp"
+	"go.uber.org/zap"
+)
+
+// TestConcurrentSessions drives the core tools from many HTTP client sessions at once against
+// one registry, so the shared cache, in-flight lookups, stats, and provider clients are all hit
+// concurrently. Run it with -race.
+func TestConcurrentSessions(t *testing.T) {
+	now := time.Now()
+	packages := map[string]*depsdev.PackageInfo{}
+	vulns := map[string][]osv.Vulnerability{}
+	names := []string{"lodash", "express", "left-pad"}
+	for _, name := range names {
+		packages["npm/"+name] = &depsdev.PackageInfo{
+			PackageKey: depsdev.PackageKey{System: "NPM", Name: name},
+			Versions: []depsdev.VersionInfo{
+				{VersionKey: depsdev.VersionKey{Version: "1.0.0"}, PublishedAt: now.Add(-400 * 24 * time.Hour), Licenses: []string{"MIT"}},
+				{VersionKey: depsdev.VersionKey{Version: "2.0.0"}, PublishedAt: now.Add(-10 * 24 * time.Hour), Licenses: []string{"MIT"}, IsDefault: true},
+			},
+		}
+		vulns["npm/"+name] = []osv.Vulnerability{
+			rangedVuln("GHSA-"+name, name, osv.Event{Introduced: "0"}, osv.Event{Fixed: "2.0.0"}),
+		}
+	}
+	osvMock := newMockOSV(t, vulns)
+	osvMock.delay = time.Millisecond
+	registry := newTestRegistry(t)
+	registry.osvClient = osvMock.client()
+	registry.depsDevClient = newMockDepsDev(t, packages).client()
+
+	srv, err := hypermcp.New(hypermcp.Config{Name: "test", Version: "1.0.0"}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	if err := registry.Register(srv); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	httpSrv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return srv.MCP() }, nil))
+	t.Cleanup(httpSrv.Close)
+
+	calls := []func(name string) *mcp.CallToolParams{
+		func(name string) *mcp.CallToolParams {
+			return &mcp.CallToolParams{Name: "deps.vulns", Arguments: map[string]any{"ecosystem": "npm", "package": name, "version": "1.0.0"}}
+		},
+		func(name string) *mcp.CallToolParams {
+			return &mcp.CallToolParams{Name: "deps.health", Arguments: map[string]any{"ecosystem": "npm", "package": name}}
+		},
+		func(string) *mcp.CallToolParams {
+			return &mcp.CallToolParams{Name: "license.info", Arguments: map[string]any{"license_id": "MIT"}}
+		},
+		func(name string) *mcp.CallToolParams {
+			return &mcp.CallToolParams{Name: "deps.upgrade_plan", Arguments: map[string]any{"ecosystem": "npm", "package": name, "current_version": "1.0.0"}}
+		},
+	}
+
+	const sessions = 8
+	const rounds = 3
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	errs := make(chan error, sessions*rounds*len(names)*len(calls))
+	for s := 0; s < sessions; s++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client := mcp.NewClient(&mcp.Implementation{Name: fmt.Sprintf("client-%d", s), Version: "1.0.0"}, nil)
+			session, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: httpSrv.URL}, nil)
+			if err != nil {
+				errs <- fmt.Errorf("session %d: Connect() error = %w", s, err)
+				return
+			}
+			defer func() { _ = session.Close() }()
+
+			// Each session calls the tools concurrently too, as a client pipelining requests would
+			var calling sync.WaitGroup
+			for r := 0; r < rounds; r++ {
+				for _, name := range names {
+					for _, call := range calls {
+						calling.Add(1)
+						go func() {
+							defer calling.Done()
+							params := call(name)
+							res, err := session.CallTool(ctx, params)
+							if err != nil {
+								errs <- fmt.Errorf("session %d: CallTool(%s) error = %w", s, params.Name, err)
+							} else if res.IsError {
+								errs <- fmt.Errorf("session %d: CallTool(%s) failed: %v", s, params.Name, res.Content)
+							}
+						}()
+					}
+				}
+			}
+			calling.Wait()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	// Every call but license.info names an ecosystem and is counted under it
+	if got, want := registry.Stats().Snapshot().Ecosystems["npm"].Successes, int64(sessions*rounds*len(names)*(len(calls)-1)); got != want {
+		t.Errorf("npm successes = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,18 @@
+package tools
+
+import (
+	"context"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// withDeadline bounds a tool handler with a deadline. Provider HTTP clients carry
+// no timeout of their own, so this context is what cuts off slow upstream calls.
+func withDeadline(timeout time.Duration, handler mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
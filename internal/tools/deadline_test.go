@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"go.uber.org/zap"
+)
+
+func TestWithDeadline_CutsOffSlowUpstream(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	registry := newTestRegistry(t)
+	registry.osvClient = osv.NewClient(zap.NewNop(), osv.WithBaseURL(server.URL))
+
+	handler := withDeadline(50*time.Millisecond, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		_, err := registry.HandleVulns(ctx, VulnsInput{Ecosystem: "npm", Package: "lodash", Version: "4.17.19"})
+		if err != nil {
+			return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}}}, nil
+		}
+		return &mcp.CallToolResult{}, nil
+	})
+
+	start := time.Now()
+	result, err := handler(context.Background(), &mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("handler took %v, deadline should have cut off the upstream request", elapsed)
+	}
+	if !result.IsError || !strings.Contains(resultText(t, result), context.DeadlineExceeded.Error()) {
+		t.Errorf("expected deadline error result, got %+v", result)
+	}
+}
+
+func TestConfigValidate_Timeouts(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Timeout = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for zero timeout")
+	}
+}
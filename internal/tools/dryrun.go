@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"net/http"
+
+	"github.com/rayprogramming/PackagePulse/internal/manifest"
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"github.com/rayprogramming/PackagePulse/internal/providers/packagist"
+)
+
+// PlannedCall is one kind of upstream request a tool would make. Count is nil when the
+// number of requests depends on what earlier responses return.
+type PlannedCall struct {
+	Upstream string `json:"upstream"`
+	Method   string `json:"method,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"`
+	Count    *int   `json:"count,omitempty"`
+	Packages int    `json:"packages,omitempty"`
+	Note     string `json:"note,omitempty"`
+}
+
+// DryRunPlan is the work plan a dry run reports instead of executing it
+type DryRunPlan struct {
+	PackageCount int `json:"package_count"`
+	InvalidCount int `json:"invalid_count"`
+	BatchCount   int `json:"batch_count"`
+	// KnownRequestCount sums the calls whose count is known up front
+	KnownRequestCount int           `json:"known_request_count"`
+	Calls             []PlannedCall `json:"calls"`
+}
+
+// add appends a call to the plan, counting it toward KnownRequestCount when its count is known
+func (p *DryRunPlan) add(call PlannedCall) {
+	if call.Count != nil {
+		p.KnownRequestCount += *call.Count
+	}
+	p.Calls = append(p.Calls, call)
+}
+
+// planBatchVulns describes the requests a batch scan of the valid queries would make.
+// NuGet names count toward a canonical-name lookup unless one is already cached.
+func (tr *ToolRegistry) planBatchVulns(queries []osv.QueryRequest, invalid int) *DryRunPlan {
+	batches := (len(queries) + batchChunkSize - 1) / batchChunkSize
+	plan := &DryRunPlan{
+		PackageCount: len(queries) + invalid,
+		InvalidCount: invalid,
+		BatchCount:   batches,
+		Calls:        []PlannedCall{},
+	}
+	if len(queries) == 0 {
+		return plan
+	}
+
+	canonical := 0
+	for _, q := range queries {
+		if q.Package.Ecosystem != osv.EcosystemNuGet {
+			continue
+		}
+		if _, ok := tr.cache.Get(canonicalNameKey(q.Package.Ecosystem, q.Package.Name)); !ok {
+			canonical++
+		}
+	}
+	if canonical > 0 {
+		plan.add(PlannedCall{
+			Upstream: "deps.dev",
+			Method:   http.MethodGet,
+			Endpoint: tr.depsDevClient.BaseURL() + "/systems/" + depsdev.System(osv.EcosystemNuGet) + "/packages/{name}",
+			Count:    &canonical,
+			Packages: canonical,
+			Note:     "resolves canonical NuGet package names",
+		})
+	}
+
+	plan.add(PlannedCall{
+		Upstream: SourceOSV,
+		Method:   http.MethodPost,
+		Endpoint: tr.osvClient.BaseURL() + osv.BatchPath,
+		Count:    &batches,
+		Packages: len(queries),
+		Note:     "up to 100 packages per request",
+	})
+	plan.add(PlannedCall{
+		Upstream: SourceOSV,
+		Method:   http.MethodGet,
+		Endpoint: tr.osvClient.BaseURL() + osv.VulnsPath + "{id}",
+		Note:     "one per distinct advisory the batch queries return",
+	})
+	plan.add(PlannedCall{
+		Upstream: SourceEPSS,
+		Method:   http.MethodGet,
+		Note:     "scores uncached CVE aliases of the findings, if any",
+	})
+	plan.add(PlannedCall{
+		Upstream: SourceKEV,
+		Method:   http.MethodGet,
+		Note:     "downloads the catalog only when it is stale",
+	})
+	return plan
+}
+
+// planLicenseAudit describes the package lookups a license audit of the distinct
+// dependencies would make
+func (tr *ToolRegistry) planLicenseAudit(deps []manifest.Dependency) *DryRunPlan {
+	plan := &DryRunPlan{PackageCount: len(deps), Calls: []PlannedCall{}}
+
+	var depsDev, composer int
+	for _, dep := range deps {
+		if dep.Ecosystem == manifest.EcosystemPackagist {
+			composer++
+		} else {
+			depsDev++
+		}
+	}
+	if depsDev > 0 {
+		plan.add(PlannedCall{
+			Upstream: "deps.dev",
+			Method:   http.MethodGet,
+			Endpoint: tr.depsDevClient.BaseURL() + "/systems/{system}/packages/{name}",
+			Count:    &depsDev,
+			Packages: depsDev,
+			Note:     "one per distinct package; cached packages are skipped",
+		})
+	}
+	if composer > 0 {
+		plan.add(PlannedCall{
+			Upstream: "packagist",
+			Method:   http.MethodGet,
+			Endpoint: tr.packagistClient.BaseURL() + packagist.PackagePath + "{name}.json",
+			Count:    &composer,
+			Packages: composer,
+			Note:     "one per distinct package; cached packages are skipped",
+		})
+	}
+	return plan
+}
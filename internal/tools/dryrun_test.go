@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+)
+
+// cargoLock builds a Cargo.lock with n registry crates
+func cargoLock(n int) string {
+	var b strings.Builder
+	b.WriteString("version = 3\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "\n[[package]]\nname = \"crate-%d\"\nversion = \"1.0.%d\"\nsource = \"registry+https://github.com/rust-lang/crates.io-index\"\n", i, i)
+	}
+	return b.String()
+}
+
+func TestDryRun(t *testing.T) {
+	osvMock := newMockOSV(t, map[string][]osv.Vulnerability{})
+	depsDevMock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{})
+
+	registry := newTestRegistry(t)
+	registry.osvClient = osvMock.client()
+	registry.depsDevClient = depsDevMock.client()
+
+	t.Run("scan manifest", func(t *testing.T) {
+		result, err := registry.HandleScanManifest(context.Background(), ScanManifestInput{
+			Filename: "Cargo.lock",
+			Content:  cargoLock(30),
+			DryRun:   true,
+		})
+		if err != nil {
+			t.Fatalf("HandleScanManifest() error = %v", err)
+		}
+		if result.Plan == nil {
+			t.Fatal("Plan = nil, want a dry-run plan")
+		}
+		if result.Plan.PackageCount != 30 {
+			t.Errorf("PackageCount = %d, want 30", result.Plan.PackageCount)
+		}
+		if result.Plan.BatchCount != 1 {
+			t.Errorf("BatchCount = %d, want 1", result.Plan.BatchCount)
+		}
+		if result.Plan.KnownRequestCount != 1 {
+			t.Errorf("KnownRequestCount = %d, want 1", result.Plan.KnownRequestCount)
+		}
+		if len(result.Results) != 0 {
+			t.Errorf("got %d results, want none on a dry run", len(result.Results))
+		}
+	})
+
+	t.Run("batch spans several chunks", func(t *testing.T) {
+		packages := make([]VulnsInput, 250)
+		for i := range packages {
+			packages[i] = VulnsInput{Ecosystem: "npm", Package: fmt.Sprintf("pkg-%d", i), Version: "1.0.0"}
+		}
+		packages[7] = VulnsInput{Ecosystem: "npm"}
+
+		result, err := registry.HandleBatchVulns(context.Background(), BatchVulnsInput{Packages: packages, DryRun: true})
+		if err != nil {
+			t.Fatalf("HandleBatchVulns() error = %v", err)
+		}
+		if result.Plan.BatchCount != 3 {
+			t.Errorf("BatchCount = %d, want 3", result.Plan.BatchCount)
+		}
+		if result.Plan.InvalidCount != 1 || len(result.Results) != 1 {
+			t.Errorf("InvalidCount = %d with %d results, want the 1 invalid entry", result.Plan.InvalidCount, len(result.Results))
+		}
+	})
+
+	t.Run("license audit", func(t *testing.T) {
+		result, err := registry.HandleLicenseAudit(context.Background(), LicenseAuditInput{
+			Filename: "Cargo.lock",
+			Content:  cargoLock(30),
+			DryRun:   true,
+		})
+		if err != nil {
+			t.Fatalf("HandleLicenseAudit() error = %v", err)
+		}
+		if result.Plan == nil || result.Plan.KnownRequestCount != 30 {
+			t.Errorf("Plan = %+v, want 30 planned package lookups", result.Plan)
+		}
+	})
+
+	if n := osvMock.requests.Load(); n != 0 {
+		t.Errorf("OSV requests = %d, want 0", n)
+	}
+	if n := depsDevMock.requests.Load(); n != 0 {
+		t.Errorf("deps.dev requests = %d, want 0", n)
+	}
+}
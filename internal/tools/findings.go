@@ -0,0 +1,455 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/pool"
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/epss"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"go.uber.org/zap"
+)
+
+// Supported values for the sort_by tool option
+const (
+	SortBySeverity  = "severity"
+	SortByPublished = "published"
+	SortByModified  = "modified"
+	SortByID        = "id"
+	SortByEPSS      = "epss"
+	SortByRisk      = "risk"
+)
+
+// sortOrders lists the sort_by values in the order they are documented
+var sortOrders = []string{SortBySeverity, SortByPublished, SortByModified, SortByID, SortByEPSS, SortByRisk}
+
+// epssCacheTTL matches the daily EPSS publication cadence
+const epssCacheTTL = 24 * time.Hour
+
+// depsDevAdvisoryCacheTTL keeps deps.dev advisories, and their absence, for a day
+const depsDevAdvisoryCacheTTL = 24 * time.Hour
+
+// depsDevAdvisoryConcurrency bounds how many deps.dev advisories one scan looks up at once
+const depsDevAdvisoryConcurrency = 8
+
+// DepsDevAdvisory is deps.dev's record of a finding's advisory
+type DepsDevAdvisory struct {
+	Title       string   `json:"title,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"`
+	CVSS3Score  *float64 `json:"cvss3_score,omitempty"`
+	CVSS3Vector string   `json:"cvss3_vector,omitempty"`
+}
+
+// Finding is an OSV vulnerability enriched with PackagePulse-derived fields
+type Finding struct {
+	osv.Vulnerability
+	Database        string   `json:"database,omitempty"`
+	Source          string   `json:"source,omitempty"`
+	ReportedBy      []string `json:"reported_by"`
+	AdvisoryURL     string   `json:"advisory_url,omitempty"`
+	FixURL          string   `json:"fix_url,omitempty"`
+	EPSSProbability *float64 `json:"epss_probability,omitempty"`
+	EPSSPercentile  *float64 `json:"epss_percentile,omitempty"`
+	KnownExploited  bool     `json:"known_exploited,omitempty"`
+	RiskScore       float64  `json:"risk_score"`
+	// SeverityVector and SeverityScore are the CVSS vector the finding is rated by and its base
+	// score, chosen across all severity entries preferring CVSS v3.1, then v3.0, then v2
+	SeverityVector string   `json:"severity_vector,omitempty"`
+	SeverityScore  *float64 `json:"severity_score,omitempty"`
+	// AffectedStatus is set when the version was matched locally rather than by the source,
+	// e.g. for a commit-pinned version, and is "possibly_affected" when it could not be placed
+	AffectedStatus string             `json:"affected_status,omitempty"`
+	GitRanges      []osv.VersionRange `json:"git_ranges,omitempty"`
+	// CWEIDs are the advisory's weakness classifications, e.g. "CWE-79" for XSS
+	CWEIDs []string `json:"cwe_ids,omitempty"`
+	// AffectedPackages lists every package the advisory affects, across ecosystems
+	AffectedPackages []osv.Package `json:"affected_packages,omitempty"`
+	// AffectedVersions restates the queried package's affected ranges as constraints, e.g.
+	// ">=4.0.0, <4.17.21", and FixedIn lists the releases that fix them, lowest first
+	AffectedVersions string   `json:"affected_versions,omitempty"`
+	FixedIn          []string `json:"fixed_in,omitempty"`
+	// DepsDev is deps.dev's copy of the advisory, when it carries one with the same ID
+	DepsDev *DepsDevAdvisory `json:"depsdev_advisory,omitempty"`
+	// DaysSincePublished and DaysSinceModified are the advisory's age and the whole days since
+	// it last changed, e.g. a severity upgrade; each is omitted when the source gave no timestamp
+	DaysSincePublished *int `json:"days_since_published,omitempty"`
+	DaysSinceModified  *int `json:"days_since_modified,omitempty"`
+	// SeverityInheritedFrom names the upstream or related entry the severity was taken from,
+	// when follow_related was set and the finding had no usable severity of its own
+	SeverityInheritedFrom string `json:"severity_inherited_from,omitempty"`
+}
+
+// newFindings wraps raw OSV vulnerabilities for enrichment, recording which database each
+// entry came from and promoting the first ADVISORY and FIX references so clients can
+// deep-link without scanning the list
+func newFindings(vulns []osv.Vulnerability) []Finding {
+	findings := make([]Finding, len(vulns))
+	for i, v := range vulns {
+		refs := osv.GroupReferences(v.References)
+		findings[i] = Finding{
+			Vulnerability:    v,
+			Database:         v.Database(),
+			Source:           v.Source(),
+			ReportedBy:       []string{SourceOSV},
+			AdvisoryURL:      firstURL(refs[osv.ReferenceAdvisory]),
+			FixURL:           firstURL(refs[osv.ReferenceFix]),
+			GitRanges:        osv.GitRanges(v, ""),
+			CWEIDs:           v.CWEIDs(),
+			AffectedPackages: v.AffectedPackages(),
+		}
+	}
+	return findings
+}
+
+// setAffectedVersions fills each finding's AffectedVersions and FixedIn for pkg
+func setAffectedVersions(findings []Finding, pkg string, scheme depsdev.VersionScheme) {
+	for i := range findings {
+		f := &findings[i]
+		f.AffectedVersions = osv.AffectedVersions(f.Vulnerability, pkg, scheme.Compare)
+		f.FixedIn = osv.FixedVersions(f.Vulnerability, pkg, scheme.Compare)
+	}
+}
+
+// setAdvisoryAges fills each finding's DaysSincePublished and DaysSinceModified as of now
+func setAdvisoryAges(findings []Finding, now time.Time) {
+	for i := range findings {
+		f := &findings[i]
+		f.DaysSincePublished = daysSince(f.Published, now)
+		f.DaysSinceModified = daysSince(f.Modified, now)
+	}
+}
+
+// daysSince returns the whole days from t to now, or nil for a missing timestamp.
+// A timestamp in the future, from clock skew, counts as zero days.
+func daysSince(t, now time.Time) *int {
+	if t.IsZero() {
+		return nil
+	}
+	days := max(int(now.Sub(t)/(24*time.Hour)), 0)
+	return &days
+}
+
+// changedWithin keeps the findings published or modified in the days before now. Findings
+// with neither timestamp are dropped, since they cannot be shown to be recent.
+func changedWithin(findings []Finding, days int, now time.Time) []Finding {
+	cutoff := now.Add(-time.Duration(days) * 24 * time.Hour)
+	kept := []Finding{}
+	for _, f := range findings {
+		if f.Published.After(cutoff) || f.Modified.After(cutoff) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// matchCommit keeps the findings that affect, or may affect, a commit-pinned version of pkg
+// and records each one's AffectedStatus
+func matchCommit(findings []Finding, pkg, version string, scheme depsdev.VersionScheme) []Finding {
+	kept := []Finding{}
+	for _, f := range findings {
+		status := osv.IsVersionAffected(f.Vulnerability, pkg, version, scheme.Compare)
+		if status == osv.StatusNotAffected {
+			continue
+		}
+		f.AffectedStatus = status
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// matchVersions keeps the findings that affect, or may affect, at least one of the versions
+func matchVersions(findings []Finding, pkg string, versions []string, scheme depsdev.VersionScheme) []Finding {
+	kept := []Finding{}
+	for _, f := range findings {
+		for _, version := range versions {
+			if osv.IsVersionAffected(f.Vulnerability, pkg, version, scheme.Compare) != osv.StatusNotAffected {
+				kept = append(kept, f)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// versionMatrix attributes findings to each version, listing IDs in the findings' order
+func versionMatrix(findings []Finding, pkg string, versions []string, scheme depsdev.VersionScheme) map[string]*VersionResult {
+	matrix := make(map[string]*VersionResult, len(versions))
+	for _, version := range versions {
+		result := &VersionResult{IDs: []string{}}
+		for _, f := range findings {
+			switch osv.IsVersionAffected(f.Vulnerability, pkg, version, scheme.Compare) {
+			case osv.StatusAffected:
+				result.IDs = append(result.IDs, f.ID)
+			case osv.StatusPossiblyAffected:
+				result.PossiblyAffected = append(result.PossiblyAffected, f.ID)
+			}
+		}
+		result.VulnerabilityCount = len(result.IDs)
+		matrix[version] = result
+	}
+	return matrix
+}
+
+func firstURL(urls []string) string {
+	if len(urls) == 0 {
+		return ""
+	}
+	return urls[0]
+}
+
+// cveID returns the CVE identifier for a vulnerability, from its ID or aliases
+func cveID(vuln osv.Vulnerability) string {
+	if strings.HasPrefix(strings.ToUpper(vuln.ID), "CVE-") {
+		return strings.ToUpper(vuln.ID)
+	}
+	for _, alias := range vuln.Aliases {
+		if strings.HasPrefix(strings.ToUpper(alias), "CVE-") {
+			return strings.ToUpper(alias)
+		}
+	}
+	return ""
+}
+
+// validateSortBy checks that the requested sort order is supported
+func validateSortBy(sortBy string) error {
+	if sortBy == "" {
+		return nil
+	}
+	for _, order := range sortOrders {
+		if sortBy == order {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported sort_by %q (valid: %s)", sortBy, strings.Join(sortOrders, ", "))
+}
+
+// sortFindings orders findings in place by the requested key, highest or newest first
+// (IDs ascend). Ties, and the default order, fall back to severity descending, then
+// published date descending, then ID, so output is deterministic whatever order OSV used.
+// Findings without a severity or EPSS score sort after those with one.
+func sortFindings(findings []Finding, sortBy string) {
+	var primary func(a, b *Finding) int
+	switch sortBy {
+	case SortByPublished:
+		primary = comparePublished
+	case SortByModified:
+		primary = func(a, b *Finding) int { return b.Modified.Compare(a.Modified) }
+	case SortByID:
+		primary = compareID
+	case SortByEPSS:
+		primary = func(a, b *Finding) int { return cmp.Compare(epssValue(*b), epssValue(*a)) }
+	case SortByRisk:
+		primary = func(a, b *Finding) int { return cmp.Compare(b.RiskScore, a.RiskScore) }
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := &findings[i], &findings[j]
+		if primary != nil {
+			if c := primary(a, b); c != 0 {
+				return c < 0
+			}
+		}
+		if c := cmp.Compare(severityValue(*b), severityValue(*a)); c != 0 {
+			return c < 0
+		}
+		if c := comparePublished(a, b); c != 0 {
+			return c < 0
+		}
+		return compareID(a, b) < 0
+	})
+}
+
+func severityValue(f Finding) float64 {
+	if score, ok := baseScore(f.Vulnerability); ok {
+		return score
+	}
+	return -1
+}
+
+func comparePublished(a, b *Finding) int {
+	return b.Published.Compare(a.Published)
+}
+
+func compareID(a, b *Finding) int {
+	return strings.Compare(a.ID, b.ID)
+}
+
+func epssValue(f Finding) float64 {
+	if f.EPSSProbability == nil {
+		return -1
+	}
+	return *f.EPSSProbability
+}
+
+// enrichEPSS attaches EPSS scores to findings with a CVE alias.
+// Scores are cached for a day; lookup failures are logged, leave findings unscored,
+// and are returned so callers can flag the result as incomplete.
+func (tr *ToolRegistry) enrichEPSS(ctx context.Context, findings []Finding) error {
+	scores := make(map[string]epss.Score)
+
+	var missing []string
+	for _, f := range findings {
+		cve := cveID(f.Vulnerability)
+		if cve == "" {
+			continue
+		}
+		if _, ok := scores[cve]; ok {
+			continue
+		}
+		if tr.cache != nil {
+			if cached, found := tr.cache.Get("epss:" + cve); found {
+				if score, ok := cached.(epss.Score); ok {
+					scores[cve] = score
+					continue
+				}
+			}
+		}
+		missing = append(missing, cve)
+		scores[cve] = epss.Score{CVE: cve, Probability: -1}
+	}
+
+	var fetchErr error
+	if len(missing) > 0 {
+		fetched, err := tr.epssClient.GetScores(ctx, missing)
+		if err != nil {
+			tr.log(ctx).Warn("Failed to fetch EPSS scores", zap.Error(err))
+			fetchErr = err
+		}
+		for _, cve := range missing {
+			score, ok := fetched[cve]
+			if !ok {
+				delete(scores, cve)
+				continue
+			}
+			scores[cve] = score
+			if tr.cache != nil {
+				tr.cache.Set("epss:"+cve, score, epssCacheTTL)
+			}
+		}
+	}
+
+	for i := range findings {
+		score, ok := scores[cveID(findings[i].Vulnerability)]
+		if !ok || score.Probability < 0 {
+			continue
+		}
+		probability, percentile := score.Probability, score.Percentile
+		findings[i].EPSSProbability = &probability
+		findings[i].EPSSPercentile = &percentile
+	}
+	return fetchErr
+}
+
+// enrichDepsDevAdvisories attaches deps.dev's advisory to each finding deps.dev has under the
+// same ID, filling a missing summary from its title and a missing severity from its CVSS v3
+// vector. Advisories deps.dev does not carry are skipped; other lookup failures are logged,
+// leave the finding as it was, and are returned so callers can flag the result as incomplete.
+func (tr *ToolRegistry) enrichDepsDevAdvisories(ctx context.Context, findings []Finding) error {
+	ids := make([]string, 0, len(findings))
+	seen := make(map[string]bool)
+	for _, f := range findings {
+		if !seen[f.ID] {
+			seen[f.ID] = true
+			ids = append(ids, f.ID)
+		}
+	}
+
+	results, err := pool.Map(ctx, ids, depsDevAdvisoryConcurrency, tr.depsDevAdvisory)
+	if err != nil {
+		return err
+	}
+	advisories := make(map[string]*depsdev.Advisory, len(ids))
+	var fetchErr error
+	for i, result := range results {
+		if result.Err != nil {
+			tr.log(ctx).Warn("Failed to fetch deps.dev advisory", zap.String("id", ids[i]), zap.Error(result.Err))
+			fetchErr = result.Err
+			continue
+		}
+		advisories[ids[i]] = result.Value
+	}
+
+	for i := range findings {
+		advisory := advisories[findings[i].ID]
+		if advisory == nil {
+			continue
+		}
+		f := &findings[i]
+		f.DepsDev = &DepsDevAdvisory{
+			Title:       advisory.Title,
+			URL:         advisory.URL,
+			Aliases:     advisory.Aliases,
+			CVSS3Vector: advisory.CVSS3Vector,
+		}
+		if advisory.CVSS3Score > 0 {
+			score := advisory.CVSS3Score
+			f.DepsDev.CVSS3Score = &score
+		}
+		if f.Summary == "" {
+			f.Summary = advisory.Title
+		}
+		if len(f.Severity) == 0 && advisory.CVSS3Vector != "" {
+			f.Severity = []osv.Severity{{Type: "CVSS_V3", Score: advisory.CVSS3Vector}}
+		}
+	}
+	return fetchErr
+}
+
+// depsDevAdvisory looks up one advisory on deps.dev, returning nil when deps.dev does not
+// carry it. Both outcomes are cached.
+func (tr *ToolRegistry) depsDevAdvisory(ctx context.Context, id string) (*depsdev.Advisory, error) {
+	cacheKey := "depsdev-advisory:" + id
+	if tr.cache != nil {
+		if cached, found := tr.cache.Get(cacheKey); found {
+			if advisory, ok := cached.(*depsdev.Advisory); ok {
+				return advisory, nil
+			}
+		}
+	}
+
+	advisory, err := tr.depsDevClient.GetAdvisory(ctx, id)
+	if errors.Is(err, depsdev.ErrAdvisoryNotFound) {
+		advisory, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tr.cache != nil {
+		tr.cache.Set(cacheKey, advisory, depsDevAdvisoryCacheTTL)
+	}
+	return advisory, nil
+}
+
+// markKnownExploited flags findings whose CVE appears in the CISA KEV catalog and
+// returns the matching CVE IDs. A stale or unavailable catalog is logged, not fatal;
+// the error is non-nil only when no catalog has ever been loaded.
+func (tr *ToolRegistry) markKnownExploited(ctx context.Context, findings []Finding) ([]string, error) {
+	catalogErr := tr.kevClient.EnsureFresh(ctx)
+	if catalogErr != nil {
+		tr.log(ctx).Warn("Failed to refresh KEV catalog", zap.Error(catalogErr))
+	}
+	// A stale catalog still answers lookups; only a never-loaded one leaves results incomplete
+	switch {
+	case !tr.kevClient.LoadedAt().IsZero():
+		catalogErr = nil
+	case catalogErr == nil:
+		catalogErr = fmt.Errorf("KEV catalog not loaded")
+	}
+
+	var exploited []string
+	for i := range findings {
+		cve := cveID(findings[i].Vulnerability)
+		if cve != "" && tr.kevClient.IsKnownExploited(cve) {
+			findings[i].KnownExploited = true
+			exploited = append(exploited, cve)
+		}
+	}
+	return exploited, catalogErr
+}
@@ -0,0 +1,585 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/epss"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"go.uber.org/zap"
+)
+
+func TestHandleVulns_EPSSEnrichment(t *testing.T) {
+	osvMock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/lodash@4.17.19": {
+			{ID: "GHSA-low-epss", Aliases: []string{"CVE-2020-0001"}},
+			{ID: "GHSA-no-cve"},
+			{ID: "CVE-2021-23337"},
+		},
+	})
+
+	var epssRequests atomic.Int64
+	epssServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		epssRequests.Add(1)
+		cves := r.URL.Query().Get("cve")
+		if !strings.Contains(cves, "CVE-2020-0001") || !strings.Contains(cves, "CVE-2021-23337") {
+			t.Errorf("unexpected cve query %q", cves)
+		}
+		_, _ = w.Write([]byte(`{"status":"OK","data":[
+			{"cve":"CVE-2020-0001","epss":"0.00120","percentile":"0.45000"},
+			{"cve":"CVE-2021-23337","epss":"0.71000","percentile":"0.98000"}
+		]}`))
+	}))
+	defer epssServer.Close()
+
+	registry := newTestRegistry(t)
+	registry.osvClient = osvMock.client()
+	registry.epssClient = epss.NewClient(zap.NewNop(), epss.WithBaseURL(epssServer.URL))
+
+	result, err := registry.HandleVulns(context.Background(), VulnsInput{
+		Ecosystem: "npm",
+		Package:   "lodash",
+		Version:   "4.17.19",
+		SortBy:    SortByEPSS,
+	})
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+
+	if len(result.Vulnerabilities) != 3 {
+		t.Fatalf("got %d findings, want 3", len(result.Vulnerabilities))
+	}
+
+	wantOrder := []string{"CVE-2021-23337", "GHSA-low-epss", "GHSA-no-cve"}
+	for i, id := range wantOrder {
+		if result.Vulnerabilities[i].ID != id {
+			t.Errorf("finding[%d] = %s, want %s", i, result.Vulnerabilities[i].ID, id)
+		}
+	}
+
+	top := result.Vulnerabilities[0]
+	if top.EPSSProbability == nil || *top.EPSSProbability != 0.71 {
+		t.Errorf("EPSSProbability = %v, want 0.71", top.EPSSProbability)
+	}
+	if top.EPSSPercentile == nil || *top.EPSSPercentile != 0.98 {
+		t.Errorf("EPSSPercentile = %v, want 0.98", top.EPSSPercentile)
+	}
+	if result.Vulnerabilities[2].EPSSProbability != nil {
+		t.Error("finding without a CVE alias should not carry an EPSS score")
+	}
+	if got := epssRequests.Load(); got != 1 {
+		t.Errorf("EPSS requests = %d, want 1 batched request", got)
+	}
+}
+
+func TestHandleVulns_DepsDevAdvisory(t *testing.T) {
+	osvMock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/widget@1.0.0": {
+			// A sparse OSV entry with no summary or severity
+			{ID: "GHSA-sprs-0000-0000"},
+			{ID: "GHSA-full-0000-0000", Summary: "Prototype pollution in widget",
+				Severity: []osv.Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:L/A:N"}}},
+			// deps.dev does not carry this one
+			{ID: "OSV-2024-0001", Summary: "Unlisted"},
+		},
+	})
+	depsMock := newMockDepsDev(t, nil)
+	depsMock.advisories = map[string]*depsdev.Advisory{
+		"GHSA-sprs-0000-0000": {
+			AdvisoryKey: depsdev.AdvisoryKey{ID: "GHSA-sprs-0000-0000"},
+			URL:         "https://osv.dev/vulnerability/GHSA-sprs-0000-0000",
+			Title:       "Command injection in widget",
+			CVSS3Score:  9.8,
+			CVSS3Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		},
+		"GHSA-full-0000-0000": {
+			AdvisoryKey: depsdev.AdvisoryKey{ID: "GHSA-full-0000-0000"},
+			Title:       "deps.dev title",
+		},
+	}
+	registry := newTestRegistry(t)
+	registry.osvClient = osvMock.client()
+	registry.depsDevClient = depsMock.client()
+
+	result, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "npm", Package: "widget", Version: "1.0.0", SortBy: SortByID})
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+	if !result.DataComplete || !slices.Contains(result.SourcesQueried, SourceDepsDev) {
+		t.Errorf("DataSources = %+v, want deps.dev queried and the result complete", result.DataSources)
+	}
+	byID := make(map[string]Finding)
+	for _, f := range result.Vulnerabilities {
+		byID[f.ID] = f
+	}
+
+	// The sparse entry takes its summary and severity from deps.dev
+	sparse := byID["GHSA-sprs-0000-0000"]
+	if sparse.DepsDev == nil || sparse.DepsDev.Title != "Command injection in widget" || sparse.DepsDev.CVSS3Score == nil || *sparse.DepsDev.CVSS3Score != 9.8 {
+		t.Errorf("sparse depsdev_advisory = %+v", sparse.DepsDev)
+	}
+	if sparse.Summary != "Command injection in widget" || sparse.SeverityScore == nil || *sparse.SeverityScore != 9.8 {
+		t.Errorf("sparse summary/severity = %q/%v, want them filled from deps.dev", sparse.Summary, sparse.SeverityScore)
+	}
+	if result.Summary.Critical != 1 {
+		t.Errorf("Summary = %+v, want the filled-in severity counted as critical", result.Summary)
+	}
+
+	// OSV's own details win over deps.dev's
+	if full := byID["GHSA-full-0000-0000"]; full.Summary != "Prototype pollution in widget" || full.DepsDev == nil {
+		t.Errorf("full finding = %q with %+v, want OSV's summary kept", full.Summary, full.DepsDev)
+	}
+	if unlisted := byID["OSV-2024-0001"]; unlisted.DepsDev != nil {
+		t.Errorf("unlisted depsdev_advisory = %+v, want none", unlisted.DepsDev)
+	}
+
+	t.Run("deps.dev unavailable", func(t *testing.T) {
+		registry := newTestRegistry(t)
+		registry.osvClient = osvMock.client()
+		registry.depsDevClient = depsdev.NewClient(zap.NewNop(), depsdev.WithBaseURL(newFailingServer(t).URL), depsdev.WithRetries(0, 0))
+
+		result, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "npm", Package: "widget", Version: "1.0.0"})
+		if err != nil {
+			t.Fatalf("HandleVulns() error = %v", err)
+		}
+		if result.DataComplete || !slices.Equal(result.SourcesFailed, []string{SourceDepsDev}) || result.VulnerabilityCount != 3 {
+			t.Errorf("result = %d findings with %+v, want OSV findings kept and deps.dev failed", result.VulnerabilityCount, result.DataSources)
+		}
+	})
+}
+
+func TestValidateSortBy(t *testing.T) {
+	if err := validateSortBy(SortByEPSS); err != nil {
+		t.Errorf("validateSortBy(epss) unexpected error: %v", err)
+	}
+	if err := validateSortBy("popularity"); err == nil {
+		t.Error("validateSortBy(popularity) expected error")
+	}
+}
+
+func TestNewFindings_PromotesReferences(t *testing.T) {
+	findings := newFindings([]osv.Vulnerability{
+		{
+			ID: "GHSA-35jh-r3h4-6jhm",
+			References: []osv.Reference{
+				{Type: "WEB", URL: "https://example.com/blog"},
+				{Type: "REPORT", URL: "https://github.com/lodash/lodash/issues/5085"},
+				{Type: "FIX", URL: "https://github.com/lodash/lodash/commit/3469357"},
+				{Type: "ADVISORY", URL: "https://nvd.nist.gov/vuln/detail/CVE-2021-23337"},
+				{Type: "ADVISORY", URL: "https://github.com/advisories/GHSA-35jh-r3h4-6jhm"},
+				{Type: "PACKAGE", URL: "https://github.com/lodash/lodash"},
+			},
+		},
+		{ID: "GHSA-no-refs"},
+	})
+
+	if got := findings[0].AdvisoryURL; got != "https://nvd.nist.gov/vuln/detail/CVE-2021-23337" {
+		t.Errorf("AdvisoryURL = %q, want the first ADVISORY reference", got)
+	}
+	if got := findings[0].FixURL; got != "https://github.com/lodash/lodash/commit/3469357" {
+		t.Errorf("FixURL = %q, want the FIX reference", got)
+	}
+	if len(findings[0].References) != 6 {
+		t.Errorf("References = %d, want the full list of 6", len(findings[0].References))
+	}
+	if findings[1].AdvisoryURL != "" || findings[1].FixURL != "" {
+		t.Errorf("expected no promoted links without references, got %+v", findings[1])
+	}
+}
+
+func TestHandleVulns_Provenance(t *testing.T) {
+	data, err := os.ReadFile("../providers/osv/testdata/GHSA-35jh-r3h4-6jhm.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var advisory osv.Vulnerability
+	if err := json.Unmarshal(data, &advisory); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{"npm/lodash@4.17.19": {advisory}})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	output, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "npm", Package: "lodash", Version: "4.17.19"})
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+	if output.OSVAPI != mock.URL+osv.QueryPath {
+		t.Errorf("OSVAPI = %q, want the mock query endpoint", output.OSVAPI)
+	}
+
+	f := output.Vulnerabilities[0]
+	if f.SchemaVersion != "1.6.0" || f.Database != "GitHub Advisory Database" || f.Source == "" {
+		t.Errorf("finding = schema %q, database %q, source %q; want provenance from the advisory", f.SchemaVersion, f.Database, f.Source)
+	}
+
+	if strings.Join(f.CWEIDs, ",") != "CWE-77,CWE-94" {
+		t.Errorf("CWEIDs = %v, want the advisory's cwe_ids", f.CWEIDs)
+	}
+	if output.Summary.CWEs["CWE-77"] != 1 || output.Summary.CWEs["CWE-94"] != 1 {
+		t.Errorf("Summary.CWEs = %v, want one finding under each CWE", output.Summary.CWEs)
+	}
+
+	// The provenance fields are part of the serialized output
+	encoded, _ := json.Marshal(f)
+	for _, key := range []string{`"schema_version":"1.6.0"`, `"database":"GitHub Advisory Database"`, `"source":"https://github.com/`} {
+		if !strings.Contains(string(encoded), key) {
+			t.Errorf("encoded finding missing %s", key)
+		}
+	}
+}
+
+func TestHandleVulns_AffectedPackages(t *testing.T) {
+	data, err := os.ReadFile("../providers/osv/testdata/GHSA-9999-mlti-eco1.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var advisory osv.Vulnerability
+	if err := json.Unmarshal(data, &advisory); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{"PyPI/widget-codec@3.1.0": {advisory}})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	output, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "PyPI", Package: "widget-codec", Version: "3.1.0"})
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+	if len(output.Vulnerabilities) != 1 {
+		t.Fatalf("Vulnerabilities = %d, want 1", len(output.Vulnerabilities))
+	}
+
+	packages := output.Vulnerabilities[0].AffectedPackages
+	if len(packages) != 3 {
+		t.Fatalf("AffectedPackages = %+v, want npm, PyPI, and Maven packages once each", packages)
+	}
+	encoded, _ := json.Marshal(output.Vulnerabilities[0])
+	if !strings.Contains(string(encoded), `"affected_packages":[{"name":"widget-codec","ecosystem":"npm"}`) {
+		t.Errorf("encoded finding missing affected_packages: %s", encoded)
+	}
+}
+
+func TestSortFindings_DefaultOrderIsStable(t *testing.T) {
+	critical := []osv.Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}
+	medium := []osv.Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:U/C:L/I:L/A:N"}}
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	base := []Finding{
+		{Vulnerability: osv.Vulnerability{ID: "GHSA-c", Severity: critical, Published: day(1)}},
+		{Vulnerability: osv.Vulnerability{ID: "GHSA-a", Severity: critical, Published: day(5)}},
+		{Vulnerability: osv.Vulnerability{ID: "GHSA-b", Severity: critical, Published: day(5)}},
+		{Vulnerability: osv.Vulnerability{ID: "GHSA-d", Severity: medium, Published: day(9)}},
+		{Vulnerability: osv.Vulnerability{ID: "GHSA-e", Published: day(20), Modified: day(21)}},
+	}
+	want := []string{"GHSA-a", "GHSA-b", "GHSA-c", "GHSA-d", "GHSA-e"}
+
+	rng := rand.New(rand.NewSource(1))
+	for run := 0; run < 20; run++ {
+		findings := append([]Finding(nil), base...)
+		rng.Shuffle(len(findings), func(i, j int) { findings[i], findings[j] = findings[j], findings[i] })
+
+		sortFindings(findings, "")
+		for i, id := range want {
+			if findings[i].ID != id {
+				t.Fatalf("run %d: order = %v, want %v", run, findingIDs(findings), want)
+			}
+		}
+	}
+
+	orders := map[string]string{
+		SortByPublished: "GHSA-e",
+		SortByModified:  "GHSA-e",
+		SortByID:        "GHSA-a",
+		SortBySeverity:  "GHSA-a",
+	}
+	for sortBy, first := range orders {
+		findings := append([]Finding(nil), base...)
+		sortFindings(findings, sortBy)
+		if findings[0].ID != first {
+			t.Errorf("sort_by %s: order = %v, want %s first", sortBy, findingIDs(findings), first)
+		}
+	}
+}
+
+func findingIDs(findings []Finding) []string {
+	ids := make([]string, len(findings))
+	for i, f := range findings {
+		ids[i] = f.ID
+	}
+	return ids
+}
+
+func TestHandleVulns_CommitPinned(t *testing.T) {
+	data, err := os.ReadFile("../providers/osv/testdata/GO-2099-0001.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var advisory osv.Vulnerability
+	if err := json.Unmarshal(data, &advisory); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+
+	// A commit cannot be queried by version, so only the all-versions key is served
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{"Go/example.com/widget": {advisory}})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+	registry.epssClient = newMockEPSS(t)
+	registry.kevClient = newMockKEV(t)
+
+	tests := []struct {
+		name    string
+		version string
+		want    string
+	}{
+		{"introducing commit", "3f1c2b4a5d6e7f8091a2b3c4d5e6f708192a3b4c", osv.StatusAffected},
+		{"fixing commit", "9e8d7c6b5a4f", ""},
+		{"unplaceable commit", "0123456789abcdef", osv.StatusPossiblyAffected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "Go", Package: "example.com/widget", Version: tt.version})
+			if err != nil {
+				t.Fatalf("HandleVulns() error = %v", err)
+			}
+			if tt.want == "" {
+				if output.VulnerabilityCount != 0 {
+					t.Errorf("got %d findings, want none", output.VulnerabilityCount)
+				}
+				return
+			}
+			if output.VulnerabilityCount != 1 {
+				t.Fatalf("got %d findings, want 1", output.VulnerabilityCount)
+			}
+			f := output.Vulnerabilities[0]
+			if f.AffectedStatus != tt.want {
+				t.Errorf("AffectedStatus = %q, want %q", f.AffectedStatus, tt.want)
+			}
+			if len(f.GitRanges) != 1 || f.GitRanges[0].Repo != "https://github.com/example/widget" {
+				t.Errorf("GitRanges = %+v, want the advisory's GIT range", f.GitRanges)
+			}
+		})
+	}
+}
+
+func TestHandleVulns_Versions(t *testing.T) {
+	affected := func(events ...osv.Event) []osv.Affected {
+		return []osv.Affected{{
+			Package: osv.Package{Name: "lodash", Ecosystem: "npm"},
+			Ranges:  []osv.VersionRange{{Type: osv.RangeSemver, Events: events}},
+		}}
+	}
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/lodash": {
+			{ID: "GHSA-command", Affected: affected(osv.Event{Introduced: "0"}, osv.Event{Fixed: "4.17.21"})},
+			{ID: "GHSA-prototype", Affected: affected(osv.Event{Introduced: "4.0.0"}, osv.Event{Fixed: "4.17.12"})},
+			{ID: "GHSA-legacy", Affected: affected(osv.Event{Introduced: "3.0.0"}, osv.Event{Fixed: "3.10.0"})},
+		},
+	})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+	registry.epssClient = newMockEPSS(t)
+	registry.kevClient = newMockKEV(t)
+
+	output, err := registry.HandleVulns(context.Background(), VulnsInput{
+		Ecosystem: "npm",
+		Package:   "lodash",
+		Versions:  []string{"4.17.11", "4.17.21"},
+		SortBy:    SortByID,
+	})
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+	if got := mock.requests.Load(); got != 1 {
+		t.Errorf("OSV requests = %d, want 1 shared request", got)
+	}
+
+	// Only findings affecting some requested version are returned
+	if output.VulnerabilityCount != 2 {
+		t.Errorf("VulnerabilityCount = %d, want 2", output.VulnerabilityCount)
+	}
+
+	old := output.Versions["4.17.11"]
+	if old == nil || !slices.Equal(old.IDs, []string{"GHSA-command", "GHSA-prototype"}) || old.VulnerabilityCount != 2 {
+		t.Errorf("versions[4.17.11] = %+v, want GHSA-command and GHSA-prototype", old)
+	}
+	fixed := output.Versions["4.17.21"]
+	if fixed == nil || len(fixed.IDs) != 0 || fixed.VulnerabilityCount != 0 {
+		t.Errorf("versions[4.17.21] = %+v, want no findings", fixed)
+	}
+
+	if _, err := registry.HandleVulns(context.Background(), VulnsInput{
+		Ecosystem: "npm",
+		Package:   "lodash",
+		Version:   "4.17.11",
+		Versions:  []string{"4.17.21"},
+	}); err == nil {
+		t.Error("expected an error combining version and versions")
+	}
+}
+
+func TestHandleVulns_Pagination(t *testing.T) {
+	vulns := make([]osv.Vulnerability, 120)
+	for i := range vulns {
+		vulns[i] = osv.Vulnerability{ID: fmt.Sprintf("GHSA-%04d", i)}
+	}
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{"npm/huge": vulns})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	var seen []string
+	for offset, page := 0, 1; ; page++ {
+		output, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "npm", Package: "huge", SortBy: SortByID, Offset: offset})
+		if err != nil {
+			t.Fatalf("HandleVulns() error = %v", err)
+		}
+		p := output.Pagination
+		if p == nil || p.TotalCount != 120 || p.Limit != DefaultVulnsLimit || p.Offset != offset {
+			t.Fatalf("page %d pagination = %+v, want offset %d of 120 at the default limit", page, p, offset)
+		}
+		// The counts and summary describe the full set on every page
+		if output.VulnerabilityCount != 120 || output.Summary.Unknown != 120 {
+			t.Errorf("page %d VulnerabilityCount = %d, Summary = %+v; want all 120", page, output.VulnerabilityCount, output.Summary)
+		}
+		if p.ReturnedCount != len(output.Vulnerabilities) {
+			t.Errorf("page %d returned_count = %d, want %d", page, p.ReturnedCount, len(output.Vulnerabilities))
+		}
+		for _, f := range output.Vulnerabilities {
+			seen = append(seen, f.ID)
+		}
+		if wantMore := page < 3; p.HasMore != wantMore {
+			t.Errorf("page %d has_more = %v, want %v", page, p.HasMore, wantMore)
+		}
+		if !p.HasMore {
+			break
+		}
+		offset += p.ReturnedCount
+	}
+	if len(seen) != 120 || seen[0] != "GHSA-0000" || seen[50] != "GHSA-0050" || seen[119] != "GHSA-0119" {
+		t.Errorf("paged through %d findings (%v...), want all 120 in order", len(seen), seen[:min(3, len(seen))])
+	}
+	output, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "npm", Package: "huge", Limit: 10, Offset: 115})
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+	if len(output.Vulnerabilities) != 5 || output.Pagination.HasMore {
+		t.Errorf("last partial page = %d findings, has_more %v; want 5 and false", len(output.Vulnerabilities), output.Pagination.HasMore)
+	}
+	output, err = registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "npm", Package: "huge", Offset: 500})
+	if err != nil || len(output.Vulnerabilities) != 0 || output.Vulnerabilities == nil {
+		t.Errorf("offset past the end = %v, %v; want an empty page", output, err)
+	}
+
+	for _, input := range []VulnsInput{{Limit: -1}, {Limit: MaxVulnsLimit + 1}, {Offset: -1}} {
+		input.Ecosystem, input.Package = "npm", "huge"
+		if _, err := registry.HandleVulns(context.Background(), input); err == nil {
+			t.Errorf("HandleVulns(limit %d, offset %d) expected an error", input.Limit, input.Offset)
+		}
+	}
+}
+
+func TestSetAdvisoryAges(t *testing.T) {
+	data, err := os.ReadFile("../providers/osv/testdata/GHSA-35jh-r3h4-6jhm.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var advisory osv.Vulnerability
+	if err := json.Unmarshal(data, &advisory); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+	// Published 2021-05-06T16:05:51Z, modified 2024-02-16T08:09:57Z
+	findings := newFindings([]osv.Vulnerability{advisory, {ID: "GHSA-undated"}})
+	setAdvisoryAges(findings, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+
+	got := findings[0]
+	if got.DaysSincePublished == nil || *got.DaysSincePublished != 1029 {
+		t.Errorf("days_since_published = %v, want 1029", daysValue(got.DaysSincePublished))
+	}
+	if got.DaysSinceModified == nil || *got.DaysSinceModified != 14 {
+		t.Errorf("days_since_modified = %v, want 14", daysValue(got.DaysSinceModified))
+	}
+
+	undated := findings[1]
+	if undated.DaysSincePublished != nil || undated.DaysSinceModified != nil {
+		t.Errorf("undated finding ages = %v, %v; want both omitted", daysValue(undated.DaysSincePublished), daysValue(undated.DaysSinceModified))
+	}
+	encoded, _ := json.Marshal(undated)
+	if strings.Contains(string(encoded), "days_since") {
+		t.Errorf("encoded undated finding carries an age: %s", encoded)
+	}
+}
+
+func TestHandleVulns_ChangedWithinDays(t *testing.T) {
+	now := time.Now()
+	daysAgo := func(d int) time.Time { return now.Add(-time.Duration(d) * 24 * time.Hour) }
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{"npm/widget": {
+		{ID: "GHSA-old", Published: daysAgo(400), Modified: daysAgo(300)},
+		{ID: "GHSA-rescored", Published: daysAgo(400), Modified: daysAgo(2)},
+		{ID: "GHSA-new", Published: daysAgo(5), Modified: daysAgo(5)},
+		{ID: "GHSA-undated"},
+	}})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	output, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "npm", Package: "widget", ChangedWithinDays: 7, SortBy: SortByModified})
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+	if ids := findingIDs(output.Vulnerabilities); !slices.Equal(ids, []string{"GHSA-rescored", "GHSA-new"}) {
+		t.Errorf("changed in the last week = %v, want [GHSA-rescored GHSA-new]", ids)
+	}
+	if output.VulnerabilityCount != 2 {
+		t.Errorf("vulnerability_count = %d, want 2", output.VulnerabilityCount)
+	}
+	if f := output.Vulnerabilities[0]; daysValue(f.DaysSincePublished) != 400 || daysValue(f.DaysSinceModified) != 2 {
+		t.Errorf("GHSA-rescored ages = %d, %d; want 400, 2", daysValue(f.DaysSincePublished), daysValue(f.DaysSinceModified))
+	}
+
+	if _, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "npm", Package: "widget", ChangedWithinDays: -1}); err == nil {
+		t.Error("expected an error for negative changed_within_days")
+	}
+}
+
+// daysValue returns an optional age, or -1 when it was omitted
+func daysValue(days *int) int {
+	if days == nil {
+		return -1
+	}
+	return *days
+}
+
+func TestHandleVulns_AffectedVersions(t *testing.T) {
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{"npm/lodash": {
+		rangedVuln("GHSA-proto", "lodash", osv.Event{Introduced: "4.0.0"}, osv.Event{Fixed: "4.17.21"}),
+	}})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	output, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "npm", Package: "lodash"})
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+	if len(output.Vulnerabilities) != 1 {
+		t.Fatalf("got %d findings, want 1", len(output.Vulnerabilities))
+	}
+	f := output.Vulnerabilities[0]
+	if f.AffectedVersions != ">=4.0.0, <4.17.21" {
+		t.Errorf("affected_versions = %q, want \">=4.0.0, <4.17.21\"", f.AffectedVersions)
+	}
+	if !slices.Equal(f.FixedIn, []string{"4.17.21"}) {
+		t.Errorf("fixed_in = %v, want [4.17.21]", f.FixedIn)
+	}
+}
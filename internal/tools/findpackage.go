@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/pool"
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"go.uber.org/zap"
+)
+
+// FindPackageInput defines input for deps.find_package tool
+type FindPackageInput struct {
+	Package string `json:"package"`
+}
+
+// PackageMatch is an ecosystem that has a package by the searched name
+type PackageMatch struct {
+	Ecosystem string `json:"ecosystem"`
+	// Package is the registry's spelling of the name, which may differ in case
+	Package          string     `json:"package"`
+	LatestVersion    string     `json:"latest_version,omitempty"`
+	VersionCount     int        `json:"version_count"`
+	LastPublished    *time.Time `json:"last_published,omitempty"`
+	Licenses         []string   `json:"licenses,omitempty"`
+	Repository       string     `json:"repository,omitempty"`
+	MaintenanceLevel string     `json:"maintenance_level"`
+}
+
+// FindPackageOutput is the deps.find_package response
+type FindPackageOutput struct {
+	Package string         `json:"package"`
+	Matches []PackageMatch `json:"matches"`
+	// Checked lists the ecosystems looked up; those whose naming rules the name breaks are
+	// skipped
+	Checked []string `json:"checked"`
+	// Unavailable lists ecosystems whose lookup failed for a reason other than the package not
+	// existing, so a match there may be missing
+	Unavailable []string `json:"unavailable,omitempty"`
+}
+
+// HandleFindPackage implements deps.find_package: which supported ecosystems have a package by
+// a name, with basic metadata for each, so an ambiguous name can be placed before a full
+// scan. The lookups run concurrently. Finding nothing is not an error.
+// Example: {"package": "requests"}
+func (tr *ToolRegistry) HandleFindPackage(ctx context.Context, input FindPackageInput) (*FindPackageOutput, error) {
+	name := strings.TrimSpace(input.Package)
+	if name == "" {
+		return nil, fmt.Errorf("%w: package is required", errInvalidInput)
+	}
+
+	output := &FindPackageOutput{Package: name, Matches: []PackageMatch{}, Checked: []string{}}
+	for _, ecosystem := range supportedEcosystems() {
+		if nameFormatProblem(ecosystem, name) == "" {
+			output.Checked = append(output.Checked, ecosystem)
+		}
+	}
+	tr.log(ctx).Info("Handling package search",
+		zap.String("package", name),
+		zap.Strings("ecosystems", output.Checked))
+
+	found, err := pool.Map(ctx, output.Checked, len(output.Checked), func(ctx context.Context, ecosystem string) (*depsdev.PackageInfo, error) {
+		return tr.getPackageInfo(ctx, ecosystem, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range found {
+		ecosystem := output.Checked[i]
+		switch {
+		case result.Err == nil:
+			output.Matches = append(output.Matches, tr.packageMatch(ecosystem, name, result.Value))
+		case !isPackageNotFound(result.Err):
+			tr.log(ctx).Warn("package search lookup failed", zap.String("ecosystem", ecosystem), zap.Error(result.Err))
+			output.Unavailable = append(output.Unavailable, ecosystem)
+		}
+	}
+	return output, nil
+}
+
+// packageMatch summarizes a package found in an ecosystem
+func (tr *ToolRegistry) packageMatch(ecosystem, name string, pkg *depsdev.PackageInfo) PackageMatch {
+	metrics := depsdev.ComputeHealthMetricsWithScoring(pkg, tr.config.HealthScoring)
+	match := PackageMatch{
+		Ecosystem:        ecosystem,
+		Package:          name,
+		LatestVersion:    metrics.LatestVersion,
+		VersionCount:     metrics.VersionCount,
+		Licenses:         metrics.Licenses,
+		MaintenanceLevel: metrics.MaintenanceLevel,
+	}
+	if pkg.PackageKey.Name != "" {
+		match.Package = pkg.PackageKey.Name
+	}
+	if !metrics.LastPublished.IsZero() {
+		published := metrics.LastPublished
+		match.LastPublished = &published
+	}
+	for _, link := range pkg.Links {
+		if link.Label == "SOURCE_REPO" || link.Label == "REPOSITORY" {
+			match.Repository = link.URL
+			break
+		}
+	}
+	return match
+}
@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+)
+
+func TestFindPackage_TwoEcosystems(t *testing.T) {
+	now := time.Now()
+	mock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"npm/widget": {
+			PackageKey: depsdev.PackageKey{System: "NPM", Name: "widget"},
+			Versions: []depsdev.VersionInfo{
+				{VersionKey: depsdev.VersionKey{Version: "1.0.0"}, PublishedAt: now.Add(-400 * 24 * time.Hour)},
+				{VersionKey: depsdev.VersionKey{Version: "2.1.0"}, PublishedAt: now.Add(-10 * 24 * time.Hour), Licenses: []string{"MIT"}, IsDefault: true},
+			},
+			Links: []depsdev.Link{{Label: "SOURCE_REPO", URL: "https://github.com/example/widget"}},
+		},
+		"pypi/widget": {
+			PackageKey: depsdev.PackageKey{System: "PYPI", Name: "Widget"},
+			Versions: []depsdev.VersionInfo{
+				{VersionKey: depsdev.VersionKey{Version: "0.3.0"}, PublishedAt: now.Add(-2000 * 24 * time.Hour), IsDefault: true},
+			},
+		},
+	})
+	registry := newTestRegistry(t)
+	registry.depsDevClient = mock.client()
+
+	result, err := registry.HandleFindPackage(context.Background(), FindPackageInput{Package: "widget"})
+	if err != nil {
+		t.Fatalf("HandleFindPackage() error = %v", err)
+	}
+
+	// Go, Maven, and Packagist names need a module path, groupId, or vendor, so are not looked up
+	if want := []string{"NuGet", "PyPI", "RubyGems", "crates.io", "npm"}; !slices.Equal(result.Checked, want) {
+		t.Errorf("Checked = %v, want %v", result.Checked, want)
+	}
+	if got := mock.requests.Load(); got != int64(len(result.Checked)) {
+		t.Errorf("deps.dev requests = %d, want one per checked ecosystem", got)
+	}
+	if len(result.Unavailable) != 0 {
+		t.Errorf("Unavailable = %v, want none", result.Unavailable)
+	}
+	if len(result.Matches) != 2 {
+		t.Fatalf("Matches = %+v, want PyPI and npm", result.Matches)
+	}
+
+	pypi, npm := result.Matches[0], result.Matches[1]
+	if pypi.Ecosystem != "PyPI" || pypi.Package != "Widget" || pypi.LatestVersion != "0.3.0" || pypi.MaintenanceLevel != "critical" {
+		t.Errorf("PyPI match = %+v, want Widget 0.3.0, critical", pypi)
+	}
+	if npm.Ecosystem != "npm" || npm.LatestVersion != "2.1.0" || npm.VersionCount != 2 {
+		t.Errorf("npm match = %+v, want widget 2.1.0 with 2 versions", npm)
+	}
+	if npm.Repository != "https://github.com/example/widget" || !slices.Equal(npm.Licenses, []string{"MIT"}) {
+		t.Errorf("npm match = %+v, want its repository and MIT", npm)
+	}
+	if npm.LastPublished == nil || !npm.LastPublished.Equal(now.Add(-10*24*time.Hour)) {
+		t.Errorf("npm LastPublished = %v, want the 2.1.0 release", npm.LastPublished)
+	}
+
+	// An unknown name is an empty result, not an error
+	result, err = registry.HandleFindPackage(context.Background(), FindPackageInput{Package: "no-such-widget"})
+	if err != nil {
+		t.Fatalf("HandleFindPackage() for an unknown name error = %v", err)
+	}
+	if result.Matches == nil || len(result.Matches) != 0 || len(result.Unavailable) != 0 {
+		t.Errorf("unknown name = %+v, want an empty matches list", result)
+	}
+
+	if _, err := registry.HandleFindPackage(context.Background(), FindPackageInput{Package: " "}); !errors.Is(err, errInvalidInput) {
+		t.Errorf("blank name error = %v, want errInvalidInput", err)
+	}
+}
@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+)
+
+// Supported values for the output_format tool option
+const (
+	OutputFormatJSON = "json"
+	OutputFormatCSV  = "csv"
+)
+
+// vulnCSVHeader lists the columns emitted by formatVulnsCSV
+var vulnCSVHeader = []string{
+	"package",
+	"ecosystem",
+	"version",
+	"vuln_id",
+	"severity",
+	"cvss_score",
+	"fixed_version",
+	"published",
+}
+
+// validateOutputFormat checks that the requested output format is supported
+func validateOutputFormat(format string) error {
+	switch format {
+	case "", OutputFormatJSON, OutputFormatCSV:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output_format %q (valid: %s, %s)", format, OutputFormatJSON, OutputFormatCSV)
+	}
+}
+
+// formatVulnsCSV renders one row per vulnerability across all results.
+// Field escaping (commas, quotes, newlines) is handled by encoding/csv.
+func formatVulnsCSV(results ...*VulnsOutput) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(vulnCSVHeader); err != nil {
+		return "", fmt.Errorf("write csv header: %w", err)
+	}
+
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		for _, vuln := range result.Vulnerabilities {
+			published := ""
+			if !vuln.Published.IsZero() {
+				published = vuln.Published.UTC().Format(time.RFC3339)
+			}
+
+			score := ""
+			if base, ok := cvssScore(vuln.Vulnerability); ok {
+				score = strconv.FormatFloat(base, 'f', 1, 64)
+			}
+
+			row := []string{
+				result.Package,
+				result.Ecosystem,
+				result.Version,
+				vuln.ID,
+				severityRating(vuln.Vulnerability),
+				score,
+				fixedVersion(vuln.Vulnerability, result.Package),
+				published,
+			}
+			if err := w.Write(row); err != nil {
+				return "", fmt.Errorf("write csv row: %w", err)
+			}
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("flush csv: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// csvResult wraps formatted CSV output in a single text content block
+func csvResult(results ...*VulnsOutput) *mcp.CallToolResult {
+	text, err := formatVulnsCSV(results...)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to format output: %v", err)}},
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+	}
+}
+
+// fixedVersion returns the first fixed version recorded for the named package
+func fixedVersion(vuln osv.Vulnerability, pkg string) string {
+	for _, affected := range vuln.Affected {
+		if pkg != "" && affected.Package.Name != "" && affected.Package.Name != pkg {
+			continue
+		}
+		for _, r := range affected.Ranges {
+			for _, event := range r.Events {
+				if event.Fixed != "" {
+					return event.Fixed
+				}
+			}
+		}
+	}
+	return ""
+}
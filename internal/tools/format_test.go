@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+)
+
+func TestFormatVulnsCSV(t *testing.T) {
+	published := time.Date(2021, 2, 15, 10, 30, 0, 0, time.UTC)
+
+	results := []*VulnsOutput{
+		{
+			Package:   "lodash",
+			Ecosystem: "npm",
+			Version:   "4.17.19",
+			Vulnerabilities: newFindings([]osv.Vulnerability{
+				{
+					ID:        "GHSA-35jh-r3h4-6jhm",
+					Published: published,
+					Severity:  []osv.Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H"}},
+					Affected: []osv.Affected{
+						{
+							Package: osv.Package{Name: "lodash", Ecosystem: "npm"},
+							Ranges: []osv.VersionRange{
+								{Type: "SEMVER", Events: []osv.Event{{Introduced: "0"}, {Fixed: "4.17.21"}}},
+							},
+						},
+					},
+				},
+			}),
+		},
+		{
+			// Package name with a comma, quote, and newline to exercise escaping
+			Package:   "weird,\"pkg\"\nname",
+			Ecosystem: "PyPI",
+			Version:   "1.0.0",
+			Vulnerabilities: newFindings([]osv.Vulnerability{
+				{ID: "PYSEC-2021-1"},
+				{ID: "PYSEC-2021-2", Severity: []osv.Severity{{Type: "CVSS_V3", Score: "HIGH"}}},
+				{ID: "PYSEC-2021-3", Severity: []osv.Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}},
+			}),
+		},
+	}
+
+	out, err := formatVulnsCSV(results...)
+	if err != nil {
+		t.Fatalf("formatVulnsCSV() error = %v", err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse emitted CSV: %v\n%s", err, out)
+	}
+
+	if len(records) != 5 {
+		t.Fatalf("expected header + 4 rows, got %d records", len(records))
+	}
+
+	wantHeader := []string{"package", "ecosystem", "version", "vuln_id", "severity", "cvss_score", "fixed_version", "published"}
+	if strings.Join(records[0], "|") != strings.Join(wantHeader, "|") {
+		t.Errorf("header = %v, want %v", records[0], wantHeader)
+	}
+
+	for i, record := range records {
+		if len(record) != len(wantHeader) {
+			t.Errorf("record %d has %d columns, want %d", i, len(record), len(wantHeader))
+		}
+	}
+
+	first := records[1]
+	if first[3] != "GHSA-35jh-r3h4-6jhm" {
+		t.Errorf("vuln_id = %s, want GHSA-35jh-r3h4-6jhm", first[3])
+	}
+	if first[6] != "4.17.21" {
+		t.Errorf("fixed_version = %s, want 4.17.21", first[6])
+	}
+	if first[7] != "2021-02-15T10:30:00Z" {
+		t.Errorf("published = %s, want 2021-02-15T10:30:00Z", first[7])
+	}
+
+	if records[2][0] != "weird,\"pkg\"\nname" {
+		t.Errorf("escaped package round-trip = %q", records[2][0])
+	}
+	if records[3][4] != "high" {
+		t.Errorf("severity = %s, want high", records[3][4])
+	}
+	if records[3][5] != "" {
+		t.Errorf("cvss_score = %q, want empty for a bare rating", records[3][5])
+	}
+
+	// Real OSV severities are vectors: the score is computed and rated from them
+	if first[4] != "high" || first[5] != "7.2" {
+		t.Errorf("severity/cvss_score = %s/%s, want high/7.2", first[4], first[5])
+	}
+	if records[4][4] != "critical" || records[4][5] != "9.8" {
+		t.Errorf("severity/cvss_score = %s/%s, want critical/9.8", records[4][4], records[4][5])
+	}
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	for _, format := range []string{"", "json", "csv"} {
+		if err := validateOutputFormat(format); err != nil {
+			t.Errorf("validateOutputFormat(%q) unexpected error: %v", format, err)
+		}
+	}
+	if err := validateOutputFormat("xml"); err == nil {
+		t.Error("validateOutputFormat(\"xml\") expected error")
+	}
+}
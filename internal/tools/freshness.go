@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/history"
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"go.uber.org/zap"
+)
+
+// FreshnessInput defines input for deps.freshness tool
+type FreshnessInput struct {
+	Ecosystem      string `json:"ecosystem"`
+	Package        string `json:"package"`
+	CurrentVersion string `json:"current_version"`
+}
+
+// FreshnessOutput is the deps.freshness result
+type FreshnessOutput struct {
+	*depsdev.Freshness
+	CacheStatus
+}
+
+// HandleFreshness implements deps.freshness: how far the current version trails the latest
+// release, without the vulnerability and health analysis of deps.upgrade_plan
+// Example: {"ecosystem": "npm", "package": "lodash", "current_version": "4.17.15"}
+func (tr *ToolRegistry) HandleFreshness(ctx context.Context, input FreshnessInput) (*FreshnessOutput, error) {
+	ecosystem, err := validateEcosystem(input.Ecosystem)
+	if err != nil {
+		return nil, err
+	}
+	if input.Package, input.CurrentVersion, err = routeGoVersion(ecosystem, input.Package, input.CurrentVersion); err != nil {
+		return nil, err
+	}
+	if input.Package == "" || input.CurrentVersion == "" {
+		return nil, fmt.Errorf("%w: package and current_version are required", errInvalidInput)
+	}
+	ecosystem, name := tr.normalizePackage(ctx, ecosystem, input.Package)
+	input.CurrentVersion = strings.TrimSpace(input.CurrentVersion)
+
+	cacheKey := cacheKey("freshness", ecosystem, name, input.CurrentVersion)
+	if tr.cache != nil {
+		if cached, status, found := tr.getCachedResponse(cacheKey); found {
+			tr.log(ctx).Debug("cache hit", zap.String("key", cacheKey))
+			if freshness, ok := cached.(*depsdev.Freshness); ok {
+				history.NoteCache(ctx, true)
+				return &FreshnessOutput{Freshness: freshness, CacheStatus: status}, nil
+			}
+		}
+		history.NoteCache(ctx, false)
+	}
+
+	tr.log(ctx).Info("Handling freshness request",
+		zap.String("ecosystem", ecosystem),
+		zap.String("package", name),
+		zap.String("current_version", input.CurrentVersion))
+
+	pkgInfo, err := tr.getPackageInfo(ctx, ecosystem, name)
+	if err != nil {
+		return nil, fmt.Errorf("query package versions: %w", tr.explainNotFound(ctx, ecosystem, name, err))
+	}
+
+	freshness, err := depsdev.ComputeFreshness(pkgInfo, input.CurrentVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	tr.setCachedResponse(cacheKey, freshness, 5*time.Minute)
+
+	return &FreshnessOutput{Freshness: freshness}, nil
+}
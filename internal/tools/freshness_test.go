@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+)
+
+func TestHandleFreshness_SeveralVersionsBehind(t *testing.T) {
+	published := func(days int) time.Time {
+		return time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, days)
+	}
+	release := func(version string, day int, isDefault bool) depsdev.VersionInfo {
+		return depsdev.VersionInfo{
+			VersionKey:  depsdev.VersionKey{System: "NPM", Name: "lodash", Version: version},
+			PublishedAt: published(day),
+			IsDefault:   isDefault,
+		}
+	}
+	mock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"npm/lodash": {
+			PackageKey: depsdev.PackageKey{System: "NPM", Name: "lodash"},
+			Versions: []depsdev.VersionInfo{
+				release("4.17.15", 0, false),
+				release("4.17.16", 30, false),
+				release("4.17.19", 60, false),
+				release("4.17.20", 90, false),
+				release("5.0.0-beta.1", 100, false),
+				release("4.17.21", 120, true),
+			},
+		},
+	})
+	registry := newTestRegistry(t)
+	registry.depsDevClient = mock.client()
+
+	result, err := registry.HandleFreshness(context.Background(), FreshnessInput{
+		Ecosystem: "npm", Package: "lodash", CurrentVersion: "4.17.15",
+	})
+	if err != nil {
+		t.Fatalf("HandleFreshness() error = %v", err)
+	}
+
+	if result.LatestVersion != "4.17.21" || result.IsLatest {
+		t.Errorf("latest = %s (is_latest %v), want 4.17.21 and behind", result.LatestVersion, result.IsLatest)
+	}
+	// The 5.0.0 prerelease is not counted
+	if result.ReleasesBehind != 4 {
+		t.Errorf("ReleasesBehind = %d, want 4", result.ReleasesBehind)
+	}
+	if result.DaysBehind != 120 {
+		t.Errorf("DaysBehind = %d, want 120", result.DaysBehind)
+	}
+	want := depsdev.VersionGap{Level: depsdev.GapPatch, Patch: 6}
+	if result.VersionGap != want {
+		t.Errorf("VersionGap = %+v, want %+v", result.VersionGap, want)
+	}
+
+	current, err := registry.HandleFreshness(context.Background(), FreshnessInput{
+		Ecosystem: "npm", Package: "lodash", CurrentVersion: "4.17.21",
+	})
+	if err != nil {
+		t.Fatalf("HandleFreshness() error = %v", err)
+	}
+	if !current.IsLatest || current.ReleasesBehind != 0 || current.DaysBehind != 0 || current.VersionGap.Level != depsdev.GapNone {
+		t.Errorf("latest version freshness = %+v, want up to date", current)
+	}
+
+	if _, err := registry.HandleFreshness(context.Background(), FreshnessInput{
+		Ecosystem: "npm", Package: "lodash", CurrentVersion: "3.0.0",
+	}); err == nil {
+		t.Error("expected an error for a version deps.dev does not list")
+	}
+}
@@ -0,0 +1,251 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/cvss"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"go.uber.org/zap"
+)
+
+// Gate verdicts
+const (
+	GateVerdictPass = "pass"
+	GateVerdictFail = "fail"
+)
+
+// Kinds of gate violations
+const (
+	GateViolationVulnerability = "vulnerability"
+	GateViolationLicense       = "license"
+	GateViolationScanError     = "scan_error"
+)
+
+// GateThresholds sets how many findings of each severity a scan may contain before the gate
+// fails. A nil maximum places no limit on that severity.
+type GateThresholds struct {
+	MaxCritical *int `json:"max_critical,omitempty"`
+	MaxHigh     *int `json:"max_high,omitempty"`
+	MaxMedium   *int `json:"max_medium,omitempty"`
+	MaxLow      *int `json:"max_low,omitempty"`
+	// FailOnKEV fails the gate on any finding listed in the CISA KEV catalog
+	FailOnKEV bool `json:"fail_on_kev,omitempty"`
+	// FailOnScanErrors fails the gate when a dependency could not be scanned
+	FailOnScanErrors bool `json:"fail_on_scan_errors,omitempty"`
+}
+
+// DefaultGateThresholds fails on any critical or known-exploited finding
+func DefaultGateThresholds() GateThresholds {
+	noCritical := 0
+	return GateThresholds{MaxCritical: &noCritical, FailOnKEV: true}
+}
+
+// GateInput defines input for deps.gate tool
+type GateInput struct {
+	Filename    string `json:"filename"`
+	Content     string `json:"content"`
+	RuntimeOnly bool   `json:"runtime_only,omitempty"`
+	// Thresholds defaults to DefaultGateThresholds when omitted
+	Thresholds *GateThresholds `json:"thresholds,omitempty"`
+	// LicensePolicy adds a license audit to the gate; without it licenses are not checked
+	LicensePolicy *LicensePolicy `json:"license_policy,omitempty"`
+	// Suppress lists accepted-risk vulnerabilities that do not count toward the verdict
+	Suppress []Suppression `json:"suppress,omitempty"`
+}
+
+// GateViolation is one finding, license, or scan failure that contributed to a failing verdict
+type GateViolation struct {
+	Kind           string `json:"kind"`
+	Ecosystem      string `json:"ecosystem"`
+	Package        string `json:"package"`
+	Version        string `json:"version,omitempty"`
+	ID             string `json:"id,omitempty"`
+	Severity       string `json:"severity,omitempty"`
+	KnownExploited bool   `json:"known_exploited,omitempty"`
+	License        string `json:"license,omitempty"`
+	Detail         string `json:"detail,omitempty"`
+}
+
+// GateOutput is the pass/fail verdict for a manifest, with the reasons behind a failure
+type GateOutput struct {
+	Verdict            string          `json:"verdict"`
+	Reasons            []string        `json:"reasons"`
+	Violations         []GateViolation `json:"violations"`
+	Format             string          `json:"format"`
+	DependencyCount    int             `json:"dependency_count"`
+	VulnerabilityCount int             `json:"vulnerability_count"`
+	ErrorCount         int             `json:"error_count"`
+	Summary            VulnSummary     `json:"summary"`
+	Thresholds         GateThresholds  `json:"thresholds"`
+	// Suppressed lists the findings matched by the suppress list; they are left out of Summary,
+	// VulnerabilityCount, and the verdict. Warnings notes suppressions that have expired.
+	Suppressed []SuppressedFinding `json:"suppressed,omitempty"`
+	Warnings   []string            `json:"warnings,omitempty"`
+}
+
+// HandleGate scans a manifest for vulnerabilities, optionally audits its licenses, and reduces
+// both to a single pass/fail verdict against the thresholds, for CI jobs that need one answer.
+// Example: {"filename": "Cargo.lock", "content": "...", "thresholds": {"max_critical": 0, "max_high": 2}}
+func (tr *ToolRegistry) HandleGate(ctx context.Context, input GateInput) (*GateOutput, error) {
+	thresholds := DefaultGateThresholds()
+	if input.Thresholds != nil {
+		thresholds = *input.Thresholds
+	}
+	for name, limit := range map[string]*int{
+		"max_critical": thresholds.MaxCritical,
+		"max_high":     thresholds.MaxHigh,
+		"max_medium":   thresholds.MaxMedium,
+		"max_low":      thresholds.MaxLow,
+	} {
+		if limit != nil && *limit < 0 {
+			return nil, fmt.Errorf("%w: %s must not be negative", errInvalidInput, name)
+		}
+	}
+	suppressions, err := newSuppressionSet(input.Suppress, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	scan, err := tr.HandleScanManifest(ctx, ScanManifestInput{
+		Filename:    input.Filename,
+		Content:     input.Content,
+		RuntimeOnly: input.RuntimeOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tr.log(ctx).Info("Handling dependency gate",
+		zap.String("format", scan.Format),
+		zap.Int("dependencies", scan.DependencyCount),
+		zap.Bool("license_policy", input.LicensePolicy != nil))
+
+	output := &GateOutput{
+		Verdict:         GateVerdictPass,
+		Reasons:         []string{},
+		Violations:      []GateViolation{},
+		Format:          scan.Format,
+		DependencyCount: scan.DependencyCount,
+		ErrorCount:      scan.ErrorCount,
+		Thresholds:      thresholds,
+		Warnings:        suppressions.warnings,
+	}
+
+	// Set suppressed findings aside before counting, so only actionable ones reach the verdict
+	findings := make([][]Finding, len(scan.Results))
+	var actionable []osv.Vulnerability
+	for i, r := range scan.Results {
+		if r.VulnsOutput == nil {
+			continue
+		}
+		kept, suppressed := suppressions.apply(r.Vulnerabilities)
+		for _, s := range suppressed {
+			s.Ecosystem, s.Package, s.Version = r.Ecosystem, r.Package, r.Version
+			output.Suppressed = append(output.Suppressed, s)
+		}
+		findings[i] = kept
+		actionable = append(actionable, findingVulns(kept)...)
+	}
+	output.VulnerabilityCount = len(actionable)
+	output.Summary = computeVulnSummary(actionable)
+
+	// A severity over its limit fails the gate and reports every finding of that severity
+	limits := map[string]*int{
+		cvss.RatingCritical: thresholds.MaxCritical,
+		cvss.RatingHigh:     thresholds.MaxHigh,
+		cvss.RatingMedium:   thresholds.MaxMedium,
+		cvss.RatingLow:      thresholds.MaxLow,
+	}
+	counts := map[string]int{
+		cvss.RatingCritical: output.Summary.Critical,
+		cvss.RatingHigh:     output.Summary.High,
+		cvss.RatingMedium:   output.Summary.Medium,
+		cvss.RatingLow:      output.Summary.Low,
+	}
+	exceeded := make(map[string]bool)
+	for _, rating := range []string{cvss.RatingCritical, cvss.RatingHigh, cvss.RatingMedium, cvss.RatingLow} {
+		if limit := limits[rating]; limit != nil && counts[rating] > *limit {
+			exceeded[rating] = true
+			output.Reasons = append(output.Reasons, fmt.Sprintf("%d %s vulnerabilities exceed max_%s of %d",
+				counts[rating], rating, rating, *limit))
+		}
+	}
+
+	kevCount := 0
+	for i, r := range scan.Results {
+		if r.VulnsOutput == nil {
+			if thresholds.FailOnScanErrors && r.Request != nil {
+				output.Violations = append(output.Violations, GateViolation{
+					Kind:      GateViolationScanError,
+					Ecosystem: r.Request.Ecosystem,
+					Package:   r.Request.Package,
+					Version:   r.Request.Version,
+					Detail:    r.Error,
+				})
+			}
+			continue
+		}
+		for _, f := range findings[i] {
+			rating := severityRating(f.Vulnerability)
+			kev := thresholds.FailOnKEV && f.KnownExploited
+			if kev {
+				kevCount++
+			}
+			if !exceeded[rating] && !kev {
+				continue
+			}
+			output.Violations = append(output.Violations, GateViolation{
+				Kind:           GateViolationVulnerability,
+				Ecosystem:      r.Ecosystem,
+				Package:        r.Package,
+				Version:        r.Version,
+				ID:             f.ID,
+				Severity:       rating,
+				KnownExploited: f.KnownExploited,
+				Detail:         f.Summary,
+			})
+		}
+	}
+	if kevCount > 0 {
+		output.Reasons = append(output.Reasons, fmt.Sprintf("%d vulnerabilities are listed in the CISA KEV catalog", kevCount))
+	}
+	if thresholds.FailOnScanErrors && scan.ErrorCount > 0 {
+		output.Reasons = append(output.Reasons, fmt.Sprintf("%d dependencies could not be scanned", scan.ErrorCount))
+	}
+
+	if input.LicensePolicy != nil {
+		audit, err := tr.HandleLicenseAudit(ctx, LicenseAuditInput{
+			Filename:    input.Filename,
+			Content:     input.Content,
+			RuntimeOnly: input.RuntimeOnly,
+			Policy:      *input.LicensePolicy,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range audit.Packages {
+			if pkg.Allowed {
+				continue
+			}
+			output.Violations = append(output.Violations, GateViolation{
+				Kind:      GateViolationLicense,
+				Ecosystem: pkg.Ecosystem,
+				Package:   pkg.Package,
+				Version:   pkg.Version,
+				License:   pkg.License,
+				Detail:    strings.Join(pkg.Violations, "; "),
+			})
+		}
+		if audit.ViolationCount > 0 {
+			output.Reasons = append(output.Reasons, fmt.Sprintf("%d packages violate the license policy", audit.ViolationCount))
+		}
+	}
+
+	if len(output.Reasons) > 0 {
+		output.Verdict = GateVerdictFail
+	}
+	return output, nil
+}
@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+)
+
+func TestGate(t *testing.T) {
+	content, err := os.ReadFile("../manifest/testdata/Cargo.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	high := []osv.Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N"}}
+	critical := []osv.Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"crates.io/serde@1.0.188": {{ID: "RUSTSEC-2099-0001", Severity: high}},
+		"crates.io/smallvec@1.6.0": {
+			{ID: "RUSTSEC-2021-0003", Aliases: []string{"CVE-2021-25900"}, Severity: critical},
+		},
+	})
+
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+	input := GateInput{Filename: "Cargo.lock", Content: string(content)}
+
+	t.Run("critical threshold", func(t *testing.T) {
+		result, err := registry.HandleGate(context.Background(), input)
+		if err != nil {
+			t.Fatalf("HandleGate() error = %v", err)
+		}
+		if result.Verdict != GateVerdictFail || len(result.Reasons) != 1 {
+			t.Fatalf("result = %+v, want a fail for the critical finding only", result)
+		}
+		if len(result.Violations) != 1 {
+			t.Fatalf("Violations = %+v, want the critical finding", result.Violations)
+		}
+		v := result.Violations[0]
+		if v.Kind != GateViolationVulnerability || v.Package != "smallvec" || v.ID != "RUSTSEC-2021-0003" || v.Severity != "critical" {
+			t.Errorf("violation = %+v, want smallvec's critical finding", v)
+		}
+	})
+
+	t.Run("passing thresholds", func(t *testing.T) {
+		one := 1
+		input := input
+		input.Thresholds = &GateThresholds{MaxCritical: &one, MaxHigh: &one, FailOnKEV: true}
+		result, err := registry.HandleGate(context.Background(), input)
+		if err != nil {
+			t.Fatalf("HandleGate() error = %v", err)
+		}
+		if result.Verdict != GateVerdictPass || len(result.Reasons) != 0 || len(result.Violations) != 0 {
+			t.Errorf("result = %+v, want a pass", result)
+		}
+		if result.Summary.Critical != 1 || result.Summary.High != 1 {
+			t.Errorf("Summary = %+v, want one critical and one high", result.Summary)
+		}
+	})
+
+	t.Run("known exploited", func(t *testing.T) {
+		registry := newTestRegistry(t)
+		registry.osvClient = mock.client()
+		registry.kevClient = newMockKEV(t, "CVE-2021-25900")
+
+		one := 1
+		input := input
+		input.Thresholds = &GateThresholds{MaxCritical: &one, FailOnKEV: true}
+		result, err := registry.HandleGate(context.Background(), input)
+		if err != nil {
+			t.Fatalf("HandleGate() error = %v", err)
+		}
+		if result.Verdict != GateVerdictFail || len(result.Violations) != 1 || !result.Violations[0].KnownExploited {
+			t.Errorf("result = %+v, want a fail for the KEV-listed finding", result)
+		}
+	})
+
+	t.Run("license policy", func(t *testing.T) {
+		crate := func(name, version string, licenses ...string) *depsdev.PackageInfo {
+			return &depsdev.PackageInfo{
+				PackageKey: depsdev.PackageKey{System: "CARGO", Name: name},
+				Versions: []depsdev.VersionInfo{
+					{VersionKey: depsdev.VersionKey{Version: version}, Licenses: licenses, IsDefault: true},
+				},
+			}
+		}
+		registry := newTestRegistry(t)
+		registry.osvClient = mock.client()
+		registry.depsDevClient = newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+			"cargo/serde":    crate("serde", "1.0.188", "MIT OR Apache-2.0"),
+			"cargo/smallvec": crate("smallvec", "1.6.0", "GPL-3.0-only"),
+		}).client()
+
+		one := 1
+		input := input
+		input.Thresholds = &GateThresholds{MaxCritical: &one}
+		input.LicensePolicy = &LicensePolicy{DenyCategories: []string{"Copyleft"}}
+		result, err := registry.HandleGate(context.Background(), input)
+		if err != nil {
+			t.Fatalf("HandleGate() error = %v", err)
+		}
+		if result.Verdict != GateVerdictFail || len(result.Violations) != 1 {
+			t.Fatalf("result = %+v, want a fail for the license violation", result)
+		}
+		if v := result.Violations[0]; v.Kind != GateViolationLicense || v.Package != "smallvec" || v.License != "GPL-3.0-only" {
+			t.Errorf("violation = %+v, want smallvec's GPL license", v)
+		}
+	})
+
+	t.Run("suppressed critical", func(t *testing.T) {
+		input := input
+		input.Suppress = []Suppression{
+			// Matched through the finding's CVE alias
+			{ID: "CVE-2021-25900", Expires: "2999-12-31", Justification: "smallvec is only used at build time"},
+			{ID: "RUSTSEC-2099-0001", Expires: "2000-01-01"},
+		}
+		result, err := registry.HandleGate(context.Background(), input)
+		if err != nil {
+			t.Fatalf("HandleGate() error = %v", err)
+		}
+		if result.Verdict != GateVerdictPass || len(result.Violations) != 0 {
+			t.Errorf("result = %+v, want a pass with the critical suppressed", result)
+		}
+		if result.Summary.Critical != 0 || result.Summary.High != 1 || result.VulnerabilityCount != 1 {
+			t.Errorf("Summary = %+v, want only the high finding counted", result.Summary)
+		}
+		if len(result.Suppressed) != 1 {
+			t.Fatalf("Suppressed = %+v, want the critical finding", result.Suppressed)
+		}
+		if s := result.Suppressed[0]; s.ID != "RUSTSEC-2021-0003" || s.Package != "smallvec" || s.Severity != "critical" || s.Justification == "" {
+			t.Errorf("suppressed = %+v, want smallvec's critical finding with its justification", s)
+		}
+		// The expired suppression no longer applies and is called out
+		if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "RUSTSEC-2099-0001") {
+			t.Errorf("Warnings = %q, want the expired suppression", result.Warnings)
+		}
+	})
+
+	t.Run("invalid suppression", func(t *testing.T) {
+		input := input
+		input.Suppress = []Suppression{{ID: "RUSTSEC-2021-0003", Expires: "next year"}}
+		if _, err := registry.HandleGate(context.Background(), input); !errors.Is(err, errInvalidInput) {
+			t.Errorf("HandleGate() error = %v, want INVALID_INPUT for an unparseable expiry", err)
+		}
+	})
+
+	t.Run("negative threshold", func(t *testing.T) {
+		negative := -1
+		input := input
+		input.Thresholds = &GateThresholds{MaxHigh: &negative}
+		if _, err := registry.HandleGate(context.Background(), input); err == nil {
+			t.Error("expected an error for a negative threshold")
+		}
+	})
+}
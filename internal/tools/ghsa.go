@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/ghsa"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"go.uber.org/zap"
+)
+
+// githubAdvisories returns the GitHub advisories affecting a package version, converted to
+// the OSV schema. ok is false when the source is skipped: no token is configured or GitHub
+// does not track the ecosystem.
+func (tr *ToolRegistry) githubAdvisories(ctx context.Context, ecosystem, name, version string) (vulns []osv.Vulnerability, ok bool, err error) {
+	if !tr.ghsaClient.Enabled() || ghsa.Ecosystem(ecosystem) == "" {
+		return nil, false, nil
+	}
+
+	results, err := tr.ghsaClient.Query(ctx, ecosystem, name)
+	if err != nil {
+		tr.log(ctx).Warn("GitHub advisory query failed",
+			zap.String("package", name),
+			zap.Error(err))
+		return nil, true, err
+	}
+
+	for _, v := range results {
+		affected, err := v.Affects(version, depsdev.SchemeFor(ecosystem).Compare)
+		if err != nil {
+			tr.log(ctx).Debug("skipping unparseable GitHub version range",
+				zap.String("id", v.Advisory.GHSAID),
+				zap.String("range", v.VulnerableVersionRange),
+				zap.Error(err))
+			continue
+		}
+		if affected {
+			vulns = append(vulns, ghsaToOSV(v, ecosystem, name))
+		}
+	}
+	return vulns, true, nil
+}
+
+// ghsaToOSV converts a GitHub advisory to the OSV schema so it flows through the same
+// enrichment, scoring, and sorting as OSV results
+func ghsaToOSV(v ghsa.Vulnerability, ecosystem, name string) osv.Vulnerability {
+	adv := v.Advisory
+	vuln := osv.Vulnerability{
+		ID:        adv.GHSAID,
+		Summary:   adv.Summary,
+		Details:   adv.Description,
+		Published: adv.PublishedAt,
+		Modified:  adv.UpdatedAt,
+		Affected: []osv.Affected{{
+			Package: osv.Package{Name: name, Ecosystem: ecosystem},
+		}},
+	}
+
+	for _, id := range adv.Identifiers {
+		if id.Value != adv.GHSAID {
+			vuln.Aliases = appendUnique(vuln.Aliases, id.Value)
+		}
+	}
+
+	if adv.CVSS.VectorString != "" {
+		scoreType := "CVSS_V3"
+		if strings.HasPrefix(adv.CVSS.VectorString, "CVSS:4") {
+			scoreType = "CVSS_V4"
+		}
+		vuln.Severity = []osv.Severity{{Type: scoreType, Score: adv.CVSS.VectorString}}
+	}
+
+	if events := rangeEvents(v.VulnerableVersionRange); len(events) > 0 {
+		vuln.Affected[0].Ranges = []osv.VersionRange{{Type: osv.RangeEcosystem, Events: events}}
+	}
+
+	if adv.Permalink != "" {
+		vuln.References = append(vuln.References, osv.Reference{Type: osv.ReferenceAdvisory, URL: adv.Permalink})
+	}
+	for _, ref := range adv.References {
+		if ref.URL != adv.Permalink {
+			vuln.References = append(vuln.References, osv.Reference{Type: osv.ReferenceWeb, URL: ref.URL})
+		}
+	}
+
+	return vuln
+}
+
+// rangeEvents converts a GitHub range such as ">= 1.0.0, < 1.2.3" into OSV events
+func rangeEvents(vulnerableRange string) []osv.Event {
+	introduced := osv.Event{Introduced: "0"}
+	var end *osv.Event
+	for _, clause := range strings.Split(vulnerableRange, ",") {
+		clause = strings.TrimSpace(clause)
+		switch {
+		case strings.HasPrefix(clause, ">="):
+			introduced.Introduced = strings.TrimSpace(clause[2:])
+		case strings.HasPrefix(clause, "<="):
+			end = &osv.Event{LastAffected: strings.TrimSpace(clause[2:])}
+		case strings.HasPrefix(clause, "<"):
+			end = &osv.Event{Fixed: strings.TrimSpace(clause[1:])}
+		case strings.HasPrefix(clause, "="):
+			version := strings.TrimSpace(clause[1:])
+			introduced.Introduced = version
+			end = &osv.Event{LastAffected: version}
+		}
+	}
+	if end == nil {
+		return nil
+	}
+	return []osv.Event{introduced, *end}
+}
+
+// mergeAdvisories folds findings from an additional source into existing ones. An advisory
+// sharing an ID or alias with an existing finding only adds source to its ReportedBy;
+// anything new is appended.
+func mergeAdvisories(findings []Finding, vulns []osv.Vulnerability, source string) []Finding {
+	index := make(map[string]int)
+	for i, f := range findings {
+		for _, id := range advisoryIDs(f.Vulnerability) {
+			index[id] = i
+		}
+	}
+
+	for _, v := range vulns {
+		matched := -1
+		for _, id := range advisoryIDs(v) {
+			if i, ok := index[id]; ok {
+				matched = i
+				break
+			}
+		}
+		if matched >= 0 {
+			findings[matched].ReportedBy = appendUnique(findings[matched].ReportedBy, source)
+			continue
+		}
+
+		added := newFindings([]osv.Vulnerability{v})[0]
+		added.ReportedBy = []string{source}
+		findings = append(findings, added)
+		for _, id := range advisoryIDs(v) {
+			index[id] = len(findings) - 1
+		}
+	}
+	return findings
+}
+
+// advisoryIDs returns a vulnerability's ID and aliases, upper-cased for matching
+func advisoryIDs(v osv.Vulnerability) []string {
+	ids := []string{strings.ToUpper(v.ID)}
+	for _, alias := range v.Aliases {
+		ids = append(ids, strings.ToUpper(alias))
+	}
+	return ids
+}
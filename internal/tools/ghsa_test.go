@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/ghsa"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"go.uber.org/zap"
+)
+
+func TestHandleVulns_MergesGitHubAdvisories(t *testing.T) {
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/lodash@4.17.19": {
+			{ID: "GHSA-35jh-r3h4-6jhm", Aliases: []string{"CVE-2021-23337"}},
+			{ID: "GHSA-p6mc-m468-83gw"},
+		},
+	})
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"securityVulnerabilities":{"nodes":[
+			{"vulnerableVersionRange":"< 4.17.21","firstPatchedVersion":{"identifier":"4.17.21"},
+			 "advisory":{"ghsaId":"GHSA-aaaa-bbbb-cccc","identifiers":[{"type":"CVE","value":"CVE-2021-23337"}]}},
+			{"vulnerableVersionRange":"< 4.17.19","firstPatchedVersion":{"identifier":"4.17.19"},
+			 "advisory":{"ghsaId":"GHSA-gone-gone-gone"}},
+			{"vulnerableVersionRange":">= 4.0.0, < 4.17.21","firstPatchedVersion":{"identifier":"4.17.21"},
+			 "advisory":{"ghsaId":"GHSA-29mw-wpgm-hmr9","summary":"ReDoS in lodash","permalink":"https://github.com/advisories/GHSA-29mw-wpgm-hmr9",
+			  "identifiers":[{"type":"GHSA","value":"GHSA-29mw-wpgm-hmr9"},{"type":"CVE","value":"CVE-2020-28500"}],
+			  "cvss":{"score":5.3,"vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:L"}}}],
+			"pageInfo":{"hasNextPage":false}}}}`))
+	}))
+	t.Cleanup(github.Close)
+
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+	registry.ghsaClient = ghsa.NewClient(zap.NewNop(), ghsa.WithBaseURL(github.URL), ghsa.WithToken("test"))
+
+	result, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "npm", Package: "lodash", Version: "4.17.19", SortBy: SortByID})
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+
+	// The CVE alias dedupes one advisory and the 4.17.19 fix excludes another
+	if result.VulnerabilityCount != 3 {
+		t.Fatalf("VulnerabilityCount = %d, want 3: %+v", result.VulnerabilityCount, result.Vulnerabilities)
+	}
+	reportedBy := make(map[string][]string)
+	for _, f := range result.Vulnerabilities {
+		reportedBy[f.ID] = f.ReportedBy
+	}
+	if got := reportedBy["GHSA-35jh-r3h4-6jhm"]; len(got) != 2 || got[0] != SourceOSV || got[1] != SourceGHSA {
+		t.Errorf("aliased finding reported_by = %v, want [osv ghsa]", got)
+	}
+	if got := reportedBy["GHSA-p6mc-m468-83gw"]; len(got) != 1 || got[0] != SourceOSV {
+		t.Errorf("OSV-only finding reported_by = %v, want [osv]", got)
+	}
+	if got := reportedBy["GHSA-29mw-wpgm-hmr9"]; len(got) != 1 || got[0] != SourceGHSA {
+		t.Errorf("GitHub-only finding reported_by = %v, want [ghsa]", got)
+	}
+	if result.Summary.Medium != 1 {
+		t.Errorf("Summary = %+v, want the GitHub CVSS score counted", result.Summary)
+	}
+	if !slices.Contains(result.SourcesQueried, SourceGHSA) || !result.DataComplete {
+		t.Errorf("sources = %+v, want ghsa queried successfully", result.DataSources)
+	}
+}
+
+func TestHandleVulns_SkipsGitHubWithoutToken(t *testing.T) {
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/lodash": {{ID: "GHSA-35jh-r3h4-6jhm"}},
+	})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	result, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "npm", Package: "lodash"})
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+	if slices.Contains(result.SourcesQueried, SourceGHSA) {
+		t.Errorf("sources_queried = %v, want ghsa skipped without a token", result.SourcesQueried)
+	}
+	if result.VulnerabilityCount != 1 || len(result.Vulnerabilities[0].ReportedBy) != 1 {
+		t.Errorf("result = %+v, want the single OSV finding", result)
+	}
+}
@@ -0,0 +1,254 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"go.uber.org/zap"
+)
+
+// healthRequest builds a deps.health tool request from an input struct
+func healthRequest(t *testing.T, input any) *mcp.CallToolRequest {
+	t.Helper()
+
+	args, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("failed to marshal input: %v", err)
+	}
+	return &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Name: "deps.health", Arguments: args},
+	}
+}
+
+func TestHealthHandler_SpecificVersion(t *testing.T) {
+	now := time.Now()
+	mock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"npm/express": {
+			PackageKey: depsdev.PackageKey{System: "NPM", Name: "express"},
+			Versions: []depsdev.VersionInfo{
+				{VersionKey: depsdev.VersionKey{Version: "4.16.0"}, PublishedAt: now.Add(-2000 * 24 * time.Hour), Licenses: []string{"MIT"}},
+				{VersionKey: depsdev.VersionKey{Version: "4.17.0"}, PublishedAt: now.Add(-1500 * 24 * time.Hour), Licenses: []string{"MIT"}},
+				{VersionKey: depsdev.VersionKey{Version: "4.18.0"}, PublishedAt: now.Add(-700 * 24 * time.Hour), Licenses: []string{"MIT"}},
+				{VersionKey: depsdev.VersionKey{Version: "4.19.0"}, PublishedAt: now.Add(-20 * 24 * time.Hour), Licenses: []string{"MIT"}, IsDefault: true},
+			},
+		},
+	})
+
+	registry := newTestRegistry(t)
+	registry.depsDevClient = mock.client()
+
+	result, err := registry.HandleHealth(context.Background(), healthRequest(t, VulnsInput{
+		Ecosystem: "npm",
+		Package:   "express",
+		Version:   "4.17.0",
+	}))
+	if err != nil {
+		t.Fatalf("HandleHealth() unexpected error: %v", err)
+	}
+	text := resultText(t, result)
+	if result.IsError {
+		t.Fatalf("HandleHealth() returned error result: %s", text)
+	}
+
+	var metrics depsdev.HealthMetrics
+	if err := json.Unmarshal([]byte(text), &metrics); err != nil {
+		t.Fatalf("Failed to parse health metrics JSON: %v", err)
+	}
+	if metrics.Version != "4.17.0" {
+		t.Errorf("Version = %s, want 4.17.0", metrics.Version)
+	}
+	if metrics.ReleasesBehind == nil || *metrics.ReleasesBehind != 2 {
+		t.Errorf("ReleasesBehind = %v, want 2", metrics.ReleasesBehind)
+	}
+	if metrics.LatestVersion != "4.19.0" {
+		t.Errorf("LatestVersion = %s, want 4.19.0", metrics.LatestVersion)
+	}
+
+	// Version-less requests keep the package-level report
+	result, err = registry.HandleHealth(context.Background(), healthRequest(t, VulnsInput{
+		Ecosystem: "npm",
+		Package:   "express",
+	}))
+	if err != nil || result.IsError {
+		t.Fatalf("HandleHealth() without version failed: %v", err)
+	}
+	text = resultText(t, result)
+	if strings.Contains(text, "releases_behind") {
+		t.Errorf("version-less report should not include releases_behind: %s", text)
+	}
+	var overall depsdev.HealthMetrics
+	if err := json.Unmarshal([]byte(text), &overall); err != nil {
+		t.Fatalf("Failed to parse health metrics JSON: %v", err)
+	}
+	if overall.Version != "" || overall.ReleasesBehind != nil {
+		t.Errorf("version-less report should not carry version details: %+v", overall)
+	}
+}
+
+func TestHealthHandler_SuggestedAlternatives(t *testing.T) {
+	// One release years ago with no repository, docs, or license scores as critical
+	abandoned := func(system, name string) *depsdev.PackageInfo {
+		return &depsdev.PackageInfo{
+			PackageKey: depsdev.PackageKey{System: system, Name: name},
+			Versions: []depsdev.VersionInfo{
+				{VersionKey: depsdev.VersionKey{Version: "2.88.2"}, PublishedAt: time.Now().Add(-2000 * 24 * time.Hour), IsDefault: true},
+			},
+		}
+	}
+	mock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"npm/request":       abandoned("NPM", "request"),
+		"npm/left-behind":   abandoned("NPM", "left-behind"),
+		"npm/custom-legacy": abandoned("NPM", "custom-legacy"),
+	})
+
+	cfg := DefaultConfig()
+	cfg.Alternatives = map[string][]string{"NPM/Custom-Legacy": {"custom-next"}}
+	registry, err := NewToolRegistryWithConfig(zap.NewNop(), newTestRegistry(t).cache, cfg)
+	if err != nil {
+		t.Fatalf("NewToolRegistryWithConfig() error = %v", err)
+	}
+	registry.depsDevClient = mock.client()
+
+	tests := []struct {
+		pkg  string
+		want string
+	}{
+		{"request", "axios,got,node-fetch"},
+		{"left-behind", ""},
+		{"custom-legacy", "custom-next"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pkg, func(t *testing.T) {
+			result, err := registry.HandleHealth(context.Background(), healthRequest(t, VulnsInput{Ecosystem: "npm", Package: tt.pkg}))
+			if err != nil || result.IsError {
+				t.Fatalf("HandleHealth() = %+v, %v", result, err)
+			}
+			var output HealthOutput
+			if err := json.Unmarshal([]byte(resultText(t, result)), &output); err != nil {
+				t.Fatalf("decode output: %v", err)
+			}
+			if output.MaintenanceLevel != "critical" {
+				t.Fatalf("MaintenanceLevel = %q, want critical", output.MaintenanceLevel)
+			}
+			if got := strings.Join(output.SuggestedAlternatives, ","); got != tt.want {
+				t.Errorf("SuggestedAlternatives = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// The upgrade plan names the same alternatives
+	registry.osvClient = newMockOSV(t, nil).client()
+	plan := runUpgradePlan(t, registry, UpgradePlanInput{Ecosystem: "npm", Package: "request", CurrentVersion: "2.88.2"})
+	if len(plan.SuggestedAlternatives) != 3 || !strings.Contains(plan.Recommendation, "Suggested alternatives: axios, got, node-fetch.") {
+		t.Errorf("plan = %+v, want the curated alternatives", plan)
+	}
+
+	// Healthy packages get no suggestions even with a curated entry
+	if alts := registry.suggestAlternatives("npm", "request", "good"); alts != nil {
+		t.Errorf("suggestAlternatives() for a healthy package = %v, want nil", alts)
+	}
+}
+
+func TestHealthHandler_Explain(t *testing.T) {
+	mock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"npm/widget": {
+			PackageKey: depsdev.PackageKey{System: "NPM", Name: "widget"},
+			Versions: []depsdev.VersionInfo{
+				{VersionKey: depsdev.VersionKey{Version: "1.0.0"}, PublishedAt: time.Now().Add(-10 * 24 * time.Hour), Licenses: []string{"MIT"}, IsDefault: true},
+			},
+			Links: []depsdev.Link{{Label: "SOURCE_REPO", URL: "https://github.com/example/widget"}},
+		},
+	})
+	registry := newTestRegistry(t)
+	registry.depsDevClient = mock.client()
+
+	decode := func(input any) HealthOutput {
+		t.Helper()
+		result, err := registry.HandleHealth(context.Background(), healthRequest(t, input))
+		if err != nil || result.IsError {
+			t.Fatalf("HandleHealth() = %+v, %v", result, err)
+		}
+		var output HealthOutput
+		if err := json.Unmarshal([]byte(resultText(t, result)), &output); err != nil {
+			t.Fatalf("decode output: %v", err)
+		}
+		return output
+	}
+
+	if output := decode(VulnsInput{Ecosystem: "npm", Package: "widget"}); output.ScoreBreakdown != nil {
+		t.Errorf("ScoreBreakdown without explain = %+v, want omitted", output.ScoreBreakdown)
+	}
+
+	output := decode(HealthInput{VulnsInput: VulnsInput{Ecosystem: "npm", Package: "widget"}, Explain: true})
+	if len(output.ScoreBreakdown) != 5 {
+		t.Fatalf("ScoreBreakdown = %+v, want 5 components", output.ScoreBreakdown)
+	}
+	sum := 0.0
+	for _, c := range output.ScoreBreakdown {
+		sum += c.Points
+	}
+	// Recent, licensed, and linked to a repository, but few versions and no docs
+	if sum != output.MaintenanceScore || sum != 70 {
+		t.Errorf("components sum to %.2f, maintenance score = %.2f, want 70", sum, output.MaintenanceScore)
+	}
+}
+
+func TestHealthHandler_NotFoundHint(t *testing.T) {
+	mock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"go/github.com/gin-gonic/gin": {
+			PackageKey: depsdev.PackageKey{System: "GO", Name: "github.com/gin-gonic/gin"},
+			Versions: []depsdev.VersionInfo{
+				{VersionKey: depsdev.VersionKey{Version: "v1.10.0"}, PublishedAt: time.Now().Add(-30 * 24 * time.Hour), IsDefault: true},
+			},
+		},
+	})
+
+	registry := newTestRegistry(t)
+	registry.depsDevClient = mock.client()
+
+	result, err := registry.HandleHealth(context.Background(), healthRequest(t, VulnsInput{
+		Ecosystem: "npm",
+		Package:   "github.com/gin-gonic/gin",
+	}))
+	if err != nil {
+		t.Fatalf("HandleHealth() unexpected error: %v", err)
+	}
+	text := resultText(t, result)
+	if !result.IsError {
+		t.Fatalf("HandleHealth() = %s, want an error result", text)
+	}
+	for _, want := range []string{"package not found", `did you mean ecosystem "Go"?`, "only contain a slash after an @scope"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("error = %q, want it to contain %q", text, want)
+		}
+	}
+}
+
+func TestNameFormatProblem(t *testing.T) {
+	tests := []struct {
+		ecosystem, name string
+		ok              bool
+	}{
+		{"Go", "github.com/gin-gonic/gin", true},
+		{"Go", "gin", false},
+		{"npm", "@babel/core", true},
+		{"npm", "Express", false},
+		{"npm", "babel/core", false},
+		{"Maven", "org.slf4j:slf4j-api", true},
+		{"Maven", "slf4j-api", false},
+		{"Packagist", "monolog/monolog", true},
+		{"Packagist", "monolog", false},
+		{"PyPI", "requests", true},
+		{"PyPI", "org.slf4j:slf4j-api", false},
+	}
+	for _, tt := range tests {
+		if problem := nameFormatProblem(tt.ecosystem, tt.name); (problem == "") != tt.ok {
+			t.Errorf("nameFormatProblem(%s, %s) = %q, want ok %v", tt.ecosystem, tt.name, problem, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/PackagePulse/internal/providers/spdx"
+	"go.uber.org/zap"
+)
+
+// BatchLicenseInput defines input for license.batch_info tool
+type BatchLicenseInput struct {
+	LicenseIDs []string `json:"license_ids"`
+}
+
+// BatchLicenseOutput contains resolved licenses and a roll-up by category
+type BatchLicenseOutput struct {
+	Licenses   map[string]*spdx.LicenseInfo `json:"licenses"`
+	Categories map[string]int               `json:"categories"`
+	Unresolved []string                     `json:"unresolved"`
+}
+
+// HandleBatchLicense resolves a set of license IDs or SPDX expressions in one call.
+// Category counts are per input entry, so two dependencies both declaring MIT count twice.
+func (tr *ToolRegistry) HandleBatchLicense(ctx context.Context, input BatchLicenseInput) (*mcp.CallToolResult, error) {
+	tr.log(ctx).Info("Handling batch license query", zap.Int("entries", len(input.LicenseIDs)))
+
+	if len(input.LicenseIDs) == 0 {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{&mcp.TextContent{Text: "license_ids is required"}},
+		}, nil
+	}
+
+	output := &BatchLicenseOutput{
+		Licenses:   make(map[string]*spdx.LicenseInfo),
+		Categories: make(map[string]int),
+		Unresolved: []string{},
+	}
+
+	unresolved := make(map[string]bool)
+	for _, entry := range input.LicenseIDs {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		expr, err := spdx.ParseExpression(entry)
+		if err != nil {
+			tr.log(ctx).Debug("unparseable license entry", zap.String("entry", entry), zap.Error(err))
+			if !unresolved[entry] {
+				unresolved[entry] = true
+				output.Unresolved = append(output.Unresolved, entry)
+			}
+			continue
+		}
+
+		// Count each category once per entry
+		seen := make(map[string]bool)
+		for _, id := range expr.Licenses() {
+			info, err := tr.spdxClient.GetLicense(ctx, id)
+			if err != nil {
+				if !unresolved[id] {
+					unresolved[id] = true
+					output.Unresolved = append(output.Unresolved, id)
+				}
+				continue
+			}
+			output.Licenses[info.ID] = info
+			if info.Category != "" && !seen[info.Category] {
+				seen[info.Category] = true
+				output.Categories[info.Category]++
+			}
+		}
+	}
+
+	result, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to format output: %v", err)}},
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(result)}},
+	}, nil
+}
+
+// ValidateExpressionInput defines input for license.validate_expression tool
+type ValidateExpressionInput struct {
+	Expression string `json:"expression"`
+}
+
+// HandleValidateExpression implements the license.validate_expression tool
+// Example: {"expression": "MIT OR (Apache-2.0 AND BSD-3-Clause)"}
+func (tr *ToolRegistry) HandleValidateExpression(ctx context.Context, input ValidateExpressionInput) (*spdx.ExpressionValidation, error) {
+	if strings.TrimSpace(input.Expression) == "" {
+		return nil, fmt.Errorf("%w: expression is required", errInvalidInput)
+	}
+	tr.log(ctx).Info("Handling license expression validation", zap.String("expression", input.Expression))
+
+	return tr.spdxClient.ValidateExpression(input.Expression), nil
+}
+
+// DeclaredLicenses describes the licenses deps.dev lists for a package version. Separate
+// declarations all apply, so they are ANDed as in license.audit_manifest; a single declaration
+// may itself be an expression such as "MIT OR Apache-2.0".
+type DeclaredLicenses struct {
+	Expression string `json:"expression"`
+	// Relation is OR when the licensee may choose among the licenses, AND when every one of
+	// them applies, and empty for a single license
+	Relation string            `json:"relation,omitempty"`
+	Licenses []DeclaredLicense `json:"licenses,omitempty"`
+	// MostPermissive is the least restrictive option the expression allows, the one to weigh
+	// for compatibility, and Category is that option's strictest category
+	MostPermissive string `json:"most_permissive,omitempty"`
+	Category       string `json:"category"`
+}
+
+// DeclaredLicense is one license of a declaration, resolved against the SPDX data
+type DeclaredLicense struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Category string `json:"category"`
+}
+
+// declaredLicenses resolves a version's license declarations, or returns nil when it declares
+// none. A declaration that is not a valid SPDX expression is reported verbatim with an unknown
+// category.
+func (tr *ToolRegistry) declaredLicenses(ctx context.Context, licenses []string) *DeclaredLicenses {
+	joined := joinLicenses(licenses)
+	if joined == "" {
+		return nil
+	}
+	declared := &DeclaredLicenses{Expression: joined, Category: LicenseCategoryUnknown}
+
+	expr, err := spdx.ParseExpression(joined)
+	if err != nil {
+		tr.log(ctx).Debug("unparseable license declaration", zap.String("license", joined), zap.Error(err))
+		return declared
+	}
+	declared.Expression = strings.TrimSuffix(strings.TrimPrefix(expr.String(), "("), ")")
+	declared.Relation = expr.Op
+
+	seen := make(map[string]bool)
+	for _, id := range expr.Licenses() {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		license := DeclaredLicense{ID: id, Category: tr.licenseCategory(ctx, id)}
+		if info, err := tr.spdxClient.GetLicense(ctx, id); err == nil {
+			license.Name = info.Name
+		}
+		declared.Licenses = append(declared.Licenses, license)
+	}
+
+	choice := tr.spdxClient.PermissiveChoice(expr)
+	declared.MostPermissive = choice.License
+	if !choice.Unknown {
+		declared.Category = choice.Category
+	}
+	return declared
+}
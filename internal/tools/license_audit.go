@@ -0,0 +1,293 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rayprogramming/PackagePulse/internal/manifest"
+	"github.com/rayprogramming/PackagePulse/internal/pool"
+	"github.com/rayprogramming/PackagePulse/internal/providers/spdx"
+	"go.uber.org/zap"
+)
+
+// licenseAuditConcurrency bounds how many packages license.audit_manifest resolves at once
+const licenseAuditConcurrency = 8
+
+// License categories reported when the SPDX dataset cannot classify a declaration
+const (
+	LicenseCategoryUnknown = "Unknown"
+	LicenseCategoryMixed   = "Mixed"
+)
+
+// LicensePolicy lists what an audited dependency may not be licensed under.
+// Categories and license IDs match case-insensitively.
+type LicensePolicy struct {
+	DenyCategories []string `json:"deny_categories,omitempty"`
+	DenyLicenses   []string `json:"deny_licenses,omitempty"`
+	DenyUnknown    bool     `json:"deny_unknown,omitempty"`
+}
+
+// LicenseAuditInput defines input for license.audit_manifest tool
+type LicenseAuditInput struct {
+	Filename    string        `json:"filename"`
+	Content     string        `json:"content"`
+	RuntimeOnly bool          `json:"runtime_only,omitempty"`
+	Policy      LicensePolicy `json:"policy"`
+	// DryRun reports the planned upstream calls instead of making them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// PackageLicense is the resolved license of one dependency and its policy verdict
+type PackageLicense struct {
+	Ecosystem  string   `json:"ecosystem"`
+	Package    string   `json:"package"`
+	Version    string   `json:"version"`
+	License    string   `json:"license,omitempty"`
+	Licenses   []string `json:"licenses,omitempty"`
+	Category   string   `json:"category"`
+	Allowed    bool     `json:"allowed"`
+	Violations []string `json:"violations,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// LicenseAuditOutput contains per-dependency licenses and the policy violations across a manifest
+type LicenseAuditOutput struct {
+	Format          string                `json:"format"`
+	DependencyCount int                   `json:"dependency_count"`
+	PackageCount    int                   `json:"package_count"`
+	Compliant       bool                  `json:"compliant"`
+	ViolationCount  int                   `json:"violation_count"`
+	Categories      map[string]int        `json:"categories"`
+	Packages        []*PackageLicense     `json:"packages"`
+	Unresolved      []manifest.Dependency `json:"unresolved,omitempty"`
+	// Plan is set instead of packages on a dry run
+	Plan *DryRunPlan `json:"plan,omitempty"`
+}
+
+// HandleLicenseAudit parses a manifest, resolves each distinct dependency's declared license
+// from deps.dev version metadata, and evaluates it against the policy.
+// Violating packages are listed first. With dry_run only the planned package lookups are returned.
+// Example: {"filename": "package-lock.json", "content": "...", "policy": {"deny_categories": ["Copyleft"]}}
+func (tr *ToolRegistry) HandleLicenseAudit(ctx context.Context, input LicenseAuditInput) (*LicenseAuditOutput, error) {
+	if input.Filename == "" || input.Content == "" {
+		return nil, fmt.Errorf("filename and content are required")
+	}
+
+	m, err := manifest.Parse(input.Filename, []byte(input.Content), manifest.Options{
+		RuntimeOnly: input.RuntimeOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Dedupe repeated ecosystem/name/version entries
+	seen := make(map[string]bool)
+	var deps []manifest.Dependency
+	for _, dep := range m.Dependencies {
+		key := dep.Ecosystem + "/" + dep.Name + "@" + dep.Version
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		deps = append(deps, dep)
+	}
+
+	tr.log(ctx).Info("Handling manifest license audit",
+		zap.String("format", m.Format),
+		zap.Int("dependencies", len(m.Dependencies)),
+		zap.Int("packages", len(deps)),
+		zap.Bool("dry_run", input.DryRun))
+
+	if input.DryRun {
+		return &LicenseAuditOutput{
+			Format:          m.Format,
+			DependencyCount: len(m.Dependencies),
+			PackageCount:    len(deps),
+			Categories:      map[string]int{},
+			Packages:        []*PackageLicense{},
+			Unresolved:      m.Unresolved,
+			Plan:            tr.planLicenseAudit(deps),
+		}, nil
+	}
+
+	audited, err := pool.Map(ctx, deps, licenseAuditConcurrency, func(ctx context.Context, dep manifest.Dependency) (*PackageLicense, error) {
+		return tr.auditPackageLicense(ctx, dep, input.Policy), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*PackageLicense, len(audited))
+	for i, r := range audited {
+		results[i] = r.Value
+	}
+
+	output := &LicenseAuditOutput{
+		Format:          m.Format,
+		DependencyCount: len(m.Dependencies),
+		PackageCount:    len(deps),
+		Compliant:       true,
+		Categories:      make(map[string]int),
+		Packages:        results,
+		Unresolved:      m.Unresolved,
+	}
+	if output.Packages == nil {
+		output.Packages = []*PackageLicense{}
+	}
+	for _, pkg := range results {
+		output.Categories[pkg.Category]++
+		if !pkg.Allowed {
+			output.Compliant = false
+			output.ViolationCount++
+		}
+	}
+
+	sort.SliceStable(output.Packages, func(i, j int) bool {
+		a, b := output.Packages[i], output.Packages[j]
+		if a.Allowed != b.Allowed {
+			return !a.Allowed
+		}
+		return a.Package < b.Package
+	})
+
+	return output, nil
+}
+
+// auditPackageLicense resolves one dependency's license and applies the policy.
+// deps.dev may list several declarations for a version; all of them apply, so they are ANDed.
+func (tr *ToolRegistry) auditPackageLicense(ctx context.Context, dep manifest.Dependency, policy LicensePolicy) *PackageLicense {
+	result := &PackageLicense{
+		Ecosystem: dep.Ecosystem,
+		Package:   dep.Name,
+		Version:   dep.Version,
+		Category:  LicenseCategoryUnknown,
+	}
+
+	metrics, err := tr.packageHealth(ctx, dep.Ecosystem, dep.Name, dep.Version)
+	if err != nil {
+		tr.log(ctx).Warn("license lookup failed",
+			zap.String("package", dep.Name),
+			zap.Error(err))
+		result.Error = err.Error()
+		result.Allowed = !policy.DenyUnknown
+		if !result.Allowed {
+			result.Violations = []string{"license could not be resolved"}
+		}
+		return result
+	}
+
+	var declared, grouped []string
+	for _, l := range metrics.VersionLicenses {
+		if l = strings.TrimSpace(l); l != "" {
+			declared = append(declared, l)
+			grouped = append(grouped, "("+l+")")
+		}
+	}
+	if len(declared) == 0 {
+		result.Allowed = !policy.DenyUnknown
+		if !result.Allowed {
+			result.Violations = []string{"no license declared"}
+		}
+		return result
+	}
+	result.License = strings.Join(declared, " AND ")
+
+	expr, err := spdx.ParseExpression(strings.Join(grouped, " AND "))
+	if err != nil {
+		tr.log(ctx).Debug("unparseable license declaration",
+			zap.String("package", dep.Name),
+			zap.String("license", result.License),
+			zap.Error(err))
+		result.Allowed = !policy.DenyUnknown
+		if !result.Allowed {
+			result.Violations = []string{fmt.Sprintf("%s is not a valid SPDX expression", result.License)}
+		}
+		return result
+	}
+	// Canonical form without the outer parentheses String() puts around compound expressions
+	result.License = strings.TrimSuffix(strings.TrimPrefix(expr.String(), "("), ")")
+
+	categories := make(map[string]bool)
+	for _, id := range expr.Licenses() {
+		result.Licenses = appendUnique(result.Licenses, id)
+		categories[tr.licenseCategory(ctx, id)] = true
+	}
+	if len(categories) == 1 {
+		for c := range categories {
+			result.Category = c
+		}
+	} else {
+		result.Category = LicenseCategoryMixed
+	}
+
+	result.Allowed, result.Violations = tr.evaluateLicense(ctx, expr, policy)
+	return result
+}
+
+// evaluateLicense applies the policy to an expression. An AND requires both operands to be
+// allowed; an OR is satisfied when either operand is, since the licensee may pick one.
+func (tr *ToolRegistry) evaluateLicense(ctx context.Context, expr *spdx.Expression, policy LicensePolicy) (bool, []string) {
+	if !expr.IsLeaf() {
+		leftOK, leftReasons := tr.evaluateLicense(ctx, expr.Left, policy)
+		rightOK, rightReasons := tr.evaluateLicense(ctx, expr.Right, policy)
+		if expr.Op == spdx.OpOr && (leftOK || rightOK) {
+			return true, nil
+		}
+		return leftOK && rightOK, append(leftReasons, rightReasons...)
+	}
+
+	for _, denied := range policy.DenyLicenses {
+		if strings.EqualFold(denied, expr.License) {
+			return false, []string{fmt.Sprintf("%s is denied by policy", expr.License)}
+		}
+	}
+
+	category := tr.licenseCategory(ctx, expr.License)
+	if category == LicenseCategoryUnknown {
+		if policy.DenyUnknown {
+			return false, []string{fmt.Sprintf("%s is not a recognized SPDX license", expr.License)}
+		}
+		return true, nil
+	}
+	for _, denied := range policy.DenyCategories {
+		if strings.EqualFold(denied, category) {
+			return false, []string{fmt.Sprintf("%s is %s, which the policy denies", expr.License, category)}
+		}
+	}
+	return true, nil
+}
+
+// licenseCategory looks up a license's category, or LicenseCategoryUnknown
+func (tr *ToolRegistry) licenseCategory(ctx context.Context, id string) string {
+	if info, ok := tr.lookupLicense(ctx, id); ok && info.Category != "" {
+		return info.Category
+	}
+	return LicenseCategoryUnknown
+}
+
+// lookupLicense finds a license in the SPDX dataset, retrying without the SPDX -only / -or-later
+// suffix since the embedded dataset keys GPL-family licenses by their base identifier
+func (tr *ToolRegistry) lookupLicense(ctx context.Context, id string) (*spdx.LicenseInfo, bool) {
+	candidates := []string{id}
+	for _, suffix := range []string{"-only", "-or-later"} {
+		if base, ok := strings.CutSuffix(id, suffix); ok {
+			candidates = append(candidates, base)
+		}
+	}
+	for _, candidate := range candidates {
+		if info, err := tr.spdxClient.GetLicense(ctx, candidate); err == nil && info != nil {
+			return info, true
+		}
+	}
+	return nil, false
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, existing := range list {
+		if existing == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
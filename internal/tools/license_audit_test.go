@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/spdx"
+)
+
+func TestLicenseAudit_DeniesCopyleft(t *testing.T) {
+	content, err := os.ReadFile("../manifest/testdata/Cargo.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	crate := func(name, version string, licenses ...string) *depsdev.PackageInfo {
+		return &depsdev.PackageInfo{
+			PackageKey: depsdev.PackageKey{System: "CARGO", Name: name},
+			Versions: []depsdev.VersionInfo{
+				{VersionKey: depsdev.VersionKey{Version: version}, Licenses: licenses, IsDefault: true},
+			},
+		}
+	}
+	mock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"cargo/serde":    crate("serde", "1.0.188", "MIT OR Apache-2.0"),
+		"cargo/smallvec": crate("smallvec", "1.6.0", "GPL-3.0-only"),
+	})
+
+	registry := newTestRegistry(t)
+	registry.depsDevClient = mock.client()
+
+	input := LicenseAuditInput{
+		Filename: "Cargo.lock",
+		Content:  string(content),
+		Policy:   LicensePolicy{DenyCategories: []string{"copyleft", "Strong Copyleft"}},
+	}
+	result, err := registry.HandleLicenseAudit(context.Background(), input)
+	if err != nil {
+		t.Fatalf("HandleLicenseAudit() error = %v", err)
+	}
+
+	if result.Compliant || result.ViolationCount != 1 || result.PackageCount != 2 {
+		t.Fatalf("result = %+v, want one violation across two packages", result)
+	}
+	gpl := result.Packages[0]
+	if gpl.Package != "smallvec" || gpl.Allowed || gpl.Category != "Copyleft" || len(gpl.Violations) != 1 {
+		t.Errorf("first package = %+v, want smallvec denied as Copyleft", gpl)
+	}
+	serde := result.Packages[1]
+	if !serde.Allowed || serde.License != "MIT OR Apache-2.0" || serde.Category != "Permissive" {
+		t.Errorf("serde = %+v, want allowed permissive dual license", serde)
+	}
+	if result.Categories["Copyleft"] != 1 || result.Categories["Permissive"] != 1 {
+		t.Errorf("Categories = %v, want one Copyleft and one Permissive", result.Categories)
+	}
+
+	// Without a policy the audit only reports licenses
+	input.Policy = LicensePolicy{}
+	result, err = registry.HandleLicenseAudit(context.Background(), input)
+	if err != nil {
+		t.Fatalf("HandleLicenseAudit() error = %v", err)
+	}
+	if !result.Compliant {
+		t.Errorf("result = %+v, want compliant with an empty policy", result)
+	}
+}
+
+func TestEvaluateLicense(t *testing.T) {
+	registry := newTestRegistry(t)
+	policy := LicensePolicy{DenyCategories: []string{"Copyleft"}, DenyLicenses: []string{"AGPL-3.0"}}
+
+	tests := []struct {
+		expr    string
+		allowed bool
+	}{
+		{"MIT", true},
+		{"GPL-3.0", false},
+		{"MIT OR GPL-3.0", true},
+		{"MIT AND GPL-3.0", false},
+		{"AGPL-3.0", false},
+		{"LicenseRef-Proprietary", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := spdx.ParseExpression(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseExpression() error = %v", err)
+			}
+			allowed, reasons := registry.evaluateLicense(context.Background(), expr, policy)
+			if allowed != tt.allowed {
+				t.Errorf("allowed = %v (%v), want %v", allowed, reasons, tt.allowed)
+			}
+			if !allowed && len(reasons) == 0 {
+				t.Error("expected a reason for a denied expression")
+			}
+		})
+	}
+
+	policy.DenyUnknown = true
+	expr, _ := spdx.ParseExpression("LicenseRef-Proprietary")
+	if allowed, _ := registry.evaluateLicense(context.Background(), expr, policy); allowed {
+		t.Error("expected unknown license to be denied with deny_unknown")
+	}
+}
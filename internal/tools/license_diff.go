@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/spdx"
+	"go.uber.org/zap"
+)
+
+// Directions of a license change reported by license.version_diff
+const (
+	LicenseChangeMorePermissive = "more_permissive"
+	LicenseChangeLessPermissive = "less_permissive"
+	// LicenseChangeLateral is a different license in the same category, e.g. MIT to BSD-3-Clause
+	LicenseChangeLateral = "lateral"
+	// LicenseChangeUnknown means either side has no recognized category, as with a
+	// commercial or non-standard license, or declares no license at all
+	LicenseChangeUnknown = "unknown"
+)
+
+// LicenseDiffInput defines input for license.version_diff tool
+type LicenseDiffInput struct {
+	Ecosystem   string `json:"ecosystem"`
+	Package     string `json:"package"`
+	FromVersion string `json:"from_version"`
+	ToVersion   string `json:"to_version"`
+}
+
+// LicenseDiffOutput compares the licenses two versions of a package declare
+type LicenseDiffOutput struct {
+	Package     string `json:"package"`
+	Ecosystem   string `json:"ecosystem"`
+	FromVersion string `json:"from_version"`
+	ToVersion   string `json:"to_version"`
+	// From and To are each version's declared licenses, nil when it declares none
+	From    *DeclaredLicenses `json:"from"`
+	To      *DeclaredLicenses `json:"to"`
+	Changed bool              `json:"changed"`
+	// Direction compares the most permissive option of each side; set only when Changed
+	Direction string `json:"direction,omitempty"`
+	// Flagged marks a change that needs review before upgrading: one toward a less permissive
+	// or unrecognized license
+	Flagged bool   `json:"flagged"`
+	Summary string `json:"summary"`
+}
+
+// HandleLicenseDiff implements license.version_diff: whether a package was relicensed between
+// two versions, and whether the new terms are more or less permissive
+// Example: {"ecosystem": "npm", "package": "widget", "from_version": "1.0.0", "to_version": "2.0.0"}
+func (tr *ToolRegistry) HandleLicenseDiff(ctx context.Context, input LicenseDiffInput) (*LicenseDiffOutput, error) {
+	input.FromVersion = strings.TrimSpace(input.FromVersion)
+	input.ToVersion = strings.TrimSpace(input.ToVersion)
+	if input.Package == "" || input.FromVersion == "" || input.ToVersion == "" {
+		return nil, fmt.Errorf("%w: package, from_version, and to_version are required", errInvalidInput)
+	}
+	ecosystem, err := validateEcosystem(input.Ecosystem)
+	if err != nil {
+		return nil, err
+	}
+	ecosystem, name := tr.normalizePackage(ctx, ecosystem, input.Package)
+
+	tr.log(ctx).Info("Handling license diff request",
+		zap.String("ecosystem", ecosystem),
+		zap.String("package", name),
+		zap.String("from_version", input.FromVersion),
+		zap.String("to_version", input.ToVersion))
+
+	pkgInfo, err := tr.getPackageInfo(ctx, ecosystem, name)
+	if err != nil {
+		return nil, fmt.Errorf("query package versions: %w", tr.explainNotFound(ctx, ecosystem, name, err))
+	}
+	fromLicenses, err := versionLicenses(pkgInfo, input.FromVersion)
+	if err != nil {
+		return nil, err
+	}
+	toLicenses, err := versionLicenses(pkgInfo, input.ToVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &LicenseDiffOutput{
+		Package:     name,
+		Ecosystem:   ecosystem,
+		FromVersion: input.FromVersion,
+		ToVersion:   input.ToVersion,
+		From:        tr.declaredLicenses(ctx, fromLicenses),
+		To:          tr.declaredLicenses(ctx, toLicenses),
+	}
+	output.Changed = licenseExpression(output.From) != licenseExpression(output.To)
+	if output.Changed {
+		output.Direction = licenseChangeDirection(output.From, output.To)
+		output.Flagged = output.Direction == LicenseChangeLessPermissive || output.Direction == LicenseChangeUnknown
+	}
+	output.Summary = licenseDiffSummary(output)
+	return output, nil
+}
+
+// versionLicenses returns the license declarations of one version of a package
+func versionLicenses(pkg *depsdev.PackageInfo, version string) ([]string, error) {
+	for _, v := range pkg.Versions {
+		if v.VersionKey.Version == version {
+			return v.Licenses, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: version not found: %s/%s@%s", errInvalidInput, pkg.PackageKey.System, pkg.PackageKey.Name, version)
+}
+
+// licenseExpression is the canonical expression of a declaration, "" when there is none
+func licenseExpression(declared *DeclaredLicenses) string {
+	if declared == nil {
+		return ""
+	}
+	return declared.Expression
+}
+
+// licenseChangeDirection compares the most permissive option of two declarations by category
+func licenseChangeDirection(from, to *DeclaredLicenses) string {
+	if from == nil || to == nil {
+		return LicenseChangeUnknown
+	}
+	c, ok := spdx.ComparePermissiveness(from.Category, to.Category)
+	switch {
+	case !ok:
+		return LicenseChangeUnknown
+	case c < 0:
+		return LicenseChangeLessPermissive
+	case c > 0:
+		return LicenseChangeMorePermissive
+	}
+	return LicenseChangeLateral
+}
+
+func licenseDiffSummary(output *LicenseDiffOutput) string {
+	describe := func(declared *DeclaredLicenses) string {
+		if declared == nil {
+			return "no declared license"
+		}
+		return fmt.Sprintf("%s (%s)", declared.Expression, declared.Category)
+	}
+	if !output.Changed {
+		return fmt.Sprintf("License unchanged between %s and %s: %s", output.FromVersion, output.ToVersion, describe(output.From))
+	}
+	summary := fmt.Sprintf("License changed from %s in %s to %s in %s",
+		describe(output.From), output.FromVersion, describe(output.To), output.ToVersion)
+	switch output.Direction {
+	case LicenseChangeLessPermissive:
+		summary += "; the new license is less permissive, review it before upgrading"
+	case LicenseChangeUnknown:
+		summary += "; the change could not be classified, review both licenses before upgrading"
+	case LicenseChangeMorePermissive:
+		summary += "; the new license is more permissive"
+	}
+	return summary
+}
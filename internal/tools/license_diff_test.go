@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+)
+
+func TestHandleLicenseDiff(t *testing.T) {
+	release := func(version string, licenses ...string) depsdev.VersionInfo {
+		return depsdev.VersionInfo{
+			VersionKey: depsdev.VersionKey{System: "NPM", Name: "widget", Version: version},
+			Licenses:   licenses,
+		}
+	}
+	mock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"npm/widget": {
+			PackageKey: depsdev.PackageKey{System: "NPM", Name: "widget"},
+			Versions: []depsdev.VersionInfo{
+				release("1.0.0", "MIT"),
+				release("1.1.0", "MIT"),
+				release("2.0.0", "AGPL-3.0-only"),
+				release("3.0.0", "non-standard"),
+				release("4.0.0", "MIT OR Apache-2.0"),
+			},
+		},
+	})
+	registry := newTestRegistry(t)
+	registry.depsDevClient = mock.client()
+
+	tests := []struct {
+		from, to  string
+		changed   bool
+		direction string
+		flagged   bool
+	}{
+		{"1.0.0", "1.1.0", false, "", false},
+		{"1.1.0", "2.0.0", true, LicenseChangeLessPermissive, true},
+		{"2.0.0", "1.1.0", true, LicenseChangeMorePermissive, false},
+		{"1.1.0", "3.0.0", true, LicenseChangeUnknown, true},
+		{"1.1.0", "4.0.0", true, LicenseChangeLateral, false},
+	}
+	for _, tt := range tests {
+		output, err := registry.HandleLicenseDiff(context.Background(), LicenseDiffInput{
+			Ecosystem: "npm", Package: "widget", FromVersion: tt.from, ToVersion: tt.to,
+		})
+		if err != nil {
+			t.Fatalf("HandleLicenseDiff(%s -> %s) error = %v", tt.from, tt.to, err)
+		}
+		if output.Changed != tt.changed || output.Direction != tt.direction || output.Flagged != tt.flagged {
+			t.Errorf("%s -> %s = changed %v, direction %q, flagged %v; want %v, %q, %v",
+				tt.from, tt.to, output.Changed, output.Direction, output.Flagged, tt.changed, tt.direction, tt.flagged)
+		}
+	}
+
+	output, err := registry.HandleLicenseDiff(context.Background(), LicenseDiffInput{
+		Ecosystem: "npm", Package: "widget", FromVersion: "1.1.0", ToVersion: "2.0.0",
+	})
+	if err != nil {
+		t.Fatalf("HandleLicenseDiff() error = %v", err)
+	}
+	if output.From.Expression != "MIT" || output.To.Expression != "AGPL-3.0-only" {
+		t.Errorf("from %s, to %s; want MIT and AGPL-3.0-only", output.From.Expression, output.To.Expression)
+	}
+	if !strings.Contains(output.Summary, "less permissive") {
+		t.Errorf("summary = %q, want it to call out the less permissive license", output.Summary)
+	}
+
+	if _, err := registry.HandleLicenseDiff(context.Background(), LicenseDiffInput{
+		Ecosystem: "npm", Package: "widget", FromVersion: "1.1.0", ToVersion: "9.9.9",
+	}); err == nil {
+		t.Error("expected an error for a version deps.dev does not list")
+	}
+}
@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rayprogramming/PackagePulse/internal/manifest"
+	"github.com/rayprogramming/PackagePulse/internal/pool"
+	"github.com/rayprogramming/PackagePulse/internal/providers/spdx"
+	"go.uber.org/zap"
+)
+
+// OSI approval statuses reported by license.osi_approved
+const (
+	OSIStatusApproved    = "approved"
+	OSIStatusNotApproved = "not_approved"
+	// OSIStatusUnknown is a license the SPDX dataset does not know, so its approval cannot be
+	// determined; it is not evidence the license is unapproved
+	OSIStatusUnknown = "unknown"
+)
+
+// OSIApprovedInput defines input for license.osi_approved tool. Give either LicenseIDs or a
+// manifest (Filename and Content).
+type OSIApprovedInput struct {
+	LicenseIDs  []string `json:"license_ids,omitempty"`
+	Filename    string   `json:"filename,omitempty"`
+	Content     string   `json:"content,omitempty"`
+	RuntimeOnly bool     `json:"runtime_only,omitempty"`
+}
+
+// OSILicense is the approval status of one license
+type OSILicense struct {
+	License string `json:"license"`
+	Name    string `json:"name,omitempty"`
+	Status  string `json:"status"`
+	// FSFLibre is the Free Software Foundation's view, omitted for unknown licenses
+	FSFLibre *bool `json:"fsf_libre,omitempty"`
+	// Packages lists the manifest dependencies declaring the license, as ecosystem/name@version
+	Packages []string `json:"packages,omitempty"`
+}
+
+// OSIApprovedOutput splits licenses by OSI approval
+type OSIApprovedOutput struct {
+	// AllApproved is true when every license is OSI-approved; unknown licenses need review
+	AllApproved bool         `json:"all_approved"`
+	Approved    []OSILicense `json:"approved"`
+	NotApproved []OSILicense `json:"not_approved"`
+	Unknown     []OSILicense `json:"unknown"`
+	// Unlicensed lists manifest dependencies whose license could not be resolved
+	Unlicensed []string `json:"unlicensed,omitempty"`
+}
+
+// HandleOSIApproved implements license.osi_approved: which licenses, given directly or declared
+// by a manifest's dependencies, are OSI-approved. Each license in an expression is screened on
+// its own, since the screen is about the licenses in play rather than the choice among them.
+// Example: {"license_ids": ["MIT", "CC0-1.0", "GPL-2.0-only OR Apache-2.0"]}
+func (tr *ToolRegistry) HandleOSIApproved(ctx context.Context, input OSIApprovedInput) (*OSIApprovedOutput, error) {
+	hasManifest := input.Filename != "" || input.Content != ""
+	if len(input.LicenseIDs) > 0 && hasManifest {
+		return nil, fmt.Errorf("%w: give license_ids or a manifest, not both", errInvalidInput)
+	}
+	if len(input.LicenseIDs) == 0 && !hasManifest {
+		return nil, fmt.Errorf("%w: license_ids, or a manifest's filename and content, are required", errInvalidInput)
+	}
+
+	// packages maps each license entry to the dependencies declaring it
+	packages := make(map[string][]string)
+	var entries, unlicensed []string
+	if hasManifest {
+		var err error
+		if entries, unlicensed, err = tr.manifestLicenses(ctx, input, packages); err != nil {
+			return nil, err
+		}
+	} else {
+		entries = input.LicenseIDs
+	}
+	tr.log(ctx).Info("Handling OSI approval screen", zap.Int("entries", len(entries)), zap.Bool("manifest", hasManifest))
+
+	output := &OSIApprovedOutput{
+		Approved:    []OSILicense{},
+		NotApproved: []OSILicense{},
+		Unknown:     []OSILicense{},
+		Unlicensed:  unlicensed,
+	}
+	screened := make(map[string]*OSILicense)
+	var order []string
+	screen := func(id string, pkgs []string) {
+		entry, ok := screened[id]
+		if !ok {
+			entry = tr.osiStatus(ctx, id)
+			screened[id] = entry
+			order = append(order, id)
+		}
+		for _, p := range pkgs {
+			entry.Packages = appendUnique(entry.Packages, p)
+		}
+	}
+	for _, raw := range entries {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		expr, err := spdx.ParseExpression(raw)
+		if err != nil {
+			screen(raw, packages[raw])
+			continue
+		}
+		for _, id := range expr.Licenses() {
+			screen(id, packages[raw])
+		}
+	}
+
+	sort.Strings(order)
+	for _, id := range order {
+		entry := *screened[id]
+		switch entry.Status {
+		case OSIStatusApproved:
+			output.Approved = append(output.Approved, entry)
+		case OSIStatusNotApproved:
+			output.NotApproved = append(output.NotApproved, entry)
+		default:
+			output.Unknown = append(output.Unknown, entry)
+		}
+	}
+	output.AllApproved = len(output.Approved) > 0 && len(output.NotApproved) == 0 && len(output.Unknown) == 0 && len(output.Unlicensed) == 0
+	return output, nil
+}
+
+// osiStatus looks up one license's OSI approval and FSF libre status
+func (tr *ToolRegistry) osiStatus(ctx context.Context, id string) *OSILicense {
+	info, ok := tr.lookupLicense(ctx, id)
+	if !ok {
+		return &OSILicense{License: id, Status: OSIStatusUnknown}
+	}
+	status := OSIStatusNotApproved
+	if info.IsOSIApproved {
+		status = OSIStatusApproved
+	}
+	libre := info.IsFSFLibre
+	return &OSILicense{License: id, Name: info.Name, Status: status, FSFLibre: &libre}
+}
+
+// manifestLicenses resolves the declared license of every distinct dependency in a manifest,
+// returning the declarations, recording in packages which dependencies declare each, and
+// listing the dependencies without a resolvable license
+func (tr *ToolRegistry) manifestLicenses(ctx context.Context, input OSIApprovedInput, packages map[string][]string) ([]string, []string, error) {
+	if input.Filename == "" || input.Content == "" {
+		return nil, nil, fmt.Errorf("%w: filename and content are required", errInvalidInput)
+	}
+	m, err := manifest.Parse(input.Filename, []byte(input.Content), manifest.Options{RuntimeOnly: input.RuntimeOnly})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seen := make(map[string]bool)
+	var deps []manifest.Dependency
+	for _, dep := range m.Dependencies {
+		key := dep.Ecosystem + "/" + dep.Name + "@" + dep.Version
+		if !seen[key] {
+			seen[key] = true
+			deps = append(deps, dep)
+		}
+	}
+
+	resolved, err := pool.Map(ctx, deps, licenseAuditConcurrency, func(ctx context.Context, dep manifest.Dependency) (*PackageLicense, error) {
+		return tr.auditPackageLicense(ctx, dep, LicensePolicy{}), nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	var entries, unlicensed []string
+	for _, r := range resolved {
+		pkg := r.Value
+		name := pkg.Ecosystem + "/" + pkg.Package + "@" + pkg.Version
+		if pkg.License == "" {
+			unlicensed = append(unlicensed, name)
+			continue
+		}
+		if _, ok := packages[pkg.License]; !ok {
+			entries = append(entries, pkg.License)
+		}
+		packages[pkg.License] = append(packages[pkg.License], name)
+	}
+	return entries, unlicensed, nil
+}
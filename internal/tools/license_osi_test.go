@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+)
+
+func TestOSIApproved_MixedSet(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	result, err := registry.HandleOSIApproved(context.Background(), OSIApprovedInput{
+		LicenseIDs: []string{"MIT", "CC0-1.0", "Not-A-Real-License", "MIT OR Apache-2.0"},
+	})
+	if err != nil {
+		t.Fatalf("HandleOSIApproved() error = %v", err)
+	}
+
+	if result.AllApproved {
+		t.Error("AllApproved = true, want false with CC0-1.0 and an unknown license")
+	}
+	if len(result.Approved) != 2 || result.Approved[0].License != "Apache-2.0" || result.Approved[1].License != "MIT" {
+		t.Fatalf("Approved = %+v, want Apache-2.0 and MIT once each", result.Approved)
+	}
+	if mit := result.Approved[1]; mit.Status != OSIStatusApproved || mit.FSFLibre == nil || !*mit.FSFLibre {
+		t.Errorf("MIT = %+v, want approved and FSF libre", mit)
+	}
+	if len(result.NotApproved) != 1 {
+		t.Fatalf("NotApproved = %+v, want only CC0-1.0", result.NotApproved)
+	}
+	// CC0 is free software to the FSF but was never OSI-approved
+	if cc0 := result.NotApproved[0]; cc0.License != "CC0-1.0" || cc0.Status != OSIStatusNotApproved || cc0.FSFLibre == nil || !*cc0.FSFLibre {
+		t.Errorf("CC0-1.0 = %+v, want not approved and FSF libre", cc0)
+	}
+	if len(result.Unknown) != 1 {
+		t.Fatalf("Unknown = %+v, want only Not-A-Real-License", result.Unknown)
+	}
+	if unknown := result.Unknown[0]; unknown.License != "Not-A-Real-License" || unknown.Status != OSIStatusUnknown || unknown.FSFLibre != nil {
+		t.Errorf("unknown = %+v, want status unknown without an FSF status", unknown)
+	}
+
+	_, err = registry.HandleOSIApproved(context.Background(), OSIApprovedInput{})
+	if !errors.Is(err, errInvalidInput) {
+		t.Errorf("empty input error = %v, want errInvalidInput", err)
+	}
+}
+
+func TestOSIApproved_Manifest(t *testing.T) {
+	content, err := os.ReadFile("../manifest/testdata/Cargo.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	crate := func(name, version string, licenses ...string) *depsdev.PackageInfo {
+		return &depsdev.PackageInfo{
+			PackageKey: depsdev.PackageKey{System: "CARGO", Name: name},
+			Versions: []depsdev.VersionInfo{
+				{VersionKey: depsdev.VersionKey{Version: version}, Licenses: licenses, IsDefault: true},
+			},
+		}
+	}
+	mock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"cargo/serde":    crate("serde", "1.0.188", "MIT OR Apache-2.0"),
+		"cargo/smallvec": crate("smallvec", "1.6.0", "CC0-1.0"),
+	})
+	registry := newTestRegistry(t)
+	registry.depsDevClient = mock.client()
+
+	result, err := registry.HandleOSIApproved(context.Background(), OSIApprovedInput{
+		Filename: "Cargo.lock",
+		Content:  string(content),
+	})
+	if err != nil {
+		t.Fatalf("HandleOSIApproved() error = %v", err)
+	}
+
+	if len(result.Approved) != 2 || len(result.NotApproved) != 1 || len(result.Unknown) != 0 {
+		t.Fatalf("result = %+v, want MIT and Apache-2.0 approved, CC0-1.0 not", result)
+	}
+	for _, l := range result.Approved {
+		if len(l.Packages) != 1 || l.Packages[0] != "crates.io/serde@1.0.188" {
+			t.Errorf("%s packages = %v, want serde", l.License, l.Packages)
+		}
+	}
+	if cc0 := result.NotApproved[0]; len(cc0.Packages) != 1 || cc0.Packages[0] != "crates.io/smallvec@1.6.0" {
+		t.Errorf("CC0-1.0 packages = %v, want smallvec", cc0.Packages)
+	}
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestBatchLicenseHandler(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	input := BatchLicenseInput{
+		LicenseIDs: []string{
+			"MIT",
+			"GPL-3.0",
+			"Apache-2.0 OR MIT",
+			"LGPL-3.0 AND BSD-3-Clause",
+			"Totally-Made-Up-1.0",
+		},
+	}
+
+	result, err := registry.HandleBatchLicense(context.Background(), input)
+	if err != nil {
+		t.Fatalf("HandleBatchLicense() unexpected error: %v", err)
+	}
+	text := resultText(t, result)
+	if result.IsError {
+		t.Fatalf("HandleBatchLicense() returned error result: %s", text)
+	}
+
+	var output BatchLicenseOutput
+	if err := json.Unmarshal([]byte(text), &output); err != nil {
+		t.Fatalf("Failed to parse output JSON: %v", err)
+	}
+
+	for _, id := range []string{"MIT", "GPL-3.0", "Apache-2.0", "LGPL-3.0", "BSD-3-Clause"} {
+		if _, ok := output.Licenses[id]; !ok {
+			t.Errorf("expected %s to be resolved", id)
+		}
+	}
+
+	if len(output.Unresolved) != 1 || output.Unresolved[0] != "Totally-Made-Up-1.0" {
+		t.Errorf("Unresolved = %v, want [Totally-Made-Up-1.0]", output.Unresolved)
+	}
+
+	// MIT, Apache-2.0 OR MIT (counted once), and the BSD half of the compound entry
+	if got := output.Categories["Permissive"]; got != 3 {
+		t.Errorf("Permissive count = %d, want 3", got)
+	}
+	if got := output.Categories["Copyleft"]; got != 1 {
+		t.Errorf("Copyleft count = %d, want 1", got)
+	}
+	if got := output.Categories["Weak Copyleft"]; got != 1 {
+		t.Errorf("Weak Copyleft count = %d, want 1", got)
+	}
+}
+
+func TestBatchLicenseHandler_EmptyInput(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	result, err := registry.HandleBatchLicense(context.Background(), BatchLicenseInput{})
+	if err != nil {
+		t.Fatalf("HandleBatchLicense() unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("expected error result for empty license_ids")
+	}
+}
+
+func TestValidateExpressionHandler(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	result, err := registry.HandleValidateExpression(context.Background(), ValidateExpressionInput{Expression: "Apache-2.0 AND (MIT OR ISC)"})
+	if err != nil {
+		t.Fatalf("HandleValidateExpression() error = %v", err)
+	}
+	if !result.Valid || result.Canonical != "(Apache-2.0 AND (MIT OR ISC))" {
+		t.Errorf("result = %+v, want a valid expression", result)
+	}
+
+	if _, err := registry.HandleValidateExpression(context.Background(), ValidateExpressionInput{}); !errors.Is(err, errInvalidInput) {
+		t.Errorf("HandleValidateExpression() error = %v, want INVALID_INPUT for an empty expression", err)
+	}
+}
@@ -0,0 +1,225 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rayprogramming/PackagePulse/internal/pool"
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/spdx"
+	"go.uber.org/zap"
+)
+
+// TreeConflictsInput defines input for license.tree_conflicts tool
+type TreeConflictsInput struct {
+	Ecosystem string `json:"ecosystem"`
+	Package   string `json:"package"`
+	Version   string `json:"version,omitempty"`
+	// ProjectLicense overrides the license the package itself declares
+	ProjectLicense string `json:"project_license,omitempty"`
+}
+
+// TreeDependency is one transitive dependency and its license verdict against the project
+type TreeDependency struct {
+	Package  string `json:"package"`
+	Version  string `json:"version"`
+	Relation string `json:"relation"`
+	// Path is the shortest chain of name@version from the root package to this dependency
+	Path []string `json:"path"`
+	// License is everything the dependency declares; ChosenLicense the option the verdict uses
+	License       string `json:"license,omitempty"`
+	ChosenLicense string `json:"chosen_license,omitempty"`
+	Category      string `json:"category,omitempty"`
+	Compatible    bool   `json:"compatible"`
+	Reason        string `json:"reason,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// ConflictCluster groups the dependencies that conflict with the project through one license
+type ConflictCluster struct {
+	License      string   `json:"license"`
+	Category     string   `json:"category,omitempty"`
+	Reason       string   `json:"reason"`
+	Dependencies []string `json:"dependencies"`
+}
+
+// TreeConflictsOutput reports license conflicts across a package's transitive dependencies
+type TreeConflictsOutput struct {
+	Ecosystem       string            `json:"ecosystem"`
+	Package         string            `json:"package"`
+	Version         string            `json:"version"`
+	ProjectLicense  string            `json:"project_license"`
+	DependencyCount int               `json:"dependency_count"`
+	ConflictCount   int               `json:"conflict_count"`
+	Compatible      bool              `json:"compatible"`
+	Clusters        []ConflictCluster `json:"clusters"`
+	Conflicts       []*TreeDependency `json:"conflicts"`
+	// Unknown lists dependencies whose license could not be resolved or classified
+	Unknown []*TreeDependency `json:"unknown,omitempty"`
+}
+
+// HandleTreeConflicts resolves a package's transitive dependency graph from deps.dev, looks up
+// every dependency's declared license, and reports those that cannot be distributed under the
+// project license, grouped by the offending license with their dependency paths.
+// Example: {"ecosystem": "npm", "package": "my-app", "version": "1.0.0", "project_license": "Apache-2.0"}
+func (tr *ToolRegistry) HandleTreeConflicts(ctx context.Context, input TreeConflictsInput) (*TreeConflictsOutput, error) {
+	if input.Ecosystem == "" || input.Package == "" {
+		return nil, fmt.Errorf("%w: ecosystem and package are required", errInvalidInput)
+	}
+	ecosystem, err := validateEcosystem(input.Ecosystem)
+	if err != nil {
+		return nil, err
+	}
+	ecosystem, name := tr.normalizePackage(ctx, ecosystem, input.Package)
+
+	// The root's metrics supply the default version and the declared project license
+	root, err := tr.packageHealth(ctx, ecosystem, name, input.Version)
+	if err != nil {
+		return nil, err
+	}
+	version := input.Version
+	if version == "" {
+		version = root.LatestVersion
+	}
+
+	projectLicense := input.ProjectLicense
+	if projectLicense == "" {
+		if root.VersionLicenses == nil {
+			// packageHealth without a version has no version licenses; look up the resolved one
+			if root, err = tr.packageHealth(ctx, ecosystem, name, version); err != nil {
+				return nil, err
+			}
+		}
+		projectLicense = joinLicenses(root.VersionLicenses)
+		if projectLicense == "" {
+			return nil, fmt.Errorf("%w: %s@%s declares no license; set project_license", errInvalidInput, name, version)
+		}
+	}
+	project, err := spdx.ParseExpression(projectLicense)
+	if err != nil {
+		return nil, fmt.Errorf("%w: project license: %v", errInvalidInput, err)
+	}
+
+	tr.log(ctx).Info("Handling license tree conflicts",
+		zap.String("ecosystem", ecosystem),
+		zap.String("package", name),
+		zap.String("version", version),
+		zap.String("project_license", projectLicense))
+
+	graph, err := tr.depsDevClient.GetDependencies(ctx, ecosystem, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := graph.Paths()
+	nodes := make([]int, 0, len(graph.Nodes)-1)
+	for i := 1; i < len(graph.Nodes); i++ {
+		nodes = append(nodes, i)
+	}
+	checked, err := pool.Map(ctx, nodes, licenseAuditConcurrency, func(ctx context.Context, i int) (*TreeDependency, error) {
+		return tr.checkTreeDependency(ctx, ecosystem, graph, paths[i], i, project), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	output := &TreeConflictsOutput{
+		Ecosystem:       ecosystem,
+		Package:         name,
+		Version:         version,
+		ProjectLicense:  strings.TrimSuffix(strings.TrimPrefix(project.String(), "("), ")"),
+		DependencyCount: len(nodes),
+		Clusters:        []ConflictCluster{},
+		Conflicts:       []*TreeDependency{},
+	}
+	clusters := make(map[string]*ConflictCluster)
+	for _, r := range checked {
+		dep := r.Value
+		switch {
+		case dep.Error != "" || (dep.Compatible && dep.Reason != ""):
+			output.Unknown = append(output.Unknown, dep)
+		case !dep.Compatible:
+			output.Conflicts = append(output.Conflicts, dep)
+			cluster, ok := clusters[dep.ChosenLicense]
+			if !ok {
+				cluster = &ConflictCluster{License: dep.ChosenLicense, Category: dep.Category, Reason: dep.Reason}
+				clusters[dep.ChosenLicense] = cluster
+			}
+			cluster.Dependencies = append(cluster.Dependencies, dep.Package+"@"+dep.Version)
+		}
+	}
+	for _, cluster := range clusters {
+		sort.Strings(cluster.Dependencies)
+		output.Clusters = append(output.Clusters, *cluster)
+	}
+	sort.Slice(output.Clusters, func(i, j int) bool {
+		return output.Clusters[i].License < output.Clusters[j].License
+	})
+	sort.SliceStable(output.Conflicts, func(i, j int) bool {
+		return len(output.Conflicts[i].Path) < len(output.Conflicts[j].Path)
+	})
+	output.ConflictCount = len(output.Conflicts)
+	output.Compatible = output.ConflictCount == 0
+
+	return output, nil
+}
+
+// checkTreeDependency resolves one graph node's declared license and checks it against the project
+func (tr *ToolRegistry) checkTreeDependency(ctx context.Context, ecosystem string, graph *depsdev.DependencyGraph, path []int, i int, project *spdx.Expression) *TreeDependency {
+	node := graph.Nodes[i]
+	dep := &TreeDependency{
+		Package:    node.VersionKey.Name,
+		Version:    node.VersionKey.Version,
+		Relation:   node.Relation,
+		Compatible: true,
+	}
+	for _, n := range path {
+		key := graph.Nodes[n].VersionKey
+		dep.Path = append(dep.Path, key.Name+"@"+key.Version)
+	}
+
+	metrics, err := tr.packageHealth(ctx, ecosystem, dep.Package, dep.Version)
+	if err != nil {
+		tr.log(ctx).Warn("license lookup failed",
+			zap.String("package", dep.Package),
+			zap.Error(err))
+		dep.Error = err.Error()
+		return dep
+	}
+	dep.License = joinLicenses(metrics.VersionLicenses)
+	if dep.License == "" {
+		dep.Error = "no license declared"
+		return dep
+	}
+	expr, err := spdx.ParseExpression(dep.License)
+	if err != nil {
+		dep.Error = fmt.Sprintf("%s is not a valid SPDX expression", dep.License)
+		return dep
+	}
+
+	verdict := tr.spdxClient.CheckCompatibility(project, expr)
+	dep.ChosenLicense = verdict.License
+	dep.Category = verdict.Category
+	dep.Compatible = verdict.Compatible
+	dep.Reason = verdict.Reason
+	return dep
+}
+
+// joinLicenses ANDs the license declarations deps.dev lists for a version, since all of them apply
+func joinLicenses(licenses []string) string {
+	var grouped []string
+	for _, l := range licenses {
+		if l = strings.TrimSpace(l); l != "" {
+			grouped = append(grouped, l)
+		}
+	}
+	if len(grouped) == 1 {
+		return grouped[0]
+	}
+	for i, l := range grouped {
+		grouped[i] = "(" + l + ")"
+	}
+	return strings.Join(grouped, " AND ")
+}
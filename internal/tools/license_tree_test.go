@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+)
+
+// treeNode builds a deps.dev graph node for an npm package version
+func treeNode(name, version, relation string) depsdev.DependencyNode {
+	return depsdev.DependencyNode{
+		VersionKey: depsdev.VersionKey{System: "NPM", Name: name, Version: version},
+		Relation:   relation,
+	}
+}
+
+func TestHandleTreeConflicts_CopyleftLeaf(t *testing.T) {
+	pkg := func(name, version string, licenses ...string) *depsdev.PackageInfo {
+		return &depsdev.PackageInfo{
+			PackageKey: depsdev.PackageKey{System: "NPM", Name: name},
+			Versions: []depsdev.VersionInfo{
+				{VersionKey: depsdev.VersionKey{Version: version}, Licenses: licenses, IsDefault: true},
+			},
+		}
+	}
+	// app -> web -> parser -> gpl-leaf, and app -> dual (GPL-3.0 OR MIT) and app -> lgpl
+	mock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"npm/app":      pkg("app", "1.0.0", "Apache-2.0"),
+		"npm/web":      pkg("web", "2.1.0", "MIT"),
+		"npm/parser":   pkg("parser", "0.3.0", "ISC"),
+		"npm/gpl-leaf": pkg("gpl-leaf", "1.2.3", "GPL-3.0-only"),
+		"npm/dual":     pkg("dual", "4.0.0", "GPL-3.0-or-later OR MIT"),
+		"npm/lgpl":     pkg("lgpl", "1.0.0", "LGPL-3.0-only"),
+	})
+	mock.graphs = map[string]*depsdev.DependencyGraph{
+		"npm/app@1.0.0": {
+			Nodes: []depsdev.DependencyNode{
+				treeNode("app", "1.0.0", depsdev.RelationSelf),
+				treeNode("web", "2.1.0", depsdev.RelationDirect),
+				treeNode("parser", "0.3.0", depsdev.RelationIndirect),
+				treeNode("gpl-leaf", "1.2.3", depsdev.RelationIndirect),
+				treeNode("dual", "4.0.0", depsdev.RelationDirect),
+				treeNode("lgpl", "1.0.0", depsdev.RelationDirect),
+			},
+			Edges: []depsdev.DependencyEdge{
+				{FromNode: 0, ToNode: 1}, {FromNode: 1, ToNode: 2}, {FromNode: 2, ToNode: 3},
+				{FromNode: 0, ToNode: 4}, {FromNode: 0, ToNode: 5},
+			},
+		},
+	}
+
+	registry := newTestRegistry(t)
+	registry.depsDevClient = mock.client()
+
+	result, err := registry.HandleTreeConflicts(context.Background(), TreeConflictsInput{
+		Ecosystem: "npm",
+		Package:   "app",
+		Version:   "1.0.0",
+	})
+	if err != nil {
+		t.Fatalf("HandleTreeConflicts() error = %v", err)
+	}
+
+	if result.ProjectLicense != "Apache-2.0" || result.DependencyCount != 5 {
+		t.Errorf("result = %+v, want Apache-2.0 project with 5 dependencies", result)
+	}
+	if result.Compatible || result.ConflictCount != 1 || len(result.Clusters) != 1 {
+		t.Fatalf("result = %+v, want exactly one conflict", result)
+	}
+	conflict := result.Conflicts[0]
+	wantPath := []string{"app@1.0.0", "web@2.1.0", "parser@0.3.0", "gpl-leaf@1.2.3"}
+	if conflict.Package != "gpl-leaf" || len(conflict.Path) != len(wantPath) {
+		t.Fatalf("conflict = %+v, want gpl-leaf via %v", conflict, wantPath)
+	}
+	for i := range wantPath {
+		if conflict.Path[i] != wantPath[i] {
+			t.Errorf("path = %v, want %v", conflict.Path, wantPath)
+			break
+		}
+	}
+	cluster := result.Clusters[0]
+	if cluster.License != "GPL-3.0-only" || cluster.Category != "Copyleft" || len(cluster.Dependencies) != 1 {
+		t.Errorf("cluster = %+v, want GPL-3.0-only with one dependency", cluster)
+	}
+	if len(result.Unknown) != 0 {
+		t.Errorf("unknown = %+v, want none", result.Unknown)
+	}
+
+	// A GPL-3.0 project absorbs the copyleft leaf
+	result, err = registry.HandleTreeConflicts(context.Background(), TreeConflictsInput{
+		Ecosystem:      "npm",
+		Package:        "app",
+		Version:        "1.0.0",
+		ProjectLicense: "GPL-3.0-or-later",
+	})
+	if err != nil {
+		t.Fatalf("HandleTreeConflicts() error = %v", err)
+	}
+	if !result.Compatible {
+		t.Errorf("conflicts = %+v, want none under GPL-3.0-or-later", result.Conflicts)
+	}
+
+	_, err = registry.HandleTreeConflicts(context.Background(), TreeConflictsInput{Ecosystem: "npm"})
+	if !errors.Is(err, errInvalidInput) {
+		t.Errorf("missing package error = %v, want errInvalidInput", err)
+	}
+}
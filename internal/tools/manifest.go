@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/history"
+	"github.com/rayprogramming/PackagePulse/internal/manifest"
+	"go.uber.org/zap"
+)
+
+// scanReportCacheTTL is how long a complete deps.scan_manifest report is reused for an
+// identical document
+const scanReportCacheTTL = 5 * time.Minute
+
+// ScanManifestInput defines input for deps.scan_manifest tool
+type ScanManifestInput struct {
+	Filename     string `json:"filename"`
+	Content      string `json:"content"`
+	RuntimeOnly  bool   `json:"runtime_only,omitempty"`
+	OutputFormat string `json:"output_format,omitempty"`
+	// DryRun reports the planned upstream calls instead of making them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// ScanManifestOutput contains vulnerability results for every manifest dependency
+type ScanManifestOutput struct {
+	Format          string                `json:"format"`
+	DependencyCount int                   `json:"dependency_count"`
+	Unresolved      []manifest.Dependency `json:"unresolved,omitempty"`
+	// ContentHash is the SHA-256 of the scanned document, which keys the report cache
+	ContentHash string `json:"content_hash,omitempty"`
+	BatchVulnsOutput
+	CacheStatus
+}
+
+// HandleScanManifest parses a manifest or lockfile and batch-scans its dependencies. A complete
+// report is cached by the document's content hash, so rescanning an unchanged document, as
+// repeated CI runs do, returns the prior report without any upstream calls. A changed document
+// is scanned afresh, still reusing what is cached per package, such as EPSS scores.
+// Example: {"filename": "Cargo.lock", "content": "..."}
+func (tr *ToolRegistry) HandleScanManifest(ctx context.Context, input ScanManifestInput) (*ScanManifestOutput, error) {
+	if input.Filename == "" || input.Content == "" {
+		return nil, fmt.Errorf("filename and content are required")
+	}
+
+	hash := contentHash(input.Filename, input.Content)
+	cacheKey := cacheKey("scan-manifest", "", hash, strconv.FormatBool(input.RuntimeOnly))
+	if !input.DryRun && !cacheRefreshing(ctx) {
+		if cached, status, found := tr.getCachedResponse(cacheKey); found {
+			if report, ok := cached.(*ScanManifestOutput); ok {
+				tr.log(ctx).Debug("cache hit", zap.String("key", cacheKey))
+				history.NoteCache(ctx, true)
+				hit := *report
+				hit.CacheStatus = status
+				return &hit, nil
+			}
+		}
+		history.NoteCache(ctx, false)
+	}
+
+	m, err := manifest.Parse(input.Filename, []byte(input.Content), manifest.Options{
+		RuntimeOnly: input.RuntimeOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tr.log(ctx).Info("Handling manifest scan",
+		zap.String("format", m.Format),
+		zap.Int("dependencies", len(m.Dependencies)))
+
+	output := &ScanManifestOutput{
+		Format:          m.Format,
+		DependencyCount: len(m.Dependencies),
+		Unresolved:      m.Unresolved,
+		ContentHash:     hash,
+		BatchVulnsOutput: BatchVulnsOutput{
+			Results:     []*BatchVulnsResult{},
+			DataSources: DataSources{DataComplete: true, SourcesQueried: []string{}},
+		},
+	}
+	if len(m.Dependencies) == 0 {
+		if input.DryRun {
+			output.Plan = tr.planBatchVulns(nil, 0)
+		}
+		return output, nil
+	}
+
+	batch := BatchVulnsInput{Packages: make([]VulnsInput, len(m.Dependencies)), DryRun: input.DryRun}
+	for i, dep := range m.Dependencies {
+		batch.Packages[i] = VulnsInput{
+			Ecosystem: dep.Ecosystem,
+			Package:   dep.Name,
+			Version:   dep.Version,
+		}
+	}
+
+	result, err := tr.HandleBatchVulns(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+	output.BatchVulnsOutput = *result
+
+	// Cache complete reports only, so a degraded scan is retried next time
+	if !input.DryRun && output.DataComplete {
+		tr.setCachedResponse(cacheKey, output, cacheTTL(ctx, scanReportCacheTTL))
+	}
+	return output, nil
+}
+
+// contentHash is the hex SHA-256 of a document and the filename its format is detected from
+func contentHash(filename, content string) string {
+	sum := sha256.New()
+	sum.Write([]byte(filename))
+	sum.Write([]byte{0})
+	sum.Write([]byte(content))
+	return hex.EncodeToString(sum.Sum(nil))
+}
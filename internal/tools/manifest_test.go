@@ -0,0 +1,402 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/cassette"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"go.uber.org/zap"
+)
+
+func TestScanManifest_CargoLock(t *testing.T) {
+	content, err := os.ReadFile("../manifest/testdata/Cargo.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"crates.io/smallvec@1.6.0": {
+			{
+				ID:      "RUSTSEC-2021-0003",
+				Summary: "Buffer overflow in SmallVec::insert_many",
+				Aliases: []string{"CVE-2021-25900"},
+			},
+		},
+	})
+
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	result, err := registry.HandleScanManifest(context.Background(), ScanManifestInput{
+		Filename: "Cargo.lock",
+		Content:  string(content),
+	})
+	if err != nil {
+		t.Fatalf("HandleScanManifest() error = %v", err)
+	}
+
+	if result.DependencyCount != 2 {
+		t.Errorf("DependencyCount = %d, want 2", result.DependencyCount)
+	}
+	if result.VulnerabilityCount != 1 {
+		t.Fatalf("VulnerabilityCount = %d, want 1", result.VulnerabilityCount)
+	}
+
+	found := false
+	for _, r := range result.Results {
+		if r.Package == "smallvec" && r.VulnerabilityCount == 1 && r.Vulnerabilities[0].ID == "RUSTSEC-2021-0003" {
+			found = true
+			if r.Ecosystem != "crates.io" {
+				t.Errorf("Ecosystem = %s, want crates.io", r.Ecosystem)
+			}
+		}
+	}
+	if !found {
+		t.Error("expected RUSTSEC-2021-0003 to be reported for smallvec 1.6.0")
+	}
+}
+
+func TestScanManifest_EnrichesFindings(t *testing.T) {
+	content, err := os.ReadFile("../manifest/testdata/Cargo.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"crates.io/smallvec@1.6.0": {
+			{
+				ID:      "RUSTSEC-2021-0003",
+				Aliases: []string{"CVE-2021-25900"},
+				Severity: []osv.Severity{
+					{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+				},
+			},
+		},
+	})
+
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+	registry.kevClient = newMockKEV(t, "CVE-2021-25900")
+
+	result, err := registry.HandleScanManifest(context.Background(), ScanManifestInput{
+		Filename: "Cargo.lock",
+		Content:  string(content),
+	})
+	if err != nil {
+		t.Fatalf("HandleScanManifest() error = %v", err)
+	}
+
+	// A 9.8 vector is critical even though the score string never says so
+	if result.Summary.Critical != 1 || result.Summary.Unknown != 0 {
+		t.Errorf("Summary = %+v, want one critical", result.Summary)
+	}
+
+	for _, r := range result.Results {
+		if r.Package != "smallvec" {
+			continue
+		}
+		if len(r.Vulnerabilities) != 1 {
+			t.Fatalf("smallvec findings = %d, want 1", len(r.Vulnerabilities))
+		}
+		f := r.Vulnerabilities[0]
+		if !f.KnownExploited {
+			t.Error("expected finding to be marked known exploited")
+		}
+		if f.RiskScore <= 0 {
+			t.Errorf("RiskScore = %v, want a computed score", f.RiskScore)
+		}
+		return
+	}
+	t.Error("expected smallvec in results")
+}
+
+func TestScanManifest_ReportCache(t *testing.T) {
+	content, err := os.ReadFile("../manifest/testdata/Cargo.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"crates.io/smallvec@1.6.0": {{ID: "RUSTSEC-2021-0003", Aliases: []string{"CVE-2021-25900"}}},
+	})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+	input := ScanManifestInput{Filename: "Cargo.lock", Content: string(content)}
+
+	first, err := registry.HandleScanManifest(context.Background(), input)
+	if err != nil {
+		t.Fatalf("HandleScanManifest() error = %v", err)
+	}
+	if first.Cached || first.ContentHash == "" {
+		t.Fatalf("first scan = cached %v, content_hash %q; want a fresh scan with its hash", first.Cached, first.ContentHash)
+	}
+	// Cache writes land asynchronously
+	key := cacheKey("scan-manifest", "", first.ContentHash, "false")
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if _, _, found := registry.getCachedResponse(key); found {
+			break
+		}
+	}
+	requests := mock.requests.Load()
+
+	second, err := registry.HandleScanManifest(context.Background(), input)
+	if err != nil {
+		t.Fatalf("HandleScanManifest() error = %v", err)
+	}
+	if !second.Cached {
+		t.Error("second scan of an identical manifest was not served from the report cache")
+	}
+	if got := mock.requests.Load(); got != requests {
+		t.Errorf("OSV requests = %d after the second scan, want %d (no per-package calls)", got, requests)
+	}
+	if second.ContentHash != first.ContentHash || second.VulnerabilityCount != 1 {
+		t.Errorf("second scan = hash %s, %d vulnerabilities; want the first report", second.ContentHash, second.VulnerabilityCount)
+	}
+	if first.Cached {
+		t.Error("serving the cached report modified the first caller's result")
+	}
+
+	// Any change to the document is a new report
+	input.Content += "\n"
+	third, err := registry.HandleScanManifest(context.Background(), input)
+	if err != nil {
+		t.Fatalf("HandleScanManifest() error = %v", err)
+	}
+	if third.Cached || third.ContentHash == first.ContentHash {
+		t.Errorf("changed manifest = cached %v, hash %s; want a fresh scan under a new hash", third.Cached, third.ContentHash)
+	}
+	if got := mock.requests.Load(); got == requests {
+		t.Error("changed manifest made no OSV requests, want a fresh scan")
+	}
+}
+
+func TestScanManifest_Unsupported(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	if _, err := registry.HandleScanManifest(context.Background(), ScanManifestInput{
+		Filename: "requirements.in",
+		Content:  "requests==2.25.0",
+	}); err == nil {
+		t.Error("expected error for unsupported manifest")
+	}
+}
+
+func TestScanManifest_POM(t *testing.T) {
+	content, err := os.ReadFile("../manifest/testdata/pom.xml")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"Maven/org.apache.logging.log4j:log4j-core@2.14.1": {
+			{ID: "GHSA-jfh8-c2jp-5v3q", Summary: "Remote code injection in Log4j", Aliases: []string{"CVE-2021-44228"}},
+		},
+	})
+
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	result, err := registry.HandleScanManifest(context.Background(), ScanManifestInput{
+		Filename:    "pom.xml",
+		Content:     string(content),
+		RuntimeOnly: true,
+	})
+	if err != nil {
+		t.Fatalf("HandleScanManifest() error = %v", err)
+	}
+
+	if result.DependencyCount != 3 {
+		t.Errorf("DependencyCount = %d, want 3", result.DependencyCount)
+	}
+	if len(result.Unresolved) != 1 {
+		t.Errorf("Unresolved = %+v, want 1 entry", result.Unresolved)
+	}
+	if result.VulnerabilityCount != 1 {
+		t.Errorf("VulnerabilityCount = %d, want 1 (log4j-core resolved via ${log4j.version})", result.VulnerabilityCount)
+	}
+}
+
+func TestScanManifest_GemfileLock(t *testing.T) {
+	content, err := os.ReadFile("../manifest/testdata/Gemfile.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"RubyGems/rack@2.2.3": {
+			{
+				ID:      "GHSA-wq4h-7r42-5hrr",
+				Summary: "Denial of service via multipart parsing in Rack",
+				Aliases: []string{"CVE-2022-30122"},
+			},
+		},
+	})
+
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	result, err := registry.HandleScanManifest(context.Background(), ScanManifestInput{
+		Filename: "Gemfile.lock",
+		Content:  string(content),
+	})
+	if err != nil {
+		t.Fatalf("HandleScanManifest() error = %v", err)
+	}
+
+	if result.DependencyCount != 4 {
+		t.Errorf("DependencyCount = %d, want 4", result.DependencyCount)
+	}
+	if result.VulnerabilityCount != 1 {
+		t.Fatalf("VulnerabilityCount = %d, want 1", result.VulnerabilityCount)
+	}
+	for _, r := range result.Results {
+		if r.VulnerabilityCount > 0 && (r.Package != "rack" || r.Ecosystem != "RubyGems") {
+			t.Errorf("unexpected finding for %s/%s", r.Ecosystem, r.Package)
+		}
+	}
+}
+
+func TestScanManifest_YarnLock(t *testing.T) {
+	content, err := os.ReadFile("../manifest/testdata/yarn.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/lodash@4.17.15": {
+			{
+				ID:      "GHSA-p6mc-m468-83gw",
+				Summary: "Prototype Pollution in lodash",
+				Aliases: []string{"CVE-2020-8203"},
+			},
+		},
+	})
+
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	result, err := registry.HandleScanManifest(context.Background(), ScanManifestInput{
+		Filename: "yarn.lock",
+		Content:  string(content),
+	})
+	if err != nil {
+		t.Fatalf("HandleScanManifest() error = %v", err)
+	}
+
+	if result.DependencyCount != 3 {
+		t.Errorf("DependencyCount = %d, want 3", result.DependencyCount)
+	}
+	if result.VulnerabilityCount != 1 {
+		t.Fatalf("VulnerabilityCount = %d, want 1", result.VulnerabilityCount)
+	}
+	for _, r := range result.Results {
+		if r.VulnerabilityCount > 0 && (r.Package != "lodash" || r.Version != "4.17.15" || r.Ecosystem != "npm") {
+			t.Errorf("unexpected finding for %s/%s@%s", r.Ecosystem, r.Package, r.Version)
+		}
+	}
+}
+
+func TestScanManifest_ComposerLock(t *testing.T) {
+	content, err := os.ReadFile("../manifest/testdata/composer.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"Packagist/guzzlehttp/psr7@2.4.1": {
+			{
+				ID:      "GHSA-wxmh-65f7-jcvw",
+				Summary: "Improper header validation in guzzlehttp/psr7",
+				Aliases: []string{"CVE-2023-29197"},
+			},
+		},
+	})
+
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	result, err := registry.HandleScanManifest(context.Background(), ScanManifestInput{
+		Filename: "composer.lock",
+		Content:  string(content),
+	})
+	if err != nil {
+		t.Fatalf("HandleScanManifest() error = %v", err)
+	}
+
+	if result.DependencyCount != 3 {
+		t.Errorf("DependencyCount = %d, want 3", result.DependencyCount)
+	}
+	if len(result.Unresolved) != 1 {
+		t.Errorf("Unresolved = %+v, want the dev-main package", result.Unresolved)
+	}
+	if result.VulnerabilityCount != 1 {
+		t.Fatalf("VulnerabilityCount = %d, want 1", result.VulnerabilityCount)
+	}
+	for _, r := range result.Results {
+		if r.VulnerabilityCount > 0 && r.Package != "guzzlehttp/psr7" {
+			t.Errorf("unexpected finding for %s", r.Package)
+		}
+	}
+}
+
+// BenchmarkScanManifest scans a lockfile against OSV responses replayed from
+// testdata/scan_manifest, so the batch path is measured without the network. Each iteration
+// appends a distinct comment so the content-hash report cache never answers.
+func BenchmarkScanManifest(b *testing.B) {
+	registry, input := newScanManifestBenchmark(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		varied := input
+		varied.Content += fmt.Sprintf("# iteration %d\n", i)
+		if _, err := registry.HandleScanManifest(context.Background(), varied); err != nil {
+			b.Fatalf("HandleScanManifest() error = %v", err)
+		}
+	}
+}
+
+// BenchmarkScanManifest_CacheHit rescans an unchanged lockfile, answered from the report cache
+func BenchmarkScanManifest_CacheHit(b *testing.B) {
+	registry, input := newScanManifestBenchmark(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := registry.HandleScanManifest(context.Background(), input); err != nil {
+			b.Fatalf("HandleScanManifest() error = %v", err)
+		}
+	}
+}
+
+// newScanManifestBenchmark returns a registry replaying OSV from testdata/scan_manifest and the
+// Cargo.lock input for it, after checking one scan gives the recorded findings
+func newScanManifestBenchmark(b *testing.B) (*ToolRegistry, ScanManifestInput) {
+	b.Helper()
+
+	content, err := os.ReadFile("../manifest/testdata/Cargo.lock")
+	if err != nil {
+		b.Fatalf("failed to read fixture: %v", err)
+	}
+	tape, err := cassette.New("testdata/scan_manifest", cassette.ModeReplay)
+	if err != nil {
+		b.Fatalf("cassette.New() error = %v", err)
+	}
+	registry := newTestRegistry(b)
+	registry.osvClient = osv.NewClient(zap.NewNop(), osv.WithCassette(tape))
+	input := ScanManifestInput{Filename: "Cargo.lock", Content: string(content)}
+
+	result, err := registry.HandleScanManifest(context.Background(), input)
+	if err != nil {
+		b.Fatalf("HandleScanManifest() error = %v", err)
+	}
+	if result.VulnerabilityCount != 2 || !result.DataComplete {
+		b.Fatalf("replayed scan = %d findings, sources failed %v; want 2 findings from complete data",
+			result.VulnerabilityCount, result.SourcesFailed)
+	}
+	return registry, input
+}
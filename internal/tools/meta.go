@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/PackagePulse/internal/stats"
+)
+
+// ToolInfo describes one registered tool
+type ToolInfo struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	InputSchema interface{} `json:"input_schema"`
+}
+
+// MetaToolsOutput lists every tool the server exposes
+type MetaToolsOutput struct {
+	ToolCount int        `json:"tool_count"`
+	Tools     []ToolInfo `json:"tools"`
+}
+
+// HandleMetaTools implements the meta.tools tool. The list is read back from the MCP server
+// over an in-memory session, exactly as a connected client would see it, so it never drifts
+// from what is registered.
+func HandleMetaTools(ctx context.Context, server *mcp.Server) (*MetaToolsOutput, error) {
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connect to server: %w", err)
+	}
+	defer func() {
+		_ = serverSession.Close()
+	}()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "packagepulse-meta", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connect client: %w", err)
+	}
+	defer func() {
+		_ = session.Close()
+	}()
+
+	output := &MetaToolsOutput{Tools: []ToolInfo{}}
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			return nil, fmt.Errorf("list tools: %w", err)
+		}
+		output.Tools = append(output.Tools, ToolInfo{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: tool.InputSchema,
+		})
+	}
+	sort.Slice(output.Tools, func(i, j int) bool {
+		return output.Tools[i].Name < output.Tools[j].Name
+	})
+	output.ToolCount = len(output.Tools)
+
+	return output, nil
+}
+
+// MetaStatsInput defines input for meta.stats tool
+type MetaStatsInput struct {
+	Reset bool `json:"reset,omitempty"`
+}
+
+// HandleMetaStats implements the meta.stats tool: the current success and error counts,
+// cleared afterwards when input.Reset is set
+func (tr *ToolRegistry) HandleMetaStats(input MetaStatsInput) stats.Snapshot {
+	snapshot := tr.stats.Snapshot()
+	if input.Reset {
+		tr.stats.Reset()
+	}
+	return snapshot
+}
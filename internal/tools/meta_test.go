@@ -0,0 +1,196 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"github.com/rayprogramming/PackagePulse/internal/retrybudget"
+	"github.com/rayprogramming/PackagePulse/internal/stats"
+	"github.com/rayprogramming/hypermcp"
+	"go.uber.org/zap"
+)
+
+func TestMetaTools_ListsRegisteredTools(t *testing.T) {
+	registry := newTestRegistry(t)
+	srv, err := hypermcp.New(hypermcp.Config{Name: "test", Version: "1.0.0"}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	if err := registry.Register(srv); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.MCP().Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "meta.tools"})
+	if err != nil || res.IsError {
+		t.Fatalf("CallTool(meta.tools) = %+v, %v", res, err)
+	}
+	var output MetaToolsOutput
+	if err := json.Unmarshal([]byte(resultText(t, res)), &output); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+
+	if output.ToolCount != len(output.Tools) {
+		t.Errorf("tool_count = %d, want %d", output.ToolCount, len(output.Tools))
+	}
+
+	want := map[string][]string{
+		"deps.vulns":        nil, // ecosystem and package, or purl
+		"deps.health":       {"ecosystem", "package"},
+		"license.info":      {"license_id"},
+		"deps.upgrade_plan": {"ecosystem", "package", "current_version"},
+		"meta.tools":        nil,
+	}
+	for _, tool := range output.Tools {
+		required, ok := want[tool.Name]
+		if !ok {
+			continue
+		}
+		delete(want, tool.Name)
+		if tool.Description == "" {
+			t.Errorf("%s has no description", tool.Name)
+		}
+		schema, _ := tool.InputSchema.(map[string]interface{})
+		var got []string
+		if list, ok := schema["required"].([]interface{}); ok {
+			for _, field := range list {
+				got = append(got, field.(string))
+			}
+		}
+		for _, field := range required {
+			if !slices.Contains(got, field) {
+				t.Errorf("%s required = %v, missing %q", tool.Name, got, field)
+			}
+		}
+	}
+	for name := range want {
+		t.Errorf("meta.tools is missing %s", name)
+	}
+}
+
+func TestRegister_DisabledTools(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.DisabledTools = []string{"deps.upgrade_plan"}
+	registry, err := NewToolRegistryWithConfig(zap.NewNop(), nil, cfg)
+	if err != nil {
+		t.Fatalf("NewToolRegistryWithConfig() error = %v", err)
+	}
+	srv, err := hypermcp.New(hypermcp.Config{Name: "test", Version: "1.0.0"}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	if err := registry.Register(srv); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	output, err := HandleMetaTools(context.Background(), srv.MCP())
+	if err != nil {
+		t.Fatalf("HandleMetaTools() error = %v", err)
+	}
+	var names []string
+	for _, tool := range output.Tools {
+		names = append(names, tool.Name)
+	}
+	if slices.Contains(names, "deps.upgrade_plan") {
+		t.Error("deps.upgrade_plan registered despite being disabled")
+	}
+	// Everything but the disabled tool and the opt-in license.reload is still registered
+	if len(names) != len(ToolNames())-2 || !slices.Contains(names, "deps.vulns") {
+		t.Errorf("registered tools = %v, want all but deps.upgrade_plan and license.reload", names)
+	}
+
+	cfg.DisabledTools = nil
+	cfg.EnabledTools = []string{"license.info", "meta.tools"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if cfg.ToolEnabled("deps.vulns") || !cfg.ToolEnabled("license.info") {
+		t.Error("ToolEnabled() should only allow the enabled tools")
+	}
+
+	cfg.EnabledTools = []string{"deps.nope"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected an error for an unknown tool name")
+	}
+}
+
+func TestMetaStats_CountsPerEcosystem(t *testing.T) {
+	registry := newTestRegistry(t)
+	registry.osvClient = newMockOSV(t, map[string][]osv.Vulnerability{"npm/lodash": {}}).client()
+	registry.epssClient = newMockEPSS(t)
+	registry.kevClient = newMockKEV(t)
+	srv, err := hypermcp.New(hypermcp.Config{Name: "test", Version: "1.0.0"}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	if err := registry.Register(srv); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.MCP().Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+
+	calls := []map[string]any{
+		{"ecosystem": "npm", "package": "lodash"},
+		{"ecosystem": "NPM", "package": "lodash", "limit": -1},
+	}
+	for _, args := range calls {
+		if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "deps.vulns", Arguments: args}); err != nil {
+			t.Fatalf("CallTool(deps.vulns, %v) error = %v", args, err)
+		}
+	}
+
+	readStats := func(args map[string]any) stats.Snapshot {
+		t.Helper()
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "meta.stats", Arguments: args})
+		if err != nil || res.IsError {
+			t.Fatalf("CallTool(meta.stats) = %+v, %v", res, err)
+		}
+		var snapshot stats.Snapshot
+		if err := json.Unmarshal([]byte(resultText(t, res)), &snapshot); err != nil {
+			t.Fatalf("decode output: %v", err)
+		}
+		return snapshot
+	}
+
+	snapshot := readStats(map[string]any{"reset": true})
+	want := stats.Counts{Successes: 1, Errors: 1, ErrorRate: 0.5}
+	if got := snapshot.Ecosystems["npm"]; got != want || len(snapshot.Ecosystems) != 1 {
+		t.Errorf("ecosystems = %+v, want npm %+v", snapshot.Ecosystems, want)
+	}
+	// No call retried, so the shared retry budget is full
+	if budget := snapshot.RetryBudget; budget == nil || budget.Available != retrybudget.DefaultCapacity || budget.Granted != 0 {
+		t.Errorf("retry_budget = %+v, want a full budget of %d", budget, retrybudget.DefaultCapacity)
+	}
+	if after := readStats(nil); len(after.Ecosystems) != 0 {
+		t.Errorf("ecosystems after reset = %+v, want none", after.Ecosystems)
+	}
+}
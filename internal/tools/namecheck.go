@@ -0,0 +1,268 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// recentPublicationWindow is how new a package must be for a near-miss name to be flagged
+const recentPublicationWindow = 90 * 24 * time.Hour
+
+// Reasons deps.name_check gives for flagging a name
+const (
+	NameReasonEditDistance = "edit_distance"
+	NameReasonSeparator    = "separator"
+	NameReasonRecent       = "recently_published"
+)
+
+// defaultPopularPackages seeds the widely installed packages typosquats imitate, keyed by
+// OSV ecosystem
+var defaultPopularPackages = map[string][]string{
+	"npm": {
+		"express", "lodash", "react", "react-dom", "axios", "request", "chalk", "commander",
+		"moment", "debug", "webpack", "typescript", "jquery", "underscore", "async", "bluebird",
+		"uuid", "dotenv", "yargs", "mongoose", "body-parser", "cross-env", "eslint", "jest",
+		"mocha", "prettier", "socket.io", "colors", "minimist", "rimraf", "semver", "node-fetch",
+		"redux", "electron", "vue", "next", "babel-core", "coffee-script", "discord.js",
+	},
+	"PyPI": {
+		"requests", "numpy", "pandas", "django", "flask", "urllib3", "setuptools", "boto3",
+		"python-dateutil", "pyyaml", "scipy", "matplotlib", "tensorflow", "torch", "pillow",
+		"beautifulsoup4", "selenium", "cryptography", "colorama", "jinja2", "certifi", "click",
+		"pytest", "sqlalchemy", "scikit-learn", "openai", "pycryptodome",
+	},
+	"Go": {
+		"github.com/gin-gonic/gin", "github.com/sirupsen/logrus", "github.com/spf13/cobra",
+		"github.com/stretchr/testify", "github.com/gorilla/mux", "github.com/google/uuid",
+		"github.com/golang-jwt/jwt/v5", "go.uber.org/zap", "google.golang.org/grpc",
+	},
+	"crates.io": {
+		"serde", "serde_json", "tokio", "rand", "clap", "regex", "reqwest", "anyhow", "syn",
+		"thiserror", "hyper",
+	},
+	"RubyGems": {
+		"rails", "rake", "bundler", "nokogiri", "rack", "rspec", "devise", "puma", "sinatra",
+		"activesupport",
+	},
+	"NuGet": {
+		"Newtonsoft.Json", "Serilog", "AutoMapper", "Dapper", "xunit", "NUnit", "Moq", "Polly",
+		"FluentValidation",
+	},
+	"Packagist": {
+		"symfony/console", "laravel/framework", "guzzlehttp/guzzle", "monolog/monolog",
+		"phpunit/phpunit", "doctrine/orm",
+	},
+	"Maven": {
+		"org.apache.logging.log4j:log4j-core", "com.google.guava:guava", "junit:junit",
+		"org.springframework:spring-core", "com.fasterxml.jackson.core:jackson-databind",
+		"org.apache.commons:commons-lang3",
+	},
+}
+
+// mergePopularPackages adds configured popular names to the seeded ones. Keys accept any
+// ecosystem spelling deps.vulns does.
+func mergePopularPackages(configured map[string][]string) map[string][]string {
+	merged := make(map[string][]string, len(defaultPopularPackages))
+	for ecosystem, names := range defaultPopularPackages {
+		merged[ecosystem] = slices.Clone(names)
+	}
+	for key, names := range configured {
+		ecosystem := normalizeEcosystem(key)
+		for _, name := range names {
+			if name = strings.TrimSpace(name); name != "" && !slices.Contains(merged[ecosystem], name) {
+				merged[ecosystem] = append(merged[ecosystem], name)
+			}
+		}
+	}
+	return merged
+}
+
+// validatePopularPackages checks that every configured key names a supported ecosystem
+func validatePopularPackages(configured map[string][]string) error {
+	for key := range configured {
+		if _, err := validateEcosystem(key); err != nil {
+			return fmt.Errorf("popular_packages: %w", err)
+		}
+	}
+	return nil
+}
+
+// NameCheckInput defines input for deps.name_check tool
+type NameCheckInput struct {
+	Ecosystem string `json:"ecosystem"`
+	Package   string `json:"package"`
+}
+
+// NameMatch is a popular package a checked name resembles
+type NameMatch struct {
+	Target       string `json:"target"`
+	EditDistance int    `json:"edit_distance"`
+	Reason       string `json:"reason"`
+}
+
+// NameCheckOutput is the deps.name_check response
+type NameCheckOutput struct {
+	Ecosystem  string `json:"ecosystem"`
+	Package    string `json:"package"`
+	Suspicious bool   `json:"suspicious"`
+	// SuspectedTarget and EditDistance describe the closest match when the name is flagged
+	SuspectedTarget string      `json:"suspected_target,omitempty"`
+	EditDistance    int         `json:"edit_distance,omitempty"`
+	Matches         []NameMatch `json:"matches,omitempty"`
+	// FirstPublished is when the checked package's earliest version was published, looked up
+	// only for names close to a popular one
+	FirstPublished *time.Time `json:"first_published,omitempty"`
+	Recommendation string     `json:"recommendation"`
+}
+
+// HandleNameCheck flags a package name that is likely a typosquat of a popular package: one
+// within a small edit distance of a popular name, or a recently published package one edit
+// further away. Popular packages themselves are never flagged.
+// Example: {"ecosystem": "npm", "package": "expresss"}
+func (tr *ToolRegistry) HandleNameCheck(ctx context.Context, input NameCheckInput) (*NameCheckOutput, error) {
+	name := strings.TrimSpace(input.Package)
+	if name == "" {
+		return nil, fmt.Errorf("%w: package is required", errInvalidInput)
+	}
+	ecosystem, err := validateEcosystem(input.Ecosystem)
+	if err != nil {
+		return nil, err
+	}
+
+	tr.log(ctx).Info("Handling name check",
+		zap.String("ecosystem", ecosystem),
+		zap.String("package", name))
+
+	output := &NameCheckOutput{Ecosystem: ecosystem, Package: name}
+	popular := tr.popularPackages[ecosystem]
+	key := nameCheckKey(ecosystem, name)
+	for _, target := range popular {
+		if nameCheckKey(ecosystem, target) == key {
+			output.Recommendation = fmt.Sprintf("%s is a popular %s package.", target, ecosystem)
+			return output, nil
+		}
+	}
+
+	// Candidates one edit beyond the typo threshold are flagged only if the package is new
+	var near []NameMatch
+	for _, target := range popular {
+		targetKey := nameCheckKey(ecosystem, target)
+		limit := maxTypoDistance(targetKey)
+		d := typoDistance(key, targetKey)
+		switch {
+		case squashSeparators(key) == squashSeparators(targetKey):
+			output.Matches = append(output.Matches, NameMatch{Target: target, EditDistance: d, Reason: NameReasonSeparator})
+		case d <= limit:
+			output.Matches = append(output.Matches, NameMatch{Target: target, EditDistance: d, Reason: NameReasonEditDistance})
+		case limit > 0 && d == limit+1:
+			near = append(near, NameMatch{Target: target, EditDistance: d, Reason: NameReasonRecent})
+		}
+	}
+
+	if len(output.Matches) > 0 || len(near) > 0 {
+		if published := tr.firstPublished(ctx, ecosystem, name); !published.IsZero() {
+			output.FirstPublished = &published
+			if time.Since(published) < recentPublicationWindow {
+				output.Matches = append(output.Matches, near...)
+			}
+		}
+	}
+
+	if len(output.Matches) == 0 {
+		output.Recommendation = "No popular package has a similar name."
+		return output, nil
+	}
+	sort.SliceStable(output.Matches, func(i, j int) bool {
+		return output.Matches[i].EditDistance < output.Matches[j].EditDistance
+	})
+	best := output.Matches[0]
+	output.Suspicious = true
+	output.SuspectedTarget = best.Target
+	output.EditDistance = best.EditDistance
+	output.Recommendation = fmt.Sprintf("WARNING: %s is %d edit(s) from the popular package %s and may be a typosquat. Confirm the intended package before installing.",
+		name, best.EditDistance, best.Target)
+	return output, nil
+}
+
+// firstPublished returns when a package's earliest version was published, or the zero time
+// when the package cannot be looked up; a lookup failure does not fail the name check
+func (tr *ToolRegistry) firstPublished(ctx context.Context, ecosystem, name string) time.Time {
+	pkgInfo, err := tr.getPackageInfo(ctx, ecosystem, name)
+	if err != nil {
+		tr.log(ctx).Debug("name check lookup failed", zap.String("package", name), zap.Error(err))
+		return time.Time{}
+	}
+	var first time.Time
+	for _, v := range pkgInfo.Versions {
+		if !v.PublishedAt.IsZero() && (first.IsZero() || v.PublishedAt.Before(first)) {
+			first = v.PublishedAt
+		}
+	}
+	return first
+}
+
+// nameCheckKey folds a name the way its registry does: case-insensitively, and for PyPI
+// treating runs of '-', '_', and '.' as one separator
+func nameCheckKey(ecosystem, name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if ecosystem == "PyPI" {
+		name = strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+			return r == '-' || r == '_' || r == '.'
+		}), "-")
+	}
+	return name
+}
+
+// squashSeparators drops separators, so "lo-dash" and "lodash" compare equal
+func squashSeparators(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' {
+			return -1
+		}
+		return r
+	}, name)
+}
+
+// maxTypoDistance is how many edits from a popular name still count as a typo. Short names
+// are too easily one edit from an unrelated package, so they are only matched by separators.
+func maxTypoDistance(name string) int {
+	switch {
+	case len(name) < 5:
+		return 0
+	case len(name) < 10:
+		return 1
+	}
+	return 2
+}
+
+// typoDistance is the edit distance between two ASCII strings where swapping adjacent
+// characters counts as one edit, the most common typing slip
+func typoDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
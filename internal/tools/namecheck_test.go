@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"go.uber.org/zap"
+)
+
+func TestHandleNameCheck(t *testing.T) {
+	published := func(name string, age time.Duration) *depsdev.PackageInfo {
+		return &depsdev.PackageInfo{
+			PackageKey: depsdev.PackageKey{System: "NPM", Name: name},
+			Versions: []depsdev.VersionInfo{
+				{VersionKey: depsdev.VersionKey{Version: "1.0.0"}, PublishedAt: time.Now().Add(-age)},
+			},
+		}
+	}
+	mock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"npm/loodashh": published("loodashh", 10*24*time.Hour),
+		"npm/reduxxx":  published("reduxxx", 1000*24*time.Hour),
+	})
+
+	cfg := DefaultConfig()
+	cfg.PopularPackages = map[string][]string{"NPM": {"our-design-system"}}
+	registry, err := NewToolRegistryWithConfig(zap.NewNop(), newTestRegistry(t).cache, cfg)
+	if err != nil {
+		t.Fatalf("NewToolRegistryWithConfig() error = %v", err)
+	}
+	registry.depsDevClient = mock.client()
+
+	tests := []struct {
+		ecosystem, pkg string
+		target         string
+		distance       int
+	}{
+		{"npm", "expresss", "express", 1},
+		{"npm", "Expresss", "express", 1},
+		{"npm", "lodahs", "lodash", 1},
+		{"npm", "body_parser", "body-parser", 1},
+		{"npm", "our-desing-system", "our-design-system", 1},
+		{"pypi", "python-datutil", "python-dateutil", 1},
+		// Two edits from lodash, but published ten days ago
+		{"npm", "loodashh", "lodash", 2},
+		// Benign or legitimate names
+		{"npm", "packagepulse-widgets", "", 0},
+		{"npm", "express", "", 0},
+		{"pypi", "Python_DateUtil", "", 0},
+		{"npm", "reduxxx", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pkg, func(t *testing.T) {
+			output, err := registry.HandleNameCheck(context.Background(), NameCheckInput{Ecosystem: tt.ecosystem, Package: tt.pkg})
+			if err != nil {
+				t.Fatalf("HandleNameCheck() error = %v", err)
+			}
+			if output.Suspicious != (tt.target != "") || output.SuspectedTarget != tt.target || output.EditDistance != tt.distance {
+				t.Errorf("HandleNameCheck(%s) = suspicious %v, target %q, distance %d; want target %q, distance %d",
+					tt.pkg, output.Suspicious, output.SuspectedTarget, output.EditDistance, tt.target, tt.distance)
+			}
+		})
+	}
+
+	// Names close to no popular package need no registry lookup
+	before := mock.requests.Load()
+	if _, err := registry.HandleNameCheck(context.Background(), NameCheckInput{Ecosystem: "npm", Package: "zzqx-internal"}); err != nil {
+		t.Fatalf("HandleNameCheck() error = %v", err)
+	}
+	if n := mock.requests.Load() - before; n != 0 {
+		t.Errorf("deps.dev requests = %d, want 0", n)
+	}
+
+	if _, err := registry.HandleNameCheck(context.Background(), NameCheckInput{Ecosystem: "npm"}); err == nil {
+		t.Error("HandleNameCheck() without a package succeeded, want an error")
+	}
+}
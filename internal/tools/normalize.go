@@ -0,0 +1,336 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"go.uber.org/zap"
+)
+
+// canonicalNameCacheTTL controls how long resolved package ID casing is cached
+const canonicalNameCacheTTL = 24 * time.Hour
+
+// ecosystemNames maps lowercased ecosystem names and common aliases to OSV's spelling
+var ecosystemNames = map[string]string{
+	"npm":       "npm",
+	"pypi":      "PyPI",
+	"go":        "Go",
+	"maven":     "Maven",
+	"cargo":     "crates.io",
+	"crates.io": "crates.io",
+	"nuget":     osv.EcosystemNuGet,
+	"rubygems":  "RubyGems",
+	"gem":       "RubyGems",
+	"packagist": "Packagist",
+	"composer":  "Packagist",
+}
+
+// osEcosystems maps lowercased OS distribution ecosystems to OSV's spelling. OSV qualifies
+// most of them with a release after a colon, e.g. "Debian:11", "Ubuntu:22.04", or "Alpine:v3.18".
+var osEcosystems = map[string]string{
+	"almalinux":   "AlmaLinux",
+	"alpine":      "Alpine",
+	"chainguard":  "Chainguard",
+	"debian":      "Debian",
+	"mageia":      "Mageia",
+	"opensuse":    "openSUSE",
+	"photon os":   "Photon OS",
+	"red hat":     "Red Hat",
+	"rocky linux": "Rocky Linux",
+	"suse":        "SUSE",
+	"ubuntu":      "Ubuntu",
+	"wolfi":       "Wolfi",
+}
+
+// normalizeEcosystem returns OSV's spelling of an ecosystem name.
+// Unknown ecosystems are returned unchanged.
+func normalizeEcosystem(ecosystem string) string {
+	if name, ok := ecosystemNames[strings.ToLower(strings.TrimSpace(ecosystem))]; ok {
+		return name
+	}
+	if name, ok := osEcosystem(ecosystem); ok {
+		return name
+	}
+	return ecosystem
+}
+
+// osEcosystem returns OSV's spelling of an OS distribution ecosystem, reporting false when
+// ecosystem is not one. Only the distribution name is respelled; a release suffix is passed
+// through verbatim, since OSV defines its format per distribution.
+func osEcosystem(ecosystem string) (string, bool) {
+	base, release, qualified := strings.Cut(strings.TrimSpace(ecosystem), ":")
+	name, ok := osEcosystems[strings.ToLower(base)]
+	if !ok || (qualified && release == "") {
+		return "", false
+	}
+	if qualified {
+		return name + ":" + release, true
+	}
+	return name, true
+}
+
+// caseInsensitiveEcosystems lists the ecosystems whose registries match package names
+// regardless of casing
+var caseInsensitiveEcosystems = map[string]bool{
+	"PyPI":             true,
+	osv.EcosystemNuGet: true,
+	"Packagist":        true,
+}
+
+// cacheKey composes a cache key from a kind and the inputs identifying a request. Every part
+// is trimmed, the ecosystem takes OSV's spelling, and names in case-insensitive ecosystems
+// are lowercased, so requests differing only in casing or whitespace share an entry. An empty
+// ecosystem is left out of the key.
+func cacheKey(kind, ecosystem, name string, fields ...string) string {
+	parts := []string{kind}
+	if ecosystem = strings.TrimSpace(ecosystem); ecosystem != "" {
+		ecosystem = normalizeEcosystem(ecosystem)
+		parts = append(parts, ecosystem)
+	}
+	name = strings.TrimSpace(name)
+	if caseInsensitiveEcosystems[ecosystem] {
+		name = strings.ToLower(name)
+	}
+	parts = append(parts, name)
+	for _, field := range fields {
+		parts = append(parts, strings.TrimSpace(field))
+	}
+	return strings.Join(parts, ":")
+}
+
+// errInvalidInput marks errors caused by bad tool arguments rather than upstream failures
+var errInvalidInput = errors.New("INVALID_INPUT")
+
+// supportedEcosystems returns the OSV spelling of every supported ecosystem, sorted
+func supportedEcosystems() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, name := range ecosystemNames {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// supportedOSEcosystems returns OSV's spelling of every supported OS distribution ecosystem, sorted
+func supportedOSEcosystems() []string {
+	names := make([]string, 0, len(osEcosystems))
+	for _, name := range osEcosystems {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EcosystemList describes the ecosystems the tools accept, for the ecosystems resource
+type EcosystemList struct {
+	// Language ecosystems work with every tool
+	Language []string `json:"language"`
+	// OS ecosystems are distribution package ecosystems, taken with an optional release
+	// suffix by OSToolNames only, since deps.dev has no data for them
+	OS          []string `json:"os"`
+	OSToolNames []string `json:"os_tools"`
+	OSExamples  []string `json:"os_examples"`
+}
+
+// SupportedEcosystems lists the ecosystems the tools accept, in OSV's spelling
+func SupportedEcosystems() EcosystemList {
+	return EcosystemList{
+		Language:    supportedEcosystems(),
+		OS:          supportedOSEcosystems(),
+		OSToolNames: []string{"deps.vulns", "deps.batch_vulns"},
+		OSExamples:  []string{"Debian:11", "Ubuntu:22.04", "Alpine:v3.18", "Rocky Linux:9"},
+	}
+}
+
+// validateScanEcosystem is validateEcosystem for tools that only query OSV, which also take
+// OS distribution ecosystems with or without a release suffix
+func validateScanEcosystem(ecosystem string) (string, error) {
+	if name, ok := osEcosystem(ecosystem); ok {
+		return name, nil
+	}
+	return validateEcosystem(ecosystem)
+}
+
+// validateEcosystem normalizes an ecosystem name and rejects unsupported ones with
+// an INVALID_INPUT error that lists the valid values and the closest match
+func validateEcosystem(ecosystem string) (string, error) {
+	key := strings.ToLower(strings.TrimSpace(ecosystem))
+	if name, ok := ecosystemNames[key]; ok {
+		return name, nil
+	}
+
+	valid := strings.Join(supportedEcosystems(), ", ")
+	if key == "" {
+		return "", fmt.Errorf("%w: ecosystem is required (valid: %s)", errInvalidInput, valid)
+	}
+	hint := ""
+	if suggestion := closestEcosystem(key); suggestion != "" {
+		hint = fmt.Sprintf(" (did you mean %q?)", suggestion)
+	}
+	return "", fmt.Errorf("%w: unsupported ecosystem %q%s; valid ecosystems: %s", errInvalidInput, ecosystem, hint, valid)
+}
+
+// closestEcosystem suggests a supported ecosystem for a misspelled name. Names that
+// extend or abbreviate an alias ("npmjs", "py") win; otherwise the nearest alias by
+// edit distance is used if it is close enough to be a plausible typo.
+func closestEcosystem(key string) string {
+	aliases := make([]string, 0, len(ecosystemNames))
+	for alias := range ecosystemNames {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	for _, alias := range aliases {
+		if len(key) >= 2 && (strings.HasPrefix(key, alias) || strings.HasPrefix(alias, key)) {
+			return ecosystemNames[alias]
+		}
+	}
+
+	best, bestDistance := "", max(2, len(key)/2)+1
+	for _, alias := range aliases {
+		if d := editDistance(key, alias); d < bestDistance {
+			best, bestDistance = ecosystemNames[alias], d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two ASCII strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// ResolvedQuery echoes the ecosystem, package, and version a lookup actually used upstream,
+// after normalization, so clients can tell when their input was read differently than intended
+type ResolvedQuery struct {
+	Ecosystem string `json:"ecosystem"`
+	Package   string `json:"package"`
+	Version   string `json:"version,omitempty"`
+}
+
+// resolveQuery validates and normalizes a package lookup's inputs
+func (tr *ToolRegistry) resolveQuery(ctx context.Context, ecosystem, name, version string) (*ResolvedQuery, error) {
+	ecosystem, err := validateEcosystem(ecosystem)
+	if err != nil {
+		return nil, err
+	}
+	if name, version, err = routeGoVersion(ecosystem, name, version); err != nil {
+		return nil, err
+	}
+	ecosystem, name = tr.normalizePackage(ctx, ecosystem, name)
+	return &ResolvedQuery{Ecosystem: ecosystem, Package: name, Version: strings.TrimSpace(version)}, nil
+}
+
+// normalizePackage canonicalizes an ecosystem and package name before querying OSV.
+// Go module paths are cleaned up locally; see canonicalGoModule.
+// NuGet IDs are case-insensitive on the registry but OSV expects the registry's
+// casing, so "newtonsoft.json" is resolved to "Newtonsoft.Json" via deps.dev.
+// Names that cannot be resolved are returned trimmed but otherwise unchanged.
+func (tr *ToolRegistry) normalizePackage(ctx context.Context, ecosystem, name string) (string, string) {
+	ecosystem = normalizeEcosystem(ecosystem)
+	name = strings.TrimSpace(name)
+	if ecosystem == "Go" {
+		name, _ = canonicalGoModule(name)
+		return ecosystem, name
+	}
+	if ecosystem != osv.EcosystemNuGet || name == "" {
+		return ecosystem, name
+	}
+
+	cacheKey := canonicalNameKey(ecosystem, name)
+	if cached, ok := tr.cache.Get(cacheKey); ok {
+		if canonical, ok := cached.(string); ok {
+			return ecosystem, canonical
+		}
+	}
+
+	canonical, err := tr.depsDevClient.CanonicalName(ctx, ecosystem, name)
+	if err != nil {
+		tr.log(ctx).Debug("could not resolve canonical package name",
+			zap.String("ecosystem", ecosystem),
+			zap.String("package", name),
+			zap.Error(err))
+		return ecosystem, name
+	}
+
+	tr.cache.Set(cacheKey, canonical, canonicalNameCacheTTL)
+	return ecosystem, canonical
+}
+
+// canonicalGoModule returns the module path a Go package name refers to and any version
+// appended to it: "https://github.com/Foo/bar/V2/@v2.1.0" is github.com/Foo/bar/v2 at v2.1.0.
+// The major-version suffix is part of the module path and is kept, lowercased; the rest of the
+// path is case-sensitive and left as given.
+func canonicalGoModule(name string) (path, version string) {
+	path = strings.TrimSpace(name)
+	if at := strings.LastIndex(path, "@"); at >= 0 {
+		path, version = path[:at], strings.TrimSpace(path[at+1:])
+	}
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "https://"), "http://")
+	path = strings.TrimSuffix(strings.TrimRight(path, "/"), ".git")
+	if slash := strings.LastIndex(path, "/"); slash >= 0 && isMajorSuffix(path[slash+1:]) {
+		path = path[:slash+1] + strings.ToLower(path[slash+1:])
+	}
+	return path, version
+}
+
+// isMajorSuffix reports whether a path element is a Go major-version suffix such as v2
+func isMajorSuffix(elem string) bool {
+	if len(elem) < 2 || (elem[0] != 'v' && elem[0] != 'V') || elem[1] == '0' {
+		return false
+	}
+	for _, c := range elem[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// routeGoVersion moves a version appended to a Go module path ("github.com/foo/bar/v2@v2.1.0")
+// into the version field, rejecting one that contradicts the version given there. Other
+// ecosystems are returned unchanged.
+func routeGoVersion(ecosystem, name, version string) (string, string, error) {
+	if normalizeEcosystem(ecosystem) != "Go" || !strings.Contains(name, "@") {
+		return name, version, nil
+	}
+	path, pinned := canonicalGoModule(name)
+	version = strings.TrimSpace(version)
+	if version != "" && pinned != "" && version != pinned {
+		return "", "", fmt.Errorf("%w: package %s names version %s but version is %s", errInvalidInput, name, pinned, version)
+	}
+	if version == "" {
+		version = pinned
+	}
+	return path, version, nil
+}
+
+// canonicalNameKey is the cache key for a package's canonical name
+func canonicalNameKey(ecosystem, name string) string {
+	return cacheKey("canonical", ecosystem, name)
+}
@@ -0,0 +1,339 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"go.uber.org/zap"
+)
+
+func TestHandleVulns_NuGetCaseInsensitive(t *testing.T) {
+	osvMock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"NuGet/Newtonsoft.Json@12.0.1": {
+			{ID: "GHSA-5crp-9r3c-p9vr", Aliases: []string{"CVE-2024-21907"}},
+		},
+	})
+	depsMock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"nuget/newtonsoft.json": {PackageKey: depsdev.PackageKey{System: "NUGET", Name: "Newtonsoft.Json"}},
+	})
+
+	registry := newTestRegistry(t)
+	registry.osvClient = osvMock.client()
+	registry.depsDevClient = depsMock.client()
+	registry.kevClient = newMockKEV(t)
+
+	result, err := registry.HandleVulns(context.Background(), VulnsInput{
+		Ecosystem: "nuget",
+		Package:   "newtonsoft.json",
+		Version:   "12.0.1",
+	})
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+
+	if result.Ecosystem != "NuGet" || result.Package != "Newtonsoft.Json" {
+		t.Errorf("resolved package = %s/%s, want NuGet/Newtonsoft.Json", result.Ecosystem, result.Package)
+	}
+	if result.VulnerabilityCount != 1 {
+		t.Errorf("VulnerabilityCount = %d, want 1", result.VulnerabilityCount)
+	}
+}
+
+func TestResolvedQuery(t *testing.T) {
+	newtonsoft := &depsdev.PackageInfo{
+		PackageKey: depsdev.PackageKey{System: "NUGET", Name: "Newtonsoft.Json"},
+		Versions: []depsdev.VersionInfo{
+			{VersionKey: depsdev.VersionKey{Version: "12.0.1"}, PublishedAt: time.Now().Add(-1000 * 24 * time.Hour)},
+			{VersionKey: depsdev.VersionKey{Version: "13.0.3"}, PublishedAt: time.Now().Add(-30 * 24 * time.Hour), IsDefault: true},
+		},
+	}
+	osvMock := newMockOSV(t, map[string][]osv.Vulnerability{})
+	depsMock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"nuget/newtonsoft.json": newtonsoft,
+		"nuget/Newtonsoft.Json": newtonsoft,
+	})
+	registry := newTestRegistry(t)
+	registry.osvClient = osvMock.client()
+	registry.depsDevClient = depsMock.client()
+
+	// A client guessing the casing and padding its input
+	want := ResolvedQuery{Ecosystem: "NuGet", Package: "Newtonsoft.Json", Version: "12.0.1"}
+	messy := VulnsInput{Ecosystem: "NUGET", Package: " newtonsoft.json ", Version: "12.0.1 "}
+
+	vulns, err := registry.HandleVulns(context.Background(), messy)
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+	if vulns.ResolvedQuery == nil || *vulns.ResolvedQuery != want {
+		t.Errorf("deps.vulns resolved_query = %+v, want %+v", vulns.ResolvedQuery, want)
+	}
+
+	result, err := registry.HandleHealth(context.Background(), healthRequest(t, messy))
+	if err != nil || result.IsError {
+		t.Fatalf("HandleHealth() = %v, %v", result, err)
+	}
+	var health HealthOutput
+	if err := json.Unmarshal([]byte(resultText(t, result)), &health); err != nil {
+		t.Fatalf("decode health: %v", err)
+	}
+	if health.ResolvedQuery == nil || *health.ResolvedQuery != want {
+		t.Errorf("deps.health resolved_query = %+v, want %+v", health.ResolvedQuery, want)
+	}
+
+	plan, err := registry.planUpgrade(context.Background(), UpgradePlanInput{Ecosystem: messy.Ecosystem, Package: messy.Package, CurrentVersion: messy.Version})
+	if err != nil {
+		t.Fatalf("planUpgrade() error = %v", err)
+	}
+	if plan.ResolvedQuery == nil || *plan.ResolvedQuery != want {
+		t.Errorf("deps.upgrade_plan resolved_query = %+v, want %+v", plan.ResolvedQuery, want)
+	}
+}
+
+func TestNormalizePackage(t *testing.T) {
+	depsMock := newMockDepsDev(t, nil)
+
+	registry := newTestRegistry(t)
+	registry.depsDevClient = depsMock.client()
+
+	// Case-sensitive ecosystems are passed through without a lookup
+	eco, name := registry.normalizePackage(context.Background(), "npm", "Lodash")
+	if eco != "npm" || name != "Lodash" {
+		t.Errorf("normalizePackage(npm) = %s/%s, want npm/Lodash", eco, name)
+	}
+	if n := depsMock.requests.Load(); n != 0 {
+		t.Errorf("deps.dev received %d requests for npm, want 0", n)
+	}
+
+	// Unknown NuGet IDs fall back to the name as given
+	eco, name = registry.normalizePackage(context.Background(), "NUGET", "no.such.package")
+	if eco != "NuGet" || name != "no.such.package" {
+		t.Errorf("normalizePackage(NUGET) = %s/%s, want NuGet/no.such.package", eco, name)
+	}
+}
+
+func TestCanonicalGoModule(t *testing.T) {
+	tests := []struct {
+		name, path, version string
+	}{
+		{"github.com/foo/bar/v2@v2.1.0", "github.com/foo/bar/v2", "v2.1.0"},
+		{" github.com/foo/bar/v2 ", "github.com/foo/bar/v2", ""},
+		{"https://github.com/Foo/bar/V3/", "github.com/Foo/bar/v3", ""},
+		{"github.com/foo/bar.git@v1.4.0", "github.com/foo/bar", "v1.4.0"},
+		{"gopkg.in/yaml.v3", "gopkg.in/yaml.v3", ""},
+		// Not major-version suffixes
+		{"github.com/foo/v0", "github.com/foo/v0", ""},
+		{"github.com/foo/vendor", "github.com/foo/vendor", ""},
+	}
+	for _, tt := range tests {
+		if path, version := canonicalGoModule(tt.name); path != tt.path || version != tt.version {
+			t.Errorf("canonicalGoModule(%q) = %q, %q; want %q, %q", tt.name, path, version, tt.path, tt.version)
+		}
+	}
+}
+
+func TestHandleVulns_GoModuleVersionSuffix(t *testing.T) {
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"Go/github.com/foo/bar/v2@v2.1.0": {{ID: "GO-2099-0002"}},
+	})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	out, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "go", Package: "github.com/foo/bar/v2@v2.1.0"})
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+	want := ResolvedQuery{Ecosystem: "Go", Package: "github.com/foo/bar/v2", Version: "v2.1.0"}
+	if out.ResolvedQuery == nil || *out.ResolvedQuery != want {
+		t.Errorf("resolved_query = %+v, want %+v", out.ResolvedQuery, want)
+	}
+	if out.VulnerabilityCount != 1 {
+		t.Errorf("vulnerability_count = %d, want the v2 module's finding", out.VulnerabilityCount)
+	}
+
+	_, err = registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "go", Package: "github.com/foo/bar/v2@v2.1.0", Version: "v2.0.0"})
+	if !errors.Is(err, errInvalidInput) {
+		t.Errorf("conflicting versions error = %v, want invalid input", err)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   string
+		shared bool
+	}{
+		{"ecosystem casing", cacheKey("vulns", "npm", "lodash", "4.17.19"), cacheKey("vulns", "NPM", "lodash", "4.17.19"), true},
+		{"whitespace", cacheKey("vulns", "npm", "lodash", "4.17.19"), cacheKey("vulns", " npm", "lodash ", " 4.17.19 "), true},
+		{"case-insensitive ecosystem", cacheKey("health", "pypi", "Django", ""), cacheKey("health", "PyPI", "django", ""), true},
+		{"case-sensitive ecosystem", cacheKey("health", "Maven", "Com.Example:lib", ""), cacheKey("health", "Maven", "com.example:lib", ""), false},
+		{"different versions", cacheKey("upgrade", "npm", "lodash", "1.0.0"), cacheKey("upgrade", "npm", "lodash", "1.0.1"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a == tt.b; got != tt.shared {
+				t.Errorf("keys %q and %q shared = %v, want %v", tt.a, tt.b, got, tt.shared)
+			}
+		})
+	}
+}
+
+func TestHandleVulns_EcosystemCasingSharesCache(t *testing.T) {
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/lodash@4.17.19": {{ID: "GHSA-p6mc-m468-83gw"}},
+	})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	if _, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "npm", Package: "lodash", Version: "4.17.19"}); err != nil {
+		t.Fatalf("HandleVulns(npm) error = %v", err)
+	}
+	// Cache writes land asynchronously
+	key := cacheKey("vulns", "npm", "lodash", "4.17.19", "", "", "", "0", "false")
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := registry.cache.Get(key); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("cache entry %q was never written", key)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	before := mock.requests.Load()
+
+	result, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "NPM", Package: " lodash ", Version: "4.17.19 "})
+	if err != nil {
+		t.Fatalf("HandleVulns(NPM) error = %v", err)
+	}
+	if n := mock.requests.Load(); n != before {
+		t.Errorf("OSV requests = %d after the NPM lookup, want %d (cache hit)", n, before)
+	}
+	if result.VulnerabilityCount != 1 {
+		t.Errorf("VulnerabilityCount = %d, want 1", result.VulnerabilityCount)
+	}
+}
+
+func TestNormalizeEcosystem(t *testing.T) {
+	tests := map[string]string{
+		"pypi":                 "PyPI",
+		"GO":                   "Go",
+		"cargo":                "crates.io",
+		"crates.io":            "crates.io",
+		"rubygems":             "RubyGems",
+		"gem":                  "RubyGems",
+		"nuget":                "NuGet",
+		"Unknown":              "Unknown",
+		"debian:11":            "Debian:11",
+		"Alpine":               "Alpine",
+		"rocky linux:9":        "Rocky Linux:9",
+		"Ubuntu:Pro:18.04:LTS": "Ubuntu:Pro:18.04:LTS",
+		"Debian:":              "Debian:",
+	}
+	for in, want := range tests {
+		if got := normalizeEcosystem(in); got != want {
+			t.Errorf("normalizeEcosystem(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestHandleVulns_OSEcosystemPassedThrough(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		_ = json.NewEncoder(w).Encode(osv.QueryResponse{Vulns: []osv.Vulnerability{{ID: "DSA-5417-1"}}})
+	}))
+	t.Cleanup(server.Close)
+	registry := newTestRegistry(t)
+	registry.osvClient = osv.NewClient(zap.NewNop(), osv.WithBaseURL(server.URL))
+
+	result, err := registry.HandleVulns(context.Background(), VulnsInput{
+		Ecosystem: "Debian:11",
+		Package:   "openssl",
+		Version:   "1.1.1n-0+deb11u4",
+	})
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+	if result.Ecosystem != "Debian:11" || result.VulnerabilityCount != 1 {
+		t.Errorf("result = %s with %d findings, want Debian:11 with 1", result.Ecosystem, result.VulnerabilityCount)
+	}
+	if len(bodies) != 1 || !strings.Contains(bodies[0], `"ecosystem":"Debian:11"`) {
+		t.Errorf("OSV query bodies = %q, want ecosystem Debian:11 verbatim", bodies)
+	}
+
+	// OS ecosystems have no deps.dev data, so the package tools still reject them
+	if _, err := validateEcosystem("Debian:11"); !errors.Is(err, errInvalidInput) {
+		t.Errorf("validateEcosystem(Debian:11) error = %v, want INVALID_INPUT", err)
+	}
+}
+
+func TestValidateEcosystem(t *testing.T) {
+	if got, err := validateEcosystem(" PyPI "); err != nil || got != "PyPI" {
+		t.Errorf("validateEcosystem(PyPI) = %q, %v; want PyPI", got, err)
+	}
+
+	typos := map[string]string{
+		"npmjs":    "npm",
+		"py":       "PyPI",
+		"pip":      "PyPI",
+		"golang":   "Go",
+		"crates":   "crates.io",
+		"rubygem":  "RubyGems",
+		"mavne":    "Maven",
+		"nugget":   "NuGet",
+		"packagst": "Packagist",
+	}
+	for in, want := range typos {
+		_, err := validateEcosystem(in)
+		if !errors.Is(err, errInvalidInput) {
+			t.Errorf("validateEcosystem(%q) error = %v, want INVALID_INPUT", in, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), fmt.Sprintf("did you mean %q", want)) {
+			t.Errorf("validateEcosystem(%q) = %v, want suggestion %q", in, err, want)
+		}
+		if !strings.Contains(err.Error(), "Packagist, PyPI, RubyGems") {
+			t.Errorf("validateEcosystem(%q) = %v, want the list of valid ecosystems", in, err)
+		}
+	}
+
+	_, err := validateEcosystem("cobol")
+	if err == nil || strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("validateEcosystem(cobol) = %v, want error without a suggestion", err)
+	}
+}
+
+func TestHandlers_RejectUnknownEcosystem(t *testing.T) {
+	registry := newTestRegistry(t)
+	ctx := context.Background()
+
+	if _, err := registry.HandleVulns(ctx, VulnsInput{Ecosystem: "npmjs", Package: "lodash"}); !errors.Is(err, errInvalidInput) {
+		t.Errorf("HandleVulns() error = %v, want INVALID_INPUT", err)
+	}
+	batch, err := registry.HandleBatchVulns(ctx, BatchVulnsInput{Packages: []VulnsInput{{Ecosystem: "py", Package: "requests"}}})
+	if err != nil || batch.ErrorCount != 1 || !strings.Contains(batch.Results[0].Error, errInvalidInput.Error()) {
+		t.Errorf("HandleBatchVulns() = %+v, %v; want an INVALID_INPUT entry error", batch, err)
+	}
+	if _, err := registry.planUpgrade(ctx, UpgradePlanInput{Ecosystem: "golang", Package: "x", CurrentVersion: "1.0.0"}); !errors.Is(err, errInvalidInput) {
+		t.Errorf("planUpgrade() error = %v, want INVALID_INPUT", err)
+	}
+
+	result, err := registry.HandleHealth(ctx, healthRequest(t, VulnsInput{Ecosystem: "mavne", Package: "x"}))
+	if err != nil {
+		t.Fatalf("HandleHealth() error = %v", err)
+	}
+	if !result.IsError || !strings.Contains(resultText(t, result), `did you mean "Maven"`) {
+		t.Errorf("HandleHealth() = %s, want INVALID_INPUT error with suggestion", resultText(t, result))
+	}
+}
@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rayprogramming/PackagePulse/internal/manifest"
+	"github.com/rayprogramming/PackagePulse/internal/pool"
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"github.com/rayprogramming/PackagePulse/internal/providers/packagist"
+)
+
+// isPackageNotFound reports whether err means the registry has no package with the name
+func isPackageNotFound(err error) bool {
+	return errors.Is(err, depsdev.ErrPackageNotFound) || errors.Is(err, packagist.ErrNotFound)
+}
+
+// explainNotFound adds diagnostics to a package-not-found error: a note when the name does
+// not follow the ecosystem's naming rules, and the other ecosystems where a package by that
+// name exists. Other errors are returned unchanged. Only ecosystems whose naming rules the
+// name satisfies are looked up, in parallel: a name with a slash or colon rules out most of
+// them, while a plain lowercase name is valid almost everywhere and costs four or five requests.
+func (tr *ToolRegistry) explainNotFound(ctx context.Context, ecosystem, name string, err error) error {
+	if !isPackageNotFound(err) {
+		return err
+	}
+
+	var hints []string
+	if problem := nameFormatProblem(ecosystem, name); problem != "" {
+		hints = append(hints, problem)
+	}
+
+	var candidates []string
+	for _, other := range supportedEcosystems() {
+		if other != ecosystem && nameFormatProblem(other, name) == "" {
+			candidates = append(candidates, other)
+		}
+	}
+	found, _ := pool.Map(ctx, candidates, len(candidates), func(ctx context.Context, other string) (bool, error) {
+		_, err := tr.getPackageInfo(ctx, other, name)
+		return err == nil, err
+	})
+	var elsewhere []string
+	for i, result := range found {
+		if result.Value {
+			elsewhere = append(elsewhere, candidates[i])
+		}
+	}
+	if len(elsewhere) > 0 {
+		hints = append(hints, fmt.Sprintf("%s exists in %s; did you mean ecosystem %q?",
+			name, strings.Join(elsewhere, ", "), elsewhere[0]))
+	}
+
+	if len(hints) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w; %s", err, strings.Join(hints, "; "))
+}
+
+// nameFormatProblem describes why a name cannot be a package in the ecosystem, or returns ""
+// when it looks plausible. The checks catch obvious mix-ups such as a Go module path queried
+// as an npm package, not every rule each registry enforces.
+func nameFormatProblem(ecosystem, name string) string {
+	switch ecosystem {
+	case "Go":
+		first, _, _ := strings.Cut(name, "/")
+		if !strings.Contains(first, ".") {
+			return "Go modules are named by module path, such as github.com/gin-gonic/gin"
+		}
+	case "npm":
+		if name != strings.ToLower(name) {
+			return "npm package names are lowercase"
+		}
+		if scope, rest, scoped := strings.Cut(name, "/"); scoped {
+			if !strings.HasPrefix(scope, "@") || scope == "@" || rest == "" || strings.Contains(rest, "/") {
+				return "npm package names only contain a slash after an @scope, as in @babel/core"
+			}
+		} else if strings.HasPrefix(name, "@") {
+			return "scoped npm packages are named @scope/name"
+		}
+	case "Maven":
+		group, artifact, ok := strings.Cut(name, ":")
+		if !ok || group == "" || artifact == "" || strings.ContainsAny(artifact, ":/") {
+			return "Maven packages are named groupId:artifactId, such as org.slf4j:slf4j-api"
+		}
+	case manifest.EcosystemPackagist:
+		vendor, pkg, ok := strings.Cut(name, "/")
+		if !ok || vendor == "" || pkg == "" || strings.Contains(pkg, "/") {
+			return "Packagist packages are named vendor/package, such as monolog/monolog"
+		}
+	case "PyPI", "crates.io", "RubyGems", osv.EcosystemNuGet:
+		if strings.ContainsAny(name, "/:@ ") {
+			return fmt.Sprintf("%s package names cannot contain %q", ecosystem, string(name[strings.IndexAny(name, "/:@ ")]))
+		}
+	}
+	return ""
+}
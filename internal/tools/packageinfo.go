@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/rayprogramming/PackagePulse/internal/manifest"
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/packagist"
+)
+
+// getPackageInfo fetches release metadata for health and upgrade analysis.
+// deps.dev does not index Packagist, so Composer packages are read from the
+// Packagist metadata API and converted to the deps.dev shape.
+func (tr *ToolRegistry) getPackageInfo(ctx context.Context, ecosystem, name string) (*depsdev.PackageInfo, error) {
+	if ecosystem != manifest.EcosystemPackagist {
+		return tr.depsDevClient.GetPackage(ctx, ecosystem, name)
+	}
+
+	versions, err := tr.packagistClient.GetVersions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return packagistPackageInfo(name, versions), nil
+}
+
+// packagistPackageInfo converts Packagist releases (newest first) to deps.dev package info.
+// The newest stable release is marked as the default version.
+func packagistPackageInfo(name string, versions []packagist.Version) *depsdev.PackageInfo {
+	info := &depsdev.PackageInfo{
+		PackageKey: depsdev.PackageKey{System: manifest.EcosystemPackagist, Name: name},
+	}
+
+	defaultIdx := -1
+	for i, v := range versions {
+		if defaultIdx < 0 && packagist.IsStable(v.Version) {
+			defaultIdx = i
+		}
+		info.Versions = append(info.Versions, depsdev.VersionInfo{
+			VersionKey: depsdev.VersionKey{
+				System:  manifest.EcosystemPackagist,
+				Name:    name,
+				Version: packagist.TrimVersionPrefix(v.Version),
+			},
+			PublishedAt: v.Time,
+			Licenses:    v.License,
+		})
+	}
+	if defaultIdx < 0 && len(versions) > 0 {
+		defaultIdx = 0
+	}
+
+	if defaultIdx >= 0 {
+		info.Versions[defaultIdx].IsDefault = true
+		latest := versions[defaultIdx]
+		if latest.Source != nil && latest.Source.URL != "" {
+			info.Links = append(info.Links, depsdev.Link{Label: "SOURCE_REPO", URL: latest.Source.URL})
+		}
+		if latest.Homepage != "" {
+			info.Links = append(info.Links, depsdev.Link{Label: "HOMEPAGE", URL: latest.Homepage})
+		}
+	}
+
+	return info
+}
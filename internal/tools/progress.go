@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// progressInterval is the minimum gap between two progress notifications for one call
+const progressInterval = 250 * time.Millisecond
+
+type progressKey struct{}
+
+// progressNotifier forwards throttled "N of M" updates for a single tool call.
+// The final update (done == total) is always sent so clients see completion.
+type progressNotifier struct {
+	send     func(done, total int)
+	interval time.Duration
+
+	mu       sync.Mutex
+	lastSent time.Time
+	lastDone int
+}
+
+func newProgressNotifier(send func(done, total int), interval time.Duration) *progressNotifier {
+	return &progressNotifier{send: send, interval: interval, lastDone: -1}
+}
+
+func (p *progressNotifier) report(done, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if done <= p.lastDone {
+		return
+	}
+	if done < total && time.Since(p.lastSent) < p.interval {
+		return
+	}
+	p.lastSent = time.Now()
+	p.lastDone = done
+	p.send(done, total)
+}
+
+// withProgress lets the handler report progress when the caller supplied a progress token.
+// Calls without a token, or sessions that cannot deliver notifications, run unchanged.
+func withProgress(handler mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if req == nil || req.Session == nil || req.Params == nil {
+			return handler(ctx, req)
+		}
+		token := req.Params.GetProgressToken()
+		if token == nil {
+			return handler(ctx, req)
+		}
+
+		notifier := newProgressNotifier(func(done, total int) {
+			// Delivery failures only lose an informational update
+			_ = req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				ProgressToken: token,
+				Message:       fmt.Sprintf("%d of %d packages scanned", done, total),
+				Progress:      float64(done),
+				Total:         float64(total),
+			})
+		}, progressInterval)
+		return handler(context.WithValue(ctx, progressKey{}, notifier), req)
+	}
+}
+
+// reportProgress emits a progress update if the current tool call asked for one
+func reportProgress(ctx context.Context, done, total int) {
+	if notifier, ok := ctx.Value(progressKey{}).(*progressNotifier); ok {
+		notifier.report(done, total)
+	}
+}
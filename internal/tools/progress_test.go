@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"github.com/rayprogramming/hypermcp"
+	"go.uber.org/zap"
+)
+
+func TestBatchVulns_ProgressNotifications(t *testing.T) {
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	srv, err := hypermcp.New(hypermcp.Config{Name: "test", Version: "1.0.0"}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	if err := registry.Register(srv); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	var (
+		mu      sync.Mutex
+		updates []*mcp.ProgressNotificationParams
+		done    = make(chan struct{})
+	)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			updates = append(updates, req.Params)
+			if req.Params.Progress == req.Params.Total {
+				close(done)
+			}
+		},
+	})
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.MCP().Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+
+	total := 2*batchChunkSize + 50
+	packages := make([]map[string]string, total)
+	for i := range packages {
+		packages[i] = map[string]string{"ecosystem": "npm", "package": fmt.Sprintf("pkg-%d", i), "version": "1.0.0"}
+	}
+	// SetProgressToken only writes into an existing Meta map
+	params := &mcp.CallToolParams{
+		Meta:      mcp.Meta{},
+		Name:      "deps.batch_vulns",
+		Arguments: map[string]interface{}{"packages": packages},
+	}
+	params.SetProgressToken("scan-1")
+
+	result, err := session.CallTool(ctx, params)
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("deps.batch_vulns returned error result: %s", resultText(t, result))
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the final progress notification")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	last := 0.0
+	for _, u := range updates {
+		if u.ProgressToken != "scan-1" {
+			t.Errorf("ProgressToken = %v, want scan-1", u.ProgressToken)
+		}
+		if u.Total != float64(total) {
+			t.Errorf("Total = %v, want %d", u.Total, total)
+		}
+		if u.Progress <= last {
+			t.Errorf("progress went from %v to %v, want strictly increasing", last, u.Progress)
+		}
+		last = u.Progress
+	}
+	if last != float64(total) {
+		t.Errorf("final progress = %v, want %d", last, total)
+	}
+	if got := mock.requests.Load(); got != 3 {
+		t.Errorf("OSV requests = %d, want 3 chunks", got)
+	}
+}
+
+func TestProgressNotifier_Throttles(t *testing.T) {
+	var sent [][2]int
+	notifier := newProgressNotifier(func(done, total int) {
+		sent = append(sent, [2]int{done, total})
+	}, time.Hour)
+
+	for done := 1; done <= 10; done++ {
+		notifier.report(done, 10)
+	}
+
+	// First update goes out immediately, the rest are throttled until completion
+	want := [][2]int{{1, 10}, {10, 10}}
+	if fmt.Sprint(sent) != fmt.Sprint(want) {
+		t.Errorf("sent = %v, want %v", sent, want)
+	}
+}
+
+func TestWithProgress_NoToken(t *testing.T) {
+	handler := withProgress(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if _, ok := ctx.Value(progressKey{}).(*progressNotifier); ok {
+			t.Error("expected no progress notifier without a progress token")
+		}
+		reportProgress(ctx, 1, 1)
+		return &mcp.CallToolResult{}, nil
+	})
+	if _, err := handler(context.Background(), &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{}}); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+}
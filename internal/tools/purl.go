@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// parsePackageURL splits a package URL (pkg:type/namespace/name@version?qualifiers#subpath)
+// into the OSV ecosystem, package name, and version it names. Maven's namespace and name join
+// as group:artifact; other namespaces stay part of the name, as in npm scopes and Go module
+// paths. Qualifiers and subpath do not affect which advisories apply and are dropped.
+func parsePackageURL(purl string) (ecosystem, name, version string, err error) {
+	rest, ok := cutPrefixFold(strings.TrimSpace(purl), "pkg:")
+	if !ok {
+		return "", "", "", fmt.Errorf("%w: purl %q must start with pkg:", errInvalidInput, purl)
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, _, _ = strings.Cut(rest, "?")
+	rest = strings.Trim(rest, "/")
+
+	// The version follows the last "@" of the final segment; an npm scope's "@" comes earlier
+	if at := strings.LastIndex(rest, "@"); at > strings.LastIndex(rest, "/") {
+		rest, version = rest[:at], rest[at+1:]
+		if version, err = url.PathUnescape(version); err != nil {
+			return "", "", "", fmt.Errorf("%w: purl %q has a malformed version: %v", errInvalidInput, purl, err)
+		}
+	}
+
+	purlType, path, ok := strings.Cut(rest, "/")
+	if !ok || path == "" {
+		return "", "", "", fmt.Errorf("%w: purl %q must name a type and a package", errInvalidInput, purl)
+	}
+	ecosystem = purlEcosystem(strings.ToLower(purlType))
+	if ecosystem == "" {
+		return "", "", "", fmt.Errorf("%w: unsupported purl type %q; supported types: %s", errInvalidInput, purlType, strings.Join(supportedPURLTypes(), ", "))
+	}
+
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if segments[i], err = url.PathUnescape(s); err != nil {
+			return "", "", "", fmt.Errorf("%w: purl %q has a malformed name: %v", errInvalidInput, purl, err)
+		}
+	}
+	name = strings.Join(segments, "/")
+	if ecosystem == "Maven" {
+		if len(segments) != 2 {
+			return "", "", "", fmt.Errorf("%w: maven purl %q must name a group and an artifact", errInvalidInput, purl)
+		}
+		name = segments[0] + ":" + segments[1]
+	}
+	return ecosystem, name, version, nil
+}
+
+// purlEcosystem returns the OSV ecosystem of a package URL type, or "" when none matches
+func purlEcosystem(purlType string) string {
+	for ecosystem, t := range purlTypes {
+		if t == purlType {
+			return ecosystem
+		}
+	}
+	return ""
+}
+
+// supportedPURLTypes lists the package URL types parsePackageURL accepts, sorted
+func supportedPURLTypes() []string {
+	types := make([]string, 0, len(purlTypes))
+	for _, t := range purlTypes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// cutPrefixFold is strings.CutPrefix ignoring case
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+)
+
+func TestParsePackageURL(t *testing.T) {
+	tests := []struct {
+		purl                     string
+		ecosystem, name, version string
+	}{
+		{"pkg:npm/lodash@4.17.19", "npm", "lodash", "4.17.19"},
+		{"pkg:npm/%40babel/core@7.24.0", "npm", "@babel/core", "7.24.0"},
+		{"pkg:npm/@babel/core@7.24.0", "npm", "@babel/core", "7.24.0"},
+		{"pkg:npm/@babel/core", "npm", "@babel/core", ""},
+		{"pkg:golang/github.com/gin-gonic/gin@v1.9.1", "Go", "github.com/gin-gonic/gin", "v1.9.1"},
+		{"pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1?type=jar", "Maven", "org.apache.logging.log4j:log4j-core", "2.14.1"},
+		{"pkg:pypi/django@1.11.1#src", "PyPI", "django", "1.11.1"},
+		{"PKG:Cargo/serde@1.0.0", "crates.io", "serde", "1.0.0"},
+		{"pkg:composer/symfony/console@5.4.0", "Packagist", "symfony/console", "5.4.0"},
+	}
+	for _, tt := range tests {
+		ecosystem, name, version, err := parsePackageURL(tt.purl)
+		if err != nil {
+			t.Errorf("parsePackageURL(%s) error = %v", tt.purl, err)
+			continue
+		}
+		if ecosystem != tt.ecosystem || name != tt.name || version != tt.version {
+			t.Errorf("parsePackageURL(%s) = %s, %s, %s; want %s, %s, %s", tt.purl, ecosystem, name, version, tt.ecosystem, tt.name, tt.version)
+		}
+	}
+
+	for _, purl := range []string{"npm/lodash@4.17.19", "pkg:npm", "pkg:deb/debian/curl@7.50.3", "pkg:maven/log4j-core@2.14.1"} {
+		if _, _, _, err := parsePackageURL(purl); !errors.Is(err, errInvalidInput) {
+			t.Errorf("parsePackageURL(%s) error = %v, want INVALID_INPUT", purl, err)
+		}
+	}
+}
+
+func TestHandleVulns_PURL(t *testing.T) {
+	osvMock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"pkg:npm/lodash@4.17.19": {{ID: "GHSA-35jh-r3h4-6jhm", Aliases: []string{"CVE-2021-23337"}}},
+	})
+	registry := newTestRegistry(t)
+	registry.osvClient = osvMock.client()
+
+	output, err := registry.HandleVulns(context.Background(), VulnsInput{PURL: "pkg:npm/lodash@4.17.19"})
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+	if output.VulnerabilityCount != 1 || output.Vulnerabilities[0].ID != "GHSA-35jh-r3h4-6jhm" {
+		t.Errorf("findings = %+v, want GHSA-35jh-r3h4-6jhm", output.Vulnerabilities)
+	}
+	if output.Ecosystem != "npm" || output.Package != "lodash" || output.Version != "4.17.19" {
+		t.Errorf("output = %s/%s@%s, want npm/lodash@4.17.19 from the purl", output.Ecosystem, output.Package, output.Version)
+	}
+
+	if _, err := registry.HandleVulns(context.Background(), VulnsInput{PURL: "pkg:npm/lodash", Version: "4.17.19"}); !errors.Is(err, errInvalidInput) {
+		t.Errorf("HandleVulns(purl and version) error = %v, want INVALID_INPUT", err)
+	}
+}
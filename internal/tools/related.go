@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"go.uber.org/zap"
+)
+
+// maxRelatedDepth bounds how many links are followed from a finding in search of a severity,
+// so entries that point at each other cannot send the search in circles
+const maxRelatedDepth = 2
+
+// osvAdvisoryCacheTTL keeps OSV entries fetched by ID while following related links
+const osvAdvisoryCacheTTL = 24 * time.Hour
+
+// inheritRelatedSeverity gives each finding without a usable severity the severity of the
+// nearest upstream or related entry that has one, at most maxRelatedDepth links away.
+// Upstream links are tried before related ones at each step, since they name the advisory an
+// ecosystem entry defers to. Entries that cannot be fetched are logged and skipped, leaving
+// the finding unrated.
+func (tr *ToolRegistry) inheritRelatedSeverity(ctx context.Context, findings []Finding) {
+	for i := range findings {
+		f := &findings[i]
+		if _, ok := baseScore(f.Vulnerability); ok || (len(f.Upstream) == 0 && len(f.Related) == 0) {
+			continue
+		}
+		if source := tr.relatedSeverity(ctx, f.Vulnerability); source != nil {
+			f.Severity = source.Severity
+			f.SeverityInheritedFrom = source.ID
+		}
+	}
+}
+
+// relatedSeverity searches the entries linked from vuln breadth-first for one with a usable
+// severity, returning nil when none is found within maxRelatedDepth links
+func (tr *ToolRegistry) relatedSeverity(ctx context.Context, vuln osv.Vulnerability) *osv.Vulnerability {
+	visited := map[string]bool{vuln.ID: true}
+	frontier := []osv.Vulnerability{vuln}
+	for depth := 0; depth < maxRelatedDepth && len(frontier) > 0; depth++ {
+		var next []osv.Vulnerability
+		for _, v := range frontier {
+			for _, id := range append(slices.Clone(v.Upstream), v.Related...) {
+				if visited[id] {
+					continue
+				}
+				visited[id] = true
+				linked, err := tr.osvAdvisory(ctx, id)
+				if err != nil {
+					tr.log(ctx).Warn("Failed to fetch linked OSV entry",
+						zap.String("id", vuln.ID),
+						zap.String("linked", id),
+						zap.Error(err))
+					continue
+				}
+				if _, ok := baseScore(*linked); ok {
+					return linked
+				}
+				next = append(next, *linked)
+			}
+		}
+		frontier = next
+	}
+	return nil
+}
+
+// osvAdvisory fetches one OSV entry by ID, caching it
+func (tr *ToolRegistry) osvAdvisory(ctx context.Context, id string) (*osv.Vulnerability, error) {
+	cacheKey := "osv-advisory:" + id
+	if tr.cache != nil {
+		if cached, found := tr.cache.Get(cacheKey); found {
+			if vuln, ok := cached.(*osv.Vulnerability); ok {
+				return vuln, nil
+			}
+		}
+	}
+
+	vuln, err := tr.osvClient.GetVulnerability(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tr.cache != nil {
+		tr.cache.Set(cacheKey, vuln, osvAdvisoryCacheTTL)
+	}
+	return vuln, nil
+}
@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+)
+
+func TestHandleVulns_FollowRelated(t *testing.T) {
+	critical := []osv.Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"Debian:12/openssl@3.0.9-1": {
+			// Defers to the CVE for its severity
+			{ID: "DSA-5532-1", Upstream: []string{"CVE-2023-5363"}},
+			// Links only in a loop, or further away than the search goes
+			{ID: "DSA-0001-1", Related: []string{"DSA-0002-1"}},
+			{ID: "DSA-0003-1", Related: []string{"DSA-0004-1"}},
+		},
+		// Served only by ID
+		"linked": {
+			{ID: "CVE-2023-5363", Related: []string{"DSA-5532-1"}, Severity: critical},
+			{ID: "DSA-0002-1", Related: []string{"DSA-0001-1"}},
+			{ID: "DSA-0004-1", Related: []string{"DSA-0005-1"}},
+			{ID: "DSA-0005-1", Related: []string{"DSA-0006-1"}},
+			{ID: "DSA-0006-1", Severity: critical},
+		},
+	})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+	input := VulnsInput{Ecosystem: "Debian:12", Package: "openssl", Version: "3.0.9-1"}
+
+	plain, err := registry.HandleVulns(context.Background(), input)
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+	if plain.Summary.Unknown != 3 || mock.details.Load() != 0 {
+		t.Errorf("without follow_related summary = %+v after %d fetches, want 3 unknown and no fetches", plain.Summary, mock.details.Load())
+	}
+
+	input.FollowRelated = true
+	result, err := registry.HandleVulns(context.Background(), input)
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+	inherited := make(map[string]string)
+	for _, f := range result.Vulnerabilities {
+		inherited[f.ID] = f.SeverityInheritedFrom
+		if f.ID == "DSA-5532-1" && severityRating(f.Vulnerability) != "critical" {
+			t.Errorf("DSA-5532-1 severity = %v, want the CVE's critical vector", f.Severity)
+		}
+	}
+	want := map[string]string{"DSA-5532-1": "CVE-2023-5363", "DSA-0001-1": "", "DSA-0003-1": ""}
+	for id, from := range want {
+		if inherited[id] != from {
+			t.Errorf("%s severity_inherited_from = %q, want %q", id, inherited[id], from)
+		}
+	}
+	if result.Summary.Critical != 1 || result.Summary.Unknown != 2 {
+		t.Errorf("summary = %+v, want 1 critical and 2 unknown", result.Summary)
+	}
+}
@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"go.uber.org/zap"
+)
+
+// PackageReport consolidates vulnerability and health data for one package
+type PackageReport struct {
+	Ecosystem       string                 `json:"ecosystem"`
+	Package         string                 `json:"package"`
+	Version         string                 `json:"version,omitempty"`
+	Vulnerabilities *VulnsOutput           `json:"vulnerabilities,omitempty"`
+	Health          *depsdev.HealthMetrics `json:"health,omitempty"`
+	Licenses        *DeclaredLicenses      `json:"licenses,omitempty"`
+	Errors          []string               `json:"errors,omitempty"`
+}
+
+// HandlePackageReport builds the consolidated report served by the packagepulse://package
+// resource templates, reusing the deps.vulns and deps.health logic and their caches.
+// A section that fails is listed under errors; the report fails only if both do.
+func (tr *ToolRegistry) HandlePackageReport(ctx context.Context, ecosystem, name, version string) (*PackageReport, error) {
+	if name == "" {
+		return nil, fmt.Errorf("%w: package is required", errInvalidInput)
+	}
+	ecosystem, err := validateEcosystem(ecosystem)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, tr.config.Timeout)
+	defer cancel()
+
+	tr.log(ctx).Info("Handling package report",
+		zap.String("ecosystem", ecosystem),
+		zap.String("package", name),
+		zap.String("version", version))
+
+	report := &PackageReport{Ecosystem: ecosystem, Package: name, Version: version}
+
+	vulns, err := tr.HandleVulns(ctx, VulnsInput{Ecosystem: ecosystem, Package: name, Version: version})
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("vulnerabilities: %v", err))
+	} else {
+		report.Vulnerabilities = vulns
+		report.Package = vulns.Package
+	}
+
+	health, err := tr.packageHealth(ctx, ecosystem, name, version)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("health: %v", err))
+	} else {
+		report.Health = health
+		declared := health.Licenses
+		if version != "" {
+			declared = health.VersionLicenses
+		}
+		report.Licenses = tr.declaredLicenses(ctx, declared)
+	}
+
+	if report.Vulnerabilities == nil && report.Health == nil {
+		return nil, fmt.Errorf("package report for %s/%s failed: %s", ecosystem, name, strings.Join(report.Errors, "; "))
+	}
+	return report, nil
+}
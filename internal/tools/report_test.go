@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+)
+
+func TestHandlePackageReport(t *testing.T) {
+	now := time.Now()
+	lodash := &depsdev.PackageInfo{
+		PackageKey: depsdev.PackageKey{System: "NPM", Name: "lodash"},
+		Versions: []depsdev.VersionInfo{
+			{VersionKey: depsdev.VersionKey{Version: "4.17.19"}, PublishedAt: now.Add(-900 * 24 * time.Hour), Licenses: []string{"MIT"}},
+			{VersionKey: depsdev.VersionKey{Version: "4.17.21"}, PublishedAt: now.Add(-800 * 24 * time.Hour), Licenses: []string{"MIT"}, IsDefault: true},
+		},
+	}
+	osvMock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/lodash@4.17.19": {{ID: "GHSA-35jh-r3h4-6jhm", Aliases: []string{"CVE-2021-23337"}}},
+	})
+	depsMock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{"npm/lodash": lodash})
+
+	registry := newTestRegistry(t)
+	registry.osvClient = osvMock.client()
+	registry.depsDevClient = depsMock.client()
+
+	report, err := registry.HandlePackageReport(context.Background(), "npm", "lodash", "4.17.19")
+	if err != nil {
+		t.Fatalf("HandlePackageReport() error = %v", err)
+	}
+	if report.Vulnerabilities == nil || report.Vulnerabilities.VulnerabilityCount != 1 {
+		t.Errorf("Vulnerabilities = %+v, want one finding", report.Vulnerabilities)
+	}
+	if report.Health == nil || report.Health.LatestVersion != "4.17.21" || report.Health.Version != "4.17.19" {
+		t.Errorf("Health = %+v, want version-scoped metrics with latest 4.17.21", report.Health)
+	}
+	if report.Licenses == nil || report.Licenses.Expression != "MIT" {
+		t.Errorf("Licenses = %+v, want MIT", report.Licenses)
+	}
+	if len(report.Errors) != 0 {
+		t.Errorf("Errors = %v, want none", report.Errors)
+	}
+
+	// deps.dev has no record of the package; the vulnerability section still stands
+	report, err = registry.HandlePackageReport(context.Background(), "npm", "left-pad", "")
+	if err != nil {
+		t.Fatalf("HandlePackageReport(left-pad) error = %v", err)
+	}
+	if report.Health != nil || len(report.Errors) != 1 {
+		t.Errorf("report = %+v, want a health error and no health section", report)
+	}
+
+	if _, err := registry.HandlePackageReport(context.Background(), "npmjs", "lodash", ""); !errors.Is(err, errInvalidInput) {
+		t.Errorf("HandlePackageReport(npmjs) error = %v, want INVALID_INPUT", err)
+	}
+}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/history"
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"go.uber.org/zap"
+)
+
+// ResolveLatestInput defines input for deps.resolve_latest tool
+type ResolveLatestInput struct {
+	Ecosystem string `json:"ecosystem"`
+	Package   string `json:"package"`
+	// IncludePrerelease resolves to the newest prerelease when it is newer than every stable release
+	IncludePrerelease bool `json:"include_prerelease,omitempty"`
+	// IncludeYanked counts releases the registry has yanked or deprecated
+	IncludeYanked bool `json:"include_yanked,omitempty"`
+}
+
+// ResolveLatestOutput names a package's newest releases
+type ResolveLatestOutput struct {
+	Package   string `json:"package"`
+	Ecosystem string `json:"ecosystem"`
+	// Resolved is the latest version under include_prerelease and include_yanked: Latest when
+	// prereleases are included or there is no stable release, LatestStable otherwise
+	Resolved string `json:"resolved"`
+	// LatestStable is the highest version that is not a prerelease, omitted when every
+	// release is one; Latest is the highest version counting prereleases. Neither counts
+	// yanked releases without include_yanked.
+	LatestStable          string     `json:"latest_stable,omitempty"`
+	LatestStablePublished *time.Time `json:"latest_stable_published,omitempty"`
+	Latest                string     `json:"latest"`
+	LatestPublished       *time.Time `json:"latest_published,omitempty"`
+	// DefaultVersion is the release the registry installs by default (npm's "latest" tag),
+	// which can trail LatestStable when a maintainer backports to an older line
+	DefaultVersion string `json:"default_version,omitempty"`
+	VersionCount   int    `json:"version_count"`
+	CacheStatus
+}
+
+// HandleResolveLatest implements deps.resolve_latest: the newest stable and prerelease
+// versions of a package, without computing health metrics. Prereleases and yanked releases
+// are left out of the resolved version unless included.
+// Example: {"ecosystem": "npm", "package": "lodash", "include_prerelease": true}
+func (tr *ToolRegistry) HandleResolveLatest(ctx context.Context, input ResolveLatestInput) (*ResolveLatestOutput, error) {
+	if input.Package == "" {
+		return nil, fmt.Errorf("%w: package is required", errInvalidInput)
+	}
+	ecosystem, err := validateEcosystem(input.Ecosystem)
+	if err != nil {
+		return nil, err
+	}
+	ecosystem, name := tr.normalizePackage(ctx, ecosystem, input.Package)
+
+	cacheKey := cacheKey("latest", ecosystem, name, strconv.FormatBool(input.IncludePrerelease), strconv.FormatBool(input.IncludeYanked))
+	if tr.cache != nil && !cacheRefreshing(ctx) {
+		if cached, status, found := tr.getCachedResponse(cacheKey); found {
+			tr.log(ctx).Debug("cache hit", zap.String("key", cacheKey))
+			if output, ok := cached.(*ResolveLatestOutput); ok {
+				history.NoteCache(ctx, true)
+				hit := *output
+				hit.CacheStatus = status
+				return &hit, nil
+			}
+		}
+		history.NoteCache(ctx, false)
+	}
+
+	pkgInfo, err := tr.getPackageInfo(ctx, ecosystem, name)
+	if err != nil {
+		return nil, fmt.Errorf("query package versions: %w", tr.explainNotFound(ctx, ecosystem, name, err))
+	}
+	if len(pkgInfo.Versions) == 0 {
+		return nil, fmt.Errorf("no versions published: %s/%s", ecosystem, name)
+	}
+
+	output := &ResolveLatestOutput{
+		Package:      name,
+		Ecosystem:    ecosystem,
+		LatestStable: depsdev.LatestMatching(pkgInfo, false, input.IncludeYanked),
+		Latest:       depsdev.LatestMatching(pkgInfo, true, input.IncludeYanked),
+		VersionCount: len(pkgInfo.Versions),
+	}
+	output.Resolved = output.LatestStable
+	if input.IncludePrerelease || output.Resolved == "" {
+		output.Resolved = output.Latest
+	}
+	for _, v := range pkgInfo.Versions {
+		if v.IsDefault {
+			output.DefaultVersion = v.VersionKey.Version
+		}
+		if v.PublishedAt.IsZero() {
+			continue
+		}
+		published := v.PublishedAt
+		if v.VersionKey.Version == output.LatestStable {
+			output.LatestStablePublished = &published
+		}
+		if v.VersionKey.Version == output.Latest {
+			output.LatestPublished = &published
+		}
+	}
+
+	tr.setCachedResponse(cacheKey, output, cacheTTL(ctx, healthCacheTTL))
+	return output, nil
+}
@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+)
+
+func TestHandleResolveLatest_MatchesSortedVersions(t *testing.T) {
+	published := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	// Listed out of order, with a 1.x backport tagged as the registry default
+	versions := []string{"1.10.0", "2.0.0", "1.9.3", "2.1.0-rc.1", "2.0.10", "1.10.1", "2.0.9"}
+	info := &depsdev.PackageInfo{PackageKey: depsdev.PackageKey{System: "NPM", Name: "widget"}}
+	for i, version := range versions {
+		info.Versions = append(info.Versions, depsdev.VersionInfo{
+			VersionKey:  depsdev.VersionKey{System: "NPM", Name: "widget", Version: version},
+			PublishedAt: published.AddDate(0, 0, i),
+			IsDefault:   version == "1.10.1",
+		})
+	}
+	mock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{"npm/widget": info})
+	registry := newTestRegistry(t)
+	registry.depsDevClient = mock.client()
+
+	output, err := registry.HandleResolveLatest(context.Background(), ResolveLatestInput{Ecosystem: "npm", Package: "widget"})
+	if err != nil {
+		t.Fatalf("HandleResolveLatest() error = %v", err)
+	}
+
+	sorted := slices.Clone(versions)
+	slices.SortFunc(sorted, depsdev.CompareVersions)
+	if top := sorted[len(sorted)-1]; output.Latest != top {
+		t.Errorf("latest = %s, want %s (top of %v)", output.Latest, top, sorted)
+	}
+	stable := slices.DeleteFunc(sorted, depsdev.IsPrerelease)
+	if top := stable[len(stable)-1]; output.LatestStable != top {
+		t.Errorf("latest_stable = %s, want %s (top of %v)", output.LatestStable, top, stable)
+	}
+	if output.LatestStable != "2.0.10" || output.Latest != "2.1.0-rc.1" || output.DefaultVersion != "1.10.1" {
+		t.Errorf("resolved = %+v, want stable 2.0.10, latest 2.1.0-rc.1, default 1.10.1", output)
+	}
+	if output.LatestStablePublished == nil || !output.LatestStablePublished.Equal(published.AddDate(0, 0, 4)) {
+		t.Errorf("latest_stable_published = %v, want %v", output.LatestStablePublished, published.AddDate(0, 0, 4))
+	}
+	if output.VersionCount != len(versions) {
+		t.Errorf("version_count = %d, want %d", output.VersionCount, len(versions))
+	}
+}
+
+func TestHandleResolveLatest_PrereleaseAndYanked(t *testing.T) {
+	info := &depsdev.PackageInfo{
+		PackageKey: depsdev.PackageKey{System: "NPM", Name: "widget"},
+		Versions: []depsdev.VersionInfo{
+			{VersionKey: depsdev.VersionKey{Version: "1.0.0"}, IsDefault: true},
+			{VersionKey: depsdev.VersionKey{Version: "1.1.0"}, IsDeprecated: true},
+			{VersionKey: depsdev.VersionKey{Version: "2.0.0-beta.1"}},
+		},
+	}
+	registry := newTestRegistry(t)
+	registry.depsDevClient = newMockDepsDev(t, map[string]*depsdev.PackageInfo{"npm/widget": info}).client()
+
+	tests := []struct {
+		name         string
+		input        ResolveLatestInput
+		resolved     string
+		latestStable string
+	}{
+		{"default excludes both", ResolveLatestInput{}, "1.0.0", "1.0.0"},
+		{"include_prerelease", ResolveLatestInput{IncludePrerelease: true}, "2.0.0-beta.1", "1.0.0"},
+		{"include_yanked", ResolveLatestInput{IncludeYanked: true}, "1.1.0", "1.1.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.Ecosystem, tt.input.Package = "npm", "widget"
+			output, err := registry.HandleResolveLatest(context.Background(), tt.input)
+			if err != nil {
+				t.Fatalf("HandleResolveLatest() error = %v", err)
+			}
+			if output.Resolved != tt.resolved || output.LatestStable != tt.latestStable {
+				t.Errorf("resolved = %s, latest_stable = %s; want %s, %s", output.Resolved, output.LatestStable, tt.resolved, tt.latestStable)
+			}
+		})
+	}
+}
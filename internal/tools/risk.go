@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/rayprogramming/PackagePulse/internal/cvss"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+)
+
+// RiskWeights controls how much each signal contributes to a finding's risk score.
+//
+// The score is a weighted average scaled to 0-100:
+//
+//	risk = 100 * (CVSS*base/10 + EPSS*probability + KEV*listed) / (CVSS + EPSS + KEV)
+//
+// where base is the CVSS base score (0-10), probability is the EPSS exploit
+// probability (0-1, 0 when unknown), and listed is 1 for CISA KEV entries.
+type RiskWeights struct {
+	CVSS float64 `json:"cvss"`
+	EPSS float64 `json:"epss"`
+	KEV  float64 `json:"kev"`
+}
+
+// DefaultRiskWeights favors evidence of exploitation so that a KEV-listed
+// medium can outrank an unexploited critical.
+func DefaultRiskWeights() RiskWeights {
+	return RiskWeights{CVSS: 0.4, EPSS: 0.3, KEV: 0.3}
+}
+
+// Validate checks that weights are non-negative and not all zero
+func (w RiskWeights) Validate() error {
+	if w.CVSS < 0 || w.EPSS < 0 || w.KEV < 0 {
+		return fmt.Errorf("risk weights must be non-negative: %+v", w)
+	}
+	if w.CVSS+w.EPSS+w.KEV == 0 {
+		return fmt.Errorf("at least one risk weight must be positive")
+	}
+	return nil
+}
+
+// qualitativeBaseScores approximates a base score when only a rating is available
+var qualitativeBaseScores = map[string]float64{
+	"critical": 9.5,
+	"high":     8.0,
+	"medium":   5.5,
+	"low":      2.0,
+}
+
+// cvssVersionRank orders CVSS versions by preference: v3.1, then v3.0, then v2. Vectors of
+// other versions cannot be scored and rank 0.
+func cvssVersionRank(sev osv.Severity) int {
+	switch {
+	case strings.HasPrefix(sev.Score, "CVSS:3.1/"):
+		return 3
+	case strings.HasPrefix(sev.Score, "CVSS:3.0/"):
+		return 2
+	case sev.Type == "CVSS_V2" || strings.Contains(sev.Score, "Au:"):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// selectSeverity picks the severity entry a vulnerability is scored by: among the parseable
+// CVSS vectors, the newest CVSS version wins and, within a version, the highest base score
+func selectSeverity(vuln osv.Vulnerability) (osv.Severity, float64, bool) {
+	var (
+		chosen   osv.Severity
+		best     float64
+		bestRank int
+		found    bool
+	)
+	for _, sev := range vuln.Severity {
+		rank := cvssVersionRank(sev)
+		if rank == 0 || rank < bestRank {
+			continue
+		}
+		var (
+			score float64
+			err   error
+		)
+		if rank == 1 {
+			score, err = cvss.BaseScoreV2(sev.Score)
+		} else {
+			score, err = cvss.BaseScoreV3(sev.Score)
+		}
+		if err != nil || (rank == bestRank && score <= best) {
+			continue
+		}
+		chosen, best, bestRank, found = sev, score, rank, true
+	}
+	return chosen, best, found
+}
+
+// cvssScore returns the base score of the vulnerability's preferred CVSS vector
+func cvssScore(vuln osv.Vulnerability) (float64, bool) {
+	_, score, ok := selectSeverity(vuln)
+	return score, ok
+}
+
+// baseScore returns the numeric CVSS base score for a vulnerability, if one can be determined.
+// Vectors are scored exactly; a bare qualitative rating is approximated.
+func baseScore(vuln osv.Vulnerability) (float64, bool) {
+	if score, ok := cvssScore(vuln); ok {
+		return score, true
+	}
+	if score, ok := qualitativeBaseScores[classifySeverity(vuln)]; ok {
+		return score, true
+	}
+	return 0, false
+}
+
+// severityRating returns the qualitative rating of a vulnerability's base score, or "unknown"
+func severityRating(vuln osv.Vulnerability) string {
+	if score, ok := baseScore(vuln); ok {
+		return cvss.Rating(score)
+	}
+	return "unknown"
+}
+
+// riskScore blends CVSS, EPSS, and KEV membership into a 0-100 prioritization number
+func riskScore(base, epssProbability float64, knownExploited bool, w RiskWeights) float64 {
+	total := w.CVSS + w.EPSS + w.KEV
+	if total <= 0 {
+		return 0
+	}
+
+	kev := 0.0
+	if knownExploited {
+		kev = 1
+	}
+	score := 100 * (w.CVSS*base/10 + w.EPSS*epssProbability + w.KEV*kev) / total
+	return math.Round(score*10) / 10
+}
+
+// scoreFindings sets RiskScore on each finding from its enrichment data
+func scoreFindings(findings []Finding, w RiskWeights) {
+	for i := range findings {
+		f := &findings[i]
+		base, _ := baseScore(f.Vulnerability)
+		probability := 0.0
+		if f.EPSSProbability != nil {
+			probability = *f.EPSSProbability
+		}
+		f.RiskScore = riskScore(base, probability, f.KnownExploited, w)
+		if sev, score, ok := selectSeverity(f.Vulnerability); ok {
+			f.SeverityVector = sev.Score
+			f.SeverityScore = &score
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+)
+
+func TestRiskScore(t *testing.T) {
+	w := DefaultRiskWeights()
+
+	tests := []struct {
+		name           string
+		base           float64
+		epss           float64
+		knownExploited bool
+		want           float64
+	}{
+		{name: "high CVSS, low EPSS", base: 9.8, epss: 0.01, want: 39.5},
+		{name: "low CVSS, KEV listed", base: 3.1, epss: 0.2, knownExploited: true, want: 48.4},
+		{name: "everything maxed", base: 10, epss: 1, knownExploited: true, want: 100},
+		{name: "no signal", base: 0, epss: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := riskScore(tt.base, tt.epss, tt.knownExploited, w); got != tt.want {
+				t.Errorf("riskScore() = %.1f, want %.1f", got, tt.want)
+			}
+		})
+	}
+
+	// With default weights, active exploitation outranks raw severity
+	if riskScore(3.1, 0.2, true, w) <= riskScore(9.8, 0.01, false, w) {
+		t.Error("KEV-listed low-CVSS finding should outrank unexploited high-CVSS finding")
+	}
+
+	// Severity-only weighting reverses that ordering
+	severityOnly := RiskWeights{CVSS: 1}
+	if riskScore(3.1, 0.2, true, severityOnly) >= riskScore(9.8, 0.01, false, severityOnly) {
+		t.Error("with CVSS-only weights the high-CVSS finding should rank first")
+	}
+}
+
+func TestScoreAndSortFindings(t *testing.T) {
+	highEPSS := 0.01
+	kevEPSS := 0.2
+	findings := []Finding{
+		{Vulnerability: osv.Vulnerability{ID: "A-unscored"}},
+		{
+			Vulnerability:   osv.Vulnerability{ID: "B-critical", Severity: []osv.Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}},
+			EPSSProbability: &highEPSS,
+		},
+		{
+			Vulnerability:   osv.Vulnerability{ID: "C-kev", Severity: []osv.Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:L/AC:H/PR:L/UI:N/S:U/C:L/I:N/A:N"}}},
+			EPSSProbability: &kevEPSS,
+			KnownExploited:  true,
+		},
+	}
+
+	scoreFindings(findings, DefaultRiskWeights())
+	sortFindings(findings, SortByRisk)
+
+	wantOrder := []string{"C-kev", "B-critical", "A-unscored"}
+	for i, id := range wantOrder {
+		if findings[i].ID != id {
+			t.Errorf("finding[%d] = %s (risk %.1f), want %s", i, findings[i].ID, findings[i].RiskScore, id)
+		}
+	}
+	if findings[2].RiskScore != 0 {
+		t.Errorf("unscored finding risk = %.1f, want 0", findings[2].RiskScore)
+	}
+}
+
+func TestSelectSeverity(t *testing.T) {
+	v2 := osv.Severity{Type: "CVSS_V2", Score: "AV:N/AC:L/Au:N/C:C/I:C/A:C"}
+	v30 := osv.Severity{Type: "CVSS_V3", Score: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}
+	v31Low := osv.Severity{Type: "CVSS_V3", Score: "CVSS:3.1/AV:L/AC:H/PR:L/UI:N/S:U/C:L/I:N/A:N"}
+	v31High := osv.Severity{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H"}
+	v4 := osv.Severity{Type: "CVSS_V4", Score: "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"}
+
+	tests := []struct {
+		name      string
+		severity  []osv.Severity
+		wantScore float64
+		wantOK    bool
+		want      osv.Severity
+	}{
+		// The v2 entry scores 10.0 and is listed first, but the v3 one is preferred
+		{"v3 over a higher v2", []osv.Severity{v2, v31Low}, 2.5, true, v31Low},
+		{"v3.1 over v3.0", []osv.Severity{v30, v31Low}, 2.5, true, v31Low},
+		{"highest score within a version", []osv.Severity{v31Low, v31High}, 7.2, true, v31High},
+		{"v2 alone", []osv.Severity{v2}, 10.0, true, v2},
+		{"unscorable v4 skipped", []osv.Severity{v4, v30}, 9.8, true, v30},
+		{"no vectors", []osv.Severity{{Type: "ECOSYSTEM", Score: "HIGH"}}, 0, false, osv.Severity{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, score, ok := selectSeverity(osv.Vulnerability{Severity: tt.severity})
+			if got != tt.want || score != tt.wantScore || ok != tt.wantOK {
+				t.Errorf("selectSeverity() = %+v, %.1f, %v; want %+v, %.1f, %v", got, score, ok, tt.want, tt.wantScore, tt.wantOK)
+			}
+		})
+	}
+
+	// The chosen vector and score are exposed on the finding
+	findings := []Finding{{Vulnerability: osv.Vulnerability{ID: "GHSA-mixed", Severity: []osv.Severity{v2, v31Low}}}}
+	scoreFindings(findings, DefaultRiskWeights())
+	if f := findings[0]; f.SeverityVector != v31Low.Score || f.SeverityScore == nil || *f.SeverityScore != 2.5 {
+		t.Errorf("finding severity = %q/%v, want the v3.1 vector scored 2.5", f.SeverityVector, f.SeverityScore)
+	}
+	if got := severityRating(findings[0].Vulnerability); got != "low" {
+		t.Errorf("severityRating() = %q, want low from the v3.1 vector", got)
+	}
+}
+
+func TestRiskWeightsValidate(t *testing.T) {
+	if err := DefaultRiskWeights().Validate(); err != nil {
+		t.Errorf("default weights invalid: %v", err)
+	}
+	if err := (RiskWeights{CVSS: -1, EPSS: 1}).Validate(); err == nil {
+		t.Error("expected error for negative weight")
+	}
+	if err := (RiskWeights{}).Validate(); err == nil {
+		t.Error("expected error for all-zero weights")
+	}
+}
+
+// TestSeverity_DatabaseSpecific covers GitHub-sourced entries that carry no severity array,
+// only a database_specific rating
+func TestSeverity_DatabaseSpecific(t *testing.T) {
+	data, err := os.ReadFile("testdata/severity/GHSA-4w2v-q235-vp99.json")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	var vuln osv.Vulnerability
+	if err := json.Unmarshal(data, &vuln); err != nil {
+		t.Fatalf("parse fixture: %v", err)
+	}
+	if got := severityRating(vuln); got != "high" {
+		t.Errorf("severityRating() = %s, want high", got)
+	}
+
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{"npm/follow-redirects": {vuln}})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+	out, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "npm", Package: "follow-redirects", Version: "1.15.5"})
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+	if out.Summary.High != 1 || out.Summary.Unknown != 0 {
+		t.Errorf("summary = %+v, want the finding counted as high", out.Summary)
+	}
+	if f := out.Vulnerabilities[0]; f.RiskScore == 0 {
+		t.Errorf("risk_score = 0, want the rating to feed the risk score")
+	}
+
+	// GitHub's "moderate" is a medium rating
+	vuln.DatabaseSpecific["severity"] = "MODERATE"
+	if got := severityRating(vuln); got != "medium" {
+		t.Errorf("severityRating(MODERATE) = %s, want medium", got)
+	}
+}
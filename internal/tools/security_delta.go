@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rayprogramming/PackagePulse/internal/cvss"
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"go.uber.org/zap"
+)
+
+// SecurityDeltaInput defines input for deps.security_delta tool
+type SecurityDeltaInput struct {
+	Ecosystem   string `json:"ecosystem"`
+	Package     string `json:"package"`
+	FromVersion string `json:"from_version"`
+	ToVersion   string `json:"to_version"`
+}
+
+// FixedVulnerability is a vulnerability that affects the version in use but not the upgrade target
+type FixedVulnerability struct {
+	ID             string   `json:"id"`
+	Aliases        []string `json:"aliases,omitempty"`
+	Summary        string   `json:"summary,omitempty"`
+	Severity       string   `json:"severity"`
+	SeverityScore  *float64 `json:"severity_score,omitempty"`
+	KnownExploited bool     `json:"known_exploited,omitempty"`
+	AdvisoryURL    string   `json:"advisory_url,omitempty"`
+}
+
+// SecurityDeltaOutput lists the vulnerabilities an upgrade resolves
+type SecurityDeltaOutput struct {
+	Package     string `json:"package"`
+	Ecosystem   string `json:"ecosystem"`
+	FromVersion string `json:"from_version"`
+	ToVersion   string `json:"to_version"`
+	FixedCount  int    `json:"fixed_count"`
+	// Fixed is ordered most severe first
+	Fixed   []FixedVulnerability `json:"fixed"`
+	Summary VulnSummary          `json:"summary"`
+	// RemainingCount counts the findings that still affect, or may affect, to_version
+	RemainingCount int `json:"remaining_count"`
+	// Justification is a sentence suitable for an upgrade pull request's description
+	Justification string `json:"justification"`
+}
+
+// HandleSecurityDelta implements deps.security_delta: the vulnerabilities that affect one
+// version of a package and not another, i.e. what an upgrade fixes. A finding counts as fixed
+// only when from_version is known to be affected and to_version known not to be.
+// Example: {"ecosystem": "npm", "package": "lodash", "from_version": "4.17.15", "to_version": "4.17.21"}
+func (tr *ToolRegistry) HandleSecurityDelta(ctx context.Context, input SecurityDeltaInput) (*SecurityDeltaOutput, error) {
+	input.FromVersion = strings.TrimSpace(input.FromVersion)
+	input.ToVersion = strings.TrimSpace(input.ToVersion)
+	if input.Package == "" || input.FromVersion == "" || input.ToVersion == "" {
+		return nil, fmt.Errorf("%w: package, from_version, and to_version are required", errInvalidInput)
+	}
+	ecosystem, err := validateScanEcosystem(input.Ecosystem)
+	if err != nil {
+		return nil, err
+	}
+	ecosystem, name := tr.normalizePackage(ctx, ecosystem, input.Package)
+
+	tr.log(ctx).Info("Handling security delta request",
+		zap.String("ecosystem", ecosystem),
+		zap.String("package", name),
+		zap.String("from_version", input.FromVersion),
+		zap.String("to_version", input.ToVersion))
+
+	findings, err := tr.allFindings(ctx, VulnsInput{
+		Ecosystem: ecosystem,
+		Package:   name,
+		Versions:  []string{input.FromVersion, input.ToVersion},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := depsdev.SchemeFor(ecosystem)
+	output := &SecurityDeltaOutput{
+		Package:     name,
+		Ecosystem:   ecosystem,
+		FromVersion: input.FromVersion,
+		ToVersion:   input.ToVersion,
+		Fixed:       []FixedVulnerability{},
+	}
+	var fixed []Finding
+	for _, f := range findings {
+		if osv.IsVersionAffected(f.Vulnerability, name, input.ToVersion, scheme.Compare) != osv.StatusNotAffected {
+			output.RemainingCount++
+			continue
+		}
+		if osv.IsVersionAffected(f.Vulnerability, name, input.FromVersion, scheme.Compare) == osv.StatusAffected {
+			fixed = append(fixed, f)
+		}
+	}
+	sortFindings(fixed, SortBySeverity)
+	for _, f := range fixed {
+		output.Fixed = append(output.Fixed, FixedVulnerability{
+			ID:             f.ID,
+			Aliases:        f.Aliases,
+			Summary:        f.Summary,
+			Severity:       severityRating(f.Vulnerability),
+			SeverityScore:  f.SeverityScore,
+			KnownExploited: f.KnownExploited,
+			AdvisoryURL:    f.AdvisoryURL,
+		})
+	}
+	output.FixedCount = len(output.Fixed)
+	output.Summary = computeVulnSummary(findingVulns(fixed))
+	output.Justification = securityDeltaJustification(output)
+	return output, nil
+}
+
+// securityDeltaJustification phrases a delta for an upgrade pull request, e.g. "Upgrading
+// lodash from 4.17.15 to 4.17.21 fixes 2 vulnerabilities (1 critical, 1 high): CVE-2021-23337,
+// CVE-2020-8203."
+func securityDeltaJustification(output *SecurityDeltaOutput) string {
+	justification := fmt.Sprintf("Upgrading %s from %s to %s fixes no known vulnerabilities.", output.Package, output.FromVersion, output.ToVersion)
+	if output.FixedCount > 0 {
+		var counts []string
+		for _, c := range []struct {
+			rating string
+			n      int
+		}{
+			{cvss.RatingCritical, output.Summary.Critical},
+			{cvss.RatingHigh, output.Summary.High},
+			{cvss.RatingMedium, output.Summary.Medium},
+			{cvss.RatingLow, output.Summary.Low},
+			{"unknown", output.Summary.Unknown},
+		} {
+			if c.n > 0 {
+				counts = append(counts, fmt.Sprintf("%d %s", c.n, c.rating))
+			}
+		}
+		// CVE IDs are the ones reviewers recognize
+		ids := make([]string, len(output.Fixed))
+		for i, f := range output.Fixed {
+			ids[i] = f.ID
+			if cve := cveID(osv.Vulnerability{ID: f.ID, Aliases: f.Aliases}); cve != "" {
+				ids[i] = cve
+			}
+		}
+		noun := "vulnerabilities"
+		if output.FixedCount == 1 {
+			noun = "vulnerability"
+		}
+		justification = fmt.Sprintf("Upgrading %s from %s to %s fixes %d %s (%s): %s.",
+			output.Package, output.FromVersion, output.ToVersion, output.FixedCount, noun, strings.Join(counts, ", "), strings.Join(ids, ", "))
+	}
+	if output.RemainingCount > 0 {
+		justification += fmt.Sprintf(" Known vulnerabilities still affecting %s: %d.", output.ToVersion, output.RemainingCount)
+	}
+	return justification
+}
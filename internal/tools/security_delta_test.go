@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+)
+
+func TestHandleSecurityDelta(t *testing.T) {
+	critical := rangedVuln("GHSA-crit", "widget", osv.Event{Introduced: "0"}, osv.Event{Fixed: "1.2.0"})
+	critical.Aliases = []string{"CVE-2024-0001"}
+	critical.Severity = []osv.Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}
+	high := rangedVuln("GHSA-high", "widget", osv.Event{Introduced: "1.0.0"}, osv.Event{Fixed: "1.5.0"})
+	high.Severity = []osv.Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H"}}
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/widget": {
+			high,
+			critical,
+			// Still present in the upgrade target
+			rangedVuln("GHSA-open", "widget", osv.Event{Introduced: "0.5.0"}, osv.Event{Fixed: "3.0.0"}),
+			// Introduced by the upgrade, not fixed by it
+			rangedVuln("GHSA-new", "widget", osv.Event{Introduced: "2.0.0"}),
+		},
+	})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	out, err := registry.HandleSecurityDelta(context.Background(), SecurityDeltaInput{
+		Ecosystem: "npm", Package: "widget", FromVersion: "1.0.0", ToVersion: "2.0.0",
+	})
+	if err != nil {
+		t.Fatalf("HandleSecurityDelta() error = %v", err)
+	}
+
+	if out.FixedCount != 2 || len(out.Fixed) != 2 || out.Fixed[0].ID != "GHSA-crit" || out.Fixed[1].ID != "GHSA-high" {
+		t.Fatalf("fixed = %+v, want GHSA-crit then GHSA-high", out.Fixed)
+	}
+	if out.Fixed[0].Severity != "critical" || out.Fixed[1].Severity != "high" {
+		t.Errorf("severities = %s, %s; want critical, high", out.Fixed[0].Severity, out.Fixed[1].Severity)
+	}
+	if out.Summary.Critical != 1 || out.Summary.High != 1 || out.RemainingCount != 2 {
+		t.Errorf("summary = %+v, remaining = %d; want 1 critical, 1 high, 2 remaining", out.Summary, out.RemainingCount)
+	}
+	want := "Upgrading widget from 1.0.0 to 2.0.0 fixes 2 vulnerabilities (1 critical, 1 high): CVE-2024-0001, GHSA-high. Known vulnerabilities still affecting 2.0.0: 2."
+	if out.Justification != want {
+		t.Errorf("justification = %q, want %q", out.Justification, want)
+	}
+
+	if _, err := registry.HandleSecurityDelta(context.Background(), SecurityDeltaInput{Ecosystem: "npm", Package: "widget", FromVersion: "1.0.0"}); !errors.Is(err, errInvalidInput) {
+		t.Errorf("missing to_version error = %v, want invalid input", err)
+	}
+}
@@ -0,0 +1,27 @@
+package tools
+
+// Upstream data sources reported in sources_queried and sources_failed
+const (
+	SourceOSV     = "osv"
+	SourceEPSS    = "epss"
+	SourceKEV     = "kev"
+	SourceGHSA    = "ghsa"
+	SourceDepsDev = "deps.dev"
+)
+
+// DataSources records which upstream sources a result was built from. DataComplete is
+// false when any of them failed, so an incomplete scan is never mistaken for a clean one.
+type DataSources struct {
+	DataComplete   bool     `json:"data_complete"`
+	SourcesQueried []string `json:"sources_queried"`
+	SourcesFailed  []string `json:"sources_failed,omitempty"`
+}
+
+// record notes that source was queried and whether it failed
+func (d *DataSources) record(source string, err error) {
+	d.SourcesQueried = append(d.SourcesQueried, source)
+	if err != nil {
+		d.SourcesFailed = append(d.SourcesFailed, source)
+	}
+	d.DataComplete = len(d.SourcesFailed) == 0
+}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/epss"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"go.uber.org/zap"
+)
+
+// newFailingServer returns a test server that answers every request with a 503
+func newFailingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHandleVulns_DataComplete(t *testing.T) {
+	osvMock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/lodash@4.17.19": {{ID: "GHSA-35jh-r3h4-6jhm", Aliases: []string{"CVE-2021-23337"}}},
+	})
+
+	t.Run("all sources answered", func(t *testing.T) {
+		registry := newTestRegistry(t)
+		registry.osvClient = osvMock.client()
+
+		result, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "npm", Package: "lodash", Version: "4.17.19"})
+		if err != nil {
+			t.Fatalf("HandleVulns() error = %v", err)
+		}
+		if !result.DataComplete || len(result.SourcesFailed) != 0 {
+			t.Errorf("DataSources = %+v, want complete", result.DataSources)
+		}
+		if len(result.SourcesQueried) != 4 {
+			t.Errorf("SourcesQueried = %v, want osv, deps.dev, epss, and kev", result.SourcesQueried)
+		}
+	})
+
+	t.Run("EPSS unavailable", func(t *testing.T) {
+		registry := newTestRegistry(t)
+		registry.osvClient = osvMock.client()
+		registry.epssClient = epss.NewClient(zap.NewNop(), epss.WithBaseURL(newFailingServer(t).URL))
+
+		result, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "npm", Package: "lodash", Version: "4.17.19"})
+		if err != nil {
+			t.Fatalf("HandleVulns() error = %v", err)
+		}
+		if result.DataComplete {
+			t.Error("DataComplete = true, want false when EPSS failed")
+		}
+		if len(result.SourcesFailed) != 1 || result.SourcesFailed[0] != SourceEPSS {
+			t.Errorf("SourcesFailed = %v, want [epss]", result.SourcesFailed)
+		}
+		if result.VulnerabilityCount != 1 {
+			t.Errorf("VulnerabilityCount = %d, want OSV findings to survive", result.VulnerabilityCount)
+		}
+	})
+}
+
+func TestUpgradePlan_OSVUnavailable(t *testing.T) {
+	depsMock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{"npm/express": expressPackage()})
+
+	registry := newTestRegistry(t)
+	registry.osvClient = osv.NewClient(zap.NewNop(), osv.WithBaseURL(newFailingServer(t).URL))
+	registry.depsDevClient = depsMock.client()
+
+	input := UpgradePlanInput{Ecosystem: "npm", Package: "express", CurrentVersion: "4.59.0"}
+	plan := runUpgradePlan(t, registry, input)
+
+	// Up to date with no findings would otherwise read as "OK"
+	if plan.Priority != "UNKNOWN" {
+		t.Errorf("Priority = %s, want UNKNOWN when OSV failed", plan.Priority)
+	}
+	if len(plan.Factors) != 1 || plan.Factors[0].Factor != FactorVulnDataUnavailable {
+		t.Errorf("Factors = %+v, want vulnerability_data_unavailable", plan.Factors)
+	}
+	if plan.DataComplete || len(plan.SourcesFailed) != 1 || plan.SourcesFailed[0] != SourceOSV {
+		t.Errorf("DataSources = %+v, want osv failed", plan.DataSources)
+	}
+
+	// Degraded plans are not cached, so a recovered upstream is picked up on retry
+	registry.osvClient = newMockOSV(t, map[string][]osv.Vulnerability{}).client()
+	plan = runUpgradePlan(t, registry, input)
+	if !plan.DataComplete || plan.Priority != "OK" {
+		t.Errorf("retry: DataComplete = %v, Priority = %s, want complete OK plan", plan.DataComplete, plan.Priority)
+	}
+}
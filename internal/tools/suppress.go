@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+)
+
+// suppressionDateLayout is the format of Suppression.Expires
+const suppressionDateLayout = "2006-01-02"
+
+// Suppression marks a vulnerability as an accepted risk so it stops counting as actionable.
+// ID matches a finding's ID or any of its aliases, so a CVE suppresses the GHSA that carries it.
+type Suppression struct {
+	ID string `json:"id"`
+	// Expires is the last day (YYYY-MM-DD, UTC) the suppression applies; omit for no expiry
+	Expires       string `json:"expires,omitempty"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// SuppressedFinding is a finding set aside by a suppression. The package fields are set by
+// deps.gate, which suppresses across a whole manifest.
+type SuppressedFinding struct {
+	ID             string `json:"id"`
+	Ecosystem      string `json:"ecosystem,omitempty"`
+	Package        string `json:"package,omitempty"`
+	Version        string `json:"version,omitempty"`
+	Summary        string `json:"summary,omitempty"`
+	Severity       string `json:"severity"`
+	KnownExploited bool   `json:"known_exploited,omitempty"`
+	Justification  string `json:"justification,omitempty"`
+	Expires        string `json:"expires,omitempty"`
+}
+
+// suppressionSet is a validated suppress list holding the suppressions still in effect
+type suppressionSet struct {
+	active map[string]Suppression
+	// warnings names the suppressions that have expired and no longer apply
+	warnings []string
+}
+
+// newSuppressionSet validates a suppress list, setting aside the entries that expired before now
+func newSuppressionSet(list []Suppression, now time.Time) (*suppressionSet, error) {
+	set := &suppressionSet{active: make(map[string]Suppression, len(list))}
+	today := now.UTC().Format(suppressionDateLayout)
+	for i, s := range list {
+		s.ID = strings.TrimSpace(s.ID)
+		if s.ID == "" {
+			return nil, fmt.Errorf("%w: suppress[%d] needs an id", errInvalidInput, i)
+		}
+		if s.Expires = strings.TrimSpace(s.Expires); s.Expires != "" {
+			if _, err := time.Parse(suppressionDateLayout, s.Expires); err != nil {
+				return nil, fmt.Errorf("%w: suppress[%d] expires %q is not a YYYY-MM-DD date", errInvalidInput, i, s.Expires)
+			}
+			if s.Expires < today {
+				set.warnings = append(set.warnings, fmt.Sprintf("suppression of %s expired on %s; its findings are reported again", s.ID, s.Expires))
+				continue
+			}
+		}
+		set.active[strings.ToUpper(s.ID)] = s
+	}
+	return set, nil
+}
+
+// match returns the suppression in effect for a finding, by its ID or one of its aliases
+func (s *suppressionSet) match(vuln osv.Vulnerability) (Suppression, bool) {
+	for _, id := range append([]string{vuln.ID}, vuln.Aliases...) {
+		if suppression, ok := s.active[strings.ToUpper(id)]; ok {
+			return suppression, true
+		}
+	}
+	return Suppression{}, false
+}
+
+// apply splits findings into those still actionable and those suppressed. findings is not modified.
+func (s *suppressionSet) apply(findings []Finding) ([]Finding, []SuppressedFinding) {
+	kept := make([]Finding, 0, len(findings))
+	var suppressed []SuppressedFinding
+	for _, f := range findings {
+		suppression, ok := s.match(f.Vulnerability)
+		if !ok {
+			kept = append(kept, f)
+			continue
+		}
+		suppressed = append(suppressed, SuppressedFinding{
+			ID:             f.ID,
+			Summary:        f.Summary,
+			Severity:       severityRating(f.Vulnerability),
+			KnownExploited: f.KnownExploited,
+			Justification:  suppression.Justification,
+			Expires:        suppression.Expires,
+		})
+	}
+	return kept, suppressed
+}
+
+// suppressVulns returns a copy of a full deps.vulns result without its suppressed findings,
+// recounting the summary and version matrix over the findings that remain. The range analysis
+// is left as scanned, since suppressing a finding does not make a version any less affected.
+func (s *suppressionSet) suppressVulns(full *VulnsOutput, input VulnsInput) *VulnsOutput {
+	out := *full
+	out.Warnings = s.warnings
+	if len(s.active) == 0 {
+		return &out
+	}
+	out.Vulnerabilities, out.Suppressed = s.apply(full.Vulnerabilities)
+	out.VulnerabilityCount = len(out.Vulnerabilities)
+	out.Summary = computeVulnSummary(findingVulns(out.Vulnerabilities))
+	if len(input.Versions) > 0 {
+		out.Versions = versionMatrix(out.Vulnerabilities, input.Package, input.Versions, depsdev.SchemeFor(input.Ecosystem))
+	}
+	return &out
+}
+
+// findingVulns returns the advisories behind findings
+func findingVulns(findings []Finding) []osv.Vulnerability {
+	vulns := make([]osv.Vulnerability, len(findings))
+	for i, f := range findings {
+		vulns[i] = f.Vulnerability
+	}
+	return vulns
+}
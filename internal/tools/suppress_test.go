@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+)
+
+func TestHandleVulns_Suppress(t *testing.T) {
+	critical := []osv.Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/lodash@4.17.19": {
+			{ID: "GHSA-35jh-r3h4-6jhm", Aliases: []string{"CVE-2021-23337"}, Severity: critical},
+			{ID: "GHSA-p6mc-m468-83gw"},
+		},
+	})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	result, err := registry.HandleVulns(context.Background(), VulnsInput{
+		Ecosystem: "npm",
+		Package:   "lodash",
+		Version:   "4.17.19",
+		Suppress:  []Suppression{{ID: "cve-2021-23337", Justification: "template() is never called"}},
+	})
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+	if result.VulnerabilityCount != 1 || len(result.Vulnerabilities) != 1 || result.Vulnerabilities[0].ID != "GHSA-p6mc-m468-83gw" {
+		t.Errorf("vulnerabilities = %+v, want only the unsuppressed finding", result.Vulnerabilities)
+	}
+	if result.Summary.Critical != 0 || result.Pagination.TotalCount != 1 {
+		t.Errorf("summary = %+v, pagination = %+v; want the suppressed critical left out", result.Summary, result.Pagination)
+	}
+	if len(result.Suppressed) != 1 || result.Suppressed[0].ID != "GHSA-35jh-r3h4-6jhm" || result.Suppressed[0].Severity != "critical" {
+		t.Errorf("suppressed = %+v, want the critical finding", result.Suppressed)
+	}
+}
@@ -3,53 +3,440 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/PackagePulse/internal/breaker"
+	"github.com/rayprogramming/PackagePulse/internal/cassette"
+	"github.com/rayprogramming/PackagePulse/internal/cvss"
+	"github.com/rayprogramming/PackagePulse/internal/decode"
+	"github.com/rayprogramming/PackagePulse/internal/drain"
+	"github.com/rayprogramming/PackagePulse/internal/history"
+	"github.com/rayprogramming/PackagePulse/internal/manifest"
 	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/epss"
+	"github.com/rayprogramming/PackagePulse/internal/providers/ghsa"
+	"github.com/rayprogramming/PackagePulse/internal/providers/kev"
 	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"github.com/rayprogramming/PackagePulse/internal/providers/packagist"
 	"github.com/rayprogramming/PackagePulse/internal/providers/spdx"
+	"github.com/rayprogramming/PackagePulse/internal/reqlog"
+	"github.com/rayprogramming/PackagePulse/internal/retrybudget"
+	"github.com/rayprogramming/PackagePulse/internal/stats"
+	"github.com/rayprogramming/PackagePulse/internal/tracing"
+	"github.com/rayprogramming/PackagePulse/internal/useragent"
 	"github.com/rayprogramming/hypermcp"
 	"github.com/rayprogramming/hypermcp/cache"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
-// ToolRegistry manages all MCP tools
+// ToolRegistry manages all MCP tools. One registry serves every client session, so its methods
+// are safe for concurrent use: its fields are set before Register and only read afterwards, the
+// provider clients and recorders guard their own state, and values shared through the cache or
+// in-flight lookups are never modified, only copied.
 type ToolRegistry struct {
-	osvClient     *osv.Client
-	depsDevClient *depsdev.Client
-	spdxClient    *spdx.Client
-	logger        *zap.Logger
-	cache         *cache.Cache
+	osvClient       *osv.Client
+	depsDevClient   *depsdev.Client
+	packagistClient *packagist.Client
+	spdxClient      *spdx.Client
+	epssClient      *epss.Client
+	kevClient       *kev.Client
+	ghsaClient      *ghsa.Client
+	breakers        map[string]*breaker.Breaker
+	history         *history.Recorder
+	// stats counts tool-call outcomes per ecosystem and request outcomes per upstream
+	stats *stats.Recorder
+	// drain tracks in-flight tool calls for graceful shutdown
+	drain *drain.Tracker
+	// alternatives maps "ecosystem/name" to curated replacements for poorly maintained packages
+	alternatives map[string][]string
+	// popularPackages lists, per OSV ecosystem, the package names deps.name_check guards
+	popularPackages map[string][]string
+	// watchlist records the background refresh of Config.Watchlist
+	watchlist *watchlist
+	// flights collapses concurrent identical upstream lookups, keyed by their cache key
+	flights singleflight.Group
+	logger  *zap.Logger
+	cache   *cache.Cache
+	config  Config
 }
 
-// NewToolRegistry creates a new tool registry
+// Upstreams guarded by a circuit breaker
+const (
+	UpstreamOSV     = "osv"
+	UpstreamDepsDev = "deps.dev"
+)
+
+// Other upstreams whose requests meta.stats counts
+const (
+	UpstreamPackagist = "packagist"
+	UpstreamSPDX      = "spdx"
+	UpstreamEPSS      = "epss"
+	UpstreamKEV       = "kev"
+	UpstreamGHSA      = "ghsa"
+)
+
+// Default per-call deadlines applied to tool handlers
+const (
+	DefaultTimeout      = 30 * time.Second
+	DefaultBatchTimeout = 2 * time.Minute
+)
+
+// Config holds tunable tool behavior
+type Config struct {
+	RiskWeights RiskWeights `json:"risk_weights"`
+	// HealthScoring weights the signals behind deps.health's maintenance score
+	HealthScoring depsdev.HealthScoringConfig `json:"health_scoring"`
+	// Timeout bounds a single-package tool call, including every upstream request it makes
+	Timeout time.Duration `json:"timeout"`
+	// BatchTimeout bounds tools that fan out across many packages
+	BatchTimeout time.Duration `json:"batch_timeout"`
+	// EnableLicenseReload registers the license.reload admin tool
+	EnableLicenseReload bool `json:"enable_license_reload"`
+	// BreakerThreshold is how many consecutive OSV or deps.dev failures open that upstream's
+	// circuit breaker, and BreakerCooldown how long the open circuit fails fast
+	BreakerThreshold int           `json:"breaker_threshold"`
+	BreakerCooldown  time.Duration `json:"breaker_cooldown"`
+	// RetryBudget is how many upstream retries may be spent in a burst across all calls, and
+	// RetryBudgetRefill how long the budget takes to earn one back
+	RetryBudget       int           `json:"retry_budget"`
+	RetryBudgetRefill time.Duration `json:"retry_budget_refill"`
+	// HistoryCapacity is how many recent tool calls the packagepulse://history resource keeps
+	HistoryCapacity int `json:"history_capacity"`
+	// Alternatives adds curated replacement packages, keyed by "ecosystem/name", suggested when a
+	// package's maintenance is poor; an entry replaces the built-in list for that package
+	Alternatives map[string][]string `json:"alternatives,omitempty"`
+	// PopularPackages adds names, keyed by ecosystem, that deps.name_check treats as popular
+	// typosquatting targets, on top of the built-in list
+	PopularPackages map[string][]string `json:"popular_packages,omitempty"`
+	// Watchlist names packages RunWatchlist re-scans every WatchlistInterval, so calls for them
+	// are served from the cache
+	Watchlist         []WatchlistEntry `json:"watchlist,omitempty"`
+	WatchlistInterval time.Duration    `json:"watchlist_interval"`
+	// EnabledTools, when set, limits registration to the named tools; DisabledTools are never
+	// registered. license.reload additionally requires EnableLicenseReload.
+	EnabledTools  []string `json:"enabled_tools,omitempty"`
+	DisabledTools []string `json:"disabled_tools,omitempty"`
+	// GitHubToken enables GitHub Security Advisories as a deps.vulns source; it is never serialized
+	GitHubToken string `json:"-"`
+	// MaxResponseBytes caps the size of a tool result; larger results have their detail fields
+	// truncated, keeping summaries and counts. 0 disables the cap.
+	MaxResponseBytes int `json:"max_response_bytes"`
+	// UserAgent is sent with every upstream request; see useragent.String
+	UserAgent string `json:"user_agent"`
+	// CassetteMode, cassette.ModeRecord or cassette.ModeReplay, saves every upstream response
+	// to CassetteDir or answers from it without touching the network; empty disables both
+	CassetteMode string `json:"cassette_mode,omitempty"`
+	CassetteDir  string `json:"cassette_dir,omitempty"`
+	// Transport, when set, carries OSV and deps.dev requests in place of http.DefaultTransport,
+	// for example to present a client certificate to a corporate proxy; it is never serialized
+	Transport http.RoundTripper `json:"-"`
+	// TracerProvider, when set, traces every tool call and the OSV and deps.dev requests it
+	// makes; it is never serialized
+	TracerProvider trace.TracerProvider `json:"-"`
+}
+
+// toolNames lists every tool Register can add, in registration order
+var toolNames = []string{
+	"deps.vulns",
+	"deps.batch_vulns",
+	"deps.scan_manifest",
+	"deps.gate",
+	"deps.vex",
+	"deps.health",
+	"deps.batch_health",
+	"deps.name_check",
+	"deps.find_package",
+	"license.info",
+	"license.batch_info",
+	"license.validate_expression",
+	"license.audit_manifest",
+	"license.osi_approved",
+	"license.tree_conflicts",
+	"license.version_diff",
+	"deps.security_delta",
+	"deps.upgrade_plan",
+	"deps.freshness",
+	"deps.resolve_latest",
+	"deps.upgrade_all",
+	"license.reload",
+	"meta.tools",
+	"meta.stats",
+}
+
+// ToolNames returns the name of every tool the registry can register
+func ToolNames() []string {
+	return slices.Clone(toolNames)
+}
+
+// DefaultConfig returns the default tool configuration
+func DefaultConfig() Config {
+	return Config{
+		RiskWeights:       DefaultRiskWeights(),
+		HealthScoring:     depsdev.DefaultHealthScoringConfig(),
+		Timeout:           DefaultTimeout,
+		BatchTimeout:      DefaultBatchTimeout,
+		BreakerThreshold:  breaker.DefaultThreshold,
+		BreakerCooldown:   breaker.DefaultCooldown,
+		RetryBudget:       retrybudget.DefaultCapacity,
+		RetryBudgetRefill: retrybudget.DefaultRefill,
+		HistoryCapacity:   history.DefaultCapacity,
+		WatchlistInterval: DefaultWatchlistInterval,
+		MaxResponseBytes:  DefaultMaxResponseBytes,
+		UserAgent:         useragent.Default,
+	}
+}
+
+// Validate checks the tool configuration
+func (c Config) Validate() error {
+	if err := c.RiskWeights.Validate(); err != nil {
+		return fmt.Errorf("risk_weights: %w", err)
+	}
+	if err := c.HealthScoring.Validate(); err != nil {
+		return fmt.Errorf("health_scoring: %w", err)
+	}
+	if c.Timeout <= 0 || c.BatchTimeout <= 0 {
+		return fmt.Errorf("timeout and batch_timeout must be positive")
+	}
+	if c.BreakerThreshold <= 0 || c.BreakerCooldown <= 0 {
+		return fmt.Errorf("breaker_threshold and breaker_cooldown must be positive")
+	}
+	if c.RetryBudget <= 0 || c.RetryBudgetRefill <= 0 {
+		return fmt.Errorf("retry_budget and retry_budget_refill must be positive")
+	}
+	if c.HistoryCapacity <= 0 {
+		return fmt.Errorf("history_capacity must be positive")
+	}
+	if c.MaxResponseBytes < 0 {
+		return fmt.Errorf("max_response_bytes must not be negative")
+	}
+	if c.CassetteMode != "" && c.CassetteMode != cassette.ModeRecord && c.CassetteMode != cassette.ModeReplay {
+		return fmt.Errorf("unsupported cassette_mode %q (valid: %s, %s)", c.CassetteMode, cassette.ModeRecord, cassette.ModeReplay)
+	}
+	if c.CassetteMode != "" && c.CassetteDir == "" {
+		return fmt.Errorf("cassette_dir is required with cassette_mode %s", c.CassetteMode)
+	}
+	if err := validateAlternatives(c.Alternatives); err != nil {
+		return err
+	}
+	if err := validatePopularPackages(c.PopularPackages); err != nil {
+		return err
+	}
+	if c.WatchlistInterval <= 0 {
+		return fmt.Errorf("watchlist_interval must be positive")
+	}
+	if err := validateWatchlist(c.Watchlist); err != nil {
+		return err
+	}
+	for _, list := range []struct {
+		key   string
+		names []string
+	}{
+		{"enabled_tools", c.EnabledTools},
+		{"disabled_tools", c.DisabledTools},
+	} {
+		for _, name := range list.names {
+			if !slices.Contains(toolNames, name) {
+				return fmt.Errorf("%s: unknown tool %q (valid: %s)", list.key, name, strings.Join(toolNames, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// ToolEnabled reports whether the enabled and disabled tool lists permit registering a tool
+func (c Config) ToolEnabled(name string) bool {
+	if len(c.EnabledTools) > 0 && !slices.Contains(c.EnabledTools, name) {
+		return false
+	}
+	return !slices.Contains(c.DisabledTools, name)
+}
+
+// NewToolRegistry creates a new tool registry with the default configuration
 func NewToolRegistry(logger *zap.Logger, c *cache.Cache) (*ToolRegistry, error) {
+	return NewToolRegistryWithConfig(logger, c, DefaultConfig())
+}
+
+// NewToolRegistryWithConfig creates a new tool registry with the given configuration
+func NewToolRegistryWithConfig(logger *zap.Logger, c *cache.Cache, cfg Config) (*ToolRegistry, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	osvBreaker := breaker.New(UpstreamOSV, cfg.BreakerThreshold, cfg.BreakerCooldown)
+	depsDevBreaker := breaker.New(UpstreamDepsDev, cfg.BreakerThreshold, cfg.BreakerCooldown)
+
+	ua := cfg.UserAgent
+	if ua == "" {
+		ua = useragent.Default
+	}
+
+	var tape *cassette.Cassette
+	if cfg.CassetteMode != "" {
+		var err error
+		if tape, err = cassette.New(cfg.CassetteDir, cfg.CassetteMode); err != nil {
+			return nil, err
+		}
+	}
+
+	// Stats wrap the transport first so they time real upstream requests, not fast-fails.
+	// The cassette sits beneath them, in place of the network when replaying, and a configured
+	// Transport beneath that.
+	recorder := stats.New()
+	retries := retrybudget.New(cfg.RetryBudget, cfg.RetryBudgetRefill)
+	recorder.TrackRetryBudget(retries)
+
 	return &ToolRegistry{
-		osvClient:     osv.NewClient(logger),
-		depsDevClient: depsdev.NewClient(logger),
-		spdxClient:    spdx.NewClient(logger),
-		logger:        logger,
-		cache:         c,
+		osvClient:       osv.NewClient(logger, osv.WithTransport(cfg.Transport), osv.WithCassette(tape), osv.WithStats(recorder.Upstream(UpstreamOSV)), osv.WithBreaker(osvBreaker), osv.WithTracerProvider(cfg.TracerProvider), osv.WithUserAgent(ua)),
+		depsDevClient:   depsdev.NewClient(logger, depsdev.WithTransport(cfg.Transport), depsdev.WithCassette(tape), depsdev.WithStats(recorder.Upstream(UpstreamDepsDev)), depsdev.WithBreaker(depsDevBreaker), depsdev.WithTracerProvider(cfg.TracerProvider), depsdev.WithRetryBudget(retries), depsdev.WithUserAgent(ua)),
+		packagistClient: packagist.NewClient(logger, packagist.WithCassette(tape), packagist.WithStats(recorder.Upstream(UpstreamPackagist)), packagist.WithUserAgent(ua)),
+		spdxClient:      spdx.NewClient(logger, spdx.WithCassette(tape), spdx.WithStats(recorder.Upstream(UpstreamSPDX)), spdx.WithUserAgent(ua)),
+		epssClient:      epss.NewClient(logger, epss.WithCassette(tape), epss.WithStats(recorder.Upstream(UpstreamEPSS)), epss.WithUserAgent(ua)),
+		kevClient:       kev.NewClient(logger, kev.WithCassette(tape), kev.WithStats(recorder.Upstream(UpstreamKEV)), kev.WithUserAgent(ua)),
+		ghsaClient:      ghsa.NewClient(logger, ghsa.WithToken(cfg.GitHubToken), ghsa.WithCassette(tape), ghsa.WithStats(recorder.Upstream(UpstreamGHSA)), ghsa.WithUserAgent(ua)),
+		breakers: map[string]*breaker.Breaker{
+			UpstreamOSV:     osvBreaker,
+			UpstreamDepsDev: depsDevBreaker,
+		},
+		history:         history.New(cfg.HistoryCapacity),
+		stats:           recorder,
+		drain:           drain.New(),
+		alternatives:    mergeAlternatives(cfg.Alternatives),
+		popularPackages: mergePopularPackages(cfg.PopularPackages),
+		watchlist:       newWatchlist(cfg.Watchlist),
+		logger:          logger,
+		cache:           c,
+		config:          cfg,
 	}, nil
 }
 
+// Breakers returns the circuit breakers guarding the OSV and deps.dev clients, keyed by upstream
+func (tr *ToolRegistry) Breakers() map[string]*breaker.Breaker {
+	return tr.breakers
+}
+
+// Stats returns the recorder behind meta.stats and the /metrics endpoint
+func (tr *ToolRegistry) Stats() *stats.Recorder {
+	return tr.stats
+}
+
+// Drain rejects new tool calls and waits, until ctx is done, for in-flight ones to finish
+func (tr *ToolRegistry) Drain(ctx context.Context) error {
+	return tr.drain.Drain(ctx)
+}
+
+// History returns the recorder behind the packagepulse://history resource
+func (tr *ToolRegistry) History() *history.Recorder {
+	return tr.history
+}
+
+// log returns the logger scoped to the tool call in ctx, falling back to the registry's logger
+func (tr *ToolRegistry) log(ctx context.Context) *zap.Logger {
+	return reqlog.Logger(ctx, tr.logger)
+}
+
+// errorResult converts a handler error into a tool error. When the failure is an open
+// circuit breaker or a malformed upstream response the result's _meta carries a retryable
+// envelope so agents can back off.
+func errorResult(err error) *mcp.CallToolResult {
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+		IsError: true,
+	}
+	var open *breaker.OpenError
+	var malformed *decode.MalformedError
+	switch {
+	case errors.As(err, &open):
+		result.Meta = mcp.Meta{
+			"retryable":           true,
+			"upstream":            open.Upstream,
+			"retry_after_seconds": int(open.RetryAfter.Round(time.Second).Seconds()),
+		}
+	case errors.As(err, &malformed):
+		result.Meta = mcp.Meta{
+			"retryable": true,
+			"upstream":  malformed.Upstream,
+		}
+	}
+	return result
+}
+
 // VulnsInput defines input for deps.vulns tool
 type VulnsInput struct {
-	Ecosystem string `json:"ecosystem"`
-	Package   string `json:"package"`
-	Version   string `json:"version,omitempty"`
+	Ecosystem    string   `json:"ecosystem"`
+	Package      string   `json:"package"`
+	Version      string   `json:"version,omitempty"`
+	Versions     []string `json:"versions,omitempty"`
+	VersionRange string   `json:"version_range,omitempty"`
+	OutputFormat string   `json:"output_format,omitempty"`
+	SortBy       string   `json:"sort_by,omitempty"`
+	// Limit caps the findings returned (DefaultVulnsLimit when 0); Offset skips that many first
+	Limit  int `json:"limit,omitempty"`
+	Offset int `json:"offset,omitempty"`
+	// PURL names the package, and optionally the version, as a package URL
+	// (pkg:npm/lodash@4.17.19) in place of ecosystem, package, and version
+	PURL string `json:"purl,omitempty"`
+	// ChangedWithinDays keeps only findings published or modified in the last that many days
+	ChangedWithinDays int `json:"changed_within_days,omitempty"`
+	// Suppress moves accepted-risk findings out of Vulnerabilities into Suppressed
+	Suppress []Suppression `json:"suppress,omitempty"`
+	// FollowRelated fetches the upstream and related entries of findings without a usable
+	// severity and inherits theirs
+	FollowRelated bool `json:"follow_related,omitempty"`
+}
+
+// Paging bounds for deps.vulns findings
+const (
+	DefaultVulnsLimit = 50
+	MaxVulnsLimit     = 500
+)
+
+// Pagination describes which page of findings a VulnsOutput holds
+type Pagination struct {
+	Offset        int  `json:"offset"`
+	Limit         int  `json:"limit"`
+	ReturnedCount int  `json:"returned_count"`
+	TotalCount    int  `json:"total_count"`
+	HasMore       bool `json:"has_more"`
 }
 
 // VulnsOutput contains vulnerability results
 type VulnsOutput struct {
-	Package            string              `json:"package"`
-	Ecosystem          string              `json:"ecosystem"`
-	Version            string              `json:"version,omitempty"`
-	VulnerabilityCount int                 `json:"vulnerability_count"`
-	Vulnerabilities    []osv.Vulnerability `json:"vulnerabilities"`
-	Summary            VulnSummary         `json:"summary"`
+	Package            string                    `json:"package"`
+	Ecosystem          string                    `json:"ecosystem"`
+	Version            string                    `json:"version,omitempty"`
+	VulnerabilityCount int                       `json:"vulnerability_count"`
+	Vulnerabilities    []Finding                 `json:"vulnerabilities"`
+	Summary            VulnSummary               `json:"summary"`
+	VersionRange       *RangeAnalysis            `json:"version_range,omitempty"`
+	Versions           map[string]*VersionResult `json:"versions,omitempty"`
+	ResolvedQuery      *ResolvedQuery            `json:"resolved_query,omitempty"`
+	// Pagination is set by deps.vulns; VulnerabilityCount, Summary, and Versions always cover
+	// every finding, not just the returned page
+	Pagination *Pagination `json:"pagination,omitempty"`
+	// Suppressed lists the findings matched by the input's suppress list, which are left out of
+	// Vulnerabilities and every count; Warnings notes suppressions that have expired
+	Suppressed []SuppressedFinding `json:"suppressed,omitempty"`
+	Warnings   []string            `json:"warnings,omitempty"`
+	OSVAPI     string              `json:"osv_api,omitempty"`
+	DataSources
+	CacheStatus
+}
+
+// VersionResult lists, by ID, the findings in a VulnsOutput that apply to one version
+type VersionResult struct {
+	VulnerabilityCount int      `json:"vulnerability_count"`
+	IDs                []string `json:"ids"`
+	// PossiblyAffected lists findings that could not be placed, e.g. for a commit hash
+	PossiblyAffected []string `json:"possibly_affected,omitempty"`
 }
 
 // VulnSummary provides aggregated vulnerability statistics
@@ -59,99 +446,1373 @@ type VulnSummary struct {
 	Medium   int `json:"medium"`
 	Low      int `json:"low"`
 	Unknown  int `json:"unknown"`
+	// CWEs counts vulnerabilities by CWE ID; one classified under several CWEs counts toward each
+	CWEs map[string]int `json:"cwes,omitempty"`
 }
 
 // HandleVulns implements deps.vulns tool
 // Example: {"ecosystem": "npm", "package": "lodash", "version": "4.17.19"}
 func (tr *ToolRegistry) HandleVulns(ctx context.Context, input VulnsInput) (*VulnsOutput, error) {
-	cacheKey := fmt.Sprintf("vulns:%s:%s:%s", input.Ecosystem, input.Package, input.Version)
+	if input.PURL = strings.TrimSpace(input.PURL); input.PURL != "" {
+		if input.Ecosystem != "" || input.Package != "" || input.Version != "" {
+			return nil, fmt.Errorf("%w: purl cannot be combined with ecosystem, package, or version; put the version in the purl", errInvalidInput)
+		}
+		var err error
+		if input.Ecosystem, input.Package, input.Version, err = parsePackageURL(input.PURL); err != nil {
+			return nil, err
+		}
+	}
+
+	ecosystem, err := validateScanEcosystem(input.Ecosystem)
+	if err != nil {
+		return nil, err
+	}
+	if input.Package, input.Version, err = routeGoVersion(ecosystem, input.Package, input.Version); err != nil {
+		return nil, err
+	}
+	input.Ecosystem, input.Package = tr.normalizePackage(ctx, ecosystem, input.Package)
+	input.Version = strings.TrimSpace(input.Version)
+
+	if len(input.Versions) > 0 {
+		if input.Version != "" || input.VersionRange != "" {
+			return nil, fmt.Errorf("versions cannot be combined with version or version_range")
+		}
+		for _, v := range input.Versions {
+			if strings.TrimSpace(v) == "" {
+				return nil, fmt.Errorf("versions must not contain empty entries")
+			}
+		}
+	}
+
+	if input.Limit < 0 || input.Limit > MaxVulnsLimit || input.Offset < 0 {
+		return nil, fmt.Errorf("%w: limit must be between 0 and %d and offset must not be negative", errInvalidInput, MaxVulnsLimit)
+	}
+
+	if input.ChangedWithinDays < 0 {
+		return nil, fmt.Errorf("%w: changed_within_days must not be negative", errInvalidInput)
+	}
+
+	// Suppressions apply to the cached scan, so they are not part of the cache key
+	suppressions, err := newSuppressionSet(input.Suppress, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var versionRange versionInterval
+	if input.VersionRange != "" {
+		if input.Version != "" {
+			return nil, fmt.Errorf("version and version_range are mutually exclusive")
+		}
+		if versionRange, err = parseVersionRange(input.VersionRange); err != nil {
+			return nil, err
+		}
+	}
+
+	cacheKey := cacheKey("vulns", input.Ecosystem, input.Package, input.Version, strings.Join(input.Versions, ","), input.VersionRange, input.SortBy, strconv.Itoa(input.ChangedWithinDays), strconv.FormatBool(input.FollowRelated))
 
 	// Check cache
-	if tr.cache != nil {
-		if cached, found := tr.cache.Get(cacheKey); found {
-			tr.logger.Debug("cache hit", zap.String("key", cacheKey))
+	if tr.cache != nil && !cacheRefreshing(ctx) {
+		if cached, status, found := tr.getCachedResponse(cacheKey); found {
+			tr.log(ctx).Debug("cache hit", zap.String("key", cacheKey))
 			if output, ok := cached.(*VulnsOutput); ok {
-				return output, nil
+				history.NoteCache(ctx, true)
+				page := pageVulns(suppressions.suppressVulns(output, input), input.Offset, input.Limit)
+				page.CacheStatus = status
+				page.ResolvedQuery = resolvedVulnsQuery(input)
+				return page, nil
 			}
 		}
-		tr.logger.Debug("cache miss", zap.String("key", cacheKey))
+		tr.log(ctx).Debug("cache miss", zap.String("key", cacheKey))
+		history.NoteCache(ctx, false)
 	}
 
-	// Query OSV
-	result, err := tr.osvClient.Query(ctx, input.Ecosystem, input.Package, input.Version)
+	// Identical calls in flight share one scan instead of each querying the upstreams
+	v, shared, err := tr.shareFlight(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		output, err := tr.scanVulns(ctx, input, versionRange)
+		if err != nil {
+			return nil, err
+		}
+		// Cache complete results (5 minutes TTL) so a degraded scan is retried next time
+		if output.DataComplete {
+			tr.setCachedResponse(cacheKey, output, cacheTTL(ctx, 5*time.Minute))
+		}
+		return output, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if shared {
+		tr.log(ctx).Debug("shared in-flight scan", zap.String("key", cacheKey))
+	}
+	page := pageVulns(suppressions.suppressVulns(v.(*VulnsOutput), input), input.Offset, input.Limit)
+	page.ResolvedQuery = resolvedVulnsQuery(input)
+	return page, nil
+}
+
+// resolvedVulnsQuery echoes the normalized coordinates a deps.vulns call scanned
+func resolvedVulnsQuery(input VulnsInput) *ResolvedQuery {
+	return &ResolvedQuery{Ecosystem: input.Ecosystem, Package: input.Package, Version: input.Version}
+}
+
+// shareFlight runs fn once for every concurrent caller with the same key. fn runs detached from
+// the cancellation of the caller that started it, bounded by the tool timeout or that caller's
+// deadline if later, so a caller that gives up or times out neither fails the others nor stops
+// the lookup; each caller still returns as soon as its own ctx is done. The lookup keeps the
+// starting caller's context values, so its logs and upstream spans belong to that call.
+func (tr *ToolRegistry) shareFlight(ctx context.Context, key string, fn func(context.Context) (interface{}, error)) (interface{}, bool, error) {
+	timeout := tr.config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = max(timeout, time.Until(deadline))
+	}
+	ch := tr.flights.DoChan(key, func() (interface{}, error) {
+		flightCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		defer cancel()
+		return fn(flightCtx)
+	})
+	select {
+	case res := <-ch:
+		return res.Val, res.Shared, res.Err
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
+
+// pageVulns returns a copy of a full result holding one page of its findings, leaving the
+// cached original intact. A zero limit uses DefaultVulnsLimit.
+func pageVulns(full *VulnsOutput, offset, limit int) *VulnsOutput {
+	if limit == 0 {
+		limit = DefaultVulnsLimit
+	}
+	total := len(full.Vulnerabilities)
+	start := min(offset, total)
+	end := min(start+limit, total)
+
+	page := *full
+	page.Vulnerabilities = full.Vulnerabilities[start:end:end]
+	if page.Vulnerabilities == nil {
+		page.Vulnerabilities = []Finding{}
+	}
+	page.Pagination = &Pagination{
+		Offset:        offset,
+		Limit:         limit,
+		ReturnedCount: end - start,
+		TotalCount:    total,
+		HasMore:       end < total,
+	}
+	return &page
+}
+
+// scanVulns queries the vulnerability sources for a validated deps.vulns input and builds
+// the enriched, uncached output
+func (tr *ToolRegistry) scanVulns(ctx context.Context, input VulnsInput, versionRange versionInterval) (*VulnsOutput, error) {
+	// A bare commit hash is not a version the sources can match, so scan every version and
+	// place the commit against the advisories' GIT ranges locally
+	queryVersion := input.Version
+	commitPinned := osv.IsCommitHash(input.Version)
+	if commitPinned {
+		queryVersion = ""
+	}
+
+	// Query OSV, by package URL when one was given and its version is queryable as is
+	var result *osv.QueryResponse
+	var err error
+	if input.PURL != "" && !commitPinned {
+		result, err = tr.osvClient.QueryByPURL(ctx, input.PURL)
+	} else {
+		result, err = tr.osvClient.Query(ctx, input.Ecosystem, input.Package, queryVersion)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("query OSV: %w", err)
 	}
 
-	// Compute summary
-	summary := VulnSummary{}
-	for _, vuln := range result.Vulns {
-		severity := "unknown"
-		if len(vuln.Severity) > 0 {
-			severity = vuln.Severity[0].Score
-		}
+	var sources DataSources
+	sources.record(SourceOSV, nil)
+	findings := newFindings(result.Vulns)
 
-		switch {
-		case containsIgnoreCase(severity, "critical"):
-			summary.Critical++
-		case containsIgnoreCase(severity, "high"):
-			summary.High++
-		case containsIgnoreCase(severity, "medium"):
-			summary.Medium++
-		case containsIgnoreCase(severity, "low"):
-			summary.Low++
-		default:
-			summary.Unknown++
-		}
+	// Merge GitHub advisories, deduping against OSV by ID and alias
+	advisories, queried, ghsaErr := tr.githubAdvisories(ctx, input.Ecosystem, input.Package, queryVersion)
+	if queried {
+		sources.record(SourceGHSA, ghsaErr)
+		findings = mergeAdvisories(findings, advisories, SourceGHSA)
+	}
+
+	if commitPinned {
+		findings = matchCommit(findings, input.Package, input.Version, depsdev.SchemeFor(input.Ecosystem))
+	}
+
+	// Narrow an all-versions scan to the findings affecting the requested range
+	var rangeAnalysis *RangeAnalysis
+	if input.VersionRange != "" {
+		rangeAnalysis, findings = analyzeVersionRange(versionRange, input.VersionRange, findings, input.Package)
+	}
+
+	// Or to the findings affecting at least one of the requested versions
+	if len(input.Versions) > 0 {
+		findings = matchVersions(findings, input.Package, input.Versions, depsdev.SchemeFor(input.Ecosystem))
+	}
+
+	// Or to what is new: findings published or modified recently
+	now := time.Now()
+	if input.ChangedWithinDays > 0 {
+		findings = changedWithin(findings, input.ChangedWithinDays, now)
+	}
+	setAdvisoryAges(findings, now)
+	setAffectedVersions(findings, input.Package, depsdev.SchemeFor(input.Ecosystem))
+
+	// Cross-reference deps.dev's copy of each advisory to fill gaps in sparse OSV entries
+	if len(findings) > 0 {
+		sources.record(SourceDepsDev, tr.enrichDepsDevAdvisories(ctx, findings))
+	}
+
+	// Then, on request, take a severity still missing from the entries an advisory defers to
+	if input.FollowRelated {
+		tr.inheritRelatedSeverity(ctx, findings)
+	}
+
+	// Compute summary
+	summary := computeVulnSummary(findingVulns(findings))
+
+	// Enrich with exploit-probability scores
+	sources.record(SourceEPSS, tr.enrichEPSS(ctx, findings))
+	_, kevErr := tr.markKnownExploited(ctx, findings)
+	sources.record(SourceKEV, kevErr)
+	scoreFindings(findings, tr.config.RiskWeights)
+	sortFindings(findings, input.SortBy)
+
+	var matrix map[string]*VersionResult
+	if len(input.Versions) > 0 {
+		matrix = versionMatrix(findings, input.Package, input.Versions, depsdev.SchemeFor(input.Ecosystem))
 	}
 
 	output := &VulnsOutput{
 		Package:            input.Package,
 		Ecosystem:          input.Ecosystem,
 		Version:            input.Version,
-		VulnerabilityCount: len(result.Vulns),
-		Vulnerabilities:    result.Vulns,
+		VulnerabilityCount: len(findings),
+		Vulnerabilities:    findings,
 		Summary:            summary,
-	}
-
-	// Cache result (5 minutes TTL)
-	if tr.cache != nil {
-		tr.cache.Set(cacheKey, output, 5*time.Minute)
+		VersionRange:       rangeAnalysis,
+		Versions:           matrix,
+		OSVAPI:             tr.osvClient.BaseURL() + osv.QueryPath,
+		DataSources:        sources,
 	}
 
 	return output, nil
 }
 
-// Register registers all tools with the server
-func (tr *ToolRegistry) Register(srv *hypermcp.Server) error {
-	mcpServer := srv.MCP()
+// addTool registers a tool with the server unless the configuration disables it
+func (tr *ToolRegistry) addTool(srv *hypermcp.Server, tool *mcp.Tool, handler mcp.ToolHandler) {
+	if !tr.config.ToolEnabled(tool.Name) {
+		tr.logger.Info("Tool disabled by configuration", zap.String("tool", tool.Name))
+		return
+	}
+	srv.MCP().AddTool(tool, limitResponse(tr.config.MaxResponseBytes, handler))
+	srv.IncrementToolCount()
+}
+
+// Register registers all tools with the server
+func (tr *ToolRegistry) Register(srv *hypermcp.Server) error {
+	mcpServer := srv.MCP()
+
+	// Trace tool calls innermost, inside history, so spans can carry the cache outcome
+	if tr.config.TracerProvider != nil {
+		mcpServer.AddReceivingMiddleware(tracing.Middleware(tr.config.TracerProvider))
+	}
+	// Scope each tool call's logs, including upstream requests, to one request ID
+	mcpServer.AddReceivingMiddleware(reqlog.Middleware(tr.logger))
+	// Keep a bounded record of recent tool calls for the history resource
+	mcpServer.AddReceivingMiddleware(history.Middleware(tr.history))
+	// Count tool-call outcomes per ecosystem for meta.stats
+	mcpServer.AddReceivingMiddleware(stats.Middleware(tr.stats, func(ecosystem string) (string, bool) {
+		name, err := validateScanEcosystem(ecosystem)
+		return name, err == nil
+	}))
+	// Let shutdown wait for in-flight tool calls and turn away new ones
+	mcpServer.AddReceivingMiddleware(drain.Middleware(tr.drain))
+
+	// deps.vulns - Vulnerability scanning tool
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "deps.vulns",
+			Description: "Query OSV.dev (and GitHub Security Advisories when a token is configured) for known vulnerabilities in a package. Supports npm, PyPI, Go, Maven, Cargo, NuGet, RubyGems, and Packagist ecosystems, plus OS distribution ecosystems such as Debian:11.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"purl": map[string]interface{}{
+						"type":        "string",
+						"description": "Package URL naming the package and optionally its version (e.g. 'pkg:npm/lodash@4.17.19'), for SBOM-driven workflows. Use instead of ecosystem, package, and version",
+					},
+					"ecosystem": map[string]interface{}{
+						"type":        "string",
+						"description": "Package ecosystem (npm, PyPI, Go, Maven, crates.io, NuGet, RubyGems, Packagist). Packagist names are vendor-prefixed (e.g. symfony/console). NuGet package IDs are case-insensitive. OS packages use OSV's distribution ecosystems, passed through with their release suffix (e.g. Debian:11, Ubuntu:22.04, Alpine:v3.18). Required unless purl is given.",
+					},
+					"package": map[string]interface{}{
+						"type":        "string",
+						"description": "Package name (e.g., 'lodash' for npm, 'github.com/gin-gonic/gin' for Go). Required unless purl is given",
+					},
+					"version": map[string]interface{}{
+						"type":        "string",
+						"description": "Specific version to check (optional, omit to check all versions). A git commit hash or Go pseudo-version is matched against GIT ranges; findings that cannot be placed are reported as possibly affected",
+					},
+					"versions": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Several versions to check at once instead of a single version. The package is fetched once and 'versions' in the response maps each version to the IDs of the findings that apply to it",
+					},
+					"version_range": map[string]interface{}{
+						"type":        "string",
+						"description": "Version range to check instead of a single version (e.g. '>=4.0.0 <4.17.21'). Reports the vulnerable subset of the range and the earliest safe version",
+					},
+					"output_format": map[string]interface{}{
+						"type":        "string",
+						"description": "Response format: 'json' (default) or 'csv'",
+						"enum":        []string{OutputFormatJSON, OutputFormatCSV},
+					},
+					"sort_by": map[string]interface{}{
+						"type":        "string",
+						"description": "Order findings by 'severity' (default), 'published' (newest first), 'modified' (most recently changed first), 'id', 'epss' (exploit probability), or 'risk' (weighted CVSS/EPSS/KEV score). Ties fall back to severity, published date, then ID",
+						"enum":        sortOrders,
+					},
+					"changed_within_days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Only return findings published or modified in the last N days, e.g. 7 for what changed since last week (optional). Findings also carry days_since_published and days_since_modified",
+						"minimum":     0,
+					},
+					"follow_related": map[string]interface{}{
+						"type":        "boolean",
+						"description": "For findings without a usable severity, fetch the upstream and related OSV entries (up to 2 links away) and inherit the first severity found; such findings name the source in severity_inherited_from (optional)",
+					},
+					"suppress": map[string]interface{}{
+						"type":        "array",
+						"description": "Accepted-risk vulnerabilities to move out of vulnerabilities and the counts into a separate suppressed list (optional)",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"id": map[string]interface{}{
+									"type":        "string",
+									"description": "Vulnerability ID or alias, e.g. GHSA-35jh-r3h4-6jhm or CVE-2021-23337",
+								},
+								"expires": map[string]interface{}{
+									"type":        "string",
+									"description": "Last day the suppression applies (YYYY-MM-DD, UTC); an expired suppression is ignored with a warning",
+								},
+								"justification": map[string]interface{}{
+									"type":        "string",
+									"description": "Why the risk is accepted, echoed in the suppressed list",
+								},
+							},
+							"required": []string{"id"},
+						},
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": fmt.Sprintf("Maximum findings to return (default %d, max %d). The summary and vulnerability_count always cover every finding", DefaultVulnsLimit, MaxVulnsLimit),
+					},
+					"offset": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of findings to skip, for paging with pagination.has_more",
+					},
+				},
+			},
+		},
+		withDeadline(tr.config.Timeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params VulnsInput
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Invalid input: %v", err),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			if err := validateOutputFormat(params.OutputFormat); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: err.Error(),
+					}},
+					IsError: true,
+				}, nil
+			}
+			if err := validateSortBy(params.SortBy); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: err.Error(),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			result, err := tr.HandleVulns(ctx, params)
+			if err != nil {
+				return errorResult(err), nil
+			}
+
+			if params.OutputFormat == OutputFormatCSV {
+				return csvResult(result), nil
+			}
+
+			data, _ := json.MarshalIndent(result, "", "  ")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: string(data),
+				}},
+			}, nil
+		}),
+	)
+
+	// deps.batch_vulns - Multi-package vulnerability scanning tool
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "deps.batch_vulns",
+			Description: "Query OSV.dev for known vulnerabilities in several packages with a single batch request. Results are aligned to the input; entries that fail carry their own error instead of failing the batch.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"packages": map[string]interface{}{
+						"type":        "array",
+						"description": "Packages to scan, each with ecosystem, package, and optional version",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"ecosystem": map[string]interface{}{"type": "string"},
+								"package":   map[string]interface{}{"type": "string"},
+								"version":   map[string]interface{}{"type": "string"},
+							},
+							"required": []string{"ecosystem", "package"},
+						},
+					},
+					"output_format": map[string]interface{}{
+						"type":        "string",
+						"description": "Response format: 'json' (default) or 'csv'",
+						"enum":        []string{OutputFormatJSON, OutputFormatCSV},
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Return the upstream calls the scan would make, with package and batch counts, without making them",
+					},
+				},
+				"required": []string{"packages"},
+			},
+		},
+		withDeadline(tr.config.BatchTimeout, withProgress(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params BatchVulnsInput
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Invalid input: %v", err),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			if err := validateOutputFormat(params.OutputFormat); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: err.Error(),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			result, err := tr.HandleBatchVulns(ctx, params)
+			if err != nil {
+				return errorResult(err), nil
+			}
+
+			if params.OutputFormat == OutputFormatCSV && !params.DryRun {
+				return csvResult(result.scanned()...), nil
+			}
+
+			data, _ := json.MarshalIndent(result, "", "  ")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: string(data),
+				}},
+			}, nil
+		})),
+	)
+
+	// deps.scan_manifest - Lockfile/manifest vulnerability scanning tool
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "deps.scan_manifest",
+			Description: "Parse a dependency manifest or lockfile and scan every pinned dependency for known vulnerabilities. Supported files: " + strings.Join(manifest.SupportedFormats(), ", ") + ".",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"filename": map[string]interface{}{
+						"type":        "string",
+						"description": "Manifest filename, used to detect the format (e.g., 'Cargo.lock')",
+					},
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "Full text content of the manifest",
+					},
+					"runtime_only": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Skip test/provided scoped (pom.xml) and packages-dev (composer.lock) dependencies",
+					},
+					"output_format": map[string]interface{}{
+						"type":        "string",
+						"description": "Response format: 'json' (default) or 'csv'",
+						"enum":        []string{OutputFormatJSON, OutputFormatCSV},
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Return the upstream calls the scan would make, with package and batch counts, without making them",
+					},
+				},
+				"required": []string{"filename", "content"},
+			},
+		},
+		withDeadline(tr.config.BatchTimeout, withProgress(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params ScanManifestInput
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Invalid input: %v", err),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			if err := validateOutputFormat(params.OutputFormat); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: err.Error(),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			result, err := tr.HandleScanManifest(ctx, params)
+			if err != nil {
+				return errorResult(err), nil
+			}
+
+			if params.OutputFormat == OutputFormatCSV && !params.DryRun {
+				return csvResult(result.scanned()...), nil
+			}
+
+			data, _ := json.MarshalIndent(result, "", "  ")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: string(data),
+				}},
+			}, nil
+		})),
+	)
+
+	// deps.gate - Single pass/fail verdict for CI
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "deps.gate",
+			Description: "Scan a dependency manifest or lockfile and return one pass/fail verdict for CI: fails when findings exceed the per-severity thresholds, a finding is in the CISA KEV catalog, or (with a license policy) a dependency's license is denied. Reasons and the violating findings are listed. Defaults to failing on any critical or known-exploited vulnerability. Supported files: " + strings.Join(manifest.SupportedFormats(), ", ") + ".",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"filename": map[string]interface{}{
+						"type":        "string",
+						"description": "Manifest filename, used to detect the format (e.g., 'Cargo.lock')",
+					},
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "Full text content of the manifest",
+					},
+					"runtime_only": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Skip test/provided scoped (pom.xml) and packages-dev (composer.lock) dependencies",
+					},
+					"thresholds": map[string]interface{}{
+						"type":        "object",
+						"description": "Failure thresholds. Omit for the default of max_critical 0 and fail_on_kev; an omitted max_* has no limit.",
+						"properties": map[string]interface{}{
+							"max_critical": map[string]interface{}{
+								"type":        "integer",
+								"description": "Most critical vulnerabilities allowed",
+							},
+							"max_high": map[string]interface{}{
+								"type":        "integer",
+								"description": "Most high vulnerabilities allowed",
+							},
+							"max_medium": map[string]interface{}{
+								"type":        "integer",
+								"description": "Most medium vulnerabilities allowed",
+							},
+							"max_low": map[string]interface{}{
+								"type":        "integer",
+								"description": "Most low vulnerabilities allowed",
+							},
+							"fail_on_kev": map[string]interface{}{
+								"type":        "boolean",
+								"description": "Fail on any vulnerability listed in the CISA KEV catalog",
+							},
+							"fail_on_scan_errors": map[string]interface{}{
+								"type":        "boolean",
+								"description": "Fail when a dependency could not be scanned",
+							},
+						},
+					},
+					"license_policy": map[string]interface{}{
+						"type":        "object",
+						"description": "License policy, as for license.audit_manifest. Licenses are only checked when set.",
+						"properties": map[string]interface{}{
+							"deny_categories": map[string]interface{}{
+								"type":        "array",
+								"items":       map[string]interface{}{"type": "string"},
+								"description": "License categories to reject: " + strings.Join(tr.spdxClient.ListCategories(), ", "),
+							},
+							"deny_licenses": map[string]interface{}{
+								"type":        "array",
+								"items":       map[string]interface{}{"type": "string"},
+								"description": "SPDX license identifiers to reject (e.g., 'AGPL-3.0')",
+							},
+							"deny_unknown": map[string]interface{}{
+								"type":        "boolean",
+								"description": "Reject packages whose license is missing, unresolvable, or not in the SPDX dataset",
+							},
+						},
+					},
+					"suppress": map[string]interface{}{
+						"type":        "array",
+						"description": "Accepted-risk vulnerabilities that do not count toward the verdict; they are reported under suppressed (optional)",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"id": map[string]interface{}{
+									"type":        "string",
+									"description": "Vulnerability ID or alias, e.g. GHSA-35jh-r3h4-6jhm or CVE-2021-23337",
+								},
+								"expires": map[string]interface{}{
+									"type":        "string",
+									"description": "Last day the suppression applies (YYYY-MM-DD, UTC); an expired suppression is ignored with a warning",
+								},
+								"justification": map[string]interface{}{
+									"type":        "string",
+									"description": "Why the risk is accepted, echoed in the suppressed list",
+								},
+							},
+							"required": []string{"id"},
+						},
+					},
+				},
+				"required": []string{"filename", "content"},
+			},
+		},
+		withDeadline(tr.config.BatchTimeout, withProgress(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params GateInput
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Invalid input: %v", err),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			result, err := tr.HandleGate(ctx, params)
+			if err != nil {
+				return errorResult(err), nil
+			}
+
+			data, _ := json.MarshalIndent(result, "", "  ")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: string(data),
+				}},
+			}, nil
+		})),
+	)
+
+	// deps.vex - OpenVEX document for a component set
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "deps.vex",
+			Description: "Produce an OpenVEX document for a set of pinned components. Each advisory known for a component's package gets a statement: affected (with the upgrade to make), fixed, not_affected (the version predates the vulnerable code), or under_investigation when the version cannot be placed. Products are identified by package URL.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"components": map[string]interface{}{
+						"type":        "array",
+						"description": "Components to make statements about, each with ecosystem, package, and version",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"ecosystem": map[string]interface{}{"type": "string"},
+								"package":   map[string]interface{}{"type": "string"},
+								"version":   map[string]interface{}{"type": "string"},
+							},
+							"required": []string{"ecosystem", "package", "version"},
+						},
+					},
+					"author": map[string]interface{}{
+						"type":        "string",
+						"description": "Document author (default 'PackagePulse')",
+					},
+				},
+				"required": []string{"components"},
+			},
+		},
+		withDeadline(tr.config.BatchTimeout, withProgress(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params VEXInput
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Invalid input: %v", err),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			result, err := tr.HandleVEX(ctx, params)
+			if err != nil {
+				return errorResult(err), nil
+			}
+
+			data, _ := json.MarshalIndent(result, "", "  ")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: string(data),
+				}},
+			}, nil
+		})),
+	)
+
+	// deps.health - Package health metrics tool
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "deps.health",
+			Description: "Query deps.dev for package health metrics including maintenance score, update frequency, and recommendations. Supports npm, pypi, Go, and other ecosystems; Packagist packages are read from the Packagist registry.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"ecosystem": map[string]interface{}{
+						"type":        "string",
+						"description": "Package ecosystem (npm, pypi, go, maven, cargo, nuget, rubygems, packagist). Packagist names are vendor-prefixed (e.g. symfony/console). NuGet package IDs are case-insensitive.",
+					},
+					"package": map[string]interface{}{
+						"type":        "string",
+						"description": "Package name (e.g., 'express' for npm, 'requests' for pypi)",
+					},
+					"version": map[string]interface{}{
+						"type":        "string",
+						"description": "Specific version to report on (optional). Adds the version's age, licenses, provenance, and releases behind latest",
+					},
+					"explain": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Add score_breakdown: the points each signal (recency, versions, repository, documentation, license) contributed to the maintenance score, out of its maximum",
+					},
+				},
+				"required": []string{"ecosystem", "package"},
+			},
+		},
+		withDeadline(tr.config.Timeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tr.HandleHealth(ctx, req)
+		}),
+	)
+
+	// deps.batch_health - Multi-package health tool
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "deps.batch_health",
+			Description: "Query package health metrics for several packages at once. Results are aligned to the input, with a summary counting packages per maintenance level; entries that fail carry their own error instead of failing the batch.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"packages": map[string]interface{}{
+						"type":        "array",
+						"description": "Packages to check, each with ecosystem, package, and optional version",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"ecosystem": map[string]interface{}{"type": "string"},
+								"package":   map[string]interface{}{"type": "string"},
+								"version":   map[string]interface{}{"type": "string"},
+							},
+							"required": []string{"ecosystem", "package"},
+						},
+					},
+				},
+				"required": []string{"packages"},
+			},
+		},
+		withDeadline(tr.config.BatchTimeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params BatchHealthInput
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Invalid input: %v", err),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			result, err := tr.HandleBatchHealth(ctx, params)
+			if err != nil {
+				return errorResult(err), nil
+			}
+
+			data, _ := json.MarshalIndent(result, "", "  ")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: string(data),
+				}},
+			}, nil
+		}),
+	)
+
+	// deps.name_check - Typosquat detection tool
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "deps.name_check",
+			Description: "Check whether a package name is a likely typosquat of a popular package: a small edit distance from a well-known name, or a recently published package with a near-identical name. Returns the suspected target and edit distance. Use before suggesting a package to install.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"ecosystem": map[string]interface{}{
+						"type":        "string",
+						"description": "Package ecosystem (npm, pypi, go, maven, cargo, nuget, rubygems, packagist)",
+					},
+					"package": map[string]interface{}{
+						"type":        "string",
+						"description": "Package name to check (e.g., 'expresss' for npm)",
+					},
+				},
+				"required": []string{"ecosystem", "package"},
+			},
+		},
+		withDeadline(tr.config.Timeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params NameCheckInput
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Invalid input: %v", err),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			result, err := tr.HandleNameCheck(ctx, params)
+			if err != nil {
+				return errorResult(err), nil
+			}
+
+			data, _ := json.MarshalIndent(result, "", "  ")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: string(data),
+				}},
+			}, nil
+		}),
+	)
+
+	// deps.find_package - Cross-ecosystem package search tool
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "deps.find_package",
+			Description: "Find which ecosystems have a package by a name (e.g. npm or PyPI), looking the name up in every supported ecosystem at once. Returns each match with its latest version, version count, last publish date, licenses, repository, and maintenance level; an empty list when nothing matches. Use to disambiguate a name before a full scan.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"package": map[string]interface{}{
+						"type":        "string",
+						"description": "Package name to search for (e.g., 'requests'). Ecosystems whose naming rules the name breaks, such as Go for a name without a module path, are skipped",
+					},
+				},
+				"required": []string{"package"},
+			},
+		},
+		withDeadline(tr.config.Timeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params FindPackageInput
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Invalid input: %v", err),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			result, err := tr.HandleFindPackage(ctx, params)
+			if err != nil {
+				return errorResult(err), nil
+			}
+
+			data, _ := json.MarshalIndent(result, "", "  ")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: string(data),
+				}},
+			}, nil
+		}),
+	)
+
+	// license.info - SPDX license information tool
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "license.info",
+			Description: "Query SPDX license database for detailed license information including OSI approval status, compatibility, category, and the permissions, conditions, and limitations the license imposes. Supports all standard SPDX license identifiers.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"license_id": map[string]interface{}{
+						"type":        "string",
+						"description": "SPDX license identifier (e.g., 'MIT', 'Apache-2.0', 'GPL-3.0')",
+					},
+				},
+				"required": []string{"license_id"},
+			},
+		},
+		withDeadline(tr.config.Timeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params LicenseInput
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Invalid input: %v", err),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			return tr.HandleLicense(ctx, params)
+		}),
+	)
+
+	// license.batch_info - Resolve many licenses at once
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "license.batch_info",
+			Description: "Resolve a set of SPDX license identifiers or expressions in one call. Returns license details keyed by ID, a count of entries per license category, and any identifiers that could not be resolved.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"license_ids": map[string]interface{}{
+						"type":        "array",
+						"description": "SPDX license identifiers or expressions (e.g., 'MIT', 'MIT OR Apache-2.0')",
+						"items":       map[string]interface{}{"type": "string"},
+					},
+				},
+				"required": []string{"license_ids"},
+			},
+		},
+		withDeadline(tr.config.Timeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params BatchLicenseInput
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Invalid input: %v", err),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			return tr.HandleBatchLicense(ctx, params)
+		}),
+	)
+
+	// license.validate_expression - SPDX expression syntax and identifier check
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "license.validate_expression",
+			Description: "Check an SPDX license expression before publishing: whether it parses and whether every license and exception identifier is known. Each problem is reported with its character index and reason, e.g. \"unknown license 'Apache2' at index 7\".",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"expression": map[string]interface{}{
+						"type":        "string",
+						"description": "SPDX license expression (e.g., 'MIT OR (Apache-2.0 AND BSD-3-Clause)', 'GPL-2.0-or-later WITH Classpath-exception-2.0')",
+					},
+				},
+				"required": []string{"expression"},
+			},
+		},
+		withDeadline(tr.config.Timeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params ValidateExpressionInput
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Invalid input: %v", err),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			result, err := tr.HandleValidateExpression(ctx, params)
+			if err != nil {
+				return errorResult(err), nil
+			}
+
+			data, _ := json.MarshalIndent(result, "", "  ")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: string(data),
+				}},
+			}, nil
+		}),
+	)
+
+	// license.audit_manifest - License compliance audit for a whole manifest
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "license.audit_manifest",
+			Description: "Parse a dependency manifest or lockfile, resolve each dependency's declared license from deps.dev, and check it against a license policy. Returns every package's license and category, violating packages first. Compound expressions are honored: OR needs one allowed choice, AND needs all. Supported files: " + strings.Join(manifest.SupportedFormats(), ", ") + ".",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"filename": map[string]interface{}{
+						"type":        "string",
+						"description": "Manifest filename, used to detect the format (e.g., 'package-lock.json')",
+					},
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "Full text content of the manifest",
+					},
+					"runtime_only": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Skip test/provided scoped (pom.xml) and packages-dev (composer.lock) dependencies",
+					},
+					"policy": map[string]interface{}{
+						"type":        "object",
+						"description": "License policy. With no policy every package is allowed and the audit only reports licenses.",
+						"properties": map[string]interface{}{
+							"deny_categories": map[string]interface{}{
+								"type":        "array",
+								"items":       map[string]interface{}{"type": "string"},
+								"description": "License categories to reject: " + strings.Join(tr.spdxClient.ListCategories(), ", "),
+							},
+							"deny_licenses": map[string]interface{}{
+								"type":        "array",
+								"items":       map[string]interface{}{"type": "string"},
+								"description": "SPDX license identifiers to reject (e.g., 'AGPL-3.0')",
+							},
+							"deny_unknown": map[string]interface{}{
+								"type":        "boolean",
+								"description": "Reject packages whose license is missing, unresolvable, or not in the SPDX dataset",
+							},
+						},
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Return the upstream calls the audit would make, with package counts, without making them",
+					},
+				},
+				"required": []string{"filename", "content"},
+			},
+		},
+		withDeadline(tr.config.BatchTimeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params LicenseAuditInput
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Invalid input: %v", err),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			result, err := tr.HandleLicenseAudit(ctx, params)
+			if err != nil {
+				return errorResult(err), nil
+			}
+
+			data, _ := json.MarshalIndent(result, "", "  ")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: string(data),
+				}},
+			}, nil
+		}),
+	)
+
+	// license.osi_approved - OSI approval screen for license IDs or a manifest's dependencies
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "license.osi_approved",
+			Description: "Screen licenses against the OSI-approved list, for organizations that only allow OSI-approved licenses. Give license IDs or expressions, or a manifest whose dependencies' licenses are resolved from deps.dev. Returns the licenses split into approved, not_approved, and unknown (not in the SPDX dataset, so needing manual review), each with its FSF libre status. Supported files: " + strings.Join(manifest.SupportedFormats(), ", ") + ".",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"license_ids": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "SPDX license identifiers or expressions (e.g., ['MIT', 'CC0-1.0', 'GPL-2.0-only OR Apache-2.0'])",
+					},
+					"filename": map[string]interface{}{
+						"type":        "string",
+						"description": "Manifest filename, used to detect the format (e.g., 'package-lock.json'); use instead of license_ids",
+					},
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "Full text content of the manifest",
+					},
+					"runtime_only": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Skip test/provided scoped (pom.xml) and packages-dev (composer.lock) dependencies",
+					},
+				},
+			},
+		},
+		withDeadline(tr.config.BatchTimeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params OSIApprovedInput
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Invalid input: %v", err),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			result, err := tr.HandleOSIApproved(ctx, params)
+			if err != nil {
+				return errorResult(err), nil
+			}
+
+			data, _ := json.MarshalIndent(result, "", "  ")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: string(data),
+				}},
+			}, nil
+		}),
+	)
+
+	// license.tree_conflicts - License compatibility across the transitive dependency tree
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "license.tree_conflicts",
+			Description: "Resolve a package's transitive dependencies from deps.dev and report licenses that cannot be distributed under the project's license (e.g. a GPL-3.0 dependency pulled into an Apache-2.0 project). Conflicts are grouped by the offending license, each with its dependency path from the root. Dual-licensed dependencies use their most permissive compatible option.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"ecosystem": map[string]interface{}{
+						"type":        "string",
+						"description": "Package ecosystem (npm, PyPI, Go, Maven, crates.io, NuGet, RubyGems)",
+					},
+					"package": map[string]interface{}{
+						"type":        "string",
+						"description": "Package name",
+					},
+					"version": map[string]interface{}{
+						"type":        "string",
+						"description": "Version to resolve (default: latest)",
+					},
+					"project_license": map[string]interface{}{
+						"type":        "string",
+						"description": "SPDX expression the project is distributed under (default: the license the package declares)",
+					},
+				},
+				"required": []string{"ecosystem", "package"},
+			},
+		},
+		withDeadline(tr.config.BatchTimeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params TreeConflictsInput
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Invalid input: %v", err),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			result, err := tr.HandleTreeConflicts(ctx, params)
+			if err != nil {
+				return errorResult(err), nil
+			}
+
+			data, _ := json.MarshalIndent(result, "", "  ")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: string(data),
+				}},
+			}, nil
+		}),
+	)
+
+	// license.version_diff - Relicensing between two versions of a package
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "license.version_diff",
+			Description: "Compare the licenses two versions of a package declare on deps.dev (Packagist for Composer packages) and report whether the package was relicensed, whether the new license is more or less permissive, and a flag when the change needs review before upgrading.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"ecosystem": map[string]interface{}{
+						"type":        "string",
+						"description": "Package ecosystem (npm, pypi, go, maven, cargo, nuget, rubygems, packagist)",
+					},
+					"package": map[string]interface{}{
+						"type":        "string",
+						"description": "Package name (e.g., 'lodash' for npm, 'requests' for pypi)",
+					},
+					"from_version": map[string]interface{}{
+						"type":        "string",
+						"description": "Version in use (e.g., '1.4.2')",
+					},
+					"to_version": map[string]interface{}{
+						"type":        "string",
+						"description": "Version to compare against, typically the upgrade target (e.g., '2.0.0')",
+					},
+				},
+				"required": []string{"ecosystem", "package", "from_version", "to_version"},
+			},
+		},
+		withDeadline(tr.config.Timeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params LicenseDiffInput
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Invalid input: %v", err),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			result, err := tr.HandleLicenseDiff(ctx, params)
+			if err != nil {
+				return errorResult(err), nil
+			}
+
+			data, _ := json.MarshalIndent(result, "", "  ")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: string(data),
+				}},
+			}, nil
+		}),
+	)
+
+	// deps.security_delta - Vulnerabilities an upgrade fixes
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "deps.security_delta",
+			Description: "List only the known vulnerabilities that affect the version in use and not the upgrade target, i.e. what the upgrade fixes, with severities, a summary count, and a justification sentence for the pull request description.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"ecosystem": map[string]interface{}{
+						"type":        "string",
+						"description": "Package ecosystem (npm, pypi, go, maven, cargo, nuget, rubygems, packagist)",
+					},
+					"package": map[string]interface{}{
+						"type":        "string",
+						"description": "Package name (e.g., 'lodash' for npm, 'requests' for pypi)",
+					},
+					"from_version": map[string]interface{}{
+						"type":        "string",
+						"description": "Version in use (e.g., '4.17.15')",
+					},
+					"to_version": map[string]interface{}{
+						"type":        "string",
+						"description": "Version to upgrade to (e.g., '4.17.21')",
+					},
+				},
+				"required": []string{"ecosystem", "package", "from_version", "to_version"},
+			},
+		},
+		withDeadline(tr.config.Timeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params SecurityDeltaInput
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Invalid input: %v", err),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			result, err := tr.HandleSecurityDelta(ctx, params)
+			if err != nil {
+				return errorResult(err), nil
+			}
+
+			data, _ := json.MarshalIndent(result, "", "  ")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: string(data),
+				}},
+			}, nil
+		}),
+	)
+
+	// deps.upgrade_plan - Smart upgrade recommendations tool
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "deps.upgrade_plan",
+			Description: "Generate smart upgrade recommendations by analyzing vulnerabilities, package health, and maintenance status. Provides priority-based upgrade advice and checks for potential breaking changes.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"ecosystem": map[string]interface{}{
+						"type":        "string",
+						"description": "Package ecosystem (npm, pypi, go, maven, cargo, nuget, rubygems, packagist). Packagist names are vendor-prefixed (e.g. symfony/console). NuGet package IDs are case-insensitive.",
+					},
+					"package": map[string]interface{}{
+						"type":        "string",
+						"description": "Package name (e.g., 'lodash' for npm, 'requests' for pypi)",
+					},
+					"current_version": map[string]interface{}{
+						"type":        "string",
+						"description": "Current version in use (e.g., '4.17.19')",
+					},
+					"include_prerelease": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Target the newest prerelease when it is newer than the latest stable release, for users on a prerelease channel",
+					},
+					"include_yanked": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Allow targeting releases the registry has yanked or deprecated",
+					},
+				},
+				"required": []string{"ecosystem", "package", "current_version"},
+			},
+		},
+		withDeadline(tr.config.Timeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params UpgradePlanInput
+			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Invalid input: %v", err),
+					}},
+					IsError: true,
+				}, nil
+			}
+
+			return tr.HandleUpgradePlan(ctx, params)
+		}),
+	)
 
-	// deps.vulns - Vulnerability scanning tool
-	mcpServer.AddTool(
+	// deps.freshness - How far behind the latest release a version is
+	tr.addTool(srv,
 		&mcp.Tool{
-			Name:        "deps.vulns",
-			Description: "Query OSV.dev for known vulnerabilities in a package. Supports npm, PyPI, Go, Maven, Cargo, and NuGet ecosystems.",
+			Name:        "deps.freshness",
+			Description: "Report how stale a pinned version is: releases behind the latest, days between their publish dates, and the major/minor/patch gap. Lighter than deps.upgrade_plan, with no vulnerability or health analysis.",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
 					"ecosystem": map[string]interface{}{
 						"type":        "string",
-						"description": "Package ecosystem (npm, pypi, Go, maven, cargo, nuget)",
+						"description": "Package ecosystem (npm, pypi, go, maven, cargo, nuget, rubygems, packagist)",
 					},
 					"package": map[string]interface{}{
 						"type":        "string",
-						"description": "Package name (e.g., 'lodash' for npm, 'github.com/gin-gonic/gin' for Go)",
+						"description": "Package name (e.g., 'lodash' for npm, 'requests' for pypi)",
 					},
-					"version": map[string]interface{}{
+					"current_version": map[string]interface{}{
 						"type":        "string",
-						"description": "Specific version to check (optional, omit to check all versions)",
+						"description": "Current version in use (e.g., '4.17.15')",
 					},
 				},
-				"required": []string{"ecosystem", "package"},
+				"required": []string{"ecosystem", "package", "current_version"},
 			},
 		},
-		func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			var params VulnsInput
+		withDeadline(tr.config.Timeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params FreshnessInput
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
 				return &mcp.CallToolResult{
 					Content: []mcp.Content{&mcp.TextContent{
@@ -161,14 +1822,9 @@ func (tr *ToolRegistry) Register(srv *hypermcp.Server) error {
 				}, nil
 			}
 
-			result, err := tr.HandleVulns(ctx, params)
+			result, err := tr.HandleFreshness(ctx, params)
 			if err != nil {
-				return &mcp.CallToolResult{
-					Content: []mcp.Content{&mcp.TextContent{
-						Text: err.Error(),
-					}},
-					IsError: true,
-				}, nil
+				return errorResult(err), nil
 			}
 
 			data, _ := json.MarshalIndent(result, "", "  ")
@@ -177,54 +1833,39 @@ func (tr *ToolRegistry) Register(srv *hypermcp.Server) error {
 					Text: string(data),
 				}},
 			}, nil
-		},
+		}),
 	)
-	srv.IncrementToolCount()
 
-	// deps.health - Package health metrics tool
-	mcpServer.AddTool(
+	// deps.resolve_latest - Latest stable and prerelease versions of a package
+	tr.addTool(srv,
 		&mcp.Tool{
-			Name:        "deps.health",
-			Description: "Query deps.dev for package health metrics including maintenance score, update frequency, and recommendations. Supports npm, pypi, Go, and other ecosystems.",
+			Name:        "deps.resolve_latest",
+			Description: "Resolve the latest stable version of a package, and the latest including prereleases, from deps.dev (Packagist for Composer packages). `resolved` is the latest under the include_prerelease and include_yanked flags, both off by default. A cheap building block when the full deps.health output is not needed.",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
 					"ecosystem": map[string]interface{}{
 						"type":        "string",
-						"description": "Package ecosystem (npm, pypi, go, maven, cargo, nuget)",
+						"description": "Package ecosystem (npm, pypi, go, maven, cargo, nuget, rubygems, packagist)",
 					},
 					"package": map[string]interface{}{
 						"type":        "string",
-						"description": "Package name (e.g., 'express' for npm, 'requests' for pypi)",
+						"description": "Package name (e.g., 'lodash' for npm, 'requests' for pypi)",
 					},
-				},
-				"required": []string{"ecosystem", "package"},
-			},
-		},
-		func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			return tr.HandleHealth(ctx, req)
-		},
-	)
-	srv.IncrementToolCount()
-
-	// license.info - SPDX license information tool
-	mcpServer.AddTool(
-		&mcp.Tool{
-			Name:        "license.info",
-			Description: "Query SPDX license database for detailed license information including OSI approval status, compatibility, and category. Supports all standard SPDX license identifiers.",
-			InputSchema: map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"license_id": map[string]interface{}{
-						"type":        "string",
-						"description": "SPDX license identifier (e.g., 'MIT', 'Apache-2.0', 'GPL-3.0')",
+					"include_prerelease": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Resolve to a prerelease when it is the newest version, for users on a prerelease channel",
+					},
+					"include_yanked": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Consider releases the registry has yanked or deprecated",
 					},
 				},
-				"required": []string{"license_id"},
+				"required": []string{"ecosystem", "package"},
 			},
 		},
-		func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			var params LicenseInput
+		withDeadline(tr.config.Timeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params ResolveLatestInput
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
 				return &mcp.CallToolResult{
 					Content: []mcp.Content{&mcp.TextContent{
@@ -234,37 +1875,51 @@ func (tr *ToolRegistry) Register(srv *hypermcp.Server) error {
 				}, nil
 			}
 
-			return tr.HandleLicense(ctx, params)
-		},
+			result, err := tr.HandleResolveLatest(ctx, params)
+			if err != nil {
+				return errorResult(err), nil
+			}
+
+			data, _ := json.MarshalIndent(result, "", "  ")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: string(data),
+				}},
+			}, nil
+		}),
 	)
-	srv.IncrementToolCount()
 
-	// deps.upgrade_plan - Smart upgrade recommendations tool
-	mcpServer.AddTool(
+	// deps.upgrade_all - Upgrade recommendations for a whole manifest
+	tr.addTool(srv,
 		&mcp.Tool{
-			Name:        "deps.upgrade_plan",
-			Description: "Generate smart upgrade recommendations by analyzing vulnerabilities, package health, and maintenance status. Provides priority-based upgrade advice and checks for potential breaking changes.",
+			Name:        "deps.upgrade_all",
+			Description: "Parse a dependency manifest or lockfile and generate upgrade plans for every dependency, ordered urgent first. Returns counts of urgent, recommended, and up-to-date packages. Supported files: " + strings.Join(manifest.SupportedFormats(), ", ") + ".",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"ecosystem": map[string]interface{}{
+					"filename": map[string]interface{}{
 						"type":        "string",
-						"description": "Package ecosystem (npm, pypi, go, maven, cargo, nuget)",
+						"description": "Manifest filename, used to detect the format (e.g., 'Cargo.lock')",
 					},
-					"package": map[string]interface{}{
+					"content": map[string]interface{}{
 						"type":        "string",
-						"description": "Package name (e.g., 'lodash' for npm, 'requests' for pypi)",
+						"description": "Full text content of the manifest",
 					},
-					"current_version": map[string]interface{}{
-						"type":        "string",
-						"description": "Current version in use (e.g., '4.17.19')",
+					"runtime_only": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Skip test/provided scoped (pom.xml) and packages-dev (composer.lock) dependencies",
+					},
+					"top_n": map[string]interface{}{
+						"type":        "integer",
+						"description": "Return only the N most pressing upgrades, by priority and then risk score (optional). Counts still cover every dependency",
+						"minimum":     0,
 					},
 				},
-				"required": []string{"ecosystem", "package", "current_version"},
+				"required": []string{"filename", "content"},
 			},
 		},
-		func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			var params UpgradePlanInput
+		withDeadline(tr.config.BatchTimeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params UpgradeAllInput
 			if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
 				return &mcp.CallToolResult{
 					Content: []mcp.Content{&mcp.TextContent{
@@ -274,17 +1929,127 @@ func (tr *ToolRegistry) Register(srv *hypermcp.Server) error {
 				}, nil
 			}
 
-			return tr.HandleUpgradePlan(ctx, params)
+			result, err := tr.HandleUpgradeAll(ctx, params)
+			if err != nil {
+				return errorResult(err), nil
+			}
+
+			data, _ := json.MarshalIndent(result, "", "  ")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: string(data),
+				}},
+			}, nil
+		}),
+	)
+
+	// license.reload - Admin tool to refresh the SPDX license list without a restart
+	if tr.config.EnableLicenseReload {
+		tr.addTool(srv,
+			&mcp.Tool{
+				Name:        "license.reload",
+				Description: "Admin: fetch the latest SPDX license list and swap it into the license database without restarting. Curated categories and compatibility notes are kept.",
+				InputSchema: map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{},
+				},
+			},
+			withDeadline(tr.config.Timeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				result, err := tr.spdxClient.Reload(ctx)
+				if err != nil {
+					return &mcp.CallToolResult{
+						Content: []mcp.Content{&mcp.TextContent{
+							Text: err.Error(),
+						}},
+						IsError: true,
+					}, nil
+				}
+
+				data, _ := json.MarshalIndent(result, "", "  ")
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: string(data),
+					}},
+				}, nil
+			}),
+		)
+	}
+
+	// meta.tools - Self-describing tool catalog, read back from the server's registry
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "meta.tools",
+			Description: "List every tool this server exposes with its description and input schema.",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
 		},
+		withDeadline(tr.config.Timeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := HandleMetaTools(ctx, mcpServer)
+			if err != nil {
+				return errorResult(err), nil
+			}
+
+			data, _ := json.MarshalIndent(result, "", "  ")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: string(data),
+				}},
+			}, nil
+		}),
+	)
+
+	// meta.stats - Success and error rates per ecosystem and upstream
+	tr.addTool(srv,
+		&mcp.Tool{
+			Name:        "meta.stats",
+			Description: "Report tool-call success and error counts per ecosystem, and request counts with p50/p95 latency per upstream API (osv, deps.dev, ghsa, epss, kev, packagist, spdx), since startup or the last reset.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"reset": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Clear every count after reporting it",
+					},
+				},
+			},
+		},
+		withDeadline(tr.config.Timeout, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var params MetaStatsInput
+			if len(req.Params.Arguments) > 0 {
+				if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+					return &mcp.CallToolResult{
+						Content: []mcp.Content{&mcp.TextContent{
+							Text: fmt.Sprintf("Invalid input: %v", err),
+						}},
+						IsError: true,
+					}, nil
+				}
+			}
+
+			data, _ := json.MarshalIndent(tr.HandleMetaStats(params), "", "  ")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: string(data),
+				}},
+			}, nil
+		}),
 	)
-	srv.IncrementToolCount()
 
 	return nil
 }
 
+// HealthInput defines input for deps.health tool
+type HealthInput struct {
+	VulnsInput // Reuse same input structure (ecosystem, package, version optional)
+	// Explain adds the points each signal contributed to the maintenance score
+	Explain bool `json:"explain,omitempty"`
+}
+
 // HandleHealth implements the deps.health tool
 func (tr *ToolRegistry) HandleHealth(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	var input VulnsInput // Reuse same input structure (ecosystem, package, version optional)
+	var input HealthInput
 	if err := json.Unmarshal(req.Params.Arguments, &input); err != nil {
 		return &mcp.CallToolResult{
 			IsError: true,
@@ -292,35 +2057,26 @@ func (tr *ToolRegistry) HandleHealth(ctx context.Context, req *mcp.CallToolReque
 		}, nil
 	}
 
-	// Check cache first
-	cacheKey := fmt.Sprintf("health:%s:%s", input.Ecosystem, input.Package)
-	if cached, ok := tr.cache.Get(cacheKey); ok {
-		tr.logger.Debug("cache hit", zap.String("key", cacheKey))
-		if healthMetrics, ok := cached.(*depsdev.HealthMetrics); ok {
-			output, _ := json.MarshalIndent(healthMetrics, "", "  ")
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{&mcp.TextContent{Text: string(output)}},
-			}, nil
-		}
+	query, err := tr.resolveQuery(ctx, input.Ecosystem, input.Package, input.Version)
+	if err != nil {
+		return errorResult(err), nil
 	}
-
-	// Query deps.dev API
-	pkgInfo, err := tr.depsDevClient.GetPackage(ctx, input.Ecosystem, input.Package)
+	healthMetrics, status, err := tr.cachedPackageHealth(ctx, query.Ecosystem, query.Package, query.Version)
 	if err != nil {
-		return &mcp.CallToolResult{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to query deps.dev: %v", err)}},
-		}, nil
+		return errorResult(err), nil
+	}
+	result := HealthOutput{
+		HealthMetrics:         healthMetrics,
+		SuggestedAlternatives: tr.suggestAlternatives(query.Ecosystem, query.Package, healthMetrics.MaintenanceLevel),
+		ResolvedQuery:         query,
+		CacheStatus:           status,
+	}
+	if input.Explain {
+		result.ScoreBreakdown = depsdev.ScoreBreakdown(healthMetrics, tr.config.HealthScoring)
 	}
-
-	// Compute health metrics
-	healthMetrics := depsdev.ComputeHealthMetrics(pkgInfo)
-
-	// Cache the result
-	tr.cache.Set(cacheKey, healthMetrics, 5*time.Minute)
 
 	// Return formatted output
-	output, err := json.MarshalIndent(healthMetrics, "", "  ")
+	output, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
 		return &mcp.CallToolResult{
 			IsError: true,
@@ -333,14 +2089,77 @@ func (tr *ToolRegistry) HandleHealth(ctx context.Context, req *mcp.CallToolReque
 	}, nil
 }
 
+// healthCacheTTL is how long package health, and other release metadata, is cached
+const healthCacheTTL = 5 * time.Minute
+
+// packageHealth computes (and caches) health metrics for a package, scoped to a version when given
+func (tr *ToolRegistry) packageHealth(ctx context.Context, ecosystem, name, version string) (*depsdev.HealthMetrics, error) {
+	metrics, _, err := tr.cachedPackageHealth(ctx, ecosystem, name, version)
+	return metrics, err
+}
+
+// cachedPackageHealth is packageHealth also reporting whether the metrics came from the cache
+func (tr *ToolRegistry) cachedPackageHealth(ctx context.Context, ecosystem, name, version string) (*depsdev.HealthMetrics, CacheStatus, error) {
+	query, err := tr.resolveQuery(ctx, ecosystem, name, version)
+	if err != nil {
+		return nil, CacheStatus{}, err
+	}
+	ecosystem, name, version = query.Ecosystem, query.Package, query.Version
+
+	// Check cache first
+	cacheKey := cacheKey("health", ecosystem, name, version)
+	if !cacheRefreshing(ctx) {
+		if cached, status, ok := tr.getCachedResponse(cacheKey); ok {
+			tr.log(ctx).Debug("cache hit", zap.String("key", cacheKey))
+			if healthMetrics, ok := cached.(*depsdev.HealthMetrics); ok {
+				history.NoteCache(ctx, true)
+				return healthMetrics, status, nil
+			}
+		}
+	}
+	history.NoteCache(ctx, false)
+
+	// Concurrent lookups of the same package share one upstream request
+	v, _, err := tr.shareFlight(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		// Query deps.dev API (or Packagist for Composer packages)
+		pkgInfo, err := tr.getPackageInfo(ctx, ecosystem, name)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to query deps.dev: %w", tr.explainNotFound(ctx, ecosystem, name, err))
+		}
+
+		// Compute health metrics, scoped to the requested version when given
+		healthMetrics := depsdev.ComputeHealthMetricsWithScoring(pkgInfo, tr.config.HealthScoring)
+		if version != "" {
+			healthMetrics, err = depsdev.ComputeVersionHealthMetricsWithScoring(pkgInfo, version, tr.config.HealthScoring)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Cache the result
+		tr.setCachedResponse(cacheKey, healthMetrics, cacheTTL(ctx, healthCacheTTL))
+		return healthMetrics, nil
+	})
+	if err != nil {
+		return nil, CacheStatus{}, err
+	}
+	return v.(*depsdev.HealthMetrics), CacheStatus{}, nil
+}
+
 // LicenseInput defines input for license.info tool
 type LicenseInput struct {
 	LicenseID string `json:"license_id"`
 }
 
+// LicenseInfoOutput is the license.info result
+type LicenseInfoOutput struct {
+	*spdx.LicenseInfo
+	CacheStatus
+}
+
 // HandleLicense retrieves information about a specific SPDX license
 func (tr *ToolRegistry) HandleLicense(ctx context.Context, input LicenseInput) (*mcp.CallToolResult, error) {
-	tr.logger.Info("Handling license query", zap.String("license_id", input.LicenseID))
+	tr.log(ctx).Info("Handling license query", zap.String("license_id", input.LicenseID))
 
 	// Validate input
 	if input.LicenseID == "" {
@@ -351,17 +2170,21 @@ func (tr *ToolRegistry) HandleLicense(ctx context.Context, input LicenseInput) (
 	}
 
 	// Check cache first
-	cacheKey := fmt.Sprintf("license:%s", input.LicenseID)
-	if cached, ok := tr.cache.Get(cacheKey); ok {
-		tr.logger.Debug("cache hit", zap.String("key", cacheKey))
+	// SPDX IDs match case-insensitively
+	cacheKey := cacheKey("license", "", strings.ToUpper(input.LicenseID))
+	if cached, status, ok := tr.getCachedResponse(cacheKey); ok {
+		tr.log(ctx).Debug("cache hit", zap.String("key", cacheKey))
 		if licenseInfo, ok := cached.(*spdx.LicenseInfo); ok {
-			output, _ := json.MarshalIndent(licenseInfo, "", "  ")
+			history.NoteCache(ctx, true)
+			output, _ := json.MarshalIndent(LicenseInfoOutput{LicenseInfo: licenseInfo, CacheStatus: status}, "", "  ")
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: string(output)}},
 			}, nil
 		}
 	}
 
+	history.NoteCache(ctx, false)
+
 	// Query SPDX database
 	licenseInfo, err := tr.spdxClient.GetLicense(ctx, input.LicenseID)
 	if err != nil {
@@ -372,7 +2195,7 @@ func (tr *ToolRegistry) HandleLicense(ctx context.Context, input LicenseInput) (
 	}
 
 	// Cache the result (licenses don't change, so longer TTL)
-	tr.cache.Set(cacheKey, licenseInfo, 24*time.Hour)
+	tr.setCachedResponse(cacheKey, licenseInfo, 24*time.Hour)
 
 	// Return formatted output
 	output, err := json.MarshalIndent(licenseInfo, "", "  ")
@@ -393,103 +2216,291 @@ type UpgradePlanInput struct {
 	Ecosystem      string `json:"ecosystem"`
 	Package        string `json:"package"`
 	CurrentVersion string `json:"current_version"`
+	// IncludePrerelease targets the newest prerelease when it is newer than the default release
+	IncludePrerelease bool `json:"include_prerelease,omitempty"`
+	// IncludeYanked lets the plan target a release the registry has yanked or deprecated
+	IncludeYanked bool `json:"include_yanked,omitempty"`
 }
 
 // UpgradePlanOutput contains upgrade recommendations
 type UpgradePlanOutput struct {
-	Package              string       `json:"package"`
-	Ecosystem            string       `json:"ecosystem"`
-	CurrentVersion       string       `json:"current_version"`
-	LatestVersion        string       `json:"latest_version"`
-	IsUpToDate           bool         `json:"is_up_to_date"`
-	HasVulnerabilities   bool         `json:"has_vulnerabilities"`
-	VulnerabilityCount   int          `json:"vulnerability_count"`
-	MaintenanceLevel     string       `json:"maintenance_level"`
-	MaintenanceScore     float64      `json:"maintenance_score"`
-	DaysSinceUpdate      int          `json:"days_since_update"`
-	Priority             string       `json:"priority"`
-	Recommendation       string       `json:"recommendation"`
-	UpgradePath          []string     `json:"upgrade_path"`
-	BreakingChanges      bool         `json:"breaking_changes_possible"`
-	VulnerabilitySummary *VulnSummary `json:"vulnerability_summary,omitempty"`
-}
-
-// HandleUpgradePlan generates smart upgrade recommendations
+	Package            string   `json:"package"`
+	Ecosystem          string   `json:"ecosystem"`
+	CurrentVersion     string   `json:"current_version"`
+	LatestVersion      string   `json:"latest_version"`
+	IsUpToDate         bool     `json:"is_up_to_date"`
+	HasVulnerabilities bool     `json:"has_vulnerabilities"`
+	VulnerabilityCount int      `json:"vulnerability_count"`
+	MaintenanceLevel   string   `json:"maintenance_level"`
+	MaintenanceScore   float64  `json:"maintenance_score"`
+	DaysSinceUpdate    int      `json:"days_since_update"`
+	Priority           string   `json:"priority"`
+	Recommendation     string   `json:"recommendation"`
+	UpgradePath        []string `json:"upgrade_path"`
+	BreakingChanges    bool     `json:"breaking_changes_possible"`
+	// Factors lists the inputs that drove Priority, most significant first
+	Factors []UpgradeFactor `json:"factors"`
+	// Set when the latest version is a prerelease; the plan then targets LatestStableVersion
+	// unless the current version is itself a prerelease
+	LatestIsPrerelease  bool   `json:"latest_is_prerelease,omitempty"`
+	LatestStableVersion string `json:"latest_stable_version,omitempty"`
+	// PseudoVersion is set when the current version is a Go pseudo-version of an untagged commit
+	PseudoVersion *depsdev.PseudoVersion `json:"pseudo_version,omitempty"`
+	// SuggestedAlternatives names curated replacements when maintenance is poor or critical
+	SuggestedAlternatives []string       `json:"suggested_alternatives,omitempty"`
+	VulnerabilitySummary  *VulnSummary   `json:"vulnerability_summary,omitempty"`
+	KnownExploited        []string       `json:"known_exploited,omitempty"`
+	ResolvedQuery         *ResolvedQuery `json:"resolved_query,omitempty"`
+	// RiskScore is the highest risk score among the current version's findings, blending CVSS,
+	// EPSS, and KEV the same way as deps.vulns; 0 without findings
+	RiskScore float64 `json:"risk_score"`
+	// Licenses are those declared by the version the plan upgrades to, flagging a choice (OR)
+	// or licenses that all apply (AND)
+	Licenses *DeclaredLicenses `json:"licenses,omitempty"`
+	DataSources
+	CacheStatus
+}
+
+// Factors an upgrade plan's priority can rest on
+const (
+	FactorKnownExploited        = "known_exploited"
+	FactorVulnDataUnavailable   = "vulnerability_data_unavailable"
+	FactorCriticalVulnerability = "critical_vulnerability"
+	FactorHighVulnerability     = "high_vulnerability"
+	FactorVulnerability         = "vulnerability"
+	FactorLatestUnknown         = "latest_version_unknown"
+	FactorUpToDate              = "up_to_date"
+	FactorPseudoVersion         = "pseudo_version"
+	FactorPoorMaintenance       = "poor_maintenance"
+	FactorCriticalMaintenance   = "critical_maintenance"
+	FactorStale                 = "stale_180_days"
+	FactorBreakingChanges       = "breaking_changes"
+	FactorUpgradeAvailable      = "upgrade_available"
+)
+
+// Weights of upgrade factors
+const (
+	WeightHigh   = "high"
+	WeightMedium = "medium"
+	WeightLow    = "low"
+)
+
+// UpgradeFactor is one input behind an upgrade plan's priority, so clients can render or
+// re-rank plans without parsing the recommendation
+type UpgradeFactor struct {
+	Factor string `json:"factor"`
+	Weight string `json:"weight"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// maintenanceFactor returns the factor for poor or critical maintenance, and false otherwise
+func maintenanceFactor(metrics *depsdev.HealthMetrics) (UpgradeFactor, bool) {
+	detail := fmt.Sprintf("maintenance score %.1f", metrics.MaintenanceScore)
+	switch metrics.MaintenanceLevel {
+	case "poor":
+		return UpgradeFactor{Factor: FactorPoorMaintenance, Weight: WeightMedium, Detail: detail}, true
+	case "critical":
+		return UpgradeFactor{Factor: FactorCriticalMaintenance, Weight: WeightHigh, Detail: detail}, true
+	}
+	return UpgradeFactor{}, false
+}
+
+// yankedVersion reports whether the registry has yanked or deprecated a version of a package
+func yankedVersion(pkg *depsdev.PackageInfo, version string) bool {
+	for _, v := range pkg.Versions {
+		if v.VersionKey.Version == version {
+			return v.IsDeprecated
+		}
+	}
+	return false
+}
+
+// HandleUpgradePlan generates smart upgrade recommendations. Prereleases and yanked releases
+// are only targeted with include_prerelease and include_yanked.
 func (tr *ToolRegistry) HandleUpgradePlan(ctx context.Context, input UpgradePlanInput) (*mcp.CallToolResult, error) {
-	tr.logger.Info("Handling upgrade plan request",
+	tr.log(ctx).Info("Handling upgrade plan request",
 		zap.String("ecosystem", input.Ecosystem),
 		zap.String("package", input.Package),
 		zap.String("current_version", input.CurrentVersion))
 
-	// Validate input
-	if input.Ecosystem == "" || input.Package == "" || input.CurrentVersion == "" {
+	plan, err := tr.planUpgrade(ctx, input)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	// Return formatted output
+	output, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
 		return &mcp.CallToolResult{
 			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: "ecosystem, package, and current_version are required"}},
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to format output: %v", err)}},
 		}, nil
 	}
 
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(output)}},
+	}, nil
+}
+
+// planUpgrade analyzes a single package version and builds its upgrade plan
+func (tr *ToolRegistry) planUpgrade(ctx context.Context, input UpgradePlanInput) (*UpgradePlanOutput, error) {
+	// Validate input
+	if input.Ecosystem == "" || input.Package == "" || input.CurrentVersion == "" {
+		return nil, fmt.Errorf("ecosystem, package, and current_version are required")
+	}
+	query, err := tr.resolveQuery(ctx, input.Ecosystem, input.Package, input.CurrentVersion)
+	if err != nil {
+		return nil, err
+	}
+	input.Ecosystem, input.Package, input.CurrentVersion = query.Ecosystem, query.Package, query.Version
+
 	// Check cache first
-	cacheKey := fmt.Sprintf("upgrade:%s:%s:%s", input.Ecosystem, input.Package, input.CurrentVersion)
-	if cached, ok := tr.cache.Get(cacheKey); ok {
-		tr.logger.Debug("cache hit", zap.String("key", cacheKey))
+	cacheKey := cacheKey("upgrade", input.Ecosystem, input.Package, input.CurrentVersion, strconv.FormatBool(input.IncludePrerelease), strconv.FormatBool(input.IncludeYanked))
+	if cached, status, ok := tr.getCachedResponse(cacheKey); ok {
+		tr.log(ctx).Debug("cache hit", zap.String("key", cacheKey))
 		if plan, ok := cached.(*UpgradePlanOutput); ok {
-			output, _ := json.MarshalIndent(plan, "", "  ")
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{&mcp.TextContent{Text: string(output)}},
-			}, nil
+			history.NoteCache(ctx, true)
+			hit := *plan
+			hit.CacheStatus = status
+			return &hit, nil
 		}
 	}
+	history.NoteCache(ctx, false)
 
 	// Step 1: Check for vulnerabilities in current version
-	tr.logger.Debug("Checking vulnerabilities", zap.String("version", input.CurrentVersion))
+	tr.log(ctx).Debug("Checking vulnerabilities", zap.String("version", input.CurrentVersion))
+	var sources DataSources
 	vulnResp, err := tr.osvClient.Query(ctx, input.Ecosystem, input.Package, input.CurrentVersion)
 	if err != nil {
-		tr.logger.Warn("Failed to query vulnerabilities", zap.Error(err))
+		tr.log(ctx).Warn("Failed to query vulnerabilities", zap.Error(err))
 	}
+	sources.record(SourceOSV, err)
+	vulnsUnknown := err != nil
 
 	hasVulns := vulnResp != nil && len(vulnResp.Vulns) > 0
 	vulnCount := 0
 	var vulnSummary *VulnSummary
+	var knownExploited []string
+	var riskScore float64
 	if hasVulns {
 		vulnCount = len(vulnResp.Vulns)
 		summary := computeVulnSummary(vulnResp.Vulns)
 		vulnSummary = &summary
+		findings := newFindings(vulnResp.Vulns)
+		sources.record(SourceEPSS, tr.enrichEPSS(ctx, findings))
+		var kevErr error
+		knownExploited, kevErr = tr.markKnownExploited(ctx, findings)
+		sources.record(SourceKEV, kevErr)
+		scoreFindings(findings, tr.config.RiskWeights)
+		for _, f := range findings {
+			riskScore = max(riskScore, f.RiskScore)
+		}
 	}
 
 	// Step 2: Get package health and latest version
-	tr.logger.Debug("Fetching package health")
-	pkgInfo, err := tr.depsDevClient.GetPackage(ctx, input.Ecosystem, input.Package)
+	tr.log(ctx).Debug("Fetching package health")
+	pkgInfo, err := tr.getPackageInfo(ctx, input.Ecosystem, input.Package)
 	if err != nil {
-		return &mcp.CallToolResult{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to query package info: %v", err)}},
-		}, nil
+		return nil, fmt.Errorf("Failed to query package info: %w", tr.explainNotFound(ctx, input.Ecosystem, input.Package, err))
+	}
+
+	healthMetrics := depsdev.ComputeHealthMetricsWithScoring(pkgInfo, tr.config.HealthScoring)
+
+	// The latest release is the registry's default, unless the flags widen it to a newer
+	// prerelease or yanked release; a yanked default otherwise gives way to the newest release
+	// still available
+	scheme := depsdev.SchemeFor(input.Ecosystem)
+	latest := healthMetrics.LatestVersion
+	if input.IncludePrerelease || input.IncludeYanked {
+		if newest := depsdev.LatestMatching(pkgInfo, input.IncludePrerelease, input.IncludeYanked); newest != "" && (latest == "" || scheme.Compare(newest, latest) > 0) {
+			latest = newest
+		}
+	}
+	if !input.IncludeYanked && yankedVersion(pkgInfo, latest) {
+		latest = depsdev.LatestMatching(pkgInfo, scheme.IsPrerelease(latest), false)
+	}
+	latestIsPrerelease := latest != "" && scheme.IsPrerelease(latest)
+	latestStable := ""
+	if latestIsPrerelease {
+		latestStable = depsdev.LatestMatching(pkgInfo, false, input.IncludeYanked)
+	}
+
+	// A prerelease is only recommended to users already on a prerelease or asking for one; a
+	// Go pseudo-version tracks a commit, not a prerelease
+	latestVersion := latest
+	onPrerelease := scheme.IsPrerelease(input.CurrentVersion) && !depsdev.IsPseudoVersion(input.CurrentVersion)
+	skipPrerelease := latestIsPrerelease && latestStable != "" && !onPrerelease && !input.IncludePrerelease
+	if skipPrerelease {
+		latestVersion = latestStable
+	}
+
+	// deps.dev may list no versions (or no default one); the plan still reports vulnerabilities
+	latestKnown := latestVersion != ""
+	upgradeTarget := latestVersion
+	upgradePath := []string{input.CurrentVersion, latestVersion}
+	if !latestKnown {
+		upgradeTarget = "a patched release"
+		upgradePath = []string{input.CurrentVersion}
 	}
 
-	healthMetrics := depsdev.ComputeHealthMetrics(pkgInfo)
+	// A pseudo-version at or past the latest tag tracks an untagged commit newer than any release
+	pseudo, isPseudo := depsdev.ParsePseudoVersion(input.CurrentVersion)
+	upToDate := latestKnown && (input.CurrentVersion == latestVersion ||
+		(isPseudo && scheme.Compare(input.CurrentVersion, latestVersion) >= 0))
 
 	// Step 3: Analyze and generate recommendations
 	plan := &UpgradePlanOutput{
 		Package:              input.Package,
 		Ecosystem:            input.Ecosystem,
 		CurrentVersion:       input.CurrentVersion,
-		LatestVersion:        healthMetrics.LatestVersion,
-		IsUpToDate:           input.CurrentVersion == healthMetrics.LatestVersion,
+		LatestVersion:        latest,
+		LatestIsPrerelease:   latestIsPrerelease,
+		LatestStableVersion:  latestStable,
+		IsUpToDate:           upToDate,
 		HasVulnerabilities:   hasVulns,
 		VulnerabilityCount:   vulnCount,
 		MaintenanceLevel:     healthMetrics.MaintenanceLevel,
 		MaintenanceScore:     healthMetrics.MaintenanceScore,
 		DaysSinceUpdate:      healthMetrics.DaysSinceUpdate,
 		VulnerabilitySummary: vulnSummary,
-		UpgradePath:          []string{input.CurrentVersion, healthMetrics.LatestVersion},
+		KnownExploited:       knownExploited,
+		RiskScore:            riskScore,
+		UpgradePath:          upgradePath,
+		ResolvedQuery:        query,
+		DataSources:          sources,
 	}
+	if isPseudo {
+		plan.PseudoVersion = pseudo
+	}
+	for _, v := range pkgInfo.Versions {
+		if latestKnown && v.VersionKey.Version == latestVersion {
+			plan.Licenses = tr.declaredLicenses(ctx, v.Licenses)
+			break
+		}
+	}
+	plan.SuggestedAlternatives = tr.suggestAlternatives(input.Ecosystem, input.Package, healthMetrics.MaintenanceLevel)
 
 	// Check for potential breaking changes (simplified semver check)
-	plan.BreakingChanges = checkBreakingChanges(input.CurrentVersion, healthMetrics.LatestVersion)
+	plan.BreakingChanges = checkBreakingChanges(input.CurrentVersion, latestVersion)
 
-	// Determine priority and recommendation
-	if hasVulns {
+	// Determine priority and recommendation, and the factors behind them
+	maintenance, poorlyMaintained := maintenanceFactor(healthMetrics)
+	if len(knownExploited) > 0 {
+		// URGENT regardless of CVSS: exploitation is happening in the wild
+		plan.Priority = "URGENT"
+		plan.Recommendation = fmt.Sprintf("CRITICAL: Upgrade to %s immediately! %d vulnerabilities in current version are known to be actively exploited (CISA KEV): %s.",
+			upgradeTarget, len(knownExploited), strings.Join(knownExploited, ", "))
+		plan.Factors = []UpgradeFactor{{Factor: FactorKnownExploited, Weight: WeightHigh, Detail: strings.Join(knownExploited, ", ")}}
+	} else if vulnsUnknown {
+		// Without vulnerability data a clean result would be a guess, not a finding
+		plan.Priority = "UNKNOWN"
+		plan.Recommendation = fmt.Sprintf("Vulnerability data unavailable (OSV query failed), so %s could not be confirmed free of known vulnerabilities. Retry before relying on this plan.",
+			input.CurrentVersion)
+		if latestKnown {
+			plan.Recommendation += fmt.Sprintf(" Latest version is %s.", latestVersion)
+		}
+		plan.Factors = []UpgradeFactor{{Factor: FactorVulnDataUnavailable, Weight: WeightHigh}}
+	} else if hasVulns {
 		// URGENT: Security vulnerabilities present
 		plan.Priority = "URGENT"
 		criticalCount := 0
@@ -503,99 +2514,155 @@ func (tr *ToolRegistry) HandleUpgradePlan(ctx context.Context, input UpgradePlan
 			plan.Recommendation = fmt.Sprintf("CRITICAL: Upgrade immediately! Found %d critical vulnerabilities in current version.", criticalCount)
 		} else if highCount > 0 {
 			plan.Recommendation = fmt.Sprintf("URGENT: Upgrade to %s to address %d high-severity vulnerabilities.",
-				healthMetrics.LatestVersion, highCount)
+				upgradeTarget, highCount)
 		} else {
 			plan.Recommendation = fmt.Sprintf("URGENT: Upgrade to %s to address %d known vulnerabilities.",
-				healthMetrics.LatestVersion, vulnCount)
+				upgradeTarget, vulnCount)
+		}
+		if criticalCount > 0 {
+			plan.Factors = append(plan.Factors, UpgradeFactor{Factor: FactorCriticalVulnerability, Weight: WeightHigh, Detail: fmt.Sprintf("%d critical", criticalCount)})
+		}
+		if highCount > 0 {
+			plan.Factors = append(plan.Factors, UpgradeFactor{Factor: FactorHighVulnerability, Weight: WeightHigh, Detail: fmt.Sprintf("%d high", highCount)})
+		}
+		if other := vulnCount - criticalCount - highCount; other > 0 {
+			plan.Factors = append(plan.Factors, UpgradeFactor{Factor: FactorVulnerability, Weight: WeightMedium, Detail: fmt.Sprintf("%d other", other)})
+		}
+	} else if !latestKnown {
+		// Nothing to compare against, so neither "up to date" nor "upgrade available" holds
+		plan.Priority = "UNKNOWN"
+		plan.Recommendation = fmt.Sprintf("Cannot determine the latest version of %s: deps.dev lists no current release. No known vulnerabilities affect %s.",
+			input.Package, input.CurrentVersion)
+		plan.Factors = []UpgradeFactor{{Factor: FactorLatestUnknown, Weight: WeightMedium}}
+	} else if plan.IsUpToDate && isPseudo {
+		plan.Priority = "OK"
+		plan.Recommendation = fmt.Sprintf("On an untagged commit (%s, committed %s) newer than the latest release %s. Pin to a tagged release once one includes this commit.",
+			pseudo.Commit, pseudo.Time.Format("2006-01-02"), latestVersion)
+		plan.Factors = []UpgradeFactor{
+			{Factor: FactorUpToDate, Weight: WeightLow},
+			{Factor: FactorPseudoVersion, Weight: WeightLow, Detail: pseudo.Commit},
 		}
 	} else if plan.IsUpToDate {
 		// Already on latest version
 		plan.Priority = "OK"
-		if healthMetrics.MaintenanceLevel == "poor" || healthMetrics.MaintenanceLevel == "critical" {
+		plan.Factors = []UpgradeFactor{{Factor: FactorUpToDate, Weight: WeightLow}}
+		if poorlyMaintained {
 			plan.Recommendation = fmt.Sprintf("On latest version, but package shows %s maintenance. Consider alternatives.",
 				healthMetrics.MaintenanceLevel)
+			plan.Factors = append(plan.Factors, maintenance)
 		} else {
 			plan.Recommendation = "Already on latest version. No action needed."
 		}
 	} else {
 		// Not on latest, no vulnerabilities
-		if healthMetrics.MaintenanceLevel == "poor" || healthMetrics.MaintenanceLevel == "critical" {
+		available := UpgradeFactor{Factor: FactorUpgradeAvailable, Weight: WeightLow, Detail: latestVersion}
+		if poorlyMaintained {
 			plan.Priority = "WARNING"
 			plan.Recommendation = fmt.Sprintf("WARNING: Package shows %s maintenance (score: %.1f). Upgrade to %s available, but consider package alternatives.",
-				healthMetrics.MaintenanceLevel, healthMetrics.MaintenanceScore, healthMetrics.LatestVersion)
+				healthMetrics.MaintenanceLevel, healthMetrics.MaintenanceScore, latestVersion)
+			plan.Factors = []UpgradeFactor{maintenance, available}
 		} else if healthMetrics.DaysSinceUpdate > 180 {
 			plan.Priority = "LOW"
 			plan.Recommendation = fmt.Sprintf("Upgrade available (%s), but no urgent issues. Current version is %d days old.",
-				healthMetrics.LatestVersion, healthMetrics.DaysSinceUpdate)
+				latestVersion, healthMetrics.DaysSinceUpdate)
+			plan.Factors = []UpgradeFactor{
+				available,
+				{Factor: FactorStale, Weight: WeightLow, Detail: fmt.Sprintf("%d days since the last release", healthMetrics.DaysSinceUpdate)},
+			}
 		} else if plan.BreakingChanges {
 			plan.Priority = "MEDIUM"
 			plan.Recommendation = fmt.Sprintf("Upgrade to %s recommended, but may contain breaking changes. Review changelog before upgrading.",
-				healthMetrics.LatestVersion)
+				latestVersion)
+			plan.Factors = []UpgradeFactor{{Factor: FactorBreakingChanges, Weight: WeightMedium}, available}
 		} else {
 			plan.Priority = "RECOMMENDED"
 			plan.Recommendation = fmt.Sprintf("Upgrade to %s recommended for latest features and improvements.",
-				healthMetrics.LatestVersion)
+				latestVersion)
+			plan.Factors = []UpgradeFactor{available}
 		}
 	}
 
-	// Cache the result
-	tr.cache.Set(cacheKey, plan, 5*time.Minute)
+	if len(plan.SuggestedAlternatives) > 0 {
+		plan.Recommendation += fmt.Sprintf(" Suggested alternatives: %s.", strings.Join(plan.SuggestedAlternatives, ", "))
+	}
 
-	// Return formatted output
-	output, err := json.MarshalIndent(plan, "", "  ")
-	if err != nil {
-		return &mcp.CallToolResult{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to format output: %v", err)}},
-		}, nil
+	if isPseudo && !plan.IsUpToDate && latestKnown {
+		plan.Recommendation += fmt.Sprintf(" %s is a pseudo-version of an untagged commit from %s; prefer the tagged release %s.",
+			input.CurrentVersion, pseudo.Time.Format("2006-01-02"), latestVersion)
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{&mcp.TextContent{Text: string(output)}},
-	}, nil
+	if skipPrerelease {
+		plan.Recommendation += fmt.Sprintf(" The newest release %s is a prerelease, so the plan targets the latest stable release %s.",
+			healthMetrics.LatestVersion, latestVersion)
+	}
+
+	// Cache complete results so a degraded plan is rebuilt on the next call
+	if plan.DataComplete {
+		tr.setCachedResponse(cacheKey, plan, 5*time.Minute)
+	}
+
+	return plan, nil
 }
 
-// checkBreakingChanges performs a simplified semver check
+// checkBreakingChanges applies semver: an upgrade across a major version may break callers,
+// and so may a minor one while the major version is 0. Go pseudo-versions are measured from the
+// release numbers they carry, and +incompatible is ignored.
 func checkBreakingChanges(current, latest string) bool {
-	// Simple heuristic: if major version changes, assume breaking changes
-	// This is a simplified check - real semver parsing would be more robust
-	if len(current) == 0 || len(latest) == 0 {
+	if current == "" || latest == "" {
 		return false
 	}
-
-	// Extract first character (major version for simple cases like "1.2.3" vs "2.0.0")
-	if current[0] != latest[0] {
+	switch depsdev.ComputeVersionGap(current, latest).Level {
+	case depsdev.GapMajor:
 		return true
+	case depsdev.GapMinor:
+		return depsdev.MajorVersion(current) == 0
 	}
-
 	return false
 }
 
-// computeVulnSummary analyzes vulnerabilities and returns a severity summary
+// computeVulnSummary counts vulnerabilities by the rating of their computed base score
 func computeVulnSummary(vulns []osv.Vulnerability) VulnSummary {
 	summary := VulnSummary{}
 	for _, vuln := range vulns {
-		severity := "unknown"
-		if len(vuln.Severity) > 0 {
-			severity = vuln.Severity[0].Score
-		}
-
-		switch {
-		case containsIgnoreCase(severity, "critical"):
+		switch severityRating(vuln) {
+		case cvss.RatingCritical:
 			summary.Critical++
-		case containsIgnoreCase(severity, "high"):
+		case cvss.RatingHigh:
 			summary.High++
-		case containsIgnoreCase(severity, "medium"):
+		case cvss.RatingMedium:
 			summary.Medium++
-		case containsIgnoreCase(severity, "low"):
+		case cvss.RatingLow:
 			summary.Low++
 		default:
 			summary.Unknown++
 		}
+		for _, id := range vuln.CWEIDs() {
+			if summary.CWEs == nil {
+				summary.CWEs = make(map[string]int)
+			}
+			summary.CWEs[id]++
+		}
 	}
 	return summary
 }
 
+// classifySeverity maps a vulnerability's qualitative severity ratings to critical, high,
+// medium, low, or unknown, taking the highest rating across all severity entries. Entries
+// without one, common for GitHub-sourced advisories, fall back to the database_specific rating.
+func classifySeverity(vuln osv.Vulnerability) string {
+	for _, rating := range []string{"critical", "high", "medium", "low"} {
+		for _, sev := range vuln.Severity {
+			if containsIgnoreCase(sev.Score, rating) {
+				return rating
+			}
+		}
+	}
+	if rating := vuln.DatabaseSeverity(); rating != "" {
+		return rating
+	}
+	return "unknown"
+}
+
 // Helper function for case-insensitive substring matching
 func containsIgnoreCase(s, substr string) bool {
 	s = toLower(s)
@@ -3,13 +3,27 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/PackagePulse/internal/breaker"
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/epss"
+	"github.com/rayprogramming/PackagePulse/internal/providers/kev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"github.com/rayprogramming/PackagePulse/internal/reqlog"
 	"github.com/rayprogramming/hypermcp"
 	"github.com/rayprogramming/hypermcp/cache"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestVulnsHandler(t *testing.T) {
@@ -419,3 +433,503 @@ func TestUpgradePlanHandler(t *testing.T) {
 		})
 	}
 }
+
+// newTestRegistry builds a registry backed by a fresh cache for offline tests
+func newTestRegistry(t testing.TB) *ToolRegistry {
+	t.Helper()
+
+	logger := zap.NewNop()
+	srv, err := hypermcp.New(hypermcp.Config{
+		Name:         "test",
+		Version:      "1.0.0",
+		CacheEnabled: true,
+		CacheConfig: cache.Config{
+			MaxCost:     100 * 1024 * 1024,
+			NumCounters: 10000,
+			BufferItems: 64,
+		},
+	}, logger)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	registry, err := NewToolRegistry(logger, srv.Cache())
+	if err != nil {
+		t.Fatalf("failed to create registry: %v", err)
+	}
+	// Keep enrichment offline; tests that need scores or KEV entries swap in their own mocks
+	registry.epssClient = newMockEPSS(t)
+	registry.kevClient = newMockKEV(t)
+	registry.depsDevClient = newMockDepsDev(t, nil).client()
+	return registry
+}
+
+// resultText returns the text of the first content block of a tool result
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+
+	if result == nil || len(result.Content) == 0 {
+		t.Fatal("tool result has no content")
+	}
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatalf("expected *mcp.TextContent, got %T", result.Content[0])
+	}
+	return textContent.Text
+}
+
+// mockOSV is an httptest stand-in for the OSV API. Vulnerabilities are keyed
+// by "ecosystem/name@version", falling back to "ecosystem/name", or by the package URL of
+// a purl query. Like OSV, querybatch
+// answers with IDs only and full advisories are served from /vulns/{id}.
+type mockOSV struct {
+	*httptest.Server
+	vulns    map[string][]osv.Vulnerability
+	requests atomic.Int64
+	// details counts /vulns/{id} fetches
+	details atomic.Int64
+	// delay holds every response, so concurrent callers overlap; set it before the first request
+	delay time.Duration
+}
+
+func newMockOSV(t *testing.T, vulns map[string][]osv.Vulnerability) *mockOSV {
+	t.Helper()
+
+	m := &mockOSV{vulns: vulns}
+	m.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.requests.Add(1)
+		time.Sleep(m.delay)
+		switch r.URL.Path {
+		case osv.QueryPath:
+			var q osv.QueryRequest
+			if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(osv.QueryResponse{Vulns: m.lookup(q)})
+		case osv.BatchPath:
+			var body struct {
+				Queries []osv.QueryRequest `json:"queries"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			results := make([]osv.QueryResponse, len(body.Queries))
+			for i, q := range body.Queries {
+				for _, v := range m.lookup(q) {
+					results[i].Vulns = append(results[i].Vulns, osv.Vulnerability{ID: v.ID, Modified: v.Modified})
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+		default:
+			id, ok := strings.CutPrefix(r.URL.Path, osv.VulnsPath)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			m.details.Add(1)
+			for _, vulns := range m.vulns {
+				for _, v := range vulns {
+					if v.ID == id {
+						_ = json.NewEncoder(w).Encode(v)
+						return
+					}
+				}
+			}
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(m.Close)
+	return m
+}
+
+func (m *mockOSV) lookup(q osv.QueryRequest) []osv.Vulnerability {
+	if q.Package.PURL != "" {
+		return m.vulns[q.Package.PURL]
+	}
+	key := q.Package.Ecosystem + "/" + q.Package.Name
+	if v, ok := m.vulns[key+"@"+q.Version]; ok {
+		return v
+	}
+	return m.vulns[key]
+}
+
+// client returns an OSV client pointed at the mock server
+func (m *mockOSV) client() *osv.Client {
+	return osv.NewClient(zap.NewNop(), osv.WithBaseURL(m.URL))
+}
+
+// mockDepsDev is an httptest stand-in for the deps.dev API. Packages are keyed by "system/name".
+type mockDepsDev struct {
+	*httptest.Server
+	packages map[string]*depsdev.PackageInfo
+	// graphs holds dependency graphs keyed by "system/name@version"
+	graphs map[string]*depsdev.DependencyGraph
+	// advisories holds advisories keyed by ID
+	advisories map[string]*depsdev.Advisory
+	requests   atomic.Int64
+}
+
+func newMockDepsDev(t testing.TB, packages map[string]*depsdev.PackageInfo) *mockDepsDev {
+	t.Helper()
+
+	m := &mockDepsDev{packages: packages}
+	m.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.requests.Add(1)
+		if id, ok := strings.CutPrefix(r.URL.Path, "/advisories/"); ok {
+			advisory, ok := m.advisories[id]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(advisory)
+			return
+		}
+		rest, ok := strings.CutPrefix(r.URL.Path, "/systems/")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		system, name, ok := strings.Cut(rest, "/packages/")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if pkgName, version, ok := strings.Cut(name, "/versions/"); ok {
+			graph, ok := m.graphs[system+"/"+pkgName+"@"+strings.TrimSuffix(version, ":dependencies")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(graph)
+			return
+		}
+		pkg, ok := m.packages[system+"/"+name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(pkg)
+	}))
+	t.Cleanup(m.Close)
+	return m
+}
+
+// client returns a deps.dev client pointed at the mock server
+func (m *mockDepsDev) client() *depsdev.Client {
+	return depsdev.NewClient(zap.NewNop(), depsdev.WithBaseURL(m.URL))
+}
+
+// newMockEPSS returns an EPSS client backed by a test server that knows no scores
+func newMockEPSS(t testing.TB) *epss.Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": []interface{}{}})
+	}))
+	t.Cleanup(server.Close)
+
+	return epss.NewClient(zap.NewNop(), epss.WithBaseURL(server.URL))
+}
+
+// newMockKEV serves a KEV catalog containing the given CVEs and returns a client for it
+func newMockKEV(t testing.TB, cves ...string) *kev.Client {
+	t.Helper()
+
+	entries := make([]map[string]string, len(cves))
+	for i, cve := range cves {
+		entries[i] = map[string]string{"cveID": cve}
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"vulnerabilities": entries})
+	}))
+	t.Cleanup(server.Close)
+
+	return kev.NewClient(zap.NewNop(), kev.WithCatalogURL(server.URL))
+}
+
+func TestRequestID_SharedByToolAndProviderLogs(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	logger := zap.New(core)
+
+	srv, err := hypermcp.New(hypermcp.Config{
+		Name:         "test",
+		Version:      "1.0.0",
+		CacheEnabled: true,
+		CacheConfig:  cache.Config{MaxCost: 1 << 20, NumCounters: 1000, BufferItems: 64},
+	}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	registry, err := NewToolRegistry(logger, srv.Cache())
+	if err != nil {
+		t.Fatalf("failed to create registry: %v", err)
+	}
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{"npm/lodash": {}, "npm/express": {}})
+	registry.osvClient = osv.NewClient(logger, osv.WithBaseURL(mock.URL))
+	registry.epssClient = newMockEPSS(t)
+	registry.kevClient = newMockKEV(t)
+	if err := registry.Register(srv); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.MCP().Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+
+	// requestIDs returns the request_id of the tool's cache miss and OSV's query log lines
+	requestIDs := func() (tool, provider string) {
+		for _, entry := range logs.TakeAll() {
+			id, _ := entry.ContextMap()[reqlog.Field].(string)
+			switch entry.Message {
+			case "cache miss":
+				tool = id
+			case "querying OSV":
+				provider = id
+			}
+		}
+		return tool, provider
+	}
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Meta:      mcp.Meta{reqlog.MetaKey: "agent-step-7"},
+		Name:      "deps.vulns",
+		Arguments: map[string]any{"ecosystem": "npm", "package": "lodash"},
+	})
+	if err != nil || res.IsError {
+		t.Fatalf("CallTool(deps.vulns) = %+v, %v", res, err)
+	}
+	if tool, provider := requestIDs(); tool != "agent-step-7" || provider != "agent-step-7" {
+		t.Errorf("request IDs = tool %q, provider %q; want the client-supplied agent-step-7", tool, provider)
+	}
+
+	// Without one in _meta an ID is generated, still shared across the call
+	res, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "deps.vulns",
+		Arguments: map[string]any{"ecosystem": "npm", "package": "express"},
+	})
+	if err != nil || res.IsError {
+		t.Fatalf("CallTool(deps.vulns) = %+v, %v", res, err)
+	}
+	if tool, provider := requestIDs(); tool == "" || tool != provider {
+		t.Errorf("request IDs = tool %q, provider %q; want one generated ID", tool, provider)
+	}
+}
+
+func TestHandleVulns_SharesInFlightQueries(t *testing.T) {
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/lodash@4.17.19": {{ID: "GHSA-35jh-r3h4-6jhm"}},
+	})
+	mock.delay = 100 * time.Millisecond
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	const calls = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, calls)
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			output, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "npm", Package: "lodash", Version: "4.17.19"})
+			if err == nil && output.VulnerabilityCount != 1 {
+				err = fmt.Errorf("VulnerabilityCount = %d, want 1", output.VulnerabilityCount)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("HandleVulns() error = %v", err)
+		}
+	}
+	if got := mock.requests.Load(); got != 1 {
+		t.Errorf("OSV requests = %d, want 1 shared by all %d calls", got, calls)
+	}
+}
+
+func TestHandleVulns_CancelledLeaderDoesNotFailFollowers(t *testing.T) {
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/lodash@4.17.19": {{ID: "GHSA-35jh-r3h4-6jhm"}},
+	})
+	mock.delay = 200 * time.Millisecond
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+	input := VulnsInput{Ecosystem: "npm", Package: "lodash", Version: "4.17.19"}
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := registry.HandleVulns(leaderCtx, input)
+		leaderErr <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	followerErr := make(chan error, 1)
+	go func() {
+		output, err := registry.HandleVulns(context.Background(), input)
+		if err == nil && output.VulnerabilityCount != 1 {
+			err = fmt.Errorf("VulnerabilityCount = %d, want 1", output.VulnerabilityCount)
+		}
+		followerErr <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancelLeader()
+
+	// The leader gives up at once; the follower still gets the shared scan
+	select {
+	case err := <-leaderErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("leader error = %v, want context.Canceled", err)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Error("cancelled leader did not return before the scan finished")
+	}
+	if err := <-followerErr; err != nil {
+		t.Errorf("follower error = %v, want the shared result", err)
+	}
+	if got := mock.requests.Load(); got != 1 {
+		t.Errorf("OSV requests = %d, want 1 shared by both calls", got)
+	}
+
+	// A follower can also give up on its own deadline while the scan runs on
+	registry = newTestRegistry(t)
+	registry.osvClient = mock.client()
+	go func() { _, _ = registry.HandleVulns(context.Background(), input) }()
+	time.Sleep(50 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := registry.HandleVulns(ctx, input); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("follower error = %v, want its own context.DeadlineExceeded", err)
+	}
+}
+
+func TestErrorResult_OpenCircuitIsRetryable(t *testing.T) {
+	var requests atomic.Int64
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	registry := newTestRegistry(t)
+	osvBreaker := breaker.New(UpstreamOSV, 2, time.Minute)
+	registry.osvClient = osv.NewClient(zap.NewNop(), osv.WithBaseURL(down.URL), osv.WithBreaker(osvBreaker))
+
+	ctx := context.Background()
+	for _, pkg := range []string{"a", "b"} {
+		_, err := registry.HandleVulns(ctx, VulnsInput{Ecosystem: "npm", Package: pkg})
+		if err == nil {
+			t.Fatal("HandleVulns() against a failing upstream should error")
+		}
+		if res := errorResult(err); res.Meta != nil {
+			t.Errorf("plain upstream failure carried _meta %v, want none", res.Meta)
+		}
+	}
+
+	// The circuit is now open: the call fails fast without reaching OSV
+	_, err := registry.HandleVulns(ctx, VulnsInput{Ecosystem: "npm", Package: "c"})
+	if requests.Load() != 2 {
+		t.Errorf("OSV requests = %d, want 2", requests.Load())
+	}
+	res := errorResult(err)
+	if !res.IsError || res.Meta["retryable"] != true || res.Meta["upstream"] != UpstreamOSV {
+		t.Errorf("result = %+v, want a retryable envelope for osv", res)
+	}
+	if after, _ := res.Meta["retry_after_seconds"].(int); after <= 0 || after > 60 {
+		t.Errorf("retry_after_seconds = %v, want within the cooldown", res.Meta["retry_after_seconds"])
+	}
+	if !strings.Contains(resultText(t, res), "UPSTREAM_UNAVAILABLE") {
+		t.Errorf("text = %q, want UPSTREAM_UNAVAILABLE", resultText(t, res))
+	}
+}
+
+func TestErrorResult_MalformedResponseIsRetryable(t *testing.T) {
+	truncated := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"vulns": [{"id": "GHSA-`))
+	}))
+	defer truncated.Close()
+
+	registry := newTestRegistry(t)
+	registry.osvClient = osv.NewClient(zap.NewNop(), osv.WithBaseURL(truncated.URL))
+
+	_, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "npm", Package: "lodash"})
+	if err == nil {
+		t.Fatal("HandleVulns() with a truncated OSV response should error")
+	}
+	res := errorResult(err)
+	if !res.IsError || res.Meta["retryable"] != true || res.Meta["upstream"] != UpstreamOSV {
+		t.Errorf("result = %+v, want a retryable envelope for osv", res)
+	}
+	if text := resultText(t, res); !strings.Contains(text, "UPSTREAM_UNAVAILABLE") || !strings.Contains(text, `{\"vulns\": [{\"id\": \"GHSA-`) {
+		t.Errorf("text = %q, want UPSTREAM_UNAVAILABLE and the start of the body", text)
+	}
+}
+
+// summaryVulns builds n advisories cycling through CVSS v3 and v2 vectors, a bare qualitative
+// rating, and no severity at all, each with a CWE
+func summaryVulns(n int) []osv.Vulnerability {
+	severities := [][]osv.Severity{
+		{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}},
+		{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H"}},
+		{{Type: "CVSS_V2", Score: "AV:N/AC:M/Au:N/C:P/I:N/A:N"}},
+		{{Type: "CVSS_V3", Score: "MODERATE"}},
+		nil,
+	}
+	vulns := make([]osv.Vulnerability, n)
+	for i := range vulns {
+		vulns[i] = osv.Vulnerability{
+			ID:               fmt.Sprintf("GHSA-bench-%04d", i),
+			Severity:         severities[i%len(severities)],
+			DatabaseSpecific: map[string]interface{}{"cwe_ids": []interface{}{fmt.Sprintf("CWE-%d", 20+i%10)}},
+		}
+	}
+	return vulns
+}
+
+func BenchmarkComputeVulnSummary(b *testing.B) {
+	vulns := summaryVulns(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		computeVulnSummary(vulns)
+	}
+}
+
+// summaryBudget is the most one summary of 1000 vulnerabilities may take. It is about a
+// hundred times the measured cost of roughly 1ms, so only a real regression in the
+// aggregation, not a slow or busy CI machine, trips it.
+const summaryBudget = 100 * time.Millisecond
+
+func TestComputeVulnSummary_Budget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("measures performance")
+	}
+	vulns := summaryVulns(1000)
+	if got := computeVulnSummary(vulns); got.Critical+got.High+got.Medium+got.Low+got.Unknown != len(vulns) {
+		t.Fatalf("summary = %+v, want all %d vulnerabilities counted", got, len(vulns))
+	}
+
+	result := testing.Benchmark(func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			computeVulnSummary(vulns)
+		}
+	})
+	if perOp := time.Duration(result.NsPerOp()); perOp > summaryBudget {
+		t.Errorf("summarizing %d vulnerabilities took %v, over the %v budget", len(vulns), perOp, summaryBudget)
+	}
+}
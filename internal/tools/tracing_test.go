@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"github.com/rayprogramming/PackagePulse/internal/tracing"
+	"github.com/rayprogramming/hypermcp"
+	"github.com/rayprogramming/hypermcp/cache"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// redirect sends requests under each upstream base URL to the test server standing in for it
+type redirect map[string]string
+
+func (r redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	for base, target := range r {
+		if rest, ok := strings.CutPrefix(req.URL.String(), base); ok {
+			u, err := url.Parse(target + rest)
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.URL, req.Host = u, u.Host
+			break
+		}
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// TestTracing_UpgradePlanSpans verifies a deps.upgrade_plan call produces one server span
+// tagged with the package, with every OSV and deps.dev request as a client span beneath it
+func TestTracing_UpgradePlanSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	osvMock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/express@4.10.0": {{ID: "GHSA-aaaa-bbbb-cccc", Severity: []osv.Severity{{Type: "CVSS_V3", Score: "HIGH"}}}},
+	})
+	depsMock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{"npm/express": expressPackage()})
+
+	srv, err := hypermcp.New(hypermcp.Config{
+		Name:         "test",
+		Version:      "1.0.0",
+		CacheEnabled: true,
+		CacheConfig:  cache.Config{MaxCost: 1 << 20, NumCounters: 1000, BufferItems: 64},
+	}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	cfg := DefaultConfig()
+	cfg.TracerProvider = tp
+	cfg.Transport = redirect{osv.APIBaseURL: osvMock.URL, depsdev.APIBaseURL: depsMock.URL}
+	registry, err := NewToolRegistryWithConfig(zap.NewNop(), srv.Cache(), cfg)
+	if err != nil {
+		t.Fatalf("failed to create registry: %v", err)
+	}
+	registry.epssClient = newMockEPSS(t)
+	registry.kevClient = newMockKEV(t)
+	if err := registry.Register(srv); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.MCP().Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "deps.upgrade_plan",
+		Arguments: map[string]any{"ecosystem": "npm", "package": "express", "current_version": "4.10.0"},
+	})
+	if err != nil || res.IsError {
+		t.Fatalf("CallTool(deps.upgrade_plan) = %+v, %v", res, err)
+	}
+
+	var root tracetest.SpanStub
+	var children []tracetest.SpanStub
+	for _, span := range exporter.GetSpans() {
+		switch span.SpanKind {
+		case trace.SpanKindServer:
+			root = span
+		case trace.SpanKindClient:
+			children = append(children, span)
+		}
+	}
+
+	if root.Name != "tools/call deps.upgrade_plan" {
+		t.Fatalf("tool span = %q, want tools/call deps.upgrade_plan", root.Name)
+	}
+	if root.Parent.IsValid() {
+		t.Error("tool span has a parent, want it to be the root")
+	}
+	attrs := attribute.NewSet(root.Attributes...)
+	for key, want := range map[attribute.Key]string{
+		tracing.AttrTool:      "deps.upgrade_plan",
+		tracing.AttrEcosystem: "npm",
+		tracing.AttrPackage:   "express",
+		tracing.AttrVersion:   "4.10.0",
+		tracing.AttrCache:     "miss",
+	} {
+		if got, _ := attrs.Value(key); got.AsString() != want {
+			t.Errorf("tool span %s = %q, want %q", key, got.AsString(), want)
+		}
+	}
+
+	upstreams := map[string]int{}
+	for _, span := range children {
+		if span.Parent.SpanID() != root.SpanContext.SpanID() || span.SpanContext.TraceID() != root.SpanContext.TraceID() {
+			t.Errorf("%s span is not a child of the tool span", span.Name)
+		}
+		spanAttrs := attribute.NewSet(span.Attributes...)
+		upstream, _ := spanAttrs.Value(tracing.AttrUpstream)
+		upstreams[upstream.AsString()]++
+	}
+	if upstreams["osv"] == 0 || upstreams["deps.dev"] == 0 {
+		t.Errorf("client spans by upstream = %v, want both osv and deps.dev", upstreams)
+	}
+}
@@ -0,0 +1,320 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultMaxResponseBytes keeps tool results under what common MCP clients accept
+const DefaultMaxResponseBytes = 1 << 20
+
+// maxDetailString is how long a string may stay once a response has to be truncated; longer
+// strings, such as advisory details, are cut to this length first
+const maxDetailString = 1024
+
+// truncationNote tells the client how to get what a truncated response left out
+const truncationNote = "The response exceeded %d bytes, so the fields in truncated_fields were shortened. " +
+	"Summaries and counts still cover the full result. Page with limit and offset, or narrow the request " +
+	"(fewer packages, a version, a filter) to see the rest."
+
+// limitResponse wraps a tool handler so a text result larger than maxBytes is truncated to fit,
+// or passes the handler through when maxBytes is 0
+func limitResponse(maxBytes int, handler mcp.ToolHandler) mcp.ToolHandler {
+	if maxBytes <= 0 {
+		return handler
+	}
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := handler(ctx, req)
+		if err != nil || result == nil || result.IsError {
+			return result, err
+		}
+		for i, content := range result.Content {
+			text, ok := content.(*mcp.TextContent)
+			if !ok || len(text.Text) <= maxBytes {
+				continue
+			}
+			result.Content[i] = &mcp.TextContent{Text: truncateResponse(text.Text, maxBytes)}
+			if result.Meta == nil {
+				result.Meta = mcp.Meta{}
+			}
+			result.Meta["truncated"] = true
+			result.Meta["truncation_note"] = fmt.Sprintf(truncationNote, maxBytes)
+		}
+		return result, nil
+	}
+}
+
+// detailFields name the nested arrays of per-finding detail, in the order they are given up.
+// They are shortened across the whole result before any top-level list loses entries, so a batch
+// keeps every package and sheds their findings' detail instead.
+var detailFields = []string{"references", "affected", "vulnerabilities"}
+
+// truncateResponse shortens a tool result to at most maxBytes where it can. A JSON object keeps
+// every scalar field, so counts and summaries survive: its long strings are cut, then the nested
+// detailFields arrays are capped, then elements are dropped from the end of whichever array
+// carries the most data of its own, until it fits. The object is flagged with truncated,
+// truncation_note, and the paths of the shortened fields. Other text, such as CSV, is cut at
+// the last line that fits.
+func truncateResponse(text string, maxBytes int) string {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return truncateLines(text, maxBytes)
+	}
+
+	t := &truncator{shortened: map[string]bool{}}
+	t.cutStrings(doc, "")
+	obj, isObject := doc.(map[string]any)
+	// encode renders the document as it is sent, flags included
+	encode := func() []byte {
+		if isObject {
+			obj["truncated"] = true
+			obj["truncation_note"] = fmt.Sprintf(truncationNote, maxBytes)
+			obj["truncated_fields"] = t.fields()
+		}
+		data, _ := json.MarshalIndent(doc, "", "  ")
+		return data
+	}
+
+	for _, field := range detailFields {
+		if len(encode()) <= maxBytes {
+			break
+		}
+		t.capArrays(doc, field, func() bool { return len(encode()) <= maxBytes })
+	}
+	for {
+		data := encode()
+		excess := len(data) - maxBytes
+		if excess <= 0 {
+			return string(data)
+		}
+		// The flags are rewritten each pass and are not candidates for dropping
+		if isObject {
+			delete(obj, "truncated_fields")
+		}
+		if !t.dropElements(doc, excess) {
+			return string(data)
+		}
+	}
+}
+
+// nestedArray is an array held under key by a map below the top level of a document
+type nestedArray struct {
+	path   string
+	parent map[string]any
+	items  []any
+}
+
+// capArrays shortens every nested array named field to the longest common length at which fits
+// reports true, or to empty when none does. The shortened arrays are noted with [*] for their
+// indexes, so a batch's many results add one path rather than one each.
+func (t *truncator) capArrays(doc any, field string, fits func() bool) {
+	var arrays []nestedArray
+	collectArrays(doc, "", field, false, &arrays)
+	longest := 0
+	for _, a := range arrays {
+		longest = max(longest, len(a.items))
+	}
+	if longest == 0 {
+		return
+	}
+	apply := func(limit int) {
+		for _, a := range arrays {
+			a.parent[field] = a.items[:min(limit, len(a.items)):min(limit, len(a.items))]
+		}
+	}
+
+	// Every cap below longest shortens something, so note the paths before measuring
+	for _, a := range arrays {
+		if len(a.items) > 0 {
+			t.note(wildcardPath(a.path))
+		}
+	}
+	lo, hi := 0, longest-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		apply(mid)
+		if fits() {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	apply(lo)
+}
+
+// collectArrays finds the non-empty arrays named field below the top level of node
+func collectArrays(node any, path, field string, nested bool, arrays *[]nestedArray) {
+	switch v := node.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if items, ok := child.([]any); ok && key == field && nested && len(items) > 0 {
+				*arrays = append(*arrays, nestedArray{path: joinPath(path, key), parent: v, items: items})
+			}
+			collectArrays(child, joinPath(path, key), field, true, arrays)
+		}
+	case []any:
+		for i, child := range v {
+			collectArrays(child, path+"["+strconv.Itoa(i)+"]", field, true, arrays)
+		}
+	}
+}
+
+// wildcardPath replaces the array indexes in path with *
+func wildcardPath(path string) string {
+	var b strings.Builder
+	for {
+		open := strings.IndexByte(path, '[')
+		if open < 0 {
+			b.WriteString(path)
+			return b.String()
+		}
+		end := strings.IndexByte(path[open:], ']')
+		if end < 0 {
+			b.WriteString(path)
+			return b.String()
+		}
+		b.WriteString(path[:open])
+		b.WriteString("[*]")
+		path = path[open+end+1:]
+	}
+}
+
+// truncator shortens a decoded JSON document, remembering the paths it shortened
+type truncator struct {
+	shortened map[string]bool
+}
+
+func (t *truncator) note(path string) {
+	t.shortened[path] = true
+}
+
+func (t *truncator) fields() []string {
+	return slices.Sorted(maps.Keys(t.shortened))
+}
+
+// cutStrings shortens every string longer than maxDetailString
+func (t *truncator) cutStrings(node any, path string) {
+	switch v := node.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if s, ok := child.(string); ok && len(s) > maxDetailString {
+				v[key] = strings.ToValidUTF8(s[:maxDetailString], "") + "…"
+				t.note(joinPath(path, key))
+				continue
+			}
+			t.cutStrings(child, joinPath(path, key))
+		}
+	case []any:
+		for i, child := range v {
+			if s, ok := child.(string); ok && len(s) > maxDetailString {
+				v[i] = strings.ToValidUTF8(s[:maxDetailString], "") + "…"
+				t.note(path + "[" + strconv.Itoa(i) + "]")
+				continue
+			}
+			t.cutStrings(child, path+"["+strconv.Itoa(i)+"]")
+		}
+	}
+}
+
+// heaviestArray is the array whose own data, not counting arrays nested in it, is largest
+type heaviestArray struct {
+	path   string
+	own    int
+	parent any // the map or slice holding the array
+	key    string
+	index  int
+	items  []any
+	depth  int // how deeply the array's elements are indented
+}
+
+// dropElements removes enough trailing elements from the heaviest array to shed about excess
+// bytes, reporting false when no array has elements left to drop
+func (t *truncator) dropElements(doc any, excess int) bool {
+	var heaviest heaviestArray
+	_, _ = measure(doc, "", nil, "", 0, 0, &heaviest)
+	if heaviest.items == nil {
+		return false
+	}
+
+	keep := len(heaviest.items)
+	for shed := 0; keep > 0 && shed < excess; {
+		keep--
+		shed += indentedSize(heaviest.items[keep], heaviest.depth)
+	}
+	trimmed := heaviest.items[:keep:keep]
+	switch parent := heaviest.parent.(type) {
+	case map[string]any:
+		parent[heaviest.key] = trimmed
+	case []any:
+		parent[heaviest.index] = trimmed
+	}
+	t.note(heaviest.path)
+	return true
+}
+
+// measure returns the approximate encoded size of node and how much of it lies inside arrays,
+// tracking the non-empty array with the most data of its own in heaviest
+func measure(node any, path string, parent any, key string, index, depth int, heaviest *heaviestArray) (size, inArrays int) {
+	switch v := node.(type) {
+	case map[string]any:
+		size = 2
+		for k, child := range v {
+			childSize, childInArrays := measure(child, joinPath(path, k), v, k, 0, depth+1, heaviest)
+			size += len(k) + 4 + childSize
+			inArrays += childInArrays
+		}
+		return size, inArrays
+	case []any:
+		size = 2
+		nested := 0
+		for i, child := range v {
+			childSize, childInArrays := measure(child, path+"["+strconv.Itoa(i)+"]", v, "", i, depth+1, heaviest)
+			size += childSize + 1
+			nested += childInArrays
+		}
+		if own := size - nested; len(v) > 0 && own > heaviest.own {
+			*heaviest = heaviestArray{path: path, own: own, parent: parent, key: key, index: index, items: v, depth: depth + 1}
+		}
+		return size, size
+	default:
+		return jsonSize(node), 0
+	}
+}
+
+func jsonSize(node any) int {
+	data, _ := json.Marshal(node)
+	return len(data)
+}
+
+// indentedSize is how many bytes node takes as an array element indented depth levels deep in
+// the MarshalIndent output, counting its leading newline and indentation and its comma
+func indentedSize(node any, depth int) int {
+	data, _ := json.MarshalIndent(node, "", "  ")
+	lines := 1 + strings.Count(string(data), "\n")
+	return len(data) + lines*(2*depth) + 2
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// truncateLines keeps the leading lines of text that fit in maxBytes
+func truncateLines(text string, maxBytes int) string {
+	text = text[:maxBytes]
+	if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+		return text[:i+1]
+	}
+	return strings.ToValidUTF8(text, "")
+}
@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+)
+
+func TestLimitResponse(t *testing.T) {
+	full := &VulnsOutput{Package: "widget", Ecosystem: "npm"}
+	for i := range 200 {
+		full.Vulnerabilities = append(full.Vulnerabilities, Finding{Vulnerability: osv.Vulnerability{
+			ID:      fmt.Sprintf("GHSA-%04d", i),
+			Details: strings.Repeat("overflow ", 300),
+			Aliases: []string{fmt.Sprintf("CVE-2024-%04d", i)},
+		}})
+	}
+	full.VulnerabilityCount = len(full.Vulnerabilities)
+	full.Summary = VulnSummary{High: 150, Unknown: 50}
+	data, _ := json.MarshalIndent(full, "", "  ")
+
+	const maxBytes = 32 * 1024
+	handler := limitResponse(maxBytes, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(data)}}}, nil
+	})
+	result, err := handler(context.Background(), &mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+
+	text := resultText(t, result)
+	if len(text) > maxBytes {
+		t.Errorf("response is %d bytes, want at most %d", len(text), maxBytes)
+	}
+	if result.Meta["truncated"] != true {
+		t.Errorf("_meta = %v, want truncated", result.Meta)
+	}
+	var got struct {
+		VulnsOutput
+		Truncated       bool     `json:"truncated"`
+		TruncationNote  string   `json:"truncation_note"`
+		TruncatedFields []string `json:"truncated_fields"`
+	}
+	if err := json.Unmarshal([]byte(text), &got); err != nil {
+		t.Fatalf("truncated response is not JSON: %v", err)
+	}
+	if !got.Truncated || !strings.Contains(got.TruncationNote, "limit and offset") {
+		t.Errorf("truncated = %v, note = %q; want the flag and paging guidance", got.Truncated, got.TruncationNote)
+	}
+	// Counts and the summary describe the whole result; only the findings are cut
+	if got.VulnerabilityCount != 200 || got.Summary.High != 150 || got.Summary.Unknown != 50 || got.Package != "widget" {
+		t.Errorf("count = %d, summary = %+v; want the original 200 and summary", got.VulnerabilityCount, got.Summary)
+	}
+	if n := len(got.Vulnerabilities); n == 0 || n >= 200 || got.Vulnerabilities[0].ID != "GHSA-0000" {
+		t.Errorf("kept %d findings, want a leading subset of the 200", n)
+	}
+	if !slices.Contains(got.TruncatedFields, "vulnerabilities") || !slices.Contains(got.TruncatedFields, "vulnerabilities[0].details") {
+		t.Errorf("truncated_fields = %v, want the findings list and their details", got.TruncatedFields)
+	}
+
+	// Results within the limit pass through untouched
+	small := limitResponse(len(data), func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(data)}}}, nil
+	})
+	if result, _ := small(context.Background(), &mcp.CallToolRequest{}); resultText(t, result) != string(data) || result.Meta != nil {
+		t.Error("a result within the limit was modified")
+	}
+}
+
+// TestLimitResponse_BatchKeepsPackages verifies a batch result about ten times the cap keeps
+// every package and its counts, shedding the findings' detail instead
+func TestLimitResponse_BatchKeepsPackages(t *testing.T) {
+	full := &BatchVulnsOutput{}
+	for p := range 40 {
+		pkg := &VulnsOutput{Package: fmt.Sprintf("pkg-%02d", p), Ecosystem: "npm"}
+		for i := range 10 {
+			finding := Finding{Vulnerability: osv.Vulnerability{
+				ID:      fmt.Sprintf("GHSA-%02d-%02d", p, i),
+				Summary: "prototype pollution",
+				Details: strings.Repeat("detail ", 100),
+			}}
+			for r := range 8 {
+				finding.References = append(finding.References, osv.Reference{Type: "WEB", URL: fmt.Sprintf("https://example.com/advisories/%d/%d/%d", p, i, r)})
+			}
+			pkg.Vulnerabilities = append(pkg.Vulnerabilities, finding)
+		}
+		pkg.VulnerabilityCount = len(pkg.Vulnerabilities)
+		full.Results = append(full.Results, &BatchVulnsResult{VulnsOutput: pkg})
+	}
+	full.PackageCount = len(full.Results)
+	full.VulnerabilityCount = 400
+	data, _ := json.MarshalIndent(full, "", "  ")
+
+	maxBytes := len(data) / 10
+	text := truncateResponse(string(data), maxBytes)
+	if len(text) > maxBytes {
+		t.Errorf("response is %d bytes, want at most %d", len(text), maxBytes)
+	}
+	if len(text) < maxBytes/2 {
+		t.Errorf("response is %d bytes, want close to the %d-byte cap rather than over-trimmed", len(text), maxBytes)
+	}
+
+	var got struct {
+		BatchVulnsOutput
+		TruncatedFields []string `json:"truncated_fields"`
+	}
+	if err := json.Unmarshal([]byte(text), &got); err != nil {
+		t.Fatalf("truncated response is not JSON: %v", err)
+	}
+	if got.PackageCount != 40 || got.VulnerabilityCount != 400 || len(got.Results) != 40 {
+		t.Fatalf("package_count = %d, vulnerability_count = %d, results = %d; want all 40 packages kept",
+			got.PackageCount, got.VulnerabilityCount, len(got.Results))
+	}
+	for _, r := range got.Results {
+		if r.VulnsOutput == nil || r.VulnerabilityCount != 10 || !strings.HasPrefix(r.Package, "pkg-") {
+			t.Fatalf("result = %+v, want its package and count intact", r)
+		}
+		for _, v := range r.Vulnerabilities {
+			if len(v.References) != 0 {
+				t.Fatalf("%s kept %d references, want them shed before whole findings", v.ID, len(v.References))
+			}
+		}
+	}
+	if !slices.Contains(got.TruncatedFields, "results[*].vulnerabilities[*].references") {
+		t.Errorf("truncated_fields = %v, want the shed references", got.TruncatedFields)
+	}
+}
@@ -0,0 +1,188 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/rayprogramming/PackagePulse/internal/manifest"
+	"github.com/rayprogramming/PackagePulse/internal/pool"
+	"go.uber.org/zap"
+)
+
+// upgradeAllConcurrency bounds how many packages deps.upgrade_all analyzes at once
+const upgradeAllConcurrency = 8
+
+// priorityRank orders upgrade priorities from most to least pressing
+var priorityRank = map[string]int{
+	"URGENT":      0,
+	"UNKNOWN":     1,
+	"WARNING":     2,
+	"MEDIUM":      3,
+	"RECOMMENDED": 4,
+	"LOW":         5,
+	"OK":          6,
+}
+
+// UpgradeAllInput defines input for deps.upgrade_all tool
+type UpgradeAllInput struct {
+	Filename    string `json:"filename"`
+	Content     string `json:"content"`
+	RuntimeOnly bool   `json:"runtime_only,omitempty"`
+	// TopN keeps only the N most pressing upgrades; 0 keeps every plan
+	TopN int `json:"top_n,omitempty"`
+}
+
+// UpgradeCounts aggregates upgrade plans by urgency.
+// Recommended covers every non-urgent priority that still suggests upgrading;
+// Unknown counts plans built without vulnerability data.
+type UpgradeCounts struct {
+	Urgent      int `json:"urgent"`
+	Unknown     int `json:"unknown"`
+	Recommended int `json:"recommended"`
+	OK          int `json:"ok"`
+	Failed      int `json:"failed"`
+}
+
+// UpgradeFailure records a dependency whose upgrade plan could not be built
+type UpgradeFailure struct {
+	Ecosystem string `json:"ecosystem"`
+	Package   string `json:"package"`
+	Version   string `json:"version"`
+	Error     string `json:"error"`
+}
+
+// UpgradeAllOutput contains prioritized upgrade plans for every manifest dependency
+type UpgradeAllOutput struct {
+	Format          string                `json:"format"`
+	DependencyCount int                   `json:"dependency_count"`
+	PackageCount    int                   `json:"package_count"`
+	Counts          UpgradeCounts         `json:"counts"`
+	Upgrades        []*UpgradePlanOutput  `json:"upgrades"`
+	Failed          []UpgradeFailure      `json:"failed,omitempty"`
+	Unresolved      []manifest.Dependency `json:"unresolved,omitempty"`
+	// Omitted counts the plans top_n left out of Upgrades; Counts still include them
+	Omitted int `json:"omitted,omitempty"`
+}
+
+// HandleUpgradeAll parses a manifest and builds an upgrade plan for each distinct dependency.
+// Plans are ordered urgent first so the output can drive a remediation PR directly; top_n keeps
+// only the most pressing ones that need action.
+// Example: {"filename": "Cargo.lock", "content": "...", "top_n": 5}
+func (tr *ToolRegistry) HandleUpgradeAll(ctx context.Context, input UpgradeAllInput) (*UpgradeAllOutput, error) {
+	if input.Filename == "" || input.Content == "" {
+		return nil, fmt.Errorf("filename and content are required")
+	}
+	if input.TopN < 0 {
+		return nil, fmt.Errorf("%w: top_n must not be negative", errInvalidInput)
+	}
+
+	m, err := manifest.Parse(input.Filename, []byte(input.Content), manifest.Options{
+		RuntimeOnly: input.RuntimeOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Dedupe repeated ecosystem/name/version entries
+	seen := make(map[string]bool)
+	var inputs []UpgradePlanInput
+	for _, dep := range m.Dependencies {
+		key := dep.Ecosystem + "/" + dep.Name + "@" + dep.Version
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		inputs = append(inputs, UpgradePlanInput{
+			Ecosystem:      dep.Ecosystem,
+			Package:        dep.Name,
+			CurrentVersion: dep.Version,
+		})
+	}
+
+	tr.log(ctx).Info("Handling manifest upgrade plan",
+		zap.String("format", m.Format),
+		zap.Int("dependencies", len(m.Dependencies)),
+		zap.Int("packages", len(inputs)))
+
+	plans, err := pool.Map(ctx, inputs, upgradeAllConcurrency, tr.planUpgrade)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &UpgradeAllOutput{
+		Format:          m.Format,
+		DependencyCount: len(m.Dependencies),
+		PackageCount:    len(inputs),
+		Upgrades:        []*UpgradePlanOutput{},
+		Unresolved:      m.Unresolved,
+	}
+	for i, result := range plans {
+		plan := result.Value
+		if result.Err != nil {
+			tr.log(ctx).Warn("upgrade plan failed",
+				zap.String("package", inputs[i].Package),
+				zap.Error(result.Err))
+			output.Failed = append(output.Failed, UpgradeFailure{
+				Ecosystem: inputs[i].Ecosystem,
+				Package:   inputs[i].Package,
+				Version:   inputs[i].CurrentVersion,
+				Error:     result.Err.Error(),
+			})
+			output.Counts.Failed++
+			continue
+		}
+
+		switch plan.Priority {
+		case "URGENT":
+			output.Counts.Urgent++
+		case "UNKNOWN":
+			output.Counts.Unknown++
+		case "OK":
+			output.Counts.OK++
+		default:
+			output.Counts.Recommended++
+		}
+		output.Upgrades = append(output.Upgrades, plan)
+	}
+
+	sortUpgradePlans(output.Upgrades)
+
+	if input.TopN > 0 {
+		// Up-to-date plans need no remediation, so they never fill a top-N slot
+		top := make([]*UpgradePlanOutput, 0, input.TopN)
+		for _, plan := range output.Upgrades {
+			if len(top) == input.TopN {
+				break
+			}
+			if plan.Priority != "OK" {
+				top = append(top, plan)
+			}
+		}
+		output.Omitted = len(output.Upgrades) - len(top)
+		output.Upgrades = top
+	}
+
+	return output, nil
+}
+
+// sortUpgradePlans orders plans by priority, then risk score, then vulnerability count, then
+// package name and version, so ties always break the same way
+func sortUpgradePlans(plans []*UpgradePlanOutput) {
+	sort.SliceStable(plans, func(i, j int) bool {
+		ri, rj := priorityRank[plans[i].Priority], priorityRank[plans[j].Priority]
+		if ri != rj {
+			return ri < rj
+		}
+		if plans[i].RiskScore != plans[j].RiskScore {
+			return plans[i].RiskScore > plans[j].RiskScore
+		}
+		if plans[i].VulnerabilityCount != plans[j].VulnerabilityCount {
+			return plans[i].VulnerabilityCount > plans[j].VulnerabilityCount
+		}
+		if plans[i].Package != plans[j].Package {
+			return plans[i].Package < plans[j].Package
+		}
+		return plans[i].CurrentVersion < plans[j].CurrentVersion
+	})
+}
@@ -0,0 +1,227 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"go.uber.org/zap"
+)
+
+const upgradeAllCargoLock = `version = 3
+
+[[package]]
+name = "serde"
+version = "1.0.188"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "smallvec"
+version = "1.6.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "smallvec"
+version = "1.6.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "vanished"
+version = "0.1.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+`
+
+// cratePackage returns deps.dev metadata whose default version is the last one given
+func cratePackage(name string, versions ...string) *depsdev.PackageInfo {
+	now := time.Now()
+	info := &depsdev.PackageInfo{PackageKey: depsdev.PackageKey{System: "CARGO", Name: name}}
+	for i, v := range versions {
+		info.Versions = append(info.Versions, depsdev.VersionInfo{
+			VersionKey:  depsdev.VersionKey{Version: v},
+			PublishedAt: now.Add(-time.Duration(len(versions)-i) * 7 * 24 * time.Hour),
+		})
+	}
+	info.Versions[len(info.Versions)-1].IsDefault = true
+	return info
+}
+
+func TestHandleUpgradeAll(t *testing.T) {
+	osvMock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"crates.io/smallvec@1.6.0": {
+			{ID: "RUSTSEC-2021-0003", Aliases: []string{"CVE-2021-25900"}},
+		},
+	})
+	depsMock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"cargo/serde":    cratePackage("serde", "1.0.187", "1.0.188"),
+		"cargo/smallvec": cratePackage("smallvec", "1.6.0", "1.6.1", "1.13.2"),
+	})
+
+	registry := newTestRegistry(t)
+	registry.osvClient = osvMock.client()
+	registry.depsDevClient = depsMock.client()
+	registry.kevClient = newMockKEV(t)
+
+	result, err := registry.HandleUpgradeAll(context.Background(), UpgradeAllInput{
+		Filename: "Cargo.lock",
+		Content:  upgradeAllCargoLock,
+	})
+	if err != nil {
+		t.Fatalf("HandleUpgradeAll() error = %v", err)
+	}
+
+	if result.DependencyCount != 4 {
+		t.Errorf("DependencyCount = %d, want 4", result.DependencyCount)
+	}
+	if result.PackageCount != 3 {
+		t.Errorf("PackageCount = %d, want 3 after dedupe", result.PackageCount)
+	}
+
+	want := UpgradeCounts{Urgent: 1, OK: 1, Failed: 1}
+	if result.Counts != want {
+		t.Errorf("Counts = %+v, want %+v", result.Counts, want)
+	}
+
+	if len(result.Upgrades) != 2 {
+		t.Fatalf("expected 2 upgrade plans, got %d", len(result.Upgrades))
+	}
+	first := result.Upgrades[0]
+	if first.Package != "smallvec" || first.Priority != "URGENT" {
+		t.Errorf("first plan = %s (%s), want smallvec (URGENT)", first.Package, first.Priority)
+	}
+	if first.LatestVersion != "1.13.2" {
+		t.Errorf("smallvec LatestVersion = %s, want 1.13.2", first.LatestVersion)
+	}
+	if last := result.Upgrades[1]; last.Package != "serde" || last.Priority != "OK" {
+		t.Errorf("last plan = %s (%s), want serde (OK)", last.Package, last.Priority)
+	}
+
+	if len(result.Failed) != 1 || result.Failed[0].Package != "vanished" {
+		t.Errorf("Failed = %+v, want vanished", result.Failed)
+	}
+}
+
+func TestSortUpgradePlans(t *testing.T) {
+	plans := []*UpgradePlanOutput{
+		{Package: "ok", Priority: "OK"},
+		{Package: "low", Priority: "LOW"},
+		{Package: "urgent-few", Priority: "URGENT", VulnerabilityCount: 1},
+		{Package: "warning", Priority: "WARNING"},
+		{Package: "urgent-many", Priority: "URGENT", VulnerabilityCount: 5},
+		{Package: "urgent-risky", Priority: "URGENT", VulnerabilityCount: 1, RiskScore: 80},
+		{Package: "urgent-many", Priority: "URGENT", VulnerabilityCount: 5, CurrentVersion: "0.9.0"},
+	}
+
+	sortUpgradePlans(plans)
+
+	wantOrder := []string{"urgent-risky", "urgent-many", "urgent-many", "urgent-few", "warning", "low", "ok"}
+	for i, name := range wantOrder {
+		if plans[i].Package != name {
+			t.Errorf("plans[%d] = %s, want %s", i, plans[i].Package, name)
+		}
+	}
+}
+
+func TestHandleUpgradeAll_TopN(t *testing.T) {
+	// Five vulnerable crates of increasing severity and one that is up to date
+	vectors := map[string]string{
+		"alpha":   "CVSS:3.1/AV:N/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N",
+		"bravo":   "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"charlie": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:L/I:N/A:N",
+		"delta":   "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N",
+		"echo":    "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:N",
+	}
+	var lock strings.Builder
+	lock.WriteString("version = 3\n")
+	vulns := make(map[string][]osv.Vulnerability)
+	packages := map[string]*depsdev.PackageInfo{"cargo/current": cratePackage("current", "1.0.0")}
+	for name, vector := range vectors {
+		fmt.Fprintf(&lock, "\n[[package]]\nname = %q\nversion = \"1.0.0\"\nsource = \"registry+https://github.com/rust-lang/crates.io-index\"\n", name)
+		vulns["crates.io/"+name+"@1.0.0"] = []osv.Vulnerability{
+			{ID: "RUSTSEC-" + name, Severity: []osv.Severity{{Type: "CVSS_V3", Score: vector}}},
+		}
+		packages["cargo/"+name] = cratePackage(name, "1.0.0", "1.0.1")
+	}
+	lock.WriteString("\n[[package]]\nname = \"current\"\nversion = \"1.0.0\"\nsource = \"registry+https://github.com/rust-lang/crates.io-index\"\n")
+
+	registry := newTestRegistry(t)
+	registry.osvClient = newMockOSV(t, vulns).client()
+	registry.depsDevClient = newMockDepsDev(t, packages).client()
+
+	result, err := registry.HandleUpgradeAll(context.Background(), UpgradeAllInput{
+		Filename: "Cargo.lock",
+		Content:  lock.String(),
+		TopN:     3,
+	})
+	if err != nil {
+		t.Fatalf("HandleUpgradeAll() error = %v", err)
+	}
+
+	var got []string
+	for i, plan := range result.Upgrades {
+		got = append(got, plan.Package)
+		if i > 0 && plan.RiskScore > result.Upgrades[i-1].RiskScore {
+			t.Errorf("Upgrades[%d] risk %.1f ranks above Upgrades[%d] risk %.1f", i, plan.RiskScore, i-1, result.Upgrades[i-1].RiskScore)
+		}
+	}
+	if want := []string{"bravo", "echo", "delta"}; !slices.Equal(got, want) {
+		t.Errorf("Upgrades = %v, want %v", got, want)
+	}
+	if want := (UpgradeCounts{Urgent: 5, OK: 1}); result.Counts != want || result.Omitted != 3 {
+		t.Errorf("Counts = %+v, Omitted = %d; want %+v and 3 omitted", result.Counts, result.Omitted, want)
+	}
+
+	if _, err := registry.HandleUpgradeAll(context.Background(), UpgradeAllInput{Filename: "Cargo.lock", Content: lock.String(), TopN: -1}); err == nil {
+		t.Error("HandleUpgradeAll() with a negative top_n succeeded, want an error")
+	}
+}
+
+func TestHandleUpgradeAll_Cancelled(t *testing.T) {
+	var lock strings.Builder
+	lock.WriteString("version = 3\n")
+	for i := 0; i < 40; i++ {
+		fmt.Fprintf(&lock, "\n[[package]]\nname = \"crate-%d\"\nversion = \"1.0.0\"\nsource = \"registry+https://github.com/rust-lang/crates.io-index\"\n", i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// deps.dev stand-in that cancels the batch on first contact and then hangs until the client gives up
+	var requests atomic.Int64
+	depsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		cancel()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(depsServer.Close)
+
+	registry := newTestRegistry(t)
+	registry.osvClient = newMockOSV(t, nil).client()
+	registry.depsDevClient = depsdev.NewClient(zap.NewNop(), depsdev.WithBaseURL(depsServer.URL))
+	registry.kevClient = newMockKEV(t)
+
+	start := time.Now()
+	_, err := registry.HandleUpgradeAll(ctx, UpgradeAllInput{
+		Filename: "Cargo.lock",
+		Content:  lock.String(),
+	})
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("HandleUpgradeAll() error = %v, want context.Canceled", err)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("HandleUpgradeAll() took %v after cancellation", elapsed)
+	}
+	if n := requests.Load(); n > upgradeAllConcurrency {
+		t.Errorf("deps.dev received %d requests, want at most %d in-flight before cancellation", n, upgradeAllConcurrency)
+	}
+}
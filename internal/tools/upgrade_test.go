@@ -0,0 +1,475 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"github.com/rayprogramming/PackagePulse/internal/providers/packagist"
+	"github.com/rayprogramming/PackagePulse/internal/providers/spdx"
+	"go.uber.org/zap"
+)
+
+// expressPackage returns deps.dev metadata for a healthy, actively maintained package
+func expressPackage() *depsdev.PackageInfo {
+	now := time.Now()
+	versions := make([]depsdev.VersionInfo, 0, 60)
+	for i := 0; i < 60; i++ {
+		versions = append(versions, depsdev.VersionInfo{
+			VersionKey:  depsdev.VersionKey{Version: fmt.Sprintf("4.%d.0", i)},
+			PublishedAt: now.Add(-time.Duration(600-i*10) * 24 * time.Hour),
+			Licenses:    []string{"MIT"},
+		})
+	}
+	versions[len(versions)-1].IsDefault = true
+	return &depsdev.PackageInfo{
+		PackageKey: depsdev.PackageKey{System: "NPM", Name: "express"},
+		Versions:   versions,
+		Links: []depsdev.Link{
+			{Label: "SOURCE_REPO", URL: "https://github.com/expressjs/express"},
+			{Label: "DOCUMENTATION", URL: "https://expressjs.com"},
+		},
+	}
+}
+
+// runUpgradePlan invokes HandleUpgradePlan and decodes the successful result
+func runUpgradePlan(t *testing.T, registry *ToolRegistry, input UpgradePlanInput) *UpgradePlanOutput {
+	t.Helper()
+
+	result, err := registry.HandleUpgradePlan(context.Background(), input)
+	if err != nil {
+		t.Fatalf("HandleUpgradePlan() unexpected error: %v", err)
+	}
+	text := resultText(t, result)
+	if result.IsError {
+		t.Fatalf("HandleUpgradePlan() returned error result: %s", text)
+	}
+
+	var plan UpgradePlanOutput
+	if err := json.Unmarshal([]byte(text), &plan); err != nil {
+		t.Fatalf("Failed to parse upgrade plan JSON: %v", err)
+	}
+	return &plan
+}
+
+func TestUpgradePlan_KnownExploitedEscalates(t *testing.T) {
+	osvMock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/express@4.10.0": {
+			// A low-severity finding that would not normally warrant an emergency
+			{ID: "GHSA-aaaa-bbbb-cccc", Aliases: []string{"CVE-2022-24999"}, Severity: []osv.Severity{{Type: "CVSS_V3", Score: "LOW"}}},
+		},
+	})
+	depsMock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{"npm/express": expressPackage()})
+
+	registry := newTestRegistry(t)
+	registry.osvClient = osvMock.client()
+	registry.depsDevClient = depsMock.client()
+	registry.kevClient = newMockKEV(t, "CVE-2022-24999")
+
+	plan := runUpgradePlan(t, registry, UpgradePlanInput{
+		Ecosystem:      "npm",
+		Package:        "express",
+		CurrentVersion: "4.10.0",
+	})
+
+	if plan.Priority != "URGENT" {
+		t.Errorf("Priority = %s, want URGENT", plan.Priority)
+	}
+	if len(plan.KnownExploited) != 1 || plan.KnownExploited[0] != "CVE-2022-24999" {
+		t.Errorf("KnownExploited = %v, want [CVE-2022-24999]", plan.KnownExploited)
+	}
+	if !strings.Contains(plan.Recommendation, "actively exploited") {
+		t.Errorf("Recommendation should mention active exploitation: %s", plan.Recommendation)
+	}
+	if len(plan.Factors) != 1 || plan.Factors[0].Factor != FactorKnownExploited || plan.Factors[0].Weight != WeightHigh {
+		t.Errorf("Factors = %+v, want only a high-weight known_exploited", plan.Factors)
+	}
+}
+
+func TestUpgradePlan_NotKnownExploited(t *testing.T) {
+	osvMock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/express@4.10.0": {
+			{ID: "GHSA-aaaa-bbbb-cccc", Aliases: []string{"CVE-2022-24999"}},
+		},
+	})
+	depsMock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{"npm/express": expressPackage()})
+
+	registry := newTestRegistry(t)
+	registry.osvClient = osvMock.client()
+	registry.depsDevClient = depsMock.client()
+	registry.kevClient = newMockKEV(t, "CVE-2021-44228")
+
+	plan := runUpgradePlan(t, registry, UpgradePlanInput{
+		Ecosystem:      "npm",
+		Package:        "express",
+		CurrentVersion: "4.10.0",
+	})
+
+	if len(plan.KnownExploited) != 0 {
+		t.Errorf("KnownExploited = %v, want none", plan.KnownExploited)
+	}
+	if strings.Contains(plan.Recommendation, "actively exploited") {
+		t.Errorf("Recommendation should not mention active exploitation: %s", plan.Recommendation)
+	}
+}
+
+func TestHandleVulns_MarksKnownExploited(t *testing.T) {
+	osvMock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"Maven/org.apache.logging.log4j:log4j-core@2.14.1": {
+			{ID: "GHSA-jfh8-c2jp-5v3q", Aliases: []string{"CVE-2021-44228"}},
+			{ID: "GHSA-7rjr-3q55-vv33", Aliases: []string{"CVE-2021-45046"}},
+		},
+	})
+
+	registry := newTestRegistry(t)
+	registry.osvClient = osvMock.client()
+	registry.kevClient = newMockKEV(t, "CVE-2021-44228")
+
+	result, err := registry.HandleVulns(context.Background(), VulnsInput{
+		Ecosystem: "Maven",
+		Package:   "org.apache.logging.log4j:log4j-core",
+		Version:   "2.14.1",
+	})
+	if err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+
+	for _, f := range result.Vulnerabilities {
+		want := f.ID == "GHSA-jfh8-c2jp-5v3q"
+		if f.KnownExploited != want {
+			t.Errorf("%s KnownExploited = %v, want %v", f.ID, f.KnownExploited, want)
+		}
+	}
+}
+
+func TestUpgradePlan_Packagist(t *testing.T) {
+	packagistServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != packagist.PackagePath+"symfony/console.json" {
+			http.NotFound(w, r)
+			return
+		}
+		now := time.Now().UTC()
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"packages": map[string]interface{}{
+				"symfony/console": []map[string]interface{}{
+					{"name": "symfony/console", "version": "v7.0.0-BETA1", "time": now.Add(-24 * time.Hour)},
+					{"name": "symfony/console", "version": "v6.3.1", "time": now.Add(-10 * 24 * time.Hour), "source": map[string]string{"url": "https://github.com/symfony/console.git"}},
+					{"name": "symfony/console", "version": "v6.3.0", "time": now.Add(-30 * 24 * time.Hour)},
+				},
+			},
+		})
+	}))
+	t.Cleanup(packagistServer.Close)
+
+	registry := newTestRegistry(t)
+	registry.osvClient = newMockOSV(t, nil).client()
+	registry.packagistClient = packagist.NewClient(zap.NewNop(), packagist.WithBaseURL(packagistServer.URL))
+	registry.kevClient = newMockKEV(t)
+
+	plan := runUpgradePlan(t, registry, UpgradePlanInput{
+		Ecosystem:      "composer",
+		Package:        "symfony/console",
+		CurrentVersion: "6.3.0",
+	})
+
+	if plan.Ecosystem != "Packagist" {
+		t.Errorf("Ecosystem = %s, want Packagist", plan.Ecosystem)
+	}
+	if plan.LatestVersion != "6.3.1" {
+		t.Errorf("LatestVersion = %s, want 6.3.1 (newest stable, without tag prefix)", plan.LatestVersion)
+	}
+	if plan.IsUpToDate {
+		t.Error("6.3.0 should not be reported as up to date")
+	}
+}
+
+func TestUpgradePlan_NoVersions(t *testing.T) {
+	osvMock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/vulnerable-ghost@1.0.0": {{ID: "GHSA-gggg-hhhh-iiii"}},
+	})
+	depsMock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"npm/ghost":            {PackageKey: depsdev.PackageKey{System: "NPM", Name: "ghost"}, Versions: []depsdev.VersionInfo{}},
+		"npm/vulnerable-ghost": {PackageKey: depsdev.PackageKey{System: "NPM", Name: "vulnerable-ghost"}, Versions: []depsdev.VersionInfo{}},
+	})
+
+	registry := newTestRegistry(t)
+	registry.osvClient = osvMock.client()
+	registry.depsDevClient = depsMock.client()
+
+	plan := runUpgradePlan(t, registry, UpgradePlanInput{Ecosystem: "npm", Package: "ghost", CurrentVersion: "1.0.0"})
+	if plan.Priority != "UNKNOWN" || plan.IsUpToDate || plan.MaintenanceLevel != depsdev.MaintenanceLevelUnknown {
+		t.Errorf("plan = %+v, want UNKNOWN priority with unknown maintenance", plan)
+	}
+	if !strings.Contains(plan.Recommendation, "Cannot determine the latest version") {
+		t.Errorf("Recommendation = %q, want a cannot-determine-latest explanation", plan.Recommendation)
+	}
+	if len(plan.UpgradePath) != 1 {
+		t.Errorf("UpgradePath = %v, want only the current version", plan.UpgradePath)
+	}
+
+	// Vulnerabilities still drive the priority when no latest version is known
+	plan = runUpgradePlan(t, registry, UpgradePlanInput{Ecosystem: "npm", Package: "vulnerable-ghost", CurrentVersion: "1.0.0"})
+	if plan.Priority != "URGENT" || !strings.Contains(plan.Recommendation, "a patched release") {
+		t.Errorf("plan = %s: %s, want URGENT pointing at a patched release", plan.Priority, plan.Recommendation)
+	}
+}
+
+func TestUpgradePlan_Factors(t *testing.T) {
+	now := time.Now()
+	stale := expressPackage()
+	for i := range stale.Versions {
+		stale.Versions[i].PublishedAt = stale.Versions[i].PublishedAt.Add(-400 * 24 * time.Hour)
+	}
+	abandoned := &depsdev.PackageInfo{
+		PackageKey: depsdev.PackageKey{System: "NPM", Name: "abandoned"},
+		Versions: []depsdev.VersionInfo{
+			{VersionKey: depsdev.VersionKey{Version: "1.0.0"}, PublishedAt: now.Add(-2100 * 24 * time.Hour)},
+			{VersionKey: depsdev.VersionKey{Version: "1.1.0"}, PublishedAt: now.Add(-2000 * 24 * time.Hour), IsDefault: true},
+		},
+	}
+	registry := newTestRegistry(t)
+	registry.osvClient = newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/vulnerable@4.10.0": {
+			{ID: "GHSA-crit-0000-0000", Severity: []osv.Severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}},
+			{ID: "GHSA-unrated-0000"},
+		},
+	}).client()
+	registry.depsDevClient = newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"npm/express":    expressPackage(),
+		"npm/vulnerable": expressPackage(),
+		"npm/stale":      stale,
+		"npm/abandoned":  abandoned,
+		"npm/ghost":      {PackageKey: depsdev.PackageKey{System: "NPM", Name: "ghost"}},
+	}).client()
+
+	tests := []struct {
+		pkg, version string
+		priority     string
+		factors      []string
+	}{
+		{"vulnerable", "4.10.0", "URGENT", []string{FactorCriticalVulnerability, FactorVulnerability}},
+		{"ghost", "1.0.0", "UNKNOWN", []string{FactorLatestUnknown}},
+		{"express", "4.59.0", "OK", []string{FactorUpToDate}},
+		{"abandoned", "1.1.0", "OK", []string{FactorUpToDate, FactorCriticalMaintenance}},
+		{"abandoned", "1.0.0", "WARNING", []string{FactorCriticalMaintenance, FactorUpgradeAvailable}},
+		{"stale", "4.58.0", "LOW", []string{FactorUpgradeAvailable, FactorStale}},
+		{"express", "3.0.0", "MEDIUM", []string{FactorBreakingChanges, FactorUpgradeAvailable}},
+		{"express", "4.10.0", "RECOMMENDED", []string{FactorUpgradeAvailable}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pkg+"@"+tt.version, func(t *testing.T) {
+			plan := runUpgradePlan(t, registry, UpgradePlanInput{Ecosystem: "npm", Package: tt.pkg, CurrentVersion: tt.version})
+			var factors []string
+			for _, f := range plan.Factors {
+				if f.Weight == "" {
+					t.Errorf("factor %s has no weight", f.Factor)
+				}
+				factors = append(factors, f.Factor)
+			}
+			if plan.Priority != tt.priority || !slices.Equal(factors, tt.factors) {
+				t.Errorf("plan = %s %v, want %s %v", plan.Priority, factors, tt.priority, tt.factors)
+			}
+		})
+	}
+}
+
+func TestUpgradePlan_PrereleaseLatest(t *testing.T) {
+	now := time.Now()
+	registry := newTestRegistry(t)
+	registry.osvClient = newMockOSV(t, nil).client()
+	registry.depsDevClient = newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"npm/widget": {
+			PackageKey: depsdev.PackageKey{System: "NPM", Name: "widget"},
+			Versions: []depsdev.VersionInfo{
+				{VersionKey: depsdev.VersionKey{Version: "1.8.0"}, PublishedAt: now.Add(-90 * 24 * time.Hour)},
+				{VersionKey: depsdev.VersionKey{Version: "1.9.0"}, PublishedAt: now.Add(-60 * 24 * time.Hour)},
+				{VersionKey: depsdev.VersionKey{Version: "2.0.0-rc.0"}, PublishedAt: now.Add(-20 * 24 * time.Hour)},
+				{VersionKey: depsdev.VersionKey{Version: "2.0.0-rc.1"}, PublishedAt: now.Add(-5 * 24 * time.Hour), IsDefault: true},
+			},
+		},
+	}).client()
+
+	// Stable users are pointed at the latest stable release, not the release candidate
+	plan := runUpgradePlan(t, registry, UpgradePlanInput{Ecosystem: "npm", Package: "widget", CurrentVersion: "1.8.0"})
+	if !plan.LatestIsPrerelease || plan.LatestStableVersion != "1.9.0" || !slices.Equal(plan.UpgradePath, []string{"1.8.0", "1.9.0"}) {
+		t.Errorf("plan = prerelease %v, stable %s, path %v; want a path to 1.9.0", plan.LatestIsPrerelease, plan.LatestStableVersion, plan.UpgradePath)
+	}
+	if plan.Priority != "RECOMMENDED" || !strings.Contains(plan.Recommendation, "latest stable release 1.9.0") {
+		t.Errorf("plan = %s: %s, want a recommended upgrade to 1.9.0", plan.Priority, plan.Recommendation)
+	}
+
+	plan = runUpgradePlan(t, registry, UpgradePlanInput{Ecosystem: "npm", Package: "widget", CurrentVersion: "1.9.0"})
+	if !plan.IsUpToDate || plan.Priority != "OK" {
+		t.Errorf("plan = %s up to date %v, want OK on the latest stable release", plan.Priority, plan.IsUpToDate)
+	}
+
+	// Prerelease users follow the prerelease line
+	plan = runUpgradePlan(t, registry, UpgradePlanInput{Ecosystem: "npm", Package: "widget", CurrentVersion: "2.0.0-rc.0"})
+	if !slices.Equal(plan.UpgradePath, []string{"2.0.0-rc.0", "2.0.0-rc.1"}) || strings.Contains(plan.Recommendation, "latest stable release") {
+		t.Errorf("plan = path %v: %s, want a path to 2.0.0-rc.1", plan.UpgradePath, plan.Recommendation)
+	}
+}
+
+func TestUpgradePlan_IncludePrereleaseAndYanked(t *testing.T) {
+	now := time.Now()
+	registry := newTestRegistry(t)
+	registry.osvClient = newMockOSV(t, nil).client()
+	registry.depsDevClient = newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"npm/widget": {
+			PackageKey: depsdev.PackageKey{System: "NPM", Name: "widget"},
+			Versions: []depsdev.VersionInfo{
+				{VersionKey: depsdev.VersionKey{Version: "0.9.0"}, PublishedAt: now.Add(-90 * 24 * time.Hour)},
+				{VersionKey: depsdev.VersionKey{Version: "1.0.0"}, PublishedAt: now.Add(-30 * 24 * time.Hour), IsDefault: true},
+				{VersionKey: depsdev.VersionKey{Version: "1.1.0"}, PublishedAt: now.Add(-20 * 24 * time.Hour), IsDeprecated: true},
+				{VersionKey: depsdev.VersionKey{Version: "2.0.0-beta.1"}, PublishedAt: now.Add(-5 * 24 * time.Hour)},
+			},
+		},
+	}).client()
+	input := UpgradePlanInput{Ecosystem: "npm", Package: "widget", CurrentVersion: "0.9.0"}
+
+	// By default the newer prerelease and the yanked release are passed over
+	plan := runUpgradePlan(t, registry, input)
+	if plan.LatestVersion != "1.0.0" || plan.LatestIsPrerelease || !slices.Equal(plan.UpgradePath, []string{"0.9.0", "1.0.0"}) {
+		t.Errorf("default plan = latest %s, path %v; want a path to 1.0.0", plan.LatestVersion, plan.UpgradePath)
+	}
+
+	input.IncludePrerelease = true
+	plan = runUpgradePlan(t, registry, input)
+	if plan.LatestVersion != "2.0.0-beta.1" || !plan.LatestIsPrerelease || !slices.Equal(plan.UpgradePath, []string{"0.9.0", "2.0.0-beta.1"}) {
+		t.Errorf("include_prerelease plan = latest %s, path %v; want a path to 2.0.0-beta.1", plan.LatestVersion, plan.UpgradePath)
+	}
+
+	input.IncludePrerelease, input.IncludeYanked = false, true
+	plan = runUpgradePlan(t, registry, input)
+	if plan.LatestVersion != "1.1.0" || !slices.Equal(plan.UpgradePath, []string{"0.9.0", "1.1.0"}) {
+		t.Errorf("include_yanked plan = latest %s, path %v; want a path to 1.1.0", plan.LatestVersion, plan.UpgradePath)
+	}
+}
+
+func TestCheckBreakingChanges(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		want            bool
+	}{
+		{"1.2.3", "2.0.0", true},
+		{"v1.2.3", "v2.0.0", true},
+		{"1.2.3", "10.0.0", true},
+		{"1.2.3", "1.9.0", false},
+		{"0.2.0", "0.3.0", true},
+		{"v2.0.0+incompatible", "v2.3.0+incompatible", false},
+		{"v0.0.0-20230101000000-abcdef123456", "v1.4.0", true},
+		{"v1.2.4-0.20230101000000-abcdef123456", "v1.5.0", false},
+		{"2.0.0", "1.9.0", false},
+	}
+	for _, tt := range tests {
+		if got := checkBreakingChanges(tt.current, tt.latest); got != tt.want {
+			t.Errorf("checkBreakingChanges(%q, %q) = %v, want %v", tt.current, tt.latest, got, tt.want)
+		}
+	}
+}
+
+func TestUpgradePlan_GoPseudoVersion(t *testing.T) {
+	now := time.Now()
+	module := &depsdev.PackageInfo{
+		PackageKey: depsdev.PackageKey{System: "GO", Name: "example.com/widget"},
+		Versions: []depsdev.VersionInfo{
+			{VersionKey: depsdev.VersionKey{Version: "v1.2.3"}, PublishedAt: now.Add(-90 * 24 * time.Hour)},
+			{VersionKey: depsdev.VersionKey{Version: "v1.3.0"}, PublishedAt: now.Add(-10 * 24 * time.Hour), IsDefault: true},
+		},
+		Links: []depsdev.Link{{Label: "SOURCE_REPO", URL: "https://github.com/example/widget"}},
+	}
+	registry := newTestRegistry(t)
+	registry.osvClient = newMockOSV(t, nil).client()
+	registry.depsDevClient = newMockDepsDev(t, map[string]*depsdev.PackageInfo{"go/example.com/widget": module}).client()
+
+	// A commit after v1.2.3 trails the v1.3.0 release
+	plan := runUpgradePlan(t, registry, UpgradePlanInput{
+		Ecosystem:      "Go",
+		Package:        "example.com/widget",
+		CurrentVersion: "v1.2.4-0.20230101000000-abcdef123456",
+	})
+	if plan.IsUpToDate || plan.BreakingChanges || plan.PseudoVersion == nil || plan.PseudoVersion.Base != "v1.2.3" {
+		t.Errorf("plan = %+v, want a non-breaking upgrade from a pseudo-version based on v1.2.3", plan)
+	}
+	if !strings.Contains(plan.Recommendation, "prefer the tagged release v1.3.0") {
+		t.Errorf("Recommendation = %q, want advice to move to the tagged release", plan.Recommendation)
+	}
+
+	// A commit past the latest tag is current
+	plan = runUpgradePlan(t, registry, UpgradePlanInput{
+		Ecosystem:      "Go",
+		Package:        "example.com/widget",
+		CurrentVersion: "v1.3.1-0.20990101000000-abcdef123456",
+	})
+	if !plan.IsUpToDate || plan.Priority != "OK" || !strings.Contains(plan.Recommendation, "untagged commit") {
+		t.Errorf("plan = %s %v: %s, want OK on an untagged commit newer than v1.3.0", plan.Priority, plan.IsUpToDate, plan.Recommendation)
+	}
+}
+
+func TestUpgradePlan_MultipleLicenses(t *testing.T) {
+	now := time.Now()
+	registry := newTestRegistry(t)
+	registry.osvClient = newMockOSV(t, nil).client()
+	registry.depsDevClient = newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"cargo/widget": {
+			PackageKey: depsdev.PackageKey{System: "CARGO", Name: "widget"},
+			Versions: []depsdev.VersionInfo{
+				{VersionKey: depsdev.VersionKey{Version: "1.0.0"}, PublishedAt: now.Add(-60 * 24 * time.Hour), Licenses: []string{"MIT"}},
+				{VersionKey: depsdev.VersionKey{Version: "1.1.0"}, PublishedAt: now.Add(-5 * 24 * time.Hour), Licenses: []string{"MIT", "Apache-2.0"}, IsDefault: true},
+			},
+		},
+	}).client()
+
+	plan := runUpgradePlan(t, registry, UpgradePlanInput{Ecosystem: "cargo", Package: "widget", CurrentVersion: "1.0.0"})
+	if plan.Licenses == nil {
+		t.Fatal("plan.Licenses = nil, want the licenses of 1.1.0")
+	}
+	got := plan.Licenses
+	if got.Expression != "MIT AND Apache-2.0" || got.Relation != spdx.OpAnd || len(got.Licenses) != 2 {
+		t.Errorf("licenses = %+v, want MIT AND Apache-2.0 with two licenses", got)
+	}
+	if got.MostPermissive != "MIT AND Apache-2.0" || got.Category != spdx.CategoryPermissive {
+		t.Errorf("most permissive = %s (%s), want MIT AND Apache-2.0 (Permissive)", got.MostPermissive, got.Category)
+	}
+	for _, l := range got.Licenses {
+		if l.Name == "" || l.Category != spdx.CategoryPermissive {
+			t.Errorf("license %+v, want a resolved permissive license", l)
+		}
+	}
+}
+
+func TestDeclaredLicenses(t *testing.T) {
+	registry := newTestRegistry(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		licenses       []string
+		relation       string
+		mostPermissive string
+		category       string
+	}{
+		{[]string{"MIT"}, "", "MIT", spdx.CategoryPermissive},
+		{[]string{"MIT OR Apache-2.0"}, spdx.OpOr, "MIT", spdx.CategoryPermissive},
+		{[]string{"GPL-3.0-or-later OR MIT"}, spdx.OpOr, "MIT", spdx.CategoryPermissive},
+		{[]string{"MIT", "GPL-2.0"}, spdx.OpAnd, "MIT AND GPL-2.0", spdx.CategoryCopyleft},
+		{[]string{"non-standard"}, "", "non-standard", LicenseCategoryUnknown},
+	}
+	for _, tt := range tests {
+		got := registry.declaredLicenses(ctx, tt.licenses)
+		if got == nil || got.Relation != tt.relation || got.MostPermissive != tt.mostPermissive || got.Category != tt.category {
+			t.Errorf("declaredLicenses(%v) = %+v, want relation %q, most permissive %q, category %s",
+				tt.licenses, got, tt.relation, tt.mostPermissive, tt.category)
+		}
+	}
+	if got := registry.declaredLicenses(ctx, nil); got != nil {
+		t.Errorf("declaredLicenses(nil) = %+v, want nil", got)
+	}
+}
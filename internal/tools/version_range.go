@@ -0,0 +1,275 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+)
+
+// RangeAnalysis reports which part of a requested version range is vulnerable
+type RangeAnalysis struct {
+	Range string `json:"range"`
+	// VulnerableRanges is the merged subset of Range affected by at least one finding
+	VulnerableRanges []string `json:"vulnerable_ranges"`
+	// EarliestSafeVersion is the lowest version at or above the range's lower bound that no
+	// finding affects, taken from the range's own lower bound or a fixed version
+	EarliestSafeVersion string `json:"earliest_safe_version,omitempty"`
+	// SafeWithinRange is true when EarliestSafeVersion satisfies the range's upper bound
+	SafeWithinRange bool `json:"safe_within_range"`
+}
+
+// versionInterval is a span of versions. An empty lower bound is unbounded below and an
+// empty upper bound is unbounded above.
+type versionInterval struct {
+	lower, upper         string
+	lowerIncl, upperIncl bool
+}
+
+var constraintPattern = regexp.MustCompile(`(>=|<=|>|<|=)?\s*([^\s,<>=]+)`)
+
+// parseVersionRange parses space- or comma-separated constraints such as ">=4.0.0 <4.17.21"
+func parseVersionRange(s string) (versionInterval, error) {
+	var r versionInterval
+	matches := constraintPattern.FindAllStringSubmatch(s, -1)
+	if len(matches) == 0 {
+		return r, fmt.Errorf("invalid version range %q", s)
+	}
+	for _, m := range matches {
+		op, version := m[1], m[2]
+		switch op {
+		case ">=", ">":
+			r.lower, r.lowerIncl = version, op == ">="
+		case "<=", "<":
+			r.upper, r.upperIncl = version, op == "<="
+		default:
+			r.lower, r.lowerIncl = version, true
+			r.upper, r.upperIncl = version, true
+		}
+	}
+	if r.empty() {
+		return r, fmt.Errorf("version range %q matches no versions", s)
+	}
+	return r, nil
+}
+
+func (r versionInterval) contains(version string) bool {
+	if r.lower != "" {
+		c := depsdev.CompareVersions(version, r.lower)
+		if c < 0 || (c == 0 && !r.lowerIncl) {
+			return false
+		}
+	}
+	if r.upper != "" {
+		c := depsdev.CompareVersions(version, r.upper)
+		if c > 0 || (c == 0 && !r.upperIncl) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r versionInterval) empty() bool {
+	if r.lower == "" || r.upper == "" {
+		return false
+	}
+	c := depsdev.CompareVersions(r.lower, r.upper)
+	return c > 0 || (c == 0 && !(r.lowerIncl && r.upperIncl))
+}
+
+// intersect returns the overlap of two intervals and whether it is non-empty
+func (r versionInterval) intersect(o versionInterval) (versionInterval, bool) {
+	out := r
+	if o.lower != "" {
+		if c := compareOptional(o.lower, out.lower, -1); c > 0 || (c == 0 && !o.lowerIncl) {
+			out.lower, out.lowerIncl = o.lower, o.lowerIncl
+		}
+	}
+	if o.upper != "" {
+		if c := compareOptional(o.upper, out.upper, 1); c < 0 || (c == 0 && !o.upperIncl) {
+			out.upper, out.upperIncl = o.upper, o.upperIncl
+		}
+	}
+	return out, !out.empty()
+}
+
+// compareOptional compares versions where an empty b is unbounded in the direction of sign
+func compareOptional(a, b string, sign int) int {
+	if b == "" {
+		return -sign
+	}
+	return depsdev.CompareVersions(a, b)
+}
+
+func (r versionInterval) String() string {
+	var parts []string
+	if r.lower != "" {
+		op := ">"
+		if r.lowerIncl {
+			op = ">="
+		}
+		parts = append(parts, op+r.lower)
+	}
+	if r.upper != "" {
+		op := "<"
+		if r.upperIncl {
+			op = "<="
+		}
+		parts = append(parts, op+r.upper)
+	}
+	if len(parts) == 0 {
+		return "*"
+	}
+	return strings.Join(parts, " ")
+}
+
+// affectedIntervals converts a vulnerability's SEMVER and ECOSYSTEM ranges for the named
+// package into version intervals. GIT ranges name commits, not versions, and are skipped.
+func affectedIntervals(vuln osv.Vulnerability, pkg string) []versionInterval {
+	var intervals []versionInterval
+	for _, affected := range vuln.Affected {
+		if pkg != "" && affected.Package.Name != "" && affected.Package.Name != pkg {
+			continue
+		}
+		for _, r := range affected.Ranges {
+			if r.Type == osv.RangeGit {
+				continue
+			}
+			events := append([]osv.Event(nil), r.Events...)
+			// "0" means "since the first release" and sorts below every version, prereleases included
+			sort.SliceStable(events, func(i, j int) bool {
+				if events[i].Introduced == "0" || events[j].Introduced == "0" {
+					return events[i].Introduced == "0" && events[j].Introduced != "0"
+				}
+				return depsdev.CompareVersions(eventVersion(events[i]), eventVersion(events[j])) < 0
+			})
+
+			var open *versionInterval
+			for _, e := range events {
+				switch {
+				case e.Introduced != "":
+					if open == nil {
+						open = &versionInterval{lowerIncl: true}
+						if e.Introduced != "0" {
+							open.lower = e.Introduced
+						}
+					}
+				case e.Fixed != "" && open != nil:
+					open.upper = e.Fixed
+					intervals = append(intervals, *open)
+					open = nil
+				case e.LastAffected != "" && open != nil:
+					open.upper, open.upperIncl = e.LastAffected, true
+					intervals = append(intervals, *open)
+					open = nil
+				}
+			}
+			if open != nil {
+				intervals = append(intervals, *open)
+			}
+		}
+	}
+	return intervals
+}
+
+func eventVersion(e osv.Event) string {
+	switch {
+	case e.Introduced != "":
+		return e.Introduced
+	case e.Fixed != "":
+		return e.Fixed
+	}
+	return e.LastAffected
+}
+
+// analyzeVersionRange intersects each finding's affected intervals with the requested range.
+// It returns the analysis and the findings that affect some version inside the range.
+func analyzeVersionRange(rng versionInterval, raw string, findings []Finding, pkg string) (*RangeAnalysis, []Finding) {
+	kept := []Finding{}
+	var (
+		overlaps   []versionInterval
+		candidates []string
+		all        []versionInterval
+	)
+	if rng.lower != "" && rng.lowerIncl {
+		candidates = append(candidates, rng.lower)
+	}
+	for _, f := range findings {
+		intervals := affectedIntervals(f.Vulnerability, pkg)
+		all = append(all, intervals...)
+		hit := false
+		for _, iv := range intervals {
+			if overlap, ok := rng.intersect(iv); ok {
+				overlaps = append(overlaps, overlap)
+				hit = true
+			}
+			if iv.upper != "" && !iv.upperIncl {
+				candidates = append(candidates, iv.upper)
+			}
+		}
+		if hit {
+			kept = append(kept, f)
+		}
+	}
+
+	analysis := &RangeAnalysis{Range: raw, VulnerableRanges: []string{}}
+	for _, iv := range mergeIntervals(overlaps) {
+		analysis.VulnerableRanges = append(analysis.VulnerableRanges, iv.String())
+	}
+
+	// The earliest safe version is the lowest candidate at or above the range's lower bound
+	// that no affected interval contains
+	sort.Slice(candidates, func(i, j int) bool {
+		return depsdev.CompareVersions(candidates[i], candidates[j]) < 0
+	})
+	above := versionInterval{lower: rng.lower, lowerIncl: rng.lowerIncl}
+candidates:
+	for _, v := range candidates {
+		if !above.contains(v) {
+			continue
+		}
+		for _, iv := range all {
+			if iv.contains(v) {
+				continue candidates
+			}
+		}
+		analysis.EarliestSafeVersion = v
+		analysis.SafeWithinRange = rng.contains(v)
+		break
+	}
+
+	return analysis, kept
+}
+
+// mergeIntervals unions overlapping or touching intervals
+func mergeIntervals(intervals []versionInterval) []versionInterval {
+	sort.Slice(intervals, func(i, j int) bool {
+		a, b := intervals[i], intervals[j]
+		if a.lower == "" || b.lower == "" {
+			return a.lower == "" && b.lower != ""
+		}
+		return depsdev.CompareVersions(a.lower, b.lower) < 0
+	})
+
+	var merged []versionInterval
+	for _, iv := range intervals {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			if last.upper == "" {
+				continue
+			}
+			c := depsdev.CompareVersions(iv.lower, last.upper)
+			if iv.lower == "" || c < 0 || (c == 0 && (iv.lowerIncl || last.upperIncl)) {
+				if u := depsdev.CompareVersions(iv.upper, last.upper); iv.upper == "" || u > 0 || (u == 0 && iv.upperIncl) {
+					last.upper, last.upperIncl = iv.upper, iv.upperIncl
+				}
+				continue
+			}
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
@@ -0,0 +1,119 @@
+package tools
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+)
+
+func TestHandleVulns_VersionRange(t *testing.T) {
+	affected := func(events ...osv.Event) []osv.Affected {
+		return []osv.Affected{{
+			Package: osv.Package{Name: "lodash", Ecosystem: "npm"},
+			Ranges:  []osv.VersionRange{{Type: "SEMVER", Events: events}},
+		}}
+	}
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/lodash": {
+			{ID: "GHSA-prototype", Affected: affected(osv.Event{Introduced: "4.0.0"}, osv.Event{Fixed: "4.17.12"})},
+			{ID: "GHSA-command", Affected: affected(osv.Event{Introduced: "0"}, osv.Event{Fixed: "4.17.21"})},
+			{ID: "GHSA-legacy", Affected: affected(osv.Event{Introduced: "3.0.0"}, osv.Event{Fixed: "3.10.0"})},
+		},
+	})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	tests := []struct {
+		name       string
+		rng        string
+		ids        []string
+		vulnerable []string
+		safe       string
+		within     bool
+	}{
+		{
+			name:       "range straddles the last fix",
+			rng:        ">=4.17.0 <4.18.0",
+			ids:        []string{"GHSA-command", "GHSA-prototype"},
+			vulnerable: []string{">=4.17.0 <4.17.21"},
+			safe:       "4.17.21",
+			within:     true,
+		},
+		{
+			name:       "fix lies after the range",
+			rng:        ">=4.0.0, <4.17.0",
+			ids:        []string{"GHSA-command", "GHSA-prototype"},
+			vulnerable: []string{">=4.0.0 <4.17.0"},
+			safe:       "4.17.21",
+			within:     false,
+		},
+		{
+			name:       "range already safe",
+			rng:        ">=4.17.21",
+			ids:        nil,
+			vulnerable: []string{},
+			safe:       "4.17.21",
+			within:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := registry.HandleVulns(context.Background(), VulnsInput{
+				Ecosystem:    "npm",
+				Package:      "lodash",
+				VersionRange: tt.rng,
+				SortBy:       SortByID,
+			})
+			if err != nil {
+				t.Fatalf("HandleVulns() error = %v", err)
+			}
+
+			var ids []string
+			for _, f := range result.Vulnerabilities {
+				ids = append(ids, f.ID)
+			}
+			if !slices.Equal(ids, tt.ids) || result.VulnerabilityCount != len(tt.ids) {
+				t.Errorf("findings = %v, want %v", ids, tt.ids)
+			}
+
+			analysis := result.VersionRange
+			if analysis == nil {
+				t.Fatal("expected a version_range analysis")
+			}
+			if !slices.Equal(analysis.VulnerableRanges, tt.vulnerable) {
+				t.Errorf("vulnerable_ranges = %v, want %v", analysis.VulnerableRanges, tt.vulnerable)
+			}
+			if analysis.EarliestSafeVersion != tt.safe || analysis.SafeWithinRange != tt.within {
+				t.Errorf("earliest safe = %q (within %v), want %q (within %v)",
+					analysis.EarliestSafeVersion, analysis.SafeWithinRange, tt.safe, tt.within)
+			}
+		})
+	}
+
+	if _, err := registry.HandleVulns(context.Background(), VulnsInput{
+		Ecosystem: "npm", Package: "lodash", Version: "4.17.19", VersionRange: ">=4.0.0",
+	}); err == nil {
+		t.Error("expected an error when both version and version_range are set")
+	}
+}
+
+func TestParseVersionRange(t *testing.T) {
+	r, err := parseVersionRange(">= 1.2.0 <2")
+	if err != nil {
+		t.Fatalf("parseVersionRange() error = %v", err)
+	}
+	if got := r.String(); got != ">=1.2.0 <2" {
+		t.Errorf("String() = %q, want >=1.2.0 <2", got)
+	}
+	if !r.contains("1.9.9") || r.contains("2.0.0") || r.contains("1.1.0") {
+		t.Errorf("range %v has the wrong bounds", r)
+	}
+
+	for _, bad := range []string{"", ">=2.0.0 <1.0.0"} {
+		if _, err := parseVersionRange(bad); err == nil {
+			t.Errorf("parseVersionRange(%q) expected an error", bad)
+		}
+	}
+}
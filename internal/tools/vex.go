@@ -0,0 +1,287 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/pool"
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"go.uber.org/zap"
+)
+
+// OpenVEXContext is the OpenVEX specification version documents are written against
+const OpenVEXContext = "https://openvex.dev/ns/v0.2.0"
+
+// vexConcurrency bounds how many packages deps.vex looks up at once
+const vexConcurrency = 8
+
+// OpenVEX statement statuses
+const (
+	VEXStatusAffected           = "affected"
+	VEXStatusNotAffected        = "not_affected"
+	VEXStatusFixed              = "fixed"
+	VEXStatusUnderInvestigation = "under_investigation"
+)
+
+// VEXJustificationCodeNotPresent is the OpenVEX justification for a version that predates
+// the vulnerable code
+const VEXJustificationCodeNotPresent = "vulnerable_code_not_present"
+
+// purlTypes maps OSV ecosystems to their package URL types
+var purlTypes = map[string]string{
+	"npm":              "npm",
+	"PyPI":             "pypi",
+	"Go":               "golang",
+	"Maven":            "maven",
+	"crates.io":        "cargo",
+	osv.EcosystemNuGet: "nuget",
+	"RubyGems":         "gem",
+	"Packagist":        "composer",
+}
+
+// VEXComponent is one pinned package a VEX document makes statements about
+type VEXComponent struct {
+	Ecosystem string `json:"ecosystem"`
+	Package   string `json:"package"`
+	Version   string `json:"version"`
+}
+
+// VEXInput defines input for deps.vex tool
+type VEXInput struct {
+	Components []VEXComponent `json:"components"`
+	// Author defaults to "PackagePulse"
+	Author string `json:"author,omitempty"`
+}
+
+// VEXDocument is an OpenVEX document
+type VEXDocument struct {
+	Context    string         `json:"@context"`
+	ID         string         `json:"@id"`
+	Author     string         `json:"author"`
+	Timestamp  time.Time      `json:"timestamp"`
+	Version    int            `json:"version"`
+	Tooling    string         `json:"tooling,omitempty"`
+	Statements []VEXStatement `json:"statements"`
+}
+
+// VEXVulnerability names the vulnerability a statement is about
+type VEXVulnerability struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// VEXProduct identifies a component by its package URL
+type VEXProduct struct {
+	ID          string            `json:"@id"`
+	Identifiers map[string]string `json:"identifiers,omitempty"`
+}
+
+// VEXStatement asserts the status of one vulnerability in one component
+type VEXStatement struct {
+	Vulnerability   VEXVulnerability `json:"vulnerability"`
+	Products        []VEXProduct     `json:"products"`
+	Status          string           `json:"status"`
+	Justification   string           `json:"justification,omitempty"`
+	StatusNotes     string           `json:"status_notes,omitempty"`
+	ActionStatement string           `json:"action_statement,omitempty"`
+}
+
+// HandleVEX builds an OpenVEX document for a set of components. Every advisory OSV (and GHSA,
+// when enabled) records for a component's package gets a statement, with the status derived
+// from where the component's version falls in the advisory's affected ranges.
+// The document is only returned when every component could be looked up, so it never omits
+// a component silently.
+// Example: {"components": [{"ecosystem": "npm", "package": "lodash", "version": "4.17.19"}]}
+func (tr *ToolRegistry) HandleVEX(ctx context.Context, input VEXInput) (*VEXDocument, error) {
+	if len(input.Components) == 0 {
+		return nil, fmt.Errorf("%w: components is required", errInvalidInput)
+	}
+	components := make([]VEXComponent, len(input.Components))
+	for i, c := range input.Components {
+		if strings.TrimSpace(c.Package) == "" || strings.TrimSpace(c.Version) == "" {
+			return nil, fmt.Errorf("%w: components[%d]: package and version are required", errInvalidInput, i)
+		}
+		ecosystem, err := validateEcosystem(c.Ecosystem)
+		if err != nil {
+			return nil, fmt.Errorf("components[%d]: %w", i, err)
+		}
+		components[i] = VEXComponent{
+			Ecosystem: ecosystem,
+			Package:   strings.TrimSpace(c.Package),
+			Version:   strings.TrimSpace(c.Version),
+		}
+	}
+
+	// Advisories are per package, so versions of one package share a lookup
+	var packages []VulnsInput
+	seen := make(map[string]bool)
+	for _, c := range components {
+		key := cacheKey("vex", c.Ecosystem, c.Package)
+		if !seen[key] {
+			seen[key] = true
+			packages = append(packages, VulnsInput{Ecosystem: c.Ecosystem, Package: c.Package})
+		}
+	}
+
+	tr.log(ctx).Info("Handling VEX document",
+		zap.Int("components", len(components)),
+		zap.Int("packages", len(packages)))
+
+	var done atomic.Int64
+	lookups, err := pool.Map(ctx, packages, vexConcurrency, func(ctx context.Context, pkg VulnsInput) ([]Finding, error) {
+		findings, err := tr.allFindings(ctx, pkg)
+		reportProgress(ctx, int(done.Add(1)), len(packages))
+		return findings, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	advisories := make(map[string][]Finding, len(packages))
+	var failed []string
+	for i, result := range lookups {
+		key := cacheKey("vex", packages[i].Ecosystem, packages[i].Package)
+		if result.Err != nil {
+			failed = append(failed, fmt.Sprintf("%s/%s: %v", packages[i].Ecosystem, packages[i].Package, result.Err))
+			continue
+		}
+		advisories[key] = result.Value
+	}
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("could not look up %d packages: %s", len(failed), strings.Join(failed, "; "))
+	}
+
+	statements := []VEXStatement{}
+	for _, c := range components {
+		findings := advisories[cacheKey("vex", c.Ecosystem, c.Package)]
+		purl := packageURL(c.Ecosystem, c.Package, c.Version)
+		for _, f := range findings {
+			statement := vexStatement(f.Vulnerability, c.Package, c.Version, depsdev.SchemeFor(c.Ecosystem))
+			statement.Products = []VEXProduct{{ID: purl, Identifiers: map[string]string{"purl": purl}}}
+			statements = append(statements, statement)
+		}
+	}
+
+	author := input.Author
+	if author == "" {
+		author = "PackagePulse"
+	}
+	return &VEXDocument{
+		Context:    OpenVEXContext,
+		ID:         vexDocumentID(statements),
+		Author:     author,
+		Timestamp:  time.Now().UTC().Truncate(time.Second),
+		Version:    1,
+		Tooling:    "PackagePulse",
+		Statements: statements,
+	}, nil
+}
+
+// allFindings returns every advisory deps.vulns knows for a package, following its pages,
+// in ID order
+func (tr *ToolRegistry) allFindings(ctx context.Context, input VulnsInput) ([]Finding, error) {
+	input.Limit = MaxVulnsLimit
+	var findings []Finding
+	for {
+		page, err := tr.HandleVulns(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, page.Vulnerabilities...)
+		if page.Pagination == nil || !page.Pagination.HasMore {
+			break
+		}
+		input.Offset += len(page.Vulnerabilities)
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].ID < findings[j].ID })
+	return findings, nil
+}
+
+// vexStatement derives a component version's status for a vulnerability. A version inside
+// an affected range is affected; one at or past a range's fix, or beyond its last affected
+// version, is fixed; and one no range reaches is not affected because it predates the
+// vulnerable code. A version that cannot be placed, such as a commit hash, is under
+// investigation.
+func vexStatement(vuln osv.Vulnerability, pkg, version string, scheme depsdev.VersionScheme) VEXStatement {
+	statement := VEXStatement{
+		Vulnerability: VEXVulnerability{Name: vuln.ID, Aliases: vuln.Aliases},
+	}
+
+	intervals := affectedIntervals(vuln, pkg)
+	switch osv.IsVersionAffected(vuln, pkg, version, scheme.Compare) {
+	case osv.StatusAffected:
+		statement.Status = VEXStatusAffected
+		statement.ActionStatement = "No fixed version is available"
+		for _, iv := range intervals {
+			if iv.contains(version) && iv.upper != "" && !iv.upperIncl {
+				statement.ActionStatement = fmt.Sprintf("Upgrade to %s or later", iv.upper)
+				break
+			}
+		}
+	case osv.StatusPossiblyAffected:
+		statement.Status = VEXStatusUnderInvestigation
+		statement.StatusNotes = "The version could not be placed against the advisory's affected ranges"
+	default:
+		statement.Status = VEXStatusNotAffected
+		statement.Justification = VEXJustificationCodeNotPresent
+		for _, iv := range intervals {
+			if iv.upper == "" {
+				continue
+			}
+			c := scheme.Compare(version, iv.upper)
+			if c > 0 || (c == 0 && !iv.upperIncl) {
+				statement.Status = VEXStatusFixed
+				statement.Justification = ""
+				statement.StatusNotes = fmt.Sprintf("Fixed in %s", iv.upper)
+				if iv.upperIncl {
+					statement.StatusNotes = fmt.Sprintf("Last affected version is %s", iv.upper)
+				}
+				break
+			}
+		}
+	}
+	return statement
+}
+
+// packageURL builds the package URL (purl) of a package version in an OSV ecosystem
+func packageURL(ecosystem, name, version string) string {
+	purlType := purlTypes[ecosystem]
+	if purlType == "" {
+		purlType = strings.ToLower(ecosystem)
+	}
+
+	switch ecosystem {
+	case "Maven":
+		// group:artifact becomes the namespace and name
+		name = strings.Replace(name, ":", "/", 1)
+	case "PyPI":
+		name = strings.ReplaceAll(strings.ToLower(name), "_", "-")
+	}
+
+	segments := strings.Split(name, "/")
+	for i, s := range segments {
+		segments[i] = purlEscape(s)
+	}
+	return "pkg:" + purlType + "/" + strings.Join(segments, "/") + "@" + purlEscape(version)
+}
+
+// purlEscape percent-encodes a purl segment; "@" separates the version, so it is encoded too
+func purlEscape(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "@", "%40")
+}
+
+// vexDocumentID derives a stable document IRI from the statements, so identical results get
+// the same ID
+func vexDocumentID(statements []VEXStatement) string {
+	data, _ := json.Marshal(statements)
+	sum := sha256.Sum256(data)
+	return "https://openvex.dev/docs/public/vex-" + hex.EncodeToString(sum[:])
+}
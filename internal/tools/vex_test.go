@@ -0,0 +1,138 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+)
+
+// rangedVuln affects pkg over the given events, as one ECOSYSTEM range
+func rangedVuln(id, pkg string, events ...osv.Event) osv.Vulnerability {
+	return osv.Vulnerability{
+		ID: id,
+		Affected: []osv.Affected{{
+			Package: osv.Package{Name: pkg, Ecosystem: "npm"},
+			Ranges:  []osv.VersionRange{{Type: "ECOSYSTEM", Events: events}},
+		}},
+	}
+}
+
+func TestVEXStatement(t *testing.T) {
+	fixedIn2 := rangedVuln("GHSA-fix2", "widget", osv.Event{Introduced: "1.0.0"}, osv.Event{Fixed: "2.0.0"})
+	lastAffected := rangedVuln("GHSA-last", "widget", osv.Event{Introduced: "0"}, osv.Event{LastAffected: "1.5.0"})
+	unfixed := rangedVuln("GHSA-open", "widget", osv.Event{Introduced: "1.0.0"})
+
+	tests := []struct {
+		name          string
+		vuln          osv.Vulnerability
+		version       string
+		status        string
+		justification string
+		notes         string
+		action        string
+	}{
+		{"inside range", fixedIn2, "1.2.0", VEXStatusAffected, "", "", "Upgrade to 2.0.0 or later"},
+		{"at the fix", fixedIn2, "2.0.0", VEXStatusFixed, "", "Fixed in 2.0.0", ""},
+		{"past the fix", fixedIn2, "2.3.1", VEXStatusFixed, "", "Fixed in 2.0.0", ""},
+		{"before introduction", fixedIn2, "0.9.0", VEXStatusNotAffected, VEXJustificationCodeNotPresent, "", ""},
+		{"at last affected", lastAffected, "1.5.0", VEXStatusAffected, "", "", "No fixed version is available"},
+		{"past last affected", lastAffected, "1.6.0", VEXStatusFixed, "", "Last affected version is 1.5.0", ""},
+		{"no fix", unfixed, "3.0.0", VEXStatusAffected, "", "", "No fixed version is available"},
+		{"commit hash", fixedIn2, "a1b2c3d4e5f6", VEXStatusUnderInvestigation, "", "The version could not be placed against the advisory's affected ranges", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := vexStatement(tt.vuln, "widget", tt.version, depsdev.SemverScheme)
+			if got.Status != tt.status || got.Justification != tt.justification ||
+				got.StatusNotes != tt.notes || got.ActionStatement != tt.action {
+				t.Errorf("vexStatement(%s) = {%s %q %q %q}, want {%s %q %q %q}", tt.version,
+					got.Status, got.Justification, got.StatusNotes, got.ActionStatement,
+					tt.status, tt.justification, tt.notes, tt.action)
+			}
+		})
+	}
+}
+
+func TestPackageURL(t *testing.T) {
+	tests := []struct {
+		ecosystem, name, version, want string
+	}{
+		{"npm", "lodash", "4.17.19", "pkg:npm/lodash@4.17.19"},
+		{"npm", "@babel/core", "7.0.0", "pkg:npm/%40babel/core@7.0.0"},
+		{"PyPI", "Django_Utils", "1.0", "pkg:pypi/django-utils@1.0"},
+		{"Maven", "org.apache.logging.log4j:log4j-core", "2.14.1", "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1"},
+		{"Go", "github.com/gin-gonic/gin", "v1.9.0", "pkg:golang/github.com/gin-gonic/gin@v1.9.0"},
+		{"crates.io", "serde", "1.0.188", "pkg:cargo/serde@1.0.188"},
+	}
+	for _, tt := range tests {
+		if got := packageURL(tt.ecosystem, tt.name, tt.version); got != tt.want {
+			t.Errorf("packageURL(%s, %s, %s) = %s, want %s", tt.ecosystem, tt.name, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestHandleVEX(t *testing.T) {
+	mock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/widget": {
+			rangedVuln("GHSA-bbbb", "widget", osv.Event{Introduced: "1.0.0"}, osv.Event{Fixed: "2.0.0"}),
+			rangedVuln("GHSA-aaaa", "widget", osv.Event{Introduced: "3.0.0"}, osv.Event{Fixed: "3.1.0"}),
+		},
+	})
+	registry := newTestRegistry(t)
+	registry.osvClient = mock.client()
+
+	doc, err := registry.HandleVEX(context.Background(), VEXInput{Components: []VEXComponent{
+		{Ecosystem: "npm", Package: "widget", Version: "1.4.0"},
+		{Ecosystem: "NPM", Package: "widget", Version: "2.1.0"},
+	}})
+	if err != nil {
+		t.Fatalf("HandleVEX() error = %v", err)
+	}
+
+	// The serialized document carries the OpenVEX required fields
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("marshal VEX: %v", err)
+	}
+	var shape map[string]interface{}
+	if err := json.Unmarshal(data, &shape); err != nil {
+		t.Fatalf("unmarshal VEX: %v", err)
+	}
+	for _, field := range []string{"@context", "@id", "author", "timestamp", "version", "statements"} {
+		if _, ok := shape[field]; !ok {
+			t.Errorf("VEX document missing %q", field)
+		}
+	}
+	if doc.Context != OpenVEXContext || !strings.HasPrefix(doc.ID, "https://openvex.dev/docs/public/vex-") {
+		t.Errorf("@context/@id = %s/%s", doc.Context, doc.ID)
+	}
+
+	// One statement per component and advisory, advisories in ID order
+	want := []struct{ product, vuln, status string }{
+		{"pkg:npm/widget@1.4.0", "GHSA-aaaa", VEXStatusNotAffected},
+		{"pkg:npm/widget@1.4.0", "GHSA-bbbb", VEXStatusAffected},
+		{"pkg:npm/widget@2.1.0", "GHSA-aaaa", VEXStatusNotAffected},
+		{"pkg:npm/widget@2.1.0", "GHSA-bbbb", VEXStatusFixed},
+	}
+	if len(doc.Statements) != len(want) {
+		t.Fatalf("got %d statements, want %d", len(doc.Statements), len(want))
+	}
+	for i, w := range want {
+		s := doc.Statements[i]
+		if len(s.Products) != 1 || s.Products[0].ID != w.product || s.Vulnerability.Name != w.vuln || s.Status != w.status {
+			t.Errorf("statement %d = %+v, want %s %s %s", i, s, w.product, w.vuln, w.status)
+		}
+	}
+	// Both components share one package lookup
+	if n := mock.requests.Load(); n != 1 {
+		t.Errorf("OSV requests = %d, want 1", n)
+	}
+
+	if _, err := registry.HandleVEX(context.Background(), VEXInput{Components: []VEXComponent{{Ecosystem: "npm", Package: "widget"}}}); err == nil {
+		t.Error("HandleVEX() without a version succeeded, want an error")
+	}
+}
@@ -0,0 +1,171 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/breaker"
+	"github.com/rayprogramming/PackagePulse/internal/probes"
+	"go.uber.org/zap"
+)
+
+// DefaultWatchlistInterval is how often watchlisted packages are re-scanned
+const DefaultWatchlistInterval = time.Hour
+
+// watchlistSpacing paces consecutive watchlist scans so a refresh never bursts the upstreams
+const watchlistSpacing = 250 * time.Millisecond
+
+// WatchlistEntry is a package kept warm in the cache by the background refresh
+type WatchlistEntry struct {
+	Ecosystem string `json:"ecosystem" yaml:"ecosystem"`
+	Package   string `json:"package" yaml:"package"`
+	// Version scopes the scan like deps.vulns' version; empty scans every version
+	Version string `json:"version,omitempty" yaml:"version"`
+}
+
+// validateWatchlist checks that every entry names a supported ecosystem and a package
+func validateWatchlist(entries []WatchlistEntry) error {
+	for i, e := range entries {
+		if strings.TrimSpace(e.Package) == "" {
+			return fmt.Errorf("watchlist[%d]: package is required", i)
+		}
+		if _, err := validateEcosystem(e.Ecosystem); err != nil {
+			return fmt.Errorf("watchlist[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// watchlist tracks the last refresh of each watchlisted package
+type watchlist struct {
+	mu    sync.Mutex
+	items []probes.WatchlistItem
+}
+
+func newWatchlist(entries []WatchlistEntry) *watchlist {
+	w := &watchlist{items: make([]probes.WatchlistItem, len(entries))}
+	for i, e := range entries {
+		ecosystem, _ := validateEcosystem(e.Ecosystem)
+		w.items[i] = probes.WatchlistItem{
+			Ecosystem: ecosystem,
+			Package:   strings.TrimSpace(e.Package),
+			Version:   strings.TrimSpace(e.Version),
+		}
+	}
+	return w
+}
+
+// record notes the outcome of one item's refresh; a failure keeps the last successful time
+func (w *watchlist) record(i int, at time.Time, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		w.items[i].Error = err.Error()
+		return
+	}
+	w.items[i].LastRefresh = &at
+	w.items[i].Error = ""
+}
+
+// WatchlistStatus returns the refresh state of every watchlisted package, for /healthz
+func (tr *ToolRegistry) WatchlistStatus() []probes.WatchlistItem {
+	tr.watchlist.mu.Lock()
+	defer tr.watchlist.mu.Unlock()
+	items := slices.Clone(tr.watchlist.items)
+	for i, item := range items {
+		if item.LastRefresh != nil {
+			refreshed := *item.LastRefresh
+			items[i].LastRefresh = &refreshed
+		}
+	}
+	return items
+}
+
+// RunWatchlist scans every watchlisted package now and then every WatchlistInterval, until
+// ctx is cancelled. It returns immediately when the watchlist is empty.
+func (tr *ToolRegistry) RunWatchlist(ctx context.Context) {
+	if len(tr.watchlist.items) == 0 {
+		return
+	}
+	ticker := time.NewTicker(tr.config.WatchlistInterval)
+	defer ticker.Stop()
+	for {
+		tr.refreshWatchlist(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshWatchlist re-scans each watchlisted package in turn, bypassing cached results and
+// caching fresh ones until the next refresh is due. It stops early when ctx is cancelled or an
+// upstream's circuit opens, leaving the remaining items for the next cycle.
+func (tr *ToolRegistry) refreshWatchlist(ctx context.Context) {
+	// Entries outlive a late or failed refresh, so agents keep getting cached answers
+	ctx = withCacheRefresh(ctx, 2*tr.config.WatchlistInterval)
+
+	for i := range tr.watchlist.items {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(watchlistSpacing):
+			}
+		}
+		if ctx.Err() != nil || tr.upstreamOpen() {
+			return
+		}
+
+		item := tr.watchlist.items[i]
+		_, err := tr.HandleVulns(ctx, VulnsInput{Ecosystem: item.Ecosystem, Package: item.Package, Version: item.Version})
+		if err == nil {
+			_, err = tr.packageHealth(ctx, item.Ecosystem, item.Package, item.Version)
+		}
+		if err != nil {
+			tr.logger.Warn("watchlist refresh failed",
+				zap.String("ecosystem", item.Ecosystem),
+				zap.String("package", item.Package),
+				zap.Error(err))
+		}
+		tr.watchlist.record(i, time.Now().UTC(), err)
+	}
+}
+
+// upstreamOpen reports whether any upstream's circuit breaker is open
+func (tr *ToolRegistry) upstreamOpen() bool {
+	for _, b := range tr.breakers {
+		if b.Status().State == breaker.StateOpen {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheRefreshKey marks a context whose lookups re-fetch instead of reading the cache
+type cacheRefreshKey struct{}
+
+// withCacheRefresh makes lookups under ctx skip cached results and cache fresh ones for at
+// least ttl
+func withCacheRefresh(ctx context.Context, ttl time.Duration) context.Context {
+	return context.WithValue(ctx, cacheRefreshKey{}, ttl)
+}
+
+// cacheRefreshing reports whether lookups under ctx should bypass cached results
+func cacheRefreshing(ctx context.Context) bool {
+	_, ok := ctx.Value(cacheRefreshKey{}).(time.Duration)
+	return ok
+}
+
+// cacheTTL returns how long to cache a result normally kept for ttl, extended for a refresh
+func cacheTTL(ctx context.Context, ttl time.Duration) time.Duration {
+	if refresh, ok := ctx.Value(cacheRefreshKey{}).(time.Duration); ok {
+		return max(ttl, refresh)
+	}
+	return ttl
+}
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
+	"go.uber.org/zap"
+)
+
+func TestRunWatchlist(t *testing.T) {
+	osvMock := newMockOSV(t, map[string][]osv.Vulnerability{
+		"npm/lodash@4.17.19": {{ID: "GHSA-p6mc-m468-83gw"}},
+	})
+	depsDevMock := newMockDepsDev(t, map[string]*depsdev.PackageInfo{
+		"npm/lodash": {
+			PackageKey: depsdev.PackageKey{System: "NPM", Name: "lodash"},
+			Versions: []depsdev.VersionInfo{
+				{VersionKey: depsdev.VersionKey{Version: "4.17.19"}, PublishedAt: time.Now().Add(-24 * time.Hour), IsDefault: true},
+			},
+		},
+	})
+
+	cfg := DefaultConfig()
+	cfg.Watchlist = []WatchlistEntry{{Ecosystem: "NPM", Package: "lodash", Version: "4.17.19"}}
+	registry, err := NewToolRegistryWithConfig(zap.NewNop(), newTestRegistry(t).cache, cfg)
+	if err != nil {
+		t.Fatalf("NewToolRegistryWithConfig() error = %v", err)
+	}
+	registry.osvClient = osvMock.client()
+	registry.depsDevClient = depsDevMock.client()
+	registry.epssClient = newMockEPSS(t)
+	registry.kevClient = newMockKEV(t)
+
+	if status := registry.WatchlistStatus(); len(status) != 1 || status[0].Ecosystem != "npm" || status[0].LastRefresh != nil {
+		t.Fatalf("WatchlistStatus() before a refresh = %+v, want one unrefreshed npm entry", status)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		registry.RunWatchlist(ctx)
+		close(done)
+	}()
+
+	// The first refresh runs immediately; cache writes land asynchronously
+	keys := []string{
+		cacheKey("vulns", "npm", "lodash", "4.17.19", "", "", "", "0", "false"),
+		cacheKey("health", "npm", "lodash", "4.17.19"),
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for _, key := range keys {
+		for {
+			if _, ok := registry.cache.Get(key); ok {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("cache entry %q was never written", key)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("RunWatchlist did not return after cancellation")
+	}
+
+	status := registry.WatchlistStatus()
+	if status[0].LastRefresh == nil || status[0].Error != "" {
+		t.Errorf("WatchlistStatus() = %+v, want a successful refresh", status[0])
+	}
+
+	// An agent call for the watchlisted package is served from the cache
+	before := osvMock.requests.Load()
+	if _, err := registry.HandleVulns(context.Background(), VulnsInput{Ecosystem: "npm", Package: "lodash", Version: "4.17.19"}); err != nil {
+		t.Fatalf("HandleVulns() error = %v", err)
+	}
+	if n := osvMock.requests.Load(); n != before {
+		t.Errorf("OSV requests = %d, want %d (cache hit)", n, before)
+	}
+}
@@ -0,0 +1,160 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/PackagePulse/internal/history"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName names the instrumentation scope of every span PackagePulse emits
+const ScopeName = "github.com/rayprogramming/PackagePulse"
+
+// Span attributes beyond the OpenTelemetry semantic conventions
+const (
+	AttrTool      = attribute.Key("mcp.tool.name")
+	AttrEcosystem = attribute.Key("packagepulse.ecosystem")
+	AttrPackage   = attribute.Key("packagepulse.package")
+	AttrVersion   = attribute.Key("packagepulse.version")
+	// AttrCache is the call's response-cache outcome: hit, miss, or partial
+	AttrCache = attribute.Key("packagepulse.cache")
+	// AttrUpstream names the upstream API a request went to, such as osv or deps.dev
+	AttrUpstream = attribute.Key("packagepulse.upstream")
+	// AttrCountPrefix prefixes the result's top-level counts, e.g.
+	// packagepulse.vulnerability_count
+	AttrCountPrefix = "packagepulse."
+)
+
+// NewProvider creates a tracer provider batching spans to the OTLP/HTTP collector at endpoint,
+// a URL such as http://localhost:4318. Shut it down to flush the spans still queued.
+func NewProvider(ctx context.Context, endpoint, version string) (*sdktrace.TracerProvider, error) {
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("create OTLP exporter: %w", err)
+	}
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName("PackagePulse"),
+		semconv.ServiceVersion(version))
+	return sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res)), nil
+}
+
+// Middleware starts a span for every tool call, named "tools/call <tool>", carrying the call's
+// ecosystem, package, and version arguments and, once the handler returns, its cache outcome
+// and result counts. The span travels in the handler's context, so upstream requests made
+// with it become child spans. Install it inside history.Middleware so the cache outcome can
+// be read.
+func Middleware(tp trace.TracerProvider) mcp.Middleware {
+	tracer := tp.Tracer(ScopeName)
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if method != "tools/call" || !ok || call.Params == nil {
+				return next(ctx, method, req)
+			}
+
+			ctx, span := tracer.Start(ctx, "tools/call "+call.Params.Name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(append([]attribute.KeyValue{AttrTool.String(call.Params.Name)}, callAttributes(call.Params.Arguments)...)...))
+			defer span.End()
+
+			result, err := next(ctx, method, req)
+
+			if cache := history.CacheOutcome(ctx); cache != "" {
+				span.SetAttributes(AttrCache.String(cache))
+			}
+			for key, n := range history.ResultCounts(result) {
+				span.SetAttributes(attribute.Int64(AttrCountPrefix+key, n))
+			}
+			switch res, _ := result.(*mcp.CallToolResult); {
+			case err != nil:
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			case res != nil && res.IsError:
+				span.SetStatus(codes.Error, "tool returned an error result")
+			}
+			return result, err
+		}
+	}
+}
+
+// callAttributes picks the package coordinates out of a call's arguments
+func callAttributes(raw json.RawMessage) []attribute.KeyValue {
+	var args struct {
+		Ecosystem      string `json:"ecosystem"`
+		Package        string `json:"package"`
+		Version        string `json:"version"`
+		CurrentVersion string `json:"current_version"`
+	}
+	if json.Unmarshal(raw, &args) != nil {
+		return nil
+	}
+	var attrs []attribute.KeyValue
+	if args.Ecosystem != "" {
+		attrs = append(attrs, AttrEcosystem.String(args.Ecosystem))
+	}
+	if args.Package != "" {
+		attrs = append(attrs, AttrPackage.String(args.Package))
+	}
+	// deps.upgrade_plan names the version it starts from current_version
+	version := args.Version
+	if version == "" {
+		version = args.CurrentVersion
+	}
+	if version != "" {
+		attrs = append(attrs, AttrVersion.String(version))
+	}
+	return attrs
+}
+
+// Transport wraps next (http.DefaultTransport when nil) so every request gets a client span
+// under the span in its context, named for the method and tagged with upstream. A nil tp
+// returns next unchanged.
+func Transport(tp trace.TracerProvider, upstream string, next http.RoundTripper) http.RoundTripper {
+	if tp == nil {
+		return next
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{tracer: tp.Tracer(ScopeName), upstream: upstream, next: next}
+}
+
+type transport struct {
+	tracer   trace.Tracer
+	upstream string
+	next     http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := t.tracer.Start(req.Context(), req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			AttrUpstream.String(t.upstream),
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.URLFull(req.URL.String()),
+			semconv.ServerAddress(req.URL.Hostname())))
+	defer span.End()
+
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, strings.TrimSpace(resp.Status))
+	}
+	return resp, nil
+}
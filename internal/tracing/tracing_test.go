@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTransport_NilProviderPassesThrough(t *testing.T) {
+	if rt := Transport(nil, "osv", http.DefaultTransport); rt != http.DefaultTransport {
+		t.Errorf("Transport(nil) = %T, want the wrapped transport unchanged", rt)
+	}
+}
+
+func TestTransport_ChildSpanPerRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	client := &http.Client{Transport: Transport(tp, "deps.dev", nil)}
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	for _, path := range []string{"/ok", "/broken"} {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		resp.Body.Close()
+	}
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want two requests and the parent", len(spans))
+	}
+	for i, wantStatus := range []codes.Code{codes.Unset, codes.Error} {
+		span := spans[i]
+		if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("request %d span is not a child of the parent span", i)
+		}
+		attrs := attribute.NewSet(span.Attributes...)
+		if upstream, _ := attrs.Value(AttrUpstream); upstream.AsString() != "deps.dev" {
+			t.Errorf("request %d upstream = %q, want deps.dev", i, upstream.AsString())
+		}
+		if span.Status.Code != wantStatus {
+			t.Errorf("request %d status = %v, want %v", i, span.Status.Code, wantStatus)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package useragent
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Default identifies PackagePulse when no version is known
+const Default = "PackagePulse"
+
+// String builds the User-Agent sent to upstream APIs, "PackagePulse/<version> (+<contact>)",
+// leaving out the version or contact when empty. Upstreams use the contact to reach the
+// operator instead of blocking traffic they cannot attribute.
+func String(version, contact string) string {
+	ua := Default
+	if version = strings.TrimSpace(version); version != "" {
+		ua += "/" + version
+	}
+	if contact = strings.TrimSpace(contact); contact != "" {
+		ua += " (+" + contact + ")"
+	}
+	return ua
+}
+
+// Transport wraps next (http.DefaultTransport when nil) so every request it sends carries ua
+// as its User-Agent
+func Transport(ua string, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{userAgent: ua, next: next}
+}
+
+type transport struct {
+	userAgent string
+	next      http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,50 @@
+package useragent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		version, contact string
+		want             string
+	}{
+		{"1.0.0", "", "PackagePulse/1.0.0"},
+		{"1.0.0", "ops@example.com", "PackagePulse/1.0.0 (+ops@example.com)"},
+		{"", "https://example.com/contact", "PackagePulse (+https://example.com/contact)"},
+		{" ", " ", "PackagePulse"},
+	}
+	for _, tt := range tests {
+		if got := String(tt.version, tt.contact); got != tt.want {
+			t.Errorf("String(%q, %q) = %q, want %q", tt.version, tt.contact, got, tt.want)
+		}
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.UserAgent()
+	}))
+	t.Cleanup(server.Close)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: Transport("PackagePulse/1.0.0", nil)}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if got != "PackagePulse/1.0.0" {
+		t.Errorf("User-Agent = %q, want PackagePulse/1.0.0", got)
+	}
+	if req.Header.Get("User-Agent") != "" {
+		t.Error("Transport modified the caller's request")
+	}
+}
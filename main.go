@@ -1,35 +1,75 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/PackagePulse/internal/cassette"
+	"github.com/rayprogramming/PackagePulse/internal/drain"
+	"github.com/rayprogramming/PackagePulse/internal/probes"
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/providers/osv"
 	"github.com/rayprogramming/PackagePulse/internal/resources"
+	"github.com/rayprogramming/PackagePulse/internal/stdioguard"
 	"github.com/rayprogramming/PackagePulse/internal/tools"
+	"github.com/rayprogramming/PackagePulse/internal/tracing"
+	"github.com/rayprogramming/PackagePulse/internal/useragent"
 	"github.com/rayprogramming/hypermcp"
 	"github.com/rayprogramming/hypermcp/cache"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
 )
 
 func main() {
-	// Setup logger
-	logger, _ := zap.NewProduction()
+	// Load settings: defaults, then config file, then environment, then flags
+	appCfg, err := loadConfig(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration: %v\n", err)
+		os.Exit(2)
+	}
+
+	// Setup logger. Logs always go to stderr; stdout carries the stdio protocol.
+	logger, err := newLogger(appCfg.LogLevel, appCfg.LogFormat, zapcore.Lock(os.Stderr))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid logging configuration: %v\n", err)
+		os.Exit(2)
+	}
 	defer func() {
 		_ = logger.Sync()
 	}()
+	cfg := appCfg.Server
 
-	// Configure server with optimized cache settings
-	cfg := hypermcp.Config{
-		Name:         "PackagePulse",
-		Version:      "1.0.0",
-		CacheEnabled: true,
-		CacheConfig: cache.Config{
-			MaxCost:     100 * 1024 * 1024, // 100MB
-			NumCounters: 10_000,
-			BufferItems: 64,
-		},
+	// Export traces when a collector is configured, flushing the last spans on exit
+	if appCfg.OTelEndpoint != "" {
+		tp, err := tracing.NewProvider(context.Background(), appCfg.OTelEndpoint, cfg.Version)
+		if err != nil {
+			logger.Fatal("failed to set up tracing", zap.Error(err))
+		}
+		defer func() {
+			flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer flushCancel()
+			if err := tp.Shutdown(flushCtx); err != nil {
+				logger.Warn("failed to flush traces", zap.Error(err))
+			}
+		}()
+		appCfg.Tools.TracerProvider = tp
+		logger.Info("tracing enabled", zap.String("otel_endpoint", appCfg.OTelEndpoint))
 	}
 
 	// Create base server
@@ -45,7 +85,8 @@ func main() {
 		zap.Bool("cache_enabled", cfg.CacheEnabled))
 
 	// Register tools and resources
-	if err := registerFeatures(srv, logger); err != nil {
+	toolRegistry, err := registerFeatures(srv, logger, appCfg.Tools)
+	if err != nil {
 		logger.Fatal("failed to register features", zap.Error(err))
 	}
 
@@ -61,15 +102,19 @@ func main() {
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
 	// Goroutine to handle shutdown signal
-	go func() {
-		sig := <-sigChan
-		logger.Info("received shutdown signal", zap.String("signal", sig.String()))
-		cancel()
-	}()
+	go awaitShutdown(sigChan, toolRegistry, appCfg.ShutdownGrace, cancel, logger)
+
+	// Keep watchlisted packages warm in the cache until shutdown
+	go toolRegistry.RunWatchlist(ctx)
 
-	// Run with stdio transport
-	logger.Info("starting PackagePulse MCP server", zap.String("transport", "stdio"))
-	if err := hypermcp.RunWithTransport(ctx, srv, hypermcp.TransportStdio, logger); err != nil {
+	logger.Info("starting PackagePulse MCP server", zap.String("transport", appCfg.Transport))
+	if appCfg.Transport == transportHTTP {
+		err = runHTTP(ctx, srv, appCfg.HTTPAddr, toolRegistry, appCfg.Tools.Transport, logger)
+	} else {
+		// stdout carries the protocol; stray writes from anywhere else are logged and dropped
+		err = srv.Run(ctx, &stdioguard.Transport{Logger: logger})
+	}
+	if err != nil {
 		// Context cancellation is expected during graceful shutdown
 		if ctx.Err() == context.Canceled {
 			logger.Info("server shutdown complete")
@@ -81,28 +126,599 @@ func main() {
 	logger.Info("server shutdown complete")
 }
 
-func registerFeatures(srv *hypermcp.Server, logger *zap.Logger) error {
-	// Initialize tool registry
-	toolRegistry, err := tools.NewToolRegistry(logger, srv.Cache())
+// awaitShutdown waits for a shutdown signal, then rejects new tool calls and gives in-flight
+// ones up to grace to finish before cancel closes the transport
+func awaitShutdown(sigChan <-chan os.Signal, calls interface{ Drain(context.Context) error }, grace time.Duration, cancel context.CancelFunc, logger *zap.Logger) {
+	sig := <-sigChan
+	logger.Info("received shutdown signal", zap.String("signal", sig.String()), zap.Duration("grace", grace))
+
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), grace)
+	defer drainCancel()
+	if err := calls.Drain(drainCtx); err != nil {
+		logger.Warn("shutdown grace period ended with tool calls still running", zap.Error(err))
+	}
+	cancel()
+}
+
+// defaultServerConfig returns the server settings used when no config file overrides them
+func defaultServerConfig() hypermcp.Config {
+	return hypermcp.Config{
+		Name:         "PackagePulse",
+		Version:      "1.0.0",
+		CacheEnabled: true,
+		CacheConfig: cache.Config{
+			MaxCost:     100 * 1024 * 1024, // 100MB
+			NumCounters: 10_000,
+			BufferItems: 64,
+		},
+	}
+}
+
+// Supported values for --transport
+const (
+	transportStdio = "stdio"
+	transportHTTP  = "http"
+)
+
+// defaultHTTPAddr keeps HTTP mode on loopback unless an address is configured
+const defaultHTTPAddr = "127.0.0.1:8080"
+
+// Supported values for --log-format
+const (
+	logFormatJSON    = "json"
+	logFormatConsole = "console"
+)
+
+// appConfig holds every setting resolved at startup
+type appConfig struct {
+	Server    hypermcp.Config
+	Tools     tools.Config
+	Transport string
+	HTTPAddr  string
+	LogLevel  string
+	LogFormat string
+	// ShutdownGrace is how long in-flight tool calls may run after a shutdown signal
+	ShutdownGrace time.Duration
+	// OTelEndpoint, when set, is the OTLP/HTTP collector URL tool-call traces are exported to
+	OTelEndpoint string
+}
+
+// fileConfig mirrors the YAML config file. Pointer fields distinguish "unset" from zero.
+type fileConfig struct {
+	Transport     *string        `yaml:"transport"`
+	HTTPAddr      *string        `yaml:"http_addr"`
+	ShutdownGrace *time.Duration `yaml:"shutdown_grace"`
+	OTelEndpoint  *string        `yaml:"otel_endpoint"`
+	Log           struct {
+		Level  *string `yaml:"level"`
+		Format *string `yaml:"format"`
+	} `yaml:"log"`
+	Cache struct {
+		Enabled     *bool  `yaml:"enabled"`
+		MaxCost     *int64 `yaml:"max_cost"`
+		NumCounters *int64 `yaml:"num_counters"`
+		BufferItems *int64 `yaml:"buffer_items"`
+	} `yaml:"cache"`
+	Tools struct {
+		Timeout             *time.Duration         `yaml:"timeout"`
+		BatchTimeout        *time.Duration         `yaml:"batch_timeout"`
+		EnableLicenseReload *bool                  `yaml:"enable_license_reload"`
+		BreakerThreshold    *int                   `yaml:"breaker_threshold"`
+		BreakerCooldown     *time.Duration         `yaml:"breaker_cooldown"`
+		RetryBudget         *int                   `yaml:"retry_budget"`
+		RetryBudgetRefill   *time.Duration         `yaml:"retry_budget_refill"`
+		HistoryCapacity     *int                   `yaml:"history_capacity"`
+		MaxResponseBytes    *int                   `yaml:"max_response_bytes"`
+		Alternatives        map[string][]string    `yaml:"alternatives"`
+		PopularPackages     map[string][]string    `yaml:"popular_packages"`
+		Watchlist           []tools.WatchlistEntry `yaml:"watchlist"`
+		WatchlistInterval   *time.Duration         `yaml:"watchlist_interval"`
+		EnabledTools        []string               `yaml:"enabled_tools"`
+		DisabledTools       []string               `yaml:"disabled_tools"`
+		RiskWeights         struct {
+			CVSS *float64 `yaml:"cvss"`
+			EPSS *float64 `yaml:"epss"`
+			KEV  *float64 `yaml:"kev"`
+		} `yaml:"risk_weights"`
+		HealthScoring struct {
+			Recency       *float64 `yaml:"recency"`
+			Versions      *float64 `yaml:"versions"`
+			Repository    *float64 `yaml:"repository"`
+			Documentation *float64 `yaml:"documentation"`
+			License       *float64 `yaml:"license"`
+		} `yaml:"health_scoring"`
+	} `yaml:"tools"`
+}
+
+// loadConfig builds server and tool settings. Later sources override earlier ones:
+// built-in defaults, the --config file, PP_* environment variables, then command-line flags.
+func loadConfig(args []string) (appConfig, error) {
+	cfg := appConfig{
+		Server:        defaultServerConfig(),
+		Tools:         tools.DefaultConfig(),
+		Transport:     transportStdio,
+		HTTPAddr:      defaultHTTPAddr,
+		LogLevel:      zapcore.InfoLevel.String(),
+		LogFormat:     logFormatJSON,
+		ShutdownGrace: drain.DefaultGrace,
+	}
+
+	flags := flag.NewFlagSet("packagepulse", flag.ContinueOnError)
+	configPath := flags.String("config", "", "path to a YAML config file")
+	toolTimeout := flags.Duration("tool-timeout", 0, "deadline for single-package tools (overrides PP_TOOL_TIMEOUT)")
+	batchTimeout := flags.Duration("batch-tool-timeout", 0, "deadline for batch tools (overrides PP_BATCH_TOOL_TIMEOUT)")
+	transport := flags.String("transport", "", "transport to serve: stdio or http (overrides PP_TRANSPORT)")
+	httpAddr := flags.String("http-addr", "", "listen address in HTTP mode (overrides PP_HTTP_ADDR)")
+	logLevel := flags.String("log-level", "", "log level: debug, info, warn, or error (overrides PP_LOG_LEVEL)")
+	logFormat := flags.String("log-format", "", "log encoding: json or console (overrides PP_LOG_FORMAT)")
+	enableTools := flags.String("enable-tools", "", "comma-separated tools to register, all others are skipped (overrides PP_ENABLE_TOOLS)")
+	disableTools := flags.String("disable-tools", "", "comma-separated tools not to register (overrides PP_DISABLE_TOOLS)")
+	shutdownGrace := flags.Duration("shutdown-grace", 0, "how long in-flight tool calls may finish after SIGTERM (overrides PP_SHUTDOWN_GRACE)")
+	otelEndpoint := flags.String("otel-endpoint", "", "OTLP/HTTP collector URL to export traces to, such as http://localhost:4318 (overrides PP_OTEL_ENDPOINT)")
+	record := flags.String("record", "", "save every upstream response to this cassette directory")
+	replay := flags.String("replay", "", "answer upstream requests from this cassette directory, without network access")
+	if err := flags.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	if *configPath != "" {
+		if err := applyConfigFile(*configPath, &cfg); err != nil {
+			return cfg, fmt.Errorf("config file %s: %w", *configPath, err)
+		}
+	}
+
+	if v := os.Getenv("PP_TRANSPORT"); v != "" {
+		cfg.Transport = v
+	}
+	if v := os.Getenv("PP_HTTP_ADDR"); v != "" {
+		cfg.HTTPAddr = v
+	}
+	if v := os.Getenv("PP_LOG_LEVEL"); v != "" {
+		cfg.LogLevel = v
+	}
+	if v := os.Getenv("PP_LOG_FORMAT"); v != "" {
+		cfg.LogFormat = v
+	}
+	if v := os.Getenv("PP_SHUTDOWN_GRACE"); v != "" {
+		grace, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("PP_SHUTDOWN_GRACE: %w", err)
+		}
+		cfg.ShutdownGrace = grace
+	}
+	if v := os.Getenv("PP_OTEL_ENDPOINT"); v != "" {
+		cfg.OTelEndpoint = v
+	}
+	if err := applyToolEnv(&cfg.Tools); err != nil {
+		return cfg, err
+	}
+	cfg.Tools.UserAgent = useragent.String(cfg.Server.Version, os.Getenv("PP_CONTACT"))
+
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "tool-timeout":
+			cfg.Tools.Timeout = *toolTimeout
+		case "batch-tool-timeout":
+			cfg.Tools.BatchTimeout = *batchTimeout
+		case "transport":
+			cfg.Transport = *transport
+		case "http-addr":
+			cfg.HTTPAddr = *httpAddr
+		case "log-level":
+			cfg.LogLevel = *logLevel
+		case "log-format":
+			cfg.LogFormat = *logFormat
+		case "enable-tools":
+			cfg.Tools.EnabledTools = splitToolList(*enableTools)
+		case "disable-tools":
+			cfg.Tools.DisabledTools = splitToolList(*disableTools)
+		case "shutdown-grace":
+			cfg.ShutdownGrace = *shutdownGrace
+		case "otel-endpoint":
+			cfg.OTelEndpoint = *otelEndpoint
+		case "record":
+			cfg.Tools.CassetteMode, cfg.Tools.CassetteDir = cassette.ModeRecord, *record
+		case "replay":
+			cfg.Tools.CassetteMode, cfg.Tools.CassetteDir = cassette.ModeReplay, *replay
+		}
+	})
+	if *record != "" && *replay != "" {
+		return cfg, fmt.Errorf("--record and --replay are mutually exclusive")
+	}
+
+	if cfg.Transport != transportStdio && cfg.Transport != transportHTTP {
+		return cfg, fmt.Errorf("unsupported transport %q (valid: %s, %s)", cfg.Transport, transportStdio, transportHTTP)
+	}
+	if _, err := parseLogLevel(cfg.LogLevel); err != nil {
+		return cfg, err
+	}
+	if cfg.LogFormat != logFormatJSON && cfg.LogFormat != logFormatConsole {
+		return cfg, fmt.Errorf("unsupported log format %q (valid: %s, %s)", cfg.LogFormat, logFormatJSON, logFormatConsole)
+	}
+	if cfg.ShutdownGrace < 0 {
+		return cfg, fmt.Errorf("shutdown grace must not be negative")
+	}
+	if cfg.OTelEndpoint != "" {
+		if u, err := url.Parse(cfg.OTelEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return cfg, fmt.Errorf("otel endpoint %q must be an http or https URL", cfg.OTelEndpoint)
+		}
+	}
+	c := cfg.Server.CacheConfig
+	if c.MaxCost <= 0 || c.NumCounters <= 0 || c.BufferItems <= 0 {
+		return cfg, fmt.Errorf("cache max_cost, num_counters, and buffer_items must be positive")
+	}
+	return cfg, cfg.Tools.Validate()
+}
+
+// applyConfigFile overlays the values set in a YAML config file, rejecting unknown keys
+func applyConfigFile(path string, cfg *appConfig) error {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return err
 	}
 
+	var file fileConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	if v := file.Transport; v != nil {
+		cfg.Transport = *v
+	}
+	if v := file.HTTPAddr; v != nil {
+		cfg.HTTPAddr = *v
+	}
+	if v := file.ShutdownGrace; v != nil {
+		cfg.ShutdownGrace = *v
+	}
+	if v := file.OTelEndpoint; v != nil {
+		cfg.OTelEndpoint = *v
+	}
+	if v := file.Log.Level; v != nil {
+		cfg.LogLevel = *v
+	}
+	if v := file.Log.Format; v != nil {
+		cfg.LogFormat = *v
+	}
+	if v := file.Cache.Enabled; v != nil {
+		cfg.Server.CacheEnabled = *v
+	}
+	if v := file.Cache.MaxCost; v != nil {
+		cfg.Server.CacheConfig.MaxCost = *v
+	}
+	if v := file.Cache.NumCounters; v != nil {
+		cfg.Server.CacheConfig.NumCounters = *v
+	}
+	if v := file.Cache.BufferItems; v != nil {
+		cfg.Server.CacheConfig.BufferItems = *v
+	}
+	if v := file.Tools.Timeout; v != nil {
+		cfg.Tools.Timeout = *v
+	}
+	if v := file.Tools.BatchTimeout; v != nil {
+		cfg.Tools.BatchTimeout = *v
+	}
+	if v := file.Tools.EnableLicenseReload; v != nil {
+		cfg.Tools.EnableLicenseReload = *v
+	}
+	if v := file.Tools.BreakerThreshold; v != nil {
+		cfg.Tools.BreakerThreshold = *v
+	}
+	if v := file.Tools.BreakerCooldown; v != nil {
+		cfg.Tools.BreakerCooldown = *v
+	}
+	if v := file.Tools.RetryBudget; v != nil {
+		cfg.Tools.RetryBudget = *v
+	}
+	if v := file.Tools.RetryBudgetRefill; v != nil {
+		cfg.Tools.RetryBudgetRefill = *v
+	}
+	if v := file.Tools.HistoryCapacity; v != nil {
+		cfg.Tools.HistoryCapacity = *v
+	}
+	if v := file.Tools.MaxResponseBytes; v != nil {
+		cfg.Tools.MaxResponseBytes = *v
+	}
+	if v := file.Tools.Alternatives; v != nil {
+		cfg.Tools.Alternatives = v
+	}
+	if v := file.Tools.PopularPackages; v != nil {
+		cfg.Tools.PopularPackages = v
+	}
+	if v := file.Tools.Watchlist; v != nil {
+		cfg.Tools.Watchlist = v
+	}
+	if v := file.Tools.WatchlistInterval; v != nil {
+		cfg.Tools.WatchlistInterval = *v
+	}
+	if v := file.Tools.EnabledTools; v != nil {
+		cfg.Tools.EnabledTools = v
+	}
+	if v := file.Tools.DisabledTools; v != nil {
+		cfg.Tools.DisabledTools = v
+	}
+	if v := file.Tools.RiskWeights.CVSS; v != nil {
+		cfg.Tools.RiskWeights.CVSS = *v
+	}
+	if v := file.Tools.RiskWeights.EPSS; v != nil {
+		cfg.Tools.RiskWeights.EPSS = *v
+	}
+	if v := file.Tools.RiskWeights.KEV; v != nil {
+		cfg.Tools.RiskWeights.KEV = *v
+	}
+	scoring := []struct {
+		value  *float64
+		target *float64
+	}{
+		{file.Tools.HealthScoring.Recency, &cfg.Tools.HealthScoring.Recency},
+		{file.Tools.HealthScoring.Versions, &cfg.Tools.HealthScoring.Versions},
+		{file.Tools.HealthScoring.Repository, &cfg.Tools.HealthScoring.Repository},
+		{file.Tools.HealthScoring.Documentation, &cfg.Tools.HealthScoring.Documentation},
+		{file.Tools.HealthScoring.License, &cfg.Tools.HealthScoring.License},
+	}
+	for _, w := range scoring {
+		if w.value != nil {
+			*w.target = *w.value
+		}
+	}
+	return nil
+}
+
+// applyToolEnv overrides tool settings from PP_* environment variables
+func applyToolEnv(cfg *tools.Config) error {
+	weights := []struct {
+		env    string
+		target *float64
+	}{
+		{"PP_RISK_WEIGHT_CVSS", &cfg.RiskWeights.CVSS},
+		{"PP_RISK_WEIGHT_EPSS", &cfg.RiskWeights.EPSS},
+		{"PP_RISK_WEIGHT_KEV", &cfg.RiskWeights.KEV},
+	}
+	for _, w := range weights {
+		value := os.Getenv(w.env)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", w.env, err)
+		}
+		*w.target = parsed
+	}
+
+	timeouts := []struct {
+		env    string
+		target *time.Duration
+	}{
+		{"PP_TOOL_TIMEOUT", &cfg.Timeout},
+		{"PP_BATCH_TOOL_TIMEOUT", &cfg.BatchTimeout},
+		{"PP_BREAKER_COOLDOWN", &cfg.BreakerCooldown},
+		{"PP_RETRY_BUDGET_REFILL", &cfg.RetryBudgetRefill},
+		{"PP_WATCHLIST_INTERVAL", &cfg.WatchlistInterval},
+	}
+	for _, d := range timeouts {
+		value := os.Getenv(d.env)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", d.env, err)
+		}
+		*d.target = parsed
+	}
+
+	if v := os.Getenv("PP_BREAKER_THRESHOLD"); v != "" {
+		threshold, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("PP_BREAKER_THRESHOLD: %w", err)
+		}
+		cfg.BreakerThreshold = threshold
+	}
+
+	if v := os.Getenv("PP_RETRY_BUDGET"); v != "" {
+		budget, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("PP_RETRY_BUDGET: %w", err)
+		}
+		cfg.RetryBudget = budget
+	}
+
+	if v := os.Getenv("PP_HISTORY_CAPACITY"); v != "" {
+		capacity, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("PP_HISTORY_CAPACITY: %w", err)
+		}
+		cfg.HistoryCapacity = capacity
+	}
+
+	if v := os.Getenv("PP_MAX_RESPONSE_BYTES"); v != "" {
+		maxBytes, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("PP_MAX_RESPONSE_BYTES: %w", err)
+		}
+		cfg.MaxResponseBytes = maxBytes
+	}
+
+	if v := os.Getenv("PP_ENABLE_LICENSE_RELOAD"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("PP_ENABLE_LICENSE_RELOAD: %w", err)
+		}
+		cfg.EnableLicenseReload = enabled
+	}
+
+	if v := os.Getenv("PP_ENABLE_TOOLS"); v != "" {
+		cfg.EnabledTools = splitToolList(v)
+	}
+	if v := os.Getenv("PP_DISABLE_TOOLS"); v != "" {
+		cfg.DisabledTools = splitToolList(v)
+	}
+
+	if v := os.Getenv("PP_GITHUB_TOKEN"); v != "" {
+		cfg.GitHubToken = v
+	}
+
+	transport, err := upstreamTransport(os.Getenv("PP_TLS_CLIENT_CERT"), os.Getenv("PP_TLS_CLIENT_KEY"), os.Getenv("PP_TLS_CA_FILE"))
+	if err != nil {
+		return err
+	}
+	cfg.Transport = transport
+
+	return nil
+}
+
+// upstreamTransport builds the transport OSV and deps.dev requests go through when a client
+// certificate or extra CA bundle is configured, and returns nil otherwise. Like the default
+// transport it honors HTTPS_PROXY and NO_PROXY.
+func upstreamTransport(certFile, keyFile, caFile string) (http.RoundTripper, error) {
+	if certFile == "" && keyFile == "" && caFile == "" {
+		return nil, nil
+	}
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("PP_TLS_CLIENT_CERT and PP_TLS_CLIENT_KEY must be set together")
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("PP_TLS_CLIENT_CERT: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("PP_TLS_CA_FILE: %w", err)
+		}
+		// Trust the extra CAs, such as a TLS-inspecting proxy's, alongside the system roots
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("PP_TLS_CA_FILE: no PEM certificates in %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// splitToolList parses a comma-separated list of tool names, ignoring blank entries
+func splitToolList(v string) []string {
+	var names []string
+	for _, name := range strings.Split(v, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// parseLogLevel accepts the levels exposed by --log-level
+func parseLogLevel(level string) (zapcore.Level, error) {
+	switch l := strings.ToLower(level); l {
+	case "debug", "info", "warn", "error":
+		return zapcore.ParseLevel(l)
+	default:
+		return zapcore.InfoLevel, fmt.Errorf("unsupported log level %q (valid: debug, info, warn, error)", level)
+	}
+}
+
+// newLogger builds a logger writing to out. Apart from level and encoding it matches
+// zap.NewProduction: caller annotation, error stack traces, and sampling.
+func newLogger(level, format string, out zapcore.WriteSyncer) (*zap.Logger, error) {
+	lvl, err := parseLogLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	var encoder zapcore.Encoder
+	switch format {
+	case logFormatJSON:
+		encoder = zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	case logFormatConsole:
+		encoder = zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	default:
+		return nil, fmt.Errorf("unsupported log format %q (valid: %s, %s)", format, logFormatJSON, logFormatConsole)
+	}
+
+	core := zapcore.NewSamplerWithOptions(zapcore.NewCore(encoder, out, lvl), time.Second, 100, 100)
+	return zap.New(core,
+		zap.ErrorOutput(out),
+		zap.AddCaller(),
+		zap.AddStacktrace(zapcore.ErrorLevel),
+	), nil
+}
+
+func registerFeatures(srv *hypermcp.Server, logger *zap.Logger, toolCfg tools.Config) (*tools.ToolRegistry, error) {
+	// Initialize tool registry
+	toolRegistry, err := tools.NewToolRegistryWithConfig(logger, srv.Cache(), toolCfg)
+	if err != nil {
+		return nil, err
+	}
+
 	// Register all tools
 	if err := toolRegistry.Register(srv); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Initialize resource registry
-	resourceRegistry, err := resources.NewResourceRegistry(logger)
+	resourceRegistry, err := resources.NewResourceRegistry(logger, toolRegistry, toolRegistry.History())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Register all resources
 	if err := resourceRegistry.Register(srv); err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return toolRegistry, nil
+}
+
+// runHTTP serves MCP over streamable HTTP at /mcp alongside /healthz and /readyz probes,
+// shutting the listener down when ctx is cancelled. /healthz reports the watchlist refresh
+// state and /readyz the upstream circuit breakers.
+func runHTTP(ctx context.Context, srv *hypermcp.Server, addr string, toolRegistry *tools.ToolRegistry, transport http.RoundTripper, logger *zap.Logger) error {
+	checker := probes.NewChecker(map[string]string{
+		tools.UpstreamOSV:     osv.APIBaseURL,
+		tools.UpstreamDepsDev: depsdev.APIBaseURL,
+	}, probes.WithBreakers(toolRegistry.Breakers()), probes.WithWatchlist(toolRegistry.WatchlistStatus), probes.WithTransport(transport))
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return srv.MCP() }, nil))
+	mux.HandleFunc("/healthz", checker.Liveness)
+	mux.HandleFunc("/readyz", checker.Readiness)
+	mux.Handle("/metrics", toolRegistry.Stats())
+
+	httpSrv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info("listening for HTTP", zap.String("addr", addr))
+		errCh <- httpSrv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := httpSrv.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		return ctx.Err()
+	}
 }
@@ -2,14 +2,29 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"testing"
 	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/PackagePulse/internal/breaker"
+	"github.com/rayprogramming/PackagePulse/internal/drain"
+	"github.com/rayprogramming/PackagePulse/internal/providers/depsdev"
+	"github.com/rayprogramming/PackagePulse/internal/retrybudget"
+	"github.com/rayprogramming/PackagePulse/internal/tools"
 	"github.com/rayprogramming/hypermcp"
 	"github.com/rayprogramming/hypermcp/cache"
 	"go.uber.org/zap"
@@ -32,7 +47,8 @@ func TestStdioTransportStartup(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "./packagepulse_test")
+	// Debug logging is the noisiest setting; all of it must stay off stdout
+	cmd := exec.CommandContext(ctx, "./packagepulse_test", "--log-level", "debug")
 
 	// Capture stdout and stderr
 	stdout, err := cmd.StdoutPipe()
@@ -70,11 +86,11 @@ func TestStdioTransportStartup(t *testing.T) {
 		done <- true
 	}()
 
-	// Also monitor stdout in case of any output
+	// stdout carries the protocol, so nothing may be written before a client speaks
 	go func() {
 		scanner := bufio.NewScanner(stdout)
 		for scanner.Scan() {
-			t.Logf("stdout: %s", scanner.Text())
+			t.Errorf("unexpected stdout output: %s", scanner.Text())
 		}
 	}()
 
@@ -221,6 +237,61 @@ func TestSignalHandling(t *testing.T) {
 	}
 }
 
+// TestAwaitShutdown_DrainsInflightCall verifies that a tool call started just before SIGTERM
+// finishes before the transport is closed
+func TestAwaitShutdown_DrainsInflightCall(t *testing.T) {
+	tracker := drain.New()
+	started := make(chan struct{})
+	handler := drain.Middleware(tracker)(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil
+	})
+
+	var finished atomic.Bool
+	go func() {
+		if result, err := handler(context.Background(), "tools/call", &mcp.CallToolRequest{}); err == nil && !result.(*mcp.CallToolResult).IsError {
+			finished.Store(true)
+		}
+	}()
+	<-started
+
+	sigChan := make(chan os.Signal, 1)
+	cancelled := make(chan bool, 1)
+	go awaitShutdown(sigChan, tracker, 2*time.Second, func() { cancelled <- finished.Load() }, zap.NewNop())
+	sigChan <- syscall.SIGTERM
+
+	select {
+	case callDone := <-cancelled:
+		if !callDone {
+			t.Error("transport cancelled before the in-flight call completed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("shutdown did not complete within the grace window")
+	}
+}
+
+// TestLoadConfig_ShutdownGrace verifies the grace period default, override, and validation
+func TestLoadConfig_ShutdownGrace(t *testing.T) {
+	cfg, err := loadConfig(nil)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if cfg.ShutdownGrace != drain.DefaultGrace {
+		t.Errorf("ShutdownGrace = %v, want %v", cfg.ShutdownGrace, drain.DefaultGrace)
+	}
+
+	t.Setenv("PP_SHUTDOWN_GRACE", "30s")
+	if cfg, err = loadConfig([]string{"--shutdown-grace", "3s"}); err != nil || cfg.ShutdownGrace != 3*time.Second {
+		t.Errorf("ShutdownGrace = %v (%v), want 3s from the flag", cfg.ShutdownGrace, err)
+	}
+
+	t.Setenv("PP_SHUTDOWN_GRACE", "-1s")
+	if _, err := loadConfig(nil); err == nil {
+		t.Error("expected error for a negative shutdown grace")
+	}
+}
+
 // TestServerConfigCreation tests the server configuration creation
 func TestServerConfigCreation(t *testing.T) {
 	tests := []struct {
@@ -359,3 +430,460 @@ func TestConfigParsing(t *testing.T) {
 		}
 	})
 }
+
+// TestLoadToolConfig verifies risk weights can be tuned through the environment
+func TestLoadToolConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg, err := loadConfig(nil)
+		if err != nil {
+			t.Fatalf("loadConfig() error = %v", err)
+		}
+		if cfg.Tools.RiskWeights != tools.DefaultRiskWeights() {
+			t.Errorf("RiskWeights = %+v, want defaults", cfg.Tools.RiskWeights)
+		}
+	})
+
+	t.Run("env overrides", func(t *testing.T) {
+		t.Setenv("PP_RISK_WEIGHT_CVSS", "1")
+		t.Setenv("PP_RISK_WEIGHT_EPSS", "0")
+		t.Setenv("PP_RISK_WEIGHT_KEV", "0.5")
+
+		cfg, err := loadConfig(nil)
+		if err != nil {
+			t.Fatalf("loadConfig() error = %v", err)
+		}
+		want := tools.RiskWeights{CVSS: 1, EPSS: 0, KEV: 0.5}
+		if cfg.Tools.RiskWeights != want {
+			t.Errorf("RiskWeights = %+v, want %+v", cfg.Tools.RiskWeights, want)
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Setenv("PP_RISK_WEIGHT_KEV", "lots")
+		if _, err := loadConfig(nil); err == nil {
+			t.Error("expected error for non-numeric weight")
+		}
+	})
+
+	t.Run("all zero", func(t *testing.T) {
+		t.Setenv("PP_RISK_WEIGHT_CVSS", "0")
+		t.Setenv("PP_RISK_WEIGHT_EPSS", "0")
+		t.Setenv("PP_RISK_WEIGHT_KEV", "0")
+		if _, err := loadConfig(nil); err == nil {
+			t.Error("expected error when all weights are zero")
+		}
+	})
+}
+
+// TestLoadToolConfig_Timeouts verifies tool deadlines can be set through the environment
+func TestLoadToolConfig_Timeouts(t *testing.T) {
+	t.Setenv("PP_TOOL_TIMEOUT", "10s")
+	t.Setenv("PP_BATCH_TOOL_TIMEOUT", "5m")
+
+	cfg, err := loadConfig(nil)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if cfg.Tools.Timeout != 10*time.Second || cfg.Tools.BatchTimeout != 5*time.Minute {
+		t.Errorf("timeouts = %v/%v, want 10s/5m", cfg.Tools.Timeout, cfg.Tools.BatchTimeout)
+	}
+
+	t.Setenv("PP_TOOL_TIMEOUT", "soon")
+	if _, err := loadConfig(nil); err == nil {
+		t.Error("expected error for unparseable duration")
+	}
+}
+
+// TestLoadToolConfig_Breaker verifies the circuit breaker settings and their validation
+func TestLoadToolConfig_Breaker(t *testing.T) {
+	cfg, err := loadConfig(nil)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if cfg.Tools.BreakerThreshold != breaker.DefaultThreshold || cfg.Tools.BreakerCooldown != breaker.DefaultCooldown {
+		t.Errorf("breaker = %d/%v, want the defaults", cfg.Tools.BreakerThreshold, cfg.Tools.BreakerCooldown)
+	}
+
+	t.Setenv("PP_BREAKER_THRESHOLD", "3")
+	t.Setenv("PP_BREAKER_COOLDOWN", "1m")
+	cfg, err = loadConfig(nil)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if cfg.Tools.BreakerThreshold != 3 || cfg.Tools.BreakerCooldown != time.Minute {
+		t.Errorf("breaker = %d/%v, want 3/1m", cfg.Tools.BreakerThreshold, cfg.Tools.BreakerCooldown)
+	}
+
+	t.Setenv("PP_BREAKER_THRESHOLD", "0")
+	if _, err := loadConfig(nil); err == nil {
+		t.Error("expected error for a non-positive breaker threshold")
+	}
+}
+
+// TestLoadToolConfig_RetryBudget verifies the retry budget settings and their validation
+func TestLoadToolConfig_RetryBudget(t *testing.T) {
+	cfg, err := loadConfig(nil)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if cfg.Tools.RetryBudget != retrybudget.DefaultCapacity || cfg.Tools.RetryBudgetRefill != retrybudget.DefaultRefill {
+		t.Errorf("retry budget = %d/%v, want the defaults", cfg.Tools.RetryBudget, cfg.Tools.RetryBudgetRefill)
+	}
+
+	t.Setenv("PP_RETRY_BUDGET", "4")
+	t.Setenv("PP_RETRY_BUDGET_REFILL", "5s")
+	cfg, err = loadConfig(nil)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if cfg.Tools.RetryBudget != 4 || cfg.Tools.RetryBudgetRefill != 5*time.Second {
+		t.Errorf("retry budget = %d/%v, want 4/5s", cfg.Tools.RetryBudget, cfg.Tools.RetryBudgetRefill)
+	}
+
+	t.Setenv("PP_RETRY_BUDGET", "0")
+	if _, err := loadConfig(nil); err == nil {
+		t.Error("expected error for a non-positive retry budget")
+	}
+}
+
+func TestLoadToolConfig_ToolLists(t *testing.T) {
+	t.Setenv("PP_DISABLE_TOOLS", "deps.gate")
+	cfg, err := loadConfig([]string{"--enable-tools", "license.info, license.batch_info,", "--disable-tools", "deps.upgrade_plan"})
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if strings.Join(cfg.Tools.EnabledTools, ",") != "license.info,license.batch_info" {
+		t.Errorf("EnabledTools = %v, want the two license tools", cfg.Tools.EnabledTools)
+	}
+	// The flag overrides the environment
+	if strings.Join(cfg.Tools.DisabledTools, ",") != "deps.upgrade_plan" {
+		t.Errorf("DisabledTools = %v, want [deps.upgrade_plan]", cfg.Tools.DisabledTools)
+	}
+
+	if _, err := loadConfig([]string{"--disable-tools", "deps.upgrade_plans"}); err == nil || !strings.Contains(err.Error(), "deps.upgrade_plans") {
+		t.Errorf("loadConfig() error = %v, want an unknown tool error", err)
+	}
+}
+
+// TestLoadConfig_File verifies config file values and their precedence below env and flags
+func TestLoadConfig_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "packagepulse.yaml")
+	content := `cache:
+  enabled: false
+  max_cost: 1048576
+  num_counters: 500
+tools:
+  timeout: 45s
+  batch_timeout: 3m
+  enable_license_reload: true
+  breaker_threshold: 8
+  breaker_cooldown: 2m
+  retry_budget: 6
+  retry_budget_refill: 10s
+  history_capacity: 25
+  risk_weights:
+    cvss: 0.5
+    epss: 0.5
+    kev: 0
+  health_scoring:
+    recency: 20
+    versions: 0
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	t.Run("file values", func(t *testing.T) {
+		appCfg, err := loadConfig([]string{"--config", path})
+		cfg, toolCfg := appCfg.Server, appCfg.Tools
+		if err != nil {
+			t.Fatalf("loadConfig() error = %v", err)
+		}
+		if cfg.CacheEnabled || cfg.CacheConfig.MaxCost != 1<<20 || cfg.CacheConfig.NumCounters != 500 {
+			t.Errorf("server config = %+v, want cache settings from file", cfg)
+		}
+		if cfg.CacheConfig.BufferItems != 64 || cfg.Name != "PackagePulse" {
+			t.Errorf("server config = %+v, want defaults for unset keys", cfg)
+		}
+		if toolCfg.Timeout != 45*time.Second || toolCfg.BatchTimeout != 3*time.Minute {
+			t.Errorf("timeouts = %v/%v, want 45s/3m", toolCfg.Timeout, toolCfg.BatchTimeout)
+		}
+		if !toolCfg.EnableLicenseReload {
+			t.Error("EnableLicenseReload = false, want true from file")
+		}
+		if toolCfg.BreakerThreshold != 8 || toolCfg.BreakerCooldown != 2*time.Minute {
+			t.Errorf("breaker = %d/%v, want 8/2m from file", toolCfg.BreakerThreshold, toolCfg.BreakerCooldown)
+		}
+		if toolCfg.RetryBudget != 6 || toolCfg.RetryBudgetRefill != 10*time.Second {
+			t.Errorf("retry budget = %d/%v, want 6/10s from file", toolCfg.RetryBudget, toolCfg.RetryBudgetRefill)
+		}
+		if toolCfg.HistoryCapacity != 25 {
+			t.Errorf("HistoryCapacity = %d, want 25 from file", toolCfg.HistoryCapacity)
+		}
+		want := tools.RiskWeights{CVSS: 0.5, EPSS: 0.5, KEV: 0}
+		if toolCfg.RiskWeights != want {
+			t.Errorf("RiskWeights = %+v, want %+v", toolCfg.RiskWeights, want)
+		}
+		wantScoring := depsdev.HealthScoringConfig{Recency: 20, Versions: 0, Repository: 20, Documentation: 10, License: 10}
+		if toolCfg.HealthScoring != wantScoring {
+			t.Errorf("HealthScoring = %+v, want %+v", toolCfg.HealthScoring, wantScoring)
+		}
+	})
+
+	t.Run("env and flags override file", func(t *testing.T) {
+		t.Setenv("PP_TOOL_TIMEOUT", "10s")
+		t.Setenv("PP_BATCH_TOOL_TIMEOUT", "4m")
+
+		appCfg, err := loadConfig([]string{"--config", path, "--batch-tool-timeout", "90s"})
+		toolCfg := appCfg.Tools
+		if err != nil {
+			t.Fatalf("loadConfig() error = %v", err)
+		}
+		if toolCfg.Timeout != 10*time.Second {
+			t.Errorf("Timeout = %v, want env value 10s", toolCfg.Timeout)
+		}
+		if toolCfg.BatchTimeout != 90*time.Second {
+			t.Errorf("BatchTimeout = %v, want flag value 90s", toolCfg.BatchTimeout)
+		}
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		bad := filepath.Join(t.TempDir(), "bad.yaml")
+		if err := os.WriteFile(bad, []byte("tools:\n  timeot: 10s\n"), 0o600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		_, err := loadConfig([]string{"--config", bad})
+		if err == nil || !strings.Contains(err.Error(), "timeot") {
+			t.Errorf("loadConfig() error = %v, want unknown key error naming timeot", err)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := loadConfig([]string{"--config", filepath.Join(t.TempDir(), "nope.yaml")}); err == nil {
+			t.Error("expected error for missing config file")
+		}
+	})
+}
+
+// TestLoadConfig_Transport verifies transport selection and its precedence
+func TestLoadConfig_Transport(t *testing.T) {
+	t.Run("defaults to stdio", func(t *testing.T) {
+		cfg, err := loadConfig(nil)
+		if err != nil {
+			t.Fatalf("loadConfig() error = %v", err)
+		}
+		if cfg.Transport != transportStdio || cfg.HTTPAddr != defaultHTTPAddr {
+			t.Errorf("transport = %s at %s, want stdio at %s", cfg.Transport, cfg.HTTPAddr, defaultHTTPAddr)
+		}
+	})
+
+	t.Run("file, env, and flag", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "packagepulse.yaml")
+		if err := os.WriteFile(path, []byte("transport: http\nhttp_addr: 0.0.0.0:9000\n"), 0o600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		t.Setenv("PP_HTTP_ADDR", "127.0.0.1:9001")
+
+		cfg, err := loadConfig([]string{"--config", path})
+		if err != nil {
+			t.Fatalf("loadConfig() error = %v", err)
+		}
+		if cfg.Transport != transportHTTP || cfg.HTTPAddr != "127.0.0.1:9001" {
+			t.Errorf("transport = %s at %s, want http at env address", cfg.Transport, cfg.HTTPAddr)
+		}
+
+		cfg, err = loadConfig([]string{"--config", path, "--transport", "stdio", "--http-addr", ":9002"})
+		if err != nil {
+			t.Fatalf("loadConfig() error = %v", err)
+		}
+		if cfg.Transport != transportStdio || cfg.HTTPAddr != ":9002" {
+			t.Errorf("transport = %s at %s, want flag values", cfg.Transport, cfg.HTTPAddr)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		if _, err := loadConfig([]string{"--transport", "sse"}); err == nil {
+			t.Error("expected error for unsupported transport")
+		}
+	})
+}
+
+// syncBuffer is a log sink safe to read while background goroutines write to it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Sync() error { return nil }
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// TestNewLogger_Levels verifies debug logging surfaces the tools' cache-hit lines and info hides them
+func TestNewLogger_Levels(t *testing.T) {
+	for _, tc := range []struct {
+		level, format string
+		wantHits      bool
+	}{
+		{"debug", logFormatConsole, true},
+		{"info", logFormatJSON, false},
+	} {
+		t.Run(tc.level, func(t *testing.T) {
+			out := &syncBuffer{}
+			logger, err := newLogger(tc.level, tc.format, out)
+			if err != nil {
+				t.Fatalf("newLogger() error = %v", err)
+			}
+
+			srv, err := hypermcp.New(defaultServerConfig(), logger)
+			if err != nil {
+				t.Fatalf("failed to create server: %v", err)
+			}
+			registry, err := tools.NewToolRegistry(logger, srv.Cache())
+			if err != nil {
+				t.Fatalf("failed to create registry: %v", err)
+			}
+
+			// Cache writes land asynchronously, so repeat the lookup until one is served from cache
+			deadline := time.Now().Add(2 * time.Second)
+			for time.Now().Before(deadline) && !strings.Contains(out.String(), "cache hit") {
+				if _, err := registry.HandleLicense(context.Background(), tools.LicenseInput{LicenseID: "MIT"}); err != nil {
+					t.Fatalf("HandleLicense() error = %v", err)
+				}
+				if !tc.wantHits {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			logs := out.String()
+			if got := strings.Contains(logs, "cache hit"); got != tc.wantHits {
+				t.Errorf("cache hit logged = %v, want %v; logs:\n%s", got, tc.wantHits, logs)
+			}
+			if tc.format == logFormatJSON && !strings.HasPrefix(logs, "{") {
+				t.Errorf("expected JSON log lines, got:\n%s", logs)
+			}
+		})
+	}
+
+	if _, err := newLogger("verbose", logFormatJSON, &syncBuffer{}); err == nil {
+		t.Error("expected error for unsupported level")
+	}
+	if _, err := loadConfig([]string{"--log-format", "logfmt"}); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+// TestLoadConfig_UserAgent verifies upstream requests identify the server version and contact
+func TestLoadConfig_UserAgent(t *testing.T) {
+	cfg, err := loadConfig(nil)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if cfg.Tools.UserAgent != "PackagePulse/1.0.0" {
+		t.Errorf("UserAgent = %q, want PackagePulse/1.0.0", cfg.Tools.UserAgent)
+	}
+
+	t.Setenv("PP_CONTACT", "ops@example.com")
+	if cfg, err = loadConfig(nil); err != nil || cfg.Tools.UserAgent != "PackagePulse/1.0.0 (+ops@example.com)" {
+		t.Errorf("UserAgent = %q (%v), want the contact appended", cfg.Tools.UserAgent, err)
+	}
+}
+
+// TestLoadConfig_Cassette verifies --record and --replay select the cassette mode and directory
+func TestLoadConfig_Cassette(t *testing.T) {
+	cfg, err := loadConfig([]string{"--record", "testdata/cassette"})
+	if err != nil {
+		t.Fatalf("loadConfig(--record) error = %v", err)
+	}
+	if cfg.Tools.CassetteMode != "record" || cfg.Tools.CassetteDir != "testdata/cassette" {
+		t.Errorf("cassette = %s %s, want record testdata/cassette", cfg.Tools.CassetteMode, cfg.Tools.CassetteDir)
+	}
+
+	if cfg, err = loadConfig([]string{"--replay", "fixtures"}); err != nil || cfg.Tools.CassetteMode != "replay" {
+		t.Errorf("loadConfig(--replay) = %s, %v; want replay mode", cfg.Tools.CassetteMode, err)
+	}
+
+	if _, err := loadConfig([]string{"--record", "a", "--replay", "b"}); err == nil {
+		t.Error("expected an error combining --record and --replay")
+	}
+}
+
+// TestLoadConfig_OTelEndpoint verifies --otel-endpoint overrides PP_OTEL_ENDPOINT and that
+// the endpoint must be a URL
+func TestLoadConfig_OTelEndpoint(t *testing.T) {
+	t.Setenv("PP_OTEL_ENDPOINT", "http://collector:4318")
+	cfg, err := loadConfig(nil)
+	if err != nil || cfg.OTelEndpoint != "http://collector:4318" {
+		t.Errorf("OTelEndpoint = %q (%v), want the environment's", cfg.OTelEndpoint, err)
+	}
+	if cfg, err = loadConfig([]string{"--otel-endpoint", "https://otel.example.com"}); err != nil || cfg.OTelEndpoint != "https://otel.example.com" {
+		t.Errorf("OTelEndpoint = %q (%v), want the flag's", cfg.OTelEndpoint, err)
+	}
+	if _, err := loadConfig([]string{"--otel-endpoint", "localhost:4318"}); err == nil {
+		t.Error("expected an error for an endpoint without a scheme")
+	}
+}
+
+// TestLoadConfig_ClientTLS verifies PP_TLS_* builds an upstream transport that presents the
+// client certificate and trusts the extra CA, as needed behind a TLS-inspecting proxy
+func TestLoadConfig_ClientTLS(t *testing.T) {
+	cfg, err := loadConfig(nil)
+	if err != nil || cfg.Tools.Transport != nil {
+		t.Fatalf("loadConfig() transport = %v (%v), want the default transport", cfg.Tools.Transport, err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			http.Error(w, "client certificate required", http.StatusUnauthorized)
+		}
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	// The test server's own certificate doubles as the client certificate and the CA
+	dir := t.TempDir()
+	serverCert := server.TLS.Certificates[0]
+	key, err := x509.MarshalPKCS8PrivateKey(serverCert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath, keyPath := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(certPath, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PP_TLS_CLIENT_CERT", certPath)
+	t.Setenv("PP_TLS_CLIENT_KEY", keyPath)
+	t.Setenv("PP_TLS_CA_FILE", certPath)
+	if cfg, err = loadConfig(nil); err != nil || cfg.Tools.Transport == nil {
+		t.Fatalf("loadConfig() transport = %v (%v), want a client TLS transport", cfg.Tools.Transport, err)
+	}
+	resp, err := (&http.Client{Transport: cfg.Tools.Transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200 with the client certificate presented", resp.StatusCode)
+	}
+
+	t.Setenv("PP_TLS_CLIENT_KEY", "")
+	if _, err := loadConfig(nil); err == nil {
+		t.Error("expected an error for a client certificate without a key")
+	}
+}